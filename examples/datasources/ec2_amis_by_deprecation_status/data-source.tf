@@ -0,0 +1,32 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Split the golden AMIs by deprecation status, warning two weeks ahead
+data "awsutils_ec2_amis_by_deprecation_status" "golden" {
+  tags = {
+    Purpose = "golden"
+  }
+
+  deprecating_soon_days = 14
+}
+
+output "deprecated_image_ids" {
+  value = data.awsutils_ec2_amis_by_deprecation_status.golden.deprecated_image_ids
+}
+
+output "deprecating_soon_image_ids" {
+  value = data.awsutils_ec2_amis_by_deprecation_status.golden.deprecating_soon_image_ids
+}
@@ -0,0 +1,31 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Look up the latest build of the golden AMI
+data "awsutils_ec2_amis_by_tag_with_latest" "golden" {
+  tags = {
+    Role = "golden"
+  }
+
+  filter {
+    name   = "state"
+    values = ["available"]
+  }
+}
+
+output "golden_image_id" {
+  value = data.awsutils_ec2_amis_by_tag_with_latest.golden.image_id
+}
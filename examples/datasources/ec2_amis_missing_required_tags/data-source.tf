@@ -0,0 +1,27 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# List the owned AMIs, or their Snapshots, missing the tags required by the tagging policy. Every owned AMI is checked,
+# so no filter is set.
+data "awsutils_ec2_amis_missing_required_tags" "default" {
+  required_keys       = ["Owner", "Environment", "CostCenter"]
+  check_snapshot_tags = true
+  allow_unfiltered    = true
+}
+
+output "untagged_image_ids" {
+  value = data.awsutils_ec2_amis_missing_required_tags.default.image_ids
+}
@@ -0,0 +1,28 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# List the owned AMIs of the platform team which no Instance or Launch Template uses, except those of the last month
+data "awsutils_ec2_amis_unused" "default" {
+  tags = {
+    Team = "platform"
+  }
+
+  grace_period_days = 30
+}
+
+output "unused_image_ids" {
+  value = data.awsutils_ec2_amis_unused.default.image_ids
+}
@@ -0,0 +1,30 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Preview the Security Groups a baseline enforcer would select before applying it
+data "awsutils_ec2_filter_preview" "web" {
+  resource_type = "security-group"
+  name          = "web-*"
+
+  filter {
+    name   = "vpc-id"
+    values = ["vpc-0123456789abcdef0"]
+  }
+}
+
+output "matched_security_group_ids" {
+  value = data.awsutils_ec2_filter_preview.web.matched_ids
+}
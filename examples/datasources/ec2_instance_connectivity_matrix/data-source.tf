@@ -0,0 +1,37 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Check which web servers can reach which databases on the PostgreSQL port
+data "awsutils_ec2_instance_connectivity_matrix" "web_to_db" {
+  source {
+    tags = {
+      Role = "web"
+    }
+  }
+
+  destination {
+    filter {
+      name   = "tag:Role"
+      values = ["db"]
+    }
+  }
+
+  port = 5432
+}
+
+output "denied_pairs" {
+  value = [for pair in data.awsutils_ec2_instance_connectivity_matrix.web_to_db.pairs : "${pair.source_instance_id} -> ${pair.destination_instance_id}: ${pair.reason}" if !pair.allowed]
+}
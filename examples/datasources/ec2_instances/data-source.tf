@@ -0,0 +1,94 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# List the running instances of the platform team in a VPC
+data "awsutils_ec2_instances" "default" {
+  vpc_id               = "vpc-0123456789abcdef0"
+  instance_state_names = ["running"]
+
+  tags = {
+    Team = "platform"
+  }
+
+  filter {
+    name   = "instance-type"
+    values = ["t3.*"]
+  }
+}
+
+output "private_ips" {
+  value = zipmap(data.awsutils_ec2_instances.default.ids, data.awsutils_ec2_instances.default.private_ips)
+}
+
+# List the instances either in us-east-1a or of type t3.large, with a request for each filter block
+data "awsutils_ec2_instances" "any" {
+  match = "any"
+
+  filter {
+    name   = "availability-zone"
+    values = ["us-east-1a"]
+  }
+
+  filter {
+    name   = "instance-type"
+    values = ["t3.large"]
+  }
+}
+
+# List the instances in the 10.0.0.0/24 range launched in June 2021, matching attributes the API cannot filter on.
+# The regular expressions are matched once the instances are read, so every instance of the region has to be read.
+data "awsutils_ec2_instances" "regex" {
+  allow_unfiltered = true
+
+  regex_filter {
+    attribute = "private_dns_name"
+    pattern   = "^ip-10-0-0-\\d+\\."
+  }
+
+  regex_filter {
+    attribute = "launch_time"
+    pattern   = "^2021-06-"
+  }
+}
+
+# Count the instances of the platform team by state, for the textfile collector of the node exporter
+data "awsutils_ec2_instances" "metrics" {
+  tags = {
+    Team = "platform"
+  }
+}
+
+output "prometheus_metrics" {
+  value = data.awsutils_ec2_instances.metrics.prometheus_metrics
+}
+
+# List the running instances of the platform team in eu-west-1, without a provider alias for that region
+data "awsutils_ec2_instances" "eu_west_1" {
+  region               = "eu-west-1"
+  instance_state_names = ["running"]
+
+  tags = {
+    Team = "platform"
+  }
+}
+
+# List the instances launched from the AMIs of the allowlist generated by CI, one AMI ID per line
+data "awsutils_ec2_instances" "allowed_amis" {
+  filter {
+    name        = "image-id"
+    values_file = "${path.module}/allowed-amis.txt"
+  }
+}
@@ -0,0 +1,31 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Split the running instances of an environment by platform for patching
+data "awsutils_ec2_instances_by_platform" "default" {
+  filter {
+    name   = "instance-state-name"
+    values = ["running"]
+  }
+
+  tags = {
+    Environment = "prod"
+  }
+}
+
+output "instance_ids_by_platform" {
+  value = { for group in data.awsutils_ec2_instances_by_platform.default.platforms : group.platform => group.instance_ids }
+}
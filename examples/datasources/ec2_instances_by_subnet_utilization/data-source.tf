@@ -0,0 +1,33 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Find the Subnets of the production EKS nodes which are running out of IP addresses
+data "awsutils_ec2_instances_by_subnet_utilization" "eks_nodes" {
+  states = ["pending", "running"]
+
+  tags = {
+    Environment        = "prod"
+    "eks:cluster-name" = "main"
+  }
+}
+
+output "nearly_full_subnet_ids" {
+  value = [for subnet in data.awsutils_ec2_instances_by_subnet_utilization.eks_nodes.subnets : subnet.subnet_id if subnet.utilization > 0.8]
+}
+
+output "available_ip_address_counts" {
+  value = { for subnet in data.awsutils_ec2_instances_by_subnet_utilization.eks_nodes.subnets : subnet.subnet_id => subnet.available_ip_address_count }
+}
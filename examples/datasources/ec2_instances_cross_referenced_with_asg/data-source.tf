@@ -0,0 +1,31 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Find the running instances launched outside of any Auto Scaling Group
+data "awsutils_ec2_instances_cross_referenced_with_asg" "running" {
+  filter {
+    name   = "instance-state-name"
+    values = ["running"]
+  }
+}
+
+output "instance_ids_by_asg" {
+  value = { for group in data.awsutils_ec2_instances_cross_referenced_with_asg.running.autoscaling_groups : group.autoscaling_group_name => group.instance_ids }
+}
+
+output "standalone_instance_ids" {
+  value = data.awsutils_ec2_instances_cross_referenced_with_asg.running.standalone_instance_ids
+}
@@ -0,0 +1,49 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Group the running instances by cost center
+data "awsutils_ec2_instances_grouped_by_tag" "by_cost_center" {
+  group_by_tag_key = "CostCenter"
+
+  filter {
+    name   = "instance-state-name"
+    values = ["running"]
+  }
+}
+
+output "instance_ids_by_cost_center" {
+  value = { for group in data.awsutils_ec2_instances_grouped_by_tag.by_cost_center.groups : group.tag_value => group.instance_ids }
+}
+
+output "instance_ids_without_cost_center" {
+  value = data.awsutils_ec2_instances_grouped_by_tag.by_cost_center.missing_tag_instance_ids
+}
+
+# Group the production instances by team, whether they are tagged prod, Prod or PROD
+data "awsutils_ec2_instances_grouped_by_tag" "production_by_team" {
+  group_by_tag_key = "Team"
+
+  tags = {
+    Environment = "prod"
+  }
+  case_insensitive = true
+}
+
+# Group the instances which are running or stopped, but not terminated, by owner
+data "awsutils_ec2_instances_grouped_by_tag" "by_owner" {
+  group_by_tag_key = "Owner"
+  states           = ["running", "stopped"]
+}
@@ -0,0 +1,44 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Find the running production instances whose tags drifted from the tagging policy,
+# tolerating the tags the policy does not cover
+data "awsutils_ec2_instances_with_drifted_tags" "production" {
+  tags = {
+    Environment = "prod"
+  }
+
+  desired_tags = {
+    Environment = "prod"
+    Team        = "platform"
+    CostCenter  = "1234"
+  }
+
+  ignore_extra_tags = true
+
+  filter {
+    name   = "instance-state-name"
+    values = ["running"]
+  }
+}
+
+output "drifted_instance_ids" {
+  value = data.awsutils_ec2_instances_with_drifted_tags.production.instance_ids
+}
+
+output "drift" {
+  value = jsondecode(data.awsutils_ec2_instances_with_drifted_tags.production.drift_json)
+}
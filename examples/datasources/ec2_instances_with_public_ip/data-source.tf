@@ -0,0 +1,29 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# List the running instances of a VPC reachable from the Internet
+data "awsutils_ec2_instances_with_public_ip" "default" {
+  vpc_id = "vpc-0123456789abcdef0"
+
+  filter {
+    name   = "instance-state-name"
+    values = ["running"]
+  }
+}
+
+output "exposed_instance_ids" {
+  value = data.awsutils_ec2_instances_with_public_ip.default.instance_ids
+}
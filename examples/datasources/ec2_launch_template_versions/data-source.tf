@@ -0,0 +1,28 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Look up the versions of a Launch Template to roll back to the one before the latest
+data "awsutils_ec2_launch_template_versions" "web" {
+  launch_template_name = "web"
+}
+
+locals {
+  version_numbers = data.awsutils_ec2_launch_template_versions.web.version_numbers
+}
+
+output "rollback_version_number" {
+  value = length(local.version_numbers) > 1 ? local.version_numbers[length(local.version_numbers) - 2] : null
+}
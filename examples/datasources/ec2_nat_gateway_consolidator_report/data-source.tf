@@ -0,0 +1,32 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Report on the NAT Gateways of the development VPC
+data "awsutils_ec2_nat_gateway_consolidator_report" "dev" {
+  name = "dev"
+}
+
+output "redundant_availability_zones" {
+  value = data.awsutils_ec2_nat_gateway_consolidator_report.dev.redundant_availability_zones
+}
+
+output "cross_az_routes" {
+  value = [for route in data.awsutils_ec2_nat_gateway_consolidator_report.dev.cross_az_routes : "${route.subnet_id} (${route.subnet_availability_zone}) -> ${route.nat_gateway_id} (${route.nat_gateway_availability_zone})"]
+}
+
+output "unused_nat_gateway_ids" {
+  value = data.awsutils_ec2_nat_gateway_consolidator_report.dev.unused_nat_gateway_ids
+}
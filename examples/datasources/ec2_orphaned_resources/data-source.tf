@@ -0,0 +1,37 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Report the orphaned resources of a VPC, leaving out the regional Volumes and Elastic IPs
+data "awsutils_ec2_orphaned_resources" "vpc" {
+  vpc_id = "vpc-0123456789abcdef0"
+
+  include_volumes     = false
+  include_elastic_ips = false
+
+  # Leave out the Network Interfaces managed by AWS services, which cannot be deleted
+  requester_managed = false
+}
+
+# Report the orphaned resources of the region belonging to a team
+data "awsutils_ec2_orphaned_resources" "team" {
+  tags = {
+    Team = "platform"
+  }
+}
+
+output "detached_network_interface_ids" {
+  value = data.awsutils_ec2_orphaned_resources.vpc.network_interfaces[*].network_interface_id
+}
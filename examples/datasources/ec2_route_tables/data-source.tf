@@ -0,0 +1,46 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Look up the main Route Table of a VPC
+data "awsutils_ec2_route_tables" "main" {
+  vpc_id                = "vpc-0123456789abcdef0"
+  main_route_table_only = true
+}
+
+output "main_route_table_id" {
+  value = one(data.awsutils_ec2_route_tables.main.route_table_ids)
+}
+
+# Look up the Route Tables of each tier of a VPC with a single data source
+data "awsutils_ec2_route_tables" "tiers" {
+  vpc_id = "vpc-0123456789abcdef0"
+
+  dynamic "filter_group" {
+    for_each = toset(["public", "private", "database"])
+
+    content {
+      label = filter_group.value
+
+      tags = {
+        Tier = filter_group.value
+      }
+    }
+  }
+}
+
+output "route_table_ids_by_tier" {
+  value = { for group in data.awsutils_ec2_route_tables.tiers.filter_group_results : group.label => group.ids }
+}
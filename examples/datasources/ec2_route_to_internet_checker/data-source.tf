@@ -0,0 +1,27 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Find the Subnets of a VPC which are reachable from the internet
+data "awsutils_ec2_route_to_internet_checker" "default" {
+  filter {
+    name   = "vpc-id"
+    values = ["vpc-0123456789abcdef0"]
+  }
+}
+
+output "public_subnet_ids" {
+  value = data.awsutils_ec2_route_to_internet_checker.default.public_subnet_ids
+}
@@ -0,0 +1,30 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Find the Security Groups of a VPC with identical rules
+data "awsutils_ec2_sg_consolidation_candidates" "default" {
+  filter {
+    name   = "vpc-id"
+    values = ["vpc-0123456789abcdef0"]
+  }
+}
+
+output "redundant_group_ids" {
+  value = flatten([
+    for candidate in data.awsutils_ec2_sg_consolidation_candidates.default.candidates :
+    [for group in candidate.security_groups : group.group_id if group.group_id != candidate.suggested_group_id]
+  ])
+}
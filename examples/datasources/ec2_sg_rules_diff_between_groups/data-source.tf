@@ -0,0 +1,25 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Compare the rules of the web Security Groups of the staging and production environments
+data "awsutils_ec2_sg_rules_diff_between_groups" "default" {
+  security_group_id       = "sg-0123456789abcdef0"
+  other_security_group_id = "sg-0fedcba9876543210"
+}
+
+output "only_in_staging" {
+  value = data.awsutils_ec2_sg_rules_diff_between_groups.default.only_in_security_group
+}
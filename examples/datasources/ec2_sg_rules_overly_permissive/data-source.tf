@@ -0,0 +1,27 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Report the risky ingress rules of all Security Groups in a VPC
+data "awsutils_ec2_sg_rules_overly_permissive" "default" {
+  filter {
+    name   = "vpc-id"
+    values = ["vpc-0123456789abcdef0"]
+  }
+}
+
+output "risky_rules" {
+  value = data.awsutils_ec2_sg_rules_overly_permissive.default.rules
+}
@@ -0,0 +1,59 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Find the subnet of the VPC containing an IP address
+data "awsutils_ec2_subnets" "default" {
+  vpc_id      = "vpc-0123456789abcdef0"
+  contains_ip = "10.0.1.25"
+}
+
+output "subnet_id" {
+  value = one(data.awsutils_ec2_subnets.default.subnet_ids)
+}
+
+# List the subnets of a VPC shared with this account through RAM, with their owners
+data "awsutils_ec2_subnets" "shared" {
+  vpc_id         = "vpc-0fedcba9876543210"
+  include_shared = true
+}
+
+output "subnet_owners" {
+  value = { for subnet in data.awsutils_ec2_subnets.shared.subnets : subnet.subnet_id => subnet.owner_id }
+}
+
+# Reuse the filters applied by one data source in another
+data "awsutils_ec2_subnets" "private" {
+  tags = {
+    Tier = "private"
+  }
+}
+
+data "awsutils_ec2_subnets" "private_again" {
+  dynamic "filter" {
+    for_each = data.awsutils_ec2_subnets.private.filters_object
+    content {
+      name     = filter.value.name
+      values   = filter.value.values
+      wildcard = filter.value.wildcard
+    }
+  }
+}
+
+# Read the Subnets of a list which may contain deleted Subnets, leaving those out rather than failing
+data "awsutils_ec2_subnets" "known" {
+  ids        = ["subnet-0123456789abcdef0", "subnet-0fedcba9876543210"]
+  ids_strict = false
+}
@@ -0,0 +1,29 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# List the instances, volumes, network interfaces and security groups of the platform team
+data "awsutils_ec2_tagged_resources" "default" {
+  resource_types    = ["instance", "volume", "network-interface", "security-group"]
+  continue_on_error = true
+
+  tags = {
+    Team = "platform"
+  }
+}
+
+output "resources" {
+  value = data.awsutils_ec2_tagged_resources.default.resources
+}
@@ -0,0 +1,31 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Estimate the monthly cost of the unattached volumes tagged for a given team
+data "awsutils_ec2_unattached_volumes_cost_estimate" "default" {
+  tags = {
+    Team = "platform"
+  }
+
+  # Override the default price of gp3 storage
+  storage_price_per_gb_month = {
+    gp3 = 0.088
+  }
+}
+
+output "unattached_volumes_monthly_cost" {
+  value = data.awsutils_ec2_unattached_volumes_cost_estimate.default.total_monthly_cost
+}
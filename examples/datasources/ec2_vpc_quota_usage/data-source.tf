@@ -0,0 +1,22 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Report the VPC quotas of the region which are over 80% utilized
+data "awsutils_ec2_vpc_quota_usage" "default" {}
+
+output "quotas_near_limit" {
+  value = [for quota in data.awsutils_ec2_vpc_quota_usage.default.quotas : quota.name if quota.utilization > 0.8]
+}
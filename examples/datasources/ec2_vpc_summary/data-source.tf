@@ -0,0 +1,24 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Summarize the topology of the VPC tagged as the production VPC
+data "awsutils_ec2_vpc_summary" "default" {
+  name = "prod"
+}
+
+output "private_subnet_ids" {
+  value = [for subnet in data.awsutils_ec2_vpc_summary.default.subnets : subnet.subnet_id if !subnet.map_public_ip_on_launch]
+}
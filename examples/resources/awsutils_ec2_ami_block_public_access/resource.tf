@@ -0,0 +1,21 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Block public sharing of the AMIs of the account, and unblock it again when destroyed
+resource "awsutils_ec2_ami_block_public_access" "default" {
+  state              = "block-new-sharing"
+  disable_on_destroy = true
+}
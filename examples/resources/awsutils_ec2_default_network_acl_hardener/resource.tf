@@ -0,0 +1,46 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Replace the allow-all entries of the default Network ACL of the production VPCs, only allowing HTTPS from within the
+# VPCs and the return traffic. Subnets relying on the default Network ACL are reported as a warning.
+resource "awsutils_ec2_default_network_acl_hardener" "production" {
+  tags = {
+    Environment = "production"
+  }
+
+  rule {
+    rule_number = 100
+    protocol    = "tcp"
+    rule_action = "allow"
+    cidr_block  = "10.0.0.0/8"
+    from_port   = 443
+    to_port     = 443
+  }
+
+  rule {
+    rule_number = 100
+    egress      = true
+    protocol    = "tcp"
+    rule_action = "allow"
+    cidr_block  = "10.0.0.0/8"
+    from_port   = 1024
+    to_port     = 65535
+  }
+}
+
+output "associated_subnet_ids" {
+  value = awsutils_ec2_default_network_acl_hardener.production.associated_subnet_ids
+}
@@ -0,0 +1,24 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Recreate the default VPC, deleting it again when this resource is destroyed
+resource "awsutils_ec2_default_vpc_recreate" "default" {
+  delete_on_destroy = true
+}
+
+output "default_vpc_id" {
+  value = awsutils_ec2_default_vpc_recreate.default.vpc_id
+}
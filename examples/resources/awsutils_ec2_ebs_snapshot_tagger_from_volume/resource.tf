@@ -0,0 +1,24 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Copy the cost allocation tags of their source Volume onto all the Snapshots of the account
+resource "awsutils_ec2_ebs_snapshot_tagger_from_volume" "default" {
+  tag_keys = ["CostCenter", "Team"]
+
+  fallback_tags = {
+    CostCenter = "unallocated"
+  }
+}
@@ -0,0 +1,23 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Tag every Elastic IP of the account for cost allocation
+resource "awsutils_ec2_elastic_ip_tagger" "cost_allocation" {
+  desired_tags = {
+    CostCenter = "1234"
+    Team       = "platform"
+  }
+}
@@ -0,0 +1,26 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Enable the detailed monitoring of the production instances, including the stopped ones
+resource "awsutils_ec2_instance_detailed_monitoring_enforcer" "default" {
+  tags = {
+    Environment = "production"
+  }
+}
+
+output "changed_instance_ids" {
+  value = awsutils_ec2_instance_detailed_monitoring_enforcer.default.changed_instance_ids
+}
@@ -0,0 +1,25 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Reboot the production instances with a pending scheduled reboot, two at a time
+resource "awsutils_ec2_instance_reboot_scheduler" "prod" {
+  tags = {
+    Environment = "prod"
+  }
+
+  event_codes     = ["system-reboot"]
+  max_concurrency = 2
+}
@@ -0,0 +1,28 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Make sure the root volumes of the production instances are deleted along with them
+resource "awsutils_ec2_instance_root_volume_deleteontermination_enforcer" "production" {
+  tags = {
+    Environment = "production"
+  }
+
+  dry_run = true
+}
+
+output "instances_keeping_their_root_volume" {
+  value = awsutils_ec2_instance_root_volume_deleteontermination_enforcer.production.changed_instance_ids
+}
@@ -0,0 +1,31 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Stop the development instances outside of the business hours, protecting them from termination while stopped
+resource "awsutils_ec2_instance_stop_protection_scheduler" "dev" {
+  tags = {
+    Environment = "dev"
+  }
+
+  business_hours {
+    days       = ["mon", "tue", "wed", "thu", "fri"]
+    start_time = "08:00"
+    end_time   = "19:00"
+    time_zone  = "Europe/Paris"
+  }
+
+  dry_run = true
+}
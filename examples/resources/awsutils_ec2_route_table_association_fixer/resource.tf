@@ -0,0 +1,29 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo,
+      # and uncomment the version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Associate the private Subnets of the VPC with the private Route Table, including those which fell back to the main
+# Route Table, and one Subnet with its own Route Table
+resource "awsutils_ec2_route_table_association_fixer" "default" {
+  route_table_id = "rtb-0123456789abcdef0"
+
+  tags = {
+    Tier = "private"
+  }
+
+  subnet_route_table_ids = {
+    "subnet-0123456789abcdef0" = "rtb-0fedcba9876543210"
+  }
+}
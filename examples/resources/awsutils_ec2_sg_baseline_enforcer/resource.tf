@@ -0,0 +1,43 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Only allow DNS egress to the VPC resolver from the Security Groups of the VPC, instead of all egress
+resource "awsutils_ec2_sg_baseline_enforcer" "dns_only" {
+  filter {
+    name   = "vpc-id"
+    values = ["vpc-0123456789abcdef0"]
+  }
+
+  baseline_egress_rule {
+    protocol    = "udp"
+    from_port   = 53
+    to_port     = 53
+    cidr_ipv4   = "10.0.0.2/32"
+    description = "DNS"
+  }
+
+  baseline_egress_rule {
+    protocol    = "tcp"
+    from_port   = 53
+    to_port     = 53
+    cidr_ipv4   = "10.0.0.2/32"
+    description = "DNS"
+  }
+}
+
+output "planned_changes" {
+  value = awsutils_ec2_sg_baseline_enforcer.dns_only.planned_changes
+}
@@ -0,0 +1,40 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Reconcile the rules of a Security Group with a rule set exported from another tool, reporting the changes only
+resource "awsutils_ec2_sg_rule_importer_from_json" "web" {
+  security_group_id = "sg-0123456789abcdef0"
+
+  rules_json = jsonencode({
+    ingress = [
+      {
+        protocol    = "tcp"
+        from_port   = 443
+        to_port     = 443
+        cidr_ipv4   = "0.0.0.0/0"
+        description = "HTTPS"
+      },
+    ]
+    egress = [
+      {
+        protocol  = "-1"
+        cidr_ipv4 = "10.0.0.0/8"
+      },
+    ]
+  })
+
+  dry_run = true
+}
@@ -0,0 +1,30 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Encode the owning team and ticket of each Security Group in the descriptions of its rules
+resource "awsutils_ec2_sg_rule_tag_sync" "default" {
+  filter {
+    name   = "vpc-id"
+    values = ["vpc-0123456789abcdef0"]
+  }
+
+  description_mapping = {
+    Team   = "team={value}"
+    Ticket = "ticket={value}"
+  }
+
+  include_egress = true
+}
@@ -0,0 +1,53 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Report the Security Groups of the VPC used by nothing, not even the rules of other Security Groups, without deleting
+# them, sparing those tagged DoNotDelete
+resource "awsutils_ec2_sg_unused_deleter" "default" {
+  filter {
+    name   = "vpc-id"
+    values = ["vpc-0123456789abcdef0"]
+  }
+
+  protect_tag           = "DoNotDelete"
+  check_rule_references = true
+  dry_run               = true
+}
+
+output "planned_changes" {
+  value = awsutils_ec2_sg_unused_deleter.default.planned_changes
+}
+
+output "deleted_group_ids" {
+  value = awsutils_ec2_sg_unused_deleter.default.deleted_group_ids
+}
+
+# Delete the unused Security Groups of the staging VPC only once reviewed: the first apply reports them and exposes
+# the token, which is then copied to confirmation_token. Any change of the Security Groups to delete changes the token,
+# requiring another review.
+resource "awsutils_ec2_sg_unused_deleter" "staging" {
+  filter {
+    name   = "vpc-id"
+    values = ["vpc-0fedcba9876543210"]
+  }
+
+  require_confirmation_token = true
+  confirmation_token         = "" # Set to the value of expected_confirmation_token once reviewed
+}
+
+output "expected_confirmation_token" {
+  value = awsutils_ec2_sg_unused_deleter.staging.expected_confirmation_token
+}
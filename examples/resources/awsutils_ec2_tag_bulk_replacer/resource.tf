@@ -0,0 +1,33 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Rename the Team tag key to team on the instances, volumes and security groups of the account
+resource "awsutils_ec2_tag_bulk_replacer" "team" {
+  resource_types = ["instance", "volume", "security-group"]
+  old_key        = "Team"
+  new_key        = "team"
+
+  # Review the planned changes before setting this to false
+  dry_run = true
+
+  # Warn above 500 resources, and refuse to rename the key on more than 2000
+  warn_if_matches_over = 500
+  fail_if_matches_over = 2000
+}
+
+output "retagged_resource_ids" {
+  value = [for change in awsutils_ec2_tag_bulk_replacer.team.planned_changes : change.resource_id if change.action != "none"]
+}
@@ -0,0 +1,21 @@
+terraform {
+  required_providers {
+    awsutils = {
+      source = "cloudposse/awsutils"
+      # For local development,
+      # install the provider on local computer by running `make install` from the root of the repo, and uncomment the 
+      # version below
+      # version = "9999.99.99"
+    }
+  }
+}
+
+provider "awsutils" {
+  region = "us-east-1"
+}
+
+# Ensure every VPC in the account/region publishes Flow Logs to an S3 bucket
+resource "awsutils_ec2_vpc_flow_log_enforcer" "default" {
+  log_destination_type = "s3"
+  log_destination      = "arn:aws:s3:::my-flow-logs-bucket"
+}
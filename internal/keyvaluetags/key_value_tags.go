@@ -55,6 +55,24 @@ func (tags KeyValueTags) IgnoreAws() KeyValueTags {
 	return result
 }
 
+// IgnoreAwsExcept returns non-AWS tag keys and the given allowed AWS tag keys.
+func (tags KeyValueTags) IgnoreAwsExcept(allowedKeys ...string) KeyValueTags {
+	result := make(KeyValueTags)
+
+	allowed := make(map[string]bool, len(allowedKeys))
+	for _, k := range allowedKeys {
+		allowed[k] = true
+	}
+
+	for k, v := range tags {
+		if !strings.HasPrefix(k, AwsTagKeyPrefix) || allowed[k] {
+			result[k] = v
+		}
+	}
+
+	return result
+}
+
 // GetTags is convenience method that returns the DefaultConfig's Tags, if any
 func (dc *DefaultConfig) GetTags() KeyValueTags {
 	if dc == nil {
@@ -397,6 +415,32 @@ func (tags KeyValueTags) Updated(newTags KeyValueTags) KeyValueTags {
 	return result
 }
 
+// Diff returns the tags of other not in tags, added, those of tags not in
+// other, removed, and those in both with a different value, changed, with
+// their value in other. Nil tags are handled as empty.
+func (tags KeyValueTags) Diff(other KeyValueTags) (added, removed, changed KeyValueTags) {
+	added = make(KeyValueTags)
+	removed = make(KeyValueTags)
+	changed = make(KeyValueTags)
+
+	for k, v := range tags {
+		if _, ok := other[k]; !ok {
+			removed[k] = v
+		}
+	}
+
+	for k, otherV := range other {
+		v, ok := tags[k]
+		if !ok {
+			added[k] = otherV
+		} else if !v.Equal(otherV) {
+			changed[k] = otherV
+		}
+	}
+
+	return added, removed, changed
+}
+
 // Chunks returns a slice of KeyValueTags, each of the specified size.
 func (tags KeyValueTags) Chunks(size int) []KeyValueTags {
 	result := []KeyValueTags{}
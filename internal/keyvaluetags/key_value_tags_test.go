@@ -347,6 +347,62 @@ func TestKeyValueTagsIgnoreAws(t *testing.T) {
 	}
 }
 
+func TestKeyValueTagsIgnoreAwsExcept(t *testing.T) {
+	testCases := []struct {
+		name        string
+		tags        KeyValueTags
+		allowedKeys []string
+		want        map[string]string
+	}{
+		{
+			name: "empty",
+			tags: New(map[string]string{}),
+			want: map[string]string{},
+		},
+		{
+			name: "no allowed keys",
+			tags: New(map[string]string{
+				"aws:ec2spot:fleet-request-id": "sfr-1",
+				"key2":                         "value2",
+			}),
+			want: map[string]string{
+				"key2": "value2",
+			},
+		},
+		{
+			name: "allowed key",
+			tags: New(map[string]string{
+				"aws:cloudformation:key1":      "value1",
+				"aws:ec2spot:fleet-request-id": "sfr-1",
+				"key3":                         "value3",
+			}),
+			allowedKeys: []string{"aws:ec2spot:fleet-request-id"},
+			want: map[string]string{
+				"aws:ec2spot:fleet-request-id": "sfr-1",
+				"key3":                         "value3",
+			},
+		},
+		{
+			name: "allowed key missing",
+			tags: New(map[string]string{
+				"key1": "value1",
+			}),
+			allowedKeys: []string{"aws:ec2spot:fleet-request-id"},
+			want: map[string]string{
+				"key1": "value1",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := testCase.tags.IgnoreAwsExcept(testCase.allowedKeys...)
+
+			testKeyValueTagsVerifyMap(t, got.Map(), testCase.want)
+		})
+	}
+}
+
 func TestKeyValueTagsIgnoreConfig(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -1647,6 +1703,114 @@ func TestKeyValueTagsUpdated(t *testing.T) {
 	}
 }
 
+func TestKeyValueTagsDiff(t *testing.T) {
+	testCases := []struct {
+		name        string
+		tags        KeyValueTags
+		other       KeyValueTags
+		wantAdded   map[string]string
+		wantRemoved map[string]string
+		wantChanged map[string]string
+	}{
+		{
+			name:        "nil",
+			wantAdded:   map[string]string{},
+			wantRemoved: map[string]string{},
+			wantChanged: map[string]string{},
+		},
+		{
+			name: "added_only",
+			tags: nil,
+			other: New(map[string]string{
+				"key1": "value1",
+				"key2": "value2",
+			}),
+			wantAdded: map[string]string{
+				"key1": "value1",
+				"key2": "value2",
+			},
+			wantRemoved: map[string]string{},
+			wantChanged: map[string]string{},
+		},
+		{
+			name: "removed_only",
+			tags: New(map[string]string{
+				"key1": "value1",
+				"key2": "value2",
+			}),
+			other:     New(map[string]string{}),
+			wantAdded: map[string]string{},
+			wantRemoved: map[string]string{
+				"key1": "value1",
+				"key2": "value2",
+			},
+			wantChanged: map[string]string{},
+		},
+		{
+			name: "changed_value",
+			tags: New(map[string]string{
+				"key1": "value1",
+				"key2": "value2",
+			}),
+			other: New(map[string]string{
+				"key1": "value1updated",
+				"key2": "value2",
+			}),
+			wantAdded:   map[string]string{},
+			wantRemoved: map[string]string{},
+			wantChanged: map[string]string{
+				"key1": "value1updated",
+			},
+		},
+		{
+			name: "mixed",
+			tags: New(map[string]string{
+				"key1": "value1",
+				"key2": "value2",
+				"key3": "value3",
+			}),
+			other: New(map[string]string{
+				"key1": "value1",
+				"key2": "value2updated",
+				"key4": "value4",
+			}),
+			wantAdded: map[string]string{
+				"key4": "value4",
+			},
+			wantRemoved: map[string]string{
+				"key3": "value3",
+			},
+			wantChanged: map[string]string{
+				"key2": "value2updated",
+			},
+		},
+		{
+			name: "identical",
+			tags: New(map[string]string{
+				"key1": "value1",
+				"key2": "value2",
+			}),
+			other: New(map[string]string{
+				"key1": "value1",
+				"key2": "value2",
+			}),
+			wantAdded:   map[string]string{},
+			wantRemoved: map[string]string{},
+			wantChanged: map[string]string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			added, removed, changed := testCase.tags.Diff(testCase.other)
+
+			testKeyValueTagsVerifyMap(t, added.Map(), testCase.wantAdded)
+			testKeyValueTagsVerifyMap(t, removed.Map(), testCase.wantRemoved)
+			testKeyValueTagsVerifyMap(t, changed.Map(), testCase.wantChanged)
+		})
+	}
+}
+
 func TestKeyValueTagsChunks(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/accessanalyzer"
 	"github.com/aws/aws-sdk-go/service/acm"
 	"github.com/aws/aws-sdk-go/service/acmpca"
@@ -210,6 +211,13 @@ type Config struct {
 	SkipMetadataApiCheck    bool
 	S3ForcePathStyle        bool
 
+	EscapeFilterWildcards bool
+	MaxResultsCap         int
+	DescribeMaxRetries    int
+	MaxFilterValues       int
+	MaxFilters            int
+	ValidateOnly          bool
+
 	terraformVersion string
 }
 
@@ -262,6 +270,7 @@ type AWSClient struct {
 	datasyncconn                        *datasync.DataSync
 	daxconn                             *dax.DAX
 	DefaultTagsConfig                   *keyvaluetags.DefaultConfig
+	describeMaxRetries                  int
 	detectiveconn                       *detective.Detective
 	devicefarmconn                      *devicefarm.DeviceFarm
 	dlmconn                             *dlm.DLM
@@ -272,6 +281,7 @@ type AWSClient struct {
 	dxconn                              *directconnect.DirectConnect
 	dynamodbconn                        *dynamodb.DynamoDB
 	ec2conn                             *ec2.EC2
+	ec2connForRegion                    func(region string) *ec2.EC2
 	ecrconn                             *ecr.ECR
 	ecrpublicconn                       *ecrpublic.ECRPublic
 	ecsconn                             *ecs.ECS
@@ -284,6 +294,7 @@ type AWSClient struct {
 	elbv2conn                           *elbv2.ELBV2
 	emrconn                             *emr.EMR
 	emrcontainersconn                   *emrcontainers.EMRContainers
+	escapeFilterWildcards               bool
 	esconn                              *elasticsearch.ElasticsearchService
 	firehoseconn                        *firehose.Firehose
 	fmsconn                             *fms.FMS
@@ -319,6 +330,9 @@ type AWSClient struct {
 	macie2conn                          *macie2.Macie2
 	managedblockchainconn               *managedblockchain.ManagedBlockchain
 	marketplacecatalogconn              *marketplacecatalog.MarketplaceCatalog
+	maxFilterValues                     int
+	maxFilters                          int
+	maxResultsCap                       int
 	mediaconnectconn                    *mediaconnect.MediaConnect
 	mediaconvertconn                    *mediaconvert.MediaConvert
 	mediaconvertaccountconn             *mediaconvert.MediaConvert
@@ -368,6 +382,7 @@ type AWSClient struct {
 	shieldconn                          *shield.Shield
 	signerconn                          *signer.Signer
 	simpledbconn                        *simpledb.SimpleDB
+	skipRegionValidation                bool
 	snsconn                             *sns.SNS
 	sqsconn                             *sqs.SQS
 	ssmconn                             *ssm.SSM
@@ -380,6 +395,7 @@ type AWSClient struct {
 	terraformVersion                    string
 	timestreamwriteconn                 *timestreamwrite.TimestreamWrite
 	transferconn                        *transfer.Transfer
+	validateOnly                        bool
 	wafconn                             *waf.WAF
 	wafregionalconn                     *wafregional.WAFRegional
 	wafv2conn                           *wafv2.WAFV2
@@ -446,17 +462,28 @@ func (c *Config) Client() (interface{}, error) {
 		},
 	}
 
-	sess, accountID, partition, err := awsbase.GetSessionWithAccountIDAndPartition(awsbaseConfig)
-	if err != nil {
-		return nil, fmt.Errorf("error configuring Terraform AWS Provider: %w", err)
-	}
+	var sess *session.Session
+	var accountID, partition string
+	var err error
+	if c.ValidateOnly {
+		// No request is sent, so the account is neither known nor validated.
+		sess, accountID, partition, err = validateOnlySession(c.Region)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		sess, accountID, partition, err = awsbase.GetSessionWithAccountIDAndPartition(awsbaseConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring Terraform AWS Provider: %w", err)
+		}
 
-	if accountID == "" {
-		log.Printf("[WARN] AWS account ID not found for provider. See https://www.terraform.io/docs/providers/aws/index.html#skip_requesting_account_id for implications.")
-	}
+		if accountID == "" {
+			log.Printf("[WARN] AWS account ID not found for provider. See https://www.terraform.io/docs/providers/aws/index.html#skip_requesting_account_id for implications.")
+		}
 
-	if err := awsbase.ValidateAccountID(accountID, c.AllowedAccountIds, c.ForbiddenAccountIds); err != nil {
-		return nil, err
+		if err := awsbase.ValidateAccountID(accountID, c.AllowedAccountIds, c.ForbiddenAccountIds); err != nil {
+			return nil, err
+		}
 	}
 
 	dnsSuffix := "amazonaws.com"
@@ -513,6 +540,7 @@ func (c *Config) Client() (interface{}, error) {
 		datasyncconn:                        datasync.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["datasync"])})),
 		daxconn:                             dax.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["dax"])})),
 		DefaultTagsConfig:                   c.DefaultTagsConfig,
+		describeMaxRetries:                  c.DescribeMaxRetries,
 		detectiveconn:                       detective.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["detective"])})),
 		devicefarmconn:                      devicefarm.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["devicefarm"])})),
 		dlmconn:                             dlm.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["dlm"])})),
@@ -535,6 +563,7 @@ func (c *Config) Client() (interface{}, error) {
 		elbv2conn:                           elbv2.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["elb"])})),
 		emrconn:                             emr.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["emr"])})),
 		emrcontainersconn:                   emrcontainers.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["emrcontainers"])})),
+		escapeFilterWildcards:               c.EscapeFilterWildcards,
 		esconn:                              elasticsearch.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["es"])})),
 		firehoseconn:                        firehose.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["firehose"])})),
 		fmsconn:                             fms.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["fms"])})),
@@ -569,6 +598,9 @@ func (c *Config) Client() (interface{}, error) {
 		macie2conn:                          macie2.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["macie2"])})),
 		managedblockchainconn:               managedblockchain.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["managedblockchain"])})),
 		marketplacecatalogconn:              marketplacecatalog.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["marketplacecatalog"])})),
+		maxFilterValues:                     c.MaxFilterValues,
+		maxFilters:                          c.MaxFilters,
+		maxResultsCap:                       c.MaxResultsCap,
 		mediaconnectconn:                    mediaconnect.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["mediaconnect"])})),
 		mediaconvertconn:                    mediaconvert.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["mediaconvert"])})),
 		medialiveconn:                       medialive.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["medialive"])})),
@@ -587,7 +619,6 @@ func (c *Config) Client() (interface{}, error) {
 		personalizeconn:                     personalize.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["personalize"])})),
 		prometheusserviceconn:               prometheusservice.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["prometheusservice"])})),
 		pinpointconn:                        pinpoint.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["pinpoint"])})),
-		pricingconn:                         pricing.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["pricing"])})),
 		qldbconn:                            qldb.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["qldb"])})),
 		quicksightconn:                      quicksight.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["quicksight"])})),
 		ramconn:                             ram.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["ram"])})),
@@ -613,6 +644,7 @@ func (c *Config) Client() (interface{}, error) {
 		sfnconn:                             sfn.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["stepfunctions"])})),
 		signerconn:                          signer.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["signer"])})),
 		simpledbconn:                        simpledb.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["sdb"])})),
+		skipRegionValidation:                c.SkipRegionValidation,
 		snsconn:                             sns.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["sns"])})),
 		sqsconn:                             sqs.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["sqs"])})),
 		ssmconn:                             ssm.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["ssm"])})),
@@ -624,6 +656,7 @@ func (c *Config) Client() (interface{}, error) {
 		terraformVersion:                    c.terraformVersion,
 		timestreamwriteconn:                 timestreamwrite.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["timestreamwrite"])})),
 		transferconn:                        transfer.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["transfer"])})),
+		validateOnly:                        c.ValidateOnly,
 		wafconn:                             waf.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["waf"])})),
 		wafregionalconn:                     wafregional.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["wafregional"])})),
 		wafv2conn:                           wafv2.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["wafv2"])})),
@@ -643,6 +676,10 @@ func (c *Config) Client() (interface{}, error) {
 	shieldConfig := &aws.Config{
 		Endpoint: aws.String(c.Endpoints["shield"]),
 	}
+	// The Price List API is only available in some regions, but returns the prices of every region.
+	pricingConfig := &aws.Config{
+		Endpoint: aws.String(c.Endpoints["pricing"]),
+	}
 
 	// Services that require multiple client configurations
 	s3Config := &aws.Config{
@@ -659,6 +696,7 @@ func (c *Config) Client() (interface{}, error) {
 	switch partition {
 	case endpoints.AwsPartitionID:
 		globalAcceleratorConfig.Region = aws.String(endpoints.UsWest2RegionID)
+		pricingConfig.Region = aws.String(endpoints.UsEast1RegionID)
 		route53Config.Region = aws.String(endpoints.UsEast1RegionID)
 		shieldConfig.Region = aws.String(endpoints.UsEast1RegionID)
 	case endpoints.AwsCnPartitionID:
@@ -673,6 +711,7 @@ func (c *Config) Client() (interface{}, error) {
 	}
 
 	client.globalacceleratorconn = globalaccelerator.New(sess.Copy(globalAcceleratorConfig))
+	client.pricingconn = pricing.New(sess.Copy(pricingConfig))
 	client.r53conn = route53.New(sess.Copy(route53Config))
 	client.shieldconn = shield.New(sess.Copy(shieldConfig))
 
@@ -768,6 +807,12 @@ func (c *Config) Client() (interface{}, error) {
 		}
 	})
 
+	client.ec2connForRegion = func(region string) *ec2.EC2 {
+		conn := ec2.New(sess.Copy(&aws.Config{Endpoint: aws.String(c.Endpoints["ec2"]), Region: aws.String(region)}))
+		conn.Handlers.Retry = client.ec2conn.Handlers.Copy().Retry
+		return conn
+	}
+
 	client.fmsconn.Handlers.Retry.PushBack(func(r *request.Request) {
 		// Acceptance testing creates and deletes resources in quick succession.
 		// The FMS onboarding process into Organizations is opaque to consumers.
@@ -859,7 +904,7 @@ func (c *Config) Client() (interface{}, error) {
 		}
 	})
 
-	if !c.SkipGetEC2Platforms {
+	if !c.SkipGetEC2Platforms && !c.ValidateOnly {
 		supportedPlatforms, err := GetSupportedEC2Platforms(client.ec2conn)
 		if err != nil {
 			// We intentionally fail *silently* because there's a chance
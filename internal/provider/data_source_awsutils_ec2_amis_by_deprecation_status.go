@@ -0,0 +1,284 @@
+package provider
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2AmisByDeprecationStatusFingerprintAttributes are the attributes of the "deprecated", "deprecating_soon" and
+// "current" AMIs hashed into their fingerprint.
+var ec2AmisByDeprecationStatusFingerprintAttributes = []string{"image_id", "name", "creation_date", "deprecation_time"}
+
+func dataSourceAwsUtilsEc2AmisByDeprecationStatus() *schema.Resource {
+	return &schema.Resource{
+		Description: `Lists the AMIs owned by the current account, matching the given filters, split by the deprecation status
+given by their deprecation time, such as to prioritize the rebuilding of the images Instances are launched from.
+
+An AMI is ` + "`deprecated`" + ` once its deprecation time is reached, ` + "`deprecating_soon`" + ` if it is reached within
+the next ` + "`deprecating_soon_days`" + `, and ` + "`current`" + ` otherwise, including when no deprecation time is set.
+An AMI whose deprecation time cannot be parsed is not reported, since its status is unknown.`,
+		ReadContext:   dataSourceAwsUtilsEc2AmisByDeprecationStatusRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeImage),
+			"ids_strict":        ec2IDsStrictSchema(),
+			"arns":              ec2ARNsSchema(ec2.ResourceTypeImage),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"region":            ec2RegionSchema(),
+			"regex_filter":      ec2RegexFiltersSchema(),
+			"tags":              tagsSchema(),
+			"case_insensitive":  ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":      ec2ExcludeTagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"has_tags":          ec2HasTagsSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"max_results_cap":   maxResultsCapSchema(),
+			"debug":             debugSchema(),
+			"raw_response_json": rawResponseJSONSchema(),
+			"applied_filters":   ec2AppliedFiltersSchema(),
+			"resolved_filters":  ec2ResolvedFiltersSchema(),
+			"filters_object":    ec2FiltersObjectSchema(),
+			"allow_unfiltered":  ec2AllowUnfilteredSchema(),
+			"deprecating_soon_days": {
+				Description:  "The number of days before their deprecation time during which AMIs are `deprecating_soon`. An AMI deprecated exactly this many days from now is `deprecating_soon`, and `0` reports none of them.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"deprecated":       ec2AmisByDeprecationStatusImagesSchema("The AMIs whose deprecation time is now or in the past, ordered by ID."),
+			"deprecating_soon": ec2AmisByDeprecationStatusImagesSchema("The AMIs whose deprecation time is after now and within `deprecating_soon_days` of it, ordered by ID."),
+			"current":          ec2AmisByDeprecationStatusImagesSchema("The AMIs without a deprecation time or whose deprecation time is later than `deprecating_soon_days` from now, ordered by ID."),
+			"deprecated_image_ids": {
+				Description: "The IDs of the `deprecated` AMIs.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"deprecating_soon_image_ids": {
+				Description: "The IDs of the `deprecating_soon` AMIs.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"current_image_ids": {
+				Description: "The IDs of the `current` AMIs.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// ec2AmisByDeprecationStatusImagesSchema returns the *schema.Schema of a list of AMIs of the
+// awsutils_ec2_amis_by_deprecation_status data source with the given description.
+func ec2AmisByDeprecationStatusImagesSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Description: description,
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"image_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"creation_date": {
+					Description: "The time the AMI was created, in RFC 3339 format.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"deprecation_time": {
+					Description: "The time the AMI is deprecated at, in RFC 3339 format, or the empty string if it is not set.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"fingerprint": fingerprintSchema(ec2AmisByDeprecationStatusFingerprintAttributes),
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2AmisByDeprecationStatusRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	accountID := meta.(*AWSClient).accountid
+	window := time.Duration(d.Get("deprecating_soon_days").(int)) * 24 * time.Hour
+
+	input := &ec2.DescribeImagesInput{
+		Owners: aws.StringSlice([]string{ec2OwnerSelf}),
+		// The owner's deprecated AMIs are always listed, but this also lists those given by ID.
+		IncludeDeprecated: aws.Bool(true),
+	}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeImage)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.ImageIds = ids
+	input.Filters = filters
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var images []*ec2.Image
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		images, err = finder.Images(conn, input, maxResultsCap(d, meta))
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 AMIs: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Image)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	owned := make([]*ec2.Image, 0, len(images))
+	for _, image := range images {
+		if excluded(image.Tags) || !tagsMatched(image.Tags) || !regexMatched(image) {
+			continue
+		}
+
+		// Owners already restricts the results, but this guards against an explicit image ID of another account.
+		if accountID != "" && aws.StringValue(image.OwnerId) != accountID {
+			log.Printf("[DEBUG] Skipping EC2 AMI (%s) owned by another account (%s)", aws.StringValue(image.ImageId), aws.StringValue(image.OwnerId))
+			continue
+		}
+
+		owned = append(owned, image)
+	}
+
+	deprecated, deprecatingSoon, current := ec2ImagesByDeprecationStatus(owned, window, time.Now())
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Images": images}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	for _, bucket := range []struct {
+		name   string
+		images []*ec2.Image
+	}{
+		{"deprecated", deprecated},
+		{"deprecating_soon", deprecatingSoon},
+		{"current", current},
+	} {
+		results := make([]map[string]interface{}, 0, len(bucket.images))
+		imageIDs := make([]string, 0, len(bucket.images))
+		for _, image := range bucket.images {
+			results = append(results, flattenEc2ImageDeprecationStatus(image))
+			imageIDs = append(imageIDs, aws.StringValue(image.ImageId))
+		}
+
+		if err := setFingerprints(results, ec2AmisByDeprecationStatusFingerprintAttributes); err != nil {
+			return diag.FromErr(err)
+		}
+
+		if err := d.Set(bucket.name, results); err != nil {
+			return diag.Errorf("error setting %s: %s", bucket.name, err)
+		}
+
+		if err := d.Set(bucket.name+"_image_ids", imageIDs); err != nil {
+			return diag.Errorf("error setting %s_image_ids: %s", bucket.name, err)
+		}
+	}
+
+	return warnings
+}
+
+// ec2ImagesByDeprecationStatus splits the given AMIs, each ordered by ID, into those deprecated at or before now,
+// those deprecated after now and at or before now plus window, and the others, which include the AMIs without a
+// deprecation time. The AMIs whose deprecation time cannot be parsed are in none of them, since their status is
+// unknown.
+func ec2ImagesByDeprecationStatus(images []*ec2.Image, window time.Duration, now time.Time) ([]*ec2.Image, []*ec2.Image, []*ec2.Image) {
+	deprecated := make([]*ec2.Image, 0)
+	deprecatingSoon := make([]*ec2.Image, 0)
+	current := make([]*ec2.Image, 0)
+
+	for _, image := range images {
+		if aws.StringValue(image.DeprecationTime) == "" {
+			current = append(current, image)
+			continue
+		}
+
+		deprecationTime, err := time.Parse(time.RFC3339, aws.StringValue(image.DeprecationTime))
+		if err != nil {
+			log.Printf("[WARN] Unparseable deprecation time of EC2 AMI (%s), skipping it: %q", aws.StringValue(image.ImageId), aws.StringValue(image.DeprecationTime))
+			continue
+		}
+
+		switch {
+		case !deprecationTime.After(now):
+			deprecated = append(deprecated, image)
+		case !deprecationTime.After(now.Add(window)):
+			deprecatingSoon = append(deprecatingSoon, image)
+		default:
+			current = append(current, image)
+		}
+	}
+
+	for _, bucket := range [][]*ec2.Image{deprecated, deprecatingSoon, current} {
+		sort.Slice(bucket, func(i, j int) bool {
+			return aws.StringValue(bucket[i].ImageId) < aws.StringValue(bucket[j].ImageId)
+		})
+	}
+
+	return deprecated, deprecatingSoon, current
+}
+
+// flattenEc2ImageDeprecationStatus returns the "deprecated", "deprecating_soon" or "current" element of the given AMI.
+func flattenEc2ImageDeprecationStatus(image *ec2.Image) map[string]interface{} {
+	var creationDate string
+	if t, err := time.Parse(time.RFC3339, aws.StringValue(image.CreationDate)); err == nil {
+		creationDate = t.UTC().Format(time.RFC3339)
+	}
+
+	var deprecationTime string
+	if t, err := time.Parse(time.RFC3339, aws.StringValue(image.DeprecationTime)); err == nil {
+		deprecationTime = t.UTC().Format(time.RFC3339)
+	}
+
+	return map[string]interface{}{
+		"image_id":         aws.StringValue(image.ImageId),
+		"name":             aws.StringValue(image.Name),
+		"creation_date":    creationDate,
+		"deprecation_time": deprecationTime,
+	}
+}
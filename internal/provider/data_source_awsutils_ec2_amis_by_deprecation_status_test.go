@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEc2ImagesByDeprecationStatus(t *testing.T) {
+	now := time.Date(2021, 6, 30, 0, 0, 0, 0, time.UTC)
+	window := 30 * 24 * time.Hour
+	images := []*ec2.Image{
+		{ImageId: aws.String("ami-00000009"), DeprecationTime: aws.String("2021-01-01T00:00:00.000Z")},
+		{ImageId: aws.String("ami-00000002"), DeprecationTime: aws.String("2021-06-30T00:00:00.000Z")},
+		{ImageId: aws.String("ami-00000003"), DeprecationTime: aws.String("2021-06-30T00:00:01.000Z")},
+		{ImageId: aws.String("ami-00000004"), DeprecationTime: aws.String("2021-07-30T00:00:00.000Z")},
+		{ImageId: aws.String("ami-00000005"), DeprecationTime: aws.String("2021-07-30T00:00:01.000Z")},
+		{ImageId: aws.String("ami-00000006")},
+		{ImageId: aws.String("ami-00000007"), DeprecationTime: aws.String("")},
+		{ImageId: aws.String("ami-00000008"), DeprecationTime: aws.String("next month")},
+	}
+
+	testCases := []struct {
+		Name                    string
+		Window                  time.Duration
+		ExpectedDeprecated      []string
+		ExpectedDeprecatingSoon []string
+		ExpectedCurrent         []string
+	}{
+		{
+			Name:                    "window",
+			Window:                  window,
+			ExpectedDeprecated:      []string{"ami-00000002", "ami-00000009"},
+			ExpectedDeprecatingSoon: []string{"ami-00000003", "ami-00000004"},
+			ExpectedCurrent:         []string{"ami-00000005", "ami-00000006", "ami-00000007"},
+		},
+		{
+			Name:                    "no window",
+			ExpectedDeprecated:      []string{"ami-00000002", "ami-00000009"},
+			ExpectedDeprecatingSoon: []string{},
+			ExpectedCurrent:         []string{"ami-00000003", "ami-00000004", "ami-00000005", "ami-00000006", "ami-00000007"},
+		},
+	}
+
+	imageIDs := func(images []*ec2.Image) []string {
+		ids := make([]string, 0, len(images))
+		for _, image := range images {
+			ids = append(ids, aws.StringValue(image.ImageId))
+		}
+		return ids
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			deprecated, deprecatingSoon, current := ec2ImagesByDeprecationStatus(images, testCase.Window, now)
+
+			if got := imageIDs(deprecated); !reflect.DeepEqual(got, testCase.ExpectedDeprecated) {
+				t.Errorf("got deprecated %v, expected %v", got, testCase.ExpectedDeprecated)
+			}
+			if got := imageIDs(deprecatingSoon); !reflect.DeepEqual(got, testCase.ExpectedDeprecatingSoon) {
+				t.Errorf("got deprecating soon %v, expected %v", got, testCase.ExpectedDeprecatingSoon)
+			}
+			if got := imageIDs(current); !reflect.DeepEqual(got, testCase.ExpectedCurrent) {
+				t.Errorf("got current %v, expected %v", got, testCase.ExpectedCurrent)
+			}
+		})
+	}
+}
+
+func TestFlattenEc2ImageDeprecationStatus(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Image    *ec2.Image
+		Expected map[string]interface{}
+	}{
+		{
+			Name: "deprecation time",
+			Image: &ec2.Image{
+				ImageId:         aws.String("ami-01234567"),
+				Name:            aws.String("my-image"),
+				CreationDate:    aws.String("2021-01-01T00:00:00.000Z"),
+				DeprecationTime: aws.String("2021-07-01T12:00:00.000Z"),
+			},
+			Expected: map[string]interface{}{
+				"image_id":         "ami-01234567",
+				"name":             "my-image",
+				"creation_date":    "2021-01-01T00:00:00Z",
+				"deprecation_time": "2021-07-01T12:00:00Z",
+			},
+		},
+		{
+			Name: "no deprecation time",
+			Image: &ec2.Image{
+				ImageId:      aws.String("ami-01234567"),
+				Name:         aws.String("my-image"),
+				CreationDate: aws.String("2021-01-01T00:00:00.000Z"),
+			},
+			Expected: map[string]interface{}{
+				"image_id":         "ami-01234567",
+				"name":             "my-image",
+				"creation_date":    "2021-01-01T00:00:00Z",
+				"deprecation_time": "",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := flattenEc2ImageDeprecationStatus(testCase.Image); !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package provider
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAwsUtilsEc2AmisByTagWithLatest() *schema.Resource {
+	return &schema.Resource{
+		Description: `Looks up the most recent AMI owned by the current account, or the given ` + "`owner_ids`" + `, matching the
+given filters, such as the latest build of a golden AMI to deploy.
+
+The matching AMIs are ordered by creation date, and the AMI with the lowest ID is selected among those created at
+the same time, so that the result does not depend on the order in which AWS returns them. It is an error for no AMI
+to match unless ` + "`fail_on_empty`" + ` is unset, in which case the attributes of the AMI are left empty.`,
+		ReadContext:   dataSourceAwsUtilsEc2AmisByTagWithLatestRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeImage),
+			"ids_strict":        ec2IDsStrictSchema(),
+			"arns":              ec2ARNsSchema(ec2.ResourceTypeImage),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"region":            ec2RegionSchema(),
+			"regex_filter":      ec2RegexFiltersSchema(),
+			"tags":              tagsSchema(),
+			"case_insensitive":  ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":      ec2ExcludeTagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"has_tags":          ec2HasTagsSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"max_results_cap":   maxResultsCapSchema(),
+			"debug":             debugSchema(),
+			"raw_response_json": rawResponseJSONSchema(),
+			"applied_filters":   ec2AppliedFiltersSchema(),
+			"resolved_filters":  ec2ResolvedFiltersSchema(),
+			"filters_object":    ec2FiltersObjectSchema(),
+			"allow_unfiltered":  ec2AllowUnfilteredSchema(),
+			"owner_ids":         ec2OwnerIDsSchema(),
+			"fail_on_empty": {
+				Description: "Whether it is an error for no AMI to match. It is not in the provider's `validate_only` mode.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			"image_id": {
+				Description: "The ID of the most recent matching AMI, or an empty string if none matches.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"image_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"creation_date": {
+				Description: "The time the AMI was created, in RFC 3339 format.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"architecture": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"platform_details": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"virtualization_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"root_device_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"root_device_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"ena_support": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"owner_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"image_location": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"deprecation_time": {
+				Description: "The time the AMI is deprecated at, if any.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"snapshot_ids": {
+				Description: "The IDs of the EBS Snapshots backing the AMI.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"image_tags": {
+				Description: "The tags of the AMI, except those with the reserved `aws:` prefix.",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"matched_image_ids": {
+				Description: "The IDs of all the matching AMIs, from the most recent to the oldest.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2AmisByTagWithLatestRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Only the AMIs of the current account are matched by default.
+	ownerIDs := []string{ec2OwnerSelf}
+	if v, ok := d.GetOk("owner_ids"); ok && v.(*schema.Set).Len() > 0 {
+		ownerIDs = ExpandStringSliceofPointers(ExpandStringSet(v.(*schema.Set)))
+	}
+
+	input := &ec2.DescribeImagesInput{}
+	owners, ownerFilters, err := buildEC2OwnerSelection(ec2.ResourceTypeImage, ownerIDs, meta.(*AWSClient).accountid)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.Owners = owners
+
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeImage)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.ImageIds = ids
+	input.Filters = append(filters, ownerFilters...)
+
+	// The owner filters only narrow down the owners of the AMIs, so only the selection filters are checked.
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var images []*ec2.Image
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		images, err = finder.Images(conn, input, maxResultsCap(d, meta))
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 AMIs: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Image)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	kept := images[:0]
+	for _, image := range images {
+		if !excluded(image.Tags) && tagsMatched(image.Tags) && regexMatched(image) {
+			kept = append(kept, image)
+		}
+	}
+	images = kept
+
+	sorted := sortEc2ImagesByCreationDate(images)
+
+	if len(sorted) == 0 && d.Get("fail_on_empty").(bool) && !meta.(*AWSClient).validateOnly {
+		return diag.Errorf("no EC2 AMI matches the given filters")
+	}
+
+	matchedImageIDs := make([]string, 0, len(sorted))
+	for _, image := range sorted {
+		matchedImageIDs = append(matchedImageIDs, aws.StringValue(image.ImageId))
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Images": images}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	var latest *ec2.Image
+	if len(sorted) > 0 {
+		latest = sorted[0]
+		log.Printf("[DEBUG] Most recent of %d matching EC2 AMIs: %s", len(sorted), aws.StringValue(latest.ImageId))
+	}
+
+	for k, v := range flattenEc2LatestImage(latest) {
+		if err := d.Set(k, v); err != nil {
+			return diag.Errorf("error setting %s: %s", k, err)
+		}
+	}
+
+	if err := d.Set("matched_image_ids", matchedImageIDs); err != nil {
+		return diag.Errorf("error setting matched_image_ids: %s", err)
+	}
+
+	return warnings
+}
+
+// sortEc2ImagesByCreationDate returns a copy of the given AMIs ordered from the most recent to the oldest, the
+// AMIs created at the same time being ordered by ID. AMIs whose creation date cannot be parsed come last.
+func sortEc2ImagesByCreationDate(images []*ec2.Image) []*ec2.Image {
+	sorted := make([]*ec2.Image, 0, len(images))
+	creationTimes := make(map[*ec2.Image]time.Time, len(images))
+
+	for _, image := range images {
+		if image == nil {
+			continue
+		}
+
+		creationTime, err := time.Parse(time.RFC3339, aws.StringValue(image.CreationDate))
+		if err != nil {
+			log.Printf("[WARN] Unparseable creation date of EC2 AMI (%s): %q", aws.StringValue(image.ImageId), aws.StringValue(image.CreationDate))
+		}
+
+		creationTimes[image] = creationTime
+		sorted = append(sorted, image)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if ti, tj := creationTimes[sorted[i]], creationTimes[sorted[j]]; !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return aws.StringValue(sorted[i].ImageId) < aws.StringValue(sorted[j].ImageId)
+	})
+
+	return sorted
+}
+
+// flattenEc2LatestImage returns the values of the attributes describing the given AMI, or their zero values if
+// it is nil.
+func flattenEc2LatestImage(image *ec2.Image) map[string]interface{} {
+	if image == nil {
+		image = &ec2.Image{}
+	}
+
+	var creationDate string
+	if t, err := time.Parse(time.RFC3339, aws.StringValue(image.CreationDate)); err == nil {
+		creationDate = t.UTC().Format(time.RFC3339)
+	}
+
+	snapshotIDs := ec2ImageSnapshotIDs(image)
+	if snapshotIDs == nil {
+		snapshotIDs = []string{}
+	}
+
+	return map[string]interface{}{
+		"image_id":            aws.StringValue(image.ImageId),
+		"image_name":          aws.StringValue(image.Name),
+		"description":         aws.StringValue(image.Description),
+		"creation_date":       creationDate,
+		"architecture":        aws.StringValue(image.Architecture),
+		"platform_details":    aws.StringValue(image.PlatformDetails),
+		"virtualization_type": aws.StringValue(image.VirtualizationType),
+		"root_device_type":    aws.StringValue(image.RootDeviceType),
+		"root_device_name":    aws.StringValue(image.RootDeviceName),
+		"ena_support":         aws.BoolValue(image.EnaSupport),
+		"state":               aws.StringValue(image.State),
+		"owner_id":            aws.StringValue(image.OwnerId),
+		"image_location":      aws.StringValue(image.ImageLocation),
+		"deprecation_time":    aws.StringValue(image.DeprecationTime),
+		"snapshot_ids":        snapshotIDs,
+		"image_tags":          keyvaluetags.Ec2KeyValueTags(image.Tags).IgnoreAws().Map(),
+	}
+}
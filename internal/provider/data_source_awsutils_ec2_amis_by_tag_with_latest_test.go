@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestSortEc2ImagesByCreationDate(t *testing.T) {
+	image := func(id, creationDate string) *ec2.Image {
+		return &ec2.Image{ImageId: aws.String(id), CreationDate: aws.String(creationDate)}
+	}
+
+	testCases := []struct {
+		Name     string
+		Images   []*ec2.Image
+		Expected []string
+	}{
+		{
+			Name:     "none",
+			Expected: []string{},
+		},
+		{
+			Name: "most recent first",
+			Images: []*ec2.Image{
+				image("ami-00000001", "2021-01-01T00:00:00.000Z"),
+				image("ami-00000002", "2021-03-01T00:00:00.000Z"),
+				image("ami-00000003", "2021-02-01T00:00:00.000Z"),
+			},
+			Expected: []string{"ami-00000002", "ami-00000003", "ami-00000001"},
+		},
+		{
+			Name: "ties broken by ID",
+			Images: []*ec2.Image{
+				image("ami-0000000c", "2021-03-01T00:00:00.000Z"),
+				image("ami-0000000a", "2021-03-01T00:00:00.000Z"),
+				image("ami-0000000b", "2021-03-01T00:00:00.000Z"),
+				image("ami-00000001", "2021-01-01T00:00:00.000Z"),
+			},
+			Expected: []string{"ami-0000000a", "ami-0000000b", "ami-0000000c", "ami-00000001"},
+		},
+		{
+			Name: "unparseable creation date last",
+			Images: []*ec2.Image{
+				image("ami-00000001", ""),
+				image("ami-00000002", "2021-01-01T00:00:00.000Z"),
+			},
+			Expected: []string{"ami-00000002", "ami-00000001"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, image := range sortEc2ImagesByCreationDate(testCase.Images) {
+				got = append(got, aws.StringValue(image.ImageId))
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestFlattenEc2LatestImage(t *testing.T) {
+	got := flattenEc2LatestImage(&ec2.Image{
+		ImageId:      aws.String("ami-01234567"),
+		Name:         aws.String("golden-20210301"),
+		CreationDate: aws.String("2021-03-01T12:00:00.000Z"),
+		BlockDeviceMappings: []*ec2.BlockDeviceMapping{
+			{DeviceName: aws.String("/dev/xvda"), Ebs: &ec2.EbsBlockDevice{SnapshotId: aws.String("snap-01234567")}},
+		},
+		Tags: []*ec2.Tag{
+			{Key: aws.String("Role"), Value: aws.String("golden")},
+			{Key: aws.String("aws:cloudformation:stack-name"), Value: aws.String("stack")},
+		},
+	})
+
+	for k, expected := range map[string]interface{}{
+		"image_id":      "ami-01234567",
+		"image_name":    "golden-20210301",
+		"creation_date": "2021-03-01T12:00:00Z",
+		"snapshot_ids":  []string{"snap-01234567"},
+		"image_tags":    map[string]string{"Role": "golden"},
+	} {
+		if !reflect.DeepEqual(got[k], expected) {
+			t.Errorf("got %s %v, expected %v", k, got[k], expected)
+		}
+	}
+
+	if got := flattenEc2LatestImage(nil); got["image_id"] != "" || !reflect.DeepEqual(got["snapshot_ids"], []string{}) {
+		t.Errorf("expected empty attributes without an AMI, got %v", got)
+	}
+}
@@ -0,0 +1,312 @@
+package provider
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2AmisMissingRequiredTagsFingerprintAttributes are the attributes of the "images" hashed into their fingerprint.
+var ec2AmisMissingRequiredTagsFingerprintAttributes = []string{"image_id", "name", "missing_keys"}
+
+func dataSourceAwsUtilsEc2AmisMissingRequiredTags() *schema.Resource {
+	return &schema.Resource{
+		Description: `Lists the AMIs owned by the current account which are missing any of the given required tag keys.
+
+Only AMIs owned by the current account are considered, even if others are shared with it or public. When
+` + "`check_snapshot_tags`" + ` is set, the EBS Snapshots backing each AMI are checked as well, and an AMI is also reported when
+any of its Snapshots is missing a required tag key. Tags with the reserved ` + "`aws:`" + ` prefix are ignored.`,
+		ReadContext:   dataSourceAwsUtilsEc2AmisMissingRequiredTagsRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeImage),
+			"ids_strict":        ec2IDsStrictSchema(),
+			"arns":              ec2ARNsSchema(ec2.ResourceTypeImage),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"region":            ec2RegionSchema(),
+			"regex_filter":      ec2RegexFiltersSchema(),
+			"tags":              tagsSchema(),
+			"case_insensitive":  ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":      ec2ExcludeTagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"has_tags":          ec2HasTagsSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"max_results_cap":   maxResultsCapSchema(),
+			"debug":             debugSchema(),
+			"raw_response_json": rawResponseJSONSchema(),
+			"applied_filters":   ec2AppliedFiltersSchema(),
+			"resolved_filters":  ec2ResolvedFiltersSchema(),
+			"filters_object":    ec2FiltersObjectSchema(),
+			"allow_unfiltered":  ec2AllowUnfilteredSchema(),
+			"required_keys": {
+				Description: "The tag keys which every AMI must have.",
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringIsNotEmpty,
+				},
+			},
+			"check_snapshot_tags": {
+				Description: "Whether the EBS Snapshots backing the AMIs must also have the required tag keys.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"images": {
+				Description: "The AMIs missing a required tag key, themselves or on one of their Snapshots, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"image_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"missing_keys": {
+							Description: "The required tag keys missing from the AMI.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"snapshots": {
+							Description: "The Snapshots of the AMI missing a required tag key, if `check_snapshot_tags` is set.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"snapshot_id": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"missing_keys": {
+										Description: "The required tag keys missing from the Snapshot.",
+										Type:        schema.TypeList,
+										Computed:    true,
+										Elem:        &schema.Schema{Type: schema.TypeString},
+									},
+								},
+							},
+						},
+						"fingerprint": fingerprintSchema(ec2AmisMissingRequiredTagsFingerprintAttributes),
+					},
+				},
+			},
+			"image_ids": {
+				Description: "The IDs of the AMIs missing a required tag key.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2AmisMissingRequiredTagsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	accountID := meta.(*AWSClient).accountid
+	requiredKeys := ExpandStringSliceofPointers(ExpandStringSet(d.Get("required_keys").(*schema.Set)))
+	checkSnapshotTags := d.Get("check_snapshot_tags").(bool)
+
+	input := &ec2.DescribeImagesInput{
+		Owners: aws.StringSlice([]string{ec2OwnerSelf}),
+	}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeImage)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.ImageIds = ids
+	input.Filters = filters
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var images []*ec2.Image
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		images, err = finder.Images(conn, input, maxResultsCap(d, meta))
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 AMIs: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Image)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	kept := images[:0]
+	for _, image := range images {
+		if !excluded(image.Tags) && tagsMatched(image.Tags) && regexMatched(image) {
+			kept = append(kept, image)
+		}
+	}
+	images = kept
+
+	owned := make([]*ec2.Image, 0, len(images))
+	for _, image := range images {
+		// Owners already restricts the results, but this guards against an explicit image ID of another account.
+		if accountID != "" && aws.StringValue(image.OwnerId) != accountID {
+			log.Printf("[DEBUG] Skipping EC2 AMI (%s) owned by another account (%s)", aws.StringValue(image.ImageId), aws.StringValue(image.OwnerId))
+			continue
+		}
+
+		owned = append(owned, image)
+	}
+
+	sort.Slice(owned, func(i, j int) bool {
+		return aws.StringValue(owned[i].ImageId) < aws.StringValue(owned[j].ImageId)
+	})
+
+	var snapshots map[string]*ec2.Snapshot
+	if checkSnapshotTags {
+		var snapshotIDs []string
+		for _, image := range owned {
+			snapshotIDs = append(snapshotIDs, ec2ImageSnapshotIDs(image)...)
+		}
+
+		found, err := finder.SnapshotsByID(conn, snapshotIDs)
+		if err != nil {
+			return diag.Errorf("error reading EBS Snapshots: %s", err)
+		}
+
+		snapshots = make(map[string]*ec2.Snapshot, len(found))
+		for _, snapshot := range found {
+			snapshots[aws.StringValue(snapshot.SnapshotId)] = snapshot
+		}
+	}
+
+	results := make([]map[string]interface{}, 0)
+	imageIDs := make([]string, 0)
+
+	for _, image := range owned {
+		result := ec2ImageMissingRequiredTags(image, snapshots, requiredKeys)
+		if result == nil {
+			continue
+		}
+
+		results = append(results, result)
+		imageIDs = append(imageIDs, aws.StringValue(image.ImageId))
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Images": images}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setFingerprints(results, ec2AmisMissingRequiredTagsFingerprintAttributes); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("images", results); err != nil {
+		return diag.Errorf("error setting images: %s", err)
+	}
+
+	if err := d.Set("image_ids", imageIDs); err != nil {
+		return diag.Errorf("error setting image_ids: %s", err)
+	}
+
+	return warnings
+}
+
+// ec2ImageSnapshotIDs returns the IDs of the EBS Snapshots backing the given AMI.
+func ec2ImageSnapshotIDs(image *ec2.Image) []string {
+	var snapshotIDs []string
+
+	for _, mapping := range image.BlockDeviceMappings {
+		if mapping == nil || mapping.Ebs == nil || mapping.Ebs.SnapshotId == nil {
+			continue
+		}
+
+		snapshotIDs = append(snapshotIDs, aws.StringValue(mapping.Ebs.SnapshotId))
+	}
+
+	return snapshotIDs
+}
+
+// ec2ImageMissingRequiredTags returns the flattened "images" element of the given AMI, or nil if neither it nor
+// any of its Snapshots found in the given map, if not nil, is missing one of the required tag keys.
+func ec2ImageMissingRequiredTags(image *ec2.Image, snapshots map[string]*ec2.Snapshot, requiredKeys []string) map[string]interface{} {
+	missingKeys := missingTagKeys(keyvaluetags.Ec2KeyValueTags(image.Tags), requiredKeys)
+
+	snapshotResults := make([]map[string]interface{}, 0)
+	if snapshots != nil {
+		for _, snapshotID := range ec2ImageSnapshotIDs(image) {
+			snapshot, ok := snapshots[snapshotID]
+			if !ok {
+				log.Printf("[WARN] EBS Snapshot (%s) of EC2 AMI (%s) not found, skipping its tags", snapshotID, aws.StringValue(image.ImageId))
+				continue
+			}
+
+			if snapshotMissingKeys := missingTagKeys(keyvaluetags.Ec2KeyValueTags(snapshot.Tags), requiredKeys); len(snapshotMissingKeys) > 0 {
+				snapshotResults = append(snapshotResults, map[string]interface{}{
+					"snapshot_id":  snapshotID,
+					"missing_keys": snapshotMissingKeys,
+				})
+			}
+		}
+	}
+
+	if len(missingKeys) == 0 && len(snapshotResults) == 0 {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"image_id":     aws.StringValue(image.ImageId),
+		"name":         aws.StringValue(image.Name),
+		"missing_keys": missingKeys,
+		"snapshots":    snapshotResults,
+	}
+}
+
+// missingTagKeys returns the sorted keys of the given list which are missing from the given tags, ignoring
+// those with the reserved "aws:" prefix.
+func missingTagKeys(tags keyvaluetags.KeyValueTags, keys []string) []string {
+	tags = tags.IgnoreAws()
+	missing := make([]string, 0)
+
+	for _, key := range keys {
+		if !tags.KeyExists(key) {
+			missing = appendUniqueString(missing, key)
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEc2ImageMissingRequiredTags(t *testing.T) {
+	image := &ec2.Image{
+		ImageId: aws.String("ami-01234567"),
+		Name:    aws.String("my-image"),
+		Tags: []*ec2.Tag{
+			{Key: aws.String("Owner"), Value: aws.String("platform")},
+		},
+		BlockDeviceMappings: []*ec2.BlockDeviceMapping{
+			{DeviceName: aws.String("/dev/xvda"), Ebs: &ec2.EbsBlockDevice{SnapshotId: aws.String("snap-01234567")}},
+			{DeviceName: aws.String("/dev/xvdb"), VirtualName: aws.String("ephemeral0")},
+		},
+	}
+
+	testCases := []struct {
+		Name         string
+		Image        *ec2.Image
+		Snapshots    map[string]*ec2.Snapshot
+		RequiredKeys []string
+		Expected     map[string]interface{}
+	}{
+		{
+			Name:         "compliant",
+			Image:        image,
+			RequiredKeys: []string{"Owner"},
+		},
+		{
+			Name:         "AMI missing keys",
+			Image:        image,
+			RequiredKeys: []string{"Owner", "Environment", "CostCenter"},
+			Expected: map[string]interface{}{
+				"image_id":     "ami-01234567",
+				"name":         "my-image",
+				"missing_keys": []string{"CostCenter", "Environment"},
+				"snapshots":    []map[string]interface{}{},
+			},
+		},
+		{
+			Name:  "snapshot missing keys",
+			Image: image,
+			Snapshots: map[string]*ec2.Snapshot{
+				"snap-01234567": {
+					SnapshotId: aws.String("snap-01234567"),
+					Tags: []*ec2.Tag{
+						{Key: aws.String("aws:backup:source-resource"), Value: aws.String("vol-01234567")},
+					},
+				},
+			},
+			RequiredKeys: []string{"Owner"},
+			Expected: map[string]interface{}{
+				"image_id":     "ami-01234567",
+				"name":         "my-image",
+				"missing_keys": []string{},
+				"snapshots": []map[string]interface{}{
+					{
+						"snapshot_id":  "snap-01234567",
+						"missing_keys": []string{"Owner"},
+					},
+				},
+			},
+		},
+		{
+			Name:         "snapshot not found",
+			Image:        image,
+			Snapshots:    map[string]*ec2.Snapshot{},
+			RequiredKeys: []string{"Owner"},
+		},
+		{
+			Name:         "AWS tags ignored",
+			Image:        &ec2.Image{ImageId: aws.String("ami-01234567"), Tags: []*ec2.Tag{{Key: aws.String("aws:owner"), Value: aws.String("x")}}},
+			RequiredKeys: []string{"aws:owner"},
+			Expected: map[string]interface{}{
+				"image_id":     "ami-01234567",
+				"name":         "",
+				"missing_keys": []string{"aws:owner"},
+				"snapshots":    []map[string]interface{}{},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := ec2ImageMissingRequiredTags(testCase.Image, testCase.Snapshots, testCase.RequiredKeys)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
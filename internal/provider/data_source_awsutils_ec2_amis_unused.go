@@ -0,0 +1,353 @@
+package provider
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2AmisUnusedFingerprintAttributes are the attributes of the "images" hashed into their fingerprint.
+var ec2AmisUnusedFingerprintAttributes = []string{"image_id", "name", "creation_date", "snapshot_ids"}
+
+func dataSourceAwsUtilsEc2AmisUnused() *schema.Resource {
+	return &schema.Resource{
+		Description: `Lists the AMIs owned by the current account, matching the given filters, which are neither used by an
+EC2 Instance nor referenced by a Launch Template, such as to clean them up.
+
+The Instances in any of the ` + "`instance_states`" + ` are looked up by the IDs of the matching AMIs, in batches. The
+` + "`$Latest`" + ` and ` + "`$Default`" + ` versions of every Launch Template are checked, which are those Auto Scaling
+Groups and other services launch from unless they pin a version, and all the versions are checked when
+` + "`all_launch_template_versions`" + ` is set. The AMI IDs given to Launch Templates as Systems Manager parameters are
+resolved. The AMIs created less than ` + "`grace_period_days`" + ` ago are not reported, as they may not be in use yet.`,
+		ReadContext:   dataSourceAwsUtilsEc2AmisUnusedRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeImage),
+			"ids_strict":        ec2IDsStrictSchema(),
+			"arns":              ec2ARNsSchema(ec2.ResourceTypeImage),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"region":            ec2RegionSchema(),
+			"regex_filter":      ec2RegexFiltersSchema(),
+			"tags":              tagsSchema(),
+			"case_insensitive":  ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":      ec2ExcludeTagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"has_tags":          ec2HasTagsSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"max_results_cap":   maxResultsCapSchema(),
+			"debug":             debugSchema(),
+			"raw_response_json": rawResponseJSONSchema(),
+			"applied_filters":   ec2AppliedFiltersSchema(),
+			"resolved_filters":  ec2ResolvedFiltersSchema(),
+			"filters_object":    ec2FiltersObjectSchema(),
+			"allow_unfiltered":  ec2AllowUnfilteredSchema(),
+			"instance_states": {
+				Description: "The states of the Instances whose AMIs are in use. Defaults to all the states but `terminated`, as a stopped Instance is launched again from its AMI's Snapshots.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(ec2.InstanceStateName_Values(), false),
+				},
+			},
+			"all_launch_template_versions": {
+				Description: "Whether all the versions of the Launch Templates are checked, rather than only their `$Latest` and `$Default` versions. This takes a request per Launch Template.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"grace_period_days": {
+				Description:  "The number of days after their creation during which AMIs are not reported.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"images": {
+				Description: "The unused AMIs, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"image_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"creation_date": {
+							Description: "The time the AMI was created, in RFC 3339 format.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"snapshot_ids": {
+							Description: "The IDs of the EBS Snapshots backing the AMI.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"fingerprint": fingerprintSchema(ec2AmisUnusedFingerprintAttributes),
+					},
+				},
+			},
+			"image_ids": {
+				Description: "The IDs of the unused AMIs, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"referenced_image_ids": {
+				Description: "The IDs of the matching AMIs used by an Instance or referenced by a Launch Template, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2AmisUnusedRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	accountID := meta.(*AWSClient).accountid
+	gracePeriod := time.Duration(d.Get("grace_period_days").(int)) * 24 * time.Hour
+
+	states := []string{
+		ec2.InstanceStateNamePending,
+		ec2.InstanceStateNameRunning,
+		ec2.InstanceStateNameShuttingDown,
+		ec2.InstanceStateNameStopping,
+		ec2.InstanceStateNameStopped,
+	}
+	if v, ok := d.GetOk("instance_states"); ok && v.(*schema.Set).Len() > 0 {
+		states = ExpandStringSliceofPointers(ExpandStringSet(v.(*schema.Set)))
+		sort.Strings(states)
+	}
+
+	input := &ec2.DescribeImagesInput{
+		Owners: aws.StringSlice([]string{ec2OwnerSelf}),
+	}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeImage)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.ImageIds = ids
+	input.Filters = filters
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var images []*ec2.Image
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		images, err = finder.Images(conn, input, maxResultsCap(d, meta))
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 AMIs: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Image)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	owned := make([]*ec2.Image, 0, len(images))
+	for _, image := range images {
+		if excluded(image.Tags) || !tagsMatched(image.Tags) || !regexMatched(image) {
+			continue
+		}
+
+		// Owners already restricts the results, but this guards against an explicit image ID of another account.
+		if accountID != "" && aws.StringValue(image.OwnerId) != accountID {
+			log.Printf("[DEBUG] Skipping EC2 AMI (%s) owned by another account (%s)", aws.StringValue(image.ImageId), aws.StringValue(image.OwnerId))
+			continue
+		}
+
+		owned = append(owned, image)
+	}
+
+	referenced := make(map[string]bool)
+
+	// There is nothing to look up the references of otherwise.
+	if len(owned) > 0 {
+		imageIDs := make([]string, 0, len(owned))
+		for _, image := range owned {
+			imageIDs = append(imageIDs, aws.StringValue(image.ImageId))
+		}
+
+		instances, err := finder.InstancesByImageID(conn, imageIDs, states)
+		if err != nil {
+			return diag.Errorf("error reading EC2 Instances: %s", err)
+		}
+
+		for _, instance := range instances {
+			referenced[aws.StringValue(instance.ImageId)] = true
+		}
+
+		versions, err := ec2AmisUnusedLaunchTemplateVersions(conn, d.Get("all_launch_template_versions").(bool))
+		if err != nil {
+			return diag.Errorf("error reading EC2 Launch Template versions: %s", err)
+		}
+
+		for _, version := range versions {
+			if version.LaunchTemplateData != nil && version.LaunchTemplateData.ImageId != nil {
+				referenced[aws.StringValue(version.LaunchTemplateData.ImageId)] = true
+			}
+		}
+	}
+
+	unused, used := ec2UnusedImages(owned, referenced, gracePeriod, time.Now())
+
+	results := make([]map[string]interface{}, 0, len(unused))
+	unusedIDs := make([]string, 0, len(unused))
+	for _, image := range unused {
+		results = append(results, flattenEc2UnusedImage(image))
+		unusedIDs = append(unusedIDs, aws.StringValue(image.ImageId))
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Images": images}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setFingerprints(results, ec2AmisUnusedFingerprintAttributes); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("images", results); err != nil {
+		return diag.Errorf("error setting images: %s", err)
+	}
+
+	if err := d.Set("image_ids", unusedIDs); err != nil {
+		return diag.Errorf("error setting image_ids: %s", err)
+	}
+
+	if err := d.Set("referenced_image_ids", used); err != nil {
+		return diag.Errorf("error setting referenced_image_ids: %s", err)
+	}
+
+	return warnings
+}
+
+// ec2AmisUnusedLaunchTemplateVersions returns the versions of all the Launch Templates, or only their $Latest and
+// $Default versions, listed in a single request sequence, unless all is set. The AMI IDs of the versions given as
+// Systems Manager parameters are resolved.
+func ec2AmisUnusedLaunchTemplateVersions(conn *ec2.EC2, all bool) ([]*ec2.LaunchTemplateVersion, error) {
+	if !all {
+		return finder.LaunchTemplateVersions(conn, &ec2.DescribeLaunchTemplateVersionsInput{
+			Versions:     aws.StringSlice([]string{"$Latest", "$Default"}),
+			ResolveAlias: aws.Bool(true),
+		})
+	}
+
+	launchTemplates, err := finder.LaunchTemplates(conn, &ec2.DescribeLaunchTemplatesInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	var output []*ec2.LaunchTemplateVersion
+	for _, launchTemplate := range launchTemplates {
+		versions, err := finder.LaunchTemplateVersions(conn, &ec2.DescribeLaunchTemplateVersionsInput{
+			LaunchTemplateId: launchTemplate.LaunchTemplateId,
+			ResolveAlias:     aws.Bool(true),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, versions...)
+	}
+
+	return output, nil
+}
+
+// ec2UnusedImages returns the given AMIs which are not referenced, ordered by ID, except those created less than
+// gracePeriod before now, and the sorted IDs of those which are referenced. The AMIs whose creation date cannot be
+// parsed are not reported unless gracePeriod is zero, since their age is unknown.
+func ec2UnusedImages(images []*ec2.Image, referenced map[string]bool, gracePeriod time.Duration, now time.Time) ([]*ec2.Image, []string) {
+	unused := make([]*ec2.Image, 0)
+	used := make([]string, 0)
+
+	for _, image := range images {
+		imageID := aws.StringValue(image.ImageId)
+
+		if referenced[imageID] {
+			used = append(used, imageID)
+			continue
+		}
+
+		if gracePeriod > 0 {
+			creationTime, err := time.Parse(time.RFC3339, aws.StringValue(image.CreationDate))
+			if err != nil {
+				log.Printf("[WARN] Unparseable creation date of EC2 AMI (%s), skipping it: %q", imageID, aws.StringValue(image.CreationDate))
+				continue
+			}
+
+			if now.Sub(creationTime) < gracePeriod {
+				log.Printf("[DEBUG] Skipping EC2 AMI (%s) created within the grace period", imageID)
+				continue
+			}
+		}
+
+		unused = append(unused, image)
+	}
+
+	sort.Slice(unused, func(i, j int) bool {
+		return aws.StringValue(unused[i].ImageId) < aws.StringValue(unused[j].ImageId)
+	})
+	sort.Strings(used)
+
+	return unused, used
+}
+
+// flattenEc2UnusedImage returns the "images" element of the given AMI.
+func flattenEc2UnusedImage(image *ec2.Image) map[string]interface{} {
+	var creationDate string
+	if t, err := time.Parse(time.RFC3339, aws.StringValue(image.CreationDate)); err == nil {
+		creationDate = t.UTC().Format(time.RFC3339)
+	}
+
+	snapshotIDs := ec2ImageSnapshotIDs(image)
+	if snapshotIDs == nil {
+		snapshotIDs = []string{}
+	}
+
+	return map[string]interface{}{
+		"image_id":      aws.StringValue(image.ImageId),
+		"name":          aws.StringValue(image.Name),
+		"creation_date": creationDate,
+		"snapshot_ids":  snapshotIDs,
+	}
+}
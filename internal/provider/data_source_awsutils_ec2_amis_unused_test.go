@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2UnusedImages(t *testing.T) {
+	now := time.Date(2021, 6, 30, 0, 0, 0, 0, time.UTC)
+	images := []*ec2.Image{
+		{ImageId: aws.String("ami-00000003"), CreationDate: aws.String("2021-01-01T00:00:00.000Z")},
+		{ImageId: aws.String("ami-00000001"), CreationDate: aws.String("2021-01-01T00:00:00.000Z")},
+		{ImageId: aws.String("ami-00000002"), CreationDate: aws.String("2021-01-01T00:00:00.000Z")},
+		{ImageId: aws.String("ami-00000004"), CreationDate: aws.String("2021-06-29T00:00:00.000Z")},
+		{ImageId: aws.String("ami-00000005"), CreationDate: aws.String("yesterday")},
+	}
+	referenced := map[string]bool{"ami-00000002": true}
+
+	testCases := []struct {
+		Name           string
+		GracePeriod    time.Duration
+		ExpectedUnused []string
+	}{
+		{
+			Name:           "no grace period",
+			ExpectedUnused: []string{"ami-00000001", "ami-00000003", "ami-00000004", "ami-00000005"},
+		},
+		{
+			Name:           "grace period",
+			GracePeriod:    7 * 24 * time.Hour,
+			ExpectedUnused: []string{"ami-00000001", "ami-00000003"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			unused, used := ec2UnusedImages(images, referenced, testCase.GracePeriod, now)
+
+			unusedIDs := make([]string, 0, len(unused))
+			for _, image := range unused {
+				unusedIDs = append(unusedIDs, aws.StringValue(image.ImageId))
+			}
+
+			if !reflect.DeepEqual(unusedIDs, testCase.ExpectedUnused) {
+				t.Errorf("got unused %v, expected %v", unusedIDs, testCase.ExpectedUnused)
+			}
+			if expected := []string{"ami-00000002"}; !reflect.DeepEqual(used, expected) {
+				t.Errorf("got used %v, expected %v", used, expected)
+			}
+		})
+	}
+}
+
+func TestDataSourceAwsUtilsEc2AmisUnusedRead(t *testing.T) {
+	// More AMIs than fit in a single "image-id" filter.
+	var images []*ec2.Image
+	for i := 1; i <= 201; i++ {
+		images = append(images, &ec2.Image{
+			ImageId:      aws.String(fmt.Sprintf("ami-%08x", i)),
+			OwnerId:      aws.String("123456789012"),
+			CreationDate: aws.String("2021-01-01T00:00:00.000Z"),
+		})
+	}
+
+	var mu sync.Mutex
+	var instancesInputs []*ec2.DescribeInstancesInput
+	var versionsInputs []*ec2.DescribeLaunchTemplateVersionsInput
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch output := r.Data.(type) {
+		case *ec2.DescribeImagesOutput:
+			output.Images = images
+		case *ec2.DescribeInstancesOutput:
+			instancesInputs = append(instancesInputs, r.Params.(*ec2.DescribeInstancesInput))
+			if len(instancesInputs) == 2 {
+				output.Reservations = []*ec2.Reservation{
+					{Instances: []*ec2.Instance{{InstanceId: aws.String("i-00000001"), ImageId: aws.String("ami-000000c9")}}},
+				}
+			}
+		case *ec2.DescribeLaunchTemplateVersionsOutput:
+			versionsInputs = append(versionsInputs, r.Params.(*ec2.DescribeLaunchTemplateVersionsInput))
+			output.LaunchTemplateVersions = []*ec2.LaunchTemplateVersion{
+				{LaunchTemplateId: aws.String("lt-00000001"), LaunchTemplateData: &ec2.ResponseLaunchTemplateData{ImageId: aws.String("ami-00000001")}},
+				{LaunchTemplateId: aws.String("lt-00000002"), LaunchTemplateData: &ec2.ResponseLaunchTemplateData{}},
+			}
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2AmisUnused().Schema, map[string]interface{}{
+		"tags": map[string]interface{}{"Team": "platform"},
+	})
+
+	if diags := dataSourceAwsUtilsEc2AmisUnusedRead(context.Background(), d, &AWSClient{ec2conn: conn, accountid: "123456789012", region: "us-east-1", maxResultsCap: defaultMaxResultsCap}); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if len(instancesInputs) != 2 {
+		t.Fatalf("got %d DescribeInstances requests, expected 2", len(instancesInputs))
+	}
+	if got := len(instancesInputs[0].Filters[0].Values) + len(instancesInputs[1].Filters[0].Values); got != 201 {
+		t.Errorf("got %d AMI IDs looked up, expected 201", got)
+	}
+
+	if len(versionsInputs) != 1 {
+		t.Fatalf("got %d DescribeLaunchTemplateVersions requests, expected 1", len(versionsInputs))
+	}
+	if got, expected := aws.StringValueSlice(versionsInputs[0].Versions), []string{"$Latest", "$Default"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got versions %v, expected %v", got, expected)
+	}
+
+	if expected := []interface{}{"ami-00000001", "ami-000000c9"}; !reflect.DeepEqual(d.Get("referenced_image_ids"), expected) {
+		t.Errorf("got referenced_image_ids %v, expected %v", d.Get("referenced_image_ids"), expected)
+	}
+
+	if got := d.Get("image_ids.#").(int); got != 199 {
+		t.Errorf("got %d unused AMIs, expected 199", got)
+	}
+
+	if got := d.Get("images.0.creation_date").(string); got != "2021-01-01T00:00:00Z" {
+		t.Errorf("got creation_date %q, expected 2021-01-01T00:00:00Z", got)
+	}
+}
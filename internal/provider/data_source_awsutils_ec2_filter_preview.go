@@ -0,0 +1,221 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2FilterPreviewQuery reads the IDs of the objects of a resource type matching the given selection IDs and
+// filters, as the resources operating on objects of that type select them.
+type ec2FilterPreviewQuery func(conn *ec2.EC2, ids []*string, filters []*ec2.Filter, maxResults int) ([]string, error)
+
+// ec2FilterPreviewQueries are the queries of the resource types the resources of the provider operate on, keyed by
+// ec2.ResourceType value.
+var ec2FilterPreviewQueries = map[string]ec2FilterPreviewQuery{
+	ec2.ResourceTypeElasticIp: func(conn *ec2.EC2, ids []*string, filters []*ec2.Filter, maxResults int) ([]string, error) {
+		addresses, err := finder.Addresses(conn, &ec2.DescribeAddressesInput{AllocationIds: ids, Filters: filters})
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 Elastic IPs: %w", err)
+		}
+
+		result := make([]string, 0, len(addresses))
+		for _, address := range addresses {
+			// EC2-Classic addresses are identified by their public IP, as by awsutils_ec2_elastic_ip_tagger.
+			id := aws.StringValue(address.AllocationId)
+			if id == "" {
+				id = aws.StringValue(address.PublicIp)
+			}
+			result = append(result, id)
+		}
+
+		return result, nil
+	},
+	ec2.ResourceTypeInstance: func(conn *ec2.EC2, ids []*string, filters []*ec2.Filter, maxResults int) ([]string, error) {
+		instances, err := finder.Instances(conn, &ec2.DescribeInstancesInput{InstanceIds: ids, Filters: filters}, maxResults)
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 Instances: %w", maxResultsCapError(err))
+		}
+
+		result := make([]string, 0, len(instances))
+		for _, instance := range instances {
+			result = append(result, aws.StringValue(instance.InstanceId))
+		}
+
+		return result, nil
+	},
+	ec2.ResourceTypeSecurityGroup: func(conn *ec2.EC2, ids []*string, filters []*ec2.Filter, maxResults int) ([]string, error) {
+		groups, err := finder.SecurityGroups(conn, &ec2.DescribeSecurityGroupsInput{GroupIds: ids, Filters: filters}, maxResults)
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 Security Groups: %w", maxResultsCapError(err))
+		}
+
+		result := make([]string, 0, len(groups))
+		for _, group := range groups {
+			result = append(result, aws.StringValue(group.GroupId))
+		}
+
+		return result, nil
+	},
+	ec2.ResourceTypeSnapshot: func(conn *ec2.EC2, ids []*string, filters []*ec2.Filter, maxResults int) ([]string, error) {
+		// Only the snapshots of the account are selected, as by awsutils_ec2_ebs_snapshot_tagger_from_volume.
+		snapshots, err := finder.Snapshots(conn, &ec2.DescribeSnapshotsInput{
+			OwnerIds:    aws.StringSlice([]string{ec2OwnerSelf}),
+			SnapshotIds: ids,
+			Filters:     filters,
+		}, 0)
+
+		if err != nil {
+			return nil, fmt.Errorf("error reading EBS Snapshots: %w", err)
+		}
+
+		result := make([]string, 0, len(snapshots))
+		for _, snapshot := range snapshots {
+			result = append(result, aws.StringValue(snapshot.SnapshotId))
+		}
+
+		return result, nil
+	},
+	ec2.ResourceTypeVpc: func(conn *ec2.EC2, ids []*string, filters []*ec2.Filter, maxResults int) ([]string, error) {
+		vpcs, err := finder.Vpcs(conn, &ec2.DescribeVpcsInput{VpcIds: ids, Filters: filters}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 VPCs: %w", err)
+		}
+
+		result := make([]string, 0, len(vpcs))
+		for _, vpc := range vpcs {
+			result = append(result, aws.StringValue(vpc.VpcId))
+		}
+
+		return result, nil
+	},
+}
+
+// ec2FilterPreviewResourceTypes returns the resource types supported by awsutils_ec2_filter_preview, sorted.
+func ec2FilterPreviewResourceTypes() []string {
+	result := make([]string, 0, len(ec2FilterPreviewQueries))
+	for resourceType := range ec2FilterPreviewQueries {
+		result = append(result, resourceType)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func dataSourceAwsUtilsEc2FilterPreview() *schema.Resource {
+	return &schema.Resource{
+		Description: `Previews the objects the given selection matches, to validate it before using it in a resource which
+modifies the objects it selects.
+
+The selection attributes are converted into filters and sent as by the resources, including their defaults such as
+only selecting the Snapshots of the account, and the IDs of the matching objects are returned along with the filters
+sent. Nothing is modified. The supported resource types are those of the resources of the provider: ` + "`elastic-ip`" + `,
+` + "`instance`" + `, ` + "`security-group`" + `, ` + "`snapshot`" + ` and ` + "`vpc`" + `.`,
+		ReadContext:   dataSourceAwsUtilsEc2FilterPreviewRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"resource_type": {
+				Description:  "The type of the objects to select, e.g. `instance` or `security-group`.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(ec2FilterPreviewResourceTypes(), false),
+			},
+			"ids": {
+				Description: "Only match objects with the given IDs, which must be IDs of objects of `resource_type`.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"tags":                      tagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"applied_filters":           ec2AppliedFiltersSchema(),
+			"resolved_filters":          ec2ResolvedFiltersSchema(),
+			"filters_object":            ec2FiltersObjectSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"matched_ids": {
+				Description: "The IDs of the matching objects, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2FilterPreviewRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	resourceType := d.Get("resource_type").(string)
+
+	query, ok := ec2FilterPreviewQueries[resourceType]
+	if !ok {
+		return diag.Errorf("unsupported resource_type: %s", resourceType)
+	}
+
+	// The IDs are validated here, as their type is only known from resource_type.
+	for _, id := range ExpandStringSliceofPointers(ExpandStringSet(d.Get("ids").(*schema.Set))) {
+		if err := tfec2.ValidateResourceID(resourceType, id); err != nil {
+			return diag.Errorf("ids: %s", err)
+		}
+	}
+
+	ids, filters, warnings, err := buildEC2Selection(d, meta, resourceType)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var matchedIDs []string
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		matchedIDs, err = query(conn, ids, filters, maxResultsCap(d, meta))
+		return err
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	sort.Strings(matchedIDs)
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("matched_ids", matchedIDs); err != nil {
+		return diag.Errorf("error setting matched_ids: %s", err)
+	}
+
+	return warnings
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2FilterPreviewResourceTypes(t *testing.T) {
+	// The resource types the resources of the provider select objects of.
+	expected := []string{
+		ec2.ResourceTypeElasticIp,
+		ec2.ResourceTypeInstance,
+		ec2.ResourceTypeSecurityGroup,
+		ec2.ResourceTypeSnapshot,
+		ec2.ResourceTypeVpc,
+	}
+
+	if got := ec2FilterPreviewResourceTypes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+
+	for _, resourceType := range expected {
+		if _, ok := tfec2.ResourceTypeMetadataFor(resourceType); !ok {
+			t.Errorf("unsupported EC2 resource type: %s", resourceType)
+		}
+	}
+}
+
+func TestEc2FilterPreviewSelection(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2FilterPreview().Schema, map[string]interface{}{
+		"resource_type": ec2.ResourceTypeSecurityGroup,
+		"ids":           []interface{}{"sg-01234567"},
+		"name":          "web-*",
+	})
+
+	ids, filters, _, err := buildEC2Selection(d, &AWSClient{}, d.Get("resource_type").(string))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ids) != 1 || *ids[0] != "sg-01234567" {
+		t.Errorf("got IDs %v, expected [sg-01234567]", ids)
+	}
+
+	if err := setEC2AppliedFilters(d, filters); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"name": "tag:Name",
+			"values": []interface{}{
+				map[string]interface{}{"value": "web-*", "wildcard": true},
+			},
+		},
+	}
+	if got := d.Get("applied_filters"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
@@ -0,0 +1,511 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	// defaultEc2InstanceConnectivityMatrixMaxPairs is the default number of pairs returned at once.
+	defaultEc2InstanceConnectivityMatrixMaxPairs = 1000
+	// maxEc2InstanceConnectivityMatrixMaxPairs bounds max_pairs, keeping the state of the data source reasonable.
+	maxEc2InstanceConnectivityMatrixMaxPairs = 10000
+)
+
+func dataSourceAwsUtilsEc2InstanceConnectivityMatrix() *schema.Resource {
+	return &schema.Resource{
+		Description: `Reports whether the Instances of a source selection can reach those of a destination selection on a given
+protocol and port, according to the rules of their Security Groups.
+
+A source Instance reaches a destination Instance when an egress rule of one of the Security Groups of the source
+allows the traffic to the destination, and an ingress rule of one of the Security Groups of the destination allows the
+traffic from the source. A rule covers an Instance when it references one of the Security Groups of the Instance, or
+when its CIDR block, or one of the CIDR blocks of its Managed Prefix List, contains one of the private IPv4 or IPv6
+addresses of the Instance.
+
+This is a Security Group reachability analysis only: the routes, the Network ACLs, the peering connections and whether
+the Instances are running are not evaluated, for which the VPC Reachability Analyzer should be used. In particular, a
+rule referencing a Security Group is assumed to apply, AWS only applying it to the private addresses of the Instances
+of the same VPC or of a peered VPC. The terminated Instances are left out.
+
+The number of pairs grows with the product of the numbers of source and destination Instances, so they are returned
+` + "`max_pairs`" + ` at a time, ordered by source then destination Instance ID, starting at ` + "`pairs_offset`" + `. All the pairs are
+read by following ` + "`next_pairs_offset`" + ` while ` + "`truncated`" + ` is set. An Instance is never paired with itself.`,
+		ReadContext:   dataSourceAwsUtilsEc2InstanceConnectivityMatrixRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"source":          ec2InstanceConnectivityMatrixSelectionSchema("The selection of the Instances the traffic is sent from."),
+			"destination":     ec2InstanceConnectivityMatrixSelectionSchema("The selection of the Instances the traffic is sent to."),
+			"region":          ec2RegionSchema(),
+			"max_results_cap": maxResultsCapSchema(),
+			"protocol": {
+				Description:  "The protocol of the traffic, `tcp` or `udp`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "tcp",
+				ValidateFunc: validation.StringInSlice([]string{"tcp", "udp"}, false),
+			},
+			"port": {
+				Description:  "The destination port of the traffic.",
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntBetween(0, 65535),
+			},
+			"allowed_only": {
+				Description: "Whether only the allowed pairs should be returned, and counted in `total_pairs`.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"max_pairs": {
+				Description:  "The maximum number of pairs to return.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultEc2InstanceConnectivityMatrixMaxPairs,
+				ValidateFunc: validation.IntBetween(1, maxEc2InstanceConnectivityMatrixMaxPairs),
+			},
+			"pairs_offset": {
+				Description:  "The number of pairs to skip, such as the `next_pairs_offset` of a previous read.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"pairs": {
+				Description: "The evaluated source and destination Instance pairs.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"source_instance_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"destination_instance_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"allowed": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"egress_security_group_rule_id": {
+							Description: "The ID of the egress rule of the source allowing the traffic, if any.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"ingress_security_group_rule_id": {
+							Description: "The ID of the ingress rule of the destination allowing the traffic, if any.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"reason": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"total_pairs": {
+				Description: "The number of pairs, including those not returned.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"truncated": {
+				Description: "Whether there are pairs after those returned.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"next_pairs_offset": {
+				Description: "The `pairs_offset` reading the pairs after those returned, or 0 if there are none.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// ec2InstanceConnectivityMatrixSelectionSchema returns the schema of the source and destination selections of the
+// awsutils_ec2_instance_connectivity_matrix data source.
+func ec2InstanceConnectivityMatrixSelectionSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Description: description,
+		Type:        schema.TypeList,
+		Required:    true,
+		MaxItems:    1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"ids":        ec2IDsSchema(ec2.ResourceTypeInstance),
+				"ids_strict": ec2IDsStrictSchema(),
+				"filter":     ec2CustomFiltersSchema(),
+				"tags":       tagsSchema(),
+			},
+		},
+	}
+}
+
+// ec2ConnectivityEndpoint is an Instance of the source or destination selection, as seen by the rules of the
+// Security Groups.
+type ec2ConnectivityEndpoint struct {
+	InstanceID string
+	GroupIDs   map[string]bool
+	Addresses  []net.IP
+}
+
+func dataSourceAwsUtilsEc2InstanceConnectivityMatrixRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	protocol := d.Get("protocol").(string)
+	port := int64(d.Get("port").(int))
+	allowedOnly := d.Get("allowed_only").(bool)
+	maxPairs := d.Get("max_pairs").(int)
+	offset := d.Get("pairs_offset").(int)
+
+	sources, warnings, err := ec2InstanceConnectivityMatrixEndpoints(ctx, d, meta, conn, "source")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	destinations, destinationWarnings, err := ec2InstanceConnectivityMatrixEndpoints(ctx, d, meta, conn, "destination")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, destinationWarnings...)
+
+	groupIDSet := make(map[string]bool)
+	for _, endpoints := range [][]*ec2ConnectivityEndpoint{sources, destinations} {
+		for _, endpoint := range endpoints {
+			for groupID := range endpoint.GroupIDs {
+				groupIDSet[groupID] = true
+			}
+		}
+	}
+	groupIDs := make([]string, 0, len(groupIDSet))
+	for groupID := range groupIDSet {
+		groupIDs = append(groupIDs, groupID)
+	}
+	sort.Strings(groupIDs)
+
+	var rules []*ec2.SecurityGroupRule
+	if len(groupIDs) > 0 {
+		rules, err = finder.SecurityGroupRulesForGroups(conn, groupIDs)
+		if err != nil {
+			return diag.Errorf("error reading EC2 Security Group Rules: %s", err)
+		}
+	}
+
+	egressRules := make(map[string][]*ec2.SecurityGroupRule)
+	ingressRules := make(map[string][]*ec2.SecurityGroupRule)
+	prefixLists := make(map[string][]*net.IPNet)
+
+	for _, rule := range rules {
+		if !ec2ConnectivityRuleAllowsPort(rule, protocol, port) {
+			continue
+		}
+
+		if prefixListID := aws.StringValue(rule.PrefixListId); prefixListID != "" {
+			if _, ok := prefixLists[prefixListID]; !ok {
+				cidrBlocks, err := ec2ConnectivityPrefixListCIDRBlocks(conn, prefixListID)
+				if err != nil {
+					return diag.Errorf("error reading EC2 Managed Prefix List (%s) entries: %s", prefixListID, err)
+				}
+				prefixLists[prefixListID] = cidrBlocks
+			}
+		}
+
+		groupID := aws.StringValue(rule.GroupId)
+		if aws.BoolValue(rule.IsEgress) {
+			egressRules[groupID] = append(egressRules[groupID], rule)
+		} else {
+			ingressRules[groupID] = append(ingressRules[groupID], rule)
+		}
+	}
+
+	var pairs []map[string]interface{}
+	total := 0
+
+	for _, source := range sources {
+		for _, destination := range destinations {
+			if source.InstanceID == destination.InstanceID {
+				continue
+			}
+
+			egressRule := ec2ConnectivityMatchingRule(source, destination, egressRules, prefixLists)
+			var ingressRule *ec2.SecurityGroupRule
+			if egressRule != nil {
+				ingressRule = ec2ConnectivityMatchingRule(destination, source, ingressRules, prefixLists)
+			}
+
+			allowed := egressRule != nil && ingressRule != nil
+			if allowedOnly && !allowed {
+				continue
+			}
+
+			total++
+			if total <= offset || len(pairs) >= maxPairs {
+				continue
+			}
+
+			pair := map[string]interface{}{
+				"source_instance_id":      source.InstanceID,
+				"destination_instance_id": destination.InstanceID,
+				"allowed":                 allowed,
+			}
+
+			switch {
+			case egressRule == nil:
+				pair["reason"] = fmt.Sprintf("no egress rule of the Security Groups of the source allows %s/%d to the destination", protocol, port)
+			case ingressRule == nil:
+				pair["egress_security_group_rule_id"] = aws.StringValue(egressRule.SecurityGroupRuleId)
+				pair["reason"] = fmt.Sprintf("no ingress rule of the Security Groups of the destination allows %s/%d from the source", protocol, port)
+			default:
+				pair["egress_security_group_rule_id"] = aws.StringValue(egressRule.SecurityGroupRuleId)
+				pair["ingress_security_group_rule_id"] = aws.StringValue(ingressRule.SecurityGroupRuleId)
+				pair["reason"] = fmt.Sprintf("allowed by the egress rule %s of %s and the ingress rule %s of %s",
+					aws.StringValue(egressRule.SecurityGroupRuleId), aws.StringValue(egressRule.GroupId),
+					aws.StringValue(ingressRule.SecurityGroupRuleId), aws.StringValue(ingressRule.GroupId))
+			}
+
+			pairs = append(pairs, pair)
+		}
+	}
+
+	truncated := offset+len(pairs) < total
+	nextOffset := 0
+	if truncated {
+		nextOffset = offset + len(pairs)
+	}
+
+	d.SetId(region)
+
+	if err := d.Set("pairs", pairs); err != nil {
+		return diag.Errorf("error setting pairs: %s", err)
+	}
+
+	if err := d.Set("total_pairs", total); err != nil {
+		return diag.Errorf("error setting total_pairs: %s", err)
+	}
+
+	if err := d.Set("truncated", truncated); err != nil {
+		return diag.Errorf("error setting truncated: %s", err)
+	}
+
+	if err := d.Set("next_pairs_offset", nextOffset); err != nil {
+		return diag.Errorf("error setting next_pairs_offset: %s", err)
+	}
+
+	return warnings
+}
+
+// ec2InstanceConnectivityMatrixEndpoints reads the Instances of the given selection attribute, "source" or
+// "destination", leaving out the terminated ones, and returns them ordered by ID, along with the warnings about the
+// "filter" blocks of the selection.
+func ec2InstanceConnectivityMatrixEndpoints(ctx context.Context, d *schema.ResourceData, meta interface{}, conn *ec2.EC2, key string) ([]*ec2ConnectivityEndpoint, diag.Diagnostics, error) {
+	var selection map[string]interface{}
+	if v := d.Get(key).([]interface{}); len(v) > 0 && v[0] != nil {
+		selection = v[0].(map[string]interface{})
+	}
+
+	var ids []string
+	var filterSet *schema.Set
+	var tags map[string]interface{}
+	if selection != nil {
+		ids = ExpandStringSliceofPointers(ExpandStringSet(selection["ids"].(*schema.Set)))
+		filterSet = selection["filter"].(*schema.Set)
+		tags = selection["tags"].(map[string]interface{})
+	}
+
+	input := &ec2.DescribeInstancesInput{}
+	idParameter, idFilters := buildEC2IDSelection(ec2.ResourceTypeInstance, ids)
+	input.InstanceIds = idParameter
+
+	filters, warnings, err := buildEC2SelectionFilters(tags, "", filterSet, meta.(*AWSClient).escapeFilterWildcards, meta.(*AWSClient).IgnoreTagsConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", key, err)
+	}
+	if filters = append(filters, idFilters...); len(filters) > 0 {
+		input.Filters = filters
+	}
+
+	var instances []*ec2.Instance
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		instances, err = finder.Instances(conn, input, maxResultsCap(d, meta))
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading %s EC2 Instances: %w", key, maxResultsCapError(err))
+	}
+
+	endpoints := make([]*ec2ConnectivityEndpoint, 0, len(instances))
+	for _, instance := range instances {
+		if instance.State != nil && aws.StringValue(instance.State.Name) == ec2.InstanceStateNameTerminated {
+			continue
+		}
+
+		endpoints = append(endpoints, ec2ConnectivityEndpointForInstance(instance))
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].InstanceID < endpoints[j].InstanceID
+	})
+
+	return endpoints, warnings, nil
+}
+
+// ec2ConnectivityEndpointForInstance returns the Security Groups and the private addresses of all the Network
+// Interfaces of the given Instance.
+func ec2ConnectivityEndpointForInstance(instance *ec2.Instance) *ec2ConnectivityEndpoint {
+	endpoint := &ec2ConnectivityEndpoint{
+		InstanceID: aws.StringValue(instance.InstanceId),
+		GroupIDs:   make(map[string]bool),
+	}
+
+	addresses := make(map[string]bool)
+	addAddress := func(address string) {
+		if address == "" || addresses[address] {
+			return
+		}
+		addresses[address] = true
+
+		if ip := net.ParseIP(address); ip != nil {
+			endpoint.Addresses = append(endpoint.Addresses, ip)
+		}
+	}
+
+	for _, group := range instance.SecurityGroups {
+		endpoint.GroupIDs[aws.StringValue(group.GroupId)] = true
+	}
+	addAddress(aws.StringValue(instance.PrivateIpAddress))
+
+	for _, networkInterface := range instance.NetworkInterfaces {
+		for _, group := range networkInterface.Groups {
+			endpoint.GroupIDs[aws.StringValue(group.GroupId)] = true
+		}
+		for _, address := range networkInterface.PrivateIpAddresses {
+			addAddress(aws.StringValue(address.PrivateIpAddress))
+		}
+		for _, address := range networkInterface.Ipv6Addresses {
+			addAddress(aws.StringValue(address.Ipv6Address))
+		}
+	}
+
+	return endpoint
+}
+
+// ec2ConnectivityRuleAllowsPort returns whether the given Security Group Rule allows the given protocol, "tcp" or
+// "udp", and port.
+func ec2ConnectivityRuleAllowsPort(rule *ec2.SecurityGroupRule, protocol string, port int64) bool {
+	switch ruleProtocol := aws.StringValue(rule.IpProtocol); ruleProtocol {
+	case "-1", "all":
+		return true
+	case "6":
+		if protocol != "tcp" {
+			return false
+		}
+	case "17":
+		if protocol != "udp" {
+			return false
+		}
+	default:
+		if ruleProtocol != protocol {
+			return false
+		}
+	}
+
+	from, to := aws.Int64Value(rule.FromPort), aws.Int64Value(rule.ToPort)
+	if from == -1 && to == -1 {
+		return true
+	}
+
+	return from <= port && port <= to
+}
+
+// ec2ConnectivityMatchingRule returns the first of the given rules of the Security Groups of endpoint, keyed by
+// Security Group ID, covering peer, or nil if none does. The rules of each Security Group are tried in the order
+// they are given in, the Security Groups being tried ordered by ID, so that the returned rule is stable.
+func ec2ConnectivityMatchingRule(endpoint, peer *ec2ConnectivityEndpoint, rules map[string][]*ec2.SecurityGroupRule, prefixLists map[string][]*net.IPNet) *ec2.SecurityGroupRule {
+	groupIDs := make([]string, 0, len(endpoint.GroupIDs))
+	for groupID := range endpoint.GroupIDs {
+		groupIDs = append(groupIDs, groupID)
+	}
+	sort.Strings(groupIDs)
+
+	for _, groupID := range groupIDs {
+		for _, rule := range rules[groupID] {
+			if ec2ConnectivityRuleCovers(rule, peer, prefixLists) {
+				return rule
+			}
+		}
+	}
+
+	return nil
+}
+
+// ec2ConnectivityRuleCovers returns whether the source, for an ingress rule, or the destination, for an egress rule,
+// of the given Security Group Rule covers the given endpoint: the referenced Security Group is one of its Security
+// Groups, or the CIDR block, or one of those of the given Managed Prefix List CIDR blocks, contains one of its
+// addresses.
+func ec2ConnectivityRuleCovers(rule *ec2.SecurityGroupRule, endpoint *ec2ConnectivityEndpoint, prefixLists map[string][]*net.IPNet) bool {
+	if rule.ReferencedGroupInfo != nil && aws.StringValue(rule.ReferencedGroupInfo.GroupId) != "" {
+		return endpoint.GroupIDs[aws.StringValue(rule.ReferencedGroupInfo.GroupId)]
+	}
+
+	var cidrBlocks []*net.IPNet
+	for _, cidrBlock := range []string{aws.StringValue(rule.CidrIpv4), aws.StringValue(rule.CidrIpv6)} {
+		if cidrBlock == "" {
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(cidrBlock)
+		if err != nil {
+			log.Printf("[WARN] Error parsing CIDR block (%s) of EC2 Security Group Rule (%s): %s", cidrBlock, aws.StringValue(rule.SecurityGroupRuleId), err)
+			continue
+		}
+		cidrBlocks = append(cidrBlocks, ipNet)
+	}
+	if prefixListID := aws.StringValue(rule.PrefixListId); prefixListID != "" {
+		cidrBlocks = append(cidrBlocks, prefixLists[prefixListID]...)
+	}
+
+	for _, ipNet := range cidrBlocks {
+		for _, address := range endpoint.Addresses {
+			if ipNet.Contains(address) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ec2ConnectivityPrefixListCIDRBlocks returns the CIDR blocks of the entries of the given Managed Prefix List.
+func ec2ConnectivityPrefixListCIDRBlocks(conn *ec2.EC2, prefixListID string) ([]*net.IPNet, error) {
+	entries, err := finder.ManagedPrefixListEntries(conn, prefixListID)
+	if err != nil {
+		return nil, err
+	}
+
+	cidrBlocks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		_, ipNet, err := net.ParseCIDR(aws.StringValue(entry.Cidr))
+		if err != nil {
+			log.Printf("[WARN] Error parsing CIDR block (%s) of EC2 Managed Prefix List (%s): %s", aws.StringValue(entry.Cidr), prefixListID, err)
+			continue
+		}
+		cidrBlocks = append(cidrBlocks, ipNet)
+	}
+
+	return cidrBlocks, nil
+}
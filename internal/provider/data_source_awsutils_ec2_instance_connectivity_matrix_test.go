@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2ConnectivityRuleAllowsPort(t *testing.T) {
+	rule := func(protocol string, from, to int64) *ec2.SecurityGroupRule {
+		return &ec2.SecurityGroupRule{IpProtocol: aws.String(protocol), FromPort: aws.Int64(from), ToPort: aws.Int64(to)}
+	}
+
+	testCases := []struct {
+		Name     string
+		Rule     *ec2.SecurityGroupRule
+		Protocol string
+		Port     int64
+		Expected bool
+	}{
+		{Name: "all protocols", Rule: rule("-1", -1, -1), Protocol: "udp", Port: 53, Expected: true},
+		{Name: "in range", Rule: rule("tcp", 1024, 2048), Protocol: "tcp", Port: 1024, Expected: true},
+		{Name: "out of range", Rule: rule("tcp", 1024, 2048), Protocol: "tcp", Port: 2049},
+		{Name: "other protocol", Rule: rule("udp", 0, 65535), Protocol: "tcp", Port: 443},
+		{Name: "protocol number", Rule: rule("6", 443, 443), Protocol: "tcp", Port: 443, Expected: true},
+		{Name: "other protocol number", Rule: rule("17", 443, 443), Protocol: "tcp", Port: 443},
+		{Name: "icmp", Rule: rule("icmp", -1, -1), Protocol: "tcp", Port: 443},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := ec2ConnectivityRuleAllowsPort(testCase.Rule, testCase.Protocol, testCase.Port); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestDataSourceAwsUtilsEc2InstanceConnectivityMatrixRead(t *testing.T) {
+	instance := func(id, groupID, address string) *ec2.Instance {
+		return &ec2.Instance{
+			InstanceId:       aws.String(id),
+			PrivateIpAddress: aws.String(address),
+			SecurityGroups:   []*ec2.GroupIdentifier{{GroupId: aws.String(groupID)}},
+			State:            &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+		}
+	}
+	sources := []*ec2.Instance{
+		instance("i-00000002", "sg-00000002", "10.0.2.2"),
+		instance("i-00000001", "sg-00000001", "10.0.1.1"),
+	}
+	destinations := []*ec2.Instance{
+		instance("i-00000003", "sg-00000003", "10.0.3.3"),
+		instance("i-00000004", "sg-00000004", "10.0.4.4"),
+		{InstanceId: aws.String("i-00000005"), State: &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameTerminated)}},
+	}
+	rules := []*ec2.SecurityGroupRule{
+		{SecurityGroupRuleId: aws.String("sgr-00000001"), GroupId: aws.String("sg-00000001"), IsEgress: aws.Bool(true), IpProtocol: aws.String("-1"), FromPort: aws.Int64(-1), ToPort: aws.Int64(-1), CidrIpv4: aws.String("0.0.0.0/0")},
+		{SecurityGroupRuleId: aws.String("sgr-00000002"), GroupId: aws.String("sg-00000002"), IsEgress: aws.Bool(true), IpProtocol: aws.String("tcp"), FromPort: aws.Int64(5432), ToPort: aws.Int64(5432), CidrIpv4: aws.String("10.0.0.0/16")},
+		{SecurityGroupRuleId: aws.String("sgr-00000003"), GroupId: aws.String("sg-00000003"), IsEgress: aws.Bool(false), IpProtocol: aws.String("tcp"), FromPort: aws.Int64(5432), ToPort: aws.Int64(5432), ReferencedGroupInfo: &ec2.ReferencedSecurityGroup{GroupId: aws.String("sg-00000001")}},
+		{SecurityGroupRuleId: aws.String("sgr-00000004"), GroupId: aws.String("sg-00000004"), IsEgress: aws.Bool(false), IpProtocol: aws.String("tcp"), FromPort: aws.Int64(5432), ToPort: aws.Int64(5432), PrefixListId: aws.String("pl-00000001")},
+		{SecurityGroupRuleId: aws.String("sgr-00000005"), GroupId: aws.String("sg-00000004"), IsEgress: aws.Bool(false), IpProtocol: aws.String("tcp"), FromPort: aws.Int64(22), ToPort: aws.Int64(22), CidrIpv4: aws.String("10.0.1.0/24")},
+	}
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeInstancesOutput:
+			// The sources are selected by ID, the destinations by tag.
+			if len(r.Params.(*ec2.DescribeInstancesInput).InstanceIds) > 0 {
+				output.Reservations = []*ec2.Reservation{{Instances: sources}}
+			} else {
+				output.Reservations = []*ec2.Reservation{{Instances: destinations}}
+			}
+		case *ec2.DescribeSecurityGroupRulesOutput:
+			output.SecurityGroupRules = rules
+		case *ec2.GetManagedPrefixListEntriesOutput:
+			output.Entries = []*ec2.PrefixListEntry{{Cidr: aws.String("10.0.2.0/24")}}
+		}
+	})
+
+	pair := func(source, destination string, allowed bool, egressRuleID, ingressRuleID, reason string) map[string]interface{} {
+		return map[string]interface{}{
+			"source_instance_id":             source,
+			"destination_instance_id":        destination,
+			"allowed":                        allowed,
+			"egress_security_group_rule_id":  egressRuleID,
+			"ingress_security_group_rule_id": ingressRuleID,
+			"reason":                         reason,
+		}
+	}
+	allowed13 := pair("i-00000001", "i-00000003", true, "sgr-00000001", "sgr-00000003", "allowed by the egress rule sgr-00000001 of sg-00000001 and the ingress rule sgr-00000003 of sg-00000003")
+	denied14 := pair("i-00000001", "i-00000004", false, "sgr-00000001", "", "no ingress rule of the Security Groups of the destination allows tcp/5432 from the source")
+	denied23 := pair("i-00000002", "i-00000003", false, "sgr-00000002", "", "no ingress rule of the Security Groups of the destination allows tcp/5432 from the source")
+	allowed24 := pair("i-00000002", "i-00000004", true, "sgr-00000002", "sgr-00000004", "allowed by the egress rule sgr-00000002 of sg-00000002 and the ingress rule sgr-00000004 of sg-00000004")
+
+	testCases := []struct {
+		Name              string
+		Raw               map[string]interface{}
+		ExpectedPairs     []interface{}
+		ExpectedTotal     int
+		ExpectedTruncated bool
+		ExpectedNext      int
+	}{
+		{
+			Name:          "all pairs",
+			ExpectedPairs: []interface{}{allowed13, denied14, denied23, allowed24},
+			ExpectedTotal: 4,
+		},
+		{
+			Name:              "first page",
+			Raw:               map[string]interface{}{"max_pairs": 3},
+			ExpectedPairs:     []interface{}{allowed13, denied14, denied23},
+			ExpectedTotal:     4,
+			ExpectedTruncated: true,
+			ExpectedNext:      3,
+		},
+		{
+			Name:          "last page",
+			Raw:           map[string]interface{}{"max_pairs": 3, "pairs_offset": 3},
+			ExpectedPairs: []interface{}{allowed24},
+			ExpectedTotal: 4,
+		},
+		{
+			Name:          "allowed only",
+			Raw:           map[string]interface{}{"allowed_only": true},
+			ExpectedPairs: []interface{}{allowed13, allowed24},
+			ExpectedTotal: 2,
+		},
+		{
+			Name: "other port",
+			Raw:  map[string]interface{}{"port": 22, "allowed_only": true},
+			ExpectedPairs: []interface{}{
+				pair("i-00000001", "i-00000004", true, "sgr-00000001", "sgr-00000005", "allowed by the egress rule sgr-00000001 of sg-00000001 and the ingress rule sgr-00000005 of sg-00000004"),
+			},
+			ExpectedTotal: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			raw := map[string]interface{}{
+				"source": []interface{}{
+					map[string]interface{}{"ids": []interface{}{"i-00000001", "i-00000002"}},
+				},
+				"destination": []interface{}{
+					map[string]interface{}{"tags": map[string]interface{}{"Role": "db"}},
+				},
+				"port": 5432,
+			}
+			for k, v := range testCase.Raw {
+				raw[k] = v
+			}
+
+			d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2InstanceConnectivityMatrix().Schema, raw)
+			if diags := dataSourceAwsUtilsEc2InstanceConnectivityMatrixRead(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-east-1"}); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if got := d.Get("pairs").([]interface{}); !reflect.DeepEqual(got, testCase.ExpectedPairs) {
+				t.Errorf("got pairs %v, expected %v", got, testCase.ExpectedPairs)
+			}
+			if got := d.Get("total_pairs").(int); got != testCase.ExpectedTotal {
+				t.Errorf("got total_pairs %d, expected %d", got, testCase.ExpectedTotal)
+			}
+			if got := d.Get("truncated").(bool); got != testCase.ExpectedTruncated {
+				t.Errorf("got truncated %t, expected %t", got, testCase.ExpectedTruncated)
+			}
+			if got := d.Get("next_pairs_offset").(int); got != testCase.ExpectedNext {
+				t.Errorf("got next_pairs_offset %d, expected %d", got, testCase.ExpectedNext)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2InstancesAttributeFilterNames maps the scalar attributes of the awsutils_ec2_instances data source to the names
+// of the DescribeInstances filters they are converted to.
+var ec2InstancesAttributeFilterNames = map[string]string{
+	"availability_zone": "availability-zone",
+	"image_id":          "image-id",
+	"instance_type":     "instance-type",
+	"subnet_id":         "subnet-id",
+	"vpc_id":            "vpc-id",
+}
+
+const (
+	// ec2InstancesMatchAll is the "match" mode of the awsutils_ec2_instances data source combining all the filters
+	// in a single request.
+	ec2InstancesMatchAll = "all"
+	// ec2InstancesMatchAny is the "match" mode of the awsutils_ec2_instances data source making a request for each
+	// "filter" block and listing the instances matching any of them.
+	ec2InstancesMatchAny = "any"
+)
+
+func dataSourceAwsUtilsEc2Instances() *schema.Resource {
+	return &schema.Resource{
+		Description: `Lists the IDs, private IPs and Availability Zones of the EC2 Instances matching the given filters.
+
+The ` + "`filter`" + ` blocks, the ` + "`tags`" + `, ` + "`name`" + ` and ` + "`cloudformation_stack_name`" + ` and the scalar attributes such as ` + "`vpc_id`" + ` are
+merged into a single set of filters, the filters sharing a name matching any of their values. Instances in every
+state are included unless constrained by ` + "`instance_state_names`" + `. No instance matching is not an error, the
+lists being empty.
+
+The ` + "`filter`" + ` blocks sharing a name are merged into one matching any of their values. Setting ` + "`match`" + ` to
+` + "`any`" + ` matches the instances matching any of the ` + "`filter`" + ` blocks instead of all of them, which the EC2 API
+cannot express: a request is made for each block, together with the other selection attributes, and the results are
+combined. This makes as many requests as there are enabled blocks, each paginated and counted against the API rate
+limits, so it is slower and more likely to be throttled than a single request. The ` + "`max_results_cap`" + ` applies to each
+request, and ` + "`applied_filters`" + ` and ` + "`resolved_filters`" + ` then hold the filters sent with every request, without
+those of the blocks. The ` + "`regex_filter`" + ` blocks are matched on the client side against the instances returned.`,
+		ReadContext:   dataSourceAwsUtilsEc2InstancesRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"regex_filter":              ec2RegexFiltersSchema(),
+			"tags":                      tagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"availability_zone": {
+				Description: "Only match the instances in the given Availability Zone.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"image_id": {
+				Description: "Only match the instances launched from the given AMI.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"instance_type": {
+				Description: "Only match the instances of the given type.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"subnet_id": {
+				Description: "Only match the instances in the given Subnet.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"vpc_id": {
+				Description: "Only match the instances in the given VPC.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"instance_state_names": {
+				Description: "Only match the instances in any of the given states, such as `running` or `stopped`.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(ec2.InstanceStateName_Values(), false),
+				},
+			},
+			"match": {
+				Description:  "Whether the instances must match `all` of the `filter` blocks, in a single request, or `any` of them, with a request for each block. The other selection attributes always apply. Defaults to `all`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      ec2InstancesMatchAll,
+				ValidateFunc: validation.StringInSlice([]string{ec2InstancesMatchAll, ec2InstancesMatchAny}, false),
+			},
+			"max_results_cap":    maxResultsCapSchema(),
+			"applied_filters":    ec2AppliedFiltersSchema(),
+			"resolved_filters":   ec2ResolvedFiltersSchema(),
+			"filters_object":     ec2FiltersObjectSchema(),
+			"allow_unfiltered":   ec2AllowUnfilteredSchema(),
+			"prometheus_metrics": prometheusMetricsSchema(),
+			"ids": {
+				Description: "The IDs of the matching instances, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"private_ips": {
+				Description: "The primary private IPv4 addresses of the matching instances, in the order of `ids`. Empty for instances without one, such as terminated instances.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"availability_zones": {
+				Description: "The Availability Zones of the matching instances, in the order of `ids`.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2InstancesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var filters []*ec2.Filter
+	var requestFilters [][]*ec2.Filter
+	var warnings diag.Diagnostics
+
+	if d.Get("match").(string) == ec2InstancesMatchAny {
+		filters, requestFilters, warnings, err = buildEC2InstancesAnyFilters(d, meta)
+	} else {
+		filters, warnings, err = buildEC2InstancesFilters(d, meta)
+		requestFilters = [][]*ec2.Filter{filters}
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, nil, filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Instance)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var instances []*ec2.Instance
+	seen := make(map[string]bool)
+
+	for _, requestFilter := range requestFilters {
+		var found []*ec2.Instance
+		err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+			found, err = finder.Instances(conn, &ec2.DescribeInstancesInput{Filters: requestFilter}, maxResultsCap(d, meta))
+			return err
+		})
+		if err != nil {
+			return diag.Errorf("error reading EC2 Instances: %s", maxResultsCapError(err))
+		}
+
+		for _, instance := range found {
+			if id := aws.StringValue(instance.InstanceId); !seen[id] && regexMatched(instance) {
+				seen[id] = true
+				instances = append(instances, instance)
+			}
+		}
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		return aws.StringValue(instances[i].InstanceId) < aws.StringValue(instances[j].InstanceId)
+	})
+
+	ids := make([]string, 0, len(instances))
+	privateIPs := make([]string, 0, len(instances))
+	availabilityZones := make([]string, 0, len(instances))
+
+	for _, instance := range instances {
+		ids = append(ids, aws.StringValue(instance.InstanceId))
+		privateIPs = append(privateIPs, aws.StringValue(instance.PrivateIpAddress))
+
+		var availabilityZone string
+		if instance.Placement != nil {
+			availabilityZone = aws.StringValue(instance.Placement.AvailabilityZone)
+		}
+		availabilityZones = append(availabilityZones, availabilityZone)
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("ids", ids); err != nil {
+		return diag.Errorf("error setting ids: %s", err)
+	}
+
+	if err := d.Set("private_ips", privateIPs); err != nil {
+		return diag.Errorf("error setting private_ips: %s", err)
+	}
+
+	if err := d.Set("availability_zones", availabilityZones); err != nil {
+		return diag.Errorf("error setting availability_zones: %s", err)
+	}
+
+	if err := setPrometheusMetrics(d, ec2InstancesPrometheusMetric(instances)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return warnings
+}
+
+// ec2InstancesPrometheusMetric returns the awsutils_ec2_instances metric counting the given instances by state.
+func ec2InstancesPrometheusMetric(instances []*ec2.Instance) prometheusMetric {
+	states := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		var state string
+		if instance.State != nil {
+			state = aws.StringValue(instance.State.Name)
+		}
+		states = append(states, state)
+	}
+
+	return prometheusMetric{
+		Name:    "awsutils_ec2_instances",
+		Help:    "The number of matching EC2 Instances by state.",
+		Samples: prometheusCountSamples("state", states),
+	}
+}
+
+// buildEC2InstancesFilters returns the filters of the awsutils_ec2_instances data source with the given
+// *schema.ResourceData: those of its "tags", "name" and "filter" attributes, as built by buildEC2SelectionFilters,
+// merged with mergeEC2FilterLists with those of its scalar attributes, "cloudformation_stack_name" and
+// "instance_state_names". The warnings about the "filter" blocks are returned along with the filters.
+func buildEC2InstancesFilters(d *schema.ResourceData, meta interface{}) ([]*ec2.Filter, diag.Diagnostics, error) {
+	var filterSet *schema.Set
+	if v, ok := d.GetOk("filter"); ok {
+		filterSet = v.(*schema.Set)
+	}
+
+	return buildEC2InstancesFiltersWithSet(d, meta, filterSet)
+}
+
+// buildEC2InstancesAnyFilters returns the filters of the awsutils_ec2_instances data source with the given
+// *schema.ResourceData when "match" is "any": the filters of all its selection attributes but the "filter" blocks,
+// as built by buildEC2InstancesFilters, and those of each request, made for each enabled "filter" block, with
+// the filter of the block merged into them. A single request is made with the former when no block is enabled.
+// The warnings about the "filter" blocks are returned along with the filters.
+func buildEC2InstancesAnyFilters(d *schema.ResourceData, meta interface{}) ([]*ec2.Filter, [][]*ec2.Filter, diag.Diagnostics, error) {
+	filters, _, err := buildEC2InstancesFiltersWithSet(d, meta, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var blocks []*ec2.Filter
+	var warnings diag.Diagnostics
+	if v, ok := d.GetOk("filter"); ok {
+		filterSet := v.(*schema.Set)
+		if err := validateEC2CustomFilters(filterSet); err != nil {
+			return nil, nil, nil, err
+		}
+
+		var diags diag.Diagnostics
+		blocks, diags = buildEC2CustomFilterBlockList(filterSet, meta.(*AWSClient).escapeFilterWildcards)
+		if warnings, err = splitEC2CustomFilterDiagnostics(diags); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if len(blocks) == 0 {
+		return filters, [][]*ec2.Filter{filters}, warnings, nil
+	}
+
+	requestFilters := make([][]*ec2.Filter, 0, len(blocks))
+	for _, block := range blocks {
+		merged := mergeEC2FilterLists(filters, []*ec2.Filter{block})
+		if err := meta.(*AWSClient).validateEC2FilterLimits(merged); err != nil {
+			return nil, nil, nil, err
+		}
+		requestFilters = append(requestFilters, merged)
+	}
+
+	return filters, requestFilters, warnings, nil
+}
+
+// buildEC2InstancesFiltersWithSet is buildEC2InstancesFilters with the given set value of the "filter" attribute,
+// which may be nil to leave the "filter" blocks out.
+func buildEC2InstancesFiltersWithSet(d *schema.ResourceData, meta interface{}, filterSet *schema.Set) ([]*ec2.Filter, diag.Diagnostics, error) {
+	var tags map[string]interface{}
+	if v, ok := d.GetOk("tags"); ok {
+		tags = v.(map[string]interface{})
+	}
+
+	selectionFilters, warnings, err := buildEC2SelectionFilters(tags, d.Get("name").(string), filterSet, meta.(*AWSClient).escapeFilterWildcards, meta.(*AWSClient).IgnoreTagsConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attrs := make(map[string]string, len(ec2InstancesAttributeFilterNames))
+	for attribute, name := range ec2InstancesAttributeFilterNames {
+		attrs[name] = d.Get(attribute).(string)
+	}
+
+	states := ExpandStringSliceofPointers(ExpandStringSet(d.Get("instance_state_names").(*schema.Set)))
+	sort.Strings(states)
+
+	var stateFilters []*ec2.Filter
+	stateFilter, err := ec2InstanceStateFilter(states...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stateFilter != nil {
+		stateFilters = []*ec2.Filter{stateFilter}
+	}
+
+	stackNameFilters := buildEC2CloudFormationStackNameFilterList(d.Get("cloudformation_stack_name").(string))
+	if meta.(*AWSClient).escapeFilterWildcards {
+		escapeEC2FilterWildcards(stackNameFilters...)
+	}
+
+	filters := mergeEC2FilterLists(buildEC2AttributeFilterList(attrs), selectionFilters, stackNameFilters, stateFilters)
+	if err := meta.(*AWSClient).validateEC2FilterLimits(filters); err != nil {
+		return nil, nil, err
+	}
+
+	return filters, warnings, nil
+}
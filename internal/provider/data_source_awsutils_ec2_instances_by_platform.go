@@ -0,0 +1,251 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	ec2InstancePlatformWindows   = "Windows"
+	ec2InstancePlatformLinuxUnix = "Linux/UNIX"
+)
+
+func dataSourceAwsUtilsEc2InstancesByPlatform() *schema.Resource {
+	return &schema.Resource{
+		Description: `Groups the EC2 Instances matching the given filters by operating system platform.
+
+The ` + "`platform`" + ` attribute of an instance is only set (to ` + "`windows`" + `) for Windows instances and is empty for all
+others, so each instance is instead classified as ` + "`Windows`" + ` or ` + "`Linux/UNIX`" + ` from its ` + "`platform_details`" + `,
+such as ` + "`Windows with SQL Server Standard`" + `, ` + "`Red Hat Enterprise Linux`" + ` or ` + "`Linux/UNIX`" + `. The
+distinct ` + "`platform_details`" + ` values of each group are reported alongside its instance IDs.
+
+Instances in every state are included unless excluded with ` + "`states`" + ` or an ` + "`instance-state-name`" + ` filter.`,
+		ReadContext:   dataSourceAwsUtilsEc2InstancesByPlatformRead,
+		SchemaVersion: 1,
+		Schema: mergeSchemas(map[string]*schema.Schema{
+			"ids":                       ec2IDsSchema(ec2.ResourceTypeInstance),
+			"ids_strict":                ec2IDsStrictSchema(),
+			"arns":                      ec2ARNsSchema(ec2.ResourceTypeInstance),
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"regex_filter":              ec2RegexFiltersSchema(),
+			"tags":                      tagsSchema(),
+			"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":              ec2ExcludeTagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"debug":                     debugSchema(),
+			"raw_response_json":         rawResponseJSONSchema(),
+			"applied_filters":           ec2AppliedFiltersSchema(),
+			"resolved_filters":          ec2ResolvedFiltersSchema(),
+			"filters_object":            ec2FiltersObjectSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"prometheus_metrics":        prometheusMetricsSchema(),
+			"platforms": {
+				Description: "The instances grouped by platform, ordered by platform.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"platform": {
+							Description: "The platform of the instances, either `Windows` or `Linux/UNIX`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"platform_details": {
+							Description: "The distinct platform details of the instances.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"instance_ids": {
+							Description: "The IDs of the instances.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"windows_instance_ids": {
+				Description: "The IDs of the Windows instances.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"linux_instance_ids": {
+				Description: "The IDs of the Linux/UNIX instances.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		}, ec2SpotInstanceFilterSchemas(), ec2InstanceHibernationFilterSchemas(), ec2InstanceStateFilterSchemas(), resultCacheSchemas()),
+	}
+}
+
+func dataSourceAwsUtilsEc2InstancesByPlatformRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &ec2.DescribeInstancesInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeInstance)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.InstanceIds = ids
+	input.Filters = append(input.Filters, filters...)
+	input.Filters = append(input.Filters, buildEC2SpotInstanceAttributeFilterList(d)...)
+	input.Filters = append(input.Filters, buildEC2InstanceHibernationAttributeFilterList(d)...)
+
+	stateFilters, err := buildEC2InstanceStateAttributeFilterList(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.Filters = append(input.Filters, stateFilters...)
+
+	if len(input.Filters) == 0 {
+		input.Filters = nil
+	}
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var instances []*ec2.Instance
+	err = withResultCache(d, meta, "awsutils_ec2_instances_by_platform", input, &instances, func() error {
+		return describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+			instances, err = finder.Instances(conn, input, maxResultsCap(d, meta))
+			return err
+		})
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Instances: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Instance)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	kept := instances[:0]
+	for _, instance := range instances {
+		if !excluded(instance.Tags) && tagsMatched(instance.Tags) && regexMatched(instance) {
+			kept = append(kept, instance)
+		}
+	}
+	instances = kept
+
+	sort.Slice(instances, func(i, j int) bool {
+		return aws.StringValue(instances[i].InstanceId) < aws.StringValue(instances[j].InstanceId)
+	})
+
+	instanceIDs := make(map[string][]string)
+	platformDetails := make(map[string][]string)
+
+	for _, instance := range instances {
+		platform := ec2InstancePlatform(instance)
+		instanceIDs[platform] = append(instanceIDs[platform], aws.StringValue(instance.InstanceId))
+
+		if details := aws.StringValue(instance.PlatformDetails); details != "" {
+			platformDetails[platform] = appendUniqueString(platformDetails[platform], details)
+		}
+	}
+
+	platforms := make([]string, 0, len(instanceIDs))
+	for platform := range instanceIDs {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	groups := make([]map[string]interface{}, 0, len(platforms))
+	for _, platform := range platforms {
+		sort.Strings(platformDetails[platform])
+
+		groups = append(groups, map[string]interface{}{
+			"platform":         platform,
+			"platform_details": platformDetails[platform],
+			"instance_ids":     instanceIDs[platform],
+		})
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Instances": instances}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("platforms", groups); err != nil {
+		return diag.Errorf("error setting platforms: %s", err)
+	}
+
+	if err := d.Set("windows_instance_ids", instanceIDs[ec2InstancePlatformWindows]); err != nil {
+		return diag.Errorf("error setting windows_instance_ids: %s", err)
+	}
+
+	if err := d.Set("linux_instance_ids", instanceIDs[ec2InstancePlatformLinuxUnix]); err != nil {
+		return diag.Errorf("error setting linux_instance_ids: %s", err)
+	}
+
+	samples := make([]prometheusSample, 0, len(platforms))
+	for _, platform := range platforms {
+		samples = append(samples, prometheusSample{
+			Labels: map[string]string{"platform": platform},
+			Value:  float64(len(instanceIDs[platform])),
+		})
+	}
+
+	if err := setPrometheusMetrics(d, prometheusMetric{
+		Name:    "awsutils_ec2_instances_by_platform",
+		Help:    "The number of matching EC2 Instances by platform.",
+		Samples: samples,
+	}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return warnings
+}
+
+// ec2InstancePlatform returns the platform of the given instance, either Windows or Linux/UNIX. The platform
+// attribute is only set for Windows instances, so the platform details are used when it is empty.
+func ec2InstancePlatform(instance *ec2.Instance) string {
+	// DescribeInstances reports "windows", unlike the "Windows" of ec2.PlatformValuesWindows.
+	if strings.EqualFold(aws.StringValue(instance.Platform), ec2.PlatformValuesWindows) {
+		return ec2InstancePlatformWindows
+	}
+
+	if strings.HasPrefix(aws.StringValue(instance.PlatformDetails), ec2InstancePlatformWindows) {
+		return ec2InstancePlatformWindows
+	}
+
+	return ec2InstancePlatformLinuxUnix
+}
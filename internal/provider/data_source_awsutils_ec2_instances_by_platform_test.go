@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEc2InstancePlatform(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Instance *ec2.Instance
+		Expected string
+	}{
+		{
+			Name: "windows platform",
+			Instance: &ec2.Instance{
+				Platform:        aws.String("windows"),
+				PlatformDetails: aws.String("Windows"),
+			},
+			Expected: ec2InstancePlatformWindows,
+		},
+		{
+			Name: "windows with SQL server",
+			Instance: &ec2.Instance{
+				PlatformDetails: aws.String("Windows with SQL Server Standard"),
+			},
+			Expected: ec2InstancePlatformWindows,
+		},
+		{
+			Name: "linux",
+			Instance: &ec2.Instance{
+				PlatformDetails: aws.String("Linux/UNIX"),
+			},
+			Expected: ec2InstancePlatformLinuxUnix,
+		},
+		{
+			Name: "red hat",
+			Instance: &ec2.Instance{
+				PlatformDetails: aws.String("Red Hat Enterprise Linux"),
+			},
+			Expected: ec2InstancePlatformLinuxUnix,
+		},
+		{
+			Name:     "no platform details",
+			Instance: &ec2.Instance{},
+			Expected: ec2InstancePlatformLinuxUnix,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := ec2InstancePlatform(testCase.Instance); got != testCase.Expected {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ec2SubnetReservedIPAddressCount is the number of IPv4 addresses AWS reserves in each Subnet, which cannot be
+// assigned to network interfaces.
+const ec2SubnetReservedIPAddressCount = 5
+
+func dataSourceAwsUtilsEc2InstancesBySubnetUtilization() *schema.Resource {
+	return &schema.Resource{
+		Description: `Groups the EC2 Instances matching the given filters by Subnet, along with the IPv4 address utilization of
+each Subnet, for capacity planning.
+
+The instances are correlated to the Subnets of their network interfaces, so an instance with network interfaces in
+several Subnets is part of the group of each of them. Instances without a network interface, such as terminated
+instances, fall back to their ` + "`SubnetId`" + `, if any, and are reported in ` + "`unplaced_instance_ids`" + `
+otherwise. The IP address counts are those of the whole Subnet, not only of the matching instances. Instances in every
+state are included unless excluded with ` + "`states`" + ` or an ` + "`instance-state-name`" + ` filter.`,
+		ReadContext:   dataSourceAwsUtilsEc2InstancesBySubnetUtilizationRead,
+		SchemaVersion: 1,
+		Schema: mergeSchemas(map[string]*schema.Schema{
+			"ids":                       ec2IDsSchema(ec2.ResourceTypeInstance),
+			"ids_strict":                ec2IDsStrictSchema(),
+			"arns":                      ec2ARNsSchema(ec2.ResourceTypeInstance),
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"regex_filter":              ec2RegexFiltersSchema(),
+			"tags":                      tagsSchema(),
+			"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":              ec2ExcludeTagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"debug":                     debugSchema(),
+			"raw_response_json":         rawResponseJSONSchema(),
+			"applied_filters":           ec2AppliedFiltersSchema(),
+			"resolved_filters":          ec2ResolvedFiltersSchema(),
+			"filters_object":            ec2FiltersObjectSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"subnets": {
+				Description: "The Subnets of the matching instances, ordered by decreasing `utilization`, then by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"subnet_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vpc_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"availability_zone": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"cidr_block": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"total_ip_address_count": {
+							Description: "The number of IPv4 addresses of the Subnet which can be assigned, those AWS reserves left out. Zero for IPv6-only Subnets and Subnets which could not be read.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"available_ip_address_count": {
+							Description: "The number of IPv4 addresses of the Subnet which are not assigned.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"used_ip_address_count": {
+							Description: "The number of IPv4 addresses of the Subnet which are assigned, to any network interface.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"utilization": {
+							Description: "The ratio of `used_ip_address_count` to `total_ip_address_count`, between 0 and 1.",
+							Type:        schema.TypeFloat,
+							Computed:    true,
+						},
+						"instance_ids": {
+							Description: "The IDs of the matching instances with a network interface in the Subnet, ordered by ID.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"network_interface_ids": {
+							Description: "The IDs of the network interfaces of the matching instances in the Subnet, ordered by ID.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"unplaced_instance_ids": {
+				Description: "The IDs of the matching instances in no Subnet, such as terminated instances, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		}, ec2SpotInstanceFilterSchemas(), ec2InstanceHibernationFilterSchemas(), ec2InstanceStateFilterSchemas(), resultCacheSchemas()),
+	}
+}
+
+func dataSourceAwsUtilsEc2InstancesBySubnetUtilizationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &ec2.DescribeInstancesInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeInstance)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.InstanceIds = ids
+	input.Filters = append(filters, buildEC2SpotInstanceAttributeFilterList(d)...)
+	input.Filters = append(input.Filters, buildEC2InstanceHibernationAttributeFilterList(d)...)
+
+	stateFilters, err := buildEC2InstanceStateAttributeFilterList(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.Filters = append(input.Filters, stateFilters...)
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var instances []*ec2.Instance
+	err = withResultCache(d, meta, "awsutils_ec2_instances_by_subnet_utilization", input, &instances, func() error {
+		return describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+			instances, err = finder.Instances(conn, input, maxResultsCap(d, meta))
+			return err
+		})
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Instances: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Instance)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	kept := instances[:0]
+	for _, instance := range instances {
+		if !excluded(instance.Tags) && tagsMatched(instance.Tags) && regexMatched(instance) {
+			kept = append(kept, instance)
+		}
+	}
+	instances = kept
+
+	groups, unplaced := ec2InstancesBySubnet(instances)
+
+	subnetIDs := make([]string, 0, len(groups))
+	for subnetID := range groups {
+		subnetIDs = append(subnetIDs, subnetID)
+	}
+	sort.Strings(subnetIDs)
+
+	subnets, err := finder.SubnetsByID(conn, subnetIDs)
+	if err != nil {
+		return diag.Errorf("error reading EC2 Subnets: %s", err)
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Instances": instances, "Subnets": subnets}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("subnets", flattenEc2InstancesBySubnetUtilization(groups, subnets)); err != nil {
+		return diag.Errorf("error setting subnets: %s", err)
+	}
+
+	if err := d.Set("unplaced_instance_ids", unplaced); err != nil {
+		return diag.Errorf("error setting unplaced_instance_ids: %s", err)
+	}
+
+	return warnings
+}
+
+// ec2InstancesBySubnetGroup is the group of the instances with a network interface in a Subnet.
+type ec2InstancesBySubnetGroup struct {
+	InstanceIDs         []string
+	NetworkInterfaceIDs []string
+}
+
+// ec2InstancesBySubnet returns the groups of the given instances by the ID of the Subnets of their network
+// interfaces, and the IDs of the instances in no Subnet, ordered by ID. The IDs of each group are ordered by ID too.
+//
+// An instance is part of the group of every Subnet one of its network interfaces is in, once. Instances without a
+// network interface fall back to their SubnetId.
+func ec2InstancesBySubnet(instances []*ec2.Instance) (map[string]*ec2InstancesBySubnetGroup, []string) {
+	groups := make(map[string]*ec2InstancesBySubnetGroup)
+	unplaced := make([]string, 0)
+
+	group := func(subnetID string) *ec2InstancesBySubnetGroup {
+		if groups[subnetID] == nil {
+			groups[subnetID] = &ec2InstancesBySubnetGroup{InstanceIDs: []string{}, NetworkInterfaceIDs: []string{}}
+		}
+		return groups[subnetID]
+	}
+
+	for _, instance := range instances {
+		instanceID := aws.StringValue(instance.InstanceId)
+
+		placed := make(map[string]bool)
+		for _, networkInterface := range instance.NetworkInterfaces {
+			subnetID := aws.StringValue(networkInterface.SubnetId)
+			if subnetID == "" {
+				continue
+			}
+
+			g := group(subnetID)
+			if id := aws.StringValue(networkInterface.NetworkInterfaceId); id != "" {
+				g.NetworkInterfaceIDs = appendUniqueString(g.NetworkInterfaceIDs, id)
+			}
+			if !placed[subnetID] {
+				placed[subnetID] = true
+				g.InstanceIDs = append(g.InstanceIDs, instanceID)
+			}
+		}
+
+		if len(placed) > 0 {
+			continue
+		}
+
+		if subnetID := aws.StringValue(instance.SubnetId); subnetID != "" {
+			g := group(subnetID)
+			g.InstanceIDs = appendUniqueString(g.InstanceIDs, instanceID)
+			continue
+		}
+
+		unplaced = append(unplaced, instanceID)
+	}
+
+	for _, g := range groups {
+		sort.Strings(g.InstanceIDs)
+		sort.Strings(g.NetworkInterfaceIDs)
+	}
+	sort.Strings(unplaced)
+
+	return groups, unplaced
+}
+
+// ec2SubnetTotalIPAddressCount returns the number of IPv4 addresses of the given Subnet which can be assigned, the
+// addresses AWS reserves in each Subnet left out. It is zero for IPv6-only Subnets.
+func ec2SubnetTotalIPAddressCount(subnet *ec2.Subnet) int {
+	_, ipNet, err := net.ParseCIDR(aws.StringValue(subnet.CidrBlock))
+	if err != nil || ipNet.IP.To4() == nil {
+		return 0
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	total := 1<<uint(bits-ones) - ec2SubnetReservedIPAddressCount
+	if total < 0 {
+		return 0
+	}
+
+	return total
+}
+
+// flattenEc2InstancesBySubnetUtilization returns the flattened "subnets" of the given groups of instances, each
+// annotated with the IP address counts of its Subnet, ordered by decreasing utilization, then by Subnet ID. The
+// groups of the Subnets missing from the given Subnets, if any, have no IP address counts.
+func flattenEc2InstancesBySubnetUtilization(groups map[string]*ec2InstancesBySubnetGroup, subnets []*ec2.Subnet) []map[string]interface{} {
+	subnetsByID := make(map[string]*ec2.Subnet, len(subnets))
+	for _, subnet := range subnets {
+		subnetsByID[aws.StringValue(subnet.SubnetId)] = subnet
+	}
+
+	result := make([]map[string]interface{}, 0, len(groups))
+	for subnetID, g := range groups {
+		m := map[string]interface{}{
+			"subnet_id":                  subnetID,
+			"vpc_id":                     "",
+			"availability_zone":          "",
+			"cidr_block":                 "",
+			"total_ip_address_count":     0,
+			"available_ip_address_count": 0,
+			"used_ip_address_count":      0,
+			"utilization":                float64(0),
+			"instance_ids":               g.InstanceIDs,
+			"network_interface_ids":      g.NetworkInterfaceIDs,
+		}
+
+		if subnet, ok := subnetsByID[subnetID]; ok {
+			total := ec2SubnetTotalIPAddressCount(subnet)
+			available := int(aws.Int64Value(subnet.AvailableIpAddressCount))
+
+			used := total - available
+			if used < 0 {
+				used = 0
+			}
+
+			m["vpc_id"] = aws.StringValue(subnet.VpcId)
+			m["availability_zone"] = aws.StringValue(subnet.AvailabilityZone)
+			m["cidr_block"] = aws.StringValue(subnet.CidrBlock)
+			m["total_ip_address_count"] = total
+			m["available_ip_address_count"] = available
+			m["used_ip_address_count"] = used
+			if total > 0 {
+				m["utilization"] = float64(used) / float64(total)
+			}
+		}
+
+		result = append(result, m)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		ui, uj := result[i]["utilization"].(float64), result[j]["utilization"].(float64)
+		if ui != uj {
+			return ui > uj
+		}
+		return result[i]["subnet_id"].(string) < result[j]["subnet_id"].(string)
+	})
+
+	return result
+}
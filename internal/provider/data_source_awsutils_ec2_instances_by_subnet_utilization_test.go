@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testEc2InstanceInSubnets(instanceID string, networkInterfaces map[string]string) *ec2.Instance {
+	instance := &ec2.Instance{InstanceId: aws.String(instanceID)}
+	for networkInterfaceID, subnetID := range networkInterfaces {
+		instance.NetworkInterfaces = append(instance.NetworkInterfaces, &ec2.InstanceNetworkInterface{
+			NetworkInterfaceId: aws.String(networkInterfaceID),
+			SubnetId:           aws.String(subnetID),
+		})
+	}
+	return instance
+}
+
+func TestEc2InstancesBySubnet(t *testing.T) {
+	instances := []*ec2.Instance{
+		testEc2InstanceInSubnets("i-00000002", map[string]string{"eni-00000003": "subnet-00000002", "eni-00000002": "subnet-00000001"}),
+		testEc2InstanceInSubnets("i-00000001", map[string]string{"eni-00000001": "subnet-00000001", "eni-00000004": "subnet-00000001"}),
+		{InstanceId: aws.String("i-00000003"), SubnetId: aws.String("subnet-00000002")},
+		{InstanceId: aws.String("i-00000004")},
+	}
+
+	groups, unplaced := ec2InstancesBySubnet(instances)
+
+	expected := map[string]*ec2InstancesBySubnetGroup{
+		"subnet-00000001": {
+			InstanceIDs:         []string{"i-00000001", "i-00000002"},
+			NetworkInterfaceIDs: []string{"eni-00000001", "eni-00000002", "eni-00000004"},
+		},
+		"subnet-00000002": {
+			InstanceIDs:         []string{"i-00000002", "i-00000003"},
+			NetworkInterfaceIDs: []string{"eni-00000003"},
+		},
+	}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("got groups %v, expected %v", groups, expected)
+	}
+
+	if expected := []string{"i-00000004"}; !reflect.DeepEqual(unplaced, expected) {
+		t.Errorf("got unplaced %v, expected %v", unplaced, expected)
+	}
+}
+
+func TestEc2SubnetTotalIPAddressCount(t *testing.T) {
+	testCases := []struct {
+		CIDRBlock string
+		Expected  int
+	}{
+		{CIDRBlock: "10.0.0.0/24", Expected: 251},
+		{CIDRBlock: "10.0.0.0/16", Expected: 65531},
+		{CIDRBlock: "10.0.0.0/28", Expected: 11},
+		{CIDRBlock: "", Expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.CIDRBlock, func(t *testing.T) {
+			if got := ec2SubnetTotalIPAddressCount(&ec2.Subnet{CidrBlock: aws.String(testCase.CIDRBlock)}); got != testCase.Expected {
+				t.Errorf("got %d, expected %d", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestDataSourceAwsUtilsEc2InstancesBySubnetUtilizationRead(t *testing.T) {
+	instances := []*ec2.Instance{
+		testEc2InstanceInSubnets("i-00000001", map[string]string{"eni-00000001": "subnet-00000001"}),
+		testEc2InstanceInSubnets("i-00000002", map[string]string{"eni-00000002": "subnet-00000002", "eni-00000003": "subnet-00000003"}),
+		testEc2InstanceInSubnets("i-00000003", map[string]string{"eni-00000004": "subnet-00000004"}),
+	}
+	subnets := []*ec2.Subnet{
+		{SubnetId: aws.String("subnet-00000001"), VpcId: aws.String("vpc-00000001"), AvailabilityZone: aws.String("us-east-1a"), CidrBlock: aws.String("10.0.1.0/24"), AvailableIpAddressCount: aws.Int64(201)},
+		{SubnetId: aws.String("subnet-00000002"), VpcId: aws.String("vpc-00000001"), AvailabilityZone: aws.String("us-east-1b"), CidrBlock: aws.String("10.0.2.0/24"), AvailableIpAddressCount: aws.Int64(1)},
+		{SubnetId: aws.String("subnet-00000003"), VpcId: aws.String("vpc-00000001"), AvailabilityZone: aws.String("us-east-1c"), CidrBlock: aws.String("10.0.3.0/24"), AvailableIpAddressCount: aws.Int64(201)},
+	}
+
+	var subnetFilters []*ec2.Filter
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeInstancesOutput:
+			output.Reservations = []*ec2.Reservation{{Instances: instances}}
+		case *ec2.DescribeSubnetsOutput:
+			subnetFilters = r.Params.(*ec2.DescribeSubnetsInput).Filters
+			// subnet-00000004 was deleted since, and is missing from the result.
+			output.Subnets = subnets
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2InstancesBySubnetUtilization().Schema, map[string]interface{}{
+		"tags": map[string]interface{}{"Environment": "production"},
+	})
+	if diags := dataSourceAwsUtilsEc2InstancesBySubnetUtilizationRead(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-east-1"}); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	expectedSubnetFilters := []*ec2.Filter{
+		{Name: aws.String("subnet-id"), Values: aws.StringSlice([]string{"subnet-00000001", "subnet-00000002", "subnet-00000003", "subnet-00000004"})},
+	}
+	if !reflect.DeepEqual(subnetFilters, expectedSubnetFilters) {
+		t.Errorf("got DescribeSubnets filters %v, expected %v", subnetFilters, expectedSubnetFilters)
+	}
+
+	subnet := func(subnetID, availabilityZone, cidrBlock string, total, available, used int, utilization float64, instanceIDs, networkInterfaceIDs []interface{}) map[string]interface{} {
+		vpcID := ""
+		if cidrBlock != "" {
+			vpcID = "vpc-00000001"
+		}
+		return map[string]interface{}{
+			"subnet_id":                  subnetID,
+			"vpc_id":                     vpcID,
+			"availability_zone":          availabilityZone,
+			"cidr_block":                 cidrBlock,
+			"total_ip_address_count":     total,
+			"available_ip_address_count": available,
+			"used_ip_address_count":      used,
+			"utilization":                utilization,
+			"instance_ids":               instanceIDs,
+			"network_interface_ids":      networkInterfaceIDs,
+		}
+	}
+	expected := []interface{}{
+		subnet("subnet-00000002", "us-east-1b", "10.0.2.0/24", 251, 1, 250, 250.0/251.0, []interface{}{"i-00000002"}, []interface{}{"eni-00000002"}),
+		subnet("subnet-00000001", "us-east-1a", "10.0.1.0/24", 251, 201, 50, 50.0/251.0, []interface{}{"i-00000001"}, []interface{}{"eni-00000001"}),
+		subnet("subnet-00000003", "us-east-1c", "10.0.3.0/24", 251, 201, 50, 50.0/251.0, []interface{}{"i-00000002"}, []interface{}{"eni-00000003"}),
+		subnet("subnet-00000004", "", "", 0, 0, 0, 0, []interface{}{"i-00000003"}, []interface{}{"eni-00000004"}),
+	}
+	if got := d.Get("subnets").([]interface{}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got subnets %v, expected %v", got, expected)
+	}
+
+	if got := d.Get("unplaced_instance_ids").([]interface{}); len(got) != 0 {
+		t.Errorf("got unplaced_instance_ids %v, expected none", got)
+	}
+}
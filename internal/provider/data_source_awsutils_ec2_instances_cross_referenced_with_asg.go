@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAwsUtilsEc2InstancesCrossReferencedWithAsg() *schema.Resource {
+	return &schema.Resource{
+		Description: `Cross-references the EC2 Instances matching the given filters with the Auto Scaling Groups they belong to.
+
+The Auto Scaling Group of an instance is read from the ` + "`" + ec2AutoScalingGroupNameTagKey + "`" + ` tag AWS adds to the
+instances it launches, which is read although tags with the reserved ` + "`aws:`" + ` prefix are otherwise ignored. The
+same tag may be used in ` + "`tags`" + ` to select the instances of a group. Instances without the tag, such as those
+launched outside of any Auto Scaling Group, are reported as standalone. Instances which were detached from their group
+keep the tag, and are still reported as members of it. Instances in every state are included unless excluded with
+` + "`states`" + ` or an ` + "`instance-state-name`" + ` filter.`,
+		ReadContext:   dataSourceAwsUtilsEc2InstancesCrossReferencedWithAsgRead,
+		SchemaVersion: 1,
+		Schema: mergeSchemas(map[string]*schema.Schema{
+			"ids":                       ec2IDsSchema(ec2.ResourceTypeInstance),
+			"ids_strict":                ec2IDsStrictSchema(),
+			"arns":                      ec2ARNsSchema(ec2.ResourceTypeInstance),
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"regex_filter":              ec2RegexFiltersSchema(),
+			"tags":                      tagsSchema(),
+			"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":              ec2ExcludeTagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"debug":                     debugSchema(),
+			"raw_response_json":         rawResponseJSONSchema(),
+			"applied_filters":           ec2AppliedFiltersSchema(),
+			"resolved_filters":          ec2ResolvedFiltersSchema(),
+			"filters_object":            ec2FiltersObjectSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"instances": {
+				Description: "The instances, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"instance_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"autoscaling_group_name": {
+							Description: "The name of the Auto Scaling Group of the instance, or an empty string if it is standalone.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"standalone": {
+							Description: "Whether the instance belongs to no Auto Scaling Group.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"autoscaling_groups": {
+				Description: "The instances grouped by Auto Scaling Group, ordered by group name.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"autoscaling_group_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"instance_ids": {
+							Description: "The IDs of the instances, ordered by ID.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"standalone_instance_ids": {
+				Description: "The IDs of the instances belonging to no Auto Scaling Group, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		}, ec2SpotInstanceFilterSchemas(), ec2InstanceHibernationFilterSchemas(), ec2InstanceStateFilterSchemas(), resultCacheSchemas()),
+	}
+}
+
+func dataSourceAwsUtilsEc2InstancesCrossReferencedWithAsgRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &ec2.DescribeInstancesInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeInstance)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.InstanceIds = ids
+	input.Filters = append(filters, buildEC2SpotInstanceAttributeFilterList(d)...)
+	input.Filters = append(input.Filters, buildEC2InstanceHibernationAttributeFilterList(d)...)
+
+	stateFilters, err := buildEC2InstanceStateAttributeFilterList(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.Filters = append(input.Filters, stateFilters...)
+
+	if len(input.Filters) == 0 {
+		input.Filters = nil
+	}
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var instances []*ec2.Instance
+	err = withResultCache(d, meta, "awsutils_ec2_instances_cross_referenced_with_asg", input, &instances, func() error {
+		return describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+			instances, err = finder.Instances(conn, input, maxResultsCap(d, meta))
+			return err
+		})
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Instances: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Instance)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	kept := instances[:0]
+	for _, instance := range instances {
+		if !excluded(instance.Tags) && tagsMatched(instance.Tags) && regexMatched(instance) {
+			kept = append(kept, instance)
+		}
+	}
+	instances = kept
+
+	results, groups, standalone := ec2InstancesCrossReferencedWithAsg(instances)
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Instances": instances}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("instances", results); err != nil {
+		return diag.Errorf("error setting instances: %s", err)
+	}
+
+	if err := d.Set("autoscaling_groups", groups); err != nil {
+		return diag.Errorf("error setting autoscaling_groups: %s", err)
+	}
+
+	if err := d.Set("standalone_instance_ids", standalone); err != nil {
+		return diag.Errorf("error setting standalone_instance_ids: %s", err)
+	}
+
+	return warnings
+}
+
+// ec2InstanceAutoScalingGroupName returns the name of the Auto Scaling Group of the given instance, from its
+// ec2AutoScalingGroupNameTagKey tag, or an empty string if it has none.
+func ec2InstanceAutoScalingGroupName(instance *ec2.Instance) string {
+	// The tag has the reserved "aws:" prefix, so it must be allowed explicitly.
+	tags := keyvaluetags.Ec2KeyValueTags(instance.Tags).IgnoreAwsExcept(ec2FilterableAwsTagKeys...)
+
+	return aws.StringValue(tags.KeyValue(ec2AutoScalingGroupNameTagKey))
+}
+
+// ec2InstancesCrossReferencedWithAsg returns the flattened "instances", ordered by ID, and "autoscaling_groups",
+// ordered by name, of the given instances, and the IDs of the standalone instances.
+func ec2InstancesCrossReferencedWithAsg(instances []*ec2.Instance) ([]map[string]interface{}, []map[string]interface{}, []string) {
+	sorted := append([]*ec2.Instance{}, instances...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return aws.StringValue(sorted[i].InstanceId) < aws.StringValue(sorted[j].InstanceId)
+	})
+
+	results := make([]map[string]interface{}, 0, len(sorted))
+	instanceIDs := make(map[string][]string)
+	standalone := make([]string, 0)
+
+	for _, instance := range sorted {
+		instanceID := aws.StringValue(instance.InstanceId)
+		groupName := ec2InstanceAutoScalingGroupName(instance)
+
+		var state string
+		if instance.State != nil {
+			state = aws.StringValue(instance.State.Name)
+		}
+
+		results = append(results, map[string]interface{}{
+			"instance_id":            instanceID,
+			"state":                  state,
+			"autoscaling_group_name": groupName,
+			"standalone":             groupName == "",
+		})
+
+		if groupName == "" {
+			standalone = append(standalone, instanceID)
+			continue
+		}
+		instanceIDs[groupName] = append(instanceIDs[groupName], instanceID)
+	}
+
+	groupNames := make([]string, 0, len(instanceIDs))
+	for groupName := range instanceIDs {
+		groupNames = append(groupNames, groupName)
+	}
+	sort.Strings(groupNames)
+
+	groups := make([]map[string]interface{}, 0, len(groupNames))
+	for _, groupName := range groupNames {
+		groups = append(groups, map[string]interface{}{
+			"autoscaling_group_name": groupName,
+			"instance_ids":           instanceIDs[groupName],
+		})
+	}
+
+	return results, groups, standalone
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEc2InstancesCrossReferencedWithAsg(t *testing.T) {
+	instance := func(id string, tags map[string]string) *ec2.Instance {
+		instance := &ec2.Instance{
+			InstanceId: aws.String(id),
+			State:      &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+		}
+		for k, v := range tags {
+			instance.Tags = append(instance.Tags, &ec2.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+		return instance
+	}
+
+	instances := []*ec2.Instance{
+		instance("i-00000004", map[string]string{"aws:autoscaling:groupName": "web"}),
+		instance("i-00000001", map[string]string{"aws:autoscaling:groupName": "web", "Name": "web"}),
+		instance("i-00000003", map[string]string{"Name": "bastion", "aws:cloudformation:stack-name": "bastion"}),
+		instance("i-00000002", map[string]string{"aws:autoscaling:groupName": "api"}),
+		instance("i-00000005", nil),
+	}
+
+	results, groups, standalone := ec2InstancesCrossReferencedWithAsg(instances)
+
+	expectedResults := []map[string]interface{}{
+		{"instance_id": "i-00000001", "state": "running", "autoscaling_group_name": "web", "standalone": false},
+		{"instance_id": "i-00000002", "state": "running", "autoscaling_group_name": "api", "standalone": false},
+		{"instance_id": "i-00000003", "state": "running", "autoscaling_group_name": "", "standalone": true},
+		{"instance_id": "i-00000004", "state": "running", "autoscaling_group_name": "web", "standalone": false},
+		{"instance_id": "i-00000005", "state": "running", "autoscaling_group_name": "", "standalone": true},
+	}
+	if !reflect.DeepEqual(results, expectedResults) {
+		t.Errorf("got instances %v, expected %v", results, expectedResults)
+	}
+
+	expectedGroups := []map[string]interface{}{
+		{"autoscaling_group_name": "api", "instance_ids": []string{"i-00000002"}},
+		{"autoscaling_group_name": "web", "instance_ids": []string{"i-00000001", "i-00000004"}},
+	}
+	if !reflect.DeepEqual(groups, expectedGroups) {
+		t.Errorf("got groups %v, expected %v", groups, expectedGroups)
+	}
+
+	if expected := []string{"i-00000003", "i-00000005"}; !reflect.DeepEqual(standalone, expected) {
+		t.Errorf("got standalone instances %v, expected %v", standalone, expected)
+	}
+}
+
+func TestEc2InstancesCrossReferencedWithAsgEmpty(t *testing.T) {
+	results, groups, standalone := ec2InstancesCrossReferencedWithAsg(nil)
+
+	if len(results) != 0 || len(groups) != 0 || standalone == nil || len(standalone) != 0 {
+		t.Errorf("got %v, %v, %v, expected empty results", results, groups, standalone)
+	}
+}
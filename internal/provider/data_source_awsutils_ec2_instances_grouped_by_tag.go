@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAwsUtilsEc2InstancesGroupedByTag() *schema.Resource {
+	return &schema.Resource{
+		Description: `Groups the EC2 Instances matching the given filters by the value of the tag with the given key.
+
+Instances without the tag are reported separately in ` + "`missing_tag_instance_ids`" + `, while instances with the tag set
+to an empty value form a group of their own. Instances in every state are included unless excluded with
+` + "`states`" + ` or an ` + "`instance-state-name`" + ` filter.`,
+		ReadContext:   dataSourceAwsUtilsEc2InstancesGroupedByTagRead,
+		SchemaVersion: 1,
+		Schema: mergeSchemas(map[string]*schema.Schema{
+			"ids":                       ec2IDsSchema(ec2.ResourceTypeInstance),
+			"ids_strict":                ec2IDsStrictSchema(),
+			"arns":                      ec2ARNsSchema(ec2.ResourceTypeInstance),
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"regex_filter":              ec2RegexFiltersSchema(),
+			"tags":                      tagsSchema(),
+			"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":              ec2ExcludeTagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"debug":                     debugSchema(),
+			"raw_response_json":         rawResponseJSONSchema(),
+			"applied_filters":           ec2AppliedFiltersSchema(),
+			"resolved_filters":          ec2ResolvedFiltersSchema(),
+			"filters_object":            ec2FiltersObjectSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"prometheus_metrics":        prometheusMetricsSchema(),
+			"group_by_tag_key": {
+				Description:  "The key of the tag whose value the instances are grouped by.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"groups": {
+				Description: "The instances grouped by tag value, ordered by tag value.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tag_value": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"instance_ids": {
+							Description: "The IDs of the instances, ordered by ID.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"missing_tag_instance_ids": {
+				Description: "The IDs of the instances without the tag, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		}, ec2SpotInstanceFilterSchemas(), ec2InstanceHibernationFilterSchemas(), ec2InstanceStateFilterSchemas(), resultCacheSchemas()),
+	}
+}
+
+func dataSourceAwsUtilsEc2InstancesGroupedByTagRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &ec2.DescribeInstancesInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeInstance)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.InstanceIds = ids
+	input.Filters = append(filters, buildEC2SpotInstanceAttributeFilterList(d)...)
+	input.Filters = append(input.Filters, buildEC2InstanceHibernationAttributeFilterList(d)...)
+
+	stateFilters, err := buildEC2InstanceStateAttributeFilterList(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.Filters = append(input.Filters, stateFilters...)
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var instances []*ec2.Instance
+	err = withResultCache(d, meta, "awsutils_ec2_instances_grouped_by_tag", input, &instances, func() error {
+		return describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+			instances, err = finder.Instances(conn, input, maxResultsCap(d, meta))
+			return err
+		})
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Instances: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Instance)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	kept := instances[:0]
+	for _, instance := range instances {
+		if !excluded(instance.Tags) && tagsMatched(instance.Tags) && regexMatched(instance) {
+			kept = append(kept, instance)
+		}
+	}
+	instances = kept
+
+	groups, missing := ec2InstancesGroupedByTag(instances, d.Get("group_by_tag_key").(string))
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Instances": instances}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("groups", groups); err != nil {
+		return diag.Errorf("error setting groups: %s", err)
+	}
+
+	if err := d.Set("missing_tag_instance_ids", missing); err != nil {
+		return diag.Errorf("error setting missing_tag_instance_ids: %s", err)
+	}
+
+	if err := setPrometheusMetrics(d, ec2InstancesGroupedByTagPrometheusMetrics(d.Get("group_by_tag_key").(string), groups, missing)...); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return warnings
+}
+
+// ec2InstancesGroupedByTagPrometheusMetrics returns the awsutils_ec2_instances_grouped_by_tag metric counting the
+// instances of each of the given groups by the tag with the given key, and the
+// awsutils_ec2_instances_grouped_by_tag_missing_tag metric counting the given instances without the tag.
+func ec2InstancesGroupedByTagPrometheusMetrics(key string, groups []map[string]interface{}, missing []string) []prometheusMetric {
+	samples := make([]prometheusSample, 0, len(groups))
+	for _, group := range groups {
+		samples = append(samples, prometheusSample{
+			Labels: map[string]string{"tag_key": key, "tag_value": group["tag_value"].(string)},
+			Value:  float64(len(group["instance_ids"].([]string))),
+		})
+	}
+
+	return []prometheusMetric{
+		{
+			Name:    "awsutils_ec2_instances_grouped_by_tag",
+			Help:    "The number of matching EC2 Instances by value of the tag.",
+			Samples: samples,
+		},
+		{
+			Name: "awsutils_ec2_instances_grouped_by_tag_missing_tag",
+			Help: "The number of matching EC2 Instances without the tag.",
+			Samples: []prometheusSample{
+				{
+					Labels: map[string]string{"tag_key": key},
+					Value:  float64(len(missing)),
+				},
+			},
+		},
+	}
+}
+
+// ec2InstancesGroupedByTag returns the flattened "groups" of the given instances by the value of the tag with the
+// given key, and the IDs of the instances without the tag.
+func ec2InstancesGroupedByTag(instances []*ec2.Instance, key string) ([]map[string]interface{}, []string) {
+	instanceIDs := make(map[string][]string)
+	missing := make([]string, 0)
+
+	for _, instance := range instances {
+		instanceID := aws.StringValue(instance.InstanceId)
+
+		tags := keyvaluetags.Ec2KeyValueTags(instance.Tags)
+		if !tags.KeyExists(key) {
+			missing = append(missing, instanceID)
+			continue
+		}
+
+		value := aws.StringValue(tags.KeyValue(key))
+		instanceIDs[value] = append(instanceIDs[value], instanceID)
+	}
+
+	values := make([]string, 0, len(instanceIDs))
+	for value := range instanceIDs {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	groups := make([]map[string]interface{}, 0, len(values))
+	for _, value := range values {
+		sort.Strings(instanceIDs[value])
+
+		groups = append(groups, map[string]interface{}{
+			"tag_value":    value,
+			"instance_ids": instanceIDs[value],
+		})
+	}
+	sort.Strings(missing)
+
+	return groups, missing
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEc2InstancesGroupedByTag(t *testing.T) {
+	instance := func(instanceID string, tags ...string) *ec2.Instance {
+		instance := &ec2.Instance{InstanceId: aws.String(instanceID)}
+		for i := 0; i < len(tags); i += 2 {
+			instance.Tags = append(instance.Tags, &ec2.Tag{Key: aws.String(tags[i]), Value: aws.String(tags[i+1])})
+		}
+		return instance
+	}
+
+	testCases := []struct {
+		Name            string
+		Instances       []*ec2.Instance
+		ExpectedGroups  []map[string]interface{}
+		ExpectedMissing []string
+	}{
+		{
+			Name:            "none",
+			Instances:       nil,
+			ExpectedGroups:  []map[string]interface{}{},
+			ExpectedMissing: []string{},
+		},
+		{
+			Name: "grouped and ordered",
+			Instances: []*ec2.Instance{
+				instance("i-00000004", "Team", "web"),
+				instance("i-00000003", "Name", "db", "Team", "data"),
+				instance("i-00000002", "Team", "web"),
+				instance("i-00000006", "Name", "untagged"),
+				instance("i-00000005", "Team", ""),
+				instance("i-00000001"),
+			},
+			ExpectedGroups: []map[string]interface{}{
+				{
+					"tag_value":    "",
+					"instance_ids": []string{"i-00000005"},
+				},
+				{
+					"tag_value":    "data",
+					"instance_ids": []string{"i-00000003"},
+				},
+				{
+					"tag_value":    "web",
+					"instance_ids": []string{"i-00000002", "i-00000004"},
+				},
+			},
+			ExpectedMissing: []string{"i-00000001", "i-00000006"},
+		},
+		{
+			Name: "key is case sensitive",
+			Instances: []*ec2.Instance{
+				instance("i-00000001", "team", "web"),
+			},
+			ExpectedGroups:  []map[string]interface{}{},
+			ExpectedMissing: []string{"i-00000001"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			groups, missing := ec2InstancesGroupedByTag(testCase.Instances, "Team")
+
+			if !reflect.DeepEqual(groups, testCase.ExpectedGroups) {
+				t.Errorf("got groups %v, expected %v", groups, testCase.ExpectedGroups)
+			}
+
+			if !reflect.DeepEqual(missing, testCase.ExpectedMissing) {
+				t.Errorf("got missing %v, expected %v", missing, testCase.ExpectedMissing)
+			}
+		})
+	}
+}
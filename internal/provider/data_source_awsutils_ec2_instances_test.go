@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testEc2InstancesConn returns an EC2 client answering each DescribeInstances request with the next of the given
+// pages, the last one without a NextToken, and recording the inputs of the requests.
+func testEc2InstancesConn(t *testing.T, pages [][]*ec2.Instance, inputs *[]*ec2.DescribeInstancesInput) *ec2.EC2 {
+	conn := testEc2Conn(t, func(r *request.Request) {
+		output, ok := r.Data.(*ec2.DescribeInstancesOutput)
+		if !ok {
+			return
+		}
+
+		*inputs = append(*inputs, r.Params.(*ec2.DescribeInstancesInput))
+		page := len(*inputs) - 1
+		if page >= len(pages) {
+			return
+		}
+
+		output.Reservations = []*ec2.Reservation{{Instances: pages[page]}}
+		if page < len(pages)-1 {
+			output.NextToken = aws.String("token")
+		}
+	})
+
+	return conn
+}
+
+func TestDataSourceAwsUtilsEc2InstancesRead(t *testing.T) {
+	pages := [][]*ec2.Instance{
+		{
+			{
+				InstanceId:       aws.String("i-00000003"),
+				PrivateIpAddress: aws.String("10.0.1.3"),
+				Placement:        &ec2.Placement{AvailabilityZone: aws.String("us-east-1b")},
+			},
+			{
+				InstanceId:       aws.String("i-00000001"),
+				PrivateIpAddress: aws.String("10.0.0.1"),
+				Placement:        &ec2.Placement{AvailabilityZone: aws.String("us-east-1a")},
+			},
+		},
+		{
+			{
+				InstanceId: aws.String("i-00000002"),
+				Placement:  &ec2.Placement{AvailabilityZone: aws.String("us-east-1a")},
+			},
+		},
+	}
+
+	var inputs []*ec2.DescribeInstancesInput
+	conn := testEc2InstancesConn(t, pages, &inputs)
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2Instances().Schema, map[string]interface{}{
+		"vpc_id": "vpc-01234567",
+		"tags":   map[string]interface{}{"Team": "platform"},
+		"filter": []interface{}{
+			map[string]interface{}{
+				"name":   "vpc-id",
+				"values": []interface{}{"vpc-89abcdef"},
+			},
+			map[string]interface{}{
+				"name":   "tag:Team",
+				"values": []interface{}{"security"},
+			},
+		},
+		"instance_state_names": []interface{}{"running"},
+	})
+
+	if diags := dataSourceAwsUtilsEc2InstancesRead(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-east-1", maxResultsCap: defaultMaxResultsCap}); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if len(inputs) != 2 {
+		t.Fatalf("got %d DescribeInstances requests, expected 2", len(inputs))
+	}
+
+	if got := aws.StringValue(inputs[1].NextToken); got != "token" {
+		t.Errorf("got NextToken %q on the second request, expected token", got)
+	}
+
+	expectedFilters := []*ec2.Filter{
+		{Name: aws.String("instance-state-name"), Values: aws.StringSlice([]string{"running"})},
+		{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{"platform", "security"})},
+		{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-01234567", "vpc-89abcdef"})},
+	}
+	if !reflect.DeepEqual(inputs[0].Filters, expectedFilters) {
+		t.Errorf("got filters %v, expected %v", inputs[0].Filters, expectedFilters)
+	}
+
+	if expected := []interface{}{"i-00000001", "i-00000002", "i-00000003"}; !reflect.DeepEqual(d.Get("ids"), expected) {
+		t.Errorf("got ids %v, expected %v", d.Get("ids"), expected)
+	}
+
+	if expected := []interface{}{"10.0.0.1", "", "10.0.1.3"}; !reflect.DeepEqual(d.Get("private_ips"), expected) {
+		t.Errorf("got private_ips %v, expected %v", d.Get("private_ips"), expected)
+	}
+
+	if expected := []interface{}{"us-east-1a", "us-east-1a", "us-east-1b"}; !reflect.DeepEqual(d.Get("availability_zones"), expected) {
+		t.Errorf("got availability_zones %v, expected %v", d.Get("availability_zones"), expected)
+	}
+}
+
+func TestDataSourceAwsUtilsEc2InstancesReadNoMatch(t *testing.T) {
+	var inputs []*ec2.DescribeInstancesInput
+	conn := testEc2InstancesConn(t, nil, &inputs)
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2Instances().Schema, map[string]interface{}{
+		"instance_type": "t3.micro",
+	})
+
+	if diags := dataSourceAwsUtilsEc2InstancesRead(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-east-1", maxResultsCap: defaultMaxResultsCap}); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	for _, attribute := range []string{"ids", "private_ips", "availability_zones"} {
+		if got := d.Get(attribute); !reflect.DeepEqual(got, []interface{}{}) {
+			t.Errorf("got %s %v, expected an empty list", attribute, got)
+		}
+	}
+}
+
+func TestDataSourceAwsUtilsEc2InstancesReadMatchAny(t *testing.T) {
+	// Each request is answered with the instances of the filter of its block.
+	instancesByFilter := map[string][]*ec2.Instance{
+		"availability-zone": {
+			{InstanceId: aws.String("i-00000002"), Placement: &ec2.Placement{AvailabilityZone: aws.String("us-west-2a")}},
+			{InstanceId: aws.String("i-00000001"), Placement: &ec2.Placement{AvailabilityZone: aws.String("us-west-2a")}},
+		},
+		"instance-type": {
+			{InstanceId: aws.String("i-00000001"), Placement: &ec2.Placement{AvailabilityZone: aws.String("us-west-2a")}},
+			{InstanceId: aws.String("i-00000003"), Placement: &ec2.Placement{AvailabilityZone: aws.String("us-west-2b")}},
+		},
+	}
+
+	var inputs []*ec2.DescribeInstancesInput
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		output, ok := r.Data.(*ec2.DescribeInstancesOutput)
+		if !ok {
+			return
+		}
+
+		input := r.Params.(*ec2.DescribeInstancesInput)
+		inputs = append(inputs, input)
+
+		for _, filter := range input.Filters {
+			if instances, ok := instancesByFilter[aws.StringValue(filter.Name)]; ok {
+				output.Reservations = append(output.Reservations, &ec2.Reservation{Instances: instances})
+			}
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2Instances().Schema, map[string]interface{}{
+		"match":  ec2InstancesMatchAny,
+		"vpc_id": "vpc-01234567",
+		"filter": []interface{}{
+			map[string]interface{}{
+				"name":   "availability-zone",
+				"values": []interface{}{"us-west-2a"},
+			},
+			map[string]interface{}{
+				"name":   "instance-type",
+				"values": []interface{}{"t3.large"},
+			},
+		},
+	})
+
+	if diags := dataSourceAwsUtilsEc2InstancesRead(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-west-2", maxResultsCap: defaultMaxResultsCap}); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if len(inputs) != 2 {
+		t.Fatalf("got %d DescribeInstances requests, expected one per filter block", len(inputs))
+	}
+
+	// The order of the requests follows that of the set elements, which depends on their hash.
+	sort.Slice(inputs, func(i, j int) bool {
+		return aws.StringValue(inputs[i].Filters[0].Name) < aws.StringValue(inputs[j].Filters[0].Name)
+	})
+
+	expectedFilters := [][]*ec2.Filter{
+		{
+			{Name: aws.String("availability-zone"), Values: aws.StringSlice([]string{"us-west-2a"})},
+			{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-01234567"})},
+		},
+		{
+			{Name: aws.String("instance-type"), Values: aws.StringSlice([]string{"t3.large"})},
+			{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-01234567"})},
+		},
+	}
+	for i, input := range inputs {
+		if !reflect.DeepEqual(input.Filters, expectedFilters[i]) {
+			t.Errorf("got filters %v, expected %v", input.Filters, expectedFilters[i])
+		}
+	}
+
+	if expected := []interface{}{"i-00000001", "i-00000002", "i-00000003"}; !reflect.DeepEqual(d.Get("ids"), expected) {
+		t.Errorf("got ids %v, expected %v", d.Get("ids"), expected)
+	}
+
+	if expected := []interface{}{"us-west-2a", "us-west-2a", "us-west-2b"}; !reflect.DeepEqual(d.Get("availability_zones"), expected) {
+		t.Errorf("got availability_zones %v, expected %v", d.Get("availability_zones"), expected)
+	}
+
+	// Only the filters sent with every request are reported.
+	if got := d.Get("resolved_filters").([]interface{}); len(got) != 1 || got[0].(map[string]interface{})["name"] != "vpc-id" {
+		t.Errorf("got resolved_filters %v, expected only vpc-id", got)
+	}
+}
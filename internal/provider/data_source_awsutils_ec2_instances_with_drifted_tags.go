@@ -0,0 +1,308 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAwsUtilsEc2InstancesWithDriftedTags() *schema.Resource {
+	return &schema.Resource{
+		Description: `Lists the EC2 Instances matching the given filters whose tags do not match the given desired tags.
+
+An instance has drifted when it is missing a desired tag, has a desired tag with another value, or, unless
+` + "`ignore_extra_tags`" + ` is set, has a tag which is not desired. Tags with the reserved ` + "`aws:`" + ` prefix are
+ignored on both sides of the comparison. The differences are reported per instance, both as ` + "`instances`" + ` and
+as the ` + "`drift_json`" + ` document, for remediation tooling. Instances in every state are included unless excluded
+with ` + "`states`" + ` or an ` + "`instance-state-name`" + ` filter.`,
+		ReadContext:   dataSourceAwsUtilsEc2InstancesWithDriftedTagsRead,
+		SchemaVersion: 1,
+		Schema: mergeSchemas(map[string]*schema.Schema{
+			"ids":                       ec2IDsSchema(ec2.ResourceTypeInstance),
+			"ids_strict":                ec2IDsStrictSchema(),
+			"arns":                      ec2ARNsSchema(ec2.ResourceTypeInstance),
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"regex_filter":              ec2RegexFiltersSchema(),
+			"tags":                      tagsSchema(),
+			"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":              ec2ExcludeTagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"debug":                     debugSchema(),
+			"raw_response_json":         rawResponseJSONSchema(),
+			"applied_filters":           ec2AppliedFiltersSchema(),
+			"resolved_filters":          ec2ResolvedFiltersSchema(),
+			"filters_object":            ec2FiltersObjectSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"desired_tags": {
+				Description: "The tags every instance must have, with their values. Keys with the reserved `aws:` prefix are ignored.",
+				Type:        schema.TypeMap,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"ignore_extra_tags": {
+				Description: "Whether the tags of the instances which are not desired are ignored rather than reported as drift.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"instances": {
+				Description: "The instances whose tags drifted, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"instance_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"missing_tags": {
+							Description: "The desired tags the instance does not have, with their desired values.",
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"extra_tags": {
+							Description: "The tags of the instance which are not desired, with their values. Empty if `ignore_extra_tags` is set.",
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"mismatched_tags": {
+							Description: "The desired tags the instance has with another value, ordered by key.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"key": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"desired_value": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"actual_value": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"instance_ids": {
+				Description: "The IDs of the instances whose tags drifted, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"drift_json": {
+				Description: "The differences of `instances` as a JSON object keyed by instance ID, each with the `missing`, `extra` and `mismatched` tags. The `mismatched` tags map each key to its `desired` and `actual` values.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		}, ec2SpotInstanceFilterSchemas(), ec2InstanceHibernationFilterSchemas(), ec2InstanceStateFilterSchemas(), resultCacheSchemas()),
+	}
+}
+
+func dataSourceAwsUtilsEc2InstancesWithDriftedTagsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	desired := keyvaluetags.New(d.Get("desired_tags").(map[string]interface{}))
+	ignoreExtraTags := d.Get("ignore_extra_tags").(bool)
+
+	input := &ec2.DescribeInstancesInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeInstance)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.InstanceIds = ids
+	input.Filters = append(filters, buildEC2SpotInstanceAttributeFilterList(d)...)
+	input.Filters = append(input.Filters, buildEC2InstanceHibernationAttributeFilterList(d)...)
+
+	stateFilters, err := buildEC2InstanceStateAttributeFilterList(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.Filters = append(input.Filters, stateFilters...)
+
+	if len(input.Filters) == 0 {
+		input.Filters = nil
+	}
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var instances []*ec2.Instance
+	err = withResultCache(d, meta, "awsutils_ec2_instances_with_drifted_tags", input, &instances, func() error {
+		return describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+			instances, err = finder.Instances(conn, input, maxResultsCap(d, meta))
+			return err
+		})
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Instances: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Instance)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	kept := instances[:0]
+	for _, instance := range instances {
+		if !excluded(instance.Tags) && tagsMatched(instance.Tags) && regexMatched(instance) {
+			kept = append(kept, instance)
+		}
+	}
+	instances = kept
+
+	sort.Slice(instances, func(i, j int) bool {
+		return aws.StringValue(instances[i].InstanceId) < aws.StringValue(instances[j].InstanceId)
+	})
+
+	results := make([]map[string]interface{}, 0)
+	instanceIDs := make([]string, 0)
+	drifts := make(map[string]ec2TagDrift)
+
+	for _, instance := range instances {
+		instanceID := aws.StringValue(instance.InstanceId)
+
+		drift := ec2TagDriftFor(keyvaluetags.Ec2KeyValueTags(instance.Tags), desired, ignoreExtraTags)
+		if drift.empty() {
+			continue
+		}
+
+		results = append(results, drift.flatten(instanceID))
+		instanceIDs = append(instanceIDs, instanceID)
+		drifts[instanceID] = drift
+	}
+
+	driftJSON, err := json.Marshal(drifts)
+	if err != nil {
+		return diag.Errorf("error encoding drift_json: %s", err)
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Instances": instances}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("instances", results); err != nil {
+		return diag.Errorf("error setting instances: %s", err)
+	}
+
+	if err := d.Set("instance_ids", instanceIDs); err != nil {
+		return diag.Errorf("error setting instance_ids: %s", err)
+	}
+
+	if err := d.Set("drift_json", string(driftJSON)); err != nil {
+		return diag.Errorf("error setting drift_json: %s", err)
+	}
+
+	return warnings
+}
+
+// ec2TagDrift is the difference between the tags of an object and the desired tags, as encoded in drift_json.
+type ec2TagDrift struct {
+	Missing    map[string]string                `json:"missing"`
+	Extra      map[string]string                `json:"extra"`
+	Mismatched map[string]ec2TagDriftMismatched `json:"mismatched"`
+}
+
+// ec2TagDriftMismatched is a desired tag an object has with another value.
+type ec2TagDriftMismatched struct {
+	Desired string `json:"desired"`
+	Actual  string `json:"actual"`
+}
+
+// ec2TagDriftFor returns the difference between the given tags and the desired tags, ignoring the tags with the
+// reserved "aws:" prefix on both sides, and the tags which are not desired if ignoreExtraTags is set.
+func ec2TagDriftFor(tags, desired keyvaluetags.KeyValueTags, ignoreExtraTags bool) ec2TagDrift {
+	tags = tags.IgnoreAws()
+	desired = desired.IgnoreAws()
+
+	drift := ec2TagDrift{
+		Missing:    desired.Removed(tags).Map(),
+		Extra:      map[string]string{},
+		Mismatched: map[string]ec2TagDriftMismatched{},
+	}
+
+	if !ignoreExtraTags {
+		drift.Extra = tags.Removed(desired).Map()
+	}
+
+	actual := tags.Map()
+	for key, value := range desired.Map() {
+		if actualValue, ok := actual[key]; ok && actualValue != value {
+			drift.Mismatched[key] = ec2TagDriftMismatched{Desired: value, Actual: actualValue}
+		}
+	}
+
+	return drift
+}
+
+// empty returns whether the tags did not drift.
+func (drift ec2TagDrift) empty() bool {
+	return len(drift.Missing) == 0 && len(drift.Extra) == 0 && len(drift.Mismatched) == 0
+}
+
+// flatten returns the "instances" element of the object with the given ID.
+func (drift ec2TagDrift) flatten(instanceID string) map[string]interface{} {
+	keys := make([]string, 0, len(drift.Mismatched))
+	for key := range drift.Mismatched {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mismatched := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		mismatched = append(mismatched, map[string]interface{}{
+			"key":           key,
+			"desired_value": drift.Mismatched[key].Desired,
+			"actual_value":  drift.Mismatched[key].Actual,
+		})
+	}
+
+	return map[string]interface{}{
+		"instance_id":     instanceID,
+		"missing_tags":    drift.Missing,
+		"extra_tags":      drift.Extra,
+		"mismatched_tags": mismatched,
+	}
+}
@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+)
+
+func TestEc2TagDriftFor(t *testing.T) {
+	desired := keyvaluetags.New(map[string]string{
+		"Environment":                   "prod",
+		"Team":                          "platform",
+		"aws:cloudformation:stack-name": "ignored",
+	})
+
+	testCases := []struct {
+		Name            string
+		Tags            map[string]string
+		IgnoreExtraTags bool
+		Expected        ec2TagDrift
+		ExpectedDrifted bool
+	}{
+		{
+			Name: "matching",
+			Tags: map[string]string{"Environment": "prod", "Team": "platform", "aws:autoscaling:groupName": "web"},
+			Expected: ec2TagDrift{
+				Missing:    map[string]string{},
+				Extra:      map[string]string{},
+				Mismatched: map[string]ec2TagDriftMismatched{},
+			},
+		},
+		{
+			Name: "missing, extra and mismatched",
+			Tags: map[string]string{"Environment": "dev", "Name": "web", "aws:autoscaling:groupName": "web"},
+			Expected: ec2TagDrift{
+				Missing:    map[string]string{"Team": "platform"},
+				Extra:      map[string]string{"Name": "web"},
+				Mismatched: map[string]ec2TagDriftMismatched{"Environment": {Desired: "prod", Actual: "dev"}},
+			},
+			ExpectedDrifted: true,
+		},
+		{
+			Name:            "extra tags ignored",
+			Tags:            map[string]string{"Environment": "prod", "Team": "platform", "Name": "web"},
+			IgnoreExtraTags: true,
+			Expected: ec2TagDrift{
+				Missing:    map[string]string{},
+				Extra:      map[string]string{},
+				Mismatched: map[string]ec2TagDriftMismatched{},
+			},
+		},
+		{
+			Name: "untagged",
+			Expected: ec2TagDrift{
+				Missing:    map[string]string{"Environment": "prod", "Team": "platform"},
+				Extra:      map[string]string{},
+				Mismatched: map[string]ec2TagDriftMismatched{},
+			},
+			ExpectedDrifted: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := ec2TagDriftFor(keyvaluetags.New(testCase.Tags), desired, testCase.IgnoreExtraTags)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+
+			if drifted := !got.empty(); drifted != testCase.ExpectedDrifted {
+				t.Errorf("got drifted %t, expected %t", drifted, testCase.ExpectedDrifted)
+			}
+		})
+	}
+}
+
+func TestEc2TagDriftFlatten(t *testing.T) {
+	drift := ec2TagDrift{
+		Missing: map[string]string{"Team": "platform"},
+		Extra:   map[string]string{},
+		Mismatched: map[string]ec2TagDriftMismatched{
+			"Environment": {Desired: "prod", Actual: "dev"},
+			"CostCenter":  {Desired: "1234", Actual: "4321"},
+		},
+	}
+
+	expected := map[string]interface{}{
+		"instance_id":  "i-00000001",
+		"missing_tags": map[string]string{"Team": "platform"},
+		"extra_tags":   map[string]string{},
+		"mismatched_tags": []map[string]interface{}{
+			{"key": "CostCenter", "desired_value": "1234", "actual_value": "4321"},
+			{"key": "Environment", "desired_value": "prod", "actual_value": "dev"},
+		},
+	}
+
+	if got := drift.flatten("i-00000001"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	// ec2PublicIPOwnerAmazon is the owner of the public IPv4 addresses automatically assigned by Amazon.
+	ec2PublicIPOwnerAmazon = "amazon"
+
+	ec2PublicIPTypeAutoAssigned = "auto-assigned"
+	ec2PublicIPTypeElastic      = "elastic"
+)
+
+// ec2InstancesWithPublicIpFingerprintAttributes are the attributes of the "instances" hashed into their fingerprint.
+var ec2InstancesWithPublicIpFingerprintAttributes = []string{"instance_id", "public_ip", "public_dns_name", "public_ip_type", "subnet_id", "vpc_id"}
+
+func dataSourceAwsUtilsEc2InstancesWithPublicIp() *schema.Resource {
+	return &schema.Resource{
+		Description: `Lists the EC2 Instances matching the given filters which are reachable through a public IPv4 address or
+public DNS name.
+
+EC2 has no filter matching instances with a public address, so the instances are filtered after being described. The
+public IP of each instance is classified as ` + "`elastic`" + ` when it is an Elastic IP (or a BYOIP address) associated with
+one of the network interfaces of the instance, and as ` + "`auto-assigned`" + ` when it was assigned by Amazon at launch.`,
+		ReadContext:   dataSourceAwsUtilsEc2InstancesWithPublicIpRead,
+		SchemaVersion: 1,
+		Schema: mergeSchemas(map[string]*schema.Schema{
+			"ids":                       ec2IDsSchema(ec2.ResourceTypeInstance),
+			"ids_strict":                ec2IDsStrictSchema(),
+			"arns":                      ec2ARNsSchema(ec2.ResourceTypeInstance),
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"regex_filter":              ec2RegexFiltersSchema(),
+			"tags":                      tagsSchema(),
+			"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":              ec2ExcludeTagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"debug":                     debugSchema(),
+			"raw_response_json":         rawResponseJSONSchema(),
+			"applied_filters":           ec2AppliedFiltersSchema(),
+			"resolved_filters":          ec2ResolvedFiltersSchema(),
+			"filters_object":            ec2FiltersObjectSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"vpc_id": {
+				Description: "Only match instances in the given VPC.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"instances": {
+				Description: "The instances with a public IP or DNS name, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"instance_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"public_ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"public_dns_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"public_ip_type": {
+							Description: "Either `elastic` or `auto-assigned`, or empty if the instance has no public IP.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"subnet_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vpc_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"fingerprint": fingerprintSchema(ec2InstancesWithPublicIpFingerprintAttributes),
+					},
+				},
+			},
+			"instance_ids": {
+				Description: "The IDs of the instances with a public IP or DNS name.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		}, ec2SpotInstanceFilterSchemas(), ec2InstanceHibernationFilterSchemas(), ec2InstanceStateFilterSchemas()),
+	}
+}
+
+func dataSourceAwsUtilsEc2InstancesWithPublicIpRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &ec2.DescribeInstancesInput{}
+	if v, ok := d.GetOk("vpc_id"); ok {
+		input.Filters = buildEC2AttributeFilterList(map[string]string{
+			"vpc-id": v.(string),
+		})
+	}
+
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeInstance)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.InstanceIds = ids
+	input.Filters = append(input.Filters, filters...)
+	input.Filters = append(input.Filters, buildEC2SpotInstanceAttributeFilterList(d)...)
+	input.Filters = append(input.Filters, buildEC2InstanceHibernationAttributeFilterList(d)...)
+
+	stateFilters, err := buildEC2InstanceStateAttributeFilterList(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.Filters = append(input.Filters, stateFilters...)
+
+	if len(input.Filters) == 0 {
+		input.Filters = nil
+	}
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	// The instances are streamed so that only those with a public IP are held in memory, unless all of them are
+	// needed for raw_response_json. Unlike the Describe calls of the other data sources, the stream is not retried
+	// by describeWithRetry: the instances of the pages read before a failure have already been consumed, so it
+	// cannot be restarted from its first page without reporting them twice.
+	stream := finder.StreamInstances(ctx, conn, input, maxResultsCap(d, meta))
+	defer stream.Close()
+
+	debug := d.Get("debug").(bool)
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Instance)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var instances []*ec2.Instance
+	results := make([]map[string]interface{}, 0)
+
+	for instance := stream.Next(); instance != nil; instance = stream.Next() {
+		if excluded(instance.Tags) || !tagsMatched(instance.Tags) || !regexMatched(instance) {
+			continue
+		}
+
+		if debug {
+			instances = append(instances, instance)
+		}
+
+		publicIP := aws.StringValue(instance.PublicIpAddress)
+		publicDNSName := aws.StringValue(instance.PublicDnsName)
+
+		if publicIP == "" && publicDNSName == "" {
+			continue
+		}
+
+		results = append(results, map[string]interface{}{
+			"instance_id":     aws.StringValue(instance.InstanceId),
+			"public_ip":       publicIP,
+			"public_dns_name": publicDNSName,
+			"public_ip_type":  ec2InstancePublicIPType(instance),
+			"subnet_id":       aws.StringValue(instance.SubnetId),
+			"vpc_id":          aws.StringValue(instance.VpcId),
+		})
+	}
+
+	if err := stream.Err(); err != nil {
+		return diag.Errorf("error reading EC2 Instances: %s", maxResultsCapError(err))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i]["instance_id"].(string) < results[j]["instance_id"].(string)
+	})
+
+	instanceIDs := make([]string, 0, len(results))
+	for _, result := range results {
+		instanceIDs = append(instanceIDs, result["instance_id"].(string))
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Instances": instances}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setFingerprints(results, ec2InstancesWithPublicIpFingerprintAttributes); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("instances", results); err != nil {
+		return diag.Errorf("error setting instances: %s", err)
+	}
+
+	if err := d.Set("instance_ids", instanceIDs); err != nil {
+		return diag.Errorf("error setting instance_ids: %s", err)
+	}
+
+	return warnings
+}
+
+// ec2InstancePublicIPType returns whether the public IP of the given instance is an Elastic IP or was auto-assigned,
+// from the owner of the network interface association holding it, or an empty string if it has no public IP.
+func ec2InstancePublicIPType(instance *ec2.Instance) string {
+	publicIP := aws.StringValue(instance.PublicIpAddress)
+	if publicIP == "" {
+		return ""
+	}
+
+	for _, networkInterface := range instance.NetworkInterfaces {
+		if networkInterface == nil || networkInterface.Association == nil {
+			continue
+		}
+
+		association := networkInterface.Association
+		if aws.StringValue(association.PublicIp) != publicIP {
+			continue
+		}
+
+		// Elastic IPs, including BYOIP addresses, are owned by the account rather than by Amazon.
+		if owner := aws.StringValue(association.IpOwnerId); owner != "" && owner != ec2PublicIPOwnerAmazon {
+			return ec2PublicIPTypeElastic
+		}
+	}
+
+	return ec2PublicIPTypeAutoAssigned
+}
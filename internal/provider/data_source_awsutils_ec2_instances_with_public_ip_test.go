@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEc2InstancePublicIPType(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Instance *ec2.Instance
+		Expected string
+	}{
+		{
+			Name:     "no public IP",
+			Instance: &ec2.Instance{},
+			Expected: "",
+		},
+		{
+			Name: "auto-assigned",
+			Instance: &ec2.Instance{
+				PublicIpAddress: aws.String("203.0.113.10"),
+				NetworkInterfaces: []*ec2.InstanceNetworkInterface{
+					{
+						Association: &ec2.InstanceNetworkInterfaceAssociation{
+							IpOwnerId: aws.String("amazon"),
+							PublicIp:  aws.String("203.0.113.10"),
+						},
+					},
+				},
+			},
+			Expected: ec2PublicIPTypeAutoAssigned,
+		},
+		{
+			Name: "elastic IP",
+			Instance: &ec2.Instance{
+				PublicIpAddress: aws.String("203.0.113.20"),
+				NetworkInterfaces: []*ec2.InstanceNetworkInterface{
+					{},
+					{
+						Association: &ec2.InstanceNetworkInterfaceAssociation{
+							IpOwnerId: aws.String("123456789012"),
+							PublicIp:  aws.String("203.0.113.20"),
+						},
+					},
+				},
+			},
+			Expected: ec2PublicIPTypeElastic,
+		},
+		{
+			Name: "elastic IP on another interface",
+			Instance: &ec2.Instance{
+				PublicIpAddress: aws.String("203.0.113.10"),
+				NetworkInterfaces: []*ec2.InstanceNetworkInterface{
+					{
+						Association: &ec2.InstanceNetworkInterfaceAssociation{
+							IpOwnerId: aws.String("amazon"),
+							PublicIp:  aws.String("203.0.113.10"),
+						},
+					},
+					{
+						Association: &ec2.InstanceNetworkInterfaceAssociation{
+							IpOwnerId: aws.String("123456789012"),
+							PublicIp:  aws.String("203.0.113.20"),
+						},
+					},
+				},
+			},
+			Expected: ec2PublicIPTypeAutoAssigned,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := ec2InstancePublicIPType(testCase.Instance); got != testCase.Expected {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
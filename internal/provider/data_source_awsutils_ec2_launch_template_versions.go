@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2LaunchTemplateVersionRegexp matches the launch template versions accepted by DescribeLaunchTemplateVersions:
+// a version number or one of the $Latest and $Default aliases.
+var ec2LaunchTemplateVersionRegexp = regexp.MustCompile(`^([1-9][0-9]*|\$Latest|\$Default)$`)
+
+// ec2LaunchTemplateVersionsFingerprintAttributes are the attributes of the "launch_template_versions" hashed into their fingerprint.
+var ec2LaunchTemplateVersionsFingerprintAttributes = []string{"version_number", "version_description", "default_version"}
+
+func dataSourceAwsUtilsEc2LaunchTemplateVersions() *schema.Resource {
+	return &schema.Resource{
+		Description: `Lists the versions of an EC2 Launch Template, such as to plan a rollback to a previous version.
+
+All the versions are listed unless restricted with ` + "`versions`" + `, which may include the ` + "`$Latest`" + ` and
+` + "`$Default`" + ` aliases alongside version numbers. A version given several times, e.g. both by number and as
+` + "`$Latest`" + `, is only listed once.`,
+		Read:          dataSourceAwsUtilsEc2LaunchTemplateVersionsRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"launch_template_id": {
+				Description:  "The ID of the Launch Template.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"launch_template_id", "launch_template_name"},
+				ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+					if err := tfec2.ValidateResourceID(ec2.ResourceTypeLaunchTemplate, v.(string)); err != nil {
+						errors = append(errors, fmt.Errorf("%s: %w", k, err))
+					}
+					return
+				},
+			},
+			"launch_template_name": {
+				Description:  "The name of the Launch Template.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"launch_template_id", "launch_template_name"},
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"versions": {
+				Description: "Only list the given versions, either version numbers or the `$Latest` and `$Default` aliases.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(ec2LaunchTemplateVersionRegexp, "must be a version number, $Latest or $Default"),
+				},
+			},
+			"debug":             debugSchema(),
+			"raw_response_json": rawResponseJSONSchema(),
+			"launch_template_versions": {
+				Description: "The versions of the Launch Template, ordered by version number.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"version_number": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"version_description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"default_version": {
+							Description: "Whether the version is the default version of the Launch Template.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						"create_time": {
+							Description: "The time the version was created, in RFC 3339 format.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"created_by": {
+							Description: "The ARN of the principal which created the version.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"fingerprint": fingerprintSchema(ec2LaunchTemplateVersionsFingerprintAttributes),
+					},
+				},
+			},
+			"version_numbers": {
+				Description: "The version numbers of the listed versions, in ascending order.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+			"default_version_number": {
+				Description: "The version number of the default version, if listed, or 0.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2LaunchTemplateVersionsRead(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*AWSClient).ec2conn
+
+	input := &ec2.DescribeLaunchTemplateVersionsInput{}
+	if v, ok := d.GetOk("launch_template_id"); ok {
+		input.LaunchTemplateId = aws.String(v.(string))
+	}
+	if v, ok := d.GetOk("launch_template_name"); ok {
+		input.LaunchTemplateName = aws.String(v.(string))
+	}
+	if v, ok := d.GetOk("versions"); ok {
+		input.Versions = ExpandStringSet(v.(*schema.Set))
+	}
+
+	versions, err := finder.LaunchTemplateVersions(conn, input)
+	if err != nil {
+		return fmt.Errorf("error reading EC2 Launch Template Versions: %w", err)
+	}
+
+	results := flattenEc2LaunchTemplateVersions(versions)
+
+	versionNumbers := make([]int, 0, len(results))
+	var defaultVersionNumber int
+	for _, result := range results {
+		versionNumbers = append(versionNumbers, result["version_number"].(int))
+		if result["default_version"].(bool) {
+			defaultVersionNumber = result["version_number"].(int)
+		}
+	}
+
+	d.SetId(meta.(*AWSClient).region)
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"LaunchTemplateVersions": versions}); err != nil {
+		return err
+	}
+
+	if err := setFingerprints(results, ec2LaunchTemplateVersionsFingerprintAttributes); err != nil {
+		return err
+	}
+
+	if err := d.Set("launch_template_versions", results); err != nil {
+		return fmt.Errorf("error setting launch_template_versions: %w", err)
+	}
+
+	if err := d.Set("version_numbers", versionNumbers); err != nil {
+		return fmt.Errorf("error setting version_numbers: %w", err)
+	}
+
+	if err := d.Set("default_version_number", defaultVersionNumber); err != nil {
+		return fmt.Errorf("error setting default_version_number: %w", err)
+	}
+
+	return nil
+}
+
+// flattenEc2LaunchTemplateVersions flattens the given launch template versions into the
+// "launch_template_versions" attribute, ordered by version number. Versions returned several times, when the
+// requested versions include aliases of the same version, are only listed once.
+func flattenEc2LaunchTemplateVersions(versions []*ec2.LaunchTemplateVersion) []map[string]interface{} {
+	byNumber := make(map[int64]*ec2.LaunchTemplateVersion, len(versions))
+	for _, version := range versions {
+		byNumber[aws.Int64Value(version.VersionNumber)] = version
+	}
+
+	numbers := make([]int64, 0, len(byNumber))
+	for number := range byNumber {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool {
+		return numbers[i] < numbers[j]
+	})
+
+	results := make([]map[string]interface{}, 0, len(numbers))
+	for _, number := range numbers {
+		version := byNumber[number]
+
+		var createTime string
+		if version.CreateTime != nil {
+			createTime = aws.TimeValue(version.CreateTime).UTC().Format(time.RFC3339)
+		}
+
+		results = append(results, map[string]interface{}{
+			"version_number":      int(number),
+			"version_description": aws.StringValue(version.VersionDescription),
+			"default_version":     aws.BoolValue(version.DefaultVersion),
+			"create_time":         createTime,
+			"created_by":          aws.StringValue(version.CreatedBy),
+		})
+	}
+
+	return results
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestFlattenEc2LaunchTemplateVersions(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	versions := []*ec2.LaunchTemplateVersion{
+		{
+			VersionNumber:      aws.Int64(10),
+			VersionDescription: aws.String("latest"),
+			DefaultVersion:     aws.Bool(false),
+			CreateTime:         aws.Time(created),
+		},
+		{
+			VersionNumber:  aws.Int64(2),
+			DefaultVersion: aws.Bool(true),
+			CreatedBy:      aws.String("arn:aws:iam::123456789012:root"),
+		},
+		// The same version returned again, e.g. for both 10 and $Latest.
+		{
+			VersionNumber:      aws.Int64(10),
+			VersionDescription: aws.String("latest"),
+			DefaultVersion:     aws.Bool(false),
+			CreateTime:         aws.Time(created),
+		},
+	}
+
+	expected := []map[string]interface{}{
+		{
+			"version_number":      2,
+			"version_description": "",
+			"default_version":     true,
+			"create_time":         "",
+			"created_by":          "arn:aws:iam::123456789012:root",
+		},
+		{
+			"version_number":      10,
+			"version_description": "latest",
+			"default_version":     false,
+			"create_time":         "2024-03-01T11:00:00Z",
+			"created_by":          "",
+		},
+	}
+
+	if got := flattenEc2LaunchTemplateVersions(versions); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestEc2LaunchTemplateVersionRegexp(t *testing.T) {
+	for _, testCase := range []struct {
+		Version string
+		Valid   bool
+	}{
+		{"1", true},
+		{"42", true},
+		{"$Latest", true},
+		{"$Default", true},
+		{"0", false},
+		{"01", false},
+		{"latest", false},
+		{"$latest", false},
+		{"", false},
+	} {
+		if got := ec2LaunchTemplateVersionRegexp.MatchString(testCase.Version); got != testCase.Valid {
+			t.Errorf("got valid %t for %q, expected %t", got, testCase.Version, testCase.Valid)
+		}
+	}
+}
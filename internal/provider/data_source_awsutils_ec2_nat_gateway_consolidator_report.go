@@ -0,0 +1,470 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/aws-sdk-go-base/tfawserr"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAwsUtilsEc2NatGatewayConsolidatorReport() *schema.Resource {
+	return &schema.Resource{
+		Description: `Reports how the NAT Gateways of a VPC are distributed across its Availability Zones and used by its Subnets,
+to find the NAT Gateways which can be consolidated, such as in development accounts where one NAT Gateway per
+Availability Zone is not worth its cost.
+
+The VPC is selected by ` + "`vpc_id`" + ` or by the given filters, which must match exactly one VPC. Only the available NAT
+Gateways are considered. Each Subnet is mapped to the NAT Gateways its effective Route Table routes to: the Route Table
+explicitly associated with the Subnet or, if there is none, the main Route Table of the VPC. All the active routes to a
+NAT Gateway are considered, not only the default routes. A Subnet routing to a NAT Gateway of another Availability Zone
+is reported in ` + "`cross_az_routes`" + `, as its traffic incurs cross-AZ data transfer charges, and an Availability Zone
+with several NAT Gateways is flagged as redundant.`,
+		ReadContext:   dataSourceAwsUtilsEc2NatGatewayConsolidatorReportRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"vpc_id": {
+				Description: "The ID of the VPC to report on. Either this or the filters must be given, unless `allow_unfiltered` is set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"tags":                      tagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"max_concurrency": {
+				Description:  "The maximum number of describe requests in flight at any time.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntBetween(1, 3),
+			},
+			"availability_zones": {
+				Description: "The Availability Zones of the VPC with a NAT Gateway or a Subnet routing to one, ordered by name.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"availability_zone": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"nat_gateway_ids": {
+							Description: "The IDs of the NAT Gateways in the Availability Zone, ordered by ID.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"subnet_ids": {
+							Description: "The IDs of the Subnets of the Availability Zone routing to a NAT Gateway, in any Availability Zone, ordered by ID.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"redundant": {
+							Description: "Whether the Availability Zone has more than one NAT Gateway.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"nat_gateways": {
+				Description: "The NAT Gateways of the VPC, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"nat_gateway_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subnet_id": {
+							Description: "The ID of the Subnet the NAT Gateway is in.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"availability_zone": {
+							Description: "The Availability Zone of the NAT Gateway, or an empty string if its Subnet is not found.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"connectivity_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"routed_subnet_ids": {
+							Description: "The IDs of the Subnets routing to the NAT Gateway, ordered by ID.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"cross_az_routes": {
+				Description: "The routes of Subnets to NAT Gateways of another Availability Zone, ordered by Subnet ID and destination.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"subnet_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subnet_availability_zone": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"route_table_id": {
+							Description: "The ID of the effective Route Table of the Subnet.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"destination": {
+							Description: "The destination CIDR block or prefix list ID of the route.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"nat_gateway_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"nat_gateway_availability_zone": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"redundant_availability_zones": {
+				Description: "The names of the Availability Zones with more than one NAT Gateway.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"cross_az_subnet_ids": {
+				Description: "The IDs of the Subnets with a route to a NAT Gateway of another Availability Zone.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"unused_nat_gateway_ids": {
+				Description: "The IDs of the NAT Gateways no Subnet routes to.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2NatGatewayConsolidatorReportRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &ec2.DescribeVpcsInput{}
+	if v, ok := d.GetOk("vpc_id"); ok {
+		input.VpcIds = aws.StringSlice([]string{v.(string)})
+	}
+
+	_, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeVpc)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.Filters = filters
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, input.VpcIds, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var vpcs []*ec2.Vpc
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		vpcs, err = finder.Vpcs(conn, input, 0)
+		return err
+	})
+	if tfawserr.ErrCodeEquals(err, tfec2.ErrCodeInvalidVpcIDNotFound) {
+		return diag.Errorf("EC2 VPC (%s) not found", d.Get("vpc_id").(string))
+	}
+	if err != nil {
+		return diag.Errorf("error reading EC2 VPCs: %s", err)
+	}
+
+	switch len(vpcs) {
+	case 0:
+		if meta.(*AWSClient).validateOnly {
+			// No VPC is found without calling the API, and so nothing to report on.
+			d.SetId(region)
+			return warnings
+		}
+		return diag.Errorf("no matching EC2 VPC found")
+	case 1:
+	default:
+		return diag.Errorf("%d EC2 VPCs matched; use additional constraints to reduce matches to a single VPC", len(vpcs))
+	}
+
+	vpcID := aws.StringValue(vpcs[0].VpcId)
+	vpcFilter := buildEC2AttributeFilterList(map[string]string{"vpc-id": vpcID})
+
+	var subnets []*ec2.Subnet
+	var routeTables []*ec2.RouteTable
+	var natGateways []*ec2.NatGateway
+
+	err = runConcurrently(d.Get("max_concurrency").(int),
+		func() error {
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				subnets, err = finder.Subnets(conn, &ec2.DescribeSubnetsInput{Filters: vpcFilter}, 0)
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EC2 Subnets for VPC (%s): %w", vpcID, err)
+			}
+			return nil
+		},
+		func() error {
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				routeTables, err = finder.RouteTables(conn, &ec2.DescribeRouteTablesInput{Filters: vpcFilter}, 0)
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EC2 Route Tables for VPC (%s): %w", vpcID, err)
+			}
+			return nil
+		},
+		func() error {
+			input := &ec2.DescribeNatGatewaysInput{
+				Filter: buildEC2AttributeFilterList(map[string]string{
+					"vpc-id": vpcID,
+					"state":  ec2.NatGatewayStateAvailable,
+				}),
+			}
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				natGateways, err = finder.NatGateways(conn, input)
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EC2 NAT Gateways for VPC (%s): %w", vpcID, err)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	report := ec2NatGatewayConsolidationReportFor(natGateways, subnets, routeTables)
+
+	d.SetId(vpcID)
+	d.Set("vpc_id", vpcID)
+
+	if err := d.Set("availability_zones", report.AvailabilityZones); err != nil {
+		return diag.Errorf("error setting availability_zones: %s", err)
+	}
+
+	if err := d.Set("nat_gateways", report.NatGateways); err != nil {
+		return diag.Errorf("error setting nat_gateways: %s", err)
+	}
+
+	if err := d.Set("cross_az_routes", report.CrossAzRoutes); err != nil {
+		return diag.Errorf("error setting cross_az_routes: %s", err)
+	}
+
+	if err := d.Set("redundant_availability_zones", report.RedundantAvailabilityZones); err != nil {
+		return diag.Errorf("error setting redundant_availability_zones: %s", err)
+	}
+
+	if err := d.Set("cross_az_subnet_ids", report.CrossAzSubnetIDs); err != nil {
+		return diag.Errorf("error setting cross_az_subnet_ids: %s", err)
+	}
+
+	if err := d.Set("unused_nat_gateway_ids", report.UnusedNatGatewayIDs); err != nil {
+		return diag.Errorf("error setting unused_nat_gateway_ids: %s", err)
+	}
+
+	return warnings
+}
+
+// ec2NatGatewayConsolidationReport holds the flattened computed attributes of the
+// awsutils_ec2_nat_gateway_consolidator_report data source.
+type ec2NatGatewayConsolidationReport struct {
+	AvailabilityZones          []map[string]interface{}
+	NatGateways                []map[string]interface{}
+	CrossAzRoutes              []map[string]interface{}
+	RedundantAvailabilityZones []string
+	CrossAzSubnetIDs           []string
+	UnusedNatGatewayIDs        []string
+}
+
+// ec2NatGatewayConsolidationReportFor returns the report of the given NAT Gateways of a VPC, mapped to the given
+// Subnets of the VPC through their effective Route Table among the given Route Tables of the VPC.
+func ec2NatGatewayConsolidationReportFor(natGateways []*ec2.NatGateway, subnets []*ec2.Subnet, routeTables []*ec2.RouteTable) ec2NatGatewayConsolidationReport {
+	report := ec2NatGatewayConsolidationReport{
+		AvailabilityZones:          make([]map[string]interface{}, 0),
+		NatGateways:                make([]map[string]interface{}, 0, len(natGateways)),
+		CrossAzRoutes:              make([]map[string]interface{}, 0),
+		RedundantAvailabilityZones: make([]string, 0),
+		CrossAzSubnetIDs:           make([]string, 0),
+		UnusedNatGatewayIDs:        make([]string, 0),
+	}
+
+	subnetAvailabilityZones := make(map[string]string, len(subnets))
+	for _, subnet := range subnets {
+		subnetAvailabilityZones[aws.StringValue(subnet.SubnetId)] = aws.StringValue(subnet.AvailabilityZone)
+	}
+
+	natGatewayAvailabilityZones := make(map[string]string, len(natGateways))
+	azNatGatewayIDs := make(map[string][]string)
+	for _, natGateway := range natGateways {
+		natGatewayID := aws.StringValue(natGateway.NatGatewayId)
+
+		az, ok := subnetAvailabilityZones[aws.StringValue(natGateway.SubnetId)]
+		if !ok {
+			log.Printf("[WARN] Subnet (%s) of EC2 NAT Gateway (%s) not found", aws.StringValue(natGateway.SubnetId), natGatewayID)
+		}
+		natGatewayAvailabilityZones[natGatewayID] = az
+		if az != "" {
+			azNatGatewayIDs[az] = append(azNatGatewayIDs[az], natGatewayID)
+		}
+	}
+
+	sortedSubnets := append([]*ec2.Subnet{}, subnets...)
+	sort.Slice(sortedSubnets, func(i, j int) bool {
+		return aws.StringValue(sortedSubnets[i].SubnetId) < aws.StringValue(sortedSubnets[j].SubnetId)
+	})
+
+	index := newEc2RouteTableIndex(routeTables)
+	routedSubnetIDs := make(map[string][]string)
+	azSubnetIDs := make(map[string][]string)
+
+	for _, subnet := range sortedSubnets {
+		subnetID := aws.StringValue(subnet.SubnetId)
+		subnetAZ := aws.StringValue(subnet.AvailabilityZone)
+
+		routeTable, _ := index.forSubnet(subnet)
+		if routeTable == nil {
+			log.Printf("[WARN] No Route Table found for EC2 Subnet (%s)", subnetID)
+			continue
+		}
+
+		routes := ec2NatGatewayRoutes(routeTable)
+		crossAz := false
+
+		for _, route := range routes {
+			natGatewayID := aws.StringValue(route.NatGatewayId)
+
+			natGatewayAZ, ok := natGatewayAvailabilityZones[natGatewayID]
+			if !ok {
+				// The route targets a NAT Gateway which is not available, and so is already broken or soon will be.
+				log.Printf("[DEBUG] EC2 NAT Gateway (%s) routed to by Subnet (%s) is not available", natGatewayID, subnetID)
+				continue
+			}
+
+			routedSubnetIDs[natGatewayID] = appendUniqueString(routedSubnetIDs[natGatewayID], subnetID)
+			azSubnetIDs[subnetAZ] = appendUniqueString(azSubnetIDs[subnetAZ], subnetID)
+
+			if natGatewayAZ == "" || natGatewayAZ == subnetAZ {
+				continue
+			}
+
+			crossAz = true
+			report.CrossAzRoutes = append(report.CrossAzRoutes, map[string]interface{}{
+				"subnet_id":                     subnetID,
+				"subnet_availability_zone":      subnetAZ,
+				"route_table_id":                aws.StringValue(routeTable.RouteTableId),
+				"destination":                   ec2RouteDestination(route),
+				"nat_gateway_id":                natGatewayID,
+				"nat_gateway_availability_zone": natGatewayAZ,
+			})
+		}
+
+		if crossAz {
+			report.CrossAzSubnetIDs = append(report.CrossAzSubnetIDs, subnetID)
+		}
+	}
+
+	azs := make([]string, 0, len(azNatGatewayIDs)+len(azSubnetIDs))
+	for az := range azNatGatewayIDs {
+		azs = appendUniqueString(azs, az)
+	}
+	for az := range azSubnetIDs {
+		azs = appendUniqueString(azs, az)
+	}
+	sort.Strings(azs)
+
+	for _, az := range azs {
+		natGatewayIDs := append([]string{}, azNatGatewayIDs[az]...)
+		sort.Strings(natGatewayIDs)
+
+		redundant := len(natGatewayIDs) > 1
+		if redundant {
+			report.RedundantAvailabilityZones = append(report.RedundantAvailabilityZones, az)
+		}
+
+		report.AvailabilityZones = append(report.AvailabilityZones, map[string]interface{}{
+			"availability_zone": az,
+			"nat_gateway_ids":   natGatewayIDs,
+			"subnet_ids":        append([]string{}, azSubnetIDs[az]...),
+			"redundant":         redundant,
+		})
+	}
+
+	sortedNatGateways := append([]*ec2.NatGateway{}, natGateways...)
+	sort.Slice(sortedNatGateways, func(i, j int) bool {
+		return aws.StringValue(sortedNatGateways[i].NatGatewayId) < aws.StringValue(sortedNatGateways[j].NatGatewayId)
+	})
+
+	for _, natGateway := range sortedNatGateways {
+		natGatewayID := aws.StringValue(natGateway.NatGatewayId)
+
+		routed := append([]string{}, routedSubnetIDs[natGatewayID]...)
+		if len(routed) == 0 {
+			report.UnusedNatGatewayIDs = append(report.UnusedNatGatewayIDs, natGatewayID)
+		}
+
+		report.NatGateways = append(report.NatGateways, map[string]interface{}{
+			"nat_gateway_id":    natGatewayID,
+			"subnet_id":         aws.StringValue(natGateway.SubnetId),
+			"availability_zone": natGatewayAvailabilityZones[natGatewayID],
+			"connectivity_type": aws.StringValue(natGateway.ConnectivityType),
+			"routed_subnet_ids": routed,
+		})
+	}
+
+	return report
+}
+
+// ec2NatGatewayRoutes returns the active routes of the given Route Table to a NAT Gateway, ordered by destination.
+func ec2NatGatewayRoutes(routeTable *ec2.RouteTable) []*ec2.Route {
+	var routes []*ec2.Route
+
+	for _, route := range routeTable.Routes {
+		if route == nil || aws.StringValue(route.NatGatewayId) == "" || aws.StringValue(route.State) == ec2.RouteStateBlackhole {
+			continue
+		}
+
+		routes = append(routes, route)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		return ec2RouteDestination(routes[i]) < ec2RouteDestination(routes[j])
+	})
+
+	return routes
+}
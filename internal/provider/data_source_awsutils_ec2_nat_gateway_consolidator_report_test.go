@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEc2NatGatewayConsolidationReportFor(t *testing.T) {
+	subnet := func(subnetID, az string) *ec2.Subnet {
+		return &ec2.Subnet{SubnetId: aws.String(subnetID), VpcId: aws.String("vpc-00000001"), AvailabilityZone: aws.String(az)}
+	}
+	natGateway := func(natGatewayID, subnetID string) *ec2.NatGateway {
+		return &ec2.NatGateway{NatGatewayId: aws.String(natGatewayID), SubnetId: aws.String(subnetID), ConnectivityType: aws.String(ec2.ConnectivityTypePublic)}
+	}
+	natRoute := func(destination, natGatewayID string) *ec2.Route {
+		return &ec2.Route{DestinationCidrBlock: aws.String(destination), NatGatewayId: aws.String(natGatewayID), State: aws.String(ec2.RouteStateActive)}
+	}
+
+	subnets := []*ec2.Subnet{
+		subnet("subnet-public-a1", "us-east-1a"),
+		subnet("subnet-public-a2", "us-east-1a"),
+		subnet("subnet-public-b", "us-east-1b"),
+		subnet("subnet-private-a", "us-east-1a"),
+		subnet("subnet-private-b", "us-east-1b"),
+		subnet("subnet-private-c", "us-east-1c"),
+	}
+	natGateways := []*ec2.NatGateway{
+		natGateway("nat-00000003", "subnet-public-b"),
+		natGateway("nat-00000002", "subnet-public-a2"),
+		natGateway("nat-00000001", "subnet-public-a1"),
+	}
+	routeTables := []*ec2.RouteTable{
+		{
+			// The private Subnet of us-east-1a has no explicit association.
+			RouteTableId: aws.String("rtb-main"),
+			VpcId:        aws.String("vpc-00000001"),
+			Associations: []*ec2.RouteTableAssociation{{Main: aws.Bool(true)}},
+			Routes:       []*ec2.Route{natRoute("0.0.0.0/0", "nat-00000001")},
+		},
+		{
+			RouteTableId: aws.String("rtb-public"),
+			VpcId:        aws.String("vpc-00000001"),
+			Associations: []*ec2.RouteTableAssociation{
+				{SubnetId: aws.String("subnet-public-a1")},
+				{SubnetId: aws.String("subnet-public-a2")},
+				{SubnetId: aws.String("subnet-public-b")},
+			},
+			Routes: []*ec2.Route{{DestinationCidrBlock: aws.String("0.0.0.0/0"), GatewayId: aws.String("igw-00000001"), State: aws.String(ec2.RouteStateActive)}},
+		},
+		{
+			RouteTableId: aws.String("rtb-private-b"),
+			VpcId:        aws.String("vpc-00000001"),
+			Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String("subnet-private-b")}},
+			Routes: []*ec2.Route{
+				natRoute("10.1.0.0/16", "nat-00000001"),
+				natRoute("0.0.0.0/0", "nat-00000002"),
+				{DestinationCidrBlock: aws.String("10.2.0.0/16"), NatGatewayId: aws.String("nat-00000003"), State: aws.String(ec2.RouteStateBlackhole)},
+			},
+		},
+		{
+			// The NAT Gateway is deleted, and so is not described.
+			RouteTableId: aws.String("rtb-private-c"),
+			VpcId:        aws.String("vpc-00000001"),
+			Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String("subnet-private-c")}},
+			Routes:       []*ec2.Route{natRoute("0.0.0.0/0", "nat-00000004")},
+		},
+	}
+
+	expected := ec2NatGatewayConsolidationReport{
+		AvailabilityZones: []map[string]interface{}{
+			{
+				"availability_zone": "us-east-1a",
+				"nat_gateway_ids":   []string{"nat-00000001", "nat-00000002"},
+				"subnet_ids":        []string{"subnet-private-a"},
+				"redundant":         true,
+			},
+			{
+				"availability_zone": "us-east-1b",
+				"nat_gateway_ids":   []string{"nat-00000003"},
+				"subnet_ids":        []string{"subnet-private-b"},
+				"redundant":         false,
+			},
+		},
+		NatGateways: []map[string]interface{}{
+			{
+				"nat_gateway_id":    "nat-00000001",
+				"subnet_id":         "subnet-public-a1",
+				"availability_zone": "us-east-1a",
+				"connectivity_type": ec2.ConnectivityTypePublic,
+				"routed_subnet_ids": []string{"subnet-private-a", "subnet-private-b"},
+			},
+			{
+				"nat_gateway_id":    "nat-00000002",
+				"subnet_id":         "subnet-public-a2",
+				"availability_zone": "us-east-1a",
+				"connectivity_type": ec2.ConnectivityTypePublic,
+				"routed_subnet_ids": []string{"subnet-private-b"},
+			},
+			{
+				"nat_gateway_id":    "nat-00000003",
+				"subnet_id":         "subnet-public-b",
+				"availability_zone": "us-east-1b",
+				"connectivity_type": ec2.ConnectivityTypePublic,
+				"routed_subnet_ids": []string{},
+			},
+		},
+		CrossAzRoutes: []map[string]interface{}{
+			{
+				"subnet_id":                     "subnet-private-b",
+				"subnet_availability_zone":      "us-east-1b",
+				"route_table_id":                "rtb-private-b",
+				"destination":                   "0.0.0.0/0",
+				"nat_gateway_id":                "nat-00000002",
+				"nat_gateway_availability_zone": "us-east-1a",
+			},
+			{
+				"subnet_id":                     "subnet-private-b",
+				"subnet_availability_zone":      "us-east-1b",
+				"route_table_id":                "rtb-private-b",
+				"destination":                   "10.1.0.0/16",
+				"nat_gateway_id":                "nat-00000001",
+				"nat_gateway_availability_zone": "us-east-1a",
+			},
+		},
+		RedundantAvailabilityZones: []string{"us-east-1a"},
+		CrossAzSubnetIDs:           []string{"subnet-private-b"},
+		UnusedNatGatewayIDs:        []string{"nat-00000003"},
+	}
+
+	got := ec2NatGatewayConsolidationReportFor(natGateways, subnets, routeTables)
+
+	if !reflect.DeepEqual(got.AvailabilityZones, expected.AvailabilityZones) {
+		t.Errorf("got availability zones %v, expected %v", got.AvailabilityZones, expected.AvailabilityZones)
+	}
+	if !reflect.DeepEqual(got.NatGateways, expected.NatGateways) {
+		t.Errorf("got NAT gateways %v, expected %v", got.NatGateways, expected.NatGateways)
+	}
+	if !reflect.DeepEqual(got.CrossAzRoutes, expected.CrossAzRoutes) {
+		t.Errorf("got cross-AZ routes %v, expected %v", got.CrossAzRoutes, expected.CrossAzRoutes)
+	}
+	if !reflect.DeepEqual(got.RedundantAvailabilityZones, expected.RedundantAvailabilityZones) {
+		t.Errorf("got redundant availability zones %v, expected %v", got.RedundantAvailabilityZones, expected.RedundantAvailabilityZones)
+	}
+	if !reflect.DeepEqual(got.CrossAzSubnetIDs, expected.CrossAzSubnetIDs) {
+		t.Errorf("got cross-AZ subnet IDs %v, expected %v", got.CrossAzSubnetIDs, expected.CrossAzSubnetIDs)
+	}
+	if !reflect.DeepEqual(got.UnusedNatGatewayIDs, expected.UnusedNatGatewayIDs) {
+		t.Errorf("got unused NAT gateway IDs %v, expected %v", got.UnusedNatGatewayIDs, expected.UnusedNatGatewayIDs)
+	}
+}
+
+func TestEc2NatGatewayConsolidationReportForEmpty(t *testing.T) {
+	got := ec2NatGatewayConsolidationReportFor(nil, nil, nil)
+
+	if len(got.AvailabilityZones) != 0 || got.AvailabilityZones == nil || got.UnusedNatGatewayIDs == nil {
+		t.Errorf("got %v, expected empty lists", got)
+	}
+}
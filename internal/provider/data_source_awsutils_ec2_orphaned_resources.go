@@ -0,0 +1,428 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAwsUtilsEc2OrphanedResources() *schema.Resource {
+	return &schema.Resource{
+		Description: `Finds the common orphaned EC2 resources of the region, for a cleanup report: the detached Network
+Interfaces, the unattached EBS Volumes, the unassociated Elastic IPs and the blackhole routes of the Route Tables.
+
+Each category is described independently, in parallel with at most ` + "`max_concurrency`" + ` requests in flight at
+any time, and can be disabled with its ` + "`include_...`" + ` attribute. The tag selection attributes apply to every
+category, matching the tags of the Network Interfaces, Volumes, Elastic IPs and Route Tables. Volumes and Elastic IPs
+do not belong to a VPC, so ` + "`vpc_id`" + ` only scopes the Network Interfaces and the Route Tables; disable the
+other categories for a report limited to a VPC. ` + "`requester_managed`" + ` and ` + "`interface_type`" + ` only scope the
+Network Interfaces, e.g. ` + "`requester_managed = false`" + ` leaves out those managed by AWS services, such as the
+interfaces of NAT gateways and VPC endpoints, which cannot be deleted.`,
+		ReadContext:   dataSourceAwsUtilsEc2OrphanedResourcesRead,
+		SchemaVersion: 1,
+		Schema: mergeSchemas(map[string]*schema.Schema{
+			"vpc_id": {
+				Description:  "Only match the Network Interfaces and Route Tables of the given VPC.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"name":                      ec2NameSchema(),
+			"tags":                      tagsSchema(),
+			"region":                    ec2RegionSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"include_network_interfaces": {
+				Description: "Whether to report the Network Interfaces which are not attached to anything.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			"include_volumes": {
+				Description: "Whether to report the EBS Volumes which are not attached to any instance.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			"include_elastic_ips": {
+				Description: "Whether to report the Elastic IPs which are not associated with any instance or Network Interface.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			"include_blackhole_routes": {
+				Description: "Whether to report the routes whose target no longer exists.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			"max_concurrency": {
+				Description:  "The maximum number of describe requests in flight at any time.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntBetween(1, 4),
+			},
+			"network_interfaces": {
+				Description: "The detached Network Interfaces, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"network_interface_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vpc_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subnet_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"interface_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"volumes": {
+				Description: "The unattached EBS Volumes, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"volume_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"availability_zone": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"volume_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"size": {
+							Description: "The size of the Volume, in GiB.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"elastic_ips": {
+				Description: "The unassociated Elastic IPs, ordered by public IP.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"allocation_id": {
+							Description: "The allocation ID of the Elastic IP, or an empty string for an EC2-Classic address.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"public_ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"domain": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"prometheus_metrics": prometheusMetricsSchema(),
+			"blackhole_routes": {
+				Description: "The blackhole routes, ordered by Route Table ID and destination.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"route_table_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vpc_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"destination": {
+							Description: "The IPv4 or IPv6 CIDR block, or the prefix list ID, of the destination of the route.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"target": {
+							Description: "The ID of the deleted target of the route.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		}, ec2NetworkInterfaceFilterSchemas()),
+	}
+}
+
+func dataSourceAwsUtilsEc2OrphanedResourcesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	maxResults := maxResultsCap(d, meta)
+
+	// Only tag filters are built, which every category supports, so the resource type only matters to IDs, of
+	// which there are none.
+	_, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeNetworkInterface)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var vpcFilters []*ec2.Filter
+	if v, ok := d.GetOk("vpc_id"); ok {
+		vpcFilters = buildEC2AttributeFilterList(map[string]string{"vpc-id": v.(string)})
+	}
+
+	// withFilters returns the selection filters followed by the given filters of a category, in a new slice as the
+	// categories are described concurrently.
+	withFilters := func(categoryFilters ...[]*ec2.Filter) []*ec2.Filter {
+		result := append([]*ec2.Filter{}, filters...)
+		for _, f := range categoryFilters {
+			result = append(result, f...)
+		}
+		if len(result) == 0 {
+			return nil
+		}
+		return result
+	}
+
+	// The status filters of the categories are always set, so only the selection and VPC filters are checked.
+	unfilteredWarnings, err := checkEC2Unfiltered(d, nil, withFilters(vpcFilters))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var networkInterfaces []*ec2.NetworkInterface
+	var volumes []*ec2.Volume
+	var addresses []*ec2.Address
+	var routeTables []*ec2.RouteTable
+
+	var funcs []func() error
+
+	if d.Get("include_network_interfaces").(bool) {
+		funcs = append(funcs, func() error {
+			input := &ec2.DescribeNetworkInterfacesInput{
+				Filters: withFilters(buildEC2AttributeFilterList(map[string]string{"status": ec2.NetworkInterfaceStatusAvailable}), vpcFilters, buildEC2NetworkInterfaceAttributeFilterList(d)),
+			}
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				networkInterfaces, err = finder.NetworkInterfaces(conn, input)
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EC2 Network Interfaces: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if d.Get("include_volumes").(bool) {
+		funcs = append(funcs, func() error {
+			input := &ec2.DescribeVolumesInput{
+				Filters: withFilters(buildEC2AttributeFilterList(map[string]string{"status": ec2.VolumeStateAvailable})),
+			}
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				volumes, err = finder.Volumes(conn, input, maxResults)
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EBS Volumes: %w", maxResultsCapError(err))
+			}
+			return nil
+		})
+	}
+
+	if d.Get("include_elastic_ips").(bool) {
+		funcs = append(funcs, func() error {
+			// There is no filter matching the unassociated addresses, so they are found by ec2OrphanedAddresses.
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				addresses, err = finder.Addresses(conn, &ec2.DescribeAddressesInput{Filters: withFilters()})
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EC2 Elastic IPs: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if d.Get("include_blackhole_routes").(bool) {
+		funcs = append(funcs, func() error {
+			input := &ec2.DescribeRouteTablesInput{
+				Filters: withFilters(buildEC2AttributeFilterList(map[string]string{"route.state": ec2.RouteStateBlackhole}), vpcFilters),
+			}
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				routeTables, err = finder.RouteTables(conn, input, maxResults)
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EC2 Route Tables: %w", maxResultsCapError(err))
+			}
+			return nil
+		})
+	}
+
+	if err := runConcurrently(d.Get("max_concurrency").(int), funcs...); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(region)
+
+	if err := d.Set("network_interfaces", flattenEc2OrphanedNetworkInterfaces(networkInterfaces)); err != nil {
+		return diag.Errorf("error setting network_interfaces: %s", err)
+	}
+
+	if err := d.Set("volumes", flattenEc2OrphanedVolumes(volumes)); err != nil {
+		return diag.Errorf("error setting volumes: %s", err)
+	}
+
+	if err := d.Set("elastic_ips", flattenEc2OrphanedAddresses(addresses)); err != nil {
+		return diag.Errorf("error setting elastic_ips: %s", err)
+	}
+
+	if err := d.Set("blackhole_routes", flattenEc2BlackholeRoutes(routeTables)); err != nil {
+		return diag.Errorf("error setting blackhole_routes: %s", err)
+	}
+
+	// The disabled categories are left out rather than counted as having no orphaned resources.
+	var samples []prometheusSample
+	for _, category := range []string{"network_interfaces", "volumes", "elastic_ips", "blackhole_routes"} {
+		if d.Get("include_" + category).(bool) {
+			samples = append(samples, prometheusSample{
+				Labels: map[string]string{"category": category},
+				Value:  float64(len(d.Get(category).([]interface{}))),
+			})
+		}
+	}
+
+	if err := setPrometheusMetrics(d, prometheusMetric{
+		Name:    "awsutils_ec2_orphaned_resources",
+		Help:    "The number of orphaned EC2 resources by category.",
+		Samples: samples,
+	}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return warnings
+}
+
+func flattenEc2OrphanedNetworkInterfaces(networkInterfaces []*ec2.NetworkInterface) []interface{} {
+	sort.Slice(networkInterfaces, func(i, j int) bool {
+		return aws.StringValue(networkInterfaces[i].NetworkInterfaceId) < aws.StringValue(networkInterfaces[j].NetworkInterfaceId)
+	})
+
+	result := make([]interface{}, 0, len(networkInterfaces))
+	for _, networkInterface := range networkInterfaces {
+		result = append(result, map[string]interface{}{
+			"network_interface_id": aws.StringValue(networkInterface.NetworkInterfaceId),
+			"vpc_id":               aws.StringValue(networkInterface.VpcId),
+			"subnet_id":            aws.StringValue(networkInterface.SubnetId),
+			"interface_type":       aws.StringValue(networkInterface.InterfaceType),
+			"description":          aws.StringValue(networkInterface.Description),
+		})
+	}
+
+	return result
+}
+
+func flattenEc2OrphanedVolumes(volumes []*ec2.Volume) []interface{} {
+	sort.Slice(volumes, func(i, j int) bool {
+		return aws.StringValue(volumes[i].VolumeId) < aws.StringValue(volumes[j].VolumeId)
+	})
+
+	result := make([]interface{}, 0, len(volumes))
+	for _, volume := range volumes {
+		result = append(result, map[string]interface{}{
+			"volume_id":         aws.StringValue(volume.VolumeId),
+			"availability_zone": aws.StringValue(volume.AvailabilityZone),
+			"volume_type":       aws.StringValue(volume.VolumeType),
+			"size":              int(aws.Int64Value(volume.Size)),
+		})
+	}
+
+	return result
+}
+
+// flattenEc2OrphanedAddresses flattens the given addresses which are associated with neither an instance nor a
+// Network Interface.
+func flattenEc2OrphanedAddresses(addresses []*ec2.Address) []interface{} {
+	sort.Slice(addresses, func(i, j int) bool {
+		return aws.StringValue(addresses[i].PublicIp) < aws.StringValue(addresses[j].PublicIp)
+	})
+
+	result := make([]interface{}, 0)
+	for _, address := range addresses {
+		// EC2-Classic addresses have no association ID, but an instance ID when associated.
+		if aws.StringValue(address.AssociationId) != "" || aws.StringValue(address.InstanceId) != "" || aws.StringValue(address.NetworkInterfaceId) != "" {
+			continue
+		}
+
+		result = append(result, map[string]interface{}{
+			"allocation_id": aws.StringValue(address.AllocationId),
+			"public_ip":     aws.StringValue(address.PublicIp),
+			"domain":        aws.StringValue(address.Domain),
+		})
+	}
+
+	return result
+}
+
+// flattenEc2BlackholeRoutes flattens the blackhole routes of the given Route Tables, which may also have active
+// routes as the "route.state" filter matches the tables with any blackhole route.
+func flattenEc2BlackholeRoutes(routeTables []*ec2.RouteTable) []interface{} {
+	var routes []map[string]interface{}
+
+	for _, routeTable := range routeTables {
+		for _, route := range routeTable.Routes {
+			if route == nil || aws.StringValue(route.State) != ec2.RouteStateBlackhole {
+				continue
+			}
+
+			routes = append(routes, map[string]interface{}{
+				"route_table_id": aws.StringValue(routeTable.RouteTableId),
+				"vpc_id":         aws.StringValue(routeTable.VpcId),
+				"destination":    ec2RouteDestination(route),
+				"target":         ec2RouteTarget(route),
+			})
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i]["route_table_id"] != routes[j]["route_table_id"] {
+			return routes[i]["route_table_id"].(string) < routes[j]["route_table_id"].(string)
+		}
+		return routes[i]["destination"].(string) < routes[j]["destination"].(string)
+	})
+
+	result := make([]interface{}, 0, len(routes))
+	for _, route := range routes {
+		result = append(result, route)
+	}
+
+	return result
+}
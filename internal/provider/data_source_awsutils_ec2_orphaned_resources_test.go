@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestFlattenEc2OrphanedAddresses(t *testing.T) {
+	addresses := []*ec2.Address{
+		{AllocationId: aws.String("eipalloc-00000002"), PublicIp: aws.String("192.0.2.2"), Domain: aws.String(ec2.DomainTypeVpc)},
+		{AllocationId: aws.String("eipalloc-00000001"), PublicIp: aws.String("192.0.2.1"), Domain: aws.String(ec2.DomainTypeVpc), AssociationId: aws.String("eipassoc-00000001")},
+		{AllocationId: aws.String("eipalloc-00000003"), PublicIp: aws.String("192.0.2.3"), Domain: aws.String(ec2.DomainTypeVpc), NetworkInterfaceId: aws.String("eni-00000001")},
+		{PublicIp: aws.String("192.0.2.4"), Domain: aws.String(ec2.DomainTypeStandard), InstanceId: aws.String("i-00000001")},
+		{PublicIp: aws.String("192.0.2.0"), Domain: aws.String(ec2.DomainTypeStandard)},
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{"allocation_id": "", "public_ip": "192.0.2.0", "domain": ec2.DomainTypeStandard},
+		map[string]interface{}{"allocation_id": "eipalloc-00000002", "public_ip": "192.0.2.2", "domain": ec2.DomainTypeVpc},
+	}
+
+	if got := flattenEc2OrphanedAddresses(addresses); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestFlattenEc2BlackholeRoutes(t *testing.T) {
+	routeTables := []*ec2.RouteTable{
+		{
+			RouteTableId: aws.String("rtb-00000002"),
+			VpcId:        aws.String("vpc-00000001"),
+			Routes: []*ec2.Route{
+				{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local"), State: aws.String(ec2.RouteStateActive)},
+				{DestinationIpv6CidrBlock: aws.String("::/0"), EgressOnlyInternetGatewayId: aws.String("eigw-00000001"), State: aws.String(ec2.RouteStateBlackhole)},
+				{DestinationCidrBlock: aws.String("0.0.0.0/0"), NatGatewayId: aws.String("nat-00000001"), State: aws.String(ec2.RouteStateBlackhole)},
+			},
+		},
+		{
+			RouteTableId: aws.String("rtb-00000001"),
+			VpcId:        aws.String("vpc-00000001"),
+			Routes: []*ec2.Route{
+				{DestinationPrefixListId: aws.String("pl-00000001"), VpcPeeringConnectionId: aws.String("pcx-00000001"), State: aws.String(ec2.RouteStateBlackhole)},
+			},
+		},
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{"route_table_id": "rtb-00000001", "vpc_id": "vpc-00000001", "destination": "pl-00000001", "target": "pcx-00000001"},
+		map[string]interface{}{"route_table_id": "rtb-00000002", "vpc_id": "vpc-00000001", "destination": "0.0.0.0/0", "target": "nat-00000001"},
+		map[string]interface{}{"route_table_id": "rtb-00000002", "vpc_id": "vpc-00000001", "destination": "::/0", "target": "eigw-00000001"},
+	}
+
+	if got := flattenEc2BlackholeRoutes(routeTables); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestDataSourceAwsUtilsEc2OrphanedResourcesRead(t *testing.T) {
+	var mu sync.Mutex
+	var operations []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		mu.Lock()
+		operations = append(operations, r.Operation.Name)
+		mu.Unlock()
+
+		switch output := r.Data.(type) {
+		case *ec2.DescribeNetworkInterfacesOutput:
+			output.NetworkInterfaces = []*ec2.NetworkInterface{
+				{NetworkInterfaceId: aws.String("eni-00000001"), VpcId: aws.String("vpc-00000001"), SubnetId: aws.String("subnet-00000001"), InterfaceType: aws.String("interface")},
+			}
+		case *ec2.DescribeRouteTablesOutput:
+			output.RouteTables = []*ec2.RouteTable{
+				{
+					RouteTableId: aws.String("rtb-00000001"),
+					VpcId:        aws.String("vpc-00000001"),
+					Routes: []*ec2.Route{
+						{DestinationCidrBlock: aws.String("0.0.0.0/0"), NatGatewayId: aws.String("nat-00000001"), State: aws.String(ec2.RouteStateBlackhole)},
+					},
+				},
+			}
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2OrphanedResources().Schema, map[string]interface{}{
+		"vpc_id":              "vpc-00000001",
+		"include_volumes":     false,
+		"include_elastic_ips": false,
+	})
+
+	if diags := dataSourceAwsUtilsEc2OrphanedResourcesRead(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-east-1", maxResultsCap: defaultMaxResultsCap}); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	// The disabled categories are not described.
+	sort.Strings(operations)
+	if expected := []string{"DescribeNetworkInterfaces", "DescribeRouteTables"}; !reflect.DeepEqual(operations, expected) {
+		t.Errorf("got operations %v, expected %v", operations, expected)
+	}
+
+	if got := d.Get("network_interfaces.#").(int); got != 1 {
+		t.Errorf("got %d network interfaces, expected 1", got)
+	}
+
+	if got := d.Get("blackhole_routes.0.target").(string); got != "nat-00000001" {
+		t.Errorf("got blackhole route target %q, expected nat-00000001", got)
+	}
+
+	if got := d.Get("volumes.#").(int); got != 0 {
+		t.Errorf("got %d volumes, expected none", got)
+	}
+}
+
+func TestDataSourceAwsUtilsEc2OrphanedResourcesReadNetworkInterfaceFilters(t *testing.T) {
+	var networkInterfaceFilters []*ec2.Filter
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		if input, ok := r.Params.(*ec2.DescribeNetworkInterfacesInput); ok {
+			networkInterfaceFilters = input.Filters
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2OrphanedResources().Schema, map[string]interface{}{
+		"requester_managed":        false,
+		"interface_type":           "interface",
+		"include_volumes":          false,
+		"include_elastic_ips":      false,
+		"include_blackhole_routes": false,
+		"allow_unfiltered":         true,
+	})
+
+	if diags := dataSourceAwsUtilsEc2OrphanedResourcesRead(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-east-1", maxResultsCap: defaultMaxResultsCap}); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	expected := []*ec2.Filter{
+		{Name: aws.String("status"), Values: aws.StringSlice([]string{ec2.NetworkInterfaceStatusAvailable})},
+		{Name: aws.String("interface-type"), Values: aws.StringSlice([]string{"interface"})},
+		{Name: aws.String("requester-managed"), Values: aws.StringSlice([]string{"false"})},
+	}
+	if !reflect.DeepEqual(networkInterfaceFilters, expected) {
+		t.Errorf("got DescribeNetworkInterfaces filters %v, expected %v", networkInterfaceFilters, expected)
+	}
+}
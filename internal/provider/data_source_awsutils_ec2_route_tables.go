@@ -0,0 +1,267 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ec2RouteTablesFingerprintAttributes are the attributes of the "route_tables" hashed into their fingerprint.
+var ec2RouteTablesFingerprintAttributes = []string{"route_table_id", "vpc_id", "main", "subnet_ids"}
+
+func dataSourceAwsUtilsEc2RouteTables() *schema.Resource {
+	return &schema.Resource{
+		Description: `Lists the Route Tables matching the given filters.
+
+Setting ` + "`main_route_table_only`" + ` restricts the results to the main Route Table of each VPC, which is the Route
+Table of the Subnets without an explicit association.
+
+Each ` + "`filter_group`" + ` block runs an additional query for the Route Tables matching both the top-level selection and
+its own, a few at a time, whose IDs are reported by label in ` + "`filter_group_results`" + `. This replaces instances of
+this data source with ` + "`for_each`" + `.`,
+		ReadContext:   dataSourceAwsUtilsEc2RouteTablesRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":                       ec2IDsSchema(ec2.ResourceTypeRouteTable),
+			"ids_strict":                ec2IDsStrictSchema(),
+			"arns":                      ec2ARNsSchema(ec2.ResourceTypeRouteTable),
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"regex_filter":              ec2RegexFiltersSchema(),
+			"tags":                      tagsSchema(),
+			"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":              ec2ExcludeTagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"debug":                     debugSchema(),
+			"raw_response_json":         rawResponseJSONSchema(),
+			"applied_filters":           ec2AppliedFiltersSchema(),
+			"resolved_filters":          ec2ResolvedFiltersSchema(),
+			"filters_object":            ec2FiltersObjectSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"vpc_id": {
+				Description: "Only match Route Tables of the given VPC.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"main_route_table_only": {
+				Description: "Only match the main Route Table of each VPC. Shorthand for an `association.main` filter.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"filter_group":         ec2FilterGroupsSchema(),
+			"filter_group_results": ec2FilterGroupResultsSchema(),
+			"route_tables": {
+				Description: "The matching Route Tables, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"route_table_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vpc_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"main": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"subnet_ids": {
+							Description: "The IDs of the Subnets explicitly associated with the Route Table.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"fingerprint": fingerprintSchema(ec2RouteTablesFingerprintAttributes),
+					},
+				},
+			},
+			"route_table_ids": {
+				Description: "The IDs of the matching Route Tables.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2RouteTablesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &ec2.DescribeRouteTablesInput{}
+	input.Filters = buildEC2RouteTableAttributeFilterList(d.Get("vpc_id").(string), d.Get("main_route_table_only").(bool))
+
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeRouteTable)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.RouteTableIds = ids
+	input.Filters = append(input.Filters, filters...)
+
+	if len(input.Filters) == 0 {
+		input.Filters = nil
+	}
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	maxResults := maxResultsCap(d, meta)
+
+	var routeTables []*ec2.RouteTable
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		routeTables, err = finder.RouteTables(conn, input, maxResults)
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Route Tables: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.RouteTable)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	kept := routeTables[:0]
+	for _, routeTable := range routeTables {
+		if !excluded(routeTable.Tags) && tagsMatched(routeTable.Tags) && regexMatched(routeTable) {
+			kept = append(kept, routeTable)
+		}
+	}
+	routeTables = kept
+
+	groups, groupWarnings, err := buildEC2FilterGroups(d, meta, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, groupWarnings...)
+
+	groupResults, err := queryEC2FilterGroups(groups, ec2FilterGroupConcurrency, func(filters []*ec2.Filter) ([]string, error) {
+		routeTables, err := finder.RouteTables(conn, &ec2.DescribeRouteTablesInput{
+			RouteTableIds: input.RouteTableIds,
+			Filters:       filters,
+		}, maxResults)
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 Route Tables: %w", maxResultsCapError(err))
+		}
+
+		ids := make([]string, 0, len(routeTables))
+		for _, routeTable := range routeTables {
+			if excluded(routeTable.Tags) || !tagsMatched(routeTable.Tags) {
+				continue
+			}
+			ids = append(ids, aws.StringValue(routeTable.RouteTableId))
+		}
+
+		return ids, nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	sort.Slice(routeTables, func(i, j int) bool {
+		return aws.StringValue(routeTables[i].RouteTableId) < aws.StringValue(routeTables[j].RouteTableId)
+	})
+
+	results := make([]map[string]interface{}, 0, len(routeTables))
+	routeTableIDs := make([]string, 0, len(routeTables))
+
+	for _, routeTable := range routeTables {
+		var main bool
+		subnetIDs := make([]string, 0)
+
+		for _, association := range routeTable.Associations {
+			if aws.BoolValue(association.Main) {
+				main = true
+			}
+
+			if subnetID := aws.StringValue(association.SubnetId); subnetID != "" {
+				subnetIDs = append(subnetIDs, subnetID)
+			}
+		}
+		sort.Strings(subnetIDs)
+
+		results = append(results, map[string]interface{}{
+			"route_table_id": aws.StringValue(routeTable.RouteTableId),
+			"vpc_id":         aws.StringValue(routeTable.VpcId),
+			"main":           main,
+			"subnet_ids":     subnetIDs,
+		})
+		routeTableIDs = append(routeTableIDs, aws.StringValue(routeTable.RouteTableId))
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"RouteTables": routeTables}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setFingerprints(results, ec2RouteTablesFingerprintAttributes); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("route_tables", results); err != nil {
+		return diag.Errorf("error setting route_tables: %s", err)
+	}
+
+	if err := d.Set("route_table_ids", routeTableIDs); err != nil {
+		return diag.Errorf("error setting route_table_ids: %s", err)
+	}
+
+	if err := d.Set("filter_group_results", groupResults); err != nil {
+		return diag.Errorf("error setting filter_group_results: %s", err)
+	}
+
+	return warnings
+}
+
+// buildEC2RouteTableAttributeFilterList returns the filters matching the Route Tables of the given VPC, if not empty,
+// and only the main Route Tables if mainOnly is set.
+func buildEC2RouteTableAttributeFilterList(vpcID string, mainOnly bool) []*ec2.Filter {
+	attrs := map[string]string{
+		"vpc-id": vpcID,
+	}
+
+	// When not set, the attribute is left unconstrained rather than only matching the Route Tables which are not main.
+	if mainOnly {
+		attrs["association.main"] = strconv.FormatBool(mainOnly)
+	}
+
+	return buildEC2AttributeFilterList(attrs)
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestBuildEC2RouteTableAttributeFilterList(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		VpcID    string
+		MainOnly bool
+		Expected []*ec2.Filter
+	}{
+		{
+			Name: "unconstrained",
+		},
+		{
+			Name:  "VPC",
+			VpcID: "vpc-01234567",
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: aws.StringSlice([]string{"vpc-01234567"}),
+				},
+			},
+		},
+		{
+			Name:     "main route table of VPC",
+			VpcID:    "vpc-01234567",
+			MainOnly: true,
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("association.main"),
+					Values: aws.StringSlice([]string{"true"}),
+				},
+				{
+					Name:   aws.String("vpc-id"),
+					Values: aws.StringSlice([]string{"vpc-01234567"}),
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := buildEC2RouteTableAttributeFilterList(testCase.VpcID, testCase.MainOnly)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
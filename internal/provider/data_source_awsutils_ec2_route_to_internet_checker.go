@@ -0,0 +1,369 @@
+package provider
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	// ec2SubnetClassificationPublic marks a Subnet with a default route to an Internet Gateway.
+	ec2SubnetClassificationPublic = "public"
+	// ec2SubnetClassificationPrivate marks a Subnet with a default route to a NAT Gateway or an egress-only Internet
+	// Gateway, but none to an Internet Gateway.
+	ec2SubnetClassificationPrivate = "private"
+	// ec2SubnetClassificationIsolated marks a Subnet with a default route to neither.
+	ec2SubnetClassificationIsolated = "isolated"
+)
+
+const (
+	ec2DefaultRouteIpv4 = "0.0.0.0/0"
+	ec2DefaultRouteIpv6 = "::/0"
+)
+
+func dataSourceAwsUtilsEc2RouteToInternetChecker() *schema.Resource {
+	return &schema.Resource{
+		Description: `Classifies the Subnets matching the given filters by their route to the internet.
+
+The effective Route Table of each Subnet is resolved: the Route Table explicitly associated with the Subnet or, if
+there is none, the main Route Table of its VPC. Each Subnet is then classified as ` + "`public`" + ` if its Route Table
+has an active default route (` + "`0.0.0.0/0`" + ` or ` + "`::/0`" + `) to an Internet Gateway, as ` + "`private`" + ` if it
+instead has one to a NAT Gateway or an egress-only Internet Gateway, and as ` + "`isolated`" + ` otherwise. Default routes
+to other targets, such as Transit Gateways, NAT instances or VPC peering connections, are reported but do not make a
+Subnet public or private, as where the traffic leaves the network cannot be told from the Route Table alone.`,
+		ReadContext:   dataSourceAwsUtilsEc2RouteToInternetCheckerRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":                       ec2IDsSchema(ec2.ResourceTypeSubnet),
+			"ids_strict":                ec2IDsStrictSchema(),
+			"arns":                      ec2ARNsSchema(ec2.ResourceTypeSubnet),
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"tags":                      tagsSchema(),
+			"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":              ec2ExcludeTagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"subnets": {
+				Description: "The classified Subnets, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"subnet_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vpc_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"availability_zone": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"route_table_id": {
+							Description: "The ID of the effective Route Table of the Subnet, or an empty string if none is found.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"main_route_table": {
+							Description: "Whether the effective Route Table is the main Route Table of the VPC, the Subnet having no explicit association.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+						"classification": {
+							Description: "The classification of the Subnet, one of `public`, `private` or `isolated`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"ipv4_default_route_target": {
+							Description: "The ID of the target of the active `0.0.0.0/0` route, if any.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"ipv6_default_route_target": {
+							Description: "The ID of the target of the active `::/0` route, if any.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"public_subnet_ids": {
+				Description: "The IDs of the public Subnets.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"private_subnet_ids": {
+				Description: "The IDs of the private Subnets.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"isolated_subnet_ids": {
+				Description: "The IDs of the isolated Subnets.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2RouteToInternetCheckerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &ec2.DescribeSubnetsInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeSubnet)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.SubnetIds = ids
+	input.Filters = filters
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var subnets []*ec2.Subnet
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		subnets, err = finder.Subnets(conn, input, 0)
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Subnets: %s", err)
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	kept := subnets[:0]
+	for _, subnet := range subnets {
+		if !excluded(subnet.Tags) && tagsMatched(subnet.Tags) {
+			kept = append(kept, subnet)
+		}
+	}
+	subnets = kept
+
+	var vpcIDs []string
+	for _, subnet := range subnets {
+		vpcIDs = appendUniqueString(vpcIDs, aws.StringValue(subnet.VpcId))
+	}
+	sort.Strings(vpcIDs)
+
+	var routeTables []*ec2.RouteTable
+	if len(vpcIDs) > 0 {
+		// All the Route Tables of the VPCs are read, rather than those associated with the Subnets, so that the main
+		// Route Tables are found as well.
+		routeTables, err = finder.RouteTables(conn, &ec2.DescribeRouteTablesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: aws.StringSlice(vpcIDs),
+				},
+			},
+		}, 0)
+		if err != nil {
+			return diag.Errorf("error reading EC2 Route Tables: %s", err)
+		}
+	}
+
+	results := flattenEc2SubnetRoutesToInternet(subnets, routeTables)
+
+	subnetIDs := map[string][]string{
+		ec2SubnetClassificationPublic:   {},
+		ec2SubnetClassificationPrivate:  {},
+		ec2SubnetClassificationIsolated: {},
+	}
+	for _, result := range results {
+		classification := result["classification"].(string)
+		subnetIDs[classification] = append(subnetIDs[classification], result["subnet_id"].(string))
+	}
+
+	d.SetId(region)
+
+	if err := d.Set("subnets", results); err != nil {
+		return diag.Errorf("error setting subnets: %s", err)
+	}
+
+	for classification, ids := range subnetIDs {
+		if err := d.Set(classification+"_subnet_ids", ids); err != nil {
+			return diag.Errorf("error setting %s_subnet_ids: %s", classification, err)
+		}
+	}
+
+	return warnings
+}
+
+// flattenEc2SubnetRoutesToInternet returns the flattened "subnets" of the given Subnets, ordered by ID, classified
+// by the routes of their effective Route Table among the given Route Tables of their VPCs.
+func flattenEc2SubnetRoutesToInternet(subnets []*ec2.Subnet, routeTables []*ec2.RouteTable) []map[string]interface{} {
+	index := newEc2RouteTableIndex(routeTables)
+
+	sorted := append([]*ec2.Subnet{}, subnets...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return aws.StringValue(sorted[i].SubnetId) < aws.StringValue(sorted[j].SubnetId)
+	})
+
+	results := make([]map[string]interface{}, 0, len(sorted))
+	for _, subnet := range sorted {
+		subnetID := aws.StringValue(subnet.SubnetId)
+
+		routeTable, explicit := index.forSubnet(subnet)
+
+		var routeTableID, ipv4Target, ipv6Target string
+		if routeTable != nil {
+			routeTableID = aws.StringValue(routeTable.RouteTableId)
+			ipv4Target, ipv6Target = ec2DefaultRouteTargets(routeTable)
+		} else {
+			log.Printf("[WARN] No Route Table found for EC2 Subnet (%s)", subnetID)
+		}
+
+		results = append(results, map[string]interface{}{
+			"subnet_id":                 subnetID,
+			"vpc_id":                    aws.StringValue(subnet.VpcId),
+			"availability_zone":         aws.StringValue(subnet.AvailabilityZone),
+			"route_table_id":            routeTableID,
+			"main_route_table":          routeTable != nil && !explicit,
+			"classification":            ec2SubnetClassification(ipv4Target, ipv6Target),
+			"ipv4_default_route_target": ipv4Target,
+			"ipv6_default_route_target": ipv6Target,
+		})
+	}
+
+	return results
+}
+
+// ec2RouteTableIndex resolves the effective Route Table of Subnets among the Route Tables of their VPCs.
+type ec2RouteTableIndex struct {
+	main    map[string]*ec2.RouteTable
+	subnets map[string]*ec2.RouteTable
+}
+
+// newEc2RouteTableIndex returns the index of the given Route Tables by their main VPC and Subnet associations.
+func newEc2RouteTableIndex(routeTables []*ec2.RouteTable) ec2RouteTableIndex {
+	index := ec2RouteTableIndex{
+		main:    make(map[string]*ec2.RouteTable),
+		subnets: make(map[string]*ec2.RouteTable),
+	}
+
+	for _, routeTable := range routeTables {
+		for _, association := range routeTable.Associations {
+			if association == nil {
+				continue
+			}
+
+			if aws.BoolValue(association.Main) {
+				index.main[aws.StringValue(routeTable.VpcId)] = routeTable
+			}
+			if subnetID := aws.StringValue(association.SubnetId); subnetID != "" {
+				index.subnets[subnetID] = routeTable
+			}
+		}
+	}
+
+	return index
+}
+
+// forSubnet returns the effective Route Table of the given Subnet, the one explicitly associated with it or, if
+// there is none, the main Route Table of its VPC, or nil if neither is indexed, and whether it is explicit.
+func (index ec2RouteTableIndex) forSubnet(subnet *ec2.Subnet) (*ec2.RouteTable, bool) {
+	if routeTable, ok := index.subnets[aws.StringValue(subnet.SubnetId)]; ok {
+		return routeTable, true
+	}
+
+	return index.main[aws.StringValue(subnet.VpcId)], false
+}
+
+// ec2DefaultRouteTargets returns the IDs of the targets of the active IPv4 and IPv6 default routes of the given
+// Route Table, or empty strings for missing routes.
+func ec2DefaultRouteTargets(routeTable *ec2.RouteTable) (string, string) {
+	var ipv4Target, ipv6Target string
+
+	for _, route := range routeTable.Routes {
+		if route == nil || aws.StringValue(route.State) == ec2.RouteStateBlackhole {
+			continue
+		}
+
+		switch {
+		case aws.StringValue(route.DestinationCidrBlock) == ec2DefaultRouteIpv4:
+			ipv4Target = ec2RouteTarget(route)
+		case aws.StringValue(route.DestinationIpv6CidrBlock) == ec2DefaultRouteIpv6:
+			ipv6Target = ec2RouteTarget(route)
+		}
+	}
+
+	return ipv4Target, ipv6Target
+}
+
+// ec2RouteTarget returns the ID of the target of the given route.
+func ec2RouteTarget(route *ec2.Route) string {
+	for _, target := range []*string{
+		route.GatewayId,
+		route.NatGatewayId,
+		route.EgressOnlyInternetGatewayId,
+		route.TransitGatewayId,
+		route.VpcPeeringConnectionId,
+		route.NetworkInterfaceId,
+		route.InstanceId,
+		route.LocalGatewayId,
+		route.CarrierGatewayId,
+	} {
+		if id := aws.StringValue(target); id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// ec2RouteDestination returns the IPv4 or IPv6 CIDR block, or the prefix list ID, of the destination of the given
+// route.
+func ec2RouteDestination(route *ec2.Route) string {
+	for _, destination := range []*string{
+		route.DestinationCidrBlock,
+		route.DestinationIpv6CidrBlock,
+		route.DestinationPrefixListId,
+	} {
+		if v := aws.StringValue(destination); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// ec2SubnetClassification classifies a Subnet from the IDs of the targets of its IPv4 and IPv6 default routes.
+func ec2SubnetClassification(targets ...string) string {
+	classification := ec2SubnetClassificationIsolated
+
+	for _, target := range targets {
+		switch {
+		case strings.HasPrefix(target, "igw-"):
+			return ec2SubnetClassificationPublic
+		case strings.HasPrefix(target, "nat-"), strings.HasPrefix(target, "eigw-"):
+			classification = ec2SubnetClassificationPrivate
+		}
+	}
+
+	return classification
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestFlattenEc2SubnetRoutesToInternet(t *testing.T) {
+	subnet := func(id, vpcID string) *ec2.Subnet {
+		return &ec2.Subnet{SubnetId: aws.String(id), VpcId: aws.String(vpcID), AvailabilityZone: aws.String("us-east-1a")}
+	}
+
+	routeTables := []*ec2.RouteTable{
+		{
+			RouteTableId: aws.String("rtb-00000001"),
+			VpcId:        aws.String("vpc-00000001"),
+			Associations: []*ec2.RouteTableAssociation{{Main: aws.Bool(true)}},
+			Routes: []*ec2.Route{
+				{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local"), State: aws.String("active")},
+				{DestinationCidrBlock: aws.String("0.0.0.0/0"), NatGatewayId: aws.String("nat-00000001"), State: aws.String("active")},
+			},
+		},
+		{
+			RouteTableId: aws.String("rtb-00000002"),
+			VpcId:        aws.String("vpc-00000001"),
+			Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String("subnet-00000002")}},
+			Routes: []*ec2.Route{
+				{DestinationCidrBlock: aws.String("0.0.0.0/0"), GatewayId: aws.String("igw-00000001"), State: aws.String("active")},
+			},
+		},
+		{
+			RouteTableId: aws.String("rtb-00000003"),
+			VpcId:        aws.String("vpc-00000001"),
+			Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String("subnet-00000003")}, {SubnetId: aws.String("subnet-00000004")}},
+			Routes: []*ec2.Route{
+				{DestinationCidrBlock: aws.String("0.0.0.0/0"), GatewayId: aws.String("igw-00000001"), State: aws.String("blackhole")},
+				{DestinationIpv6CidrBlock: aws.String("::/0"), GatewayId: aws.String("igw-00000001"), State: aws.String("active")},
+			},
+		},
+		{
+			RouteTableId: aws.String("rtb-00000004"),
+			VpcId:        aws.String("vpc-00000002"),
+			Associations: []*ec2.RouteTableAssociation{{Main: aws.Bool(true)}},
+			Routes: []*ec2.Route{
+				{DestinationCidrBlock: aws.String("0.0.0.0/0"), TransitGatewayId: aws.String("tgw-00000001"), State: aws.String("active")},
+				{DestinationIpv6CidrBlock: aws.String("::/0"), EgressOnlyInternetGatewayId: aws.String("eigw-00000001"), State: aws.String("active")},
+			},
+		},
+		{
+			RouteTableId: aws.String("rtb-00000005"),
+			VpcId:        aws.String("vpc-00000002"),
+			Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String("subnet-00000006")}},
+			Routes: []*ec2.Route{
+				{DestinationCidrBlock: aws.String("0.0.0.0/0"), TransitGatewayId: aws.String("tgw-00000001"), State: aws.String("active")},
+			},
+		},
+	}
+
+	subnets := []*ec2.Subnet{
+		subnet("subnet-00000006", "vpc-00000002"),
+		subnet("subnet-00000001", "vpc-00000001"),
+		subnet("subnet-00000002", "vpc-00000001"),
+		subnet("subnet-00000003", "vpc-00000001"),
+		subnet("subnet-00000005", "vpc-00000002"),
+		subnet("subnet-00000007", "vpc-00000003"),
+	}
+
+	result := func(subnetID, vpcID, routeTableID string, main bool, classification, ipv4Target, ipv6Target string) map[string]interface{} {
+		return map[string]interface{}{
+			"subnet_id":                 subnetID,
+			"vpc_id":                    vpcID,
+			"availability_zone":         "us-east-1a",
+			"route_table_id":            routeTableID,
+			"main_route_table":          main,
+			"classification":            classification,
+			"ipv4_default_route_target": ipv4Target,
+			"ipv6_default_route_target": ipv6Target,
+		}
+	}
+
+	expected := []map[string]interface{}{
+		// Without an explicit association, the main Route Table of the VPC applies.
+		result("subnet-00000001", "vpc-00000001", "rtb-00000001", true, "private", "nat-00000001", ""),
+		result("subnet-00000002", "vpc-00000001", "rtb-00000002", false, "public", "igw-00000001", ""),
+		// Blackhole routes are ignored, but an IPv6 default route to an Internet Gateway makes a Subnet public.
+		result("subnet-00000003", "vpc-00000001", "rtb-00000003", false, "public", "", "igw-00000001"),
+		result("subnet-00000005", "vpc-00000002", "rtb-00000004", true, "private", "tgw-00000001", "eigw-00000001"),
+		result("subnet-00000006", "vpc-00000002", "rtb-00000005", false, "isolated", "tgw-00000001", ""),
+		result("subnet-00000007", "vpc-00000003", "", false, "isolated", "", ""),
+	}
+
+	if got := flattenEc2SubnetRoutesToInternet(subnets, routeTables); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
@@ -0,0 +1,363 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	// sgRuleSetSelfReference replaces the ID of the Security Group itself in the canonical form of its rules, so that
+	// Security Groups referencing themselves have identical rule sets.
+	sgRuleSetSelfReference = "self"
+
+	// sgNetworkInterfaceGroupIDChunkSize is the maximum number of group IDs passed in a single "group-id" filter
+	// when looking up the network interfaces referencing Security Groups.
+	sgNetworkInterfaceGroupIDChunkSize = 200
+)
+
+// sgRuleProtocolNames maps the protocol numbers which may be used in Security Group Rules to the names the EC2 API
+// reports them with otherwise.
+var sgRuleProtocolNames = map[string]string{
+	"1":  "icmp",
+	"6":  "tcp",
+	"17": "udp",
+	"58": "icmpv6",
+}
+
+func dataSourceAwsUtilsEc2SgConsolidationCandidates() *schema.Resource {
+	return &schema.Resource{
+		Description: `Groups the Security Groups matching the given filters which have identical rule sets, as candidates for
+consolidation into a single Security Group.
+
+The rules of each Security Group are reduced to a canonical form ignoring their order, IDs and descriptions, in which
+references to the Security Group itself are replaced by ` + "`self`" + `, and hashed. Security Groups of the same VPC with
+the same rule set hash form a candidate group. The number of network interfaces using each Security Group is reported
+alongside it, and the most used Security Group of each candidate group is suggested as the one to keep. This data source
+only reports candidates and never modifies any Security Group.`,
+		ReadContext:   dataSourceAwsUtilsEc2SgConsolidationCandidatesRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":                       ec2IDsSchema(ec2.ResourceTypeSecurityGroup),
+			"ids_strict":                ec2IDsStrictSchema(),
+			"arns":                      ec2ARNsSchema(ec2.ResourceTypeSecurityGroup),
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"tags":                      tagsSchema(),
+			"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":              ec2ExcludeTagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"debug":                     debugSchema(),
+			"raw_response_json":         rawResponseJSONSchema(),
+			"applied_filters":           ec2AppliedFiltersSchema(),
+			"resolved_filters":          ec2ResolvedFiltersSchema(),
+			"filters_object":            ec2FiltersObjectSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"candidates": {
+				Description: "The groups of Security Groups with identical rule sets, ordered by VPC ID and rule set hash.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"rule_set_hash": {
+							Description: "The SHA-256 hash of the canonical rule set of the Security Groups.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"vpc_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"rule_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"suggested_group_id": {
+							Description: "The ID of the Security Group used by the most network interfaces, to keep.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"security_groups": {
+							Description: "The Security Groups, ordered by decreasing network interface count and ID.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"group_id": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"group_name": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"network_interface_count": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2SgConsolidationCandidatesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &ec2.DescribeSecurityGroupsInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeSecurityGroup)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.GroupIds = ids
+	input.Filters = filters
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var groups []*ec2.SecurityGroup
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		groups, err = finder.SecurityGroups(conn, input, maxResultsCap(d, meta))
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Security Groups: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	kept := groups[:0]
+	for _, group := range groups {
+		if !excluded(group.Tags) && tagsMatched(group.Tags) {
+			kept = append(kept, group)
+		}
+	}
+	groups = kept
+
+	groupIDs := make([]string, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, aws.StringValue(group.GroupId))
+	}
+
+	rules, err := finder.SecurityGroupRulesForGroups(conn, groupIDs)
+	if err != nil {
+		return diag.Errorf("error reading EC2 Security Group Rules: %s", err)
+	}
+
+	rulesByGroup := make(map[string][]*ec2.SecurityGroupRule, len(groups))
+	for _, rule := range rules {
+		groupID := aws.StringValue(rule.GroupId)
+		rulesByGroup[groupID] = append(rulesByGroup[groupID], rule)
+	}
+
+	networkInterfaceCounts, err := sgNetworkInterfaceCounts(conn, groupIDs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"SecurityGroups": groups}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("candidates", sgConsolidationCandidates(groups, rulesByGroup, networkInterfaceCounts)); err != nil {
+		return diag.Errorf("error setting candidates: %s", err)
+	}
+
+	return warnings
+}
+
+// sgNetworkInterfaceCounts returns the number of network interfaces using each of the given Security Groups.
+func sgNetworkInterfaceCounts(conn *ec2.EC2, groupIDs []string) (map[string]int, error) {
+	selected := make(map[string]bool, len(groupIDs))
+	for _, groupID := range groupIDs {
+		selected[groupID] = true
+	}
+
+	counts := make(map[string]int, len(groupIDs))
+	seen := make(map[string]bool)
+
+	for i := 0; i < len(groupIDs); i += sgNetworkInterfaceGroupIDChunkSize {
+		j := i + sgNetworkInterfaceGroupIDChunkSize
+		if j > len(groupIDs) {
+			j = len(groupIDs)
+		}
+
+		networkInterfaces, err := finder.NetworkInterfaces(conn, &ec2.DescribeNetworkInterfacesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("group-id"),
+					Values: aws.StringSlice(groupIDs[i:j]),
+				},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 Network Interfaces: %w", err)
+		}
+
+		for _, networkInterface := range networkInterfaces {
+			// A network interface using Security Groups of several chunks is returned for each of them.
+			networkInterfaceID := aws.StringValue(networkInterface.NetworkInterfaceId)
+			if seen[networkInterfaceID] {
+				continue
+			}
+			seen[networkInterfaceID] = true
+
+			for _, group := range networkInterface.Groups {
+				if groupID := aws.StringValue(group.GroupId); selected[groupID] {
+					counts[groupID]++
+				}
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// sgConsolidationCandidates returns the flattened "candidates" of the given Security Groups, grouping those of the
+// same VPC with identical rule sets.
+func sgConsolidationCandidates(groups []*ec2.SecurityGroup, rulesByGroup map[string][]*ec2.SecurityGroupRule, networkInterfaceCounts map[string]int) []map[string]interface{} {
+	type candidateKey struct {
+		vpcID, hash string
+	}
+
+	members := make(map[candidateKey][]*ec2.SecurityGroup)
+	for _, group := range groups {
+		groupID := aws.StringValue(group.GroupId)
+		key := candidateKey{
+			vpcID: aws.StringValue(group.VpcId),
+			hash:  sgRuleSetHash(groupID, rulesByGroup[groupID]),
+		}
+		members[key] = append(members[key], group)
+	}
+
+	keys := make([]candidateKey, 0, len(members))
+	for key, groups := range members {
+		if len(groups) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].vpcID != keys[j].vpcID {
+			return keys[i].vpcID < keys[j].vpcID
+		}
+		return keys[i].hash < keys[j].hash
+	})
+
+	candidates := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		groups := members[key]
+		sort.Slice(groups, func(i, j int) bool {
+			ci, cj := networkInterfaceCounts[aws.StringValue(groups[i].GroupId)], networkInterfaceCounts[aws.StringValue(groups[j].GroupId)]
+			if ci != cj {
+				return ci > cj
+			}
+			return aws.StringValue(groups[i].GroupId) < aws.StringValue(groups[j].GroupId)
+		})
+
+		securityGroups := make([]map[string]interface{}, 0, len(groups))
+		for _, group := range groups {
+			groupID := aws.StringValue(group.GroupId)
+			securityGroups = append(securityGroups, map[string]interface{}{
+				"group_id":                groupID,
+				"group_name":              aws.StringValue(group.GroupName),
+				"network_interface_count": networkInterfaceCounts[groupID],
+			})
+		}
+
+		candidates = append(candidates, map[string]interface{}{
+			"rule_set_hash":      key.hash,
+			"vpc_id":             key.vpcID,
+			"rule_count":         len(rulesByGroup[aws.StringValue(groups[0].GroupId)]),
+			"suggested_group_id": aws.StringValue(groups[0].GroupId),
+			"security_groups":    securityGroups,
+		})
+	}
+
+	return candidates
+}
+
+// sgRuleSetHash returns the hex-encoded SHA-256 hash of the canonical form of the given rules of the given Security
+// Group, which is independent of the order of the rules, their IDs and descriptions.
+func sgRuleSetHash(groupID string, rules []*ec2.SecurityGroupRule) string {
+	canonical := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		canonical = append(canonical, sgRuleCanonicalForm(groupID, rule))
+	}
+	sort.Strings(canonical)
+
+	hash := sha256.Sum256([]byte(strings.Join(canonical, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+// sgRuleCanonicalForm returns the canonical form of the given rule of the given Security Group: its direction,
+// protocol, port range and source or destination, with references to the Security Group itself replaced by "self".
+func sgRuleCanonicalForm(groupID string, rule *ec2.SecurityGroupRule) string {
+	direction := "ingress"
+	if aws.BoolValue(rule.IsEgress) {
+		direction = "egress"
+	}
+
+	protocol := strings.ToLower(aws.StringValue(rule.IpProtocol))
+	if name, ok := sgRuleProtocolNames[protocol]; ok {
+		protocol = name
+	}
+
+	var referencedGroupID string
+	if rule.ReferencedGroupInfo != nil {
+		referencedGroupID = aws.StringValue(rule.ReferencedGroupInfo.GroupId)
+		if referencedGroupID == groupID {
+			referencedGroupID = sgRuleSetSelfReference
+		}
+	}
+
+	return strings.Join([]string{
+		direction,
+		protocol,
+		fmt.Sprintf("%d", aws.Int64Value(rule.FromPort)),
+		fmt.Sprintf("%d", aws.Int64Value(rule.ToPort)),
+		aws.StringValue(rule.CidrIpv4),
+		aws.StringValue(rule.CidrIpv6),
+		aws.StringValue(rule.PrefixListId),
+		referencedGroupID,
+	}, "|")
+}
@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestSgRuleSetHash(t *testing.T) {
+	https := &ec2.SecurityGroupRule{
+		SecurityGroupRuleId: aws.String("sgr-01234567"),
+		IpProtocol:          aws.String("tcp"),
+		FromPort:            aws.Int64(443),
+		ToPort:              aws.Int64(443),
+		CidrIpv4:            aws.String("10.0.0.0/8"),
+		Description:         aws.String("HTTPS"),
+	}
+	egress := &ec2.SecurityGroupRule{
+		SecurityGroupRuleId: aws.String("sgr-89abcdef"),
+		IsEgress:            aws.Bool(true),
+		IpProtocol:          aws.String("-1"),
+		FromPort:            aws.Int64(-1),
+		ToPort:              aws.Int64(-1),
+		CidrIpv4:            aws.String("0.0.0.0/0"),
+	}
+
+	expected := sgRuleSetHash("sg-01234567", []*ec2.SecurityGroupRule{https, egress})
+
+	testCases := []struct {
+		Name     string
+		GroupID  string
+		Rules    []*ec2.SecurityGroupRule
+		Expected bool
+	}{
+		{
+			Name:     "different order",
+			GroupID:  "sg-89abcdef",
+			Rules:    []*ec2.SecurityGroupRule{egress, https},
+			Expected: true,
+		},
+		{
+			Name:    "different IDs, descriptions and protocol number",
+			GroupID: "sg-89abcdef",
+			Rules: []*ec2.SecurityGroupRule{
+				egress,
+				{
+					SecurityGroupRuleId: aws.String("sgr-00000000"),
+					IpProtocol:          aws.String("6"),
+					FromPort:            aws.Int64(443),
+					ToPort:              aws.Int64(443),
+					CidrIpv4:            aws.String("10.0.0.0/8"),
+					Description:         aws.String("TLS"),
+				},
+			},
+			Expected: true,
+		},
+		{
+			Name:    "different port",
+			GroupID: "sg-89abcdef",
+			Rules: []*ec2.SecurityGroupRule{
+				egress,
+				{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int64(8443),
+					ToPort:     aws.Int64(8443),
+					CidrIpv4:   aws.String("10.0.0.0/8"),
+				},
+			},
+			Expected: false,
+		},
+		{
+			Name:     "missing rule",
+			GroupID:  "sg-89abcdef",
+			Rules:    []*ec2.SecurityGroupRule{https},
+			Expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := sgRuleSetHash(testCase.GroupID, testCase.Rules) == expected; got != testCase.Expected {
+				t.Errorf("got equal hashes %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestSgRuleSetHashSelfReference(t *testing.T) {
+	selfRule := func(groupID string) *ec2.SecurityGroupRule {
+		return &ec2.SecurityGroupRule{
+			IpProtocol:          aws.String("-1"),
+			FromPort:            aws.Int64(-1),
+			ToPort:              aws.Int64(-1),
+			ReferencedGroupInfo: &ec2.ReferencedSecurityGroup{GroupId: aws.String(groupID)},
+		}
+	}
+
+	if sgRuleSetHash("sg-01234567", []*ec2.SecurityGroupRule{selfRule("sg-01234567")}) != sgRuleSetHash("sg-89abcdef", []*ec2.SecurityGroupRule{selfRule("sg-89abcdef")}) {
+		t.Errorf("expected self references to have equal hashes")
+	}
+
+	if sgRuleSetHash("sg-01234567", []*ec2.SecurityGroupRule{selfRule("sg-00000000")}) == sgRuleSetHash("sg-89abcdef", []*ec2.SecurityGroupRule{selfRule("sg-89abcdef")}) {
+		t.Errorf("expected a reference to another group and a self reference to have different hashes")
+	}
+}
+
+func TestSgConsolidationCandidates(t *testing.T) {
+	rule := &ec2.SecurityGroupRule{
+		IpProtocol: aws.String("tcp"),
+		FromPort:   aws.Int64(22),
+		ToPort:     aws.Int64(22),
+		CidrIpv4:   aws.String("10.0.0.0/8"),
+	}
+
+	groups := []*ec2.SecurityGroup{
+		{GroupId: aws.String("sg-00000001"), GroupName: aws.String("ssh-1"), VpcId: aws.String("vpc-01234567")},
+		{GroupId: aws.String("sg-00000002"), GroupName: aws.String("ssh-2"), VpcId: aws.String("vpc-01234567")},
+		{GroupId: aws.String("sg-00000003"), GroupName: aws.String("ssh-3"), VpcId: aws.String("vpc-89abcdef")},
+		{GroupId: aws.String("sg-00000004"), GroupName: aws.String("other"), VpcId: aws.String("vpc-01234567")},
+	}
+	rulesByGroup := map[string][]*ec2.SecurityGroupRule{
+		"sg-00000001": {rule},
+		"sg-00000002": {rule},
+		"sg-00000003": {rule},
+		"sg-00000004": {rule, {IpProtocol: aws.String("tcp"), FromPort: aws.Int64(80), ToPort: aws.Int64(80), CidrIpv4: aws.String("10.0.0.0/8")}},
+	}
+	networkInterfaceCounts := map[string]int{
+		"sg-00000002": 3,
+	}
+
+	expected := []map[string]interface{}{
+		{
+			"rule_set_hash":      sgRuleSetHash("sg-00000001", []*ec2.SecurityGroupRule{rule}),
+			"vpc_id":             "vpc-01234567",
+			"rule_count":         1,
+			"suggested_group_id": "sg-00000002",
+			"security_groups": []map[string]interface{}{
+				{
+					"group_id":                "sg-00000002",
+					"group_name":              "ssh-2",
+					"network_interface_count": 3,
+				},
+				{
+					"group_id":                "sg-00000001",
+					"group_name":              "ssh-1",
+					"network_interface_count": 0,
+				},
+			},
+		},
+	}
+
+	if got := sgConsolidationCandidates(groups, rulesByGroup, networkInterfaceCounts); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
@@ -0,0 +1,230 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfnet "github.com/cloudposse/terraform-provider-awsutils/internal/net"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAwsUtilsEc2SgRulesDiffBetweenGroups() *schema.Resource {
+	return &schema.Resource{
+		Description: `Compares the rules of two Security Groups, listing the rules of each which the other does not have.
+
+Rules are compared in the canonical form used by ` + "`awsutils_ec2_sg_consolidation_candidates`" + `, ignoring their
+order, IDs and descriptions: two rules are the same when they have the same direction, protocol, port range and source
+or destination. CIDR blocks are compared once canonicalized, so that ` + "`10.0.0.1/24`" + ` and ` + "`10.0.0.0/24`" + ` are
+the same source, while a CIDR block, a prefix list and a referenced Security Group are never the same source, even if
+they cover the same addresses. References of each Security Group to itself are compared as ` + "`self`" + `, so that two
+Security Groups allowing traffic from themselves have the same rule. This data source never modifies any Security
+Group.`,
+		Read:          dataSourceAwsUtilsEc2SgRulesDiffBetweenGroupsRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"security_group_id": {
+				Description:  "The ID of the first Security Group.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateEc2SgRulesDiffGroupID,
+			},
+			"other_security_group_id": {
+				Description:  "The ID of the Security Group to compare the first one with.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateEc2SgRulesDiffGroupID,
+			},
+			"only_in_security_group": {
+				Description: "The rules of `security_group_id` which `other_security_group_id` does not have, ordered by rule ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        ec2SgRulesDiffRuleSchema(),
+			},
+			"only_in_other_security_group": {
+				Description: "The rules of `other_security_group_id` which `security_group_id` does not have, ordered by rule ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        ec2SgRulesDiffRuleSchema(),
+			},
+			"identical": {
+				Description: "Whether the two Security Groups have the same rules.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// validateEc2SgRulesDiffGroupID validates the ID of one of the compared Security Groups.
+func validateEc2SgRulesDiffGroupID(v interface{}, k string) (ws []string, errors []error) {
+	if err := tfec2.ValidateResourceID(ec2.ResourceTypeSecurityGroup, v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%s: %w", k, err))
+	}
+	return
+}
+
+// ec2SgRulesDiffRuleSchema returns the schema of the rules listed by the awsutils_ec2_sg_rules_diff_between_groups
+// data source.
+func ec2SgRulesDiffRuleSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"security_group_rule_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"is_egress": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"ip_protocol": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"from_port": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"to_port": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"cidr_ipv4": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"cidr_ipv6": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"prefix_list_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"referenced_security_group_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2SgRulesDiffBetweenGroupsRead(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*AWSClient).ec2conn
+	groupID := d.Get("security_group_id").(string)
+	otherGroupID := d.Get("other_security_group_id").(string)
+
+	// The Security Groups are looked up first, as reading the rules of a missing Security Group is not an error.
+	groupIDs := []string{groupID}
+	groupIDs = appendUniqueString(groupIDs, otherGroupID)
+
+	if _, err := finder.SecurityGroups(conn, &ec2.DescribeSecurityGroupsInput{GroupIds: aws.StringSlice(groupIDs)}, 0); err != nil {
+		return fmt.Errorf("error reading EC2 Security Groups: %w", err)
+	}
+
+	rules, err := finder.SecurityGroupRulesForGroups(conn, groupIDs)
+	if err != nil {
+		return fmt.Errorf("error reading EC2 Security Group Rules: %w", err)
+	}
+
+	rulesByGroupID := make(map[string][]*ec2.SecurityGroupRule, len(groupIDs))
+	for _, rule := range rules {
+		rulesByGroupID[aws.StringValue(rule.GroupId)] = append(rulesByGroupID[aws.StringValue(rule.GroupId)], rule)
+	}
+
+	onlyInGroup, onlyInOther := ec2SgRulesDiff(groupID, rulesByGroupID[groupID], otherGroupID, rulesByGroupID[otherGroupID])
+
+	d.SetId(meta.(*AWSClient).region)
+
+	if err := d.Set("only_in_security_group", flattenEc2SgRulesDiffRules(onlyInGroup)); err != nil {
+		return fmt.Errorf("error setting only_in_security_group: %w", err)
+	}
+
+	if err := d.Set("only_in_other_security_group", flattenEc2SgRulesDiffRules(onlyInOther)); err != nil {
+		return fmt.Errorf("error setting only_in_other_security_group: %w", err)
+	}
+
+	if err := d.Set("identical", len(onlyInGroup) == 0 && len(onlyInOther) == 0); err != nil {
+		return fmt.Errorf("error setting identical: %w", err)
+	}
+
+	return nil
+}
+
+// ec2SgRulesDiff returns the given rules of the Security Group with the given ID whose canonical form is not among
+// those of the given rules of the other Security Group, and conversely, each ordered by rule ID.
+func ec2SgRulesDiff(groupID string, rules []*ec2.SecurityGroupRule, otherGroupID string, otherRules []*ec2.SecurityGroupRule) ([]*ec2.SecurityGroupRule, []*ec2.SecurityGroupRule) {
+	return ec2SgRulesMissingFrom(groupID, rules, otherGroupID, otherRules), ec2SgRulesMissingFrom(otherGroupID, otherRules, groupID, rules)
+}
+
+// ec2SgRulesMissingFrom returns the given rules of the Security Group with the given ID whose canonical form is not
+// among those of the given rules of the other Security Group, ordered by rule ID.
+func ec2SgRulesMissingFrom(groupID string, rules []*ec2.SecurityGroupRule, otherGroupID string, otherRules []*ec2.SecurityGroupRule) []*ec2.SecurityGroupRule {
+	existing := make(map[string]bool, len(otherRules))
+	for _, rule := range otherRules {
+		existing[ec2SgRulesDiffCanonicalForm(otherGroupID, rule)] = true
+	}
+
+	missing := make([]*ec2.SecurityGroupRule, 0)
+	for _, rule := range rules {
+		if !existing[ec2SgRulesDiffCanonicalForm(groupID, rule)] {
+			missing = append(missing, rule)
+		}
+	}
+
+	sort.Slice(missing, func(i, j int) bool {
+		return aws.StringValue(missing[i].SecurityGroupRuleId) < aws.StringValue(missing[j].SecurityGroupRuleId)
+	})
+
+	return missing
+}
+
+// ec2SgRulesDiffCanonicalForm returns the canonical form of the given rule of the given Security Group, as returned
+// by sgRuleCanonicalForm once its CIDR blocks are canonicalized.
+func ec2SgRulesDiffCanonicalForm(groupID string, rule *ec2.SecurityGroupRule) string {
+	canonical := *rule
+
+	if cidr := aws.StringValue(rule.CidrIpv4); cidr != "" {
+		canonical.CidrIpv4 = aws.String(tfnet.CanonicalCIDRBlock(cidr))
+	}
+	if cidr := aws.StringValue(rule.CidrIpv6); cidr != "" {
+		canonical.CidrIpv6 = aws.String(tfnet.CanonicalCIDRBlock(cidr))
+	}
+
+	return sgRuleCanonicalForm(groupID, &canonical)
+}
+
+// flattenEc2SgRulesDiffRules returns the flattened listed rules of the awsutils_ec2_sg_rules_diff_between_groups data
+// source.
+func flattenEc2SgRulesDiffRules(rules []*ec2.SecurityGroupRule) []interface{} {
+	result := make([]interface{}, 0, len(rules))
+
+	for _, rule := range rules {
+		m := map[string]interface{}{
+			"security_group_rule_id": aws.StringValue(rule.SecurityGroupRuleId),
+			"is_egress":              aws.BoolValue(rule.IsEgress),
+			"ip_protocol":            aws.StringValue(rule.IpProtocol),
+			"from_port":              int(aws.Int64Value(rule.FromPort)),
+			"to_port":                int(aws.Int64Value(rule.ToPort)),
+			"cidr_ipv4":              aws.StringValue(rule.CidrIpv4),
+			"cidr_ipv6":              aws.StringValue(rule.CidrIpv6),
+			"prefix_list_id":         aws.StringValue(rule.PrefixListId),
+			"description":            aws.StringValue(rule.Description),
+		}
+
+		if rule.ReferencedGroupInfo != nil {
+			m["referenced_security_group_id"] = aws.StringValue(rule.ReferencedGroupInfo.GroupId)
+		}
+
+		result = append(result, m)
+	}
+
+	return result
+}
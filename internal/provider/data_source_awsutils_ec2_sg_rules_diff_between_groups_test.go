@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEc2SgRulesDiff(t *testing.T) {
+	cidrRule := func(id, groupID, cidr string) *ec2.SecurityGroupRule {
+		return &ec2.SecurityGroupRule{
+			SecurityGroupRuleId: aws.String(id),
+			GroupId:             aws.String(groupID),
+			IsEgress:            aws.Bool(false),
+			IpProtocol:          aws.String("tcp"),
+			FromPort:            aws.Int64(443),
+			ToPort:              aws.Int64(443),
+			CidrIpv4:            aws.String(cidr),
+		}
+	}
+	groupRule := func(id, groupID, referencedGroupID string) *ec2.SecurityGroupRule {
+		return &ec2.SecurityGroupRule{
+			SecurityGroupRuleId: aws.String(id),
+			GroupId:             aws.String(groupID),
+			IsEgress:            aws.Bool(false),
+			IpProtocol:          aws.String("tcp"),
+			FromPort:            aws.Int64(443),
+			ToPort:              aws.Int64(443),
+			ReferencedGroupInfo: &ec2.ReferencedSecurityGroup{GroupId: aws.String(referencedGroupID)},
+		}
+	}
+	egressRule := func(id, groupID string) *ec2.SecurityGroupRule {
+		return &ec2.SecurityGroupRule{
+			SecurityGroupRuleId: aws.String(id),
+			GroupId:             aws.String(groupID),
+			IsEgress:            aws.Bool(true),
+			IpProtocol:          aws.String("-1"),
+			FromPort:            aws.Int64(-1),
+			ToPort:              aws.Int64(-1),
+			CidrIpv4:            aws.String("0.0.0.0/0"),
+			Description:         aws.String(groupID),
+		}
+	}
+
+	testCases := []struct {
+		Name          string
+		Rules         []*ec2.SecurityGroupRule
+		OtherRules    []*ec2.SecurityGroupRule
+		ExpectedOnly  []string
+		ExpectedOther []string
+	}{
+		{
+			Name:       "identical in another order with other descriptions",
+			Rules:      []*ec2.SecurityGroupRule{egressRule("sgr-a1", "sg-a"), cidrRule("sgr-a2", "sg-a", "10.0.0.0/16")},
+			OtherRules: []*ec2.SecurityGroupRule{cidrRule("sgr-b1", "sg-b", "10.0.0.0/16"), egressRule("sgr-b2", "sg-b")},
+		},
+		{
+			Name:       "non-canonical CIDR block",
+			Rules:      []*ec2.SecurityGroupRule{cidrRule("sgr-a1", "sg-a", "10.0.0.1/16")},
+			OtherRules: []*ec2.SecurityGroupRule{cidrRule("sgr-b1", "sg-b", "10.0.0.0/16")},
+		},
+		{
+			Name:          "other CIDR block",
+			Rules:         []*ec2.SecurityGroupRule{cidrRule("sgr-a1", "sg-a", "10.0.0.0/16")},
+			OtherRules:    []*ec2.SecurityGroupRule{cidrRule("sgr-b1", "sg-b", "10.1.0.0/16")},
+			ExpectedOnly:  []string{"sgr-a1"},
+			ExpectedOther: []string{"sgr-b1"},
+		},
+		{
+			Name:          "CIDR block and referenced group",
+			Rules:         []*ec2.SecurityGroupRule{cidrRule("sgr-a1", "sg-a", "10.0.0.0/16"), groupRule("sgr-a2", "sg-a", "sg-c")},
+			OtherRules:    []*ec2.SecurityGroupRule{groupRule("sgr-b1", "sg-b", "sg-c")},
+			ExpectedOnly:  []string{"sgr-a1"},
+			ExpectedOther: []string{},
+		},
+		{
+			Name:       "self references",
+			Rules:      []*ec2.SecurityGroupRule{groupRule("sgr-a1", "sg-a", "sg-a")},
+			OtherRules: []*ec2.SecurityGroupRule{groupRule("sgr-b1", "sg-b", "sg-b")},
+		},
+		{
+			Name:          "other referenced groups",
+			Rules:         []*ec2.SecurityGroupRule{groupRule("sgr-a2", "sg-a", "sg-c"), groupRule("sgr-a1", "sg-a", "sg-d")},
+			OtherRules:    []*ec2.SecurityGroupRule{groupRule("sgr-b1", "sg-b", "sg-e")},
+			ExpectedOnly:  []string{"sgr-a1", "sgr-a2"},
+			ExpectedOther: []string{"sgr-b1"},
+		},
+	}
+
+	ruleIDs := func(rules []*ec2.SecurityGroupRule) []string {
+		ids := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			ids = append(ids, aws.StringValue(rule.SecurityGroupRuleId))
+		}
+		return ids
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			onlyInGroup, onlyInOther := ec2SgRulesDiff("sg-a", testCase.Rules, "sg-b", testCase.OtherRules)
+
+			expectedOnly, expectedOther := testCase.ExpectedOnly, testCase.ExpectedOther
+			if expectedOnly == nil {
+				expectedOnly = []string{}
+			}
+			if expectedOther == nil {
+				expectedOther = []string{}
+			}
+
+			if got := ruleIDs(onlyInGroup); !reflect.DeepEqual(got, expectedOnly) {
+				t.Errorf("got only in security group %v, expected %v", got, expectedOnly)
+			}
+			if got := ruleIDs(onlyInOther); !reflect.DeepEqual(got, expectedOther) {
+				t.Errorf("got only in other security group %v, expected %v", got, expectedOther)
+			}
+		})
+	}
+}
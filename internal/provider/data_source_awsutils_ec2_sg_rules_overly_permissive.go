@@ -0,0 +1,361 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfnet "github.com/cloudposse/terraform-provider-awsutils/internal/net"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	// sgRuleRiskOpenIpv4 flags rules whose source (or destination, for egress) is 0.0.0.0/0.
+	sgRuleRiskOpenIpv4 = "open_ipv4"
+	// sgRuleRiskOpenIpv6 flags rules whose source (or destination, for egress) is ::/0.
+	sgRuleRiskOpenIpv6 = "open_ipv6"
+	// sgRuleRiskOpenPrefixList flags rules referencing a prefix list containing 0.0.0.0/0 or ::/0.
+	sgRuleRiskOpenPrefixList = "open_prefix_list"
+	// sgRuleRiskAllPorts flags TCP or UDP rules covering the full 0-65535 port range.
+	sgRuleRiskAllPorts = "all_ports"
+	// sgRuleRiskAllProtocols flags rules allowing all protocols (and therefore all ports).
+	sgRuleRiskAllProtocols = "all_protocols"
+)
+
+const (
+	openIpv4CidrBlock = "0.0.0.0/0"
+	openIpv6CidrBlock = "::/0"
+)
+
+// ec2SgRulesOverlyPermissiveFingerprintAttributes are the attributes of the "rules" hashed into their fingerprint.
+var ec2SgRulesOverlyPermissiveFingerprintAttributes = []string{"security_group_id", "security_group_rule_id", "is_egress", "ip_protocol", "from_port", "to_port", "cidr_ipv4", "cidr_ipv6", "prefix_list_id", "referenced_security_group_id", "description"}
+
+func dataSourceAwsUtilsEc2SgRulesOverlyPermissive() *schema.Resource {
+	return &schema.Resource{
+		Description: `Reports the overly permissive rules of the Security Groups matching the given filters.
+
+Each returned rule is flagged with one or more of the following risk categories:
+
+- ` + "`open_ipv4`" + `: the rule allows traffic from (or to, for egress rules) ` + "`0.0.0.0/0`" + `.
+- ` + "`open_ipv6`" + `: the rule allows traffic from (or to, for egress rules) ` + "`::/0`" + `.
+- ` + "`open_prefix_list`" + `: the rule references a managed prefix list containing ` + "`0.0.0.0/0`" + ` or ` + "`::/0`" + `.
+- ` + "`all_ports`" + `: the rule is a TCP or UDP rule covering the full ` + "`0-65535`" + ` port range.
+- ` + "`all_protocols`" + `: the rule allows all protocols, which implies all ports.
+
+Rules referencing other Security Groups are never flagged as open to the world, but may still be flagged as
+` + "`all_ports`" + ` or ` + "`all_protocols`" + `. Only ingress rules are evaluated unless ` + "`include_egress`" + ` is set.`,
+		ReadContext:   dataSourceAwsUtilsEc2SgRulesOverlyPermissiveRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":                       ec2IDsSchema(ec2.ResourceTypeSecurityGroup),
+			"ids_strict":                ec2IDsStrictSchema(),
+			"arns":                      ec2ARNsSchema(ec2.ResourceTypeSecurityGroup),
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"tags":                      tagsSchema(),
+			"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":              ec2ExcludeTagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"debug":                     debugSchema(),
+			"raw_response_json":         rawResponseJSONSchema(),
+			"applied_filters":           ec2AppliedFiltersSchema(),
+			"resolved_filters":          ec2ResolvedFiltersSchema(),
+			"filters_object":            ec2FiltersObjectSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"owner_ids":                 ec2OwnerIDsSchema(),
+			"include_egress": {
+				Description: "Whether egress rules should be evaluated in addition to ingress rules.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"rules": {
+				Description: "The overly permissive rules of the matching Security Groups.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"security_group_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"security_group_rule_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"is_egress": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"ip_protocol": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"from_port": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"to_port": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"cidr_ipv4": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"cidr_ipv6": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"prefix_list_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"referenced_security_group_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"risk_categories": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"import_ids": {
+							Description: "The IDs to use with `terraform import` for this rule in the upstream AWS provider, keyed by resource type.",
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"fingerprint": fingerprintSchema(ec2SgRulesOverlyPermissiveFingerprintAttributes),
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2SgRulesOverlyPermissiveRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	includeEgress := d.Get("include_egress").(bool)
+
+	input := &ec2.DescribeSecurityGroupsInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeSecurityGroup)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.GroupIds = ids
+	input.Filters = append(input.Filters, filters...)
+
+	_, ownerFilters, err := buildEC2OwnerSelection(ec2.ResourceTypeSecurityGroup, ExpandStringSliceofPointers(ExpandStringSet(d.Get("owner_ids").(*schema.Set))), meta.(*AWSClient).accountid)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.Filters = append(input.Filters, ownerFilters...)
+
+	if len(input.Filters) == 0 {
+		input.Filters = nil
+	}
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var groups []*ec2.SecurityGroup
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		groups, err = finder.SecurityGroups(conn, input, maxResultsCap(d, meta))
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Security Groups: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	kept := groups[:0]
+	for _, group := range groups {
+		if !excluded(group.Tags) && tagsMatched(group.Tags) {
+			kept = append(kept, group)
+		}
+	}
+	groups = kept
+
+	groupIDs := make([]string, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, aws.StringValue(group.GroupId))
+	}
+
+	rules, err := finder.SecurityGroupRulesForGroups(conn, groupIDs)
+	if err != nil {
+		return diag.Errorf("error reading EC2 Security Group Rules: %s", err)
+	}
+
+	openPrefixLists := make(map[string]bool)
+	var flagged []map[string]interface{}
+
+	for _, rule := range rules {
+		if aws.BoolValue(rule.IsEgress) && !includeEgress {
+			continue
+		}
+
+		if prefixListID := aws.StringValue(rule.PrefixListId); prefixListID != "" {
+			if _, ok := openPrefixLists[prefixListID]; !ok {
+				open, err := managedPrefixListIsOpen(conn, prefixListID)
+				if err != nil {
+					return diag.Errorf("error reading EC2 Managed Prefix List (%s) entries: %s", prefixListID, err)
+				}
+				openPrefixLists[prefixListID] = open
+			}
+		}
+
+		categories := sgRuleRiskCategories(rule, openPrefixLists[aws.StringValue(rule.PrefixListId)])
+		if len(categories) == 0 {
+			continue
+		}
+
+		m := map[string]interface{}{
+			"security_group_id":      aws.StringValue(rule.GroupId),
+			"security_group_rule_id": aws.StringValue(rule.SecurityGroupRuleId),
+			"is_egress":              aws.BoolValue(rule.IsEgress),
+			"ip_protocol":            aws.StringValue(rule.IpProtocol),
+			"from_port":              int(aws.Int64Value(rule.FromPort)),
+			"to_port":                int(aws.Int64Value(rule.ToPort)),
+			"cidr_ipv4":              aws.StringValue(rule.CidrIpv4),
+			"cidr_ipv6":              aws.StringValue(rule.CidrIpv6),
+			"prefix_list_id":         aws.StringValue(rule.PrefixListId),
+			"description":            aws.StringValue(rule.Description),
+			"risk_categories":        categories,
+			"import_ids":             sgRuleImportIDs(rule),
+		}
+
+		if rule.ReferencedGroupInfo != nil {
+			m["referenced_security_group_id"] = aws.StringValue(rule.ReferencedGroupInfo.GroupId)
+		}
+
+		flagged = append(flagged, m)
+	}
+
+	sort.SliceStable(flagged, func(i, j int) bool {
+		if flagged[i]["security_group_id"].(string) != flagged[j]["security_group_id"].(string) {
+			return flagged[i]["security_group_id"].(string) < flagged[j]["security_group_id"].(string)
+		}
+		return flagged[i]["security_group_rule_id"].(string) < flagged[j]["security_group_rule_id"].(string)
+	})
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"SecurityGroups": groups}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setFingerprints(flagged, ec2SgRulesOverlyPermissiveFingerprintAttributes); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("rules", flagged); err != nil {
+		return diag.Errorf("error setting rules: %s", err)
+	}
+
+	return warnings
+}
+
+// sgRuleRiskCategories returns the risk categories of the given Security Group Rule. openPrefixList
+// indicates whether the prefix list referenced by the rule, if any, contains 0.0.0.0/0 or ::/0.
+func sgRuleRiskCategories(rule *ec2.SecurityGroupRule, openPrefixList bool) []string {
+	var categories []string
+
+	if cidr := aws.StringValue(rule.CidrIpv4); cidr != "" && tfnet.CanonicalCIDRBlock(cidr) == openIpv4CidrBlock {
+		categories = append(categories, sgRuleRiskOpenIpv4)
+	}
+
+	if cidr := aws.StringValue(rule.CidrIpv6); cidr != "" && tfnet.CanonicalCIDRBlock(cidr) == openIpv6CidrBlock {
+		categories = append(categories, sgRuleRiskOpenIpv6)
+	}
+
+	if aws.StringValue(rule.PrefixListId) != "" && openPrefixList {
+		categories = append(categories, sgRuleRiskOpenPrefixList)
+	}
+
+	switch protocol := aws.StringValue(rule.IpProtocol); protocol {
+	case "-1", "all":
+		categories = append(categories, sgRuleRiskAllProtocols)
+	case "tcp", "udp", "6", "17":
+		from, to := aws.Int64Value(rule.FromPort), aws.Int64Value(rule.ToPort)
+		if (from <= 0 && to >= 65535) || (from == -1 && to == -1) {
+			categories = append(categories, sgRuleRiskAllPorts)
+		}
+	}
+
+	return categories
+}
+
+// sgRuleImportIDs returns the upstream AWS provider import IDs of the given Security Group Rule,
+// keyed by resource type.
+func sgRuleImportIDs(rule *ec2.SecurityGroupRule) map[string]interface{} {
+	groupID := aws.StringValue(rule.GroupId)
+
+	var referencedGroupID string
+	if rule.ReferencedGroupInfo != nil {
+		referencedGroupID = aws.StringValue(rule.ReferencedGroupInfo.GroupId)
+	}
+
+	source := tfec2.SecurityGroupRuleSource(groupID, aws.StringValue(rule.CidrIpv4), aws.StringValue(rule.CidrIpv6), aws.StringValue(rule.PrefixListId), referencedGroupID)
+
+	vpcRuleType := "aws_vpc_security_group_ingress_rule"
+	if aws.BoolValue(rule.IsEgress) {
+		vpcRuleType = "aws_vpc_security_group_egress_rule"
+	}
+
+	return map[string]interface{}{
+		"aws_security_group_rule": tfec2.SecurityGroupRuleImportID(groupID, aws.BoolValue(rule.IsEgress), aws.StringValue(rule.IpProtocol), aws.Int64Value(rule.FromPort), aws.Int64Value(rule.ToPort), source),
+		vpcRuleType:               aws.StringValue(rule.SecurityGroupRuleId),
+	}
+}
+
+// managedPrefixListIsOpen returns whether the given Managed Prefix List contains 0.0.0.0/0 or ::/0.
+func managedPrefixListIsOpen(conn *ec2.EC2, prefixListID string) (bool, error) {
+	entries, err := finder.ManagedPrefixListEntries(conn, prefixListID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, entry := range entries {
+		switch tfnet.CanonicalCIDRBlock(aws.StringValue(entry.Cidr)) {
+		case openIpv4CidrBlock, openIpv6CidrBlock:
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
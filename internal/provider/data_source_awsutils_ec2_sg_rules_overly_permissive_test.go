@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestSgRuleRiskCategories(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Rule           *ec2.SecurityGroupRule
+		OpenPrefixList bool
+		Expected       []string
+	}{
+		{
+			Name: "restricted rule",
+			Rule: &ec2.SecurityGroupRule{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(443),
+				ToPort:     aws.Int64(443),
+				CidrIpv4:   aws.String("10.0.0.0/16"),
+			},
+		},
+		{
+			Name: "open IPv4",
+			Rule: &ec2.SecurityGroupRule{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(22),
+				ToPort:     aws.Int64(22),
+				CidrIpv4:   aws.String("0.0.0.0/0"),
+			},
+			Expected: []string{sgRuleRiskOpenIpv4},
+		},
+		{
+			Name: "open IPv6 non-canonical",
+			Rule: &ec2.SecurityGroupRule{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(22),
+				ToPort:     aws.Int64(22),
+				CidrIpv6:   aws.String("::0/0"),
+			},
+			Expected: []string{sgRuleRiskOpenIpv6},
+		},
+		{
+			Name: "restricted IPv6",
+			Rule: &ec2.SecurityGroupRule{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(22),
+				ToPort:     aws.Int64(22),
+				CidrIpv6:   aws.String("2001:db8::/32"),
+			},
+		},
+		{
+			Name: "open prefix list all ports",
+			Rule: &ec2.SecurityGroupRule{
+				IpProtocol:   aws.String("udp"),
+				FromPort:     aws.Int64(0),
+				ToPort:       aws.Int64(65535),
+				PrefixListId: aws.String("pl-12345678"),
+			},
+			OpenPrefixList: true,
+			Expected:       []string{sgRuleRiskOpenPrefixList, sgRuleRiskAllPorts},
+		},
+		{
+			Name: "restricted prefix list",
+			Rule: &ec2.SecurityGroupRule{
+				IpProtocol:   aws.String("tcp"),
+				FromPort:     aws.Int64(443),
+				ToPort:       aws.Int64(443),
+				PrefixListId: aws.String("pl-12345678"),
+			},
+		},
+		{
+			Name: "referenced group all protocols",
+			Rule: &ec2.SecurityGroupRule{
+				IpProtocol:          aws.String("-1"),
+				FromPort:            aws.Int64(-1),
+				ToPort:              aws.Int64(-1),
+				ReferencedGroupInfo: &ec2.ReferencedSecurityGroup{GroupId: aws.String("sg-12345678")},
+			},
+			Expected: []string{sgRuleRiskAllProtocols},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := sgRuleRiskCategories(testCase.Rule, testCase.OpenPrefixList)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package provider
+
+import (
+	"context"
+	"log"
+	"net"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAwsUtilsEc2Subnets() *schema.Resource {
+	return &schema.Resource{
+		Description: `Lists the Subnets matching the given filters, optionally only those whose CIDR block contains a given IP
+address, such as to find the Subnet of an address.
+
+The EC2 API cannot filter the Subnets by the addresses their CIDR blocks contain, so when ` + "`contains_ip`" + ` is set,
+the Subnets matching the other filters, such as ` + "`vpc_id`" + `, are read and those whose IPv4 CIDR block, for an
+IPv4 address, or one of whose associated IPv6 CIDR blocks, for an IPv6 address, contains it are kept on the client side.
+No Subnet containing the address is not an error, the lists being empty.
+
+The Subnets shared with the account of the provider by other accounts through Resource Access Manager (RAM), such as
+those of a shared VPC, are only matched if ` + "`include_shared`" + ` is set, each Subnet being annotated with the
+account owning it.`,
+		ReadContext:   dataSourceAwsUtilsEc2SubnetsRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeSubnet),
+			"ids_strict":        ec2IDsStrictSchema(),
+			"arns":              ec2ARNsSchema(ec2.ResourceTypeSubnet),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"region":            ec2RegionSchema(),
+			"regex_filter":      ec2RegexFiltersSchema(),
+			"tags":              tagsSchema(),
+			"case_insensitive":  ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":      ec2ExcludeTagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"has_tags":          ec2HasTagsSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"max_results_cap":   maxResultsCapSchema(),
+			"debug":             debugSchema(),
+			"raw_response_json": rawResponseJSONSchema(),
+			"applied_filters":   ec2AppliedFiltersSchema(),
+			"resolved_filters":  ec2ResolvedFiltersSchema(),
+			"filters_object":    ec2FiltersObjectSchema(),
+			"allow_unfiltered":  ec2AllowUnfilteredSchema(),
+			"include_shared":    ec2IncludeSharedSchema(),
+			"vpc_id": {
+				Description:  "Only match the Subnets of the given VPC.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"contains_ip": {
+				Description:  "Only keep the Subnets whose CIDR block contains the given IPv4 or IPv6 address.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsIPAddress,
+			},
+			"subnets": {
+				Description: "The Subnets, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"subnet_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vpc_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"availability_zone": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"cidr_block": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ipv6_cidr_blocks": {
+							Description: "The associated IPv6 CIDR blocks of the Subnet.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"owner_id": {
+							Description: "The ID of the AWS account owning the Subnet.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"shared": {
+							Description: "Whether the Subnet is shared with the account of the provider by another account, rather than owned by it.",
+							Type:        schema.TypeBool,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"subnet_ids": {
+				Description: "The IDs of the Subnets, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2SubnetsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	accountID := meta.(*AWSClient).accountid
+
+	input := &ec2.DescribeSubnetsInput{
+		Filters: buildEC2AttributeFilterList(map[string]string{
+			"vpc-id": d.Get("vpc_id").(string),
+		}),
+	}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeSubnet)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.SubnetIds = ids
+	input.Filters = append(input.Filters, filters...)
+	input.Filters = append(input.Filters, buildEC2SharedExclusionFilterList(d.Get("include_shared").(bool), accountID)...)
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var subnets []*ec2.Subnet
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		subnets, err = finder.Subnets(conn, input, maxResultsCap(d, meta))
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Subnets: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Subnet)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	kept := subnets[:0]
+	for _, subnet := range subnets {
+		if !excluded(subnet.Tags) && tagsMatched(subnet.Tags) && regexMatched(subnet) {
+			kept = append(kept, subnet)
+		}
+	}
+	subnets = kept
+
+	if v, ok := d.GetOk("contains_ip"); ok {
+		subnets = ec2SubnetsContainingIP(subnets, net.ParseIP(v.(string)))
+	}
+
+	sort.Slice(subnets, func(i, j int) bool {
+		return aws.StringValue(subnets[i].SubnetId) < aws.StringValue(subnets[j].SubnetId)
+	})
+
+	results := make([]map[string]interface{}, 0, len(subnets))
+	subnetIDs := make([]string, 0, len(subnets))
+	for _, subnet := range subnets {
+		results = append(results, map[string]interface{}{
+			"subnet_id":         aws.StringValue(subnet.SubnetId),
+			"vpc_id":            aws.StringValue(subnet.VpcId),
+			"availability_zone": aws.StringValue(subnet.AvailabilityZone),
+			"cidr_block":        aws.StringValue(subnet.CidrBlock),
+			"ipv6_cidr_blocks":  ec2SubnetIpv6CidrBlocks(subnet),
+			"owner_id":          aws.StringValue(subnet.OwnerId),
+			"shared":            ec2SharedByOtherAccount(aws.StringValue(subnet.OwnerId), accountID),
+		})
+		subnetIDs = append(subnetIDs, aws.StringValue(subnet.SubnetId))
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Subnets": subnets}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("subnets", results); err != nil {
+		return diag.Errorf("error setting subnets: %s", err)
+	}
+
+	if err := d.Set("subnet_ids", subnetIDs); err != nil {
+		return diag.Errorf("error setting subnet_ids: %s", err)
+	}
+
+	return warnings
+}
+
+// ec2SubnetsContainingIP returns the given Subnets with a CIDR block containing the given IP address: the IPv4
+// CIDR block for an IPv4 address, and any of the associated IPv6 CIDR blocks for an IPv6 address. A CIDR block
+// which cannot be parsed is logged and does not contain any address.
+func ec2SubnetsContainingIP(subnets []*ec2.Subnet, ip net.IP) []*ec2.Subnet {
+	result := make([]*ec2.Subnet, 0)
+
+	for _, subnet := range subnets {
+		cidrBlocks := ec2SubnetIpv6CidrBlocks(subnet)
+		if ip.To4() != nil {
+			cidrBlocks = []string{aws.StringValue(subnet.CidrBlock)}
+		}
+
+		for _, cidrBlock := range cidrBlocks {
+			if cidrBlock == "" {
+				continue
+			}
+
+			_, ipNet, err := net.ParseCIDR(cidrBlock)
+			if err != nil {
+				log.Printf("[WARN] Error parsing CIDR block (%s) of EC2 Subnet (%s): %s", cidrBlock, aws.StringValue(subnet.SubnetId), err)
+				continue
+			}
+
+			if ipNet.Contains(ip) {
+				result = append(result, subnet)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// ec2SubnetIpv6CidrBlocks returns the associated IPv6 CIDR blocks of the given Subnet, leaving out those being
+// associated or disassociated.
+func ec2SubnetIpv6CidrBlocks(subnet *ec2.Subnet) []string {
+	cidrBlocks := make([]string, 0, len(subnet.Ipv6CidrBlockAssociationSet))
+
+	for _, association := range subnet.Ipv6CidrBlockAssociationSet {
+		if association == nil || association.Ipv6CidrBlockState == nil || aws.StringValue(association.Ipv6CidrBlockState.State) != ec2.SubnetCidrBlockStateCodeAssociated {
+			continue
+		}
+
+		cidrBlocks = append(cidrBlocks, aws.StringValue(association.Ipv6CidrBlock))
+	}
+
+	return cidrBlocks
+}
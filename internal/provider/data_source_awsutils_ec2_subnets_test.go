@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2SubnetsContainingIP(t *testing.T) {
+	ipv6CidrBlock := func(cidrBlock, state string) *ec2.SubnetIpv6CidrBlockAssociation {
+		return &ec2.SubnetIpv6CidrBlockAssociation{
+			Ipv6CidrBlock:      aws.String(cidrBlock),
+			Ipv6CidrBlockState: &ec2.SubnetCidrBlockState{State: aws.String(state)},
+		}
+	}
+
+	subnets := []*ec2.Subnet{
+		{
+			SubnetId:                    aws.String("subnet-00000001"),
+			CidrBlock:                   aws.String("10.0.0.0/24"),
+			Ipv6CidrBlockAssociationSet: []*ec2.SubnetIpv6CidrBlockAssociation{ipv6CidrBlock("2001:db8:0:1::/64", ec2.SubnetCidrBlockStateCodeAssociated)},
+		},
+		{
+			SubnetId:  aws.String("subnet-00000002"),
+			CidrBlock: aws.String("10.0.1.0/24"),
+			Ipv6CidrBlockAssociationSet: []*ec2.SubnetIpv6CidrBlockAssociation{
+				ipv6CidrBlock("2001:db8:0:2::/64", ec2.SubnetCidrBlockStateCodeDisassociated),
+				ipv6CidrBlock("2001:db8:0:3::/64", ec2.SubnetCidrBlockStateCodeAssociated),
+			},
+		},
+		{
+			SubnetId:  aws.String("subnet-00000003"),
+			CidrBlock: aws.String("10.0.0.0/16"),
+		},
+		{
+			SubnetId:  aws.String("subnet-00000004"),
+			CidrBlock: aws.String("not a CIDR block"),
+		},
+	}
+
+	testCases := []struct {
+		Name     string
+		IP       string
+		Expected []string
+	}{
+		{
+			Name:     "IPv4",
+			IP:       "10.0.1.10",
+			Expected: []string{"subnet-00000002", "subnet-00000003"},
+		},
+		{
+			Name:     "IPv4 network address",
+			IP:       "10.0.0.0",
+			Expected: []string{"subnet-00000001", "subnet-00000003"},
+		},
+		{
+			Name:     "IPv4 not contained",
+			IP:       "192.168.0.1",
+			Expected: []string{},
+		},
+		{
+			Name:     "IPv6",
+			IP:       "2001:db8:0:3::10",
+			Expected: []string{"subnet-00000002"},
+		},
+		{
+			Name:     "IPv6 of a disassociated CIDR block",
+			IP:       "2001:db8:0:2::10",
+			Expected: []string{},
+		},
+		{
+			Name:     "IPv6 not contained",
+			IP:       "2001:db8:1::1",
+			Expected: []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, subnet := range ec2SubnetsContainingIP(subnets, net.ParseIP(testCase.IP)) {
+				got = append(got, aws.StringValue(subnet.SubnetId))
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestDataSourceAwsUtilsEc2SubnetsReadIncludeShared(t *testing.T) {
+	subnets := []*ec2.Subnet{
+		{SubnetId: aws.String("subnet-00000001"), OwnerId: aws.String("111111111111")},
+		{SubnetId: aws.String("subnet-00000002"), OwnerId: aws.String("222222222222")},
+	}
+
+	var inputs []*ec2.DescribeSubnetsInput
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		output, ok := r.Data.(*ec2.DescribeSubnetsOutput)
+		if !ok {
+			return
+		}
+
+		input := r.Params.(*ec2.DescribeSubnetsInput)
+		inputs = append(inputs, input)
+
+		// The shared Subnets are returned unless filtered out by owner.
+		var ownerIDs []string
+		for _, filter := range input.Filters {
+			if aws.StringValue(filter.Name) == "owner-id" {
+				ownerIDs = aws.StringValueSlice(filter.Values)
+			}
+		}
+		for _, subnet := range subnets {
+			if len(ownerIDs) == 0 || aws.StringValue(subnet.OwnerId) == ownerIDs[0] {
+				output.Subnets = append(output.Subnets, subnet)
+			}
+		}
+	})
+
+	testCases := []struct {
+		Name          string
+		IncludeShared bool
+		AccountID     string
+		Expected      []interface{}
+	}{
+		{
+			Name:      "shared left out",
+			AccountID: "111111111111",
+			Expected: []interface{}{
+				map[string]interface{}{"subnet_id": "subnet-00000001", "owner_id": "111111111111", "shared": false},
+			},
+		},
+		{
+			Name:          "shared included",
+			IncludeShared: true,
+			AccountID:     "111111111111",
+			Expected: []interface{}{
+				map[string]interface{}{"subnet_id": "subnet-00000001", "owner_id": "111111111111", "shared": false},
+				map[string]interface{}{"subnet_id": "subnet-00000002", "owner_id": "222222222222", "shared": true},
+			},
+		},
+		{
+			Name: "unknown account",
+			Expected: []interface{}{
+				map[string]interface{}{"subnet_id": "subnet-00000001", "owner_id": "111111111111", "shared": false},
+				map[string]interface{}{"subnet_id": "subnet-00000002", "owner_id": "222222222222", "shared": false},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2Subnets().Schema, map[string]interface{}{
+				"include_shared":   testCase.IncludeShared,
+				"allow_unfiltered": true,
+			})
+
+			client := &AWSClient{ec2conn: conn, region: "us-east-1", accountid: testCase.AccountID, maxResultsCap: defaultMaxResultsCap}
+			if diags := dataSourceAwsUtilsEc2SubnetsRead(context.Background(), d, client); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			var got []interface{}
+			for _, v := range d.Get("subnets").([]interface{}) {
+				m := v.(map[string]interface{})
+				got = append(got, map[string]interface{}{"subnet_id": m["subnet_id"], "owner_id": m["owner_id"], "shared": m["shared"]})
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got subnets %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestDataSourceAwsUtilsEc2SubnetsReadIDsStrict(t *testing.T) {
+	existing := &ec2.Subnet{SubnetId: aws.String("subnet-00000001"), CidrBlock: aws.String("10.0.1.0/24")}
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		input := r.Params.(*ec2.DescribeSubnetsInput)
+		// As the API does, the SubnetIds parameter fails on a missing Subnet, while the filter leaves it out.
+		for _, id := range aws.StringValueSlice(input.SubnetIds) {
+			if id != aws.StringValue(existing.SubnetId) {
+				r.Error = awserr.New("InvalidSubnetID.NotFound", fmt.Sprintf("The subnet ID '%s' does not exist", id), nil)
+				return
+			}
+		}
+		r.Data.(*ec2.DescribeSubnetsOutput).Subnets = []*ec2.Subnet{existing}
+	})
+
+	ids := []interface{}{"subnet-00000001", "subnet-00000002"}
+	client := &AWSClient{ec2conn: conn, region: "us-east-1", maxResultsCap: defaultMaxResultsCap}
+
+	t.Run("strict", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2Subnets().Schema, map[string]interface{}{
+			"ids": ids,
+		})
+
+		diags := dataSourceAwsUtilsEc2SubnetsRead(context.Background(), d, client)
+		if !diags.HasError() || !strings.Contains(diags[0].Summary, "InvalidSubnetID.NotFound") {
+			t.Errorf("expected an InvalidSubnetID.NotFound error, got %v", diags)
+		}
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2Subnets().Schema, map[string]interface{}{
+			"ids":        ids,
+			"ids_strict": false,
+		})
+
+		if diags := dataSourceAwsUtilsEc2SubnetsRead(context.Background(), d, client); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+
+		var got []interface{}
+		for _, v := range d.Get("subnets").([]interface{}) {
+			got = append(got, v.(map[string]interface{})["subnet_id"])
+		}
+		if expected := []interface{}{"subnet-00000001"}; !reflect.DeepEqual(got, expected) {
+			t.Errorf("got subnets %v, expected %v", got, expected)
+		}
+	})
+}
+
+func TestDataSourceAwsUtilsEc2SubnetsReadFilterWildcardWarning(t *testing.T) {
+	conn := testEc2Conn(t, func(r *request.Request) {
+		r.Data.(*ec2.DescribeSubnetsOutput).Subnets = []*ec2.Subnet{
+			{SubnetId: aws.String("subnet-00000001"), CidrBlock: aws.String("10.0.1.0/24")},
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2Subnets().Schema, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{"name": "tag:Name", "values": []interface{}{"web-*"}},
+		},
+	})
+
+	diags := dataSourceAwsUtilsEc2SubnetsRead(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-east-1", maxResultsCap: defaultMaxResultsCap})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	// The warning of the "filter" block is returned along with the Subnets read.
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || diags[0].Summary != `filter tag:Name: value "web-*" contains "*" but wildcard is not set` {
+		t.Errorf("got diagnostics %v, expected a warning about the \"*\" of web-*", diags)
+	}
+	if got, expected := d.Get("subnet_ids").([]interface{}), []interface{}{"subnet-00000001"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got subnet_ids %v, expected %v", got, expected)
+	}
+}
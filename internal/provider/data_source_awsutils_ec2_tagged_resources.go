@@ -0,0 +1,318 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2TaggedResource is a resource listed by the awsutils_ec2_tagged_resources data source, whatever its type.
+type ec2TaggedResource struct {
+	ResourceType string
+	ID           string
+	Tags         []*ec2.Tag
+}
+
+// ec2TaggedResourceListers list the resources of each type supported by the awsutils_ec2_tagged_resources data
+// source matching the given filters, reading at most maxResults of them.
+var ec2TaggedResourceListers = map[string]func(conn *ec2.EC2, filters []*ec2.Filter, maxResults int) ([]ec2TaggedResource, error){
+	ec2.ResourceTypeInstance: func(conn *ec2.EC2, filters []*ec2.Filter, maxResults int) ([]ec2TaggedResource, error) {
+		// The terminated instances are left out, as they cannot be tagged anymore.
+		input := &ec2.DescribeInstancesInput{
+			Filters: append(filters, &ec2.Filter{
+				Name: aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{
+					ec2.InstanceStateNamePending,
+					ec2.InstanceStateNameRunning,
+					ec2.InstanceStateNameShuttingDown,
+					ec2.InstanceStateNameStopping,
+					ec2.InstanceStateNameStopped,
+				}),
+			}),
+		}
+
+		instances, err := finder.Instances(conn, input, maxResults)
+		if err != nil {
+			return nil, err
+		}
+
+		resources := make([]ec2TaggedResource, 0, len(instances))
+		for _, instance := range instances {
+			resources = append(resources, ec2TaggedResource{ec2.ResourceTypeInstance, aws.StringValue(instance.InstanceId), instance.Tags})
+		}
+
+		return resources, nil
+	},
+	ec2.ResourceTypeNetworkInterface: func(conn *ec2.EC2, filters []*ec2.Filter, maxResults int) ([]ec2TaggedResource, error) {
+		networkInterfaces, err := finder.NetworkInterfaces(conn, &ec2.DescribeNetworkInterfacesInput{Filters: filters})
+		if err != nil {
+			return nil, err
+		}
+
+		resources := make([]ec2TaggedResource, 0, len(networkInterfaces))
+		for _, networkInterface := range networkInterfaces {
+			resources = append(resources, ec2TaggedResource{ec2.ResourceTypeNetworkInterface, aws.StringValue(networkInterface.NetworkInterfaceId), networkInterface.TagSet})
+		}
+
+		return resources, nil
+	},
+	ec2.ResourceTypeSecurityGroup: func(conn *ec2.EC2, filters []*ec2.Filter, maxResults int) ([]ec2TaggedResource, error) {
+		groups, err := finder.SecurityGroups(conn, &ec2.DescribeSecurityGroupsInput{Filters: filters}, maxResults)
+		if err != nil {
+			return nil, err
+		}
+
+		resources := make([]ec2TaggedResource, 0, len(groups))
+		for _, group := range groups {
+			resources = append(resources, ec2TaggedResource{ec2.ResourceTypeSecurityGroup, aws.StringValue(group.GroupId), group.Tags})
+		}
+
+		return resources, nil
+	},
+	ec2.ResourceTypeVolume: func(conn *ec2.EC2, filters []*ec2.Filter, maxResults int) ([]ec2TaggedResource, error) {
+		volumes, err := finder.Volumes(conn, &ec2.DescribeVolumesInput{Filters: filters}, maxResults)
+		if err != nil {
+			return nil, err
+		}
+
+		resources := make([]ec2TaggedResource, 0, len(volumes))
+		for _, volume := range volumes {
+			resources = append(resources, ec2TaggedResource{ec2.ResourceTypeVolume, aws.StringValue(volume.VolumeId), volume.Tags})
+		}
+
+		return resources, nil
+	},
+}
+
+func dataSourceAwsUtilsEc2TaggedResources() *schema.Resource {
+	return &schema.Resource{
+		Description: `Lists the EC2 resources of several types carrying the given tags, such as for a tag audit, in a single
+list of the same shape for every type.
+
+Each of the ` + "`resource_types`" + ` is described independently, in parallel with at most ` + "`max_concurrency`" + `
+requests in flight at any time, with the same tag filters. Terminated instances are left out. Reading fails if
+describing any type fails, unless ` + "`continue_on_error`" + ` is set, in which case the resources of the other types
+are still listed and the failed types are reported in ` + "`failed_resource_types`" + `.`,
+		ReadContext:   dataSourceAwsUtilsEc2TaggedResourcesRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"resource_types": {
+				Description: "The types of the resources to list, among `instance`, `network-interface`, `security-group` and `volume`.",
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(ec2TaggedResourceTypes(), false),
+				},
+			},
+			"name":                      ec2NameSchema(),
+			"tags":                      tagsSchema(),
+			"region":                    ec2RegionSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"max_concurrency": {
+				Description:  "The maximum number of describe requests in flight at any time.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntBetween(1, 4),
+			},
+			"continue_on_error": {
+				Description: "Keep the resources of the other types when describing a type fails, reporting the failure in `failed_resource_types` instead of failing the read.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"resources": {
+				Description: "The matching resources, ordered by type and ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Description: "The type of the resource, one of `resource_types`.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"tags": {
+							Description: "The tags of the resource, without those with the reserved `aws:` prefix and those ignored by the provider's `ignore_tags`.",
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"prometheus_metrics": prometheusMetricsSchema(),
+			"failed_resource_types": {
+				Description: "The types which could not be described when `continue_on_error` is set, ordered by type.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"error": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// ec2TaggedResourceTypes returns the types supported by the awsutils_ec2_tagged_resources data source, sorted.
+func ec2TaggedResourceTypes() []string {
+	types := make([]string, 0, len(ec2TaggedResourceListers))
+	for resourceType := range ec2TaggedResourceListers {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+func dataSourceAwsUtilsEc2TaggedResourcesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	maxResults := maxResultsCap(d, meta)
+	continueOnError := d.Get("continue_on_error").(bool)
+
+	// Only tag filters are built, which every type supports, so the resource type only matters to IDs, of which
+	// there are none.
+	_, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeInstance)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, nil, filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	resourceTypes := ExpandStringSliceofPointers(ExpandStringSet(d.Get("resource_types").(*schema.Set)))
+	sort.Strings(resourceTypes)
+
+	results := make([][]ec2TaggedResource, len(resourceTypes))
+	errs := make([]error, len(resourceTypes))
+	funcs := make([]func() error, 0, len(resourceTypes))
+
+	for i, resourceType := range resourceTypes {
+		i, resourceType := i, resourceType
+		lister, ok := ec2TaggedResourceListers[resourceType]
+		if !ok {
+			return diag.Errorf("unsupported resource type: %s", resourceType)
+		}
+
+		funcs = append(funcs, func() error {
+			var resources []ec2TaggedResource
+			err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				// The filters are copied as the types are described concurrently.
+				resources, err = lister(conn, append([]*ec2.Filter{}, filters...), maxResults)
+				return err
+			})
+			if err != nil {
+				err = fmt.Errorf("error reading EC2 resources of type %s: %w", resourceType, maxResultsCapError(err))
+				if !continueOnError {
+					return err
+				}
+				log.Printf("[WARN] %s", err)
+				errs[i] = err
+				return nil
+			}
+
+			results[i] = resources
+			return nil
+		})
+	}
+
+	if err := runConcurrently(d.Get("max_concurrency").(int), funcs...); err != nil {
+		return diag.FromErr(err)
+	}
+
+	var resources []ec2TaggedResource
+	var samples []prometheusSample
+	failed := make([]interface{}, 0)
+	for i, resourceType := range resourceTypes {
+		if errs[i] != nil {
+			failed = append(failed, map[string]interface{}{
+				"type":  resourceType,
+				"error": errs[i].Error(),
+			})
+			continue
+		}
+		resources = append(resources, results[i]...)
+		samples = append(samples, prometheusSample{
+			Labels: map[string]string{"type": resourceType},
+			Value:  float64(len(results[i])),
+		})
+	}
+
+	d.SetId(region)
+
+	if err := d.Set("resources", flattenEc2TaggedResources(resources, meta.(*AWSClient).IgnoreTagsConfig)); err != nil {
+		return diag.Errorf("error setting resources: %s", err)
+	}
+
+	if err := d.Set("failed_resource_types", failed); err != nil {
+		return diag.Errorf("error setting failed_resource_types: %s", err)
+	}
+
+	// The failed types are left out rather than counted as having no resources.
+	if err := setPrometheusMetrics(d, prometheusMetric{
+		Name:    "awsutils_ec2_tagged_resources",
+		Help:    "The number of matching EC2 resources by type.",
+		Samples: samples,
+	}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return warnings
+}
+
+// flattenEc2TaggedResources flattens the given resources, ordered by type and ID, without their tags ignored by
+// the given configuration.
+func flattenEc2TaggedResources(resources []ec2TaggedResource, ignoreConfig *keyvaluetags.IgnoreConfig) []interface{} {
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].ResourceType != resources[j].ResourceType {
+			return resources[i].ResourceType < resources[j].ResourceType
+		}
+		return resources[i].ID < resources[j].ID
+	})
+
+	result := make([]interface{}, 0, len(resources))
+	for _, resource := range resources {
+		result = append(result, map[string]interface{}{
+			"type": resource.ResourceType,
+			"id":   resource.ID,
+			"tags": keyvaluetags.Ec2KeyValueTags(resource.Tags).IgnoreAws().IgnoreConfig(ignoreConfig).Map(),
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testEc2TaggedResourcesConn returns an EC2 client answering the describe requests of every supported type with a
+// resource carrying the Team tag, failing those of Security Groups if failSecurityGroups is set, and recording the
+// filters of the requests.
+func testEc2TaggedResourcesConn(t *testing.T, failSecurityGroups bool, filters *[][]*ec2.Filter) *ec2.EC2 {
+	tags := []*ec2.Tag{
+		{Key: aws.String("Team"), Value: aws.String("platform")},
+		{Key: aws.String("aws:cloudformation:stack-name"), Value: aws.String("stack")},
+	}
+
+	var mu sync.Mutex
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeInstancesOutput:
+			mu.Lock()
+			*filters = append(*filters, r.Params.(*ec2.DescribeInstancesInput).Filters)
+			mu.Unlock()
+			output.Reservations = []*ec2.Reservation{{Instances: []*ec2.Instance{
+				{InstanceId: aws.String("i-00000002"), Tags: tags},
+				{InstanceId: aws.String("i-00000001"), Tags: tags},
+			}}}
+		case *ec2.DescribeNetworkInterfacesOutput:
+			output.NetworkInterfaces = []*ec2.NetworkInterface{{NetworkInterfaceId: aws.String("eni-00000001"), TagSet: tags}}
+		case *ec2.DescribeSecurityGroupsOutput:
+			if failSecurityGroups {
+				r.Error = errors.New("UnauthorizedOperation")
+				return
+			}
+			output.SecurityGroups = []*ec2.SecurityGroup{{GroupId: aws.String("sg-00000001"), Tags: tags}}
+		case *ec2.DescribeVolumesOutput:
+			output.Volumes = []*ec2.Volume{{VolumeId: aws.String("vol-00000001"), Tags: tags}}
+		}
+	})
+
+	return conn
+}
+
+func TestDataSourceAwsUtilsEc2TaggedResourcesRead(t *testing.T) {
+	var filters [][]*ec2.Filter
+	conn := testEc2TaggedResourcesConn(t, false, &filters)
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2TaggedResources().Schema, map[string]interface{}{
+		"resource_types": []interface{}{"volume", "instance", "security-group"},
+		"tags":           map[string]interface{}{"Team": "platform"},
+	})
+
+	if diags := dataSourceAwsUtilsEc2TaggedResourcesRead(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-east-1", maxResultsCap: defaultMaxResultsCap}); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	tags := map[string]interface{}{"Team": "platform"}
+	expected := []interface{}{
+		map[string]interface{}{"type": "instance", "id": "i-00000001", "tags": tags},
+		map[string]interface{}{"type": "instance", "id": "i-00000002", "tags": tags},
+		map[string]interface{}{"type": "security-group", "id": "sg-00000001", "tags": tags},
+		map[string]interface{}{"type": "volume", "id": "vol-00000001", "tags": tags},
+	}
+	if got := d.Get("resources"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got resources %v, expected %v", got, expected)
+	}
+
+	expectedFilters := [][]*ec2.Filter{
+		{
+			{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{"platform"})},
+			{Name: aws.String("instance-state-name"), Values: aws.StringSlice([]string{"pending", "running", "shutting-down", "stopping", "stopped"})},
+		},
+	}
+	if !reflect.DeepEqual(filters, expectedFilters) {
+		t.Errorf("got DescribeInstances filters %v, expected %v", filters, expectedFilters)
+	}
+
+	if got := d.Get("failed_resource_types"); !reflect.DeepEqual(got, []interface{}{}) {
+		t.Errorf("got failed_resource_types %v, expected none", got)
+	}
+}
+
+func TestDataSourceAwsUtilsEc2TaggedResourcesReadPartialFailure(t *testing.T) {
+	raw := map[string]interface{}{
+		"resource_types":   []interface{}{"network-interface", "security-group"},
+		"allow_unfiltered": true,
+	}
+
+	var filters [][]*ec2.Filter
+	conn := testEc2TaggedResourcesConn(t, true, &filters)
+	client := &AWSClient{ec2conn: conn, region: "us-east-1", maxResultsCap: defaultMaxResultsCap}
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2TaggedResources().Schema, raw)
+	if diags := dataSourceAwsUtilsEc2TaggedResourcesRead(context.Background(), d, client); !diags.HasError() || !strings.Contains(diags[0].Summary, "security-group") {
+		t.Fatalf("got %v, expected the error of the security-group type", diags)
+	}
+
+	raw["continue_on_error"] = true
+	d = schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2TaggedResources().Schema, raw)
+	if diags := dataSourceAwsUtilsEc2TaggedResourcesRead(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{"type": "network-interface", "id": "eni-00000001", "tags": map[string]interface{}{"Team": "platform"}},
+	}
+	if got := d.Get("resources"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got resources %v, expected %v", got, expected)
+	}
+
+	failed := d.Get("failed_resource_types").([]interface{})
+	if len(failed) != 1 || failed[0].(map[string]interface{})["type"] != "security-group" {
+		t.Errorf("got failed_resource_types %v, expected security-group", failed)
+	}
+}
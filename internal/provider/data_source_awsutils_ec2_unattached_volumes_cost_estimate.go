@@ -0,0 +1,493 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/pricing"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	// gp3 volumes include a baseline of 3,000 IOPS and 125 MiB/s of throughput at no additional cost.
+	ebsGp3BaselineIops       = 3000
+	ebsGp3BaselineThroughput = 125
+)
+
+// The default prices are the us-east-1 on-demand list prices in USD.
+var (
+	defaultEbsStoragePricePerGbMonth = map[string]float64{
+		ec2.VolumeTypeGp2:      0.10,
+		ec2.VolumeTypeGp3:      0.08,
+		ec2.VolumeTypeIo1:      0.125,
+		ec2.VolumeTypeIo2:      0.125,
+		ec2.VolumeTypeSt1:      0.045,
+		ec2.VolumeTypeSc1:      0.015,
+		ec2.VolumeTypeStandard: 0.05,
+	}
+
+	defaultEbsIopsPricePerMonth = map[string]float64{
+		ec2.VolumeTypeGp3: 0.005,
+		ec2.VolumeTypeIo1: 0.065,
+		ec2.VolumeTypeIo2: 0.065,
+	}
+
+	defaultEbsThroughputPricePerMibpsMonth = map[string]float64{
+		ec2.VolumeTypeGp3: 0.04,
+	}
+)
+
+// ec2UnattachedVolumesFingerprintAttributes are the attributes of the "volumes" hashed into their fingerprint.
+var ec2UnattachedVolumesFingerprintAttributes = []string{"volume_id", "volume_type", "availability_zone", "size", "iops", "throughput"}
+
+func dataSourceAwsUtilsEc2UnattachedVolumesCostEstimate() *schema.Resource {
+	return &schema.Resource{
+		Description: `Estimates the monthly cost of the unattached (` + "`available`" + `) EBS volumes matching the given filters.
+
+The cost of each volume is the sum of its storage, provisioned IOPS and provisioned throughput components:
+
+- Storage is charged per GB-month for every volume type.
+- For ` + "`gp3`" + ` volumes, IOPS above the 3,000 baseline and throughput above the 125 MiB/s baseline are charged separately.
+- For ` + "`io1`" + ` and ` + "`io2`" + ` volumes, all provisioned IOPS are charged. The volume discounts of the higher ` + "`io2`" + ` IOPS tiers
+  are not taken into account.
+- ` + "`gp2`" + `, ` + "`st1`" + `, ` + "`sc1`" + ` and ` + "`standard`" + ` volumes are charged for storage only.
+
+Prices default to the us-east-1 on-demand list prices and can be overridden per volume type. When ` + "`use_pricing_api`" + `
+is set, prices are looked up for the region of the volumes in the AWS Price List API instead, falling back to the
+configured prices for any component that cannot be found.`,
+		ReadContext:   dataSourceAwsUtilsEc2UnattachedVolumesCostEstimateRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":                       ec2IDsSchema(ec2.ResourceTypeVolume),
+			"ids_strict":                ec2IDsStrictSchema(),
+			"arns":                      ec2ARNsSchema(ec2.ResourceTypeVolume),
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"regex_filter":              ec2RegexFiltersSchema(),
+			"tags":                      tagsSchema(),
+			"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+			"exclude_tags":              ec2ExcludeTagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"max_results_cap":           maxResultsCapSchema(),
+			"debug":                     debugSchema(),
+			"raw_response_json":         rawResponseJSONSchema(),
+			"applied_filters":           ec2AppliedFiltersSchema(),
+			"resolved_filters":          ec2ResolvedFiltersSchema(),
+			"filters_object":            ec2FiltersObjectSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"prometheus_metrics":        prometheusMetricsSchema(),
+			"storage_price_per_gb_month": {
+				Description: "Storage prices in USD per GB-month, keyed by volume type. Overrides the default prices for the given volume types.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeFloat},
+			},
+			"iops_price_per_month": {
+				Description: "Provisioned IOPS prices in USD per IOPS-month, keyed by volume type. Overrides the default prices for the given volume types.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeFloat},
+			},
+			"throughput_price_per_mibps_month": {
+				Description: "Provisioned throughput prices in USD per MiB/s-month, keyed by volume type. Overrides the default prices for the given volume types.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeFloat},
+			},
+			"use_pricing_api": {
+				Description: "Look up prices in the AWS Price List API for the region of the volumes.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"total_monthly_cost": {
+				Description: "The estimated total monthly cost in USD of the unattached volumes.",
+				Type:        schema.TypeFloat,
+				Computed:    true,
+			},
+			"volumes": {
+				Description: "The per-volume breakdown of the estimated monthly cost.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"volume_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"volume_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"availability_zone": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"iops": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"throughput": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"storage_cost": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+						"iops_cost": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+						"throughput_cost": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+						"monthly_cost": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+						"fingerprint": fingerprintSchema(ec2UnattachedVolumesFingerprintAttributes),
+					},
+				},
+			},
+		},
+	}
+}
+
+// ebsPrices holds the prices used to estimate the cost of EBS volumes, keyed by volume type.
+type ebsPrices struct {
+	storage    map[string]float64
+	iops       map[string]float64
+	throughput map[string]float64
+}
+
+func dataSourceAwsUtilsEc2UnattachedVolumesCostEstimateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &ec2.DescribeVolumesInput{
+		Filters: buildEC2AttributeFilterList(map[string]string{
+			"status": ec2.VolumeStateAvailable,
+		}),
+	}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeVolume)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.VolumeIds = ids
+	input.Filters = append(input.Filters, filters...)
+
+	// The status filter is always set, so only the selection filters are checked.
+	unfilteredWarnings, err := checkEC2Unfiltered(d, ids, filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var volumes []*ec2.Volume
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		volumes, err = finder.Volumes(conn, input, maxResultsCap(d, meta))
+		return err
+	})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Volumes: %s", maxResultsCapError(err))
+	}
+
+	excluded := buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")
+	tagsMatched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+	regexMatched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Volume)(nil))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	kept := volumes[:0]
+	for _, volume := range volumes {
+		if !excluded(volume.Tags) && tagsMatched(volume.Tags) && regexMatched(volume) {
+			kept = append(kept, volume)
+		}
+	}
+	volumes = kept
+
+	prices := ebsPrices{
+		storage:    mergeEbsPrices(defaultEbsStoragePricePerGbMonth, d.Get("storage_price_per_gb_month").(map[string]interface{})),
+		iops:       mergeEbsPrices(defaultEbsIopsPricePerMonth, d.Get("iops_price_per_month").(map[string]interface{})),
+		throughput: mergeEbsPrices(defaultEbsThroughputPricePerMibpsMonth, d.Get("throughput_price_per_mibps_month").(map[string]interface{})),
+	}
+
+	if d.Get("use_pricing_api").(bool) {
+		if err := lookupEbsPrices(meta.(*AWSClient).pricingconn, region, volumes, &prices); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	sort.Slice(volumes, func(i, j int) bool {
+		return aws.StringValue(volumes[i].VolumeId) < aws.StringValue(volumes[j].VolumeId)
+	})
+
+	var total float64
+	breakdown := make([]map[string]interface{}, 0, len(volumes))
+
+	for _, volume := range volumes {
+		storageCost, iopsCost, throughputCost := ebsVolumeMonthlyCost(volume, prices)
+		cost := storageCost + iopsCost + throughputCost
+		total += cost
+
+		breakdown = append(breakdown, map[string]interface{}{
+			"volume_id":         aws.StringValue(volume.VolumeId),
+			"volume_type":       aws.StringValue(volume.VolumeType),
+			"availability_zone": aws.StringValue(volume.AvailabilityZone),
+			"size":              int(aws.Int64Value(volume.Size)),
+			"iops":              int(aws.Int64Value(volume.Iops)),
+			"throughput":        int(aws.Int64Value(volume.Throughput)),
+			"storage_cost":      roundCost(storageCost),
+			"iops_cost":         roundCost(iopsCost),
+			"throughput_cost":   roundCost(throughputCost),
+			"monthly_cost":      roundCost(cost),
+		})
+	}
+
+	d.SetId(region)
+
+	if err := setEC2AppliedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2ResolvedFilters(d, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setEC2FiltersObject(d, meta, input.Filters); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := setRawResponseJSON(d, map[string]interface{}{"Volumes": volumes}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("total_monthly_cost", roundCost(total)); err != nil {
+		return diag.Errorf("error setting total_monthly_cost: %s", err)
+	}
+
+	if err := setFingerprints(breakdown, ec2UnattachedVolumesFingerprintAttributes); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("volumes", breakdown); err != nil {
+		return diag.Errorf("error setting volumes: %s", err)
+	}
+
+	if err := setPrometheusMetrics(d, ec2UnattachedVolumesPrometheusMetrics(breakdown)...); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return warnings
+}
+
+// ec2UnattachedVolumesPrometheusMetrics returns the awsutils_ec2_unattached_volumes_cost_estimate metric counting
+// the volumes of the given breakdown by type, and the awsutils_ec2_unattached_volumes_cost_estimate_monthly_cost
+// metric summing their estimated monthly cost by type.
+func ec2UnattachedVolumesPrometheusMetrics(breakdown []map[string]interface{}) []prometheusMetric {
+	counts := make(map[string]int)
+	costs := make(map[string]float64)
+	for _, volume := range breakdown {
+		volumeType := volume["volume_type"].(string)
+		counts[volumeType]++
+		costs[volumeType] += volume["monthly_cost"].(float64)
+	}
+
+	metrics := []prometheusMetric{
+		{Name: "awsutils_ec2_unattached_volumes_cost_estimate", Help: "The number of unattached EBS Volumes by type."},
+		{Name: "awsutils_ec2_unattached_volumes_cost_estimate_monthly_cost", Help: "The estimated monthly cost in USD of the unattached EBS Volumes by type."},
+	}
+
+	for volumeType, count := range counts {
+		labels := map[string]string{"volume_type": volumeType}
+		metrics[0].Samples = append(metrics[0].Samples, prometheusSample{Labels: labels, Value: float64(count)})
+		metrics[1].Samples = append(metrics[1].Samples, prometheusSample{Labels: labels, Value: roundCost(costs[volumeType])})
+	}
+
+	return metrics
+}
+
+// ebsVolumeMonthlyCost returns the estimated monthly storage, IOPS and throughput costs of the given volume.
+func ebsVolumeMonthlyCost(volume *ec2.Volume, prices ebsPrices) (float64, float64, float64) {
+	volumeType := aws.StringValue(volume.VolumeType)
+	storageCost := float64(aws.Int64Value(volume.Size)) * prices.storage[volumeType]
+
+	var iopsCost, throughputCost float64
+
+	switch volumeType {
+	case ec2.VolumeTypeGp3:
+		if iops := aws.Int64Value(volume.Iops); iops > ebsGp3BaselineIops {
+			iopsCost = float64(iops-ebsGp3BaselineIops) * prices.iops[volumeType]
+		}
+		if throughput := aws.Int64Value(volume.Throughput); throughput > ebsGp3BaselineThroughput {
+			throughputCost = float64(throughput-ebsGp3BaselineThroughput) * prices.throughput[volumeType]
+		}
+	case ec2.VolumeTypeIo1, ec2.VolumeTypeIo2:
+		iopsCost = float64(aws.Int64Value(volume.Iops)) * prices.iops[volumeType]
+	}
+
+	return storageCost, iopsCost, throughputCost
+}
+
+// mergeEbsPrices returns the given default prices overridden by the configured ones.
+func mergeEbsPrices(defaults map[string]float64, configured map[string]interface{}) map[string]float64 {
+	result := make(map[string]float64, len(defaults)+len(configured))
+
+	for k, v := range defaults {
+		result[k] = v
+	}
+
+	for k, v := range configured {
+		if f, ok := v.(float64); ok {
+			result[k] = f
+		}
+	}
+
+	return result
+}
+
+// lookupEbsPrices updates the given prices with those found in the AWS Price List API for the volume types
+// of the given volumes. Components without a published price keep their configured price.
+func lookupEbsPrices(conn *pricing.Pricing, region string, volumes []*ec2.Volume, prices *ebsPrices) error {
+	volumeTypes := make(map[string]bool)
+	for _, volume := range volumes {
+		volumeTypes[aws.StringValue(volume.VolumeType)] = true
+	}
+
+	for volumeType := range volumeTypes {
+		price, err := ebsPricingAPIPrice(conn, region, volumeType, map[string]string{"productFamily": "Storage"}, "GB-Mo")
+		if err != nil {
+			return err
+		}
+		if price != nil {
+			prices.storage[volumeType] = *price
+		}
+
+		if _, ok := defaultEbsIopsPricePerMonth[volumeType]; ok {
+			price, err := ebsPricingAPIPrice(conn, region, volumeType, map[string]string{"productFamily": "System Operation", "group": "EBS IOPS"}, "IOPS-Mo")
+			if err != nil {
+				return err
+			}
+			if price != nil {
+				prices.iops[volumeType] = *price
+			}
+		}
+
+		if _, ok := defaultEbsThroughputPricePerMibpsMonth[volumeType]; ok {
+			// Provisioned throughput is published per GiBps-month.
+			price, err := ebsPricingAPIPrice(conn, region, volumeType, map[string]string{"productFamily": "Provisioned Throughput"}, "GiBps-mo")
+			if err != nil {
+				return err
+			}
+			if price != nil {
+				prices.throughput[volumeType] = *price / 1024
+			}
+		}
+	}
+
+	return nil
+}
+
+// ebsPricingAPIPrice returns the on-demand USD price with the given unit of the EBS product matching the given
+// attributes, or nil if no such price is published.
+func ebsPricingAPIPrice(conn *pricing.Pricing, region, volumeType string, attributes map[string]string, unit string) (*float64, error) {
+	input := &pricing.GetProductsInput{
+		ServiceCode: aws.String("AmazonEC2"),
+		Filters: []*pricing.Filter{
+			{
+				Field: aws.String("regionCode"),
+				Type:  aws.String(pricing.FilterTypeTermMatch),
+				Value: aws.String(region),
+			},
+			{
+				Field: aws.String("volumeApiName"),
+				Type:  aws.String(pricing.FilterTypeTermMatch),
+				Value: aws.String(volumeType),
+			},
+		},
+	}
+
+	for field, value := range attributes {
+		input.Filters = append(input.Filters, &pricing.Filter{
+			Field: aws.String(field),
+			Type:  aws.String(pricing.FilterTypeTermMatch),
+			Value: aws.String(value),
+		})
+	}
+
+	var price *float64
+
+	err := conn.GetProductsPages(input, func(page *pricing.GetProductsOutput, lastPage bool) bool {
+		for _, product := range page.PriceList {
+			if price = onDemandUsdPrice(product, unit); price != nil {
+				return false
+			}
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading Pricing Products for EBS volume type (%s): %w", volumeType, err)
+	}
+
+	if price == nil {
+		log.Printf("[WARN] No %s price found in the Price List API for EBS volume type (%s) in region (%s)", unit, volumeType, region)
+	}
+
+	return price, nil
+}
+
+// onDemandUsdPrice extracts the first non-zero on-demand USD price with the given unit from a Price List API product.
+func onDemandUsdPrice(product aws.JSONValue, unit string) *float64 {
+	terms, _ := product["terms"].(map[string]interface{})
+	onDemand, _ := terms["OnDemand"].(map[string]interface{})
+
+	for _, offerI := range onDemand {
+		offer, _ := offerI.(map[string]interface{})
+		dimensions, _ := offer["priceDimensions"].(map[string]interface{})
+
+		for _, dimensionI := range dimensions {
+			dimension, _ := dimensionI.(map[string]interface{})
+			if u, _ := dimension["unit"].(string); u != unit {
+				continue
+			}
+
+			pricePerUnit, _ := dimension["pricePerUnit"].(map[string]interface{})
+			usd, _ := pricePerUnit["USD"].(string)
+
+			if f, err := strconv.ParseFloat(usd, 64); err == nil && f > 0 {
+				return aws.Float64(f)
+			}
+		}
+	}
+
+	return nil
+}
+
+// roundCost rounds the given cost to the nearest cent.
+func roundCost(cost float64) float64 {
+	return math.Round(cost*100) / 100
+}
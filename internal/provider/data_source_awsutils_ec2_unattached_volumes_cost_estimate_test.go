@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEbsVolumeMonthlyCost(t *testing.T) {
+	prices := ebsPrices{
+		storage:    defaultEbsStoragePricePerGbMonth,
+		iops:       defaultEbsIopsPricePerMonth,
+		throughput: defaultEbsThroughputPricePerMibpsMonth,
+	}
+
+	testCases := []struct {
+		Name               string
+		Volume             *ec2.Volume
+		ExpectedStorage    float64
+		ExpectedIops       float64
+		ExpectedThroughput float64
+	}{
+		{
+			Name: "gp2 storage only",
+			Volume: &ec2.Volume{
+				VolumeType: aws.String(ec2.VolumeTypeGp2),
+				Size:       aws.Int64(100),
+				Iops:       aws.Int64(300),
+			},
+			ExpectedStorage: 10,
+		},
+		{
+			Name: "gp3 baseline",
+			Volume: &ec2.Volume{
+				VolumeType: aws.String(ec2.VolumeTypeGp3),
+				Size:       aws.Int64(100),
+				Iops:       aws.Int64(3000),
+				Throughput: aws.Int64(125),
+			},
+			ExpectedStorage: 8,
+		},
+		{
+			Name: "gp3 provisioned above baseline",
+			Volume: &ec2.Volume{
+				VolumeType: aws.String(ec2.VolumeTypeGp3),
+				Size:       aws.Int64(100),
+				Iops:       aws.Int64(5000),
+				Throughput: aws.Int64(250),
+			},
+			ExpectedStorage:    8,
+			ExpectedIops:       10,
+			ExpectedThroughput: 5,
+		},
+		{
+			Name: "io2 all provisioned IOPS",
+			Volume: &ec2.Volume{
+				VolumeType: aws.String(ec2.VolumeTypeIo2),
+				Size:       aws.Int64(100),
+				Iops:       aws.Int64(1000),
+			},
+			ExpectedStorage: 12.5,
+			ExpectedIops:    65,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			storage, iops, throughput := ebsVolumeMonthlyCost(testCase.Volume, prices)
+
+			if roundCost(storage) != testCase.ExpectedStorage {
+				t.Errorf("got storage cost %f, expected %f", storage, testCase.ExpectedStorage)
+			}
+
+			if roundCost(iops) != testCase.ExpectedIops {
+				t.Errorf("got IOPS cost %f, expected %f", iops, testCase.ExpectedIops)
+			}
+
+			if roundCost(throughput) != testCase.ExpectedThroughput {
+				t.Errorf("got throughput cost %f, expected %f", throughput, testCase.ExpectedThroughput)
+			}
+		})
+	}
+}
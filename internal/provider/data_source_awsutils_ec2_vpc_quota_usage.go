@@ -0,0 +1,362 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/aws-sdk-go-base/tfawserr"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2VpcServiceQuotaCode is the Service Quotas service code of the VPC quotas.
+const ec2VpcServiceQuotaCode = "vpc"
+
+const (
+	ec2VpcQuotaLimitSourceServiceQuotas = "service_quotas"
+	ec2VpcQuotaLimitSourceDefault       = "default"
+)
+
+// ec2VpcQuota is a VPC quota reported by the awsutils_ec2_vpc_quota_usage data source.
+type ec2VpcQuota struct {
+	Name      string
+	QuotaCode string
+	// DefaultLimit is the default value of the quota, used when it cannot be read from Service Quotas.
+	DefaultLimit float64
+}
+
+// ec2VpcQuotas are the reported quotas, in the order they are reported.
+var ec2VpcQuotas = []ec2VpcQuota{
+	{Name: "vpcs_per_region", QuotaCode: "L-F678F1CE", DefaultLimit: 5},
+	{Name: "security_groups_per_vpc", QuotaCode: "L-E79EC296", DefaultLimit: 2500},
+	{Name: "rules_per_security_group", QuotaCode: "L-0EA8095F", DefaultLimit: 60},
+	{Name: "routes_per_route_table", QuotaCode: "L-93826ACB", DefaultLimit: 50},
+}
+
+// ec2VpcQuotaUsage is the current usage of a quota: the highest number of objects counted against it, and the ID of
+// the object, such as a VPC for security_groups_per_vpc, with that number.
+type ec2VpcQuotaUsage struct {
+	Current    int
+	ResourceID string
+}
+
+func dataSourceAwsUtilsEc2VpcQuotaUsage() *schema.Resource {
+	return &schema.Resource{
+		Description: `Reports the usage of the VPC quotas of the provider's region which most often cause outages when they are
+reached, against their limit.
+
+The usage is counted from the VPCs, Security Groups and Route Tables of the region, and the limits are read from
+Service Quotas, with at most ` + "`max_concurrency`" + ` requests in flight at any time. When Service Quotas cannot be read,
+because access to it is denied or the quota is not found, the default limit of the quota is reported instead, as
+shown by ` + "`limit_source`" + `. Quotas applying to each VPC, Security Group or Route Table report the highest usage
+among them, and the ID of the object with that usage.
+
+Rules are counted separately for each direction and IP version, as AWS enforces the quota. Rules referencing a
+Security Group or a prefix list count as one IPv4 and one IPv6 rule, although AWS counts prefix lists by their maximum
+number of entries, and propagated routes are not counted against the routes of a Route Table.`,
+		Read:          dataSourceAwsUtilsEc2VpcQuotaUsageRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"max_concurrency": {
+				Description:  "The maximum number of requests in flight at any time.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntBetween(1, 5),
+			},
+			"prometheus_metrics": prometheusMetricsSchema(),
+			"quotas": {
+				Description: "The usage of each quota: `vpcs_per_region`, `security_groups_per_vpc`, `rules_per_security_group` and `routes_per_route_table`.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"quota_code": {
+							Description: "The Service Quotas code of the quota.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"resource_id": {
+							Description: "The ID of the object with the highest usage, or the region for `vpcs_per_region`. Empty if there is no such object.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"current": {
+							Description: "The highest number of objects counted against the quota.",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"limit": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+						"limit_source": {
+							Description: "Where the limit was read from: `service_quotas`, or `default` if it could not be read.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"utilization": {
+							Description: "The ratio of `current` to `limit`, from 0 to 1 unless the quota is exceeded.",
+							Type:        schema.TypeFloat,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2VpcQuotaUsageRead(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*AWSClient).ec2conn
+	quotasconn := meta.(*AWSClient).servicequotasconn
+	region := meta.(*AWSClient).region
+
+	var vpcs []*ec2.Vpc
+	var securityGroups []*ec2.SecurityGroup
+	var routeTables []*ec2.RouteTable
+
+	funcs := []func() error{
+		func() (err error) {
+			if vpcs, err = finder.Vpcs(conn, &ec2.DescribeVpcsInput{}, 0); err != nil {
+				return fmt.Errorf("error reading EC2 VPCs: %w", err)
+			}
+			return nil
+		},
+		func() (err error) {
+			if securityGroups, err = finder.SecurityGroups(conn, &ec2.DescribeSecurityGroupsInput{}, 0); err != nil {
+				return fmt.Errorf("error reading EC2 Security Groups: %w", err)
+			}
+			return nil
+		},
+		func() (err error) {
+			if routeTables, err = finder.RouteTables(conn, &ec2.DescribeRouteTablesInput{}, 0); err != nil {
+				return fmt.Errorf("error reading EC2 Route Tables: %w", err)
+			}
+			return nil
+		},
+	}
+
+	var mu sync.Mutex
+	limits := make(map[string]float64, len(ec2VpcQuotas))
+	limitSources := make(map[string]string, len(ec2VpcQuotas))
+
+	for _, quota := range ec2VpcQuotas {
+		quota := quota
+		funcs = append(funcs, func() error {
+			limit, source, err := ec2VpcQuotaLimit(quotasconn, quota)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			limits[quota.Name] = limit
+			limitSources[quota.Name] = source
+
+			return nil
+		})
+	}
+
+	if err := runConcurrently(d.Get("max_concurrency").(int), funcs...); err != nil {
+		return err
+	}
+
+	usages := ec2VpcQuotaUsages(region, vpcs, securityGroups, routeTables)
+
+	d.SetId(region)
+
+	quotas := flattenEc2VpcQuotaUsages(usages, limits, limitSources)
+
+	if err := d.Set("quotas", quotas); err != nil {
+		return fmt.Errorf("error setting quotas: %w", err)
+	}
+
+	if err := setPrometheusMetrics(d, ec2VpcQuotaUsagePrometheusMetrics(quotas)...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ec2VpcQuotaUsagePrometheusMetrics returns the awsutils_ec2_vpc_quota_usage_current, _limit and _utilization
+// metrics of the given flattened "quotas", labelled with the name and code of each quota.
+func ec2VpcQuotaUsagePrometheusMetrics(quotas []interface{}) []prometheusMetric {
+	metrics := []prometheusMetric{
+		{Name: "awsutils_ec2_vpc_quota_usage_current", Help: "The highest number of objects counted against the VPC quota."},
+		{Name: "awsutils_ec2_vpc_quota_usage_limit", Help: "The limit of the VPC quota."},
+		{Name: "awsutils_ec2_vpc_quota_usage_utilization", Help: "The ratio of the usage of the VPC quota to its limit."},
+	}
+
+	for _, v := range quotas {
+		quota := v.(map[string]interface{})
+		labels := map[string]string{"name": quota["name"].(string), "quota_code": quota["quota_code"].(string)}
+
+		for i, value := range []float64{float64(quota["current"].(int)), quota["limit"].(float64), quota["utilization"].(float64)} {
+			metrics[i].Samples = append(metrics[i].Samples, prometheusSample{Labels: labels, Value: value})
+		}
+	}
+
+	return metrics
+}
+
+// ec2VpcQuotaLimit returns the applied value of the given VPC quota read from Service Quotas, or its default value if
+// access to Service Quotas is denied or the quota is not found, and the source of the returned value.
+func ec2VpcQuotaLimit(conn *servicequotas.ServiceQuotas, quota ec2VpcQuota) (float64, string, error) {
+	output, err := conn.GetServiceQuota(&servicequotas.GetServiceQuotaInput{
+		ServiceCode: aws.String(ec2VpcServiceQuotaCode),
+		QuotaCode:   aws.String(quota.QuotaCode),
+	})
+
+	if tfawserr.ErrCodeEquals(err, servicequotas.ErrCodeAccessDeniedException) || tfawserr.ErrCodeEquals(err, servicequotas.ErrCodeNoSuchResourceException) {
+		log.Printf("[WARN] Unable to read Service Quota (%s/%s), using its default value (%v): %s", ec2VpcServiceQuotaCode, quota.QuotaCode, quota.DefaultLimit, err)
+		return quota.DefaultLimit, ec2VpcQuotaLimitSourceDefault, nil
+	}
+
+	if err != nil {
+		return 0, "", fmt.Errorf("error reading Service Quota (%s/%s): %w", ec2VpcServiceQuotaCode, quota.QuotaCode, err)
+	}
+
+	if output == nil || output.Quota == nil || output.Quota.Value == nil {
+		return quota.DefaultLimit, ec2VpcQuotaLimitSourceDefault, nil
+	}
+
+	return aws.Float64Value(output.Quota.Value), ec2VpcQuotaLimitSourceServiceQuotas, nil
+}
+
+// ec2VpcQuotaUsages returns the usage of each of ec2VpcQuotas, by name, counted from the given VPCs, Security Groups
+// and Route Tables of the given region. Ties between objects are broken by lowest ID, so that the result does not
+// depend on the order of the objects.
+func ec2VpcQuotaUsages(region string, vpcs []*ec2.Vpc, securityGroups []*ec2.SecurityGroup, routeTables []*ec2.RouteTable) map[string]ec2VpcQuotaUsage {
+	usages := map[string]ec2VpcQuotaUsage{
+		"vpcs_per_region": {Current: len(vpcs), ResourceID: region},
+	}
+
+	securityGroupsPerVpc := make(map[string]int)
+	for _, securityGroup := range securityGroups {
+		if vpcID := aws.StringValue(securityGroup.VpcId); vpcID != "" {
+			securityGroupsPerVpc[vpcID]++
+		}
+	}
+	usages["security_groups_per_vpc"] = maxEc2VpcQuotaUsage(securityGroupsPerVpc)
+
+	rulesPerSecurityGroup := make(map[string]int)
+	for _, securityGroup := range securityGroups {
+		rulesPerSecurityGroup[aws.StringValue(securityGroup.GroupId)] = ec2SecurityGroupRuleCount(securityGroup)
+	}
+	usages["rules_per_security_group"] = maxEc2VpcQuotaUsage(rulesPerSecurityGroup)
+
+	routesPerRouteTable := make(map[string]int)
+	for _, routeTable := range routeTables {
+		routesPerRouteTable[aws.StringValue(routeTable.RouteTableId)] = ec2RouteTableRouteCount(routeTable)
+	}
+	usages["routes_per_route_table"] = maxEc2VpcQuotaUsage(routesPerRouteTable)
+
+	return usages
+}
+
+// maxEc2VpcQuotaUsage returns the highest of the given counts by object ID, with the lowest ID among ties.
+func maxEc2VpcQuotaUsage(counts map[string]int) ec2VpcQuotaUsage {
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var usage ec2VpcQuotaUsage
+	for _, id := range ids {
+		if usage.ResourceID == "" || counts[id] > usage.Current {
+			usage = ec2VpcQuotaUsage{Current: counts[id], ResourceID: id}
+		}
+	}
+
+	return usage
+}
+
+// ec2SecurityGroupRuleCount returns the number of rules of the given Security Group counted against the rules per
+// Security Group quota: the highest of its inbound and outbound IPv4 and IPv6 rules.
+func ec2SecurityGroupRuleCount(securityGroup *ec2.SecurityGroup) int {
+	var max int
+
+	for _, permissions := range [][]*ec2.IpPermission{securityGroup.IpPermissions, securityGroup.IpPermissionsEgress} {
+		var ipv4, ipv6 int
+
+		for _, permission := range permissions {
+			if permission == nil {
+				continue
+			}
+
+			// References to groups and prefix lists count against both IP versions.
+			references := len(permission.UserIdGroupPairs) + len(permission.PrefixListIds)
+			ipv4 += len(permission.IpRanges) + references
+			ipv6 += len(permission.Ipv6Ranges) + references
+		}
+
+		for _, count := range []int{ipv4, ipv6} {
+			if count > max {
+				max = count
+			}
+		}
+	}
+
+	return max
+}
+
+// ec2RouteTableRouteCount returns the number of routes of the given Route Table counted against the routes per
+// Route Table quota, which excludes the local routes and the propagated routes.
+func ec2RouteTableRouteCount(routeTable *ec2.RouteTable) int {
+	var count int
+
+	for _, route := range routeTable.Routes {
+		if route == nil {
+			continue
+		}
+
+		switch aws.StringValue(route.Origin) {
+		case ec2.RouteOriginCreateRouteTable, ec2.RouteOriginEnableVgwRoutePropagation:
+			continue
+		}
+
+		count++
+	}
+
+	return count
+}
+
+// flattenEc2VpcQuotaUsages returns the flattened "quotas" of the given usages and limits by quota name, in the order
+// of ec2VpcQuotas.
+func flattenEc2VpcQuotaUsages(usages map[string]ec2VpcQuotaUsage, limits map[string]float64, limitSources map[string]string) []interface{} {
+	result := make([]interface{}, 0, len(ec2VpcQuotas))
+
+	for _, quota := range ec2VpcQuotas {
+		usage := usages[quota.Name]
+		limit := limits[quota.Name]
+
+		var utilization float64
+		if limit > 0 {
+			utilization = float64(usage.Current) / limit
+		}
+
+		result = append(result, map[string]interface{}{
+			"name":         quota.Name,
+			"quota_code":   quota.QuotaCode,
+			"resource_id":  usage.ResourceID,
+			"current":      usage.Current,
+			"limit":        limit,
+			"limit_source": limitSources[quota.Name],
+			"utilization":  utilization,
+		})
+	}
+
+	return result
+}
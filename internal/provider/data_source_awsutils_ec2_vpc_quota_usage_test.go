@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+)
+
+func TestEc2VpcQuotaUsages(t *testing.T) {
+	vpcs := []*ec2.Vpc{
+		{VpcId: aws.String("vpc-00000001")},
+		{VpcId: aws.String("vpc-00000002")},
+	}
+
+	securityGroups := []*ec2.SecurityGroup{
+		{
+			GroupId: aws.String("sg-00000001"),
+			VpcId:   aws.String("vpc-00000001"),
+			IpPermissions: []*ec2.IpPermission{
+				{
+					IpRanges:   []*ec2.IpRange{{CidrIp: aws.String("10.0.0.0/16")}, {CidrIp: aws.String("10.1.0.0/16")}},
+					Ipv6Ranges: []*ec2.Ipv6Range{{CidrIpv6: aws.String("::/0")}},
+				},
+				{
+					UserIdGroupPairs: []*ec2.UserIdGroupPair{{GroupId: aws.String("sg-00000002")}},
+				},
+			},
+			IpPermissionsEgress: []*ec2.IpPermission{
+				{IpRanges: []*ec2.IpRange{{CidrIp: aws.String("0.0.0.0/0")}}},
+			},
+		},
+		{
+			GroupId: aws.String("sg-00000002"),
+			VpcId:   aws.String("vpc-00000002"),
+			IpPermissions: []*ec2.IpPermission{
+				{
+					IpRanges: []*ec2.IpRange{{CidrIp: aws.String("10.0.0.0/16")}},
+				},
+			},
+		},
+		{
+			GroupId: aws.String("sg-00000003"),
+			VpcId:   aws.String("vpc-00000002"),
+			IpPermissionsEgress: []*ec2.IpPermission{
+				{
+					Ipv6Ranges:    []*ec2.Ipv6Range{{CidrIpv6: aws.String("::/0")}, {CidrIpv6: aws.String("2001:db8::/32")}},
+					PrefixListIds: []*ec2.PrefixListId{{PrefixListId: aws.String("pl-00000001")}},
+				},
+			},
+		},
+	}
+
+	routeTables := []*ec2.RouteTable{
+		{
+			RouteTableId: aws.String("rtb-00000001"),
+			Routes: []*ec2.Route{
+				{DestinationCidrBlock: aws.String("10.0.0.0/16"), Origin: aws.String(ec2.RouteOriginCreateRouteTable)},
+				{DestinationCidrBlock: aws.String("0.0.0.0/0"), Origin: aws.String(ec2.RouteOriginCreateRoute)},
+				{DestinationCidrBlock: aws.String("192.168.0.0/16"), Origin: aws.String(ec2.RouteOriginEnableVgwRoutePropagation)},
+			},
+		},
+		{
+			RouteTableId: aws.String("rtb-00000002"),
+			Routes: []*ec2.Route{
+				{DestinationCidrBlock: aws.String("0.0.0.0/0"), Origin: aws.String(ec2.RouteOriginCreateRoute)},
+				{DestinationIpv6CidrBlock: aws.String("::/0"), Origin: aws.String(ec2.RouteOriginCreateRoute)},
+			},
+		},
+	}
+
+	expected := map[string]ec2VpcQuotaUsage{
+		"vpcs_per_region":          {Current: 2, ResourceID: "us-east-1"},
+		"security_groups_per_vpc":  {Current: 2, ResourceID: "vpc-00000002"},
+		"rules_per_security_group": {Current: 3, ResourceID: "sg-00000001"},
+		"routes_per_route_table":   {Current: 2, ResourceID: "rtb-00000002"},
+	}
+
+	got := ec2VpcQuotaUsages("us-east-1", vpcs, securityGroups, routeTables)
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestEc2VpcQuotaUsagesEmpty(t *testing.T) {
+	expected := map[string]ec2VpcQuotaUsage{
+		"vpcs_per_region":          {ResourceID: "us-east-1"},
+		"security_groups_per_vpc":  {},
+		"rules_per_security_group": {},
+		"routes_per_route_table":   {},
+	}
+
+	got := ec2VpcQuotaUsages("us-east-1", nil, nil, nil)
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestEc2VpcQuotaLimit(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Output         *servicequotas.GetServiceQuotaOutput
+		Error          error
+		ExpectedLimit  float64
+		ExpectedSource string
+		ExpectedError  bool
+	}{
+		{
+			Name: "applied quota",
+			Output: &servicequotas.GetServiceQuotaOutput{
+				Quota: &servicequotas.ServiceQuota{Value: aws.Float64(20)},
+			},
+			ExpectedLimit:  20,
+			ExpectedSource: ec2VpcQuotaLimitSourceServiceQuotas,
+		},
+		{
+			Name:           "access denied",
+			Error:          awserr.New(servicequotas.ErrCodeAccessDeniedException, "not authorized", nil),
+			ExpectedLimit:  5,
+			ExpectedSource: ec2VpcQuotaLimitSourceDefault,
+		},
+		{
+			Name:           "quota not found",
+			Error:          awserr.New(servicequotas.ErrCodeNoSuchResourceException, "not found", nil),
+			ExpectedLimit:  5,
+			ExpectedSource: ec2VpcQuotaLimitSourceDefault,
+		},
+		{
+			Name:          "other error",
+			Error:         awserr.New(servicequotas.ErrCodeIllegalArgumentException, "invalid", nil),
+			ExpectedError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			conn := servicequotas.New(testAWSSession(t))
+			testMockHandlers(&conn.Handlers, func(r *request.Request) {
+				if testCase.Error != nil {
+					r.Error = testCase.Error
+					return
+				}
+				*r.Data.(*servicequotas.GetServiceQuotaOutput) = *testCase.Output
+			})
+
+			limit, source, err := ec2VpcQuotaLimit(conn, ec2VpcQuotas[0])
+
+			if testCase.ExpectedError {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if limit != testCase.ExpectedLimit || source != testCase.ExpectedSource {
+				t.Errorf("got %v (%s), expected %v (%s)", limit, source, testCase.ExpectedLimit, testCase.ExpectedSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,440 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/aws-sdk-go-base/tfawserr"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAwsUtilsEc2VpcSummary() *schema.Resource {
+	return &schema.Resource{
+		Description: `Summarizes the topology of a VPC: its Subnets, Route Tables, Internet Gateways, NAT Gateways and Security
+Groups.
+
+The VPC is selected by ` + "`vpc_id`" + ` or by the given filters, which must match exactly one VPC. The child resources are
+then described in parallel, with at most ` + "`max_concurrency`" + ` requests in flight at any time.`,
+		ReadContext:   dataSourceAwsUtilsEc2VpcSummaryRead,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"vpc_id": {
+				Description: "The ID of the VPC to summarize. Either this or the filters must be given, unless `allow_unfiltered` is set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			"name":                      ec2NameSchema(),
+			"filter":                    ec2CustomFiltersSchema(),
+			"region":                    ec2RegionSchema(),
+			"tags":                      tagsSchema(),
+			"any_tag_keys":              ec2AnyTagKeysSchema(),
+			"has_tags":                  ec2HasTagsSchema(),
+			"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+			"required_tag_keys":         ec2RequiredTagKeysSchema(),
+			"filters_csv":               ec2FiltersCSVSchema(),
+			"filters_json":              ec2FiltersJSONSchema(),
+			"allow_unfiltered":          ec2AllowUnfilteredSchema(),
+			"max_concurrency": {
+				Description:  "The maximum number of describe requests in flight at any time.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntBetween(1, 5),
+			},
+			"cidr_block": {
+				Description: "The primary IPv4 CIDR block of the VPC.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"is_default": {
+				Description: "Whether the VPC is the default VPC of the region.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"subnets": {
+				Description: "The Subnets of the VPC, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"subnet_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"cidr_block": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"availability_zone": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"available_ip_address_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"map_public_ip_on_launch": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"route_table_id": {
+							Description: "The ID of the Route Table associated with the Subnet, or of the main Route Table if none is.",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"route_tables": {
+				Description: "The Route Tables of the VPC, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"route_table_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"main": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"subnet_ids": {
+							Description: "The IDs of the Subnets explicitly associated with the Route Table.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"internet_gateway_ids": {
+				Description: "The IDs of the Internet Gateways attached to the VPC.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"nat_gateways": {
+				Description: "The NAT Gateways of the VPC which are not deleted, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"nat_gateway_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subnet_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"connectivity_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"public_ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"security_groups": {
+				Description: "The Security Groups of the VPC, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"group_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"group_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceAwsUtilsEc2VpcSummaryRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn, region, err := ec2DataSourceConnForRegion(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	input := &ec2.DescribeVpcsInput{}
+	if v, ok := d.GetOk("vpc_id"); ok {
+		input.VpcIds = aws.StringSlice([]string{v.(string)})
+	}
+
+	_, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeVpc)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	input.Filters = filters
+
+	unfilteredWarnings, err := checkEC2Unfiltered(d, input.VpcIds, input.Filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	warnings = append(warnings, unfilteredWarnings...)
+
+	var vpcs []*ec2.Vpc
+	err = describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+		vpcs, err = finder.Vpcs(conn, input, 0)
+		return err
+	})
+	if tfawserr.ErrCodeEquals(err, tfec2.ErrCodeInvalidVpcIDNotFound) {
+		return diag.Errorf("EC2 VPC (%s) not found", d.Get("vpc_id").(string))
+	}
+	if err != nil {
+		return diag.Errorf("error reading EC2 VPCs: %s", err)
+	}
+
+	switch len(vpcs) {
+	case 0:
+		if meta.(*AWSClient).validateOnly {
+			// No VPC is found without calling the API, and so nothing to report on.
+			d.SetId(region)
+			return warnings
+		}
+		return diag.Errorf("no matching EC2 VPC found")
+	case 1:
+	default:
+		return diag.Errorf("%d EC2 VPCs matched; use additional constraints to reduce matches to a single VPC", len(vpcs))
+	}
+
+	vpc := vpcs[0]
+	vpcID := aws.StringValue(vpc.VpcId)
+	vpcFilter := buildEC2AttributeFilterList(map[string]string{"vpc-id": vpcID})
+
+	var subnets []*ec2.Subnet
+	var routeTables []*ec2.RouteTable
+	var internetGateways []*ec2.InternetGateway
+	var natGateways []*ec2.NatGateway
+	var securityGroups []*ec2.SecurityGroup
+
+	err = runConcurrently(d.Get("max_concurrency").(int),
+		func() error {
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				subnets, err = finder.Subnets(conn, &ec2.DescribeSubnetsInput{Filters: vpcFilter}, 0)
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EC2 Subnets for VPC (%s): %w", vpcID, err)
+			}
+			return nil
+		},
+		func() error {
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				routeTables, err = finder.RouteTables(conn, &ec2.DescribeRouteTablesInput{Filters: vpcFilter}, 0)
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EC2 Route Tables for VPC (%s): %w", vpcID, err)
+			}
+			return nil
+		},
+		func() error {
+			input := &ec2.DescribeInternetGatewaysInput{
+				Filters: buildEC2AttributeFilterList(map[string]string{"attachment.vpc-id": vpcID}),
+			}
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				internetGateways, err = finder.InternetGateways(conn, input)
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EC2 Internet Gateways for VPC (%s): %w", vpcID, err)
+			}
+			return nil
+		},
+		func() error {
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				natGateways, err = finder.NatGateways(conn, &ec2.DescribeNatGatewaysInput{Filter: vpcFilter})
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EC2 NAT Gateways for VPC (%s): %w", vpcID, err)
+			}
+			return nil
+		},
+		func() error {
+			if err := describeWithRetry(ctx, meta.(*AWSClient).describeMaxRetries, func() (err error) {
+				securityGroups, err = finder.SecurityGroups(conn, &ec2.DescribeSecurityGroupsInput{Filters: vpcFilter}, 0)
+				return err
+			}); err != nil {
+				return fmt.Errorf("error reading EC2 Security Groups for VPC (%s): %w", vpcID, err)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(vpcID)
+	d.Set("vpc_id", vpcID)
+	d.Set("cidr_block", vpc.CidrBlock)
+	d.Set("is_default", vpc.IsDefault)
+
+	if err := d.Set("subnets", flattenEc2VpcSummarySubnets(subnets, routeTables)); err != nil {
+		return diag.Errorf("error setting subnets: %s", err)
+	}
+
+	if err := d.Set("route_tables", flattenEc2VpcSummaryRouteTables(routeTables)); err != nil {
+		return diag.Errorf("error setting route_tables: %s", err)
+	}
+
+	internetGatewayIDs := make([]string, 0, len(internetGateways))
+	for _, internetGateway := range internetGateways {
+		internetGatewayIDs = append(internetGatewayIDs, aws.StringValue(internetGateway.InternetGatewayId))
+	}
+	sort.Strings(internetGatewayIDs)
+
+	if err := d.Set("internet_gateway_ids", internetGatewayIDs); err != nil {
+		return diag.Errorf("error setting internet_gateway_ids: %s", err)
+	}
+
+	if err := d.Set("nat_gateways", flattenEc2VpcSummaryNatGateways(natGateways)); err != nil {
+		return diag.Errorf("error setting nat_gateways: %s", err)
+	}
+
+	if err := d.Set("security_groups", flattenEc2VpcSummarySecurityGroups(securityGroups)); err != nil {
+		return diag.Errorf("error setting security_groups: %s", err)
+	}
+
+	return warnings
+}
+
+func flattenEc2VpcSummarySubnets(subnets []*ec2.Subnet, routeTables []*ec2.RouteTable) []interface{} {
+	var mainRouteTableID string
+	subnetRouteTableIDs := make(map[string]string)
+
+	for _, routeTable := range routeTables {
+		for _, association := range routeTable.Associations {
+			if aws.BoolValue(association.Main) {
+				mainRouteTableID = aws.StringValue(routeTable.RouteTableId)
+			}
+			if subnetID := aws.StringValue(association.SubnetId); subnetID != "" {
+				subnetRouteTableIDs[subnetID] = aws.StringValue(routeTable.RouteTableId)
+			}
+		}
+	}
+
+	sort.Slice(subnets, func(i, j int) bool {
+		return aws.StringValue(subnets[i].SubnetId) < aws.StringValue(subnets[j].SubnetId)
+	})
+
+	result := make([]interface{}, 0, len(subnets))
+	for _, subnet := range subnets {
+		routeTableID, ok := subnetRouteTableIDs[aws.StringValue(subnet.SubnetId)]
+		if !ok {
+			routeTableID = mainRouteTableID
+		}
+
+		result = append(result, map[string]interface{}{
+			"subnet_id":                  aws.StringValue(subnet.SubnetId),
+			"cidr_block":                 aws.StringValue(subnet.CidrBlock),
+			"availability_zone":          aws.StringValue(subnet.AvailabilityZone),
+			"available_ip_address_count": int(aws.Int64Value(subnet.AvailableIpAddressCount)),
+			"map_public_ip_on_launch":    aws.BoolValue(subnet.MapPublicIpOnLaunch),
+			"route_table_id":             routeTableID,
+		})
+	}
+
+	return result
+}
+
+func flattenEc2VpcSummaryRouteTables(routeTables []*ec2.RouteTable) []interface{} {
+	sort.Slice(routeTables, func(i, j int) bool {
+		return aws.StringValue(routeTables[i].RouteTableId) < aws.StringValue(routeTables[j].RouteTableId)
+	})
+
+	result := make([]interface{}, 0, len(routeTables))
+	for _, routeTable := range routeTables {
+		var main bool
+		var subnetIDs []string
+
+		for _, association := range routeTable.Associations {
+			if aws.BoolValue(association.Main) {
+				main = true
+			}
+			if subnetID := aws.StringValue(association.SubnetId); subnetID != "" {
+				subnetIDs = append(subnetIDs, subnetID)
+			}
+		}
+		sort.Strings(subnetIDs)
+
+		result = append(result, map[string]interface{}{
+			"route_table_id": aws.StringValue(routeTable.RouteTableId),
+			"main":           main,
+			"subnet_ids":     subnetIDs,
+		})
+	}
+
+	return result
+}
+
+func flattenEc2VpcSummaryNatGateways(natGateways []*ec2.NatGateway) []interface{} {
+	sort.Slice(natGateways, func(i, j int) bool {
+		return aws.StringValue(natGateways[i].NatGatewayId) < aws.StringValue(natGateways[j].NatGatewayId)
+	})
+
+	result := make([]interface{}, 0, len(natGateways))
+	for _, natGateway := range natGateways {
+		if aws.StringValue(natGateway.State) == ec2.NatGatewayStateDeleted {
+			continue
+		}
+
+		var publicIP string
+		for _, address := range natGateway.NatGatewayAddresses {
+			if v := aws.StringValue(address.PublicIp); v != "" {
+				publicIP = v
+				break
+			}
+		}
+
+		result = append(result, map[string]interface{}{
+			"nat_gateway_id":    aws.StringValue(natGateway.NatGatewayId),
+			"subnet_id":         aws.StringValue(natGateway.SubnetId),
+			"state":             aws.StringValue(natGateway.State),
+			"connectivity_type": aws.StringValue(natGateway.ConnectivityType),
+			"public_ip":         publicIP,
+		})
+	}
+
+	return result
+}
+
+func flattenEc2VpcSummarySecurityGroups(securityGroups []*ec2.SecurityGroup) []interface{} {
+	sort.Slice(securityGroups, func(i, j int) bool {
+		return aws.StringValue(securityGroups[i].GroupId) < aws.StringValue(securityGroups[j].GroupId)
+	})
+
+	result := make([]interface{}, 0, len(securityGroups))
+	for _, securityGroup := range securityGroups {
+		result = append(result, map[string]interface{}{
+			"group_id":    aws.StringValue(securityGroup.GroupId),
+			"group_name":  aws.StringValue(securityGroup.GroupName),
+			"description": aws.StringValue(securityGroup.Description),
+		})
+	}
+
+	return result
+}
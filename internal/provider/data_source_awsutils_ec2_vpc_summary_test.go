@@ -0,0 +1,239 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceAwsUtilsEc2VpcSummaryReadSelection(t *testing.T) {
+	vpc := func(id string) *ec2.Vpc {
+		return &ec2.Vpc{VpcId: aws.String(id), CidrBlock: aws.String("10.0.0.0/16")}
+	}
+
+	testCases := []struct {
+		Name          string
+		Config        map[string]interface{}
+		Vpcs          []*ec2.Vpc
+		Error         error
+		ExpectedError string
+	}{
+		{
+			Name:          "vpc_id not found",
+			Config:        map[string]interface{}{"vpc_id": "vpc-00000001"},
+			Error:         awserr.New(tfec2.ErrCodeInvalidVpcIDNotFound, "The vpc ID 'vpc-00000001' does not exist", nil),
+			ExpectedError: "EC2 VPC (vpc-00000001) not found",
+		},
+		{
+			Name:          "no filter match",
+			Config:        map[string]interface{}{"tags": map[string]interface{}{"Environment": "production"}},
+			ExpectedError: "no matching EC2 VPC found",
+		},
+		{
+			Name:          "several filter matches",
+			Config:        map[string]interface{}{"tags": map[string]interface{}{"Environment": "production"}},
+			Vpcs:          []*ec2.Vpc{vpc("vpc-00000001"), vpc("vpc-00000002")},
+			ExpectedError: "2 EC2 VPCs matched; use additional constraints to reduce matches to a single VPC",
+		},
+		{
+			Name:          "no selection",
+			Config:        map[string]interface{}{},
+			ExpectedError: errEC2Unfiltered.Error(),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var operations []string
+
+			conn := testEc2Conn(t, func(r *request.Request) {
+				operations = append(operations, r.Operation.Name)
+
+				if output, ok := r.Data.(*ec2.DescribeVpcsOutput); ok {
+					if testCase.Error != nil {
+						r.Error = testCase.Error
+						return
+					}
+					output.Vpcs = testCase.Vpcs
+				}
+			})
+
+			d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2VpcSummary().Schema, testCase.Config)
+			diags := dataSourceAwsUtilsEc2VpcSummaryRead(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-east-1"})
+			if !diags.HasError() || diags[0].Summary != testCase.ExpectedError {
+				t.Fatalf("got %v, expected %q", diags, testCase.ExpectedError)
+			}
+
+			// The child resources are only described once a single VPC is selected.
+			for _, operation := range operations {
+				if operation != "DescribeVpcs" {
+					t.Errorf("got a %s request, expected only DescribeVpcs", operation)
+				}
+			}
+		})
+	}
+}
+
+func TestDataSourceAwsUtilsEc2VpcSummaryRead(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	vpcFilters := make(map[string][]*ec2.Filter)
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		if _, ok := r.Data.(*ec2.DescribeVpcsOutput); !ok {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			// Slow enough for a request above max_concurrency to be sent.
+			time.Sleep(10 * time.Millisecond)
+
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+		}
+
+		record := func(filters []*ec2.Filter) {
+			mu.Lock()
+			vpcFilters[r.Operation.Name] = filters
+			mu.Unlock()
+		}
+
+		switch output := r.Data.(type) {
+		case *ec2.DescribeVpcsOutput:
+			output.Vpcs = []*ec2.Vpc{{VpcId: aws.String("vpc-00000001"), CidrBlock: aws.String("10.0.0.0/16"), IsDefault: aws.Bool(false)}}
+		case *ec2.DescribeSubnetsOutput:
+			record(r.Params.(*ec2.DescribeSubnetsInput).Filters)
+			output.Subnets = []*ec2.Subnet{
+				{SubnetId: aws.String("subnet-00000002"), CidrBlock: aws.String("10.0.2.0/24"), AvailabilityZone: aws.String("us-east-1b"), AvailableIpAddressCount: aws.Int64(250)},
+				{SubnetId: aws.String("subnet-00000001"), CidrBlock: aws.String("10.0.1.0/24"), AvailabilityZone: aws.String("us-east-1a"), AvailableIpAddressCount: aws.Int64(200), MapPublicIpOnLaunch: aws.Bool(true)},
+			}
+		case *ec2.DescribeRouteTablesOutput:
+			record(r.Params.(*ec2.DescribeRouteTablesInput).Filters)
+			output.RouteTables = []*ec2.RouteTable{
+				{RouteTableId: aws.String("rtb-00000002"), Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String("subnet-00000001")}}},
+				{RouteTableId: aws.String("rtb-00000001"), Associations: []*ec2.RouteTableAssociation{{Main: aws.Bool(true)}}},
+			}
+		case *ec2.DescribeInternetGatewaysOutput:
+			record(r.Params.(*ec2.DescribeInternetGatewaysInput).Filters)
+			output.InternetGateways = []*ec2.InternetGateway{{InternetGatewayId: aws.String("igw-00000001")}}
+		case *ec2.DescribeNatGatewaysOutput:
+			record(r.Params.(*ec2.DescribeNatGatewaysInput).Filter)
+			output.NatGateways = []*ec2.NatGateway{
+				{
+					NatGatewayId:        aws.String("nat-00000001"),
+					SubnetId:            aws.String("subnet-00000001"),
+					State:               aws.String(ec2.NatGatewayStateAvailable),
+					ConnectivityType:    aws.String(ec2.ConnectivityTypePublic),
+					NatGatewayAddresses: []*ec2.NatGatewayAddress{{PublicIp: aws.String("203.0.113.1")}},
+				},
+				{NatGatewayId: aws.String("nat-00000002"), State: aws.String(ec2.NatGatewayStateDeleted)},
+			}
+		case *ec2.DescribeSecurityGroupsOutput:
+			record(r.Params.(*ec2.DescribeSecurityGroupsInput).Filters)
+			output.SecurityGroups = []*ec2.SecurityGroup{
+				{GroupId: aws.String("sg-00000001"), GroupName: aws.String("default"), Description: aws.String("default VPC security group")},
+			}
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2VpcSummary().Schema, map[string]interface{}{
+		"tags":            map[string]interface{}{"Environment": "production"},
+		"max_concurrency": 2,
+	})
+	if diags := dataSourceAwsUtilsEc2VpcSummaryRead(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-east-1"}); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if maxInFlight > 2 {
+		t.Errorf("got %d requests in flight, expected at most max_concurrency (2)", maxInFlight)
+	}
+
+	expectedFilters := map[string][]*ec2.Filter{
+		"DescribeSubnets":          {{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-00000001"})}},
+		"DescribeRouteTables":      {{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-00000001"})}},
+		"DescribeInternetGateways": {{Name: aws.String("attachment.vpc-id"), Values: aws.StringSlice([]string{"vpc-00000001"})}},
+		"DescribeNatGateways":      {{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-00000001"})}},
+		"DescribeSecurityGroups":   {{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-00000001"})}},
+	}
+	if !reflect.DeepEqual(vpcFilters, expectedFilters) {
+		t.Errorf("got filters %v, expected %v", vpcFilters, expectedFilters)
+	}
+
+	if d.Id() != "vpc-00000001" || d.Get("vpc_id").(string) != "vpc-00000001" {
+		t.Errorf("got ID %s and vpc_id %s, expected vpc-00000001", d.Id(), d.Get("vpc_id"))
+	}
+	if got := d.Get("cidr_block").(string); got != "10.0.0.0/16" {
+		t.Errorf("got cidr_block %s, expected 10.0.0.0/16", got)
+	}
+
+	// subnet-00000002 has no explicit association, and so uses the main Route Table.
+	expectedSubnets := []interface{}{
+		map[string]interface{}{
+			"subnet_id":                  "subnet-00000001",
+			"cidr_block":                 "10.0.1.0/24",
+			"availability_zone":          "us-east-1a",
+			"available_ip_address_count": 200,
+			"map_public_ip_on_launch":    true,
+			"route_table_id":             "rtb-00000002",
+		},
+		map[string]interface{}{
+			"subnet_id":                  "subnet-00000002",
+			"cidr_block":                 "10.0.2.0/24",
+			"availability_zone":          "us-east-1b",
+			"available_ip_address_count": 250,
+			"map_public_ip_on_launch":    false,
+			"route_table_id":             "rtb-00000001",
+		},
+	}
+	if got := d.Get("subnets").([]interface{}); !reflect.DeepEqual(got, expectedSubnets) {
+		t.Errorf("got subnets %v, expected %v", got, expectedSubnets)
+	}
+
+	expectedRouteTables := []interface{}{
+		map[string]interface{}{"route_table_id": "rtb-00000001", "main": true, "subnet_ids": []interface{}{}},
+		map[string]interface{}{"route_table_id": "rtb-00000002", "main": false, "subnet_ids": []interface{}{"subnet-00000001"}},
+	}
+	if got := d.Get("route_tables").([]interface{}); !reflect.DeepEqual(got, expectedRouteTables) {
+		t.Errorf("got route_tables %v, expected %v", got, expectedRouteTables)
+	}
+
+	if got, expected := d.Get("internet_gateway_ids").([]interface{}), []interface{}{"igw-00000001"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got internet_gateway_ids %v, expected %v", got, expected)
+	}
+
+	// The deleted NAT Gateway is left out.
+	expectedNatGateways := []interface{}{
+		map[string]interface{}{
+			"nat_gateway_id":    "nat-00000001",
+			"subnet_id":         "subnet-00000001",
+			"state":             ec2.NatGatewayStateAvailable,
+			"connectivity_type": ec2.ConnectivityTypePublic,
+			"public_ip":         "203.0.113.1",
+		},
+	}
+	if got := d.Get("nat_gateways").([]interface{}); !reflect.DeepEqual(got, expectedNatGateways) {
+		t.Errorf("got nat_gateways %v, expected %v", got, expectedNatGateways)
+	}
+
+	expectedSecurityGroups := []interface{}{
+		map[string]interface{}{"group_id": "sg-00000001", "group_name": "default", "description": "default VPC security group"},
+	}
+	if got := d.Get("security_groups").([]interface{}); !reflect.DeepEqual(got, expectedSecurityGroups) {
+		t.Errorf("got security_groups %v, expected %v", got, expectedSecurityGroups)
+	}
+}
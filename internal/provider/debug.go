@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// rawResponseRedacted replaces the values of the sensitive fields in raw_response_json.
+const rawResponseRedacted = "REDACTED"
+
+// rawResponseSensitiveFields are the lowercased names of the API response fields whose values are never stored in
+// raw_response_json, such as the user data of launch templates.
+var rawResponseSensitiveFields = map[string]bool{
+	"accesskeyid":     true,
+	"keymaterial":     true,
+	"password":        true,
+	"passworddata":    true,
+	"privatekey":      true,
+	"secretaccesskey": true,
+	"sessiontoken":    true,
+	"userdata":        true,
+}
+
+// debugSchema returns a *schema.Schema for the "debug" attribute of data
+// sources, opting into raw_response_json.
+func debugSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Whether to store the API response the results are computed from in `raw_response_json`, to diagnose unexpected results.",
+	}
+}
+
+// rawResponseJSONSchema returns a *schema.Schema for the computed
+// "raw_response_json" attribute, set by setRawResponseJSON.
+func rawResponseJSONSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "When `debug` is set, the JSON-encoded API response, with the objects of all result pages and the values of sensitive fields such as `UserData` redacted. Empty otherwise.",
+	}
+}
+
+// setRawResponseJSON sets the raw_response_json attribute of the given
+// *schema.ResourceData to the JSON encoding of the given response, such as
+// map[string]interface{}{"Instances": instances} for the instances of all
+// result pages, with the sensitive fields redacted, if debug is set, and to
+// the empty string otherwise so as not to bloat the state.
+func setRawResponseJSON(d *schema.ResourceData, response interface{}) error {
+	var rawResponseJSON string
+
+	if d.Get("debug").(bool) {
+		var err error
+		if rawResponseJSON, err = redactedResponseJSON(response); err != nil {
+			return fmt.Errorf("error encoding raw_response_json: %w", err)
+		}
+	}
+
+	if err := d.Set("raw_response_json", rawResponseJSON); err != nil {
+		return fmt.Errorf("error setting raw_response_json: %w", err)
+	}
+
+	return nil
+}
+
+// redactedResponseJSON returns the JSON encoding of the given response, with
+// the values of the sensitive fields at any depth replaced. The keys of the
+// objects are sorted, so the encoding is deterministic.
+func redactedResponseJSON(response interface{}) (string, error) {
+	b, err := json.Marshal(response)
+	if err != nil {
+		return "", err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return "", err
+	}
+
+	b, err = json.Marshal(redactResponseFields(decoded))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// redactResponseFields replaces the values of the sensitive fields of the
+// given decoded JSON value, at any depth.
+func redactResponseFields(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if rawResponseSensitiveFields[strings.ToLower(key)] {
+				v[key] = rawResponseRedacted
+				continue
+			}
+			v[key] = redactResponseFields(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactResponseFields(value)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRedactedResponseJSON(t *testing.T) {
+	response := map[string]interface{}{
+		"LaunchTemplateVersions": []*ec2.LaunchTemplateVersion{
+			{
+				VersionNumber: aws.Int64(1),
+				LaunchTemplateData: &ec2.ResponseLaunchTemplateData{
+					ImageId:  aws.String("ami-01234567"),
+					UserData: aws.String("IyEvYmluL2Jhc2gK"),
+				},
+			},
+			{
+				VersionNumber: aws.Int64(2),
+			},
+		},
+	}
+
+	got, err := redactedResponseJSON(response)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if strings.Contains(got, "IyEvYmluL2Jhc2gK") {
+		t.Errorf("expected the user data to be redacted, got %s", got)
+	}
+
+	for _, expected := range []string{`"UserData":"REDACTED"`, `"ImageId":"ami-01234567"`, `"VersionNumber":2`} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("expected %s in %s", expected, got)
+		}
+	}
+
+	again, err := redactedResponseJSON(response)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if again != got {
+		t.Errorf("expected a deterministic encoding, got %s and %s", got, again)
+	}
+}
+
+func TestRedactResponseFields(t *testing.T) {
+	got, err := redactedResponseJSON(map[string]interface{}{
+		"Items": []interface{}{
+			map[string]interface{}{"Id": "a", "PasswordData": "secret", "Nested": map[string]interface{}{"privateKey": "secret"}},
+		},
+		"SessionToken": "secret",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := `{"Items":[{"Id":"a","Nested":{"privateKey":"REDACTED"},"PasswordData":"REDACTED"}],"SessionToken":"REDACTED"}`; got != expected {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+}
+
+func TestSetRawResponseJSON(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"debug":             debugSchema(),
+		"raw_response_json": rawResponseJSONSchema(),
+	}
+	response := map[string]interface{}{"Items": []string{"a", "b"}}
+
+	for _, testCase := range []struct {
+		Debug    bool
+		Expected string
+	}{
+		{false, ""},
+		{true, `{"Items":["a","b"]}`},
+	} {
+		d := schema.TestResourceDataRaw(t, s, map[string]interface{}{"debug": testCase.Debug})
+
+		if err := setRawResponseJSON(d, response); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if got := d.Get("raw_response_json").(string); got != testCase.Expected {
+			t.Errorf("got %s with debug %t, expected %s", got, testCase.Debug, testCase.Expected)
+		}
+	}
+}
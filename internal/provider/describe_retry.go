@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// defaultDescribeMaxRetries is the default of the provider's describe_max_retries, the maximum number of times the
+// "Describe..." calls of the data sources are retried by describeWithRetry.
+const defaultDescribeMaxRetries = 5
+
+// describeRetryMinDelay and describeRetryMaxDelay bound the delay between the attempts of describeWithRetry, which
+// doubles after each attempt. They are variables so that tests can shorten them.
+var (
+	describeRetryMinDelay = 1 * time.Second
+	describeRetryMaxDelay = 30 * time.Second
+)
+
+// describeWithRetry calls the given function, a "Describe..." call made through the finders, and calls it again up
+// to maxRetries times while it fails with a throttling error, such as RequestLimitExceeded, or another transient
+// error, such as a 5xx response, as told by describeRetryable. Other errors, such as the InvalidParameterValue of a
+// bad filter, are returned right away.
+//
+// The retries come on top of those of the retryer of the AWS SDK, bounded by the provider's max_retries, once it
+// gave up, and restart the call from its first result page. The delay between attempts grows exponentially, with
+// jitter, from describeRetryMinDelay up to describeRetryMaxDelay. It is an error for the given context to be done
+// while waiting, in which case the last error of the call is wrapped in it.
+func describeWithRetry(ctx context.Context, maxRetries int, describe func() error) error {
+	delay := describeRetryMinDelay
+
+	for retry := 1; ; retry++ {
+		err := describe()
+		if err == nil || retry > maxRetries || !describeRetryable(err) {
+			return err
+		}
+
+		// Half of the delay is random, so that concurrent data sources do not retry in lockstep.
+		wait := delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		log.Printf("[WARN] Retrying in %s (%d/%d): %s", wait, retry, maxRetries, err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w, giving up on retrying: %s", ctx.Err(), err)
+		case <-timer.C:
+		}
+
+		if delay *= 2; delay > describeRetryMaxDelay {
+			delay = describeRetryMaxDelay
+		}
+	}
+}
+
+// describeRetryable returns whether the given error, possibly wrapping an AWS error, is worth retrying the call
+// for: a throttling error, an error the AWS SDK retries, such as a timeout, or a 5xx response. Errors which are not
+// AWS errors, such as a *finder.MaxResultsExceededError, are not.
+func describeRetryable(err error) bool {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+
+	if request.IsErrorThrottle(awsErr) || request.IsErrorRetryable(awsErr) {
+		return true
+	}
+
+	var requestFailure awserr.RequestFailure
+	return errors.As(err, &requestFailure) && requestFailure.StatusCode() >= http.StatusInternalServerError
+}
@@ -0,0 +1,260 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/aws-sdk-go-base/tfawserr"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testDescribeRetryShortDelays shortens the delays of describeWithRetry for the duration of the test.
+func testDescribeRetryShortDelays(t *testing.T) {
+	minDelay, maxDelay := describeRetryMinDelay, describeRetryMaxDelay
+	describeRetryMinDelay, describeRetryMaxDelay = time.Millisecond, 2*time.Millisecond
+	t.Cleanup(func() {
+		describeRetryMinDelay, describeRetryMaxDelay = minDelay, maxDelay
+	})
+}
+
+// testDescribeRetryConn returns an EC2 client whose DescribeSubnets calls fail with the given errors, in turn,
+// then succeed, counting the calls in the given counter.
+func testDescribeRetryConn(t *testing.T, calls *int, errs ...error) *ec2.EC2 {
+	conn := testEc2Conn(t, func(r *request.Request) {
+		*calls++
+		if *calls <= len(errs) {
+			r.Error = errs[*calls-1]
+			return
+		}
+		r.Data.(*ec2.DescribeSubnetsOutput).Subnets = []*ec2.Subnet{{SubnetId: aws.String("subnet-00000001")}}
+	})
+
+	return conn
+}
+
+func testDescribeRetryThrottlingError() error {
+	return awserr.NewRequestFailure(awserr.New("RequestLimitExceeded", "Request limit exceeded.", nil), http.StatusServiceUnavailable, "")
+}
+
+func TestDescribeWithRetryThrottling(t *testing.T) {
+	testDescribeRetryShortDelays(t)
+
+	var calls int
+	conn := testDescribeRetryConn(t, &calls, testDescribeRetryThrottlingError(), testDescribeRetryThrottlingError())
+
+	var subnets []*ec2.Subnet
+	err := describeWithRetry(context.Background(), 3, func() (err error) {
+		subnets, err = finder.Subnets(conn, &ec2.DescribeSubnetsInput{}, 0)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("got %d calls, expected 3", calls)
+	}
+	if len(subnets) != 1 {
+		t.Errorf("got %d subnets, expected 1", len(subnets))
+	}
+}
+
+func TestDescribeWithRetryPermanentError(t *testing.T) {
+	testDescribeRetryShortDelays(t)
+
+	var calls int
+	conn := testDescribeRetryConn(t, &calls,
+		awserr.NewRequestFailure(awserr.New("InvalidParameterValue", "The filter 'foo' is invalid", nil), http.StatusBadRequest, ""),
+	)
+
+	err := describeWithRetry(context.Background(), 3, func() error {
+		_, err := finder.Subnets(conn, &ec2.DescribeSubnetsInput{}, 0)
+		return err
+	})
+	if !tfawserr.ErrCodeEquals(err, "InvalidParameterValue") {
+		t.Errorf("expected an InvalidParameterValue error, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("got %d calls, expected 1", calls)
+	}
+}
+
+func TestDescribeWithRetryExhausted(t *testing.T) {
+	testDescribeRetryShortDelays(t)
+
+	var calls int
+	conn := testDescribeRetryConn(t, &calls, testDescribeRetryThrottlingError(), testDescribeRetryThrottlingError(), testDescribeRetryThrottlingError())
+
+	err := describeWithRetry(context.Background(), 1, func() error {
+		_, err := finder.Subnets(conn, &ec2.DescribeSubnetsInput{}, 0)
+		return err
+	})
+	if !tfawserr.ErrCodeEquals(err, "RequestLimitExceeded") {
+		t.Errorf("expected a RequestLimitExceeded error, got %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("got %d calls, expected 2", calls)
+	}
+}
+
+func TestDescribeWithRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls int
+	err := describeWithRetry(ctx, 3, func() error {
+		calls++
+		return testDescribeRetryThrottlingError()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("got %d calls, expected 1", calls)
+	}
+}
+
+func TestDescribeRetryable(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Err      error
+		Expected bool
+	}{
+		{
+			Name:     "throttling",
+			Err:      testDescribeRetryThrottlingError(),
+			Expected: true,
+		},
+		{
+			Name:     "wrapped throttling",
+			Err:      fmt.Errorf("error reading EC2 Subnets: %w", awserr.New("EC2ThrottledException", "", nil)),
+			Expected: true,
+		},
+		{
+			Name:     "server error",
+			Err:      awserr.NewRequestFailure(awserr.New("InternalError", "An internal error has occurred", nil), http.StatusInternalServerError, ""),
+			Expected: true,
+		},
+		{
+			Name: "invalid filter",
+			Err:  awserr.NewRequestFailure(awserr.New("InvalidParameterValue", "The filter 'foo' is invalid", nil), http.StatusBadRequest, ""),
+		},
+		{
+			Name: "not an AWS error",
+			Err:  &finder.MaxResultsExceededError{MaxResults: 10},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := describeRetryable(testCase.Err); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestDataSourceAwsUtilsEc2SubnetsReadThrottled(t *testing.T) {
+	testDescribeRetryShortDelays(t)
+
+	var calls int
+	conn := testDescribeRetryConn(t, &calls, testDescribeRetryThrottlingError(), testDescribeRetryThrottlingError())
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2Subnets().Schema, map[string]interface{}{
+		"vpc_id": "vpc-00000001",
+	})
+
+	client := &AWSClient{ec2conn: conn, region: "us-east-1", maxResultsCap: defaultMaxResultsCap, describeMaxRetries: defaultDescribeMaxRetries}
+	if diags := dataSourceAwsUtilsEc2SubnetsRead(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if calls != 3 {
+		t.Errorf("got %d calls, expected 3", calls)
+	}
+	if got := len(d.Get("subnets").([]interface{})); got != 1 {
+		t.Errorf("got %d subnets, expected 1", got)
+	}
+}
+
+func TestDataSourceAwsUtilsEc2SubnetsReadCanceled(t *testing.T) {
+	var calls int
+	conn := testDescribeRetryConn(t, &calls, testDescribeRetryThrottlingError())
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2Subnets().Schema, map[string]interface{}{
+		"vpc_id": "vpc-00000001",
+	})
+
+	// The retry delays are left as is, the canceled context of the read ending the wait right away.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := &AWSClient{ec2conn: conn, region: "us-east-1", maxResultsCap: defaultMaxResultsCap, describeMaxRetries: defaultDescribeMaxRetries}
+	diags := dataSourceAwsUtilsEc2SubnetsRead(ctx, d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, context.Canceled.Error()) {
+		t.Fatalf("got %v, expected the read to be canceled", diags)
+	}
+
+	if calls != 1 {
+		t.Errorf("got %d calls, expected 1", calls)
+	}
+}
+
+func TestDataSourceAwsUtilsEc2VpcSummaryReadThrottled(t *testing.T) {
+	testDescribeRetryShortDelays(t)
+
+	// Each of the Describe calls is throttled once before succeeding.
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	conn := testEc2Conn(t, func(r *request.Request) {
+		mu.Lock()
+		calls[r.Operation.Name]++
+		throttled := calls[r.Operation.Name] == 1
+		mu.Unlock()
+
+		if throttled {
+			r.Error = testDescribeRetryThrottlingError()
+			return
+		}
+
+		if output, ok := r.Data.(*ec2.DescribeVpcsOutput); ok {
+			output.Vpcs = []*ec2.Vpc{{VpcId: aws.String("vpc-00000001"), CidrBlock: aws.String("10.0.0.0/16")}}
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2VpcSummary().Schema, map[string]interface{}{
+		"vpc_id": "vpc-00000001",
+	})
+
+	client := &AWSClient{ec2conn: conn, region: "us-east-1", describeMaxRetries: defaultDescribeMaxRetries}
+	if diags := dataSourceAwsUtilsEc2VpcSummaryRead(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	expected := map[string]int{
+		"DescribeVpcs":             2,
+		"DescribeSubnets":          2,
+		"DescribeRouteTables":      2,
+		"DescribeInternetGateways": 2,
+		"DescribeNatGateways":      2,
+		"DescribeSecurityGroups":   2,
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("got calls %v, expected %v", calls, expected)
+	}
+}
@@ -0,0 +1,310 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ec2AppliedFiltersSchema returns a *schema.Schema for the computed
+// "applied_filters" attribute of data sources, reporting the filters sent to
+// the EC2 API once all the selection attributes have been converted, as set
+// by setEC2AppliedFilters.
+func ec2AppliedFiltersSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The filters sent to the EC2 API, as converted from the selection attributes, to diagnose unexpected results.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"values": {
+					Type:        schema.TypeList,
+					Computed:    true,
+					Description: "The values of the filter, as sent, with the wildcards escaped if they are matched literally.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"value": {
+								Type:     schema.TypeString,
+								Computed: true,
+							},
+							"wildcard": {
+								Type:        schema.TypeBool,
+								Computed:    true,
+								Description: "Whether the value is interpreted as a pattern, having an unescaped `*` or `?`.",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// setEC2AppliedFilters sets the applied_filters attribute of the given
+// *schema.ResourceData to the given filters, in the order they are sent, and
+// logs them.
+func setEC2AppliedFilters(d *schema.ResourceData, filters []*ec2.Filter) error {
+	log.Printf("[DEBUG] Applied EC2 filters: %s", formatEC2Filters(filters))
+
+	if err := d.Set("applied_filters", flattenEC2AppliedFilters(filters)); err != nil {
+		return fmt.Errorf("error setting applied_filters: %w", err)
+	}
+
+	return nil
+}
+
+// ec2ResolvedFiltersSchema returns a *schema.Schema for the computed
+// "resolved_filters" attribute of data sources, reporting the filters sent to
+// the EC2 API in the shape of the "filter" blocks, as set by
+// setEC2ResolvedFilters.
+func ec2ResolvedFiltersSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The filters sent to the EC2 API once merged from the selection attributes, in the shape of the `filter` blocks.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"values": {
+					Type:     schema.TypeSet,
+					Computed: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+// setEC2ResolvedFilters sets the resolved_filters attribute of the given
+// *schema.ResourceData to the given filters, in the order they are sent.
+func setEC2ResolvedFilters(d *schema.ResourceData, filters []*ec2.Filter) error {
+	resolved := make([]interface{}, 0, len(filters))
+	for _, filter := range flattenEC2Filters(filters) {
+		resolved = append(resolved, filter)
+	}
+
+	if err := d.Set("resolved_filters", resolved); err != nil {
+		return fmt.Errorf("error setting resolved_filters: %w", err)
+	}
+
+	return nil
+}
+
+// ec2FiltersObjectSchema returns a *schema.Schema for the computed
+// "filters_object" attribute of data sources, reporting the filters sent to
+// the EC2 API as "filter" blocks which give the same filters when fed back
+// into another data source, as set by setEC2FiltersObject. In Terraform
+// configuration this looks like this:
+//
+//	dynamic "filter" {
+//	  for_each = module.network.filters_object
+//	  content {
+//	    name     = filter.value.name
+//	    values   = filter.value.values
+//	    wildcard = filter.value.wildcard
+//	  }
+//	}
+func ec2FiltersObjectSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The filters sent to the EC2 API as `filter` blocks, to reuse them in another data source with a `dynamic \"filter\"` block giving the same filters.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"values": {
+					Type:     schema.TypeList,
+					Computed: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"wildcard": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// setEC2FiltersObject sets the filters_object attribute of the given
+// *schema.ResourceData to the given filters, as flattened by
+// flattenEC2FiltersObject for the escape_filter_wildcards of the provider.
+func setEC2FiltersObject(d *schema.ResourceData, meta interface{}, filters []*ec2.Filter) error {
+	if err := d.Set("filters_object", flattenEC2FiltersObject(filters, meta.(*AWSClient).escapeFilterWildcards)); err != nil {
+		return fmt.Errorf("error setting filters_object: %w", err)
+	}
+
+	return nil
+}
+
+// flattenEC2FiltersObject returns the "filter" blocks which
+// buildEC2CustomFilterList, or buildEC2CustomFilterListEscapingWildcards if
+// escapeWildcards is set, turns back into the given filters, in their order.
+//
+// When the wildcards are escaped, the values sent escaped are unescaped, to
+// be escaped again, while the wildcard patterns are given in a block of their
+// own setting "wildcard", so that a filter with both gives two blocks of the
+// same name, merged back into one. Otherwise, every value is given as sent in
+// a single block.
+//
+// The filters repeating a name, such as those of required_tag_keys, cannot be
+// expressed as blocks, which are merged when they share a name, so reusing
+// them matches the objects having any of the values rather than all of them.
+func flattenEC2FiltersObject(filters []*ec2.Filter, escapeWildcards bool) []interface{} {
+	result := make([]interface{}, 0, len(filters))
+
+	for _, filter := range filters {
+		if filter == nil {
+			continue
+		}
+
+		name := aws.StringValue(filter.Name)
+
+		if !escapeWildcards {
+			result = append(result, map[string]interface{}{
+				"name":     name,
+				"values":   aws.StringValueSlice(filter.Values),
+				"wildcard": false,
+			})
+			continue
+		}
+
+		var literals, patterns []string
+		for _, value := range aws.StringValueSlice(filter.Values) {
+			if ec2FilterValueIsWildcard(value) {
+				patterns = append(patterns, value)
+			} else {
+				literals = append(literals, unescapeEC2FilterValue(value))
+			}
+		}
+
+		if len(literals) > 0 || len(patterns) == 0 {
+			if literals == nil {
+				literals = []string{}
+			}
+			result = append(result, map[string]interface{}{
+				"name":     name,
+				"values":   literals,
+				"wildcard": false,
+			})
+		}
+		if len(patterns) > 0 {
+			result = append(result, map[string]interface{}{
+				"name":     name,
+				"values":   patterns,
+				"wildcard": true,
+			})
+		}
+	}
+
+	return result
+}
+
+// flattenEC2Filters returns the given filters in the shape of the "filter"
+// blocks of ec2CustomFiltersSchema, the inverse of buildEC2CustomFilterList. A
+// nil name is flattened to an empty string and nil values to an empty set.
+func flattenEC2Filters(filters []*ec2.Filter) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(filters))
+
+	for _, filter := range filters {
+		if filter == nil {
+			continue
+		}
+
+		values := schema.NewSet(schema.HashString, nil)
+		for _, value := range filter.Values {
+			values.Add(aws.StringValue(value))
+		}
+
+		result = append(result, map[string]interface{}{
+			"name":   aws.StringValue(filter.Name),
+			"values": values,
+		})
+	}
+
+	return result
+}
+
+// flattenEC2AppliedFilters returns the flattened "applied_filters" of the
+// given filters, each value annotated with whether it is a wildcard pattern.
+func flattenEC2AppliedFilters(filters []*ec2.Filter) []interface{} {
+	result := make([]interface{}, 0, len(filters))
+
+	for _, filter := range filters {
+		if filter == nil {
+			continue
+		}
+
+		values := make([]interface{}, 0, len(filter.Values))
+		for _, value := range aws.StringValueSlice(filter.Values) {
+			values = append(values, map[string]interface{}{
+				"value":    value,
+				"wildcard": ec2FilterValueIsWildcard(value),
+			})
+		}
+
+		result = append(result, map[string]interface{}{
+			"name":   aws.StringValue(filter.Name),
+			"values": values,
+		})
+	}
+
+	return result
+}
+
+// formatEC2Filters returns a human-readable representation of the given
+// filters for logging, such as `tag:Name=[my-*-vpc (wildcard), other]`, in
+// which the wildcard patterns are marked.
+func formatEC2Filters(filters []*ec2.Filter) string {
+	formatted := make([]string, 0, len(filters))
+
+	for _, filter := range filters {
+		if filter == nil {
+			continue
+		}
+
+		values := make([]string, 0, len(filter.Values))
+		for _, value := range aws.StringValueSlice(filter.Values) {
+			if ec2FilterValueIsWildcard(value) {
+				value += " (wildcard)"
+			}
+			values = append(values, value)
+		}
+
+		formatted = append(formatted, fmt.Sprintf("%s=[%s]", aws.StringValue(filter.Name), strings.Join(values, ", ")))
+	}
+
+	return strings.Join(formatted, " ")
+}
+
+// ec2FilterValueIsWildcard returns whether the given EC2 filter value is
+// interpreted as a pattern by the EC2 API, which is when it has a * or ?
+// which is not escaped with a backslash, as done by escapeEC2FilterValue. An
+// escaped backslash does not escape the character following it.
+func ec2FilterValueIsWildcard(value string) bool {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			// Skip the escaped character, whatever it is.
+			i++
+		case '*', '?':
+			return true
+		}
+	}
+
+	return false
+}
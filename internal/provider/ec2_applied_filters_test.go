@@ -0,0 +1,280 @@
+package provider
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2FilterValueIsWildcard(t *testing.T) {
+	testCases := []struct {
+		Value    string
+		Expected bool
+	}{
+		{Value: "my-awesome-vpc", Expected: false},
+		{Value: "", Expected: false},
+		{Value: "my-*-vpc", Expected: true},
+		{Value: "vpc-?", Expected: true},
+		{Value: `my-\*-vpc`, Expected: false},
+		{Value: `vpc-\?`, Expected: false},
+		{Value: `my-\*-vpc-*`, Expected: true},
+		{Value: `my\\*`, Expected: true},
+		{Value: `my\\\*`, Expected: false},
+		{Value: `trailing\`, Expected: false},
+		{Value: escapeEC2FilterValue("my-*-vpc-?"), Expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Value, func(t *testing.T) {
+			if got := ec2FilterValueIsWildcard(testCase.Value); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestFlattenEC2AppliedFilters(t *testing.T) {
+	filters := []*ec2.Filter{
+		{
+			Name:   aws.String("tag:Name"),
+			Values: aws.StringSlice([]string{"my-*-vpc", `my-\*-vpc`}),
+		},
+		{
+			Name:   aws.String("vpc-id"),
+			Values: aws.StringSlice([]string{"vpc-01234567"}),
+		},
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"name": "tag:Name",
+			"values": []interface{}{
+				map[string]interface{}{"value": "my-*-vpc", "wildcard": true},
+				map[string]interface{}{"value": `my-\*-vpc`, "wildcard": false},
+			},
+		},
+		map[string]interface{}{
+			"name": "vpc-id",
+			"values": []interface{}{
+				map[string]interface{}{"value": "vpc-01234567", "wildcard": false},
+			},
+		},
+	}
+
+	if got := flattenEC2AppliedFilters(filters); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+
+	if got, expected := formatEC2Filters(filters), `tag:Name=[my-*-vpc (wildcard), my-\*-vpc] vpc-id=[vpc-01234567]`; got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
+
+func TestSetEC2AppliedFiltersEscapingWildcards(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"name":            ec2NameSchema(),
+		"filter":          ec2CustomFiltersSchema(),
+		"applied_filters": ec2AppliedFiltersSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"name": "my-*-vpc",
+		"filter": []interface{}{
+			map[string]interface{}{
+				"name":     "tag:Team",
+				"values":   []interface{}{"platform-*"},
+				"wildcard": true,
+			},
+		},
+	})
+
+	_, filters, _, err := buildEC2Selection(d, &AWSClient{escapeFilterWildcards: true}, ec2.ResourceTypeVpc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := setEC2AppliedFilters(d, filters); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"name": "tag:Name",
+			"values": []interface{}{
+				map[string]interface{}{"value": `my-\*-vpc`, "wildcard": false},
+			},
+		},
+		map[string]interface{}{
+			"name": "tag:Team",
+			"values": []interface{}{
+				map[string]interface{}{"value": "platform-*", "wildcard": true},
+			},
+		},
+	}
+
+	if got := d.Get("applied_filters"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestFlattenEC2FiltersRoundTrip(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"filter":           ec2CustomFiltersSchema(),
+		"resolved_filters": ec2ResolvedFiltersSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{
+				"name":   "vpc-id",
+				"values": []interface{}{"vpc-01234567", "vpc-89abcdef"},
+			},
+			map[string]interface{}{
+				"name":   "tag:Team",
+				"values": []interface{}{"platform"},
+			},
+		},
+	})
+
+	filters, diags := buildEC2CustomFilterList(d.Get("filter").(*schema.Set))
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	set := schema.NewSet(schema.HashResource(ec2CustomFiltersSchema().Elem.(*schema.Resource)), nil)
+	for _, filter := range flattenEC2Filters(filters) {
+		set.Add(filter)
+	}
+
+	roundTripped, diags := buildEC2CustomFilterList(set)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	// The order of the filters follows that of the set elements, which depends on their hash.
+	for _, l := range [][]*ec2.Filter{filters, roundTripped} {
+		sort.Slice(l, func(i, j int) bool { return aws.StringValue(l[i].Name) < aws.StringValue(l[j].Name) })
+	}
+
+	if !reflect.DeepEqual(roundTripped, filters) {
+		t.Errorf("got %v, expected %v", roundTripped, filters)
+	}
+
+	if err := setEC2ResolvedFilters(d, filters); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := d.Get("resolved_filters.#"); got != 2 {
+		t.Errorf("got %v resolved filters, expected 2", got)
+	}
+}
+
+func TestFlattenEC2FiltersNil(t *testing.T) {
+	got := flattenEC2Filters([]*ec2.Filter{nil, {}})
+
+	if len(got) != 1 {
+		t.Fatalf("got %d filters, expected 1", len(got))
+	}
+
+	if name := got[0]["name"]; name != "" {
+		t.Errorf("got name %q, expected empty", name)
+	}
+
+	if values := got[0]["values"].(*schema.Set); values.Len() != 0 {
+		t.Errorf("got values %v, expected none", values.List())
+	}
+}
+
+func TestFlattenEC2FiltersObjectRoundTrip(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"name":           ec2NameSchema(),
+		"filter":         ec2CustomFiltersSchema(),
+		"tags":           tagsSchema(),
+		"filters_object": ec2FiltersObjectSchema(),
+	}
+
+	for _, escapeWildcards := range []bool{false, true} {
+		client := &AWSClient{escapeFilterWildcards: escapeWildcards}
+
+		d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+			"name": "my-*-vpc",
+			"tags": map[string]interface{}{"Environment": `prod\east`},
+			"filter": []interface{}{
+				map[string]interface{}{
+					"name":     "tag:Team",
+					"values":   []interface{}{"platform-*"},
+					"wildcard": true,
+				},
+				map[string]interface{}{
+					"name":   "tag:Team",
+					"values": []interface{}{"data?"},
+				},
+				map[string]interface{}{
+					"name":   "vpc-id",
+					"values": []interface{}{"vpc-89abcdef", "vpc-01234567"},
+				},
+			},
+		})
+
+		_, filters, _, err := buildEC2Selection(d, client, ec2.ResourceTypeVpc)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if err := setEC2FiltersObject(d, client, filters); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		// The output fed back with a dynamic "filter" block.
+		var blocks []interface{}
+		for _, v := range d.Get("filters_object").([]interface{}) {
+			m := v.(map[string]interface{})
+			blocks = append(blocks, map[string]interface{}{
+				"name":     m["name"],
+				"values":   m["values"],
+				"wildcard": m["wildcard"],
+			})
+		}
+
+		reused := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+			"filter": blocks,
+		})
+
+		_, roundTripped, _, err := buildEC2Selection(reused, client, ec2.ResourceTypeVpc)
+		if err != nil {
+			t.Fatalf("escape_filter_wildcards %t: unexpected error: %s", escapeWildcards, err)
+		}
+
+		// The order of the filters follows that of the set elements, which depends on their hash.
+		sort.Slice(roundTripped, func(i, j int) bool {
+			return aws.StringValue(roundTripped[i].Name) < aws.StringValue(roundTripped[j].Name)
+		})
+
+		if !reflect.DeepEqual(roundTripped, filters) {
+			t.Errorf("escape_filter_wildcards %t: got %v, expected %v", escapeWildcards, roundTripped, filters)
+		}
+	}
+}
+
+func TestFlattenEC2FiltersObjectEscapingWildcards(t *testing.T) {
+	filters := []*ec2.Filter{
+		{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{`data\?`, "platform-*"})},
+		{Name: aws.String("tag-key"), Values: aws.StringSlice([]string{"Owner"})},
+		{Name: aws.String("tag:Path"), Values: aws.StringSlice([]string{`C:\\Temp\*`})},
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{"name": "tag:Team", "values": []string{"data?"}, "wildcard": false},
+		map[string]interface{}{"name": "tag:Team", "values": []string{"platform-*"}, "wildcard": true},
+		map[string]interface{}{"name": "tag-key", "values": []string{"Owner"}, "wildcard": false},
+		map[string]interface{}{"name": "tag:Path", "values": []string{`C:\Temp*`}, "wildcard": false},
+	}
+
+	if got := flattenEC2FiltersObject(filters, true); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
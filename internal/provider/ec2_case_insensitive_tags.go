@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ec2CaseInsensitiveTagsSchema returns a *schema.Schema for the
+// "case_insensitive" attribute of data sources, matching the values of their
+// "tags" attribute ignoring case, such as Prod for prod. The EC2 API only
+// matches tag values exactly, so buildEC2Selection then selects the objects
+// with the keys of "tags", whatever their values, and the values are
+// compared by the predicate returned by
+// buildEC2CaseInsensitiveTagPredicateFromResourceData.
+func ec2CaseInsensitiveTagsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Match the values of `tags` ignoring case, such as `Prod` for `prod`. The EC2 API only matches tag values exactly, so the objects with the keys of `tags` are then read whatever their values, and those with other values are dropped on the client side, which reads more objects than the default exact match. The `*` and `?` wildcards of the values keep their meaning unless the provider's `escape_filter_wildcards` is set.",
+	}
+}
+
+// ec2CaseInsensitiveTags returns the tags of the "tags" attribute of the
+// given resourceDataGetter when its "case_insensitive" attribute is set,
+// without the tags buildEC2SelectionFilters does not turn into filters:
+// those with the reserved "aws:" prefix, except for ec2FilterableAwsTagKeys,
+// and those ignored by the given configuration. It returns nil otherwise.
+func ec2CaseInsensitiveTags(d resourceDataGetter, ignoreConfig *keyvaluetags.IgnoreConfig) keyvaluetags.KeyValueTags {
+	if v, ok := d.GetOk("case_insensitive"); !ok || !v.(bool) {
+		return nil
+	}
+
+	v, ok := d.GetOk("tags")
+	if !ok {
+		return nil
+	}
+
+	return keyvaluetags.New(v.(map[string]interface{})).IgnoreAwsExcept(ec2FilterableAwsTagKeys...).IgnoreConfig(ignoreConfig)
+}
+
+// buildEC2CaseInsensitiveTagKeyFilterList returns the filters selecting the
+// objects with all the keys of the given tags, whatever their values, as
+// built by buildEC2RequiredTagKeyFilterList, for their values to be compared
+// ignoring case on the client side. The wildcards of the keys are escaped, as
+// the keys of the "tags" attribute are matched literally. The filters are
+// sorted by key, and nil is returned if there are no tags.
+func buildEC2CaseInsensitiveTagKeyFilterList(tags keyvaluetags.KeyValueTags) []*ec2.Filter {
+	keys := tags.Keys()
+	sort.Strings(keys)
+
+	filters := buildEC2RequiredTagKeyFilterList(keys)
+	escapeEC2FilterWildcards(filters...)
+
+	return filters
+}
+
+// buildEC2CaseInsensitiveTagPredicate returns a function reporting whether
+// the given object tags have all of the given tags with values equal to
+// theirs ignoring case, as the client-side companion of
+// buildEC2CaseInsensitiveTagKeyFilterList. The `*` and `?` wildcards of the
+// values match any characters and any single character unless
+// escapeWildcards is set, as they would in a tag filter. The returned
+// function matches everything if there are no tags.
+func buildEC2CaseInsensitiveTagPredicate(tags keyvaluetags.KeyValueTags, escapeWildcards bool) func([]*ec2.Tag) bool {
+	patterns := make(map[string]*regexp.Regexp, len(tags))
+	for key, value := range tags.Map() {
+		patterns[key] = ec2CaseInsensitiveTagValueRegexp(value, escapeWildcards)
+	}
+
+	return func(objectTags []*ec2.Tag) bool {
+		matched := 0
+		for _, tag := range objectTags {
+			if pattern, ok := patterns[aws.StringValue(tag.Key)]; ok && pattern.MatchString(aws.StringValue(tag.Value)) {
+				matched++
+			}
+		}
+
+		// Object tag keys are unique, so all the tags match when they are all counted.
+		return matched == len(patterns)
+	}
+}
+
+// buildEC2CaseInsensitiveTagPredicateFromResourceData returns the result of
+// buildEC2CaseInsensitiveTagPredicate for the tags returned by
+// ec2CaseInsensitiveTags, which matches everything unless the
+// "case_insensitive" attribute of the given *schema.ResourceData is set.
+func buildEC2CaseInsensitiveTagPredicateFromResourceData(d *schema.ResourceData, meta interface{}) func([]*ec2.Tag) bool {
+	return buildEC2CaseInsensitiveTagPredicate(ec2CaseInsensitiveTags(d, meta.(*AWSClient).IgnoreTagsConfig), meta.(*AWSClient).escapeFilterWildcards)
+}
+
+// ec2CaseInsensitiveTagValueRegexp returns the regular expression matching
+// the whole of the values equal to the given tag filter value ignoring case,
+// with its wildcards unless escapeWildcards is set.
+func ec2CaseInsensitiveTagValueRegexp(value string, escapeWildcards bool) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+
+	for _, r := range value {
+		switch {
+		case r == '*' && !escapeWildcards:
+			b.WriteString(".*")
+		case r == '?' && !escapeWildcards:
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestBuildEC2CaseInsensitiveTagPredicate(t *testing.T) {
+	objectTags := func(value string) []*ec2.Tag {
+		return []*ec2.Tag{
+			{Key: aws.String("Environment"), Value: aws.String(value)},
+			{Key: aws.String("Team"), Value: aws.String("Platform")},
+		}
+	}
+
+	testCases := []struct {
+		Name            string
+		Tags            map[string]string
+		EscapeWildcards bool
+		ObjectTags      []*ec2.Tag
+		Expected        bool
+	}{
+		{
+			Name:       "no tags",
+			ObjectTags: objectTags("prod"),
+			Expected:   true,
+		},
+		{
+			Name:       "same case",
+			Tags:       map[string]string{"Environment": "prod"},
+			ObjectTags: objectTags("prod"),
+			Expected:   true,
+		},
+		{
+			Name:       "different case",
+			Tags:       map[string]string{"Environment": "Prod"},
+			ObjectTags: objectTags("prod"),
+			Expected:   true,
+		},
+		{
+			Name:       "different value",
+			Tags:       map[string]string{"Environment": "Prod"},
+			ObjectTags: objectTags("production"),
+			Expected:   false,
+		},
+		{
+			Name:       "key matched exactly",
+			Tags:       map[string]string{"environment": "prod"},
+			ObjectTags: objectTags("prod"),
+			Expected:   false,
+		},
+		{
+			Name:       "all tags",
+			Tags:       map[string]string{"Environment": "PROD", "Team": "platform"},
+			ObjectTags: objectTags("prod"),
+			Expected:   true,
+		},
+		{
+			Name:       "missing tag",
+			Tags:       map[string]string{"Environment": "prod", "Owner": "alice"},
+			ObjectTags: objectTags("prod"),
+			Expected:   false,
+		},
+		{
+			Name:       "wildcard",
+			Tags:       map[string]string{"Environment": "Prod*"},
+			ObjectTags: objectTags("production"),
+			Expected:   true,
+		},
+		{
+			Name:            "escaped wildcard",
+			Tags:            map[string]string{"Environment": "Prod*"},
+			EscapeWildcards: true,
+			ObjectTags:      objectTags("production"),
+			Expected:        false,
+		},
+		{
+			Name:            "literal wildcard",
+			Tags:            map[string]string{"Environment": "Prod*"},
+			EscapeWildcards: true,
+			ObjectTags:      objectTags("prod*"),
+			Expected:        true,
+		},
+		{
+			Name:       "regular expression characters",
+			Tags:       map[string]string{"Environment": "prod.1"},
+			ObjectTags: objectTags("prodx1"),
+			Expected:   false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			matched := buildEC2CaseInsensitiveTagPredicate(keyvaluetags.New(testCase.Tags), testCase.EscapeWildcards)
+
+			if got := matched(testCase.ObjectTags); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2SelectionCaseInsensitiveTags(t *testing.T) {
+	schemas := map[string]*schema.Schema{
+		"tags":             tagsSchema(),
+		"case_insensitive": ec2CaseInsensitiveTagsSchema(),
+	}
+
+	testCases := []struct {
+		Name            string
+		CaseInsensitive bool
+		Expected        []*ec2.Filter
+		// Matched reports whether an object tagged Environment = production is kept on the client side.
+		Matched bool
+	}{
+		{
+			Name: "exact match",
+			Expected: []*ec2.Filter{
+				{Name: aws.String("tag:Environment"), Values: aws.StringSlice([]string{"Prod"})},
+			},
+			Matched: true,
+		},
+		{
+			Name:            "case insensitive",
+			CaseInsensitive: true,
+			Expected: []*ec2.Filter{
+				{Name: aws.String("tag-key"), Values: aws.StringSlice([]string{"Environment"})},
+			},
+			Matched: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, schemas, map[string]interface{}{
+				"tags":             map[string]interface{}{"Environment": "Prod"},
+				"case_insensitive": testCase.CaseInsensitive,
+			})
+			meta := &AWSClient{}
+
+			_, filters, _, err := buildEC2Selection(d, meta, ec2.ResourceTypeInstance)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(filters, testCase.Expected) {
+				t.Errorf("got filters %v, expected %v", filters, testCase.Expected)
+			}
+
+			// With an exact match, the EC2 API does not return the object, so the predicate matches everything.
+			matched := buildEC2CaseInsensitiveTagPredicateFromResourceData(d, meta)
+			if got := matched([]*ec2.Tag{{Key: aws.String("Environment"), Value: aws.String("production")}}); got != testCase.Matched {
+				t.Errorf("got %t, expected %t", got, testCase.Matched)
+			}
+		})
+	}
+}
+
+func TestBuildEC2SelectionCaseInsensitiveTagsWithCloudFormationStackName(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{
+		"tags":                      tagsSchema(),
+		"case_insensitive":          ec2CaseInsensitiveTagsSchema(),
+		"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+	}, map[string]interface{}{
+		"tags":                      map[string]interface{}{"Environment": "Prod", "Team": "Platform"},
+		"case_insensitive":          true,
+		"cloudformation_stack_name": "network",
+	})
+
+	_, filters, _, err := buildEC2Selection(d, &AWSClient{}, ec2.ResourceTypeInstance)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Each key keeps its own "tag-key" filter, so that both are required rather than either.
+	expected := []*ec2.Filter{
+		{Name: aws.String("tag:aws:cloudformation:stack-name"), Values: aws.StringSlice([]string{"network"})},
+		{Name: aws.String("tag-key"), Values: aws.StringSlice([]string{"Environment"})},
+		{Name: aws.String("tag-key"), Values: aws.StringSlice([]string{"Team"})},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("got filters %v, expected %v", filters, expected)
+	}
+}
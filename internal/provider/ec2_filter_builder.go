@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// EC2FilterBuilder builds the filters passed to the "Filters" attribute of
+// most of the "Describe..." functions in the EC2 API incrementally, from
+// attributes, tags, tag keys and custom filters, rather than concatenating
+// the outputs of buildEC2AttributeFilterList, ec2TagFiltersFromMap,
+// buildEC2TagKeyFilterList and the custom filters by hand:
+//
+//	filters := NewEC2FilterBuilder().
+//		AddAttribute("vpc-id", vpcID).
+//		AddTag("Environment", "production").
+//		AddCustom("instance-state-name", "running", "stopped").
+//		Build()
+//
+// Each method skips what the helper it reuses skips, so that optional
+// values can be added unconditionally. The filters sharing a name are merged
+// by Build, as by mergeEC2FilterLists, so that an object matches any of the
+// values added for a name.
+type EC2FilterBuilder struct {
+	lists [][]*ec2.Filter
+}
+
+// NewEC2FilterBuilder returns an EC2FilterBuilder without any filter.
+func NewEC2FilterBuilder() *EC2FilterBuilder {
+	return &EC2FilterBuilder{}
+}
+
+// AddAttribute adds an exact match of the given attribute, as built by
+// buildEC2AttributeFilterList. Nothing is added if the value is empty,
+// leaving the attribute unconstrained.
+func (b *EC2FilterBuilder) AddAttribute(name, value string) *EC2FilterBuilder {
+	b.lists = append(b.lists, buildEC2AttributeFilterList(map[string]string{name: value}))
+
+	return b
+}
+
+// AddTag adds an exact match of the given tag, as built by
+// ec2TagFiltersFromMap: an empty value matches the objects with the tag set
+// to the empty value. Nothing is added if the key is empty or has the
+// reserved "aws:" prefix, except for ec2FilterableAwsTagKeys.
+func (b *EC2FilterBuilder) AddTag(key, value string) *EC2FilterBuilder {
+	if key == "" {
+		return b
+	}
+
+	b.lists = append(b.lists, ec2TagFiltersFromMap(map[string]interface{}{key: value}, nil))
+
+	return b
+}
+
+// AddTagKey adds a match of the objects with the given tag key, whatever its
+// value, as built by buildEC2TagKeyFilterList. The keys added are merged
+// into a single "tag-key" filter, matching the objects with any of them.
+// Nothing is added if the key is empty.
+func (b *EC2FilterBuilder) AddTagKey(key string) *EC2FilterBuilder {
+	b.lists = append(b.lists, buildEC2TagKeyFilterList([]string{key}))
+
+	return b
+}
+
+// AddCustom adds a filter with the given name matching any of the given
+// values, as given in a "filter" block. The empty values are dropped, and
+// nothing is added if the name is empty or no values are left.
+func (b *EC2FilterBuilder) AddCustom(name string, values ...string) *EC2FilterBuilder {
+	if name == "" {
+		return b
+	}
+
+	var nonEmpty []string
+	for _, value := range values {
+		if value != "" {
+			nonEmpty = appendUniqueString(nonEmpty, value)
+		}
+	}
+
+	if len(nonEmpty) > 0 {
+		b.lists = append(b.lists, []*ec2.Filter{
+			{
+				Name:   aws.String(name),
+				Values: aws.StringSlice(nonEmpty),
+			},
+		})
+	}
+
+	return b
+}
+
+// Build returns the filters added so far, merged with mergeEC2FilterLists:
+// the filters sharing a name are merged into one with the union of their
+// values, and the filters are sorted by name. It returns nil if no filter
+// was added. The builder may still be added to afterwards.
+func (b *EC2FilterBuilder) Build() []*ec2.Filter {
+	return mergeEC2FilterLists(b.lists...)
+}
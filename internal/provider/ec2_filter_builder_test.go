@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEC2FilterBuilder(t *testing.T) {
+	got := NewEC2FilterBuilder().
+		AddAttribute("vpc-id", "vpc-01234567").
+		AddAttribute("subnet-id", "").
+		AddTag("Environment", "production").
+		AddTag("", "ignored").
+		AddTag("aws:cloudformation:stack-name", "ignored").
+		AddTagKey("Team").
+		AddTagKey("").
+		AddTagKey("Owner").
+		AddCustom("instance-state-name", "running", "", "stopped", "running").
+		AddCustom("image-id").
+		AddCustom("vpc-id", "vpc-89abcdef").
+		Build()
+
+	expected := []*ec2.Filter{
+		{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{"running", "stopped"}),
+		},
+		{
+			Name:   aws.String("tag-key"),
+			Values: aws.StringSlice([]string{"Owner", "Team"}),
+		},
+		{
+			Name:   aws.String("tag:Environment"),
+			Values: aws.StringSlice([]string{"production"}),
+		},
+		{
+			Name:   aws.String("vpc-id"),
+			Values: aws.StringSlice([]string{"vpc-01234567", "vpc-89abcdef"}),
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+
+	if got := NewEC2FilterBuilder().AddAttribute("vpc-id", "").Build(); got != nil {
+		t.Errorf("got %v, expected nil", got)
+	}
+}
+
+func TestEC2FilterBuilderMergesLikeMergeEC2FilterLists(t *testing.T) {
+	tags := map[string]interface{}{"Environment": "production", "Team": "platform"}
+
+	got := NewEC2FilterBuilder().
+		AddTag("Team", "platform").
+		AddAttribute("availability-zone", "us-east-1a").
+		AddCustom("tag:Team", "security").
+		AddTag("Environment", "production").
+		Build()
+
+	expected := mergeEC2FilterLists(
+		buildEC2AttributeFilterList(map[string]string{"availability-zone": "us-east-1a"}),
+		ec2TagFiltersFromMap(tags, nil),
+		[]*ec2.Filter{{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{"security"})}},
+	)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2FilterGroupConcurrency is the maximum number of filter_group queries run
+// at the same time.
+const ec2FilterGroupConcurrency = 3
+
+// ec2FilterGroup is a labelled, independent set of filters read from a
+// "filter_group" block.
+type ec2FilterGroup struct {
+	Label   string
+	Filters []*ec2.Filter
+}
+
+// ec2FilterGroupsSchema returns a *schema.Schema for the repeatable
+// "filter_group" block, each of which selects objects with its own "name",
+// "filter" and "tags", in addition to the top-level selection, so that a
+// single data source runs several queries rather than one per instance of a
+// data source with for_each.
+func ec2FilterGroupsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Independent queries, each selecting the objects matching both the top-level selection and its own, whose results are reported by label in `filter_group_results`.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"label": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringIsNotEmpty,
+					Description:  "The label of the group, unique among the groups.",
+				},
+				"name":   ec2NameSchema(),
+				"filter": ec2CustomFiltersSchema(),
+				"tags":   tagsSchema(),
+			},
+		},
+	}
+}
+
+// ec2FilterGroupResultsSchema returns a *schema.Schema for the computed
+// "filter_group_results" attribute, listing the IDs of the objects selected
+// by each "filter_group" block, ordered by label.
+func ec2FilterGroupResultsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The IDs of the objects matching each `filter_group`, ordered by label.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"label": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"ids": {
+					Type:        schema.TypeList,
+					Computed:    true,
+					Description: "The IDs of the matching objects, deduplicated and ordered by ID.",
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+// buildEC2FilterGroups reads the "filter_group" blocks of the given
+// *schema.ResourceData into their filters, which are appended to the given
+// filters of the top-level selection. It is an error for several groups to
+// have the same label. The warnings about the "filter" blocks of the groups
+// are returned along with them.
+func buildEC2FilterGroups(d *schema.ResourceData, meta interface{}, filters []*ec2.Filter) ([]ec2FilterGroup, diag.Diagnostics, error) {
+	escapeWildcards := meta.(*AWSClient).escapeFilterWildcards
+	var groups []ec2FilterGroup
+	var warnings diag.Diagnostics
+	labels := make(map[string]bool)
+
+	for _, v := range d.Get("filter_group").([]interface{}) {
+		m := v.(map[string]interface{})
+		label := m["label"].(string)
+
+		if labels[label] {
+			return nil, nil, fmt.Errorf("duplicate filter_group label: %s", label)
+		}
+		labels[label] = true
+
+		var tags map[string]interface{}
+		if v, ok := m["tags"].(map[string]interface{}); ok {
+			tags = v
+		}
+
+		var filterSet *schema.Set
+		if v, ok := m["filter"].(*schema.Set); ok {
+			filterSet = v
+		}
+
+		groupFilters, groupWarnings, err := buildEC2SelectionFilters(tags, m["name"].(string), filterSet, escapeWildcards, meta.(*AWSClient).IgnoreTagsConfig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("filter_group %s: %w", label, err)
+		}
+		warnings = append(warnings, groupWarnings...)
+
+		groups = append(groups, ec2FilterGroup{
+			Label:   label,
+			Filters: append(append([]*ec2.Filter{}, filters...), groupFilters...),
+		})
+	}
+
+	return groups, warnings, nil
+}
+
+// queryEC2FilterGroups runs the given query with the filters of each of the
+// given groups, at most concurrency at a time, and returns the flattened
+// "filter_group_results", ordered by label. The IDs returned for each group
+// are deduplicated and sorted.
+//
+// When queries fail, the error of the first failed group in label order is
+// returned, so that the error does not depend on scheduling.
+func queryEC2FilterGroups(groups []ec2FilterGroup, concurrency int, query func(filters []*ec2.Filter) ([]string, error)) ([]map[string]interface{}, error) {
+	sorted := append([]ec2FilterGroup{}, groups...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Label < sorted[j].Label
+	})
+
+	ids := make([][]string, len(sorted))
+	funcs := make([]func() error, 0, len(sorted))
+
+	for i := range sorted {
+		i := i
+		funcs = append(funcs, func() (err error) {
+			if ids[i], err = query(sorted[i].Filters); err != nil {
+				return fmt.Errorf("filter_group %s: %w", sorted[i].Label, err)
+			}
+			return nil
+		})
+	}
+
+	if err := runConcurrently(concurrency, funcs...); err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]interface{}, 0, len(sorted))
+	for i, group := range sorted {
+		seen := make(map[string]bool, len(ids[i]))
+		unique := make([]string, 0, len(ids[i]))
+		for _, id := range ids[i] {
+			if !seen[id] {
+				seen[id] = true
+				unique = append(unique, id)
+			}
+		}
+		sort.Strings(unique)
+
+		results = append(results, map[string]interface{}{
+			"label": group.Label,
+			"ids":   unique,
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestBuildEC2FilterGroups(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"filter_group": ec2FilterGroupsSchema(),
+	}
+
+	base := []*ec2.Filter{
+		{
+			Name:   aws.String("vpc-id"),
+			Values: aws.StringSlice([]string{"vpc-01234567"}),
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"filter_group": []interface{}{
+			map[string]interface{}{
+				"label": "prod",
+				"tags": map[string]interface{}{
+					"Environment": "prod",
+				},
+			},
+			map[string]interface{}{
+				"label": "public",
+				"name":  "public-*",
+				"filter": []interface{}{
+					map[string]interface{}{
+						"name":   "association.main",
+						"values": []interface{}{"false"},
+					},
+				},
+			},
+		},
+	})
+
+	groups, _, err := buildEC2FilterGroups(d, &AWSClient{}, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []ec2FilterGroup{
+		{
+			Label: "prod",
+			Filters: []*ec2.Filter{
+				base[0],
+				{
+					Name:   aws.String("tag:Environment"),
+					Values: aws.StringSlice([]string{"prod"}),
+				},
+			},
+		},
+		{
+			Label: "public",
+			Filters: []*ec2.Filter{
+				base[0],
+				{
+					Name:   aws.String("association.main"),
+					Values: aws.StringSlice([]string{"false"}),
+				},
+				{
+					Name:   aws.String("tag:Name"),
+					Values: aws.StringSlice([]string{"public-*"}),
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("got %v, expected %v", groups, expected)
+	}
+
+	if len(base) != 1 {
+		t.Errorf("expected the base filters to be left untouched, got %v", base)
+	}
+}
+
+func TestBuildEC2FilterGroupsDuplicateLabel(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"filter_group": ec2FilterGroupsSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"filter_group": []interface{}{
+			map[string]interface{}{"label": "prod", "name": "a"},
+			map[string]interface{}{"label": "prod", "name": "b"},
+		},
+	})
+
+	if _, _, err := buildEC2FilterGroups(d, &AWSClient{}, nil); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestQueryEC2FilterGroups(t *testing.T) {
+	groups := []ec2FilterGroup{
+		{Label: "web", Filters: []*ec2.Filter{{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{"web"})}}},
+		{Label: "data", Filters: []*ec2.Filter{{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{"data"})}}},
+		{Label: "empty", Filters: []*ec2.Filter{{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{"none"})}}},
+	}
+	ids := map[string][]string{
+		"web":  {"rtb-00000003", "rtb-00000001", "rtb-00000003"},
+		"data": {"rtb-00000002"},
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+
+	results, err := queryEC2FilterGroups(groups, 2, func(filters []*ec2.Filter) ([]string, error) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		return ids[aws.StringValue(filters[0].Values[0])], nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []map[string]interface{}{
+		{"label": "data", "ids": []string{"rtb-00000002"}},
+		{"label": "empty", "ids": []string{}},
+		{"label": "web", "ids": []string{"rtb-00000001", "rtb-00000003"}},
+	}
+
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("got %v, expected %v", results, expected)
+	}
+
+	if maxRunning > 2 {
+		t.Errorf("got %d concurrent queries, expected at most 2", maxRunning)
+	}
+}
+
+func TestQueryEC2FilterGroupsError(t *testing.T) {
+	groups := []ec2FilterGroup{
+		{Label: "b", Filters: []*ec2.Filter{{Name: aws.String("b")}}},
+		{Label: "a", Filters: []*ec2.Filter{{Name: aws.String("a")}}},
+		{Label: "c", Filters: []*ec2.Filter{{Name: aws.String("c")}}},
+	}
+
+	_, err := queryEC2FilterGroups(groups, 3, func(filters []*ec2.Filter) ([]string, error) {
+		if name := aws.StringValue(filters[0].Name); name != "a" {
+			return nil, errors.New(name)
+		}
+		return nil, nil
+	})
+
+	if err == nil || err.Error() != "filter_group b: b" {
+		t.Errorf("got %v, expected the error of group b", err)
+	}
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	// defaultEC2MaxFilterValues is the default of the provider's max_filter_values, the number of values of a
+	// single filter the "Describe..." functions of the EC2 API are documented to accept.
+	defaultEC2MaxFilterValues = 200
+	// defaultEC2MaxFilters is the default of the provider's max_filters, the number of filters the
+	// "Describe..." functions of the EC2 API are documented to accept in a single request.
+	defaultEC2MaxFilters = 50
+)
+
+// validateEC2FilterLimits returns an error naming the first of the given
+// filters with more values than the provider's max_filter_values, or
+// reporting that there are more filters than its max_filters, so that
+// such a selection fails before the request is made rather than with the
+// opaque error of the EC2 API. A limit which is not set, as in an AWSClient
+// which was not configured by the provider, defaults to the limit documented
+// by AWS.
+func (client *AWSClient) validateEC2FilterLimits(filters []*ec2.Filter) error {
+	maxValues := client.maxFilterValues
+	if maxValues <= 0 {
+		maxValues = defaultEC2MaxFilterValues
+	}
+
+	maxFilters := client.maxFilters
+	if maxFilters <= 0 {
+		maxFilters = defaultEC2MaxFilters
+	}
+
+	for _, filter := range filters {
+		if len(filter.Values) > maxValues {
+			return fmt.Errorf("filter %s has %d values, more than the %d the EC2 API accepts in a single filter; narrow the selection, split it across several data sources or resources, or raise the provider's max_filter_values", aws.StringValue(filter.Name), len(filter.Values), maxValues)
+		}
+	}
+
+	if len(filters) > maxFilters {
+		return fmt.Errorf("the selection has %d filters, more than the %d the EC2 API accepts in a single request; use fewer tags or filter blocks, or raise the provider's max_filters", len(filters), maxFilters)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testEC2FilterWithValues returns a filter with the given name and number of values.
+func testEC2FilterWithValues(name string, count int) *ec2.Filter {
+	values := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		values = append(values, fmt.Sprintf("value-%d", i))
+	}
+
+	return &ec2.Filter{Name: aws.String(name), Values: aws.StringSlice(values)}
+}
+
+// testEC2FiltersWithNames returns the given number of filters with a single value and distinct names.
+func testEC2FiltersWithNames(count int) []*ec2.Filter {
+	filters := make([]*ec2.Filter, 0, count)
+	for i := 0; i < count; i++ {
+		filters = append(filters, testEC2FilterWithValues(fmt.Sprintf("tag:Key%d", i), 1))
+	}
+
+	return filters
+}
+
+func TestValidateEC2FilterLimits(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Client  *AWSClient
+		Filters []*ec2.Filter
+		Error   string
+	}{
+		{
+			Name:    "no filters",
+			Client:  &AWSClient{},
+			Filters: nil,
+		},
+		{
+			Name:    "at the default limits",
+			Client:  &AWSClient{},
+			Filters: append(testEC2FiltersWithNames(defaultEC2MaxFilters-1), testEC2FilterWithValues("tag:Team", defaultEC2MaxFilterValues)),
+		},
+		{
+			Name:    "too many values",
+			Client:  &AWSClient{},
+			Filters: []*ec2.Filter{testEC2FilterWithValues("vpc-id", 1), testEC2FilterWithValues("tag:Team", defaultEC2MaxFilterValues+1)},
+			Error:   "filter tag:Team has 201 values, more than the 200",
+		},
+		{
+			Name:    "too many filters",
+			Client:  &AWSClient{},
+			Filters: testEC2FiltersWithNames(defaultEC2MaxFilters + 1),
+			Error:   "the selection has 51 filters, more than the 50",
+		},
+		{
+			Name:    "raised values limit",
+			Client:  &AWSClient{maxFilterValues: 500},
+			Filters: []*ec2.Filter{testEC2FilterWithValues("tag:Team", 300)},
+		},
+		{
+			Name:    "lowered values limit",
+			Client:  &AWSClient{maxFilterValues: 2},
+			Filters: []*ec2.Filter{testEC2FilterWithValues("tag:Team", 3)},
+			Error:   "raise the provider's max_filter_values",
+		},
+		{
+			Name:    "lowered filters limit",
+			Client:  &AWSClient{maxFilters: 2},
+			Filters: testEC2FiltersWithNames(3),
+			Error:   "raise the provider's max_filters",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := testCase.Client.validateEC2FilterLimits(testCase.Filters)
+
+			if testCase.Error == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), testCase.Error) {
+				t.Errorf("got error %v, expected %q", err, testCase.Error)
+			}
+		})
+	}
+}
+
+func TestBuildEC2SelectionFilterLimits(t *testing.T) {
+	values := make([]interface{}, 0, defaultEC2MaxFilterValues+1)
+	for i := 0; i <= defaultEC2MaxFilterValues; i++ {
+		values = append(values, fmt.Sprintf("team-%d", i))
+	}
+
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{"filter": ec2CustomFiltersSchema()}, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{
+				"name":   "tag:Team",
+				"values": values,
+			},
+		},
+	})
+
+	if _, _, _, err := buildEC2Selection(d, &AWSClient{}, ec2.ResourceTypeInstance); err == nil || !strings.Contains(err.Error(), "filter tag:Team has 201 values") {
+		t.Errorf("got error %v, expected the tag:Team filter to exceed the limit", err)
+	}
+}
@@ -2,12 +2,52 @@ package provider
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
 	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2OwnerSelf is the owner ID referring to the account the provider is configured for.
+const ec2OwnerSelf = "self"
+
+var ec2OwnerIDRegexp = regexp.MustCompile(`^(\d{12}|self)$`)
+
+// ec2SpotFleetRequestIDTagKey is the tag AWS adds to the instances launched by a Spot Fleet request, whose value
+// is the ID of the request.
+const ec2SpotFleetRequestIDTagKey = "aws:ec2spot:fleet-request-id"
+
+// ec2AutoScalingGroupNameTagKey is the tag AWS adds to the instances launched by an Auto Scaling Group, whose value
+// is the name of the group.
+const ec2AutoScalingGroupNameTagKey = "aws:autoscaling:groupName"
+
+// ec2CloudFormationStackNameTagKey is the tag AWS CloudFormation adds to the objects of a stack, whose value is the
+// name of the stack. It is not among ec2FilterableAwsTagKeys, so it is only filtered on when explicitly allowed, as
+// done by buildEC2CloudFormationStackNameFilterList.
+const ec2CloudFormationStackNameTagKey = "aws:cloudformation:stack-name"
+
+// ec2FilterableAwsTagKeys are the tags with the reserved "aws:" prefix, otherwise ignored, which may be filtered
+// on with the "tags" attribute because AWS sets them to identify the objects a service manages.
+var ec2FilterableAwsTagKeys = []string{
+	ec2AutoScalingGroupNameTagKey,
+	ec2SpotFleetRequestIDTagKey,
+}
+
+var (
+	ec2SpotInstanceRequestIDRegexp = regexp.MustCompile(`^sir-[0-9a-z]+$`)
+	ec2SpotFleetRequestIDRegexp    = regexp.MustCompile(`^sfr-[0-9a-f-]+$`)
 )
 
 // buildEC2AttributeFilterList takes a flat map of scalar attributes (most
@@ -32,7 +72,108 @@ import (
 // the EC2 API, to aid in the implementation of Terraform data sources that
 // retrieve data about EC2 objects.
 func buildEC2AttributeFilterList(attrs map[string]string) []*ec2.Filter {
-	return tfec2.BuildAttributeFilterList(attrs)
+	return buildEC2AttributeFilterListWithOpts(attrs, false)
+}
+
+// buildEC2AttributeFilterListWithOpts is buildEC2AttributeFilterList, except
+// that the attributes with empty values produce a filter matching the empty
+// value exactly if keepEmpty is set, rather than being left unconstrained.
+// This changes which objects match, so it is only meant for the filters
+// where an empty value is meaningful, such as an empty Name tag.
+func buildEC2AttributeFilterListWithOpts(attrs map[string]string, keepEmpty bool) []*ec2.Filter {
+	return tfec2.BuildAttributeFilterListWithOpts(attrs, keepEmpty)
+}
+
+// buildEC2AttributeFilterListMulti is buildEC2AttributeFilterList for the
+// attributes taking several values, which the filter of each attribute
+// matches any of. The same rules apply: the empty values are dropped, and the
+// attributes left without any value are ignored rather than matching nothing.
+// The repeated values of an attribute are dropped too, the others keeping the
+// order they are given in, and the filters are sorted by name.
+func buildEC2AttributeFilterListMulti(attrs map[string][]string) []*ec2.Filter {
+	var filters []*ec2.Filter
+
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var values []string
+		for _, v := range attrs[name] {
+			if v == "" {
+				continue
+			}
+			values = appendUniqueString(values, v)
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String(name),
+			Values: aws.StringSlice(values),
+		})
+	}
+
+	return filters
+}
+
+// mergeEC2FilterLists concatenates the given filter lists, typically the
+// outputs of buildEC2AttributeFilterList, buildEC2TagFilterList and
+// buildEC2CustomFilterList, and merges the filters sharing the same name into
+// one, with the union of their values, deduplicated and sorted. The merged
+// filters are sorted by name, so that the requests, and the plans showing
+// them, do not depend on the order the lists are given in. The given filters
+// are not modified.
+//
+// Merging matches the objects having any of the values given for a name by
+// any of the lists, rather than repeating the name, which the EC2 API does not
+// evaluate consistently. It must not be used on lists deliberately repeating
+// a name to require all of its values, such as those built by
+// buildEC2RequiredTagKeyFilterList.
+func mergeEC2FilterLists(lists ...[]*ec2.Filter) []*ec2.Filter {
+	values := make(map[string][]string)
+
+	for _, list := range lists {
+		for _, filter := range list {
+			if filter == nil {
+				continue
+			}
+
+			name := aws.StringValue(filter.Name)
+			if _, ok := values[name]; !ok {
+				values[name] = []string{}
+			}
+			for _, value := range filter.Values {
+				values[name] = appendUniqueString(values[name], aws.StringValue(value))
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	filters := make([]*ec2.Filter, 0, len(names))
+	for _, name := range names {
+		sort.Strings(values[name])
+
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String(name),
+			Values: aws.StringSlice(values[name]),
+		})
+	}
+
+	return filters
 }
 
 // buildEC2TagFilterList takes a []*ec2.Tag and produces a []*ec2.Filter that
@@ -47,7 +188,8 @@ func buildEC2AttributeFilterList(attrs map[string]string) []*ec2.Filter {
 // It is conventional for an EC2 data source to include an attribute called
 // "tags" which conforms to the schema returned by the tagsSchema() function.
 // The value of this can then be converted to a tags slice using tagsFromMap,
-// and the result finally passed in to this function.
+// and the result finally passed in to this function, or all of these steps
+// can be performed at once with buildEC2TagFilterListFromResourceData.
 //
 // In Terraform configuration this would then look like this, to constrain
 // results by name:
@@ -55,6 +197,10 @@ func buildEC2AttributeFilterList(attrs map[string]string) []*ec2.Filter {
 // tags {
 //   Name = "my-awesome-subnet"
 // }
+//
+// or, using the "name" shorthand merged into the tags by buildEC2Selection:
+//
+// name = "my-awesome-subnet"
 func buildEC2TagFilterList(tags []*ec2.Tag) []*ec2.Filter {
 	filters := make([]*ec2.Filter, len(tags))
 
@@ -68,6 +214,204 @@ func buildEC2TagFilterList(tags []*ec2.Tag) []*ec2.Filter {
 	return filters
 }
 
+// buildEC2TagFilterListFromResourceData reads the tags map stored under the
+// given key of a *schema.ResourceData (an attribute conforming to the schema
+// returned by tagsSchema()), converts it using tagsFromMap and returns the
+// result of buildEC2TagFilterList. It returns nil if the attribute is unset.
+func buildEC2TagFilterListFromResourceData(d *schema.ResourceData, key string) []*ec2.Filter {
+	v, ok := d.GetOk(key)
+	if !ok {
+		return nil
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return nil
+	}
+
+	return buildEC2TagFilterList(tagsFromMap(m))
+}
+
+// ec2ExcludeTagsSchema returns a *schema.Schema for the "exclude_tags"
+// attribute, the negated counterpart of "tags": the objects whose tags match
+// all of its key/value pairs are dropped from the results. The EC2 API has no
+// negated filters, so the objects are described first and then dropped with
+// the predicate returned by buildEC2TagExclusionPredicate.
+//
+// In Terraform configuration this looks like this, to select the objects
+// which are not both in the prod environment and owned by the platform team:
+//
+// exclude_tags = {
+//   Environment = "prod"
+//   Team        = "platform"
+// }
+func ec2ExcludeTagsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeMap,
+		Optional:    true,
+		Description: "Drop the objects whose tags match all of the given key/value pairs (AND) from the results. The values are matched exactly, without wildcards, and those which are empty are ignored. Objects matching only some of the pairs are kept.",
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// buildEC2TagExclusionPredicate returns a function reporting whether the
+// given object tags match all of the given tag key/value pairs, and so
+// whether the object is to be dropped from the results, as the companion of
+// buildEC2TagFilterList for excluding objects rather than selecting them.
+//
+// The tags with nil or empty values are ignored, and the returned function
+// never excludes anything if there are none left, so that an unset
+// "exclude_tags" attribute keeps all the objects.
+func buildEC2TagExclusionPredicate(tags []*ec2.Tag) func([]*ec2.Tag) bool {
+	excluded := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		if tag == nil || aws.StringValue(tag.Key) == "" || aws.StringValue(tag.Value) == "" {
+			continue
+		}
+		excluded[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return func(objectTags []*ec2.Tag) bool {
+		if len(excluded) == 0 {
+			return false
+		}
+
+		matched := 0
+		for _, tag := range objectTags {
+			if v, ok := excluded[aws.StringValue(tag.Key)]; ok && v == aws.StringValue(tag.Value) {
+				matched++
+			}
+		}
+
+		// Object tag keys are unique, so all the pairs match when they are all counted.
+		return matched == len(excluded)
+	}
+}
+
+// buildEC2TagExclusionPredicateFromResourceData reads the tags map stored
+// under the given key of a *schema.ResourceData (an attribute conforming to
+// the schema returned by ec2ExcludeTagsSchema()), converts it using
+// tagsFromMap and returns the result of buildEC2TagExclusionPredicate, which
+// excludes nothing if the attribute is unset.
+func buildEC2TagExclusionPredicateFromResourceData(d *schema.ResourceData, key string) func([]*ec2.Tag) bool {
+	var tags []*ec2.Tag
+	if v, ok := d.GetOk(key); ok {
+		tags = tagsFromMap(v.(map[string]interface{}))
+	}
+
+	return buildEC2TagExclusionPredicate(tags)
+}
+
+// ec2AnyTagKeysSchema returns a *schema.Schema for the "any_tag_keys"
+// attribute, constraining the selected objects to those with at least one of
+// the given tag keys, whatever their values. Its value is converted into a
+// single "tag-key" filter with buildEC2TagKeyFilterList.
+//
+// Contrast with ec2RequiredTagKeysSchema, whose objects must have all the
+// keys. In Terraform configuration this looks like this, to select the
+// objects with either a cost center or a project tag:
+//
+// any_tag_keys = ["CostCenter", "Project"]
+func ec2AnyTagKeysSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Only match objects with at least one of the given tag keys (OR), whatever their values. Unlike `required_tag_keys`, which requires all of its keys (AND). The keys may contain the `*` and `?` wildcards unless the provider's `escape_filter_wildcards` is set.",
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validation.StringIsNotEmpty,
+		},
+	}
+}
+
+// ec2HasTagsSchema returns a *schema.Schema for the "has_tags" attribute,
+// constraining the selected objects to those with at least one of the given
+// tag keys, whatever their values, as "any_tag_keys" does. The keys of both
+// are converted into the same "tag-key" filter with buildEC2TagKeyFilterList,
+// which drops the empty keys, so that they can be computed conditionally.
+//
+// In Terraform configuration this looks like this, to select the objects
+// with a cost center tag:
+//
+// has_tags = ["CostCenter"]
+func ec2HasTagsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Only match objects having at least one of the given tag keys (OR), whatever their values, such as `[\"CostCenter\"]` for the objects with a `CostCenter` tag. The keys are combined with those of `any_tag_keys`, and the empty keys are ignored.",
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// ec2RequiredTagKeysSchema returns a *schema.Schema for the
+// "required_tag_keys" attribute, constraining the selected objects to those
+// with all of the given tag keys, whatever their values. Its value is
+// converted into one "tag-key" filter per key with
+// buildEC2RequiredTagKeyFilterList.
+//
+// Contrast with ec2AnyTagKeysSchema, whose objects need only one of the keys.
+func ec2RequiredTagKeysSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Only match objects with all of the given tag keys (AND), whatever their values. Unlike `any_tag_keys`, which requires only one of its keys (OR). The keys may contain the `*` and `?` wildcards unless the provider's `escape_filter_wildcards` is set.",
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validation.StringIsNotEmpty,
+		},
+	}
+}
+
+// buildEC2TagKeyFilterList takes a list of tag keys and produces a
+// []*ec2.Filter holding a single "tag-key" filter with all the keys as its
+// values, matching the objects with any of the keys: as with every filter, an
+// object matches if it matches any of the values. Duplicate and empty keys
+// are dropped. It returns nil if no keys are left.
+func buildEC2TagKeyFilterList(keys []string) []*ec2.Filter {
+	var values []string
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		values = appendUniqueString(values, key)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	return []*ec2.Filter{
+		{
+			Name:   aws.String("tag-key"),
+			Values: aws.StringSlice(values),
+		},
+	}
+}
+
+// buildEC2RequiredTagKeyFilterList takes a list of tag keys and produces a
+// []*ec2.Filter holding one "tag-key" filter per key, matching the objects
+// with all of the keys: an object has to match every filter. Duplicate and
+// empty keys are dropped. It returns nil if no keys are left.
+func buildEC2RequiredTagKeyFilterList(keys []string) []*ec2.Filter {
+	var unique []string
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		unique = appendUniqueString(unique, key)
+	}
+
+	var filters []*ec2.Filter
+	for _, key := range unique {
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String("tag-key"),
+			Values: aws.StringSlice([]string{key}),
+		})
+	}
+
+	return filters
+}
+
 // ec2AttributeFiltersFromMultimap returns an array of EC2 Filter objects to be used when listing resources.
 //
 // The keys of the specified map are the resource attributes names used in the filter - see the documentation
@@ -76,16 +420,31 @@ func buildEC2TagFilterList(tags []*ec2.Tag) []*ec2.Filter {
 // The values of the specified map are lists of resource attribute values used in the filter. The resource can
 // match any of the filter values to be included in the result.
 // See https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/Using_Filtering.html#Filtering_Resources_CLI for more details.
+//
+// The filters are ordered by name, and the repeated values of each are dropped, the others keeping the order they
+// are given in, so that the filters do not depend on the iteration order of the map, as when they are stored in the
+// state. This does not change which resources match, as the EC2 API ignores the order and the repetition of values.
 func ec2AttributeFiltersFromMultimap(m map[string][]string) []*ec2.Filter {
 	if len(m) == 0 {
 		return nil
 	}
 
-	filters := []*ec2.Filter{}
-	for k, v := range m {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	filters := make([]*ec2.Filter, 0, len(names))
+	for _, k := range names {
+		values := make([]string, 0, len(m[k]))
+		for _, v := range m[k] {
+			values = appendUniqueString(values, v)
+		}
+
 		filters = append(filters, &ec2.Filter{
 			Name:   aws.String(k),
-			Values: aws.StringSlice(v),
+			Values: aws.StringSlice(values),
 		})
 	}
 
@@ -94,23 +453,132 @@ func ec2AttributeFiltersFromMultimap(m map[string][]string) []*ec2.Filter {
 
 // ec2TagFiltersFromMap returns an array of EC2 Filter objects to be used when listing resources.
 //
-// The filters represent exact matches for all the resource tags in the given key/value map.
-func ec2TagFiltersFromMap(m map[string]interface{}) []*ec2.Filter {
+// The filters represent exact matches for all the resource tags in the given key/value map, ordered by key. Tags
+// with the reserved "aws:" prefix are ignored, except those of ec2FilterableAwsTagKeys and the given allowed AWS tag
+// keys, as are the tags ignored by the given configuration, typically the provider's ignore_tags, which may be nil.
+func ec2TagFiltersFromMap(m map[string]interface{}, ignoreConfig *keyvaluetags.IgnoreConfig, allowedAwsKeys ...string) []*ec2.Filter {
 	if len(m) == 0 {
 		return nil
 	}
 
+	allowed := append(append([]string{}, ec2FilterableAwsTagKeys...), allowedAwsKeys...)
+
 	filters := []*ec2.Filter{}
-	for _, tag := range keyvaluetags.New(m).IgnoreAws().Ec2Tags() {
+	for _, tag := range keyvaluetags.New(m).IgnoreAwsExcept(allowed...).IgnoreConfig(ignoreConfig).Ec2Tags() {
 		filters = append(filters, &ec2.Filter{
 			Name:   aws.String(fmt.Sprintf("tag:%s", aws.StringValue(tag.Key))),
 			Values: []*string{tag.Value},
 		})
 	}
+	sort.Slice(filters, func(i, j int) bool {
+		return aws.StringValue(filters[i].Name) < aws.StringValue(filters[j].Name)
+	})
 
 	return filters
 }
 
+// buildEC2CloudFormationStackNameFilterList returns the filter matching the objects of the AWS CloudFormation stack
+// with the given name, on the ec2CloudFormationStackNameTagKey tag which is otherwise ignored for its "aws:" prefix,
+// or nil if the name is empty.
+func buildEC2CloudFormationStackNameFilterList(stackName string) []*ec2.Filter {
+	if stackName == "" {
+		return nil
+	}
+
+	return ec2TagFiltersFromMap(map[string]interface{}{ec2CloudFormationStackNameTagKey: stackName}, nil, ec2CloudFormationStackNameTagKey)
+}
+
+// ec2OwnerIDsSchema returns a *schema.Schema for a set of AWS account IDs
+// used to constrain the results of a data source that wraps a "Describe..."
+// API call on EC2 objects which can be shared across accounts, such as AMIs,
+// snapshots, transit gateways or prefix lists. The special value "self"
+// refers to the account the provider is configured for.
+//
+// It is conventional for an attribute of this type to be called "owner_ids",
+// and for its value to be converted with buildEC2OwnerSelection.
+func ec2OwnerIDsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "Only match objects owned by the given AWS account IDs. Use `self` for the account of the provider.",
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validation.StringMatch(ec2OwnerIDRegexp, "must be a 12 digit AWS account ID or \"self\""),
+		},
+	}
+}
+
+// buildEC2OwnerIDFilterList takes a list of AWS account IDs, as given in an
+// attribute conforming to ec2OwnerIDsSchema, and produces a []*ec2.Filter
+// matching objects owned by any of them via the "owner-id" filter.
+//
+// Unlike the dedicated "Owners" parameter of some "Describe..." API calls, the
+// "owner-id" filter does not accept "self", so it is replaced with the given
+// account ID, which is the one resolved via the caller identity when the
+// provider was configured.
+func buildEC2OwnerIDFilterList(ownerIDs []string, accountID string) ([]*ec2.Filter, error) {
+	var values []string
+
+	for _, ownerID := range ownerIDs {
+		if ownerID == "" {
+			continue
+		}
+
+		if ownerID == ec2OwnerSelf {
+			if accountID == "" {
+				return nil, fmt.Errorf("unable to resolve owner ID %q: the AWS account ID of the provider is unknown", ec2OwnerSelf)
+			}
+			ownerID = accountID
+		}
+
+		values = appendUniqueString(values, ownerID)
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return []*ec2.Filter{
+		{
+			Name:   aws.String("owner-id"),
+			Values: aws.StringSlice(values),
+		},
+	}, nil
+}
+
+// buildEC2OwnerSelection takes a list of AWS account IDs, as given in an
+// attribute conforming to ec2OwnerIDsSchema, and returns either the values to
+// pass in the dedicated owner list parameter of the "Describe..." input of the
+// given EC2 resource type (e.g. "Owners" for AMIs), when it has one, or the
+// "owner-id" filter of buildEC2OwnerIDFilterList otherwise.
+//
+// This is where "self" is routed: the dedicated parameter accepts it, so it is
+// passed through for the API to resolve, while it is replaced with the given
+// account ID in the filter.
+func buildEC2OwnerSelection(resourceType string, ownerIDs []string, accountID string) ([]*string, []*ec2.Filter, error) {
+	metadata, _ := tfec2.ResourceTypeMetadataFor(resourceType)
+
+	if metadata.OwnersParameter == "" {
+		filters, err := buildEC2OwnerIDFilterList(ownerIDs, accountID)
+		return nil, filters, err
+	}
+
+	var owners []string
+	for _, ownerID := range ownerIDs {
+		if ownerID == "" {
+			continue
+		}
+
+		owners = appendUniqueString(owners, ownerID)
+	}
+
+	if len(owners) == 0 {
+		return nil, nil, nil
+	}
+
+	return aws.StringSlice(owners), nil, nil
+}
+
 // ec2CustomFiltersSchema returns a *schema.Schema that represents
 // a set of custom filtering criteria that a user can specify as input
 // to a data source that wraps one of the many "Describe..." API calls
@@ -126,6 +594,34 @@ func ec2TagFiltersFromMap(m map[string]interface{}) []*ec2.Filter {
 //   name   = "availabilityZone"
 //   values = ["us-west-2a", "us-west-2b"]
 // }
+//
+// The "wildcard" attribute of a block opts it into wildcard matching when
+// the provider escapes them by default (see escape_filter_wildcards), and
+// setting "enabled" to false leaves the block out, e.g.
+//
+// filter {
+//   name    = "tag:Environment"
+//   values  = var.environment == "" ? [] : [var.environment]
+//   enabled = var.environment != ""
+// }
+//
+// The "value_transform" attribute converts each value before the filter is
+// built, see ec2FilterValueTransform, such as to filter by the IDs of the
+// given ARNs:
+//
+// filter {
+//   name            = "vpc-id"
+//   values          = [var.vpc_arn]
+//   value_transform = "arn_resource_id"
+// }
+//
+// The "values_file" attribute reads further values from a file, one per line,
+// see readEC2FilterValuesFile, such as for a generated allowlist:
+//
+// filter {
+//   name        = "image-id"
+//   values_file = "${path.module}/allowed-amis.txt"
+// }
 func ec2CustomFiltersSchema() *schema.Schema {
 	return &schema.Schema{
 		Type:     schema.TypeSet,
@@ -133,52 +629,922 @@ func ec2CustomFiltersSchema() *schema.Schema {
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
 				"name": {
-					Type:     schema.TypeString,
-					Required: true,
+					Type:             schema.TypeString,
+					Required:         true,
+					ValidateDiagFunc: validateEC2FilterName,
 				},
 				"values": {
-					Type:     schema.TypeSet,
-					Required: true,
+					Type:        schema.TypeSet,
+					Optional:    true,
+					Description: "The values of the filter, any of which an object must match. Required unless `values_file` is set.",
 					Elem: &schema.Schema{
 						Type: schema.TypeString,
 					},
 				},
+				"values_file": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringIsNotEmpty,
+					Description:  "The path of a file of further values, one per line, merged with `values`. The lines are trimmed, and the blank lines and those starting with `#` are ignored. The file is read whenever the filter is built, so a data source picks up its changes on every plan.",
+				},
+				"wildcard": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Whether the `*` and `?` characters of the values are wildcards even when the provider's `escape_filter_wildcards` is set. They always are otherwise. When set, every value must contain at least one of them, and when unset, the values containing `*` are warned about.",
+				},
+				"enabled": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Whether the filter is applied, to toggle it without a `dynamic` block. The `values` of a disabled filter may be empty.",
+				},
+				"value_transform": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      ec2FilterValueTransformNone,
+					ValidateFunc: validation.StringInSlice(ec2FilterValueTransforms, false),
+					Description:  "How each of the `values` is converted before filtering: `none`, `arn_resource_id` for the last part of the resource of an ARN, such as the ID of an EC2 object, `lowercase`, `uppercase` or `trim` for the value without leading and trailing whitespace.",
+				},
 			},
 		},
 	}
 }
 
+// KnownEC2FilterNames are the names of the common filters of the
+// "Describe..." API calls of the EC2 API. The filters of which are accepted
+// depend on the call, and AWS adds new ones, so a name which is not listed is
+// not necessarily invalid, but it is more likely mistyped: the API matches no
+// objects rather than failing on unknown filters. The "tag:<key>" filters and
+// those starting with "tag-" are always valid, see validateEC2FilterName.
+var KnownEC2FilterNames = []string{
+	"allocation-id",
+	"architecture",
+	"association.association-id",
+	"association.gateway-id",
+	"association.main",
+	"association.route-table-association-id",
+	"association.route-table-id",
+	"association.subnet-id",
+	"attachment.attachment-id",
+	"attachment.instance-id",
+	"attachment.state",
+	"attachment.status",
+	"attachment.vpc-id",
+	"availability-zone",
+	"availability-zone-id",
+	"block-device-mapping.device-name",
+	"block-device-mapping.snapshot-id",
+	"block-device-mapping.volume-id",
+	"block-device-mapping.volume-size",
+	"block-device-mapping.volume-type",
+	"cidr",
+	"cidr-block",
+	"cidr-block-association.cidr-block",
+	"cidr-block-association.state",
+	"create-time",
+	"default-for-az",
+	"deprecation-time",
+	"description",
+	"dhcp-options-id",
+	"dns-name",
+	"domain",
+	"egress.ip-permission.cidr",
+	"egress.ip-permission.from-port",
+	"egress.ip-permission.group-id",
+	"egress.ip-permission.protocol",
+	"egress.ip-permission.to-port",
+	"ena-support",
+	"encrypted",
+	"entry.cidr",
+	"event.code",
+	"flow-log-id",
+	"group-id",
+	"group-name",
+	"hibernation-options.configured",
+	"host-id",
+	"hypervisor",
+	"iam-instance-profile.arn",
+	"image-id",
+	"image-type",
+	"instance-id",
+	"instance-lifecycle",
+	"instance-state-code",
+	"instance-state-name",
+	"instance-type",
+	"interface-type",
+	"internet-gateway-id",
+	"ip-permission.cidr",
+	"ip-permission.from-port",
+	"ip-permission.group-id",
+	"ip-permission.protocol",
+	"ip-permission.to-port",
+	"ipv6-cidr-block-association.ipv6-cidr-block",
+	"is-default",
+	"is-public",
+	"kernel-id",
+	"key",
+	"key-name",
+	"launch-template-id",
+	"launch-template-name",
+	"launch-time",
+	"mac-address",
+	"manifest-location",
+	"monitoring-state",
+	"name",
+	"nat-gateway-id",
+	"network-acl-id",
+	"network-interface-id",
+	"network-interface.network-interface-id",
+	"network-interface.subnet-id",
+	"network-interface.vpc-id",
+	"owner-alias",
+	"owner-id",
+	"placement-group-name",
+	"platform",
+	"platform-details",
+	"prefix-list-id",
+	"prefix-list-name",
+	"private-dns-name",
+	"private-ip-address",
+	"product-code",
+	"public-ip",
+	"ramdisk-id",
+	"requester-id",
+	"requester-managed",
+	"reservation-id",
+	"resource-id",
+	"resource-type",
+	"root-device-name",
+	"root-device-type",
+	"route-table-id",
+	"route.destination-cidr-block",
+	"route.destination-ipv6-cidr-block",
+	"route.destination-prefix-list-id",
+	"route.gateway-id",
+	"route.nat-gateway-id",
+	"route.state",
+	"route.transit-gateway-id",
+	"route.vpc-peering-connection-id",
+	"security-group-rule-id",
+	"service-name",
+	"size",
+	"snapshot-id",
+	"source-dest-check",
+	"spot-instance-request-id",
+	"start-time",
+	"state",
+	"status",
+	"subnet-arn",
+	"subnet-id",
+	"transit-gateway-id",
+	"virtualization-type",
+	"volume-id",
+	"volume-size",
+	"volume-type",
+	"vpc-endpoint-id",
+	"vpc-endpoint-type",
+	"vpc-id",
+	"vpc-peering-connection-id",
+}
+
+// validateEC2FilterName is the ValidateDiagFunc of the "name" of the "filter"
+// blocks, returning a warning rather than an error if the name is neither one
+// of KnownEC2FilterNames nor a tag filter, "tag:" followed by the key or
+// exactly "tag-key" or "tag-value", since it may be a filter AWS added.
+func validateEC2FilterName(v interface{}, path cty.Path) diag.Diagnostics {
+	name := normalizeEC2FilterName(v.(string))
+
+	if strings.HasPrefix(name, "tag:") || name == "tag-key" || name == "tag-value" {
+		return nil
+	}
+
+	for _, known := range KnownEC2FilterNames {
+		if name == known {
+			return nil
+		}
+	}
+
+	return diag.Diagnostics{
+		{
+			Severity:      diag.Warning,
+			Summary:       fmt.Sprintf("Unknown EC2 filter name %q", name),
+			Detail:        "The EC2 API matches no objects rather than failing on an unknown filter name, so check it is not mistyped and is supported by the API of the data source. It is accepted in case it is a filter AWS added recently.",
+			AttributePath: path,
+		},
+	}
+}
+
+// normalizeEC2FilterName returns the given filter name with its "tag:" or
+// "tag-key" prefix, in any case, lowercased, since the EC2 API only knows the
+// lowercase filter names and matches no objects for the others. The rest of
+// the name is left untouched, the tag keys being case-sensitive.
+func normalizeEC2FilterName(name string) string {
+	for _, prefix := range []string{"tag:", "tag-key"} {
+		if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			return prefix + name[len(prefix):]
+		}
+	}
+
+	return name
+}
+
 // buildEC2CustomFilterList takes the set value extracted from a schema
 // attribute conforming to the schema returned by ec2CustomFiltersSchema,
 // and transforms it into a []*ec2.Filter representing the same filter
 // expressions which is ready to pass into the "Filters" attribute on most
 // of the "Describe..." functions in the EC2 API.
 //
+// The "tag:" and "tag-key" prefixes of the names are lowercased with
+// normalizeEC2FilterName, so that "Tag:Name" is the "tag:Name" filter.
+//
+// The values of each filter are converted by its "value_transform" with
+// ec2FilterValueTransform, then deduplicated, which is safe since an object
+// matches a filter if it matches any of its values, and sorted, so that the
+// filters do not depend on the order the values are given in.
+//
+// The blocks sharing a name are merged into a single filter with the union
+// of their values, in the position of the first of them, since the EC2 API
+// ANDs repeated names at best: the blocks of a name are ORed, as are the
+// values of a block. Blocks with distinct names are still ANDed, which the
+// EC2 API cannot express otherwise; see buildEC2CustomFilterBlockList for
+// making a request per block instead.
+//
+// The returned diagnostics hold an error for each value which cannot be
+// converted, which is left out, for each value without a wildcard of
+// the blocks setting "wildcard", so that a mistyped pattern is not silently
+// matched exactly, and a warning for each value containing the * wildcard of
+// the other blocks, since it is matched literally when the provider escapes
+// wildcards. They also hold an error for each value which is not of the
+// format of its filter, see validateEC2CustomFilterValues. The filters are
+// returned either way.
+//
 // This function is intended only to be used in conjunction with
 // ec2CustomFitlersSchema. See the docs on that function for more details
 // on the configuration pattern this is intended to support.
-func buildEC2CustomFilterList(filterSet *schema.Set) []*ec2.Filter {
+func buildEC2CustomFilterList(filterSet *schema.Set) ([]*ec2.Filter, diag.Diagnostics) {
+	filters, diags := buildEC2CustomFilterBlockList(filterSet, false)
+
+	return mergeEC2CustomFilterBlocks(filters), diags
+}
+
+// buildEC2CustomFilterBlockList is like buildEC2CustomFilterList, but returns
+// a filter for each enabled block, in the order of the set elements, without
+// merging the blocks sharing a name, for use when each block is sent in a
+// request of its own. The values of the blocks which do not opt into
+// wildcards with "wildcard" are escaped if escapeWildcards is set.
+func buildEC2CustomFilterBlockList(filterSet *schema.Set, escapeWildcards bool) ([]*ec2.Filter, diag.Diagnostics) {
 	if filterSet == nil {
-		return []*ec2.Filter{}
+		return []*ec2.Filter{}, nil
 	}
 
+	var diags diag.Diagnostics
+
 	customFilters := filterSet.List()
-	filters := make([]*ec2.Filter, len(customFilters))
+	filters := make([]*ec2.Filter, 0, len(customFilters))
 
-	for filterIdx, customFilterI := range customFilters {
+	for _, customFilterI := range customFilters {
 		customFilterMapI := customFilterI.(map[string]interface{})
-		name := customFilterMapI["name"].(string)
+		if !ec2CustomFilterEnabled(customFilterMapI) {
+			continue
+		}
+
+		name := normalizeEC2FilterName(customFilterMapI["name"].(string))
+		transform, _ := customFilterMapI["value_transform"].(string)
 		valuesI := customFilterMapI["values"].(*schema.Set).List()
-		values := make([]*string, len(valuesI))
-		for valueIdx, valueI := range valuesI {
-			values[valueIdx] = aws.String(valueI.(string))
+		rawValues := make([]string, 0, len(valuesI))
+		for _, valueI := range valuesI {
+			rawValues = append(rawValues, valueI.(string))
+		}
+		if valuesFile, _ := customFilterMapI["values_file"].(string); valuesFile != "" {
+			fileValues, err := readEC2FilterValuesFile(valuesFile)
+			if err != nil {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  fmt.Sprintf("filter %s: %s", name, err),
+				})
+				continue
+			}
+			if len(fileValues) == 0 && len(rawValues) == 0 {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  fmt.Sprintf("filter %s: values_file %q has no values", name, valuesFile),
+					Detail:   "The EC2 API rejects the filters without values. Disable the filter when the file is empty.",
+				})
+				continue
+			}
+			rawValues = append(rawValues, fileValues...)
 		}
 
-		filters[filterIdx] = &ec2.Filter{
+		values := make([]string, 0, len(rawValues))
+		for _, rawValue := range rawValues {
+			value, err := ec2FilterValueTransform(transform, rawValue)
+			if err != nil {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  fmt.Sprintf("filter %s: %s", name, err),
+				})
+				continue
+			}
+			values = appendUniqueString(values, value)
+		}
+		// The values are ORed, so their order does not matter to the API, but
+		// a sorted order keeps the requests deterministic.
+		sort.Strings(values)
+
+		wildcard, _ := customFilterMapI["wildcard"].(bool)
+		diags = append(diags, validateEC2CustomFilterWildcards(name, values, wildcard)...)
+		diags = append(diags, validateEC2CustomFilterValues(name, values)...)
+
+		filter := &ec2.Filter{
 			Name:   &name,
-			Values: values,
+			Values: aws.StringSlice(values),
+		}
+		if escapeWildcards && !wildcard {
+			escapeEC2FilterWildcards(filter)
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, diags
+}
+
+// mergeEC2CustomFilterBlocks merges the given filters sharing a name, as
+// returned by buildEC2CustomFilterBlockList, into one with the union of their
+// values, deduplicated and sorted, in the position of the first of them.
+// Unlike mergeEC2FilterLists, the order of the filters is kept.
+func mergeEC2CustomFilterBlocks(blocks []*ec2.Filter) []*ec2.Filter {
+	names := make([]string, 0, len(blocks))
+	values := make(map[string][]string, len(blocks))
+
+	for _, block := range blocks {
+		name := aws.StringValue(block.Name)
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+			values[name] = []string{}
+		}
+		for _, value := range block.Values {
+			values[name] = appendUniqueString(values[name], aws.StringValue(value))
 		}
 	}
 
+	filters := make([]*ec2.Filter, 0, len(names))
+	for _, name := range names {
+		sort.Strings(values[name])
+
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String(name),
+			Values: aws.StringSlice(values[name]),
+		})
+	}
+
 	return filters
 }
+
+// validateEC2CustomFilterWildcards returns the diagnostics of the given values
+// of the "filter" block with the given name, as described on
+// buildEC2CustomFilterList.
+func validateEC2CustomFilterWildcards(name string, values []string, wildcard bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, value := range values {
+		switch {
+		case wildcard && !strings.ContainsAny(value, "*?"):
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("filter %s: value %q has no wildcard", name, value),
+				Detail:   "The values of a filter setting `wildcard` must contain at least one `*` or `?` wildcard. Unset `wildcard` to match the value exactly.",
+			})
+		case !wildcard && strings.Contains(value, "*"):
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("filter %s: value %q contains \"*\" but wildcard is not set", name, value),
+				Detail:   "The `*` is matched literally when the provider's `escape_filter_wildcards` is set, and as a wildcard otherwise. Set `wildcard = true` if it is meant as a wildcard.",
+			})
+		}
+	}
+
+	return diags
+}
+
+const (
+	ec2FilterValueTransformNone          = "none"
+	ec2FilterValueTransformARNResourceID = "arn_resource_id"
+	ec2FilterValueTransformLowercase     = "lowercase"
+	ec2FilterValueTransformUppercase     = "uppercase"
+	ec2FilterValueTransformTrim          = "trim"
+)
+
+// ec2FilterValueTransforms are the values of the "value_transform" attribute
+// of the "filter" blocks, see ec2FilterValueTransform.
+var ec2FilterValueTransforms = []string{
+	ec2FilterValueTransformNone,
+	ec2FilterValueTransformARNResourceID,
+	ec2FilterValueTransformLowercase,
+	ec2FilterValueTransformUppercase,
+	ec2FilterValueTransformTrim,
+}
+
+// ec2FilterValueTransform returns the given value of a "filter" block
+// converted by the given transform of ec2FilterValueTransforms, the empty
+// transform of the blocks read from a state without the "value_transform"
+// attribute leaving it unchanged. The "arn_resource_id" transform parses the
+// value with arn.Parse and returns the part of its resource after the last
+// "/" or ":", e.g. "vpc-0123456789abcdef0" for
+// "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0123456789abcdef0", and fails
+// for a value which is not an ARN.
+func ec2FilterValueTransform(transform, value string) (string, error) {
+	switch transform {
+	case "", ec2FilterValueTransformNone:
+		return value, nil
+	case ec2FilterValueTransformARNResourceID:
+		parsed, err := arn.Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("value %q is not a valid ARN: %w", value, err)
+		}
+
+		resource := parsed.Resource
+		if i := strings.LastIndexAny(resource, "/:"); i >= 0 {
+			resource = resource[i+1:]
+		}
+		if resource == "" {
+			return "", fmt.Errorf("value %q is an ARN without a resource ID", value)
+		}
+
+		return resource, nil
+	case ec2FilterValueTransformLowercase:
+		return strings.ToLower(value), nil
+	case ec2FilterValueTransformUppercase:
+		return strings.ToUpper(value), nil
+	case ec2FilterValueTransformTrim:
+		return strings.TrimSpace(value), nil
+	default:
+		return "", fmt.Errorf("unsupported value transform %q", transform)
+	}
+}
+
+// ec2CIDRFilterNames are the names of the filters of KnownEC2FilterNames
+// whose values are CIDR blocks, see validateEC2CustomFilterValues.
+var ec2CIDRFilterNames = []string{
+	"cidr",
+	"cidr-block",
+	"cidr-block-association.cidr-block",
+	"egress.ip-permission.cidr",
+	"entry.cidr",
+	"ip-permission.cidr",
+	"ipv6-cidr-block-association.ipv6-cidr-block",
+	"route.destination-cidr-block",
+	"route.destination-ipv6-cidr-block",
+}
+
+// validateEC2CustomFilterValues returns an error diagnostic for each of the
+// given values of the "filter" block with the given name which is not of the
+// format of the values of the filter: a well-formed ID for the filters by ID
+// of the resource types of tfec2.ResourceTypes, such as "vpc-id", and a CIDR
+// block for those of ec2CIDRFilterNames. The values with wildcards are not
+// checked, nor are those of the other filters.
+func validateEC2CustomFilterValues(name string, values []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var resourceType string
+	for _, t := range tfec2.ResourceTypes() {
+		if metadata, _ := tfec2.ResourceTypeMetadataFor(t); metadata.IDFilterName == name {
+			resourceType = t
+			break
+		}
+	}
+
+	for _, value := range values {
+		if strings.ContainsAny(value, "*?") {
+			continue
+		}
+
+		var err error
+		switch {
+		case resourceType != "":
+			err = tfec2.ValidateResourceID(resourceType, value)
+		case contains(ec2CIDRFilterNames, name):
+			if _, _, cidrErr := net.ParseCIDR(value); cidrErr != nil {
+				err = fmt.Errorf("%q is not a valid CIDR block", value)
+			}
+		}
+
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("filter %s: %s", name, err),
+				Detail:   "The EC2 API matches no objects rather than failing on malformed filter values.",
+			})
+		}
+	}
+
+	return diags
+}
+
+// splitEC2CustomFilterDiagnostics returns the warnings of the given
+// diagnostics returned by buildEC2CustomFilterList, to be returned by the
+// caller along with its own, and their errors as a single error, or nil if
+// there are none.
+func splitEC2CustomFilterDiagnostics(diags diag.Diagnostics) (diag.Diagnostics, error) {
+	var warnings diag.Diagnostics
+	var errs []string
+
+	for _, d := range diags {
+		if d.Severity == diag.Warning {
+			warnings = append(warnings, d)
+			continue
+		}
+		errs = append(errs, d.Summary)
+	}
+
+	if len(errs) == 0 {
+		return warnings, nil
+	}
+
+	return warnings, fmt.Errorf("%s", strings.Join(errs, "; "))
+}
+
+// readEC2FilterValuesFile returns the values of the "values_file" of a
+// "filter" block at the given path: its lines, trimmed, without the blank
+// ones and those starting with "#". The error of a file which cannot be read
+// names the path.
+func readEC2FilterValuesFile(path string) ([]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading values_file %q: %w", path, err)
+	}
+
+	var values []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		values = append(values, line)
+	}
+
+	return values, nil
+}
+
+// ec2CustomFilterEnabled returns whether the given "filter" block is enabled,
+// which blocks read from a state without the "enabled" attribute are.
+func ec2CustomFilterEnabled(customFilter map[string]interface{}) bool {
+	enabled, ok := customFilter["enabled"].(bool)
+	return !ok || enabled
+}
+
+// validateEC2CustomFilters returns an error if any of the enabled blocks of
+// the given set value of an attribute conforming to ec2CustomFiltersSchema
+// has neither values nor a values_file, which the EC2 API rejects. Disabled blocks are not checked,
+// so that their values can be computed from the same condition.
+func validateEC2CustomFilters(filterSet *schema.Set) error {
+	if filterSet == nil {
+		return nil
+	}
+
+	for _, customFilterI := range filterSet.List() {
+		customFilterMapI := customFilterI.(map[string]interface{})
+		if !ec2CustomFilterEnabled(customFilterMapI) {
+			continue
+		}
+
+		if valuesFile, _ := customFilterMapI["values_file"].(string); customFilterMapI["values"].(*schema.Set).Len() == 0 && valuesFile == "" {
+			return fmt.Errorf("filter %s: values must not be empty unless values_file is set or the filter is disabled", customFilterMapI["name"].(string))
+		}
+	}
+
+	return nil
+}
+
+// buildEC2CustomFilterListEscapingWildcards is like buildEC2CustomFilterList,
+// but escapes the wildcards in the values of the blocks which do not opt into
+// them with "wildcard", for use when escape_filter_wildcards is set.
+func buildEC2CustomFilterListEscapingWildcards(filterSet *schema.Set) ([]*ec2.Filter, diag.Diagnostics) {
+	// The values are escaped block by block, before the blocks sharing a name
+	// are merged, as only some of them may opt into wildcards.
+	filters, diags := buildEC2CustomFilterBlockList(filterSet, true)
+
+	return mergeEC2CustomFilterBlocks(filters), diags
+}
+
+// ec2FilterWildcardReplacer escapes the characters of EC2 filter values which
+// are otherwise interpreted as wildcards, and the escape character itself.
+var ec2FilterWildcardReplacer = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`)
+
+// escapeEC2FilterValue returns the given EC2 filter value with its * and ?
+// wildcards escaped, so that they are matched literally.
+func escapeEC2FilterValue(value string) string {
+	return ec2FilterWildcardReplacer.Replace(value)
+}
+
+// ec2FilterWildcardUnescaper reverses ec2FilterWildcardReplacer.
+var ec2FilterWildcardUnescaper = strings.NewReplacer(`\\`, `\`, `\*`, `*`, `\?`, `?`)
+
+// unescapeEC2FilterValue returns the given EC2 filter value, as escaped by
+// escapeEC2FilterValue, with its wildcards and escape characters unescaped.
+func unescapeEC2FilterValue(value string) string {
+	return ec2FilterWildcardUnescaper.Replace(value)
+}
+
+// escapeEC2FilterWildcards escapes the wildcards of all the values of the
+// given filters in place, so that they are matched literally.
+func escapeEC2FilterWildcards(filters ...*ec2.Filter) {
+	for _, filter := range filters {
+		for i, value := range filter.Values {
+			filter.Values[i] = aws.String(escapeEC2FilterValue(aws.StringValue(value)))
+		}
+	}
+}
+
+// ec2NetworkInterfaceFilterSchemas returns the convenience attributes of data
+// sources and resources selecting EC2 network interfaces, to be merged into
+// their schema. The attributes are converted into filters with
+// buildEC2NetworkInterfaceAttributeFilterList.
+//
+// In Terraform configuration this looks like this, to only select the
+// interfaces of NAT gateways:
+//
+// requester_managed = true
+// interface_type    = "nat_gateway"
+func ec2NetworkInterfaceFilterSchemas() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"requester_managed": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Only match network interfaces which are (`true`) or are not (`false`) managed by an AWS service.",
+		},
+		"interface_type": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Only match network interfaces of the given type, e.g. `interface`, `nat_gateway` or `lambda`.",
+			ValidateFunc: validation.StringInSlice(tfec2.NetworkInterfaceTypeFilter_Values(), false),
+		},
+	}
+}
+
+// buildEC2NetworkInterfaceAttributeFilterList reads the attributes returned by
+// ec2NetworkInterfaceFilterSchemas from the given *schema.ResourceData and
+// produces the corresponding "requester-managed" and "interface-type" filters
+// with buildEC2AttributeFilterList. An unset requester_managed attribute leaves
+// the filter out, while false only matches interfaces not managed by AWS.
+func buildEC2NetworkInterfaceAttributeFilterList(d *schema.ResourceData) []*ec2.Filter {
+	attrs := map[string]string{
+		"interface-type": d.Get("interface_type").(string),
+	}
+
+	// GetOkExists is the only way to tell an explicit false from an unset bool.
+	if v, ok := d.GetOkExists("requester_managed"); ok { // nolint:staticcheck
+		attrs["requester-managed"] = strconv.FormatBool(v.(bool))
+	}
+
+	return buildEC2AttributeFilterList(attrs)
+}
+
+// ec2SpotInstanceFilterSchemas returns the convenience attributes of data
+// sources selecting EC2 instances by the Spot request they were launched for,
+// to be merged into their schema with mergeSchemas. The attributes are
+// converted into filters with buildEC2SpotInstanceAttributeFilterList.
+//
+// In Terraform configuration this looks like this, to only select the
+// instances of a Spot Fleet:
+//
+// spot_fleet_request_id = "sfr-0123abcd-0123-abcd-0123-0123456789ab"
+func ec2SpotInstanceFilterSchemas() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"spot_instance_request_id": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Only match the instance launched for the given Spot Instance request.",
+			ValidateFunc: validation.StringMatch(ec2SpotInstanceRequestIDRegexp, "must be a Spot Instance request ID (sir-...)"),
+		},
+		"spot_fleet_request_id": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Only match the instances launched by the given Spot Fleet request, from their `" + ec2SpotFleetRequestIDTagKey + "` tag.",
+			ValidateFunc: validation.StringMatch(ec2SpotFleetRequestIDRegexp, "must be a Spot Fleet request ID (sfr-...)"),
+		},
+	}
+}
+
+// buildEC2SpotInstanceAttributeFilterList reads the attributes returned by
+// ec2SpotInstanceFilterSchemas from the given *schema.ResourceData and
+// produces the corresponding "spot-instance-request-id" and
+// "tag:aws:ec2spot:fleet-request-id" filters with buildEC2AttributeFilterList.
+func buildEC2SpotInstanceAttributeFilterList(d *schema.ResourceData) []*ec2.Filter {
+	return buildEC2AttributeFilterList(map[string]string{
+		"spot-instance-request-id":                          d.Get("spot_instance_request_id").(string),
+		fmt.Sprintf("tag:%s", ec2SpotFleetRequestIDTagKey): d.Get("spot_fleet_request_id").(string),
+	})
+}
+
+// ec2InstanceHibernationFilterSchemas returns the convenience attribute of
+// data sources selecting EC2 instances by whether they were launched with
+// hibernation enabled, to be merged into their schema with mergeSchemas. The
+// attribute is converted into a filter with
+// buildEC2InstanceHibernationAttributeFilterList.
+//
+// In Terraform configuration this looks like this, to only select the
+// instances which can be hibernated:
+//
+// hibernation_enabled = true
+func ec2InstanceHibernationFilterSchemas() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"hibernation_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Only match instances which were (`true`) or were not (`false`) launched with hibernation enabled.",
+		},
+	}
+}
+
+// buildEC2InstanceHibernationAttributeFilterList reads the attribute returned
+// by ec2InstanceHibernationFilterSchemas from the given *schema.ResourceData
+// and produces the corresponding "hibernation-options.configured" filter with
+// buildEC2AttributeFilterList. The nested attribute is matched against the
+// lowercase "true" or "false" the API serializes booleans as, and an unset
+// attribute leaves the filter out.
+func buildEC2InstanceHibernationAttributeFilterList(d *schema.ResourceData) []*ec2.Filter {
+	attrs := map[string]string{}
+
+	// GetOkExists is the only way to tell an explicit false from an unset bool.
+	if v, ok := d.GetOkExists("hibernation_enabled"); ok { // nolint:staticcheck
+		attrs["hibernation-options.configured"] = strconv.FormatBool(v.(bool))
+	}
+
+	return buildEC2AttributeFilterList(attrs)
+}
+
+// ec2InstanceStateFilter returns an "instance-state-name" filter matching the
+// EC2 instances in any of the given states, such as "running" and "stopped"
+// for the instances which are not terminated, or nil if none is given. The
+// repeated states are dropped, the others keeping the order they are given
+// in. An error is returned for a state which is not one of
+// ec2.InstanceStateName_Values, which the API would match no instance for.
+func ec2InstanceStateFilter(states ...string) (*ec2.Filter, error) {
+	var values []string
+
+	for _, state := range states {
+		valid := false
+		for _, known := range ec2.InstanceStateName_Values() {
+			if state == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid instance state %q, expected one of: %s", state, strings.Join(ec2.InstanceStateName_Values(), ", "))
+		}
+
+		values = appendUniqueString(values, state)
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return &ec2.Filter{
+		Name:   aws.String("instance-state-name"),
+		Values: aws.StringSlice(values),
+	}, nil
+}
+
+// ec2InstanceStateFilterSchemas returns the convenience attribute of data
+// sources selecting EC2 instances by state, to be merged into their schema
+// with mergeSchemas. The attribute is converted into a filter with
+// buildEC2InstanceStateAttributeFilterList.
+//
+// In Terraform configuration this looks like this, to only select the
+// instances which are not terminated nor being terminated:
+//
+// states = ["pending", "running", "stopping", "stopped"]
+func ec2InstanceStateFilterSchemas() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"states": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "Only match instances in any of the given states, such as `running` or `stopped`.",
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice(ec2.InstanceStateName_Values(), false),
+			},
+		},
+	}
+}
+
+// buildEC2InstanceStateAttributeFilterList reads the attribute returned by
+// ec2InstanceStateFilterSchemas from the given *schema.ResourceData and
+// produces the corresponding "instance-state-name" filter with
+// ec2InstanceStateFilter, its values sorted so that the filter does not
+// depend on the order of the set. An unset attribute leaves the filter out.
+func buildEC2InstanceStateAttributeFilterList(d *schema.ResourceData) ([]*ec2.Filter, error) {
+	var states []string
+	if v, ok := d.GetOk("states"); ok {
+		states = ExpandStringSliceofPointers(ExpandStringSet(v.(*schema.Set)))
+	}
+	sort.Strings(states)
+
+	filter, err := ec2InstanceStateFilter(states...)
+	if err != nil || filter == nil {
+		return nil, err
+	}
+
+	return []*ec2.Filter{filter}, nil
+}
+
+// mergeSchemas returns the given schema with the attributes of the given
+// convenience schemas, such as those of ec2SpotInstanceFilterSchemas, added.
+func mergeSchemas(s map[string]*schema.Schema, schemas ...map[string]*schema.Schema) map[string]*schema.Schema {
+	for _, m := range schemas {
+		for k, v := range m {
+			s[k] = v
+		}
+	}
+
+	return s
+}
+
+// ec2IDsSchema returns a *schema.Schema for a set of IDs of objects of the
+// given EC2 resource type (an ec2.ResourceType value), validated against the
+// ID format of that type.
+//
+// It is conventional for an attribute of this type to be called "ids", and
+// for its value to be converted with buildEC2IDSelection. It panics if the
+// resource type is not supported, which is caught when the provider schema
+// is built.
+func ec2IDsSchema(resourceType string) *schema.Schema {
+	if _, ok := tfec2.ResourceTypeMetadataFor(resourceType); !ok {
+		panic(fmt.Sprintf("unsupported EC2 resource type: %s", resourceType))
+	}
+
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "Only match objects with the given IDs.",
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+			ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+				if err := tfec2.ValidateResourceID(resourceType, v.(string)); err != nil {
+					errors = append(errors, fmt.Errorf("%s: %w", k, err))
+				}
+				return
+			},
+		},
+	}
+}
+
+// ec2IDsStrictSchema returns a *schema.Schema for the "ids_strict" attribute,
+// choosing how the IDs of the "ids" attribute are passed by
+// buildEC2Selection.
+func ec2IDsStrictSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     true,
+		Description: "Whether `ids` are passed in the dedicated ID parameter of the request, such as `SubnetIds`, which fails if any of the objects does not exist, rather than in the ID filter, such as `subnet-id`, which leaves the missing objects out.",
+	}
+}
+
+// buildEC2IDSelection takes a list of IDs of objects of the given EC2
+// resource type, as given in an attribute conforming to ec2IDsSchema, and
+// returns either the values to pass in the dedicated ID list parameter of the
+// "Describe..." input of that type (e.g. "VpcIds"), when it has one, or a
+// []*ec2.Filter matching the same IDs with the ID filter of that type. Both
+// compose with other filters, the objects having to match all of them.
+//
+// The dedicated parameter is preferred as it is evaluated more efficiently
+// by the API, but unlike the filter, it fails if any of the IDs do not exist.
+func buildEC2IDSelection(resourceType string, ids []string) ([]*string, []*ec2.Filter) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	metadata, _ := tfec2.ResourceTypeMetadataFor(resourceType)
+
+	if metadata.IDParameter != "" {
+		return aws.StringSlice(ids), nil
+	}
+
+	return nil, buildEC2IDFilterList(resourceType, ids)
+}
+
+// buildEC2IDFilterList returns a []*ec2.Filter matching the objects of the
+// given EC2 resource type with the given IDs with the ID filter of that type,
+// such as "subnet-id", whether or not the "Describe..." input of that type has
+// a dedicated ID list parameter. Unlike the parameter, the filter does not fail
+// when some of the IDs do not exist, the missing objects being left out.
+func buildEC2IDFilterList(resourceType string, ids []string) []*ec2.Filter {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	metadata, _ := tfec2.ResourceTypeMetadataFor(resourceType)
+
+	return []*ec2.Filter{
+		{
+			Name:   aws.String(metadata.IDFilterName),
+			Values: aws.StringSlice(ids),
+		},
+	}
+}
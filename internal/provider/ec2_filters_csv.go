@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	// ec2FiltersCSVHeaderAuto skips the first row of a filters CSV if it is a "name,value" header.
+	ec2FiltersCSVHeaderAuto = "auto"
+	// ec2FiltersCSVHeaderPresent always skips the first row of a filters CSV.
+	ec2FiltersCSVHeaderPresent = "present"
+	// ec2FiltersCSVHeaderAbsent reads the first row of a filters CSV as a filter.
+	ec2FiltersCSVHeaderAbsent = "absent"
+)
+
+// ec2FiltersCSVSchema returns a *schema.Schema for the "filters_csv"
+// attribute, loading additional filters from a local CSV file of name,value
+// rows, as read by ec2FiltersFromCSV.
+func ec2FiltersCSVSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Only match objects matching the filters read from a local CSV file of two columns, the name and a value of a filter. The rows with the same name are grouped into a single filter matching any of their values. The values are matched like those of a `filter` block, and the filters are combined with the other selection attributes.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"path": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringIsNotEmpty,
+					Description:  "The path of the CSV file.",
+				},
+				"header": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      ec2FiltersCSVHeaderAuto,
+					ValidateFunc: validation.StringInSlice([]string{ec2FiltersCSVHeaderAuto, ec2FiltersCSVHeaderPresent, ec2FiltersCSVHeaderAbsent}, false),
+					Description:  "How the first row of the file is read: `auto` skips it if its columns are `name` and `value`, ignoring case, `present` always skips it and `absent` reads it as a filter.",
+				},
+			},
+		},
+	}
+}
+
+// buildEC2FiltersCSVFilterList returns the filters read from the CSV file of
+// the given "filters_csv" block, with the path of the file in the errors.
+func buildEC2FiltersCSVFilterList(tfMap map[string]interface{}) ([]*ec2.Filter, error) {
+	path := tfMap["path"].(string)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening filters_csv (%s): %w", path, err)
+	}
+	defer f.Close()
+
+	filters, err := ec2FiltersFromCSV(f, tfMap["header"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("error reading filters_csv (%s): %w", path, err)
+	}
+
+	return filters, nil
+}
+
+// ec2FiltersFromCSV returns the filters of the given CSV of name,value rows,
+// in the order of the first row of each name, with the deduplicated values of
+// all the rows of that name, sorted. The first row is skipped according to the
+// given header mode. It is an error for a row not to have exactly two
+// columns, or to have an empty name or value.
+func ec2FiltersFromCSV(r io.Reader, header string) ([]*ec2.Filter, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var names []string
+	values := make(map[string][]string)
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if row == 1 && (header == ec2FiltersCSVHeaderPresent || header == ec2FiltersCSVHeaderAuto && ec2FiltersCSVIsHeader(record)) {
+			continue
+		}
+
+		name, value := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if name == "" {
+			return nil, fmt.Errorf("row %d: empty filter name", row)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("row %d: empty value for filter %s", row, name)
+		}
+
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		values[name] = appendUniqueString(values[name], value)
+	}
+
+	filters := make([]*ec2.Filter, 0, len(names))
+	for _, name := range names {
+		// As for the "filter" blocks, the values are sorted to keep the
+		// requests deterministic.
+		sort.Strings(values[name])
+
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String(name),
+			Values: aws.StringSlice(values[name]),
+		})
+	}
+
+	return filters, nil
+}
+
+// ec2FiltersCSVIsHeader returns whether the given row of a filters CSV is a
+// "name,value" header.
+func ec2FiltersCSVIsHeader(record []string) bool {
+	return strings.EqualFold(strings.TrimSpace(record[0]), "name") && strings.EqualFold(strings.TrimSpace(record[1]), "value")
+}
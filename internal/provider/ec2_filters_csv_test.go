@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2FiltersFromCSV(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		CSV           string
+		Header        string
+		Expected      []*ec2.Filter
+		ExpectedError string
+	}{
+		{
+			Name:     "empty",
+			Header:   ec2FiltersCSVHeaderAuto,
+			Expected: []*ec2.Filter{},
+		},
+		{
+			Name:   "grouped by name",
+			CSV:    "tag:Team,platform\nvpc-id,vpc-01234567\ntag:Team,data\ntag:Team,platform\n",
+			Header: ec2FiltersCSVHeaderAbsent,
+			Expected: []*ec2.Filter{
+				{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{"data", "platform"})},
+				{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-01234567"})},
+			},
+		},
+		{
+			Name:   "quoted and spaced",
+			CSV:    "tag:Name, \"my, vpc\"\n",
+			Header: ec2FiltersCSVHeaderAbsent,
+			Expected: []*ec2.Filter{
+				{Name: aws.String("tag:Name"), Values: aws.StringSlice([]string{"my, vpc"})},
+			},
+		},
+		{
+			Name:   "auto header",
+			CSV:    "Name,Value\ntag:Team,platform\n",
+			Header: ec2FiltersCSVHeaderAuto,
+			Expected: []*ec2.Filter{
+				{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{"platform"})},
+			},
+		},
+		{
+			Name:   "auto without header",
+			CSV:    "tag:Team,platform\n",
+			Header: ec2FiltersCSVHeaderAuto,
+			Expected: []*ec2.Filter{
+				{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{"platform"})},
+			},
+		},
+		{
+			Name:   "present header",
+			CSV:    "filter,constraint\ntag:Team,platform\n",
+			Header: ec2FiltersCSVHeaderPresent,
+			Expected: []*ec2.Filter{
+				{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{"platform"})},
+			},
+		},
+		{
+			Name:   "absent header",
+			CSV:    "name,value\n",
+			Header: ec2FiltersCSVHeaderAbsent,
+			Expected: []*ec2.Filter{
+				{Name: aws.String("name"), Values: aws.StringSlice([]string{"value"})},
+			},
+		},
+		{
+			Name:          "wrong number of columns",
+			CSV:           "tag:Team,platform\nvpc-id\n",
+			Header:        ec2FiltersCSVHeaderAbsent,
+			ExpectedError: "wrong number of fields",
+		},
+		{
+			Name:          "empty name",
+			CSV:           "tag:Team,platform\n,data\n",
+			Header:        ec2FiltersCSVHeaderAbsent,
+			ExpectedError: "row 2: empty filter name",
+		},
+		{
+			Name:          "empty value",
+			CSV:           "tag:Team, \n",
+			Header:        ec2FiltersCSVHeaderAbsent,
+			ExpectedError: "row 1: empty value for filter tag:Team",
+		},
+		{
+			Name:          "unterminated quote",
+			CSV:           "tag:Team,\"platform\n",
+			Header:        ec2FiltersCSVHeaderAbsent,
+			ExpectedError: "extraneous or missing \" in quoted-field",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, err := ec2FiltersFromCSV(strings.NewReader(testCase.CSV), testCase.Header)
+
+			if testCase.ExpectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.ExpectedError) {
+					t.Fatalf("got error %v, expected %q", err, testCase.ExpectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2SelectionFiltersCSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filters_csv")
+	if err != nil {
+		t.Fatalf("error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "filters.csv")
+	if err := ioutil.WriteFile(path, []byte("name,value\ntag:Team,platform-*\n"), 0600); err != nil {
+		t.Fatalf("error writing CSV: %s", err)
+	}
+
+	s := map[string]*schema.Schema{
+		"name":        ec2NameSchema(),
+		"filters_csv": ec2FiltersCSVSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"name": "my-vpc",
+		"filters_csv": []interface{}{
+			map[string]interface{}{"path": path},
+		},
+	})
+
+	_, filters, _, err := buildEC2Selection(d, &AWSClient{escapeFilterWildcards: true}, ec2.ResourceTypeVpc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []*ec2.Filter{
+		{Name: aws.String("tag:Name"), Values: aws.StringSlice([]string{"my-vpc"})},
+		{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{`platform-\*`})},
+	}
+
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("got %v, expected %v", filters, expected)
+	}
+
+	d = schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"filters_csv": []interface{}{
+			map[string]interface{}{"path": filepath.Join(dir, "missing.csv")},
+		},
+	})
+
+	if _, _, _, err := buildEC2Selection(d, &AWSClient{}, ec2.ResourceTypeVpc); err == nil || !strings.Contains(err.Error(), "missing.csv") {
+		t.Errorf("got error %v, expected an error naming the file", err)
+	}
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ec2FiltersJSONSchema returns a *schema.Schema for the "filters_json"
+// attribute, giving additional filters as a JSON document, as read by
+// ec2FiltersFromJSON, for configurations generating them rather than
+// writing "filter" blocks.
+//
+// In Terraform configuration this looks like this:
+//
+// filters_json = jsonencode([
+//   { name = "vpc-id", values = [var.vpc_id] },
+// ])
+func ec2FiltersJSONSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Only match objects matching the filters of the given JSON array of `{\"name\": ..., \"values\": [...]}` objects, e.g. built with `jsonencode`. The values are matched like those of a `filter` block, and the filters are combined with the other selection attributes.",
+		ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+			if _, err := ec2FiltersFromJSON(v.(string)); err != nil {
+				errors = append(errors, fmt.Errorf("%s: %w", k, err))
+			}
+			return
+		},
+	}
+}
+
+// ec2FilterJSON is an element of the JSON array read by ec2FiltersFromJSON.
+type ec2FilterJSON struct {
+	Name   *string  `json:"name"`
+	Values []string `json:"values"`
+}
+
+// ec2FiltersFromJSON returns the filters of the given JSON array of objects
+// with the "name" of a filter and its "values", in the given order, values
+// included, so that the requests are the same as long as the document is. It
+// is an error for the document not to be such an array, or for an object to
+// have no name or no values.
+func ec2FiltersFromJSON(raw string) ([]*ec2.Filter, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	decoder.DisallowUnknownFields()
+
+	var elements []ec2FilterJSON
+	if err := decoder.Decode(&elements); err != nil {
+		return nil, fmt.Errorf("invalid filters JSON, expected an array of {\"name\": ..., \"values\": [...]} objects: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("invalid filters JSON: unexpected data after the array")
+	}
+
+	filters := make([]*ec2.Filter, 0, len(elements))
+	for i, element := range elements {
+		name := aws.StringValue(element.Name)
+		if name == "" {
+			return nil, fmt.Errorf("filter %d: name is required", i)
+		}
+		if len(element.Values) == 0 {
+			return nil, fmt.Errorf("filter %d (%s): values must not be empty", i, name)
+		}
+
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String(name),
+			Values: aws.StringSlice(element.Values),
+		})
+	}
+
+	return filters, nil
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2FiltersFromJSON(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		JSON          string
+		Expected      []*ec2.Filter
+		ExpectedError string
+	}{
+		{
+			Name:     "empty",
+			JSON:     "[]",
+			Expected: []*ec2.Filter{},
+		},
+		{
+			Name: "order preserved",
+			JSON: `[{"name": "vpc-id", "values": ["vpc-02", "vpc-01"]}, {"name": "tag:Team", "values": ["platform"]}]`,
+			Expected: []*ec2.Filter{
+				{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-02", "vpc-01"})},
+				{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{"platform"})},
+			},
+		},
+		{
+			Name:          "malformed",
+			JSON:          `[{"name": "vpc-id", "values": ["vpc-01"]`,
+			ExpectedError: "invalid filters JSON",
+		},
+		{
+			Name:          "not an array",
+			JSON:          `{"name": "vpc-id", "values": ["vpc-01"]}`,
+			ExpectedError: "invalid filters JSON",
+		},
+		{
+			Name:          "unknown field",
+			JSON:          `[{"name": "vpc-id", "value": ["vpc-01"]}]`,
+			ExpectedError: `unknown field "value"`,
+		},
+		{
+			Name:          "trailing data",
+			JSON:          `[] []`,
+			ExpectedError: "unexpected data after the array",
+		},
+		{
+			Name:          "missing name",
+			JSON:          `[{"name": "vpc-id", "values": ["vpc-01"]}, {"values": ["platform"]}]`,
+			ExpectedError: "filter 1: name is required",
+		},
+		{
+			Name:          "empty values",
+			JSON:          `[{"name": "vpc-id", "values": []}]`,
+			ExpectedError: "filter 0 (vpc-id): values must not be empty",
+		},
+		{
+			Name:          "missing values",
+			JSON:          `[{"name": "vpc-id"}]`,
+			ExpectedError: "filter 0 (vpc-id): values must not be empty",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, err := ec2FiltersFromJSON(testCase.JSON)
+
+			if testCase.ExpectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.ExpectedError) {
+					t.Fatalf("got error %v, expected %q", err, testCase.ExpectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2SelectionFiltersJSON(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"filter":       ec2CustomFiltersSchema(),
+		"filters_json": ec2FiltersJSONSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{"name": "vpc-id", "values": []interface{}{"vpc-01234567"}},
+		},
+		"filters_json": `[{"name": "tag:Team", "values": ["platform-*"]}]`,
+	})
+
+	_, filters, _, err := buildEC2Selection(d, &AWSClient{escapeFilterWildcards: true}, ec2.ResourceTypeVpc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []*ec2.Filter{
+		{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-01234567"})},
+		{Name: aws.String("tag:Team"), Values: aws.StringSlice([]string{`platform-\*`})},
+	}
+
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("got %v, expected %v", filters, expected)
+	}
+
+	if _, errs := s["filters_json"].ValidateFunc(`[{"name": "vpc-id"}]`, "filters_json"); len(errs) != 1 {
+		t.Errorf("got errors %v, expected one", errs)
+	}
+}
@@ -0,0 +1,1784 @@
+package provider
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestBuildEC2TagFilterListFromResourceData(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Raw      map[string]interface{}
+		Expected []*ec2.Filter
+	}{
+		{
+			Name: "no tags",
+			Raw:  map[string]interface{}{},
+		},
+		{
+			Name: "empty tags",
+			Raw: map[string]interface{}{
+				"tags": map[string]interface{}{},
+			},
+		},
+		{
+			Name: "tags",
+			Raw: map[string]interface{}{
+				"tags": map[string]interface{}{
+					"Name":        "my-awesome-subnet",
+					"Environment": "prod",
+				},
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("tag:Environment"),
+					Values: aws.StringSlice([]string{"prod"}),
+				},
+				{
+					Name:   aws.String("tag:Name"),
+					Values: aws.StringSlice([]string{"my-awesome-subnet"}),
+				},
+			},
+		},
+		{
+			Name: "AWS tags ignored",
+			Raw: map[string]interface{}{
+				"tags": map[string]interface{}{
+					"Name":                          "my-awesome-subnet",
+					"aws:cloudformation:stack-name": "my-stack",
+				},
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("tag:Name"),
+					Values: aws.StringSlice([]string{"my-awesome-subnet"}),
+				},
+			},
+		},
+		{
+			Name: "Spot Fleet tag allowed",
+			Raw: map[string]interface{}{
+				"tags": map[string]interface{}{
+					"aws:ec2spot:fleet-request-id":  "sfr-0123abcd-0123-abcd-0123-0123456789ab",
+					"aws:cloudformation:stack-name": "my-stack",
+				},
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("tag:aws:ec2spot:fleet-request-id"),
+					Values: aws.StringSlice([]string{"sfr-0123abcd-0123-abcd-0123-0123456789ab"}),
+				},
+			},
+		},
+		{
+			Name: "Auto Scaling Group tag allowed",
+			Raw: map[string]interface{}{
+				"tags": map[string]interface{}{
+					"aws:autoscaling:groupName":     "web",
+					"aws:cloudformation:stack-name": "my-stack",
+				},
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("tag:aws:autoscaling:groupName"),
+					Values: aws.StringSlice([]string{"web"}),
+				},
+			},
+		},
+	}
+
+	s := map[string]*schema.Schema{
+		"tags": tagsSchema(),
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, testCase.Raw)
+
+			got := buildEC2TagFilterListFromResourceData(d, "tags")
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2TagExclusionPredicate(t *testing.T) {
+	prod := []*ec2.Tag{
+		{Key: aws.String("Environment"), Value: aws.String("prod")},
+		{Key: aws.String("Team"), Value: aws.String("platform")},
+		{Key: aws.String("Name"), Value: aws.String("my-awesome-subnet")},
+	}
+
+	testCases := []struct {
+		Name     string
+		Excluded []*ec2.Tag
+		Tags     []*ec2.Tag
+		Expected bool
+	}{
+		{
+			Name:     "no exclusions",
+			Tags:     prod,
+			Expected: false,
+		},
+		{
+			Name:     "single pair matched",
+			Excluded: []*ec2.Tag{{Key: aws.String("Environment"), Value: aws.String("prod")}},
+			Tags:     prod,
+			Expected: true,
+		},
+		{
+			Name:     "single pair with another value",
+			Excluded: []*ec2.Tag{{Key: aws.String("Environment"), Value: aws.String("dev")}},
+			Tags:     prod,
+			Expected: false,
+		},
+		{
+			Name: "all pairs matched",
+			Excluded: []*ec2.Tag{
+				{Key: aws.String("Environment"), Value: aws.String("prod")},
+				{Key: aws.String("Team"), Value: aws.String("platform")},
+			},
+			Tags:     prod,
+			Expected: true,
+		},
+		{
+			Name: "one pair matched but not another",
+			Excluded: []*ec2.Tag{
+				{Key: aws.String("Environment"), Value: aws.String("prod")},
+				{Key: aws.String("Team"), Value: aws.String("data")},
+			},
+			Tags:     prod,
+			Expected: false,
+		},
+		{
+			Name: "one key matched but another missing",
+			Excluded: []*ec2.Tag{
+				{Key: aws.String("Environment"), Value: aws.String("prod")},
+				{Key: aws.String("CostCenter"), Value: aws.String("1234")},
+			},
+			Tags:     prod,
+			Expected: false,
+		},
+		{
+			Name: "nil and empty values skipped",
+			Excluded: []*ec2.Tag{
+				{Key: aws.String("Environment"), Value: aws.String("prod")},
+				{Key: aws.String("CostCenter"), Value: aws.String("")},
+				{Key: aws.String("Owner")},
+				nil,
+			},
+			Tags:     prod,
+			Expected: true,
+		},
+		{
+			Name: "only empty values",
+			Excluded: []*ec2.Tag{
+				{Key: aws.String("CostCenter"), Value: aws.String("")},
+			},
+			Tags:     nil,
+			Expected: false,
+		},
+		{
+			Name:     "untagged object",
+			Excluded: []*ec2.Tag{{Key: aws.String("Environment"), Value: aws.String("prod")}},
+			Tags:     nil,
+			Expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := buildEC2TagExclusionPredicate(testCase.Excluded)(testCase.Tags)
+
+			if got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2TagExclusionPredicateFromResourceData(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"exclude_tags": ec2ExcludeTagsSchema(),
+	}
+
+	tags := []*ec2.Tag{{Key: aws.String("Environment"), Value: aws.String("prod")}}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{})
+	if buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")(tags) {
+		t.Errorf("unset exclude_tags excluded an object")
+	}
+
+	d = schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"exclude_tags": map[string]interface{}{"Environment": "prod"},
+	})
+	if !buildEC2TagExclusionPredicateFromResourceData(d, "exclude_tags")(tags) {
+		t.Errorf("exclude_tags did not exclude a matching object")
+	}
+}
+
+func TestMergeEC2FilterLists(t *testing.T) {
+	tagFilters := buildEC2TagFilterList([]*ec2.Tag{
+		{Key: aws.String("Environment"), Value: aws.String("prod")},
+		{Key: aws.String("Name"), Value: aws.String("web")},
+	})
+	attributeFilters := buildEC2AttributeFilterList(map[string]string{
+		"vpc-id": "vpc-01234567",
+	})
+	customFilters := []*ec2.Filter{
+		{
+			Name:   aws.String("tag:Environment"),
+			Values: aws.StringSlice([]string{"staging", "prod"}),
+		},
+		{
+			Name:   aws.String("vpc-id"),
+			Values: aws.StringSlice([]string{"vpc-89abcdef"}),
+		},
+		{
+			Name:   aws.String("availability-zone"),
+			Values: aws.StringSlice([]string{"us-east-1a"}),
+		},
+	}
+
+	expected := []*ec2.Filter{
+		{
+			Name:   aws.String("availability-zone"),
+			Values: aws.StringSlice([]string{"us-east-1a"}),
+		},
+		{
+			Name:   aws.String("tag:Environment"),
+			Values: aws.StringSlice([]string{"prod", "staging"}),
+		},
+		{
+			Name:   aws.String("tag:Name"),
+			Values: aws.StringSlice([]string{"web"}),
+		},
+		{
+			Name:   aws.String("vpc-id"),
+			Values: aws.StringSlice([]string{"vpc-01234567", "vpc-89abcdef"}),
+		},
+	}
+
+	if got := mergeEC2FilterLists(tagFilters, attributeFilters, customFilters); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+
+	// The order of the lists does not matter.
+	if got := mergeEC2FilterLists(customFilters, tagFilters, attributeFilters); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %s in another order, expected %s", got, expected)
+	}
+
+	if got := aws.StringValueSlice(customFilters[0].Values); !reflect.DeepEqual(got, []string{"staging", "prod"}) {
+		t.Errorf("expected the given filters to be left untouched, got values %v", got)
+	}
+
+	if got := mergeEC2FilterLists(nil, []*ec2.Filter{}); got != nil {
+		t.Errorf("got %s, expected nil", got)
+	}
+}
+
+func TestBuildEC2OwnerIDFilterList(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		OwnerIDs    []string
+		AccountID   string
+		Expected    []*ec2.Filter
+		ExpectError bool
+	}{
+		{
+			Name: "no owners",
+		},
+		{
+			Name:      "account IDs",
+			OwnerIDs:  []string{"123456789012", "210987654321"},
+			AccountID: "111111111111",
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("owner-id"),
+					Values: aws.StringSlice([]string{"123456789012", "210987654321"}),
+				},
+			},
+		},
+		{
+			Name:      "self resolved",
+			OwnerIDs:  []string{"self", "123456789012"},
+			AccountID: "111111111111",
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("owner-id"),
+					Values: aws.StringSlice([]string{"111111111111", "123456789012"}),
+				},
+			},
+		},
+		{
+			Name:      "self deduplicated",
+			OwnerIDs:  []string{"self", "111111111111"},
+			AccountID: "111111111111",
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("owner-id"),
+					Values: aws.StringSlice([]string{"111111111111"}),
+				},
+			},
+		},
+		{
+			Name:        "self without account ID",
+			OwnerIDs:    []string{"self"},
+			ExpectError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, err := buildEC2OwnerIDFilterList(testCase.OwnerIDs, testCase.AccountID)
+
+			if testCase.ExpectError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2OwnerSelection(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		ResourceType    string
+		OwnerIDs        []string
+		AccountID       string
+		ExpectedOwners  []*string
+		ExpectedFilters []*ec2.Filter
+		ExpectError     bool
+	}{
+		{
+			Name:         "no owners",
+			ResourceType: ec2.ResourceTypeImage,
+			AccountID:    "123456789012",
+		},
+		{
+			Name:           "self passed through to the Owners parameter",
+			ResourceType:   ec2.ResourceTypeImage,
+			OwnerIDs:       []string{"self", "210987654321", "self"},
+			ExpectedOwners: aws.StringSlice([]string{"self", "210987654321"}),
+		},
+		{
+			Name:           "self passed through to the OwnerIds parameter",
+			ResourceType:   ec2.ResourceTypeSnapshot,
+			OwnerIDs:       []string{"self"},
+			AccountID:      "123456789012",
+			ExpectedOwners: aws.StringSlice([]string{"self"}),
+		},
+		{
+			Name:         "self resolved in the owner-id filter",
+			ResourceType: ec2.ResourceTypeSecurityGroup,
+			OwnerIDs:     []string{"self", "210987654321"},
+			AccountID:    "123456789012",
+			ExpectedFilters: []*ec2.Filter{
+				{
+					Name:   aws.String("owner-id"),
+					Values: aws.StringSlice([]string{"123456789012", "210987654321"}),
+				},
+			},
+		},
+		{
+			Name:         "self in the owner-id filter without account ID",
+			ResourceType: ec2.ResourceTypeSecurityGroup,
+			OwnerIDs:     []string{"self"},
+			ExpectError:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			owners, filters, err := buildEC2OwnerSelection(testCase.ResourceType, testCase.OwnerIDs, testCase.AccountID)
+
+			if testCase.ExpectError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(owners, testCase.ExpectedOwners) {
+				t.Errorf("got owners %s, expected %s", aws.StringValueSlice(owners), aws.StringValueSlice(testCase.ExpectedOwners))
+			}
+
+			if !reflect.DeepEqual(filters, testCase.ExpectedFilters) {
+				t.Errorf("got filters %s, expected %s", filters, testCase.ExpectedFilters)
+			}
+		})
+	}
+}
+
+func TestBuildEC2NetworkInterfaceAttributeFilterList(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Raw      map[string]interface{}
+		Expected []*ec2.Filter
+	}{
+		{
+			Name: "unset",
+			Raw:  map[string]interface{}{},
+		},
+		{
+			Name: "requester managed",
+			Raw: map[string]interface{}{
+				"requester_managed": true,
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("requester-managed"),
+					Values: aws.StringSlice([]string{"true"}),
+				},
+			},
+		},
+		{
+			Name: "not requester managed",
+			Raw: map[string]interface{}{
+				"requester_managed": false,
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("requester-managed"),
+					Values: aws.StringSlice([]string{"false"}),
+				},
+			},
+		},
+		{
+			Name: "interface type",
+			Raw: map[string]interface{}{
+				"requester_managed": false,
+				"interface_type":    "interface",
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("interface-type"),
+					Values: aws.StringSlice([]string{"interface"}),
+				},
+				{
+					Name:   aws.String("requester-managed"),
+					Values: aws.StringSlice([]string{"false"}),
+				},
+			},
+		},
+	}
+
+	s := ec2NetworkInterfaceFilterSchemas()
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, testCase.Raw)
+
+			got := buildEC2NetworkInterfaceAttributeFilterList(d)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2SpotInstanceAttributeFilterList(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Raw      map[string]interface{}
+		Expected []*ec2.Filter
+	}{
+		{
+			Name: "unset",
+			Raw:  map[string]interface{}{},
+		},
+		{
+			Name: "Spot Instance request",
+			Raw: map[string]interface{}{
+				"spot_instance_request_id": "sir-0123abcd",
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("spot-instance-request-id"),
+					Values: aws.StringSlice([]string{"sir-0123abcd"}),
+				},
+			},
+		},
+		{
+			Name: "Spot Fleet request",
+			Raw: map[string]interface{}{
+				"spot_instance_request_id": "sir-0123abcd",
+				"spot_fleet_request_id":    "sfr-0123abcd-0123-abcd-0123-0123456789ab",
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("spot-instance-request-id"),
+					Values: aws.StringSlice([]string{"sir-0123abcd"}),
+				},
+				{
+					Name:   aws.String("tag:aws:ec2spot:fleet-request-id"),
+					Values: aws.StringSlice([]string{"sfr-0123abcd-0123-abcd-0123-0123456789ab"}),
+				},
+			},
+		},
+	}
+
+	s := ec2SpotInstanceFilterSchemas()
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, testCase.Raw)
+
+			got := buildEC2SpotInstanceAttributeFilterList(d)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2InstanceHibernationAttributeFilterList(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Raw      map[string]interface{}
+		Expected []*ec2.Filter
+	}{
+		{
+			Name: "unset",
+			Raw:  map[string]interface{}{},
+		},
+		{
+			Name: "hibernation enabled",
+			Raw: map[string]interface{}{
+				"hibernation_enabled": true,
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("hibernation-options.configured"),
+					Values: aws.StringSlice([]string{"true"}),
+				},
+			},
+		},
+		{
+			Name: "hibernation disabled",
+			Raw: map[string]interface{}{
+				"hibernation_enabled": false,
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("hibernation-options.configured"),
+					Values: aws.StringSlice([]string{"false"}),
+				},
+			},
+		},
+	}
+
+	s := ec2InstanceHibernationFilterSchemas()
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, testCase.Raw)
+
+			got := buildEC2InstanceHibernationAttributeFilterList(d)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestEc2InstanceStateFilter(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		States   []string
+		Expected *ec2.Filter
+		Error    string
+	}{
+		{
+			Name:   "multiple states",
+			States: []string{"running", "stopped", "running", "pending"},
+			Expected: &ec2.Filter{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"running", "stopped", "pending"}),
+			},
+		},
+		{
+			Name:   "single state",
+			States: []string{"terminated"},
+			Expected: &ec2.Filter{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"terminated"}),
+			},
+		},
+		{
+			Name:   "invalid state",
+			States: []string{"running", "Stopped"},
+			Error:  `invalid instance state "Stopped"`,
+		},
+		{
+			Name: "no states",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, err := ec2InstanceStateFilter(testCase.States...)
+
+			if testCase.Error != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.Error) {
+					t.Fatalf("got error %v, expected %q", err, testCase.Error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2InstanceStateAttributeFilterList(t *testing.T) {
+	s := ec2InstanceStateFilterSchemas()
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"states": []interface{}{"stopped", "running"},
+	})
+
+	got, err := buildEC2InstanceStateAttributeFilterList(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []*ec2.Filter{
+		{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{"running", "stopped"}),
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+
+	if got, err := buildEC2InstanceStateAttributeFilterList(schema.TestResourceDataRaw(t, s, map[string]interface{}{})); err != nil || got != nil {
+		t.Errorf("got %s, %v, expected no filter", got, err)
+	}
+}
+
+func TestEC2SpotRequestIDValidation(t *testing.T) {
+	testCases := []struct {
+		Key         string
+		Value       string
+		ExpectError bool
+	}{
+		{Key: "spot_instance_request_id", Value: "sir-0123abcd"},
+		{Key: "spot_instance_request_id", Value: "sfr-0123abcd", ExpectError: true},
+		{Key: "spot_fleet_request_id", Value: "sfr-0123abcd-0123-abcd-0123-0123456789ab"},
+		{Key: "spot_fleet_request_id", Value: "sir-0123abcd", ExpectError: true},
+	}
+
+	s := ec2SpotInstanceFilterSchemas()
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Value, func(t *testing.T) {
+			_, errs := s[testCase.Key].ValidateFunc(testCase.Value, testCase.Key)
+
+			if testCase.ExpectError != (len(errs) > 0) {
+				t.Errorf("got errors %v, expected error: %t", errs, testCase.ExpectError)
+			}
+		})
+	}
+}
+
+func TestEC2NetworkInterfaceTypeValidation(t *testing.T) {
+	testCases := []struct {
+		Value       string
+		ExpectError bool
+	}{
+		{Value: "interface"},
+		{Value: "nat_gateway"},
+		{Value: "lambda"},
+		{Value: "vpc_endpoint"},
+		{Value: "efa"},
+		{Value: "natGateway", ExpectError: true},
+		{Value: "Interface", ExpectError: true},
+		{Value: "", ExpectError: true},
+	}
+
+	validate := ec2NetworkInterfaceFilterSchemas()["interface_type"].ValidateFunc
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Value, func(t *testing.T) {
+			_, errs := validate(testCase.Value, "interface_type")
+
+			if testCase.ExpectError != (len(errs) > 0) {
+				t.Errorf("got errors %v, expected error: %t", errs, testCase.ExpectError)
+			}
+		})
+	}
+}
+
+func TestBuildEC2CustomFilterList(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Raw      []interface{}
+		Expected []*ec2.Filter
+	}{
+		{
+			Name:     "no filters",
+			Expected: []*ec2.Filter{},
+		},
+		{
+			Name: "sorted values",
+			Raw: []interface{}{
+				map[string]interface{}{
+					"name":   "availability-zone",
+					"values": []interface{}{"us-east-1c", "us-east-1a", "us-east-1b"},
+				},
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("availability-zone"),
+					Values: aws.StringSlice([]string{"us-east-1a", "us-east-1b", "us-east-1c"}),
+				},
+			},
+		},
+		{
+			Name: "duplicate values",
+			Raw: []interface{}{
+				map[string]interface{}{
+					"name":   "tag:Environment",
+					"values": []interface{}{"prod", "dev", "prod", "staging", "dev"},
+				},
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("tag:Environment"),
+					Values: aws.StringSlice([]string{"dev", "prod", "staging"}),
+				},
+			},
+		},
+		{
+			Name: "duplicate names",
+			Raw: []interface{}{
+				map[string]interface{}{
+					"name":   "instance-type",
+					"values": []interface{}{"t3.large"},
+				},
+				map[string]interface{}{
+					"name":   "instance-type",
+					"values": []interface{}{"m5.large", "t3.large"},
+				},
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("instance-type"),
+					Values: aws.StringSlice([]string{"m5.large", "t3.large"}),
+				},
+			},
+		},
+		{
+			Name: "tag prefix case",
+			Raw: []interface{}{
+				map[string]interface{}{
+					"name":   "Tag:Environment",
+					"values": []interface{}{"prod"},
+				},
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("tag:Environment"),
+					Values: aws.StringSlice([]string{"prod"}),
+				},
+			},
+		},
+		{
+			Name: "tag-key prefix case",
+			Raw: []interface{}{
+				map[string]interface{}{
+					"name":   "TAG-Key",
+					"values": []interface{}{"Team"},
+				},
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("tag-key"),
+					Values: aws.StringSlice([]string{"Team"}),
+				},
+			},
+		},
+		{
+			Name: "non-tag name case",
+			Raw: []interface{}{
+				map[string]interface{}{
+					"name":   "Availability-Zone",
+					"values": []interface{}{"us-east-1a"},
+				},
+			},
+			Expected: []*ec2.Filter{
+				{
+					Name:   aws.String("Availability-Zone"),
+					Values: aws.StringSlice([]string{"us-east-1a"}),
+				},
+			},
+		},
+	}
+
+	s := map[string]*schema.Schema{
+		"filter": ec2CustomFiltersSchema(),
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+				"filter": testCase.Raw,
+			})
+
+			got, _ := buildEC2CustomFilterList(d.Get("filter").(*schema.Set))
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got filters %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2CustomFilterListEscapingWildcardsDuplicateNames(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"filter": ec2CustomFiltersSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{
+				"name":     "tag:Name",
+				"values":   []interface{}{"web-*"},
+				"wildcard": true,
+			},
+			map[string]interface{}{
+				"name":   "tag:Name",
+				"values": []interface{}{"db-1"},
+			},
+			map[string]interface{}{
+				"name":   "tag:Team",
+				"values": []interface{}{"platform?"},
+			},
+		},
+	})
+
+	filters, _ := buildEC2CustomFilterListEscapingWildcards(d.Get("filter").(*schema.Set))
+	sort.Slice(filters, func(i, j int) bool { return aws.StringValue(filters[i].Name) < aws.StringValue(filters[j].Name) })
+
+	// Only the values of the block opting into wildcards are left unescaped once merged.
+	expected := []*ec2.Filter{
+		{
+			Name:   aws.String("tag:Name"),
+			Values: aws.StringSlice([]string{"db-1", "web-*"}),
+		},
+		{
+			Name:   aws.String("tag:Team"),
+			Values: aws.StringSlice([]string{`platform\?`}),
+		},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("got filters %s, expected %s", filters, expected)
+	}
+
+	blocks, _ := buildEC2CustomFilterBlockList(d.Get("filter").(*schema.Set), true)
+	if len(blocks) != 3 {
+		t.Errorf("got %d filters, expected one per block", len(blocks))
+	}
+}
+
+func TestNormalizeEC2FilterName(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Expected string
+	}{
+		{Name: "Tag:Environment", Expected: "tag:Environment"},
+		{Name: "TAG:aws:autoscaling:groupName", Expected: "tag:aws:autoscaling:groupName"},
+		{Name: "tag:Name", Expected: "tag:Name"},
+		{Name: "Tag-Key", Expected: "tag-key"},
+		{Name: "Tag-Value", Expected: "Tag-Value"},
+		{Name: "Availability-Zone", Expected: "Availability-Zone"},
+		{Name: "Tag", Expected: "Tag"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := normalizeEC2FilterName(testCase.Name); got != testCase.Expected {
+				t.Errorf("got %q, expected %q", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestValidateEC2FilterName(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		ExpectWarning bool
+	}{
+		{Name: "availability-zone"},
+		{Name: "instance-state-name"},
+		{Name: "tag:Foo"},
+		{Name: "tag:aws:autoscaling:groupName"},
+		{Name: "tag-key"},
+		{Name: "tag-value"},
+		{Name: "availabilty-zone", ExpectWarning: true},
+		{Name: "Tag:Foo"},
+		{Name: "TAG-KEY"},
+		{Name: "vpc_id", ExpectWarning: true},
+		{Name: "tag-keys", ExpectWarning: true},
+		{Name: "tag-name", ExpectWarning: true},
+		{Name: "tag-", ExpectWarning: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			diags := validateEC2FilterName(testCase.Name, cty.GetAttrPath("filter").IndexInt(0).GetAttr("name"))
+
+			if !testCase.ExpectWarning {
+				if len(diags) != 0 {
+					t.Errorf("got diagnostics %v, expected none", diags)
+				}
+				return
+			}
+
+			if len(diags) != 1 || diags[0].Severity != diag.Warning {
+				t.Errorf("got diagnostics %v, expected a single warning", diags)
+			}
+		})
+	}
+}
+
+func TestKnownEC2FilterNames(t *testing.T) {
+	// The ID filters of the supported resource types are built by buildEC2IDSelection.
+	for _, resourceType := range tfec2.ResourceTypes() {
+		metadata, _ := tfec2.ResourceTypeMetadataFor(resourceType)
+
+		if diags := validateEC2FilterName(metadata.IDFilterName, nil); len(diags) != 0 {
+			t.Errorf("ID filter %s of %s is not known", metadata.IDFilterName, resourceType)
+		}
+	}
+
+	seen := make(map[string]bool, len(KnownEC2FilterNames))
+	for i, name := range KnownEC2FilterNames {
+		if seen[name] {
+			t.Errorf("duplicate name %s", name)
+		}
+		seen[name] = true
+
+		if i > 0 && KnownEC2FilterNames[i-1] > name {
+			t.Errorf("%s is not sorted after %s", name, KnownEC2FilterNames[i-1])
+		}
+	}
+}
+
+func TestEc2AttributeFiltersFromMultimap(t *testing.T) {
+	m := map[string][]string{
+		"vpc-id":            {"vpc-89abcdef", "vpc-01234567", "vpc-89abcdef"},
+		"availability-zone": {"us-east-1b"},
+		"instance-type":     {"t3.large", "t3.large", "m5.large", "t3.large"},
+		"image-id":          {},
+	}
+
+	expected := []*ec2.Filter{
+		{
+			Name:   aws.String("availability-zone"),
+			Values: aws.StringSlice([]string{"us-east-1b"}),
+		},
+		{
+			Name:   aws.String("image-id"),
+			Values: aws.StringSlice([]string{}),
+		},
+		{
+			Name:   aws.String("instance-type"),
+			Values: aws.StringSlice([]string{"t3.large", "m5.large"}),
+		},
+		{
+			Name:   aws.String("vpc-id"),
+			Values: aws.StringSlice([]string{"vpc-89abcdef", "vpc-01234567"}),
+		},
+	}
+
+	// The iteration order of a map varies from one range to the next.
+	for i := 0; i < 10; i++ {
+		if got := ec2AttributeFiltersFromMultimap(m); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("got %v, expected %v", got, expected)
+		}
+	}
+
+	if got := ec2AttributeFiltersFromMultimap(nil); got != nil {
+		t.Errorf("got %v, expected nil", got)
+	}
+}
+
+func TestEc2TagFiltersFromMap(t *testing.T) {
+	tags := map[string]interface{}{
+		"Name":                           "my-awesome-subnet",
+		"aws:cloudformation:stack-name":  "my-stack",
+		"kubernetes.io/cluster/my-eks":   "shared",
+		"kubernetes.io/role/elb":         "1",
+		"k8s.io/cluster-autoscaler/node": "true",
+	}
+
+	testCases := []struct {
+		Name         string
+		IgnoreConfig *keyvaluetags.IgnoreConfig
+		Expected     []*ec2.Filter
+	}{
+		{
+			Name: "no ignore config",
+			Expected: []*ec2.Filter{
+				{Name: aws.String("tag:Name"), Values: aws.StringSlice([]string{"my-awesome-subnet"})},
+				{Name: aws.String("tag:k8s.io/cluster-autoscaler/node"), Values: aws.StringSlice([]string{"true"})},
+				{Name: aws.String("tag:kubernetes.io/cluster/my-eks"), Values: aws.StringSlice([]string{"shared"})},
+				{Name: aws.String("tag:kubernetes.io/role/elb"), Values: aws.StringSlice([]string{"1"})},
+			},
+		},
+		{
+			Name: "ignored key prefix",
+			IgnoreConfig: &keyvaluetags.IgnoreConfig{
+				KeyPrefixes: keyvaluetags.New([]string{"kubernetes.io/"}),
+			},
+			Expected: []*ec2.Filter{
+				{Name: aws.String("tag:Name"), Values: aws.StringSlice([]string{"my-awesome-subnet"})},
+				{Name: aws.String("tag:k8s.io/cluster-autoscaler/node"), Values: aws.StringSlice([]string{"true"})},
+			},
+		},
+		{
+			Name: "ignored keys and key prefixes",
+			IgnoreConfig: &keyvaluetags.IgnoreConfig{
+				Keys:        keyvaluetags.New([]string{"Name"}),
+				KeyPrefixes: keyvaluetags.New([]string{"kubernetes.io/", "k8s.io/"}),
+			},
+			Expected: []*ec2.Filter{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := ec2TagFiltersFromMap(tags, testCase.IgnoreConfig)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+
+	if got := ec2TagFiltersFromMap(nil, nil); got != nil {
+		t.Errorf("got %v, expected nil", got)
+	}
+}
+
+func TestBuildEC2CloudFormationStackNameFilterList(t *testing.T) {
+	expected := []*ec2.Filter{
+		{
+			Name:   aws.String("tag:aws:cloudformation:stack-name"),
+			Values: aws.StringSlice([]string{"my-stack"}),
+		},
+	}
+	if got := buildEC2CloudFormationStackNameFilterList("my-stack"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+
+	if got := buildEC2CloudFormationStackNameFilterList(""); got != nil {
+		t.Errorf("got %v, expected nil", got)
+	}
+
+	// The tag is dropped unless explicitly allowed.
+	tags := map[string]interface{}{ec2CloudFormationStackNameTagKey: "my-stack"}
+	if got := ec2TagFiltersFromMap(tags, nil); len(got) != 0 {
+		t.Errorf("got %v, expected no filters", got)
+	}
+	if got := tagsFromMap(tags); len(got) != 0 {
+		t.Errorf("got %v, expected no tags", got)
+	}
+	if got := ec2TagFiltersFromMap(tags, nil, ec2CloudFormationStackNameTagKey); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestBuildEC2AttributeFilterListWithOpts(t *testing.T) {
+	attrs := map[string]string{
+		"vpc-id":   "vpc-01234567",
+		"tag:Name": "",
+		"state":    "available",
+	}
+
+	testCases := []struct {
+		Name      string
+		KeepEmpty bool
+		Expected  []*ec2.Filter
+	}{
+		{
+			Name: "empty values dropped",
+			Expected: []*ec2.Filter{
+				{Name: aws.String("state"), Values: aws.StringSlice([]string{"available"})},
+				{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-01234567"})},
+			},
+		},
+		{
+			Name:      "empty values kept",
+			KeepEmpty: true,
+			Expected: []*ec2.Filter{
+				{Name: aws.String("state"), Values: aws.StringSlice([]string{"available"})},
+				{Name: aws.String("tag:Name"), Values: aws.StringSlice([]string{""})},
+				{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-01234567"})},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := buildEC2AttributeFilterListWithOpts(attrs, testCase.KeepEmpty); !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+
+	// The existing contract is unchanged.
+	if got, expected := buildEC2AttributeFilterList(attrs), testCases[0].Expected; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+
+	if got := buildEC2AttributeFilterListWithOpts(map[string]string{"tag:Name": ""}, false); got != nil {
+		t.Errorf("got %v, expected nil", got)
+	}
+}
+
+func TestBuildEC2AttributeFilterListMulti(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Attrs    map[string][]string
+		Expected []*ec2.Filter
+	}{
+		{
+			Name: "mixed empty and non-empty values",
+			Attrs: map[string][]string{
+				"vpc-id":              {"vpc-01234567"},
+				"instance-state-name": {"", "running", "", "stopped", "running"},
+			},
+			Expected: []*ec2.Filter{
+				{Name: aws.String("instance-state-name"), Values: aws.StringSlice([]string{"running", "stopped"})},
+				{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-01234567"})},
+			},
+		},
+		{
+			Name: "entirely empty attributes skipped",
+			Attrs: map[string][]string{
+				"vpc-id":   {"vpc-01234567"},
+				"tag:Name": {"", ""},
+				"image-id": {},
+				"state":    nil,
+			},
+			Expected: []*ec2.Filter{
+				{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-01234567"})},
+			},
+		},
+		{
+			Name:  "no attributes left",
+			Attrs: map[string][]string{"tag:Name": {""}},
+		},
+		{
+			Name: "nil",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := buildEC2AttributeFilterListMulti(testCase.Attrs); !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+
+	// The scalar values give the same filters as buildEC2AttributeFilterList.
+	attrs := map[string]string{"vpc-id": "vpc-01234567", "tag:Name": "", "state": "available"}
+	multi := make(map[string][]string, len(attrs))
+	for k, v := range attrs {
+		multi[k] = []string{v}
+	}
+	if got, expected := buildEC2AttributeFilterListMulti(multi), buildEC2AttributeFilterList(attrs); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestEc2FilterValueTransform(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Transform     string
+		Value         string
+		Expected      string
+		ExpectedError string
+	}{
+		{
+			Name:     "none",
+			Value:    " My-VPC ",
+			Expected: " My-VPC ",
+		},
+		{
+			Name:      "explicit none",
+			Transform: ec2FilterValueTransformNone,
+			Value:     " My-VPC ",
+			Expected:  " My-VPC ",
+		},
+		{
+			Name:      "EC2 ARN",
+			Transform: ec2FilterValueTransformARNResourceID,
+			Value:     "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0123456789abcdef0",
+			Expected:  "vpc-0123456789abcdef0",
+		},
+		{
+			Name:      "ARN with a path",
+			Transform: ec2FilterValueTransformARNResourceID,
+			Value:     "arn:aws:iam::123456789012:instance-profile/path/web",
+			Expected:  "web",
+		},
+		{
+			Name:      "ARN with a colon",
+			Transform: ec2FilterValueTransformARNResourceID,
+			Value:     "arn:aws:logs:us-east-1:123456789012:log-group:flow-logs",
+			Expected:  "flow-logs",
+		},
+		{
+			Name:          "not an ARN",
+			Transform:     ec2FilterValueTransformARNResourceID,
+			Value:         "vpc-0123456789abcdef0",
+			ExpectedError: `value "vpc-0123456789abcdef0" is not a valid ARN`,
+		},
+		{
+			Name:          "ARN without a resource ID",
+			Transform:     ec2FilterValueTransformARNResourceID,
+			Value:         "arn:aws:ec2:us-east-1:123456789012:vpc/",
+			ExpectedError: "without a resource ID",
+		},
+		{
+			Name:      "lowercase",
+			Transform: ec2FilterValueTransformLowercase,
+			Value:     "My-VPC",
+			Expected:  "my-vpc",
+		},
+		{
+			Name:      "uppercase",
+			Transform: ec2FilterValueTransformUppercase,
+			Value:     "My-VPC",
+			Expected:  "MY-VPC",
+		},
+		{
+			Name:      "trim",
+			Transform: ec2FilterValueTransformTrim,
+			Value:     "\t My VPC \n",
+			Expected:  "My VPC",
+		},
+		{
+			Name:          "unsupported",
+			Transform:     "reverse",
+			Value:         "My-VPC",
+			ExpectedError: `unsupported value transform "reverse"`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, err := ec2FilterValueTransform(testCase.Transform, testCase.Value)
+
+			if testCase.ExpectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.ExpectedError) {
+					t.Fatalf("got error %v, expected %q", err, testCase.ExpectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != testCase.Expected {
+				t.Errorf("got %q, expected %q", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2CustomFilterListValueTransform(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"filter": ec2CustomFiltersSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{
+				"name": "vpc-id",
+				"values": []interface{}{
+					"arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0123456789abcdef0",
+					"arn:aws:ec2:us-west-2:123456789012:vpc/vpc-0123456789abcdef0",
+					"vpc-01234567",
+				},
+				"value_transform": ec2FilterValueTransformARNResourceID,
+			},
+		},
+	})
+
+	filters, diags := buildEC2CustomFilterList(d.Get("filter").(*schema.Set))
+
+	// The ARNs of the same ID are deduplicated once converted.
+	expected := []*ec2.Filter{
+		{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-0123456789abcdef0"})},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("got %v, expected %v", filters, expected)
+	}
+
+	if len(diags) != 1 || !strings.Contains(diags[0].Summary, `filter vpc-id: value "vpc-01234567" is not a valid ARN`) {
+		t.Errorf("got diagnostics %v, expected the value which is not an ARN", diags)
+	}
+
+	transformSchema := s["filter"].Elem.(*schema.Resource).Schema["value_transform"]
+	if _, errs := transformSchema.ValidateFunc("reverse", "value_transform"); len(errs) != 1 {
+		t.Errorf("got errors %v, expected one for the unknown transform", errs)
+	}
+}
+
+func TestBuildEC2CustomFilterListValuesFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "values_file")
+	if err != nil {
+		t.Fatalf("error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"valid.txt":    "ami-00000002\nami-00000001\n",
+		"comments.txt": "# Allowed AMIs\n\n  ami-00000003  \r\n\t\n# ami-00000009\nami-00000001\n   # indented comment\n",
+		"empty.txt":    "# nothing allowed yet\n\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("error writing %s: %s", name, err)
+		}
+	}
+
+	testCases := []struct {
+		Name     string
+		Raw      map[string]interface{}
+		Expected []*ec2.Filter
+		Error    string
+	}{
+		{
+			Name: "valid file",
+			Raw: map[string]interface{}{
+				"name":        "image-id",
+				"values_file": filepath.Join(dir, "valid.txt"),
+			},
+			Expected: []*ec2.Filter{
+				{Name: aws.String("image-id"), Values: aws.StringSlice([]string{"ami-00000001", "ami-00000002"})},
+			},
+		},
+		{
+			Name: "comments and blank lines",
+			Raw: map[string]interface{}{
+				"name":        "image-id",
+				"values_file": filepath.Join(dir, "comments.txt"),
+			},
+			Expected: []*ec2.Filter{
+				{Name: aws.String("image-id"), Values: aws.StringSlice([]string{"ami-00000001", "ami-00000003"})},
+			},
+		},
+		{
+			Name: "union with values",
+			Raw: map[string]interface{}{
+				"name":        "image-id",
+				"values":      []interface{}{"ami-00000004", "ami-00000001"},
+				"values_file": filepath.Join(dir, "valid.txt"),
+			},
+			Expected: []*ec2.Filter{
+				{Name: aws.String("image-id"), Values: aws.StringSlice([]string{"ami-00000001", "ami-00000002", "ami-00000004"})},
+			},
+		},
+		{
+			Name: "nonexistent path",
+			Raw: map[string]interface{}{
+				"name":        "image-id",
+				"values_file": filepath.Join(dir, "missing.txt"),
+			},
+			Expected: []*ec2.Filter{},
+			Error:    `filter image-id: error reading values_file "` + filepath.Join(dir, "missing.txt") + `"`,
+		},
+		{
+			Name: "no values",
+			Raw: map[string]interface{}{
+				"name":        "image-id",
+				"values_file": filepath.Join(dir, "empty.txt"),
+			},
+			Expected: []*ec2.Filter{},
+			Error:    `filter image-id: values_file "` + filepath.Join(dir, "empty.txt") + `" has no values`,
+		},
+	}
+
+	s := map[string]*schema.Schema{
+		"filter": ec2CustomFiltersSchema(),
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+				"filter": []interface{}{testCase.Raw},
+			})
+
+			if err := validateEC2CustomFilters(d.Get("filter").(*schema.Set)); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got, diags := buildEC2CustomFilterList(d.Get("filter").(*schema.Set))
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got filters %s, expected %s", got, testCase.Expected)
+			}
+
+			if testCase.Error == "" {
+				if diags.HasError() {
+					t.Errorf("unexpected diagnostics: %v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 || !strings.Contains(diags[0].Summary, testCase.Error) {
+				t.Errorf("got diagnostics %v, expected %q", diags, testCase.Error)
+			}
+		})
+	}
+}
+
+func TestValidateEC2CustomFiltersValuesFile(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"filter": ec2CustomFiltersSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{"name": "image-id"},
+		},
+	})
+
+	if err := validateEC2CustomFilters(d.Get("filter").(*schema.Set)); err == nil || !strings.Contains(err.Error(), "unless values_file is set") {
+		t.Errorf("got error %v, expected a filter without values nor values_file to be rejected", err)
+	}
+}
+
+func TestValidateEC2CustomFilterValues(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		FilterName     string
+		Values         []string
+		ExpectedErrors []string
+	}{
+		{
+			Name:       "valid IDs",
+			FilterName: "vpc-id",
+			Values:     []string{"vpc-01234567", "vpc-0123456789abcdef0", "vpc-0123*"},
+		},
+		{
+			Name:           "invalid IDs",
+			FilterName:     "group-id",
+			Values:         []string{"sg-0123", "vpc-01234567"},
+			ExpectedErrors: []string{`filter group-id: "sg-0123" is not a valid security-group ID, expected sg- followed by 8 or 17 hexadecimal characters`, `filter group-id: "vpc-01234567" is not a valid security-group ID, expected sg- followed by 8 or 17 hexadecimal characters`},
+		},
+		{
+			Name:       "valid CIDR blocks",
+			FilterName: "ip-permission.cidr",
+			Values:     []string{"0.0.0.0/0", "2001:db8::/32", "10.*"},
+		},
+		{
+			Name:           "invalid CIDR block",
+			FilterName:     "cidr-block",
+			Values:         []string{"10.0.0.0/16", "10.0.0.0"},
+			ExpectedErrors: []string{`filter cidr-block: "10.0.0.0" is not a valid CIDR block`},
+		},
+		{
+			Name:       "other filter",
+			FilterName: "tag:Name",
+			Values:     []string{"vpc-0123", "10.0.0.0"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var errs []string
+			for _, d := range validateEC2CustomFilterValues(testCase.FilterName, testCase.Values) {
+				errs = append(errs, d.Summary)
+			}
+
+			if !reflect.DeepEqual(errs, testCase.ExpectedErrors) {
+				t.Errorf("got errors %v, expected %v", errs, testCase.ExpectedErrors)
+			}
+		})
+	}
+}
+
+func TestBuildEC2CustomFilterListWildcardDiagnostics(t *testing.T) {
+	testCases := []struct {
+		Name             string
+		Raw              []interface{}
+		ExpectedErrors   []string
+		ExpectedWarnings []string
+	}{
+		{
+			Name: "mixed",
+			Raw: []interface{}{
+				map[string]interface{}{
+					"name":     "tag:Name",
+					"values":   []interface{}{"web-*", "api-?"},
+					"wildcard": true,
+				},
+				map[string]interface{}{
+					"name":   "tag:Environment",
+					"values": []interface{}{"prod"},
+				},
+			},
+		},
+		{
+			Name: "wildcard without wildcards",
+			Raw: []interface{}{
+				map[string]interface{}{
+					"name":     "tag:Name",
+					"values":   []interface{}{"web-*", "api"},
+					"wildcard": true,
+				},
+				map[string]interface{}{
+					"name":   "tag:Environment",
+					"values": []interface{}{"prod"},
+				},
+			},
+			ExpectedErrors: []string{`filter tag:Name: value "api" has no wildcard`},
+		},
+		{
+			Name: "wildcards without wildcard",
+			Raw: []interface{}{
+				map[string]interface{}{
+					"name":     "tag:Name",
+					"values":   []interface{}{"web-*"},
+					"wildcard": true,
+				},
+				map[string]interface{}{
+					"name":   "tag:Environment",
+					"values": []interface{}{"prod*", "dev?"},
+				},
+			},
+			ExpectedWarnings: []string{`filter tag:Environment: value "prod*" contains "*" but wildcard is not set`},
+		},
+		{
+			Name: "disabled",
+			Raw: []interface{}{
+				map[string]interface{}{
+					"name":     "tag:Name",
+					"values":   []interface{}{"api"},
+					"wildcard": true,
+					"enabled":  false,
+				},
+			},
+		},
+	}
+
+	s := map[string]*schema.Schema{
+		"filter": ec2CustomFiltersSchema(),
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+				"filter": testCase.Raw,
+			})
+
+			filters, diags := buildEC2CustomFilterList(d.Get("filter").(*schema.Set))
+
+			var errs, warnings []string
+			for _, d := range diags {
+				if d.Severity == diag.Warning {
+					warnings = append(warnings, d.Summary)
+				} else {
+					errs = append(errs, d.Summary)
+				}
+			}
+
+			if !reflect.DeepEqual(errs, testCase.ExpectedErrors) {
+				t.Errorf("got errors %v, expected %v", errs, testCase.ExpectedErrors)
+			}
+			if !reflect.DeepEqual(warnings, testCase.ExpectedWarnings) {
+				t.Errorf("got warnings %v, expected %v", warnings, testCase.ExpectedWarnings)
+			}
+
+			// The filters are built regardless of the diagnostics.
+			expected := 0
+			for _, v := range testCase.Raw {
+				if ec2CustomFilterEnabled(v.(map[string]interface{})) {
+					expected++
+				}
+			}
+			if len(filters) != expected {
+				t.Errorf("got %d filters, expected %d", len(filters), expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2TagKeyFilterList(t *testing.T) {
+	testCases := []struct {
+		Name             string
+		Keys             []string
+		ExpectedAny      []*ec2.Filter
+		ExpectedRequired []*ec2.Filter
+	}{
+		{
+			Name: "no keys",
+		},
+		{
+			Name: "single key",
+			Keys: []string{"CostCenter"},
+			ExpectedAny: []*ec2.Filter{
+				{
+					Name:   aws.String("tag-key"),
+					Values: aws.StringSlice([]string{"CostCenter"}),
+				},
+			},
+			ExpectedRequired: []*ec2.Filter{
+				{
+					Name:   aws.String("tag-key"),
+					Values: aws.StringSlice([]string{"CostCenter"}),
+				},
+			},
+		},
+		{
+			Name: "empty keys",
+			Keys: []string{"", ""},
+		},
+		{
+			Name: "several keys",
+			Keys: []string{"CostCenter", "", "Project", "CostCenter"},
+			// A single filter, matching the objects with any of the keys.
+			ExpectedAny: []*ec2.Filter{
+				{
+					Name:   aws.String("tag-key"),
+					Values: aws.StringSlice([]string{"CostCenter", "Project"}),
+				},
+			},
+			// One filter per key, matching the objects with all of them.
+			ExpectedRequired: []*ec2.Filter{
+				{
+					Name:   aws.String("tag-key"),
+					Values: aws.StringSlice([]string{"CostCenter"}),
+				},
+				{
+					Name:   aws.String("tag-key"),
+					Values: aws.StringSlice([]string{"Project"}),
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := buildEC2TagKeyFilterList(testCase.Keys); !reflect.DeepEqual(got, testCase.ExpectedAny) {
+				t.Errorf("got any filters %s, expected %s", got, testCase.ExpectedAny)
+			}
+
+			if got := buildEC2RequiredTagKeyFilterList(testCase.Keys); !reflect.DeepEqual(got, testCase.ExpectedRequired) {
+				t.Errorf("got required filters %s, expected %s", got, testCase.ExpectedRequired)
+			}
+		})
+	}
+}
+
+func TestBuildEC2IDSelection(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		ResourceType    string
+		IDs             []string
+		ExpectedIDs     []*string
+		ExpectedFilters []*ec2.Filter
+	}{
+		{
+			Name:         "no IDs",
+			ResourceType: ec2.ResourceTypeVpc,
+		},
+		{
+			Name:         "dedicated parameter",
+			ResourceType: ec2.ResourceTypeVpc,
+			IDs:          []string{"vpc-01234567", "vpc-0123456789abcdef0"},
+			ExpectedIDs:  aws.StringSlice([]string{"vpc-01234567", "vpc-0123456789abcdef0"}),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			ids, filters := buildEC2IDSelection(testCase.ResourceType, testCase.IDs)
+
+			if !reflect.DeepEqual(ids, testCase.ExpectedIDs) {
+				t.Errorf("got IDs %s, expected %s", aws.StringValueSlice(ids), aws.StringValueSlice(testCase.ExpectedIDs))
+			}
+
+			if !reflect.DeepEqual(filters, testCase.ExpectedFilters) {
+				t.Errorf("got filters %s, expected %s", filters, testCase.ExpectedFilters)
+			}
+		})
+	}
+}
+
+func TestEscapeEC2FilterValue(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Value    string
+		Expected string
+	}{
+		{
+			Name:     "no wildcards",
+			Value:    "my-awesome-vpc",
+			Expected: "my-awesome-vpc",
+		},
+		{
+			Name:     "wildcards",
+			Value:    "my-*-vpc-?",
+			Expected: `my-\*-vpc-\?`,
+		},
+		{
+			Name:     "backslash",
+			Value:    `my\vpc*`,
+			Expected: `my\\vpc\*`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := escapeEC2FilterValue(testCase.Value); got != testCase.Expected {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2WarnIfMatchesOverSchema returns a *schema.Schema for the
+// "warn_if_matches_over" attribute of the resources changing the objects they
+// select, checked by checkEc2MatchThresholds.
+func ec2WarnIfMatchesOverSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+		Description:  "Warn, naming the count and the filters, when the selection matches more than this many objects, such as to catch filters broader than intended. The selection is counted at plan time, reporting the warning in `match_threshold_warning`, and again on apply, before any change is made.",
+	}
+}
+
+// ec2FailIfMatchesOverSchema returns a *schema.Schema for the
+// "fail_if_matches_over" attribute of the resources changing the objects
+// they select, checked by checkEc2MatchThresholds.
+func ec2FailIfMatchesOverSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+		Description:  "Fail without making any change when the selection matches more than this many objects, at plan time and again on apply. The selection stops being read as soon as this many are exceeded, so the error gives a lower bound of the count.",
+	}
+}
+
+// ec2MatchThresholdWarningSchema returns a *schema.Schema for the
+// "match_threshold_warning" attribute of the resources declaring
+// ec2WarnIfMatchesOverSchema, set at plan time by
+// customizeDiffEc2MatchThresholds.
+func ec2MatchThresholdWarningSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The warning of `warn_if_matches_over` when the selection matched more than that many objects at plan time, shown in the plan, or empty.",
+	}
+}
+
+// ec2MatchThresholdSelectionAttributes are the attributes of the selection
+// read by buildEC2Selection, which customizeDiffEc2MatchThresholds only
+// counts once they are known.
+var ec2MatchThresholdSelectionAttributes = []string{
+	"ids",
+	"arns",
+	"name",
+	"filter",
+	"tags",
+	"case_insensitive",
+	"any_tag_keys",
+	"has_tags",
+	"required_tag_keys",
+	"filters_csv",
+	"filters_json",
+	"cloudformation_stack_name",
+	"ids_strict",
+}
+
+// ec2MatchCountFunc counts the objects selected by the resource with the
+// given resourceDataGetter, reading no more than its fail_if_matches_over,
+// and returns the count along with the filters they were selected with.
+type ec2MatchCountFunc func(d resourceDataGetter, meta interface{}) (int, []*ec2.Filter, error)
+
+// customizeDiffEc2MatchThresholds returns the CustomizeDiff of the resources
+// declaring ec2WarnIfMatchesOverSchema, ec2FailIfMatchesOverSchema and
+// ec2MatchThresholdWarningSchema, checking the count of the objects of the
+// given kind selected with count against the thresholds at plan time, so
+// that a selection broader than intended is caught before apply. It fails the
+// plan when fail_if_matches_over is exceeded, and otherwise sets
+// match_threshold_warning to the warning of warn_if_matches_over, as a
+// CustomizeDiff cannot return warnings.
+//
+// Nothing is counted when no threshold is set, or while the selection, or any
+// of the given attributes it also depends on, is unknown. The thresholds are
+// checked again on apply, with checkEc2MatchThresholds.
+func customizeDiffEc2MatchThresholds(kind string, count ec2MatchCountFunc, keys ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		if d.Get("warn_if_matches_over").(int) == 0 && d.Get("fail_if_matches_over").(int) == 0 {
+			return nil
+		}
+
+		if _, ok := meta.(*AWSClient); !ok {
+			return nil
+		}
+
+		for _, k := range append(append([]string{}, ec2MatchThresholdSelectionAttributes...), keys...) {
+			if unknown := unknownResourceDiffKey(d, k); unknown != "" {
+				log.Printf("[DEBUG] Not counting the selected %s at plan time: %s is unknown", kind, unknown)
+				return nil
+			}
+		}
+
+		n, filters, err := count(d, meta)
+		if err != nil {
+			return err
+		}
+
+		warnings, err := checkEc2MatchThresholds(d, n, kind, filters)
+		if err != nil {
+			return err
+		}
+
+		var warning string
+		for _, w := range warnings {
+			log.Printf("[WARN] %s: %s", w.Summary, w.Detail)
+			warning = w.Summary + ". " + w.Detail
+		}
+
+		return d.SetNew("match_threshold_warning", warning)
+	}
+}
+
+// unknownResourceDiffKey returns the first key of the given attribute of the
+// given *schema.ResourceDiff, or of any of its nested values, whose value is
+// unknown at plan time, or an empty string if it is entirely known. The
+// unknown elements of maps, lists and sets only show in the keys of their
+// counts, such as "tags.%", which NewValueKnown on the attribute misses.
+func unknownResourceDiffKey(d *schema.ResourceDiff, key string) string {
+	if !d.NewValueKnown(key) {
+		return key
+	}
+
+	for _, k := range d.GetChangedKeysPrefix(key) {
+		if !d.NewValueKnown(k) {
+			return k
+		}
+	}
+
+	return ""
+}
+
+// ec2FailIfMatchesOver returns the "fail_if_matches_over" attribute of the
+// given resourceDataGetter, or 0 if it is not set, to pass as the
+// maxResults of the finders reading the selection, so that they stop
+// following result pages as soon as it is exceeded.
+func ec2FailIfMatchesOver(d resourceDataGetter) int {
+	return d.Get("fail_if_matches_over").(int)
+}
+
+// ec2FailIfMatchesOverError turns the given error into the error of
+// "fail_if_matches_over" naming the given filters if it is a
+// *finder.MaxResultsExceededError, as returned by the finders passed
+// ec2FailIfMatchesOver, and returns it unchanged otherwise.
+func ec2FailIfMatchesOverError(err error, filters []*ec2.Filter) error {
+	var exceeded *finder.MaxResultsExceededError
+	if errors.As(err, &exceeded) {
+		return fmt.Errorf("%w with %s, above fail_if_matches_over; narrow the selection or raise fail_if_matches_over", err, describeEc2MatchThresholdFilters(filters))
+	}
+
+	return err
+}
+
+// checkEc2MatchThresholds checks the given count of the objects of the given
+// kind, such as "EC2 Instances", selected with the given filters, against the
+// "warn_if_matches_over" and "fail_if_matches_over" attributes of the given
+// resourceDataGetter. It returns an error when the count exceeds
+// fail_if_matches_over, which covers the lookups the finders make in a single
+// request regardless of their maxResults, and a warning when it exceeds
+// warn_if_matches_over.
+func checkEc2MatchThresholds(d resourceDataGetter, count int, kind string, filters []*ec2.Filter) (diag.Diagnostics, error) {
+	if threshold := d.Get("fail_if_matches_over").(int); threshold > 0 && count > threshold {
+		return nil, fmt.Errorf("%d %s matched %s, more than fail_if_matches_over (%d); narrow the selection or raise fail_if_matches_over", count, kind, describeEc2MatchThresholdFilters(filters), threshold)
+	}
+
+	if threshold := d.Get("warn_if_matches_over").(int); threshold > 0 && count > threshold {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("The selection matches %d %s, more than warn_if_matches_over (%d)", count, kind, threshold),
+				Detail:   fmt.Sprintf("The %s were selected with %s. Check that the selection is not broader than intended, or raise warn_if_matches_over.", kind, describeEc2MatchThresholdFilters(filters)),
+			},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// describeEc2MatchThresholdFilters returns the given filters as formatted by
+// formatEC2Filters for the messages of the match thresholds.
+func describeEc2MatchThresholdFilters(filters []*ec2.Filter) string {
+	if len(filters) == 0 {
+		return "no filters"
+	}
+
+	return "the filters " + formatEC2Filters(filters)
+}
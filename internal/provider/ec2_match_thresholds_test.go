@@ -0,0 +1,253 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestCheckEc2MatchThresholds(t *testing.T) {
+	schemas := map[string]*schema.Schema{
+		"warn_if_matches_over": ec2WarnIfMatchesOverSchema(),
+		"fail_if_matches_over": ec2FailIfMatchesOverSchema(),
+	}
+	filters := []*ec2.Filter{
+		{Name: aws.String("tag:Environment"), Values: aws.StringSlice([]string{"prod*"})},
+	}
+
+	testCases := []struct {
+		Name    string
+		Config  map[string]interface{}
+		Count   int
+		Warning []string
+		Error   []string
+	}{
+		{
+			Name:   "no thresholds",
+			Config: map[string]interface{}{},
+			Count:  1000,
+		},
+		{
+			Name:   "at the thresholds",
+			Config: map[string]interface{}{"warn_if_matches_over": 10, "fail_if_matches_over": 10},
+			Count:  10,
+		},
+		{
+			Name:    "warning",
+			Config:  map[string]interface{}{"warn_if_matches_over": 10},
+			Count:   11,
+			Warning: []string{"matches 11 EC2 Instances", "warn_if_matches_over (10)", "tag:Environment=[prod* (wildcard)]"},
+		},
+		{
+			Name:   "error",
+			Config: map[string]interface{}{"warn_if_matches_over": 10, "fail_if_matches_over": 20},
+			Count:  21,
+			Error:  []string{"21 EC2 Instances matched", "fail_if_matches_over (20)", "tag:Environment=[prod* (wildcard)]"},
+		},
+		{
+			Name:    "warning under the error",
+			Config:  map[string]interface{}{"warn_if_matches_over": 10, "fail_if_matches_over": 20},
+			Count:   20,
+			Warning: []string{"matches 20 EC2 Instances"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, schemas, testCase.Config)
+
+			warnings, err := checkEc2MatchThresholds(d, testCase.Count, "EC2 Instances", filters)
+
+			if testCase.Error == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				for _, expected := range testCase.Error {
+					if !strings.Contains(err.Error(), expected) {
+						t.Errorf("got error %q, expected it to contain %q", err, expected)
+					}
+				}
+			}
+
+			if testCase.Warning == nil {
+				if len(warnings) != 0 {
+					t.Errorf("unexpected warnings: %v", warnings)
+				}
+				return
+			}
+
+			if len(warnings) != 1 || warnings[0].Severity != diag.Warning {
+				t.Fatalf("got %v, expected a single warning", warnings)
+			}
+			for _, expected := range testCase.Warning {
+				if message := warnings[0].Summary + " " + warnings[0].Detail; !strings.Contains(message, expected) {
+					t.Errorf("got warning %q, expected it to contain %q", message, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestEc2FailIfMatchesOverError(t *testing.T) {
+	filters := []*ec2.Filter{
+		{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{"vpc-01234567"})},
+	}
+
+	err := ec2FailIfMatchesOverError(&finder.MaxResultsExceededError{MaxResults: 5}, filters)
+	if expected := "more than 5 results matched with the filters vpc-id=[vpc-01234567], above fail_if_matches_over"; !strings.Contains(err.Error(), expected) {
+		t.Errorf("got error %q, expected it to contain %q", err, expected)
+	}
+
+	if err := ec2FailIfMatchesOverError(&finder.MaxResultsExceededError{MaxResults: 5}, nil); !strings.Contains(err.Error(), "with no filters") {
+		t.Errorf("got error %q, expected it to mention that there are no filters", err)
+	}
+
+	other := errors.New("throttled")
+	if err := ec2FailIfMatchesOverError(other, filters); err != other {
+		t.Errorf("got error %v, expected %v unchanged", err, other)
+	}
+}
+
+func TestEnforceEc2InstanceDetailedMonitoringFailIfMatchesOver(t *testing.T) {
+	page := func(ids ...string) []*ec2.Reservation {
+		instances := make([]*ec2.Instance, 0, len(ids))
+		for _, id := range ids {
+			instances = append(instances, &ec2.Instance{
+				InstanceId: aws.String(id),
+				State:      &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+				Monitoring: &ec2.Monitoring{State: aws.String(ec2.MonitoringStateDisabled)},
+			})
+		}
+		return []*ec2.Reservation{{Instances: instances}}
+	}
+
+	var describeRequests int
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeInstancesOutput:
+			describeRequests++
+			// Every page but the last has a next token, so reading stops early only if the threshold is checked.
+			output.Reservations = page("i-00000001", "i-00000002")
+			if describeRequests < 3 {
+				output.NextToken = aws.String("next")
+			}
+		case *ec2.MonitorInstancesOutput:
+			t.Errorf("unexpected MonitorInstances request")
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2InstanceDetailedMonitoringEnforcer().Schema, map[string]interface{}{
+		"tags":                 map[string]interface{}{"Environment": "production"},
+		"fail_if_matches_over": 3,
+	})
+
+	_, err := enforceEc2InstanceDetailedMonitoring(context.Background(), d, &AWSClient{ec2conn: conn})
+	if err == nil || !strings.Contains(err.Error(), "more than 3 results matched with the filters tag:Environment=[production]") {
+		t.Errorf("got error %v, expected fail_if_matches_over to be exceeded", err)
+	}
+
+	if describeRequests != 2 {
+		t.Errorf("got %d DescribeInstances requests, expected reading to stop after 2", describeRequests)
+	}
+}
+
+func TestCustomizeDiffEc2MatchThresholds(t *testing.T) {
+	// unknownValue is the value of the attributes unknown at plan time in a *terraform.ResourceConfig.
+	const unknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+	testCases := []struct {
+		Name             string
+		Config           map[string]interface{}
+		ExpectedRequests int
+		Warning          string
+		Error            string
+	}{
+		{
+			Name:   "no thresholds",
+			Config: map[string]interface{}{"tags": map[string]interface{}{"Environment": "production"}},
+		},
+		{
+			Name:             "under the thresholds",
+			Config:           map[string]interface{}{"tags": map[string]interface{}{"Environment": "production"}, "warn_if_matches_over": 10},
+			ExpectedRequests: 3,
+		},
+		{
+			Name:             "warning",
+			Config:           map[string]interface{}{"tags": map[string]interface{}{"Environment": "production"}, "warn_if_matches_over": 5},
+			ExpectedRequests: 3,
+			Warning:          "The selection matches 6 EC2 Instances, more than warn_if_matches_over (5)",
+		},
+		{
+			Name:             "error",
+			Config:           map[string]interface{}{"tags": map[string]interface{}{"Environment": "production"}, "fail_if_matches_over": 3},
+			ExpectedRequests: 2,
+			Error:            "more than 3 results matched with the filters tag:Environment=[production]",
+		},
+		{
+			Name:   "unknown selection",
+			Config: map[string]interface{}{"tags": map[string]interface{}{"Environment": unknownValue}, "fail_if_matches_over": 3},
+		},
+		{
+			Name: "unknown filter value",
+			Config: map[string]interface{}{
+				"filter":               []interface{}{map[string]interface{}{"name": "vpc-id", "values": []interface{}{unknownValue}}},
+				"fail_if_matches_over": 3,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var describeRequests int
+
+			conn := testEc2Conn(t, func(r *request.Request) {
+				if output, ok := r.Data.(*ec2.DescribeInstancesOutput); ok {
+					describeRequests++
+					output.Reservations = []*ec2.Reservation{{Instances: []*ec2.Instance{
+						{InstanceId: aws.String("i-0000000" + string(rune('0'+2*describeRequests-1)))},
+						{InstanceId: aws.String("i-0000000" + string(rune('0'+2*describeRequests)))},
+					}}}
+					if describeRequests < 3 {
+						output.NextToken = aws.String("next")
+					}
+				}
+			})
+
+			diff, err := resourceAwsUtilsEc2InstanceDetailedMonitoringEnforcer().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(testCase.Config), &AWSClient{ec2conn: conn})
+
+			if testCase.Error != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.Error) {
+					t.Errorf("got error %v, expected %q", err, testCase.Error)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if describeRequests != testCase.ExpectedRequests {
+				t.Errorf("got %d DescribeInstances requests, expected %d", describeRequests, testCase.ExpectedRequests)
+			}
+
+			if err != nil || testCase.ExpectedRequests == 0 {
+				return
+			}
+
+			if got := diff.Attributes["match_threshold_warning"]; got == nil || !strings.Contains(got.New, testCase.Warning) || (testCase.Warning == "") != (got.New == "") {
+				t.Errorf("got match_threshold_warning %v, expected %q", got, testCase.Warning)
+			}
+		})
+	}
+}
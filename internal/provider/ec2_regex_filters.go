@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2RegexFilterAttributeRegexp matches the attribute paths of the "regex_filter" blocks: snake_case names of the
+// fields of the objects returned by the EC2 API, separated by dots for nested objects.
+var ec2RegexFilterAttributeRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+// ec2RegexFiltersSchema returns a *schema.Schema for the "regex_filter"
+// blocks of data sources, matching the objects returned by the EC2 API
+// against regular expressions on the client side, for the attributes which
+// cannot be filtered on, or not with wildcards, such as the private DNS name
+// or the launch time of instances. The blocks are converted into a predicate
+// by buildEC2RegexFilterPredicateFromResourceData.
+func ec2RegexFiltersSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "Only keep the objects returned by the EC2 API whose attribute matches the regular expression, on the client side, after the other filters are applied by the API. Every block must match.",
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"attribute": {
+					Description:  "The attribute of the objects, as named by the EC2 API in snake_case, such as `private_dns_name`, with dots for nested attributes, such as `placement.availability_zone`. Times are matched in the RFC 3339 format, such as `2021-06-01T12:00:00Z`, and missing attributes as the empty string. Lists cannot be matched.",
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validation.StringMatch(ec2RegexFilterAttributeRegexp, "must be a snake_case attribute name, with dots for nested attributes"),
+				},
+				"pattern": {
+					Description:  "The regular expression, in the syntax of Go's `regexp` package, which the attribute must match. It is not anchored, so `^` and `$` are needed to match the whole value.",
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validateEC2RegexFilterPattern,
+				},
+			},
+		},
+	}
+}
+
+// validateEC2RegexFilterPattern validates the regular expression of a "regex_filter" block, so that an invalid
+// pattern fails at plan time rather than after the objects are read.
+func validateEC2RegexFilterPattern(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := regexp.Compile(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%s: invalid regular expression %q: %w", k, v.(string), err))
+	}
+
+	return
+}
+
+// ec2RegexFilter is an expanded "regex_filter" block.
+type ec2RegexFilter struct {
+	Path    [][]int
+	Pattern *regexp.Regexp
+}
+
+// buildEC2RegexFilterPredicate returns a function reporting whether the
+// given object returned by the EC2 API matches all the given "regex_filter"
+// blocks, as the client-side companion of buildEC2CustomFilterList for the
+// attributes the API cannot filter on. The objects are of the type of the
+// given object, such as (*ec2.Instance)(nil), against which the attributes
+// are resolved, so that an attribute which is not one of the type fails
+// before any object is matched. The returned function matches everything if
+// there are no blocks.
+func buildEC2RegexFilterPredicate(object interface{}, blocks []interface{}) (func(interface{}) bool, error) {
+	filters := make([]*ec2RegexFilter, 0, len(blocks))
+
+	for _, tfMapRaw := range blocks {
+		tfMap := tfMapRaw.(map[string]interface{})
+		attribute := tfMap["attribute"].(string)
+		pattern := tfMap["pattern"].(string)
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("regex_filter %s: invalid regular expression %q: %w", attribute, pattern, err)
+		}
+
+		path, err := ec2RegexFilterAttributePath(reflect.TypeOf(object), attribute)
+		if err != nil {
+			return nil, fmt.Errorf("regex_filter %s: %w", attribute, err)
+		}
+
+		filters = append(filters, &ec2RegexFilter{
+			Path:    path,
+			Pattern: re,
+		})
+	}
+
+	return func(object interface{}) bool {
+		for _, filter := range filters {
+			if !filter.Pattern.MatchString(ec2RegexFilterAttributeValue(reflect.ValueOf(object), filter.Path)) {
+				return false
+			}
+		}
+
+		return true
+	}, nil
+}
+
+// buildEC2RegexFilterPredicateFromResourceData reads the "regex_filter"
+// blocks of a *schema.ResourceData (an attribute conforming to the schema
+// returned by ec2RegexFiltersSchema()) and returns the result of
+// buildEC2RegexFilterPredicate, which matches everything if there are none.
+func buildEC2RegexFilterPredicateFromResourceData(d *schema.ResourceData, object interface{}) (func(interface{}) bool, error) {
+	var blocks []interface{}
+	if v, ok := d.GetOk("regex_filter"); ok {
+		blocks = v.(*schema.Set).List()
+	}
+
+	return buildEC2RegexFilterPredicate(object, blocks)
+}
+
+// ec2RegexFilterAttributePath returns the indexes of the fields of the given
+// attribute path of the given type of object, following the pointers to
+// nested objects, as passed to reflect.Value.FieldByIndex. Each snake_case
+// name of the path is that of a field of the object, ignoring case, such as
+// private_dns_name for PrivateDnsName. The attribute must be a string, a
+// boolean, a number or a time.
+func ec2RegexFilterAttributePath(t reflect.Type, attribute string) ([][]int, error) {
+	var path [][]int
+
+	for _, name := range strings.Split(attribute, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct || t == ec2RegexFilterTimeType {
+			return nil, fmt.Errorf("%s is not an attribute of a nested object", name)
+		}
+
+		fieldName := strings.ReplaceAll(name, "_", "")
+		field, ok := t.FieldByNameFunc(func(n string) bool {
+			return strings.EqualFold(n, fieldName)
+		})
+		if !ok {
+			return nil, fmt.Errorf("%s is not an attribute of %s", name, t.Name())
+		}
+
+		path = append(path, field.Index)
+		t = field.Type
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return path, nil
+	case reflect.Struct:
+		if t == ec2RegexFilterTimeType {
+			return path, nil
+		}
+		return nil, fmt.Errorf("is a nested object, name one of its attributes")
+	default:
+		return nil, fmt.Errorf("is a %s, which cannot be matched", t.Kind())
+	}
+}
+
+// ec2RegexFilterTimeType is the type of the times of the objects returned by the EC2 API.
+var ec2RegexFilterTimeType = reflect.TypeOf(time.Time{})
+
+// ec2RegexFilterAttributeValue returns the value of the attribute at the
+// given path, as returned by ec2RegexFilterAttributePath, of the given
+// object. Times are formatted in the RFC 3339 format, and missing values,
+// including those of missing nested objects, are returned as the empty
+// string.
+func ec2RegexFilterAttributeValue(v reflect.Value, path [][]int) string {
+	for _, index := range path {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return ""
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByIndex(index)
+	}
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	default:
+		return v.Interface().(time.Time).UTC().Format(time.RFC3339)
+	}
+}
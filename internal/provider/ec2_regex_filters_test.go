@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestBuildEC2RegexFilterPredicate(t *testing.T) {
+	instances := []*ec2.Instance{
+		{
+			InstanceId:     aws.String("i-00000001"),
+			PrivateDnsName: aws.String("ip-10-0-0-1.ec2.internal"),
+			LaunchTime:     aws.Time(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)),
+			Placement:      &ec2.Placement{AvailabilityZone: aws.String("us-east-1a")},
+		},
+		{
+			InstanceId:     aws.String("i-00000002"),
+			PrivateDnsName: aws.String("ip-10-0-1-2.ec2.internal"),
+			LaunchTime:     aws.Time(time.Date(2021, 6, 2, 12, 0, 0, 0, time.UTC)),
+			Placement:      &ec2.Placement{AvailabilityZone: aws.String("us-east-1a")},
+		},
+		{
+			InstanceId:     aws.String("i-00000003"),
+			PrivateDnsName: aws.String("ip-10-0-0-3.ec2.internal"),
+			LaunchTime:     aws.Time(time.Date(2021, 6, 1, 18, 0, 0, 0, time.UTC)),
+		},
+	}
+
+	testCases := []struct {
+		Name     string
+		Blocks   []interface{}
+		Expected []string
+	}{
+		{
+			Name:     "no blocks",
+			Expected: []string{"i-00000001", "i-00000002", "i-00000003"},
+		},
+		{
+			Name: "string attribute",
+			Blocks: []interface{}{
+				map[string]interface{}{"attribute": "private_dns_name", "pattern": `^ip-10-0-0-\d+\.`},
+			},
+			Expected: []string{"i-00000001", "i-00000003"},
+		},
+		{
+			Name: "time attribute",
+			Blocks: []interface{}{
+				map[string]interface{}{"attribute": "launch_time", "pattern": `^2021-06-01T`},
+			},
+			Expected: []string{"i-00000001", "i-00000003"},
+		},
+		{
+			Name: "all blocks must match",
+			Blocks: []interface{}{
+				map[string]interface{}{"attribute": "private_dns_name", "pattern": `^ip-10-0-0-`},
+				map[string]interface{}{"attribute": "placement.availability_zone", "pattern": `^us-east-1a$`},
+			},
+			Expected: []string{"i-00000001"},
+		},
+		{
+			Name: "missing nested object",
+			Blocks: []interface{}{
+				map[string]interface{}{"attribute": "placement.availability_zone", "pattern": `^$`},
+			},
+			Expected: []string{"i-00000003"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			matched, err := buildEC2RegexFilterPredicate((*ec2.Instance)(nil), testCase.Blocks)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var got []string
+			for _, instance := range instances {
+				if matched(instance) {
+					got = append(got, aws.StringValue(instance.InstanceId))
+				}
+			}
+
+			if strings.Join(got, ",") != strings.Join(testCase.Expected, ",") {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestBuildEC2RegexFilterPredicateErrors(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Attribute string
+		Pattern   string
+		Error     string
+	}{
+		{
+			Name:      "invalid pattern",
+			Attribute: "private_dns_name",
+			Pattern:   `ip-(10`,
+			Error:     `invalid regular expression "ip-(10"`,
+		},
+		{
+			Name:      "unknown attribute",
+			Attribute: "private_dns",
+			Pattern:   `.`,
+			Error:     "private_dns is not an attribute of Instance",
+		},
+		{
+			Name:      "list attribute",
+			Attribute: "security_groups",
+			Pattern:   `.`,
+			Error:     "cannot be matched",
+		},
+		{
+			Name:      "nested object",
+			Attribute: "placement",
+			Pattern:   `.`,
+			Error:     "is a nested object",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := buildEC2RegexFilterPredicate((*ec2.Instance)(nil), []interface{}{
+				map[string]interface{}{"attribute": testCase.Attribute, "pattern": testCase.Pattern},
+			})
+
+			if err == nil || !strings.Contains(err.Error(), testCase.Error) {
+				t.Errorf("got error %v, expected %q", err, testCase.Error)
+			}
+		})
+	}
+}
+
+func TestValidateEC2RegexFilterPattern(t *testing.T) {
+	if _, errs := validateEC2RegexFilterPattern(`^ip-10-0-\d+`, "regex_filter.0.pattern"); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	_, errs := validateEC2RegexFilterPattern(`ip-[10`, "regex_filter.0.pattern")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `"ip-[10"`) {
+		t.Errorf("got errors %v, expected one citing the pattern", errs)
+	}
+}
+
+func TestBuildEC2RegexFilterPredicateFromResourceData(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{"regex_filter": ec2RegexFiltersSchema()}, map[string]interface{}{
+		"regex_filter": []interface{}{
+			map[string]interface{}{"attribute": "image_id", "pattern": `^ami-0`},
+		},
+	})
+
+	matched, err := buildEC2RegexFilterPredicateFromResourceData(d, (*ec2.Image)(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !matched(&ec2.Image{ImageId: aws.String("ami-01234567")}) {
+		t.Errorf("expected ami-01234567 to match")
+	}
+
+	if matched(&ec2.Image{ImageId: aws.String("ami-89abcdef")}) {
+		t.Errorf("expected ami-89abcdef not to match")
+	}
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"github.com/aws/aws-sdk-go/service/ec2"
+	awsbase "github.com/hashicorp/aws-sdk-go-base"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ec2RegionSchema returns a *schema.Schema for the "region" attribute of the
+// data sources selecting EC2 objects with filters, read by
+// ec2DataSourceConnForRegion.
+func ec2RegionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "The region to select the objects in, instead of the region of the provider, such as to read another region without configuring a provider alias for it. It is validated against the known AWS regions unless `skip_region_validation` is set on the provider. Defaults to the region of the provider.",
+	}
+}
+
+// ec2ConnForRegion returns the EC2 client of the given region, made with the
+// ec2connForRegion factory of the client, and the client's own EC2 client if
+// the region is empty or the region of the provider.
+func (client *AWSClient) ec2ConnForRegion(region string) (*ec2.EC2, error) {
+	if region == "" || region == client.region {
+		return client.ec2conn, nil
+	}
+
+	if !client.skipRegionValidation {
+		if err := awsbase.ValidateRegion(region); err != nil {
+			return nil, err
+		}
+	}
+
+	return client.ec2connForRegion(region), nil
+}
+
+// ec2DataSourceConnForRegion returns the EC2 client of the "region" attribute
+// of the given *schema.ResourceData, as returned by ec2ConnForRegion, and the
+// region it reads, to set as the ID of the data source.
+func ec2DataSourceConnForRegion(d *schema.ResourceData, meta interface{}) (*ec2.EC2, string, error) {
+	client := meta.(*AWSClient)
+
+	region := d.Get("region").(string)
+	if region == "" {
+		region = client.region
+	}
+
+	conn, err := client.ec2ConnForRegion(region)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return conn, region, nil
+}
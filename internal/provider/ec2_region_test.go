@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2DataSourceConnForRegion(t *testing.T) {
+	providerConn := &ec2.EC2{}
+	regionConn := &ec2.EC2{}
+
+	testCases := []struct {
+		Name                 string
+		Region               string
+		SkipRegionValidation bool
+		ExpectedConn         *ec2.EC2
+		ExpectedRegion       string
+		ExpectedFactory      []string
+		Error                string
+	}{
+		{
+			Name:           "omitted",
+			ExpectedConn:   providerConn,
+			ExpectedRegion: "us-east-1",
+		},
+		{
+			Name:           "region of the provider",
+			Region:         "us-east-1",
+			ExpectedConn:   providerConn,
+			ExpectedRegion: "us-east-1",
+		},
+		{
+			Name:            "other region",
+			Region:          "eu-west-1",
+			ExpectedConn:    regionConn,
+			ExpectedRegion:  "eu-west-1",
+			ExpectedFactory: []string{"eu-west-1"},
+		},
+		{
+			Name:   "invalid region",
+			Region: "eu-nowhere-1",
+			Error:  "Invalid AWS Region: eu-nowhere-1",
+		},
+		{
+			Name:                 "invalid region skipping validation",
+			Region:               "eu-nowhere-1",
+			SkipRegionValidation: true,
+			ExpectedConn:         regionConn,
+			ExpectedRegion:       "eu-nowhere-1",
+			ExpectedFactory:      []string{"eu-nowhere-1"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var factory []string
+			client := &AWSClient{
+				ec2conn: providerConn,
+				ec2connForRegion: func(region string) *ec2.EC2 {
+					factory = append(factory, region)
+					return regionConn
+				},
+				region:               "us-east-1",
+				skipRegionValidation: testCase.SkipRegionValidation,
+			}
+
+			d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{"region": ec2RegionSchema()}, map[string]interface{}{
+				"region": testCase.Region,
+			})
+
+			conn, region, err := ec2DataSourceConnForRegion(d, client)
+
+			if testCase.Error != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.Error) {
+					t.Fatalf("got error %v, expected it to contain %q", err, testCase.Error)
+				}
+				if len(factory) != 0 {
+					t.Errorf("got EC2 clients made for %v, expected none", factory)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if conn != testCase.ExpectedConn {
+				t.Errorf("got the wrong EC2 client")
+			}
+			if region != testCase.ExpectedRegion {
+				t.Errorf("got region %q, expected %q", region, testCase.ExpectedRegion)
+			}
+			if strings.Join(factory, ",") != strings.Join(testCase.ExpectedFactory, ",") {
+				t.Errorf("got EC2 clients made for %v, expected %v", factory, testCase.ExpectedFactory)
+			}
+		})
+	}
+}
+
+func TestDataSourceAwsUtilsEc2InstancesReadRegion(t *testing.T) {
+	var providerInputs, regionInputs []*ec2.DescribeInstancesInput
+	providerConn := testEc2InstancesConn(t, [][]*ec2.Instance{{}}, &providerInputs)
+	regionConn := testEc2InstancesConn(t, [][]*ec2.Instance{{{InstanceId: aws.String("i-01234567")}}}, &regionInputs)
+
+	var factory []string
+	client := &AWSClient{
+		ec2conn: providerConn,
+		ec2connForRegion: func(region string) *ec2.EC2 {
+			factory = append(factory, region)
+			return regionConn
+		},
+		region:        "us-east-1",
+		maxResultsCap: defaultMaxResultsCap,
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceAwsUtilsEc2Instances().Schema, map[string]interface{}{
+		"region":           "ap-southeast-2",
+		"allow_unfiltered": true,
+	})
+
+	if diags := dataSourceAwsUtilsEc2InstancesRead(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if strings.Join(factory, ",") != "ap-southeast-2" {
+		t.Errorf("got EC2 clients made for %v, expected ap-southeast-2", factory)
+	}
+	if len(providerInputs) != 0 || len(regionInputs) != 1 {
+		t.Errorf("got %d requests in the provider region and %d in ap-southeast-2, expected only 1 in ap-southeast-2", len(providerInputs), len(regionInputs))
+	}
+	if got := d.Id(); got != "ap-southeast-2" {
+		t.Errorf("got ID %q, expected ap-southeast-2", got)
+	}
+	if got := d.Get("ids").([]interface{}); len(got) != 1 || got[0] != "i-01234567" {
+		t.Errorf("got ids %v, expected [i-01234567]", got)
+	}
+}
@@ -0,0 +1,501 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2NameTagKey is the key of the tag conventionally holding the name of EC2 objects.
+const ec2NameTagKey = "Name"
+
+// ec2CloudFormationStackNameSchema returns a *schema.Schema for the
+// "cloudformation_stack_name" attribute, constraining the selected objects to
+// those of the given AWS CloudFormation stack with a filter on its
+// "aws:cloudformation:stack-name" tag, which is otherwise ignored for its
+// reserved prefix.
+func ec2CloudFormationStackNameSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Only match the objects of the AWS CloudFormation stack with the given name, from their `aws:cloudformation:stack-name` tag, which `tags` ignores for its reserved `aws:` prefix.",
+	}
+}
+
+// ec2NameSchema returns a *schema.Schema for the "name" attribute, a shorthand
+// for constraining the Name tag of the selected objects. Like the underlying
+// "tag:Name" filter, the value may contain the * and ? wildcards, unless the
+// provider escapes them.
+func ec2NameSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Only match objects whose `Name` tag matches the given value, which may contain the `*` and `?` wildcards unless the provider's `escape_filter_wildcards` is set. Shorthand for `tags = { Name = ... }`.",
+	}
+}
+
+// ec2ARNsSchema returns a *schema.Schema for the "arns" attribute, selecting
+// objects of the given EC2 resource type by ARN rather than by ID.
+func ec2ARNsSchema(resourceType string) *schema.Schema {
+	if _, ok := tfec2.ResourceTypeMetadataFor(resourceType); !ok {
+		panic(fmt.Sprintf("unsupported EC2 resource type: %s", resourceType))
+	}
+
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: fmt.Sprintf("Only match objects with the given ARNs, which must be `%s` ARNs of the provider's region. Combined with `ids` if both are given.", resourceType),
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+			ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+				if _, err := tfec2.ParseResourceARN(resourceType, v.(string)); err != nil {
+					errors = append(errors, fmt.Errorf("%s: %w", k, err))
+				}
+				return
+			},
+		},
+	}
+}
+
+// resourceDataGetter reads the attributes of a resource or data source. It is
+// implemented by *schema.ResourceData, on read and apply, and by
+// *schema.ResourceDiff, at plan time in a CustomizeDiff, so that a selection
+// can be built in both.
+type resourceDataGetter interface {
+	Get(key string) interface{}
+	GetOk(key string) (interface{}, bool)
+}
+
+// buildEC2Selection reads the attributes conventionally used by the data
+// sources and resources operating on a set of EC2 objects to select them, and
+// returns the IDs to pass in the dedicated ID parameter of the "Describe..."
+// input of the given resource type, if any, and the filters to pass in its
+// "Filters" attribute, or nil if there are none. The attributes are declared
+// in the schema as follows, and any which are not are ignored:
+//
+// "ids":               ec2IDsSchema(ec2.ResourceTypeVpc),
+// "arns":              ec2ARNsSchema(ec2.ResourceTypeVpc),
+// "name":              ec2NameSchema(),
+// "filter":            ec2CustomFiltersSchema(),
+// "tags":              tagsSchema(),
+// "any_tag_keys":      ec2AnyTagKeysSchema(),
+// "has_tags":          ec2HasTagsSchema(),
+// "required_tag_keys": ec2RequiredTagKeysSchema(),
+// "filters_csv":       ec2FiltersCSVSchema(),
+// "filters_json":      ec2FiltersJSONSchema(),
+// "cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+// "ids_strict":        ec2IDsStrictSchema(),
+//
+// The "name" attribute is merged into the "tags" map as the Name tag, so that
+// both are converted with ec2TagFiltersFromMap, which drops the tags ignored
+// by the provider's ignore_tags. It is an error for both to constrain the Name
+// tag to different values. The IDs parsed from the "arns"
+// attribute are added to those of the "ids" attribute, and it is an error for
+// any of the ARNs to be of another region than the provider's. The IDs are
+// returned as filters rather than IDs when the "ids_strict" attribute,
+// declared with ec2IDsStrictSchema(), is unset, see buildEC2IDFilterList. The
+// "any_tag_keys" and "has_tags" attributes become a single "tag-key" filter,
+// matching the objects with any of their keys, while "required_tag_keys"
+// becomes one per key, matching the objects with all of them. The
+// "cloudformation_stack_name" attribute becomes a filter on the tag AWS
+// CloudFormation adds despite its reserved "aws:" prefix, merged with the
+// "filter" blocks given on that tag. The "filters_csv" attribute adds the
+// filters read from its file with ec2FiltersFromCSV, and the "filters_json"
+// attribute those of its document with ec2FiltersFromJSON.
+//
+// When the provider's escape_filter_wildcards is set, the wildcards of the
+// "name", "tags", "filter", "any_tag_keys", "has_tags", "required_tag_keys",
+// "filters_csv" and "filters_json" values are escaped, except for the
+// "filter" blocks opting into them.
+//
+// When the "case_insensitive" attribute, declared with
+// ec2CaseInsensitiveTagsSchema(), is set, the "tags" attribute becomes a
+// "tag-key" filter per key instead, built by
+// buildEC2CaseInsensitiveTagKeyFilterList, and the caller is expected to
+// compare the values with the predicate returned by
+// buildEC2CaseInsensitiveTagPredicateFromResourceData. The "name" attribute
+// is still matched exactly.
+//
+// It is an error for the filters to exceed the provider's max_filter_values
+// or max_filters, as checked by validateEC2FilterLimits. The warnings about
+// the "filter" blocks, such as a "*" in the values of a block not setting
+// "wildcard", are returned for the caller to return along with its own.
+func buildEC2Selection(d resourceDataGetter, meta interface{}, resourceType string) ([]*string, []*ec2.Filter, diag.Diagnostics, error) {
+	var tags map[string]interface{}
+	if v, ok := d.GetOk("tags"); ok {
+		tags = v.(map[string]interface{})
+	}
+
+	var name string
+	if v, ok := d.GetOk("name"); ok {
+		name = v.(string)
+	}
+
+	var filterSet *schema.Set
+	if v, ok := d.GetOk("filter"); ok {
+		filterSet = v.(*schema.Set)
+	}
+
+	// The values of case-insensitive tags are compared on the client side, so only their keys are filtered on.
+	caseInsensitiveTags := ec2CaseInsensitiveTags(d, meta.(*AWSClient).IgnoreTagsConfig)
+	if caseInsensitiveTags != nil {
+		tags = nil
+	}
+
+	filters, warnings, err := buildEC2SelectionFilters(tags, name, filterSet, meta.(*AWSClient).escapeFilterWildcards, meta.(*AWSClient).IgnoreTagsConfig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if v, ok := d.GetOk("cloudformation_stack_name"); ok {
+		stackNameFilters := buildEC2CloudFormationStackNameFilterList(v.(string))
+		if meta.(*AWSClient).escapeFilterWildcards {
+			escapeEC2FilterWildcards(stackNameFilters...)
+		}
+		filters = mergeEC2FilterLists(filters, stackNameFilters)
+	}
+
+	// The "tag-key" filters of the case-insensitive tags are added once the filters are merged, as each of them
+	// must be kept separate for all of the keys to be required.
+	filters = append(filters, buildEC2CaseInsensitiveTagKeyFilterList(caseInsensitiveTags)...)
+
+	var anyTagKeys []string
+	if v, ok := d.GetOk("any_tag_keys"); ok {
+		anyTagKeys = append(anyTagKeys, ExpandStringSliceofPointers(ExpandStringList(v.([]interface{})))...)
+	}
+	if v, ok := d.GetOk("has_tags"); ok {
+		anyTagKeys = append(anyTagKeys, ExpandStringSliceofPointers(ExpandStringList(v.([]interface{})))...)
+	}
+
+	tagKeyFilters := buildEC2TagKeyFilterList(anyTagKeys)
+	if v, ok := d.GetOk("required_tag_keys"); ok {
+		tagKeyFilters = append(tagKeyFilters, buildEC2RequiredTagKeyFilterList(ExpandStringSliceofPointers(ExpandStringList(v.([]interface{}))))...)
+	}
+	if meta.(*AWSClient).escapeFilterWildcards {
+		escapeEC2FilterWildcards(tagKeyFilters...)
+	}
+	filters = append(filters, tagKeyFilters...)
+
+	if v, ok := d.GetOk("filters_csv"); ok && len(v.([]interface{})) > 0 && v.([]interface{})[0] != nil {
+		csvFilters, err := buildEC2FiltersCSVFilterList(v.([]interface{})[0].(map[string]interface{}))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if meta.(*AWSClient).escapeFilterWildcards {
+			escapeEC2FilterWildcards(csvFilters...)
+		}
+		filters = append(filters, csvFilters...)
+	}
+
+	if v, ok := d.GetOk("filters_json"); ok {
+		jsonFilters, err := ec2FiltersFromJSON(v.(string))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("error reading filters_json: %w", err)
+		}
+		if meta.(*AWSClient).escapeFilterWildcards {
+			escapeEC2FilterWildcards(jsonFilters...)
+		}
+		filters = append(filters, jsonFilters...)
+	}
+
+	var selectedIDs []string
+	if v, ok := d.GetOk("ids"); ok {
+		selectedIDs = append(selectedIDs, ExpandStringSliceofPointers(ExpandStringSet(v.(*schema.Set)))...)
+	}
+
+	if v, ok := d.GetOk("arns"); ok {
+		arnIDs, err := ec2IDsFromARNs(resourceType, meta.(*AWSClient).region, ExpandStringSliceofPointers(ExpandStringSet(v.(*schema.Set))))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		// An object may be given both by ID and by ARN.
+		for _, id := range arnIDs {
+			selectedIDs = appendUniqueString(selectedIDs, id)
+		}
+	}
+
+	var ids []*string
+	if v, ok := d.Get("ids_strict").(bool); !ok || v {
+		var idFilters []*ec2.Filter
+		ids, idFilters = buildEC2IDSelection(resourceType, selectedIDs)
+		filters = append(filters, idFilters...)
+	} else {
+		filters = append(filters, buildEC2IDFilterList(resourceType, selectedIDs)...)
+	}
+
+	if err := meta.(*AWSClient).validateEC2FilterLimits(filters); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if len(filters) == 0 {
+		filters = nil
+	}
+
+	return ids, filters, warnings, nil
+}
+
+// ec2IDsFromARNs returns the IDs of the objects with the given ARNs of the
+// given EC2 resource type, and an error if any of them is malformed, of
+// another resource type or, unless region is empty, of another region.
+func ec2IDsFromARNs(resourceType, region string, arns []string) ([]string, error) {
+	ids := make([]string, 0, len(arns))
+
+	for _, v := range arns {
+		parsed, err := tfec2.ParseResourceARN(resourceType, v)
+		if err != nil {
+			return nil, err
+		}
+
+		if region != "" && parsed.Region != region {
+			return nil, fmt.Errorf("%q is of region %s, but the provider is configured for %s", v, parsed.Region, region)
+		}
+
+		ids = append(ids, parsed.ID)
+	}
+
+	return ids, nil
+}
+
+// buildEC2SelectionFilters returns the filters of the given "tags", "name"
+// and "filter" attribute values of a selection, as described on
+// buildEC2Selection, any of which may be empty. They are merged with
+// mergeEC2FilterLists, so that a "filter" block on a tag also given in "tags"
+// matches either value. The tags ignored by the given configuration, the
+// provider's ignore_tags, are not turned into filters. The warnings about the
+// "filter" blocks are returned along with the filters.
+func buildEC2SelectionFilters(tagMap map[string]interface{}, name string, filterSet *schema.Set, escapeWildcards bool, ignoreConfig *keyvaluetags.IgnoreConfig) ([]*ec2.Filter, diag.Diagnostics, error) {
+	tags := make(map[string]interface{}, len(tagMap)+1)
+	for k, v := range tagMap {
+		tags[k] = v
+	}
+
+	if name != "" {
+		if existing, ok := tags[ec2NameTagKey]; ok && existing.(string) != name {
+			return nil, nil, fmt.Errorf("name (%s) conflicts with tags.%s (%s)", name, ec2NameTagKey, existing)
+		}
+		tags[ec2NameTagKey] = name
+	}
+
+	var tagFilters []*ec2.Filter
+	if len(tags) > 0 {
+		tagFilters = ec2TagFiltersFromMap(tags, ignoreConfig)
+		if escapeWildcards {
+			escapeEC2FilterWildcards(tagFilters...)
+		}
+	}
+
+	var customFilters []*ec2.Filter
+	var warnings diag.Diagnostics
+
+	if filterSet != nil && filterSet.Len() > 0 {
+		if err := validateEC2CustomFilters(filterSet); err != nil {
+			return nil, nil, err
+		}
+
+		var diags diag.Diagnostics
+		if escapeWildcards {
+			customFilters, diags = buildEC2CustomFilterListEscapingWildcards(filterSet)
+		} else {
+			customFilters, diags = buildEC2CustomFilterList(filterSet)
+		}
+
+		var err error
+		if warnings, err = splitEC2CustomFilterDiagnostics(diags); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return mergeEC2FilterLists(tagFilters, customFilters), warnings, nil
+}
+
+// defaultMaxResultsCap is the default of the provider's max_results_cap, the
+// maximum number of objects a data source may read.
+const defaultMaxResultsCap = 10000
+
+// maxResultsCapSchema returns a *schema.Schema for the "max_results_cap"
+// attribute of data sources, overriding the provider's.
+func maxResultsCapSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+		Description:  "The maximum number of objects to read, above which reading fails. Defaults to the provider's `max_results_cap`. Does not apply to lookups by `ids` or `arns` alone, which are made in a single request.",
+	}
+}
+
+// maxResultsCap returns the maximum number of objects the data source with
+// the given *schema.ResourceData may read, to pass to the finders.
+func maxResultsCap(d *schema.ResourceData, meta interface{}) int {
+	if v, ok := d.GetOk("max_results_cap"); ok {
+		return v.(int)
+	}
+
+	return meta.(*AWSClient).maxResultsCap
+}
+
+// maxResultsCapError adds a hint on how to resolve the given error to it if
+// it is a *finder.MaxResultsExceededError, and returns it unchanged otherwise.
+func maxResultsCapError(err error) error {
+	var exceeded *finder.MaxResultsExceededError
+	if errors.As(err, &exceeded) {
+		return fmt.Errorf("%w; narrow the selection or raise max_results_cap", err)
+	}
+
+	return err
+}
+
+// errEC2Unfiltered is the error of requireNonEmptyFilters.
+var errEC2Unfiltered = errors.New("no filter is set, so every object of the region would be read; set a filter, or set allow_unfiltered to read them all")
+
+// ec2AllowUnfilteredSchema returns a *schema.Schema for the "allow_unfiltered"
+// attribute of data sources, allowing them to be read without any filter.
+func ec2AllowUnfilteredSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Whether the data source may be read without any filter, reading every object of the region. By default, setting no filter is an error, as it is most often a mistake and can read thousands of objects. When set, reading without any filter returns a warning.",
+	}
+}
+
+// requireNonEmptyFilters returns an error if the given filters, the merged
+// filters of a data source, are empty, as reading with them would read every
+// object of the region.
+func requireNonEmptyFilters(filters []*ec2.Filter) error {
+	for _, filter := range filters {
+		if filter != nil {
+			return nil
+		}
+	}
+
+	return errEC2Unfiltered
+}
+
+// checkEC2Unfiltered returns the error of requireNonEmptyFilters for the given
+// IDs and merged filters of the data source with the given
+// *schema.ResourceData, unless its "allow_unfiltered" attribute is set, in
+// which case it returns a warning that every object of the region is read,
+// for the caller to return along with the warnings of buildEC2Selection.
+// Selecting by IDs alone is not unfiltered, as only the objects with those
+// IDs are read.
+func checkEC2Unfiltered(d *schema.ResourceData, ids []*string, filters []*ec2.Filter) (diag.Diagnostics, error) {
+	if len(ids) > 0 {
+		return nil, nil
+	}
+
+	err := requireNonEmptyFilters(filters)
+	if err == nil {
+		return nil, nil
+	}
+
+	if d.Get("allow_unfiltered").(bool) {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Warning,
+				Summary:  "No filter is set, reading every object of the region",
+				Detail:   "allow_unfiltered is set and no filter is, so every object of the region is read, which can be thousands of objects and slow. Set a filter to read only those needed.",
+			},
+		}, nil
+	}
+
+	return nil, err
+}
+
+// findEc2SelectedVpcs reads the VPCs selected by the resource with the given
+// resourceDataGetter, as built by buildEC2Selection, stopping past its
+// fail_if_matches_over, and returns them along with the filters they were
+// selected with and the warnings of buildEC2Selection. It is shared by apply
+// and the count of customizeDiffEc2MatchThresholds.
+func findEc2SelectedVpcs(d resourceDataGetter, meta interface{}) ([]*ec2.Vpc, []*ec2.Filter, diag.Diagnostics, error) {
+	input := &ec2.DescribeVpcsInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeVpc)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	input.VpcIds = ids
+	input.Filters = filters
+
+	vpcs, err := finder.Vpcs(meta.(*AWSClient).ec2conn, input, ec2FailIfMatchesOver(d))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading EC2 VPCs: %w", ec2FailIfMatchesOverError(err, input.Filters))
+	}
+
+	return vpcs, input.Filters, warnings, nil
+}
+
+// findEc2SelectedSecurityGroups reads the Security Groups selected by the
+// resource with the given resourceDataGetter, as findEc2SelectedVpcs does
+// the VPCs.
+func findEc2SelectedSecurityGroups(d resourceDataGetter, meta interface{}) ([]*ec2.SecurityGroup, []*ec2.Filter, diag.Diagnostics, error) {
+	input := &ec2.DescribeSecurityGroupsInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeSecurityGroup)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	input.GroupIds = ids
+	input.Filters = filters
+
+	groups, err := finder.SecurityGroups(meta.(*AWSClient).ec2conn, input, ec2FailIfMatchesOver(d))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading EC2 Security Groups: %w", ec2FailIfMatchesOverError(err, input.Filters))
+	}
+
+	return groups, input.Filters, warnings, nil
+}
+
+// findEc2SelectedInstances reads the instances in the given states selected
+// by the resource with the given resourceDataGetter, as findEc2SelectedVpcs
+// does the VPCs.
+func findEc2SelectedInstances(d resourceDataGetter, meta interface{}, states ...string) ([]*ec2.Instance, []*ec2.Filter, diag.Diagnostics, error) {
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeInstance)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	stateFilter, err := ec2InstanceStateFilter(states...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	input := &ec2.DescribeInstancesInput{
+		InstanceIds: ids,
+		Filters:     append(filters, stateFilter),
+	}
+
+	instances, err := finder.Instances(meta.(*AWSClient).ec2conn, input, ec2FailIfMatchesOver(d))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading EC2 Instances: %w", ec2FailIfMatchesOverError(err, input.Filters))
+	}
+
+	return instances, input.Filters, warnings, nil
+}
+
+// countEc2SelectedVpcs is the ec2MatchCountFunc of the resources selecting
+// VPCs with findEc2SelectedVpcs.
+func countEc2SelectedVpcs(d resourceDataGetter, meta interface{}) (int, []*ec2.Filter, error) {
+	vpcs, filters, _, err := findEc2SelectedVpcs(d, meta)
+	return len(vpcs), filters, err
+}
+
+// countEc2SelectedSecurityGroups is the ec2MatchCountFunc of the resources
+// selecting Security Groups with findEc2SelectedSecurityGroups.
+func countEc2SelectedSecurityGroups(d resourceDataGetter, meta interface{}) (int, []*ec2.Filter, error) {
+	groups, filters, _, err := findEc2SelectedSecurityGroups(d, meta)
+	return len(groups), filters, err
+}
+
+// countEc2SelectedInstances returns the ec2MatchCountFunc of the resources
+// selecting the instances in the given states with findEc2SelectedInstances.
+func countEc2SelectedInstances(states ...string) ec2MatchCountFunc {
+	return func(d resourceDataGetter, meta interface{}) (int, []*ec2.Filter, error) {
+		instances, filters, _, err := findEc2SelectedInstances(d, meta, states...)
+		return len(instances), filters, err
+	}
+}
@@ -0,0 +1,796 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestBuildEC2Selection(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		Raw             map[string]interface{}
+		ExpectedIDs     []*string
+		ExpectedFilters []*ec2.Filter
+		ExpectedError   bool
+	}{
+		{
+			Name: "nothing",
+			Raw:  map[string]interface{}{},
+		},
+		{
+			Name: "name",
+			Raw: map[string]interface{}{
+				"name": "my-awesome-vpc",
+			},
+			ExpectedFilters: []*ec2.Filter{
+				{
+					Name:   aws.String("tag:Name"),
+					Values: aws.StringSlice([]string{"my-awesome-vpc"}),
+				},
+			},
+		},
+		{
+			Name: "name with wildcards",
+			Raw: map[string]interface{}{
+				"name": "my-*-vpc-?",
+			},
+			ExpectedFilters: []*ec2.Filter{
+				{
+					Name:   aws.String("tag:Name"),
+					Values: aws.StringSlice([]string{"my-*-vpc-?"}),
+				},
+			},
+		},
+		{
+			Name: "name merged with tags",
+			Raw: map[string]interface{}{
+				"name": "my-awesome-vpc",
+				"tags": map[string]interface{}{
+					"Environment": "prod",
+				},
+			},
+			ExpectedFilters: []*ec2.Filter{
+				{
+					Name:   aws.String("tag:Environment"),
+					Values: aws.StringSlice([]string{"prod"}),
+				},
+				{
+					Name:   aws.String("tag:Name"),
+					Values: aws.StringSlice([]string{"my-awesome-vpc"}),
+				},
+			},
+		},
+		{
+			Name: "name same as tags.Name",
+			Raw: map[string]interface{}{
+				"name": "my-awesome-vpc",
+				"tags": map[string]interface{}{
+					"Name": "my-awesome-vpc",
+				},
+			},
+			ExpectedFilters: []*ec2.Filter{
+				{
+					Name:   aws.String("tag:Name"),
+					Values: aws.StringSlice([]string{"my-awesome-vpc"}),
+				},
+			},
+		},
+		{
+			Name: "name conflicting with tags.Name",
+			Raw: map[string]interface{}{
+				"name": "my-awesome-vpc",
+				"tags": map[string]interface{}{
+					"Name": "my-other-vpc",
+				},
+			},
+			ExpectedError: true,
+		},
+		{
+			Name: "any tag keys",
+			Raw: map[string]interface{}{
+				"any_tag_keys": []interface{}{"CostCenter", "Project"},
+			},
+			ExpectedFilters: []*ec2.Filter{
+				{
+					Name:   aws.String("tag-key"),
+					Values: aws.StringSlice([]string{"CostCenter", "Project"}),
+				},
+			},
+		},
+		{
+			Name: "has tags",
+			Raw: map[string]interface{}{
+				"has_tags":     []interface{}{"Team", "", "CostCenter"},
+				"any_tag_keys": []interface{}{"CostCenter"},
+			},
+			ExpectedFilters: []*ec2.Filter{
+				{
+					Name:   aws.String("tag-key"),
+					Values: aws.StringSlice([]string{"CostCenter", "Team"}),
+				},
+			},
+		},
+		{
+			Name: "required tag keys",
+			Raw: map[string]interface{}{
+				"required_tag_keys": []interface{}{"CostCenter", "Project"},
+			},
+			ExpectedFilters: []*ec2.Filter{
+				{
+					Name:   aws.String("tag-key"),
+					Values: aws.StringSlice([]string{"CostCenter"}),
+				},
+				{
+					Name:   aws.String("tag-key"),
+					Values: aws.StringSlice([]string{"Project"}),
+				},
+			},
+		},
+		{
+			Name: "all",
+			Raw: map[string]interface{}{
+				"ids":  []interface{}{"vpc-01234567"},
+				"name": "my-awesome-vpc",
+				"filter": []interface{}{
+					map[string]interface{}{
+						"name":   "cidr",
+						"values": []interface{}{"10.0.0.0/16"},
+					},
+				},
+			},
+			ExpectedIDs: aws.StringSlice([]string{"vpc-01234567"}),
+			ExpectedFilters: []*ec2.Filter{
+				{
+					Name:   aws.String("cidr"),
+					Values: aws.StringSlice([]string{"10.0.0.0/16"}),
+				},
+				{
+					Name:   aws.String("tag:Name"),
+					Values: aws.StringSlice([]string{"my-awesome-vpc"}),
+				},
+			},
+		},
+		{
+			Name: "tag filter overlapping tags",
+			Raw: map[string]interface{}{
+				"tags": map[string]interface{}{
+					"Environment": "prod",
+				},
+				"filter": []interface{}{
+					map[string]interface{}{
+						"name":   "tag:Environment",
+						"values": []interface{}{"staging", "prod"},
+					},
+				},
+			},
+			ExpectedFilters: []*ec2.Filter{
+				{
+					Name:   aws.String("tag:Environment"),
+					Values: aws.StringSlice([]string{"prod", "staging"}),
+				},
+			},
+		},
+	}
+
+	s := map[string]*schema.Schema{
+		"ids":               ec2IDsSchema(ec2.ResourceTypeVpc),
+		"name":              ec2NameSchema(),
+		"filter":            ec2CustomFiltersSchema(),
+		"tags":              tagsSchema(),
+		"any_tag_keys":      ec2AnyTagKeysSchema(),
+		"has_tags":          ec2HasTagsSchema(),
+		"required_tag_keys": ec2RequiredTagKeysSchema(),
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, testCase.Raw)
+
+			ids, filters, _, err := buildEC2Selection(d, &AWSClient{}, ec2.ResourceTypeVpc)
+
+			if testCase.ExpectedError {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(ids, testCase.ExpectedIDs) {
+				t.Errorf("got IDs %s, expected %s", aws.StringValueSlice(ids), aws.StringValueSlice(testCase.ExpectedIDs))
+			}
+
+			if !reflect.DeepEqual(filters, testCase.ExpectedFilters) {
+				t.Errorf("got filters %s, expected %s", filters, testCase.ExpectedFilters)
+			}
+		})
+	}
+}
+
+func TestBuildEC2SelectionPartialSchema(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"name": ec2NameSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"name": "my-awesome-vpc",
+	})
+
+	ids, filters, _, err := buildEC2Selection(d, &AWSClient{}, ec2.ResourceTypeVpc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if ids != nil {
+		t.Errorf("got IDs %s, expected none", aws.StringValueSlice(ids))
+	}
+
+	expected := []*ec2.Filter{
+		{
+			Name:   aws.String("tag:Name"),
+			Values: aws.StringSlice([]string{"my-awesome-vpc"}),
+		},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("got filters %s, expected %s", filters, expected)
+	}
+}
+
+func TestBuildEC2SelectionIDsStrict(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"ids":        ec2IDsSchema(ec2.ResourceTypeSubnet),
+		"ids_strict": ec2IDsStrictSchema(),
+	}
+
+	testCases := []struct {
+		Name            string
+		Raw             map[string]interface{}
+		ExpectedIDs     []*string
+		ExpectedFilters []*ec2.Filter
+	}{
+		{
+			Name:        "strict by default",
+			Raw:         map[string]interface{}{"ids": []interface{}{"subnet-01234567"}},
+			ExpectedIDs: aws.StringSlice([]string{"subnet-01234567"}),
+		},
+		{
+			Name: "lenient",
+			Raw:  map[string]interface{}{"ids": []interface{}{"subnet-01234567"}, "ids_strict": false},
+			ExpectedFilters: []*ec2.Filter{
+				{
+					Name:   aws.String("subnet-id"),
+					Values: aws.StringSlice([]string{"subnet-01234567"}),
+				},
+			},
+		},
+		{
+			Name: "lenient without IDs",
+			Raw:  map[string]interface{}{"ids_strict": false},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, testCase.Raw)
+
+			ids, filters, _, err := buildEC2Selection(d, &AWSClient{}, ec2.ResourceTypeSubnet)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(ids, testCase.ExpectedIDs) {
+				t.Errorf("got IDs %s, expected %s", aws.StringValueSlice(ids), aws.StringValueSlice(testCase.ExpectedIDs))
+			}
+
+			if !reflect.DeepEqual(filters, testCase.ExpectedFilters) {
+				t.Errorf("got filters %s, expected %s", filters, testCase.ExpectedFilters)
+			}
+		})
+	}
+}
+
+func TestBuildEC2SelectionCloudFormationStackName(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"tags":                      tagsSchema(),
+		"filter":                    ec2CustomFiltersSchema(),
+		"cloudformation_stack_name": ec2CloudFormationStackNameSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"tags": map[string]interface{}{
+			"Team":                          "platform",
+			"aws:cloudformation:stack-name": "ignored-stack",
+		},
+		"filter": []interface{}{
+			map[string]interface{}{
+				"name":   "tag:aws:cloudformation:stack-name",
+				"values": []interface{}{"other-stack"},
+			},
+		},
+		"cloudformation_stack_name": "my-stack",
+	})
+
+	_, filters, _, err := buildEC2Selection(d, &AWSClient{}, ec2.ResourceTypeInstance)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []*ec2.Filter{
+		{
+			Name:   aws.String("tag:Team"),
+			Values: aws.StringSlice([]string{"platform"}),
+		},
+		{
+			Name:   aws.String("tag:aws:cloudformation:stack-name"),
+			Values: aws.StringSlice([]string{"my-stack", "other-stack"}),
+		},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("got filters %s, expected %s", filters, expected)
+	}
+}
+
+func TestBuildEC2SelectionIgnoreTags(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"tags": tagsSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"tags": map[string]interface{}{
+			"Environment":                     "prod",
+			"kubernetes.io/cluster/my-eks":    "owned",
+			"kubernetes.io/role/internal-elb": "1",
+			"LastScanned":                     "2021-06-01",
+		},
+	})
+
+	meta := &AWSClient{
+		IgnoreTagsConfig: &keyvaluetags.IgnoreConfig{
+			Keys:        keyvaluetags.New([]string{"LastScanned"}),
+			KeyPrefixes: keyvaluetags.New([]string{"kubernetes.io/"}),
+		},
+	}
+
+	_, filters, _, err := buildEC2Selection(d, meta, ec2.ResourceTypeSubnet)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []*ec2.Filter{
+		{
+			Name:   aws.String("tag:Environment"),
+			Values: aws.StringSlice([]string{"prod"}),
+		},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("got filters %s, expected %s", filters, expected)
+	}
+}
+
+func TestBuildEC2SelectionEscapingWildcards(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"name":   ec2NameSchema(),
+		"filter": ec2CustomFiltersSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"name": "my-*-vpc",
+		"filter": []interface{}{
+			map[string]interface{}{
+				"name":   "tag:Environment",
+				"values": []interface{}{"prod?"},
+			},
+			map[string]interface{}{
+				"name":     "tag:Team",
+				"values":   []interface{}{"platform-*"},
+				"wildcard": true,
+			},
+		},
+	})
+
+	_, filters, _, err := buildEC2Selection(d, &AWSClient{escapeFilterWildcards: true}, ec2.ResourceTypeVpc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got := make(map[string][]string, len(filters))
+	for _, filter := range filters {
+		got[aws.StringValue(filter.Name)] = aws.StringValueSlice(filter.Values)
+	}
+
+	expected := map[string][]string{
+		"tag:Name":        {`my-\*-vpc`},
+		"tag:Environment": {`prod\?`},
+		"tag:Team":        {"platform-*"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestBuildEC2SelectionDisabledFilters(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"filter": ec2CustomFiltersSchema(),
+	}
+
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{
+				"name":    "tag:Environment",
+				"values":  []interface{}{},
+				"enabled": false,
+			},
+			map[string]interface{}{
+				"name":    "tag:Team",
+				"values":  []interface{}{"platform-*"},
+				"enabled": false,
+			},
+			map[string]interface{}{
+				"name":   "tag:Owner",
+				"values": []interface{}{"ops?"},
+			},
+			map[string]interface{}{
+				"name":     "tag:Name",
+				"values":   []interface{}{"web-*"},
+				"wildcard": true,
+			},
+		},
+	})
+
+	for _, escapeWildcards := range []bool{false, true} {
+		_, filters, _, err := buildEC2Selection(d, &AWSClient{escapeFilterWildcards: escapeWildcards}, ec2.ResourceTypeVpc)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		got := make(map[string][]string, len(filters))
+		for _, filter := range filters {
+			got[aws.StringValue(filter.Name)] = aws.StringValueSlice(filter.Values)
+		}
+
+		expected := map[string][]string{
+			"tag:Owner": {"ops?"},
+			"tag:Name":  {"web-*"},
+		}
+		if escapeWildcards {
+			expected["tag:Owner"] = []string{`ops\?`}
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("got %v, expected %v with escapeWildcards %t", got, expected, escapeWildcards)
+		}
+	}
+
+	d = schema.TestResourceDataRaw(t, s, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{
+				"name":   "tag:Environment",
+				"values": []interface{}{},
+			},
+		},
+	})
+
+	if _, _, _, err := buildEC2Selection(d, &AWSClient{}, ec2.ResourceTypeVpc); err == nil {
+		t.Error("expected an error for an enabled filter without values")
+	}
+}
+
+func TestBuildEC2SelectionARNs(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Region        string
+		ARNs          []interface{}
+		ExpectedIDs   []*string
+		ExpectedError bool
+	}{
+		{
+			Name:        "valid",
+			Region:      "us-east-1",
+			ARNs:        []interface{}{"arn:aws:ec2:us-east-1:123456789012:vpc/vpc-89abcdef"},
+			ExpectedIDs: aws.StringSlice([]string{"vpc-01234567", "vpc-89abcdef"}),
+		},
+		{
+			Name:        "duplicate of an ID",
+			Region:      "us-east-1",
+			ARNs:        []interface{}{"arn:aws:ec2:us-east-1:123456789012:vpc/vpc-01234567"},
+			ExpectedIDs: aws.StringSlice([]string{"vpc-01234567"}),
+		},
+		{
+			Name:   "other region",
+			Region: "us-east-1",
+			ARNs: []interface{}{
+				"arn:aws:ec2:us-east-1:123456789012:vpc/vpc-89abcdef",
+				"arn:aws:ec2:eu-west-1:123456789012:vpc/vpc-76543210",
+			},
+			ExpectedError: true,
+		},
+		{
+			Name:   "mixed valid and invalid",
+			Region: "us-east-1",
+			ARNs: []interface{}{
+				"arn:aws:ec2:us-east-1:123456789012:vpc/vpc-89abcdef",
+				"arn:aws:ec2:us-east-1:123456789012:subnet/subnet-89abcdef",
+			},
+			ExpectedError: true,
+		},
+	}
+
+	s := map[string]*schema.Schema{
+		"ids":  ec2IDsSchema(ec2.ResourceTypeVpc),
+		"arns": ec2ARNsSchema(ec2.ResourceTypeVpc),
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+				"ids":  []interface{}{"vpc-01234567"},
+				"arns": testCase.ARNs,
+			})
+
+			ids, _, _, err := buildEC2Selection(d, &AWSClient{region: testCase.Region}, ec2.ResourceTypeVpc)
+
+			if testCase.ExpectedError {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got := aws.StringValueSlice(ids)
+			sort.Strings(got)
+			if expected := aws.StringValueSlice(testCase.ExpectedIDs); !reflect.DeepEqual(got, expected) {
+				t.Errorf("got IDs %s, expected %s", got, expected)
+			}
+		})
+	}
+}
+
+func TestEc2ARNsSchemaValidation(t *testing.T) {
+	validate := ec2ARNsSchema(ec2.ResourceTypeInstance).Elem.(*schema.Schema).ValidateFunc
+
+	for _, testCase := range []struct {
+		ARN   string
+		Valid bool
+	}{
+		{"arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0", true},
+		{"i-0123456789abcdef0", false},
+		{"arn:aws:ec2:us-east-1:123456789012:volume/vol-0123456789abcdef0", false},
+		{"arn:aws:ec2:us-east-1:123456789012:instance/i-xyz", false},
+	} {
+		_, errs := validate(testCase.ARN, "arns.0")
+		if got := len(errs) == 0; got != testCase.Valid {
+			t.Errorf("got valid %t for %s, expected %t: %v", got, testCase.ARN, testCase.Valid, errs)
+		}
+	}
+}
+
+func TestMaxResultsCap(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Raw      map[string]interface{}
+		Expected int
+	}{
+		{
+			Name:     "provider default",
+			Raw:      map[string]interface{}{},
+			Expected: defaultMaxResultsCap,
+		},
+		{
+			Name: "override",
+			Raw: map[string]interface{}{
+				"max_results_cap": 50,
+			},
+			Expected: 50,
+		},
+	}
+
+	s := map[string]*schema.Schema{
+		"max_results_cap": maxResultsCapSchema(),
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, testCase.Raw)
+
+			if got := maxResultsCap(d, &AWSClient{maxResultsCap: defaultMaxResultsCap}); got != testCase.Expected {
+				t.Errorf("got %d, expected %d", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestMaxResultsCapError(t *testing.T) {
+	err := maxResultsCapError(fmt.Errorf("wrapped: %w", &finder.MaxResultsExceededError{MaxResults: 10}))
+
+	var exceeded *finder.MaxResultsExceededError
+	if !errors.As(err, &exceeded) || exceeded.MaxResults != 10 {
+		t.Errorf("expected a wrapped *finder.MaxResultsExceededError, got %s", err)
+	}
+
+	if expected := "wrapped: more than 10 results matched; narrow the selection or raise max_results_cap"; err.Error() != expected {
+		t.Errorf("got %s, expected %s", err, expected)
+	}
+
+	other := errors.New("other")
+	if got := maxResultsCapError(other); got != other {
+		t.Errorf("got %s, expected %s", got, other)
+	}
+}
+
+func TestRequireNonEmptyFilters(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Filters       []*ec2.Filter
+		ExpectedError bool
+	}{
+		{
+			Name:          "no filters",
+			ExpectedError: true,
+		},
+		{
+			Name:          "nil filters",
+			Filters:       []*ec2.Filter{nil},
+			ExpectedError: true,
+		},
+		{
+			Name:    "filters",
+			Filters: buildEC2AttributeFilterList(map[string]string{"vpc-id": "vpc-01234567"}),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := requireNonEmptyFilters(testCase.Filters)
+
+			if testCase.ExpectedError && !errors.Is(err, errEC2Unfiltered) {
+				t.Errorf("expected errEC2Unfiltered, got %v", err)
+			}
+			if !testCase.ExpectedError && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestCheckEC2Unfiltered(t *testing.T) {
+	filters := buildEC2AttributeFilterList(map[string]string{"vpc-id": "vpc-01234567"})
+
+	testCases := []struct {
+		Name            string
+		Raw             map[string]interface{}
+		IDs             []*string
+		Filters         []*ec2.Filter
+		ExpectedError   bool
+		ExpectedWarning bool
+	}{
+		{
+			Name:          "empty",
+			ExpectedError: true,
+		},
+		{
+			Name:            "empty allowing unfiltered",
+			Raw:             map[string]interface{}{"allow_unfiltered": true},
+			ExpectedWarning: true,
+		},
+		{
+			Name:    "filters allowing unfiltered",
+			Raw:     map[string]interface{}{"allow_unfiltered": true},
+			Filters: filters,
+		},
+		{
+			Name:    "filters",
+			Filters: filters,
+		},
+		{
+			Name: "IDs",
+			IDs:  aws.StringSlice([]string{"subnet-01234567"}),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{"allow_unfiltered": ec2AllowUnfilteredSchema()}, testCase.Raw)
+
+			warnings, err := checkEC2Unfiltered(d, testCase.IDs, testCase.Filters)
+
+			if testCase.ExpectedError && !errors.Is(err, errEC2Unfiltered) {
+				t.Errorf("expected errEC2Unfiltered, got %v", err)
+			}
+			if !testCase.ExpectedError && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+
+			if testCase.ExpectedWarning {
+				if len(warnings) != 1 || warnings[0].Severity != diag.Warning || !strings.Contains(warnings[0].Summary, "every object of the region") {
+					t.Errorf("got %v, expected a warning that every object of the region is read", warnings)
+				}
+			} else if len(warnings) != 0 {
+				t.Errorf("unexpected warnings: %v", warnings)
+			}
+		})
+	}
+}
+
+func TestDataSourcesCheckEC2Unfiltered(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Resource *schema.Resource
+		Raw      map[string]interface{}
+		// RequiresMatch is set for the data sources failing when nothing matches, as nothing does in an empty region.
+		RequiresMatch bool
+	}{
+		{Name: "amis_by_deprecation_status", Resource: dataSourceAwsUtilsEc2AmisByDeprecationStatus()},
+		{Name: "amis_by_tag_with_latest", Resource: dataSourceAwsUtilsEc2AmisByTagWithLatest(), RequiresMatch: true},
+		{Name: "amis_missing_required_tags", Resource: dataSourceAwsUtilsEc2AmisMissingRequiredTags(), Raw: map[string]interface{}{"required_keys": []interface{}{"Team"}}},
+		{Name: "amis_unused", Resource: dataSourceAwsUtilsEc2AmisUnused()},
+		{Name: "nat_gateway_consolidator_report", Resource: dataSourceAwsUtilsEc2NatGatewayConsolidatorReport(), RequiresMatch: true},
+		{Name: "orphaned_resources", Resource: dataSourceAwsUtilsEc2OrphanedResources()},
+		{Name: "route_to_internet_checker", Resource: dataSourceAwsUtilsEc2RouteToInternetChecker()},
+		{Name: "tagged_resources", Resource: dataSourceAwsUtilsEc2TaggedResources(), Raw: map[string]interface{}{"resource_types": []interface{}{"instance"}}},
+		{Name: "unattached_volumes_cost_estimate", Resource: dataSourceAwsUtilsEc2UnattachedVolumesCostEstimate()},
+		{Name: "vpc_summary", Resource: dataSourceAwsUtilsEc2VpcSummary(), RequiresMatch: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var operations []string
+			conn := testEc2Conn(t, func(r *request.Request) {
+				operations = append(operations, r.Operation.Name)
+			})
+			client := &AWSClient{ec2conn: conn, region: "us-east-1", accountid: "123456789012", maxResultsCap: defaultMaxResultsCap}
+
+			d := schema.TestResourceDataRaw(t, testCase.Resource.Schema, testCase.Raw)
+			diags := testCase.Resource.ReadContext(context.Background(), d, client)
+			if !diags.HasError() || diags[0].Summary != errEC2Unfiltered.Error() {
+				t.Fatalf("got %v, expected %q", diags, errEC2Unfiltered)
+			}
+
+			if len(operations) != 0 {
+				t.Errorf("got requests %v, expected none", operations)
+			}
+
+			if testCase.RequiresMatch {
+				return
+			}
+
+			// With allow_unfiltered, every object of the region is read, with a warning.
+			raw := map[string]interface{}{"allow_unfiltered": true}
+			for k, v := range testCase.Raw {
+				raw[k] = v
+			}
+
+			d = schema.TestResourceDataRaw(t, testCase.Resource.Schema, raw)
+			diags = testCase.Resource.ReadContext(context.Background(), d, client)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			warned := false
+			for _, diagnostic := range diags {
+				warned = warned || diagnostic.Severity == diag.Warning && strings.Contains(diagnostic.Summary, "every object of the region")
+			}
+			if !warned {
+				t.Errorf("got %v, expected a warning that every object of the region is read", diags)
+			}
+		})
+	}
+}
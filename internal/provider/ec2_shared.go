@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"log"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ec2IncludeSharedSchema returns a *schema.Schema for the "include_shared"
+// attribute of the data sources reading EC2 objects which can be shared with
+// the account of the provider by Resource Access Manager (RAM), such as
+// Subnets, read by buildEC2SharedExclusionFilterList.
+func ec2IncludeSharedSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Whether the objects shared with the account of the provider by other accounts through Resource Access Manager (RAM) should be matched too. By default, only the objects owned by the account of the provider are matched.",
+	}
+}
+
+// buildEC2SharedExclusionFilterList returns the "owner-id" filter matching
+// only the objects owned by the given account ID, the account of the
+// provider, leaving out those shared with it by other accounts through RAM,
+// which the "Describe..." API calls otherwise return along with the owned
+// ones. No filter is returned if includeShared is set.
+//
+// No filter is returned either if the account ID is unknown, as when the
+// provider skips requesting it, in which case the shared objects cannot be
+// told apart and are matched too.
+func buildEC2SharedExclusionFilterList(includeShared bool, accountID string) []*ec2.Filter {
+	if includeShared {
+		return nil
+	}
+
+	if accountID == "" {
+		log.Printf("[WARN] The AWS account ID of the provider is unknown, so the EC2 objects shared with it are not left out")
+		return nil
+	}
+
+	filters, _ := buildEC2OwnerIDFilterList([]string{ec2OwnerSelf}, accountID)
+
+	return filters
+}
+
+// ec2SharedByOtherAccount returns whether an EC2 object owned by the given
+// account ID is shared with the given account of the provider, rather than
+// owned by it. It is false if either account ID is unknown.
+func ec2SharedByOtherAccount(ownerID, accountID string) bool {
+	return ownerID != "" && accountID != "" && ownerID != accountID
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// fingerprintSchema returns a *schema.Schema for the computed "fingerprint"
+// attribute of the items of list data sources, a hash of the given attributes
+// of the item as set by setFingerprints. The attributes are listed in the
+// description, so that the hashed subset is documented for each data source.
+func fingerprintSchema(attributes []string) *schema.Schema {
+	quoted := make([]string, 0, len(attributes))
+	for _, attribute := range attributes {
+		quoted = append(quoted, "`"+attribute+"`")
+	}
+
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: fmt.Sprintf("A SHA-256 hash of the %s attributes of the item, which only changes when one of them does, to detect changes without comparing every attribute.", strings.Join(quoted, ", ")),
+	}
+}
+
+// setFingerprints sets the "fingerprint" of each of the given flattened items
+// to the hex-encoded SHA-256 hash of the JSON encoding of its given
+// attributes. The keys of maps are encoded in order, so the fingerprint is
+// stable across reads as long as the attributes do not change, provided that
+// their lists are flattened in a deterministic order.
+func setFingerprints(items []map[string]interface{}, attributes []string) error {
+	for _, item := range items {
+		hashed := make(map[string]interface{}, len(attributes))
+		for _, attribute := range attributes {
+			hashed[attribute] = item[attribute]
+		}
+
+		b, err := json.Marshal(hashed)
+		if err != nil {
+			return fmt.Errorf("error computing fingerprint: %w", err)
+		}
+
+		sum := sha256.Sum256(b)
+		item["fingerprint"] = hex.EncodeToString(sum[:])
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetFingerprints(t *testing.T) {
+	attributes := []string{"volume_id", "size", "tags"}
+
+	item := func(size int, cost float64) map[string]interface{} {
+		return map[string]interface{}{
+			"volume_id":    "vol-01234567",
+			"size":         size,
+			"tags":         map[string]string{"Team": "platform", "Environment": "prod"},
+			"monthly_cost": cost,
+		}
+	}
+
+	items := []map[string]interface{}{item(100, 8), item(100, 8), item(100, 10), item(200, 8)}
+	if err := setFingerprints(items, attributes); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fingerprints := make([]string, 0, len(items))
+	for _, item := range items {
+		fingerprints = append(fingerprints, item["fingerprint"].(string))
+	}
+
+	if len(fingerprints[0]) != 64 || strings.Trim(fingerprints[0], "0123456789abcdef") != "" {
+		t.Errorf("got fingerprint %q, expected a hex-encoded SHA-256 hash", fingerprints[0])
+	}
+
+	if fingerprints[0] != fingerprints[1] {
+		t.Errorf("got fingerprints %q and %q for identical items, expected equal", fingerprints[0], fingerprints[1])
+	}
+
+	if fingerprints[0] != fingerprints[2] {
+		t.Errorf("got fingerprints %q and %q for items differing in an unhashed attribute, expected equal", fingerprints[0], fingerprints[2])
+	}
+
+	if fingerprints[0] == fingerprints[3] {
+		t.Errorf("got fingerprint %q for items differing in a hashed attribute, expected different", fingerprints[0])
+	}
+
+	// The fingerprint of an item is the same on every read.
+	again := []map[string]interface{}{item(100, 8)}
+	if err := setFingerprints(again, attributes); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := again[0]["fingerprint"]; got != fingerprints[0] {
+		t.Errorf("got fingerprint %q, expected %q", got, fingerprints[0])
+	}
+}
@@ -0,0 +1,387 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	plannedChangeActionCreate = "create"
+	plannedChangeActionUpdate = "update"
+	plannedChangeActionDelete = "delete"
+	// plannedChangeActionNone marks a selected resource which is already compliant and requires no change.
+	plannedChangeActionNone = "none"
+)
+
+const (
+	// plannedChangeStatusPlanned marks a change which would have been made if dry_run was not set.
+	plannedChangeStatusPlanned = "planned"
+	// plannedChangeStatusApplied marks a change which was made.
+	plannedChangeStatusApplied = "applied"
+	// plannedChangeStatusSkipped marks a selected resource which was left untouched.
+	plannedChangeStatusSkipped = "skipped"
+	// plannedChangeStatusFailed marks a change which could not be made when continue_on_error is set.
+	plannedChangeStatusFailed = "failed"
+)
+
+// plannedChange describes the change a mutating resource makes, or would make in dry-run mode, to a single
+// AWS resource.
+type plannedChange struct {
+	ResourceID string
+	Action     string
+	Reason     string
+	Before     map[string]string
+	After      map[string]string
+	Status     string
+	Error      string
+}
+
+// plannedChangesSchema returns the schema of the computed planned_changes attribute shared by the mutating
+// resources.
+func plannedChangesSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "The changes made, or planned when `dry_run` is set, to each of the selected resources.",
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"resource_id": {
+					Description: "The ID of the AWS resource.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"action": {
+					Description: "The action taken on the resource, one of `create`, `update`, `delete` or `none`.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"reason": {
+					Description: "Why the action is taken.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"before": {
+					Description: "The relevant attributes of the resource before the change.",
+					Type:        schema.TypeMap,
+					Computed:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+				"after": {
+					Description: "The relevant attributes of the resource after the change.",
+					Type:        schema.TypeMap,
+					Computed:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+				"status": {
+					Description: "Whether the change was `planned` (dry run), `applied`, `skipped` or `failed`.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+			},
+		},
+	}
+}
+
+// applyPlannedChanges calls apply for each of the given changes requiring an action, unless dryRun is set,
+// and records the status of every change. Changes with the none action are always skipped.
+//
+// By default, processing stops at the first error returned by apply, leaving the remaining changes without
+// a status, and that error is returned. When continueOnError is set, the change is instead marked as failed
+// with the error recorded and processing continues, so the successful changes are still made.
+func applyPlannedChanges(changes []*plannedChange, dryRun bool, continueOnError bool, apply func(*plannedChange) error) error {
+	for _, change := range changes {
+		switch {
+		case change.Action == plannedChangeActionNone:
+			change.Status = plannedChangeStatusSkipped
+		case dryRun:
+			change.Status = plannedChangeStatusPlanned
+		default:
+			if err := apply(change); err != nil {
+				if !continueOnError {
+					return err
+				}
+				change.Status = plannedChangeStatusFailed
+				change.Error = err.Error()
+				continue
+			}
+			change.Status = plannedChangeStatusApplied
+		}
+	}
+
+	return nil
+}
+
+// applyPlannedChangesInBatches is like applyPlannedChanges, but calls apply with batches of up to batchSize
+// changes, for AWS APIs acting on several resources at once. The changes with the same batchKey are batched
+// together, in the order of their first occurrence. When a batch fails, all of its changes are failed.
+func applyPlannedChangesInBatches(changes []*plannedChange, dryRun bool, continueOnError bool, batchSize int, batchKey func(*plannedChange) string, apply func([]*plannedChange) error) error {
+	var keys []string
+	batches := make(map[string][]*plannedChange)
+
+	for _, change := range changes {
+		switch {
+		case change.Action == plannedChangeActionNone:
+			change.Status = plannedChangeStatusSkipped
+		case dryRun:
+			change.Status = plannedChangeStatusPlanned
+		default:
+			key := batchKey(change)
+			if _, ok := batches[key]; !ok {
+				keys = append(keys, key)
+			}
+			batches[key] = append(batches[key], change)
+		}
+	}
+
+	for _, key := range keys {
+		pending := batches[key]
+
+		for i := 0; i < len(pending); i += batchSize {
+			j := i + batchSize
+			if j > len(pending) {
+				j = len(pending)
+			}
+			batch := pending[i:j]
+
+			status, message := plannedChangeStatusApplied, ""
+			if err := apply(batch); err != nil {
+				if !continueOnError {
+					return err
+				}
+				status, message = plannedChangeStatusFailed, err.Error()
+			}
+
+			for _, change := range batch {
+				change.Status = status
+				change.Error = message
+			}
+		}
+	}
+
+	return nil
+}
+
+// flattenPlannedChanges flattens the given changes into the planned_changes attribute.
+func flattenPlannedChanges(changes []*plannedChange) []interface{} {
+	result := make([]interface{}, 0, len(changes))
+
+	for _, change := range changes {
+		result = append(result, map[string]interface{}{
+			"resource_id": change.ResourceID,
+			"action":      change.Action,
+			"reason":      change.Reason,
+			"before":      change.Before,
+			"after":       change.After,
+			"status":      change.Status,
+		})
+	}
+
+	return result
+}
+
+// continueOnErrorSchema returns the schema of the continue_on_error attribute shared by the mutating
+// resources, which is passed on to applyPlannedChanges.
+func continueOnErrorSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "Keep processing the remaining resources when a change fails, reporting the failures in `failed` and failing the apply only once every resource was processed.",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	}
+}
+
+// failedChangesSchema returns the schema of the computed failed attribute listing the changes which could not
+// be made when continue_on_error is set.
+func failedChangesSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "The changes which failed when `continue_on_error` is set.",
+		Type:        schema.TypeList,
+		Computed:    true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"resource_id": {
+					Description: "The ID of the AWS resource.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"action": {
+					Description: "The action which failed.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+				"error": {
+					Description: "The error returned by AWS.",
+					Type:        schema.TypeString,
+					Computed:    true,
+				},
+			},
+		},
+	}
+}
+
+// flattenFailedChanges flattens the failed changes among the given ones into the failed attribute.
+func flattenFailedChanges(changes []*plannedChange) []interface{} {
+	result := make([]interface{}, 0)
+
+	for _, change := range changes {
+		if change.Status != plannedChangeStatusFailed {
+			continue
+		}
+
+		result = append(result, map[string]interface{}{
+			"resource_id": change.ResourceID,
+			"action":      change.Action,
+			"error":       change.Error,
+		})
+	}
+
+	return result
+}
+
+// setPlannedChanges sets the planned_changes and failed attributes of the given *schema.ResourceData from the
+// given changes.
+func setPlannedChanges(d *schema.ResourceData, changes []*plannedChange) error {
+	if err := d.Set("planned_changes", flattenPlannedChanges(changes)); err != nil {
+		return fmt.Errorf("error setting planned_changes: %w", err)
+	}
+
+	if err := d.Set("failed", flattenFailedChanges(changes)); err != nil {
+		return fmt.Errorf("error setting failed: %w", err)
+	}
+
+	return nil
+}
+
+// failedChangesDiagnostics returns an error summarizing the failed changes recorded in the given
+// *schema.ResourceData, if any, so that the apply fails once the remaining changes were made.
+//
+// It is returned after the ID and the planned_changes and failed attributes are set, so that the state is still
+// saved, the resource being marked as tainted on create, and the changes that succeeded are not rolled back.
+func failedChangesDiagnostics(d *schema.ResourceData) diag.Diagnostics {
+	failed := d.Get("failed").([]interface{})
+	if len(failed) == 0 {
+		return nil
+	}
+
+	details := make([]string, 0, len(failed))
+	for _, v := range failed {
+		m := v.(map[string]interface{})
+		details = append(details, fmt.Sprintf("%s (%s): %s", m["resource_id"], m["action"], m["error"]))
+	}
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("%d change(s) failed", len(failed)),
+			Detail:   strings.Join(details, "\n"),
+		},
+	}
+}
+
+// requireConfirmationTokenSchema returns the schema of the require_confirmation_token attribute of the destructive
+// resources, gating their changes behind confirmation_token as checked by checkConfirmationToken.
+func requireConfirmationTokenSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "Whether the changes are only made once `confirmation_token` is set to `expected_confirmation_token`, the token of the changes to make, so that they are reviewed first. Until then, the changes are reported in `planned_changes` but not made, as when `dry_run` is set.",
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+	}
+}
+
+// confirmationTokenSchema returns the schema of the confirmation_token attribute confirming the changes of the
+// destructive resources setting require_confirmation_token.
+func confirmationTokenSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "The `expected_confirmation_token` of the changes reviewed in `planned_changes`, confirming them when `require_confirmation_token` is set. The changes are not made if they differ from those of the token.",
+		Type:        schema.TypeString,
+		Optional:    true,
+	}
+}
+
+// expectedConfirmationTokenSchema returns the schema of the computed expected_confirmation_token attribute of the
+// destructive resources setting require_confirmation_token.
+func expectedConfirmationTokenSchema() *schema.Schema {
+	return &schema.Schema{
+		Description: "The token to set `confirmation_token` to in order to confirm the changes of `planned_changes`. It changes whenever the changes do, such as when the selection matches other resources.",
+		Type:        schema.TypeString,
+		Computed:    true,
+	}
+}
+
+// plannedChangesConfirmationToken returns the hex-encoded SHA-256 hash of the JSON encoding of the IDs, actions and
+// after states of the given changes requiring an action, in the given order, so that it changes whenever the
+// resources to change or their changes do, but not when only those left as they are do.
+func plannedChangesConfirmationToken(changes []*plannedChange) (string, error) {
+	hashed := make([]map[string]interface{}, 0, len(changes))
+	for _, change := range changes {
+		if change.Action == plannedChangeActionNone {
+			continue
+		}
+
+		hashed = append(hashed, map[string]interface{}{
+			"resource_id": change.ResourceID,
+			"action":      change.Action,
+			"after":       change.After,
+		})
+	}
+
+	b, err := json.Marshal(hashed)
+	if err != nil {
+		return "", fmt.Errorf("error computing confirmation token: %w", err)
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// checkConfirmationToken sets the expected_confirmation_token attribute of the given *schema.ResourceData to the
+// token of the given changes, and returns whether they may be made: when require_confirmation_token is set, only if
+// confirmation_token is set to that token, or if none of the changes requires an action. Otherwise, a warning telling
+// the token to set is returned too.
+//
+// A warning is used rather than an error so that the expected token is recorded in the state even on create.
+func checkConfirmationToken(d *schema.ResourceData, changes []*plannedChange) (bool, diag.Diagnostics, error) {
+	token, err := plannedChangesConfirmationToken(changes)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if err := d.Set("expected_confirmation_token", token); err != nil {
+		return false, nil, fmt.Errorf("error setting expected_confirmation_token: %w", err)
+	}
+
+	if !d.Get("require_confirmation_token").(bool) || d.Get("confirmation_token").(string) == token {
+		return true, nil, nil
+	}
+
+	var pending int
+	for _, change := range changes {
+		if change.Action != plannedChangeActionNone {
+			pending++
+		}
+	}
+	if pending == 0 {
+		return true, nil, nil
+	}
+
+	summary := fmt.Sprintf("%d change(s) not made pending confirmation", pending)
+	if d.Get("confirmation_token").(string) != "" {
+		summary = fmt.Sprintf("%d change(s) not made as they differ from those confirmed", pending)
+	}
+
+	return false, diag.Diagnostics{
+		{
+			Severity: diag.Warning,
+			Summary:  summary,
+			Detail:   fmt.Sprintf("Review planned_changes, then set confirmation_token to %q to make the changes.", token),
+		},
+	}, nil
+}
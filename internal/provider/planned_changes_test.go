@@ -0,0 +1,333 @@
+package provider
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestApplyPlannedChanges(t *testing.T) {
+	testCases := []struct {
+		Name             string
+		DryRun           bool
+		ContinueOnError  bool
+		ApplyErr         error
+		ExpectedStatuses []string
+		ExpectedApplied  []string
+		ExpectError      bool
+	}{
+		{
+			Name:             "dry run",
+			DryRun:           true,
+			ExpectedStatuses: []string{plannedChangeStatusSkipped, plannedChangeStatusPlanned, plannedChangeStatusPlanned},
+		},
+		{
+			Name:             "real mode",
+			ExpectedStatuses: []string{plannedChangeStatusSkipped, plannedChangeStatusApplied, plannedChangeStatusApplied},
+			ExpectedApplied:  []string{"vpc-2", "vpc-3"},
+		},
+		{
+			Name:             "apply error",
+			ApplyErr:         errors.New("boom"),
+			ExpectedStatuses: []string{plannedChangeStatusSkipped, "", ""},
+			ExpectedApplied:  []string{"vpc-2"},
+			ExpectError:      true,
+		},
+		{
+			Name:             "apply error with continue on error",
+			ContinueOnError:  true,
+			ApplyErr:         errors.New("boom"),
+			ExpectedStatuses: []string{plannedChangeStatusSkipped, plannedChangeStatusFailed, plannedChangeStatusFailed},
+			ExpectedApplied:  []string{"vpc-2", "vpc-3"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			changes := []*plannedChange{
+				{ResourceID: "vpc-1", Action: plannedChangeActionNone},
+				{ResourceID: "vpc-2", Action: plannedChangeActionCreate},
+				{ResourceID: "vpc-3", Action: plannedChangeActionCreate},
+			}
+
+			var applied []string
+			err := applyPlannedChanges(changes, testCase.DryRun, testCase.ContinueOnError, func(change *plannedChange) error {
+				applied = append(applied, change.ResourceID)
+				return testCase.ApplyErr
+			})
+
+			if testCase.ExpectError != (err != nil) {
+				t.Fatalf("got error %v, expected error: %t", err, testCase.ExpectError)
+			}
+
+			statuses := make([]string, 0, len(changes))
+			for _, change := range changes {
+				statuses = append(statuses, change.Status)
+			}
+
+			if !reflect.DeepEqual(statuses, testCase.ExpectedStatuses) {
+				t.Errorf("got statuses %s, expected %s", statuses, testCase.ExpectedStatuses)
+			}
+
+			if !reflect.DeepEqual(applied, testCase.ExpectedApplied) {
+				t.Errorf("got applied %s, expected %s", applied, testCase.ExpectedApplied)
+			}
+		})
+	}
+}
+
+func TestApplyPlannedChangesInBatches(t *testing.T) {
+	testCases := []struct {
+		Name             string
+		DryRun           bool
+		ContinueOnError  bool
+		FailingBatch     int
+		ExpectedStatuses []string
+		ExpectedBatches  [][]string
+		ExpectError      bool
+	}{
+		{
+			Name:             "dry run",
+			DryRun:           true,
+			FailingBatch:     -1,
+			ExpectedStatuses: []string{plannedChangeStatusSkipped, plannedChangeStatusPlanned, plannedChangeStatusPlanned, plannedChangeStatusPlanned, plannedChangeStatusPlanned},
+		},
+		{
+			Name:             "real mode",
+			FailingBatch:     -1,
+			ExpectedStatuses: []string{plannedChangeStatusSkipped, plannedChangeStatusApplied, plannedChangeStatusApplied, plannedChangeStatusApplied, plannedChangeStatusApplied},
+			ExpectedBatches:  [][]string{{"vpc-2", "vpc-4"}, {"vpc-5"}, {"vpc-3"}},
+		},
+		{
+			Name:             "apply error",
+			FailingBatch:     0,
+			ExpectedStatuses: []string{plannedChangeStatusSkipped, "", "", "", ""},
+			ExpectedBatches:  [][]string{{"vpc-2", "vpc-4"}},
+			ExpectError:      true,
+		},
+		{
+			Name:             "apply error with continue on error",
+			ContinueOnError:  true,
+			FailingBatch:     0,
+			ExpectedStatuses: []string{plannedChangeStatusSkipped, plannedChangeStatusFailed, plannedChangeStatusApplied, plannedChangeStatusFailed, plannedChangeStatusApplied},
+			ExpectedBatches:  [][]string{{"vpc-2", "vpc-4"}, {"vpc-5"}, {"vpc-3"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			changes := []*plannedChange{
+				{ResourceID: "vpc-1", Action: plannedChangeActionNone},
+				{ResourceID: "vpc-2", Action: plannedChangeActionUpdate, After: map[string]string{"Team": "a"}},
+				{ResourceID: "vpc-3", Action: plannedChangeActionUpdate, After: map[string]string{"Team": "b"}},
+				{ResourceID: "vpc-4", Action: plannedChangeActionUpdate, After: map[string]string{"Team": "a"}},
+				{ResourceID: "vpc-5", Action: plannedChangeActionUpdate, After: map[string]string{"Team": "a"}},
+			}
+
+			var batches [][]string
+			err := applyPlannedChangesInBatches(changes, testCase.DryRun, testCase.ContinueOnError, 2, func(change *plannedChange) string {
+				return change.After["Team"]
+			}, func(batch []*plannedChange) error {
+				ids := make([]string, 0, len(batch))
+				for _, change := range batch {
+					ids = append(ids, change.ResourceID)
+				}
+				batches = append(batches, ids)
+
+				if len(batches)-1 == testCase.FailingBatch {
+					return errors.New("boom")
+				}
+				return nil
+			})
+
+			if testCase.ExpectError != (err != nil) {
+				t.Fatalf("got error %v, expected error: %t", err, testCase.ExpectError)
+			}
+
+			statuses := make([]string, 0, len(changes))
+			for _, change := range changes {
+				statuses = append(statuses, change.Status)
+			}
+
+			if !reflect.DeepEqual(statuses, testCase.ExpectedStatuses) {
+				t.Errorf("got statuses %s, expected %s", statuses, testCase.ExpectedStatuses)
+			}
+
+			if !reflect.DeepEqual(batches, testCase.ExpectedBatches) {
+				t.Errorf("got batches %v, expected %v", batches, testCase.ExpectedBatches)
+			}
+		})
+	}
+}
+
+func TestFlattenPlannedChanges(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"planned_changes": plannedChangesSchema(),
+	}
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{})
+
+	changes := []*plannedChange{
+		{
+			ResourceID: "vpc-1",
+			Action:     plannedChangeActionCreate,
+			Reason:     "VPC has no Flow Log",
+			After:      map[string]string{"flow_log_id": "fl-1"},
+			Status:     plannedChangeStatusApplied,
+		},
+	}
+
+	if err := d.Set("planned_changes", flattenPlannedChanges(changes)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got, expected := d.Get("planned_changes.0.after.flow_log_id").(string), "fl-1"; got != expected {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+
+	if got, expected := d.Get("planned_changes.0.status").(string), plannedChangeStatusApplied; got != expected {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+}
+
+func TestFailedChangesDiagnostics(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"planned_changes": plannedChangesSchema(),
+		"failed":          failedChangesSchema(),
+	}
+	d := schema.TestResourceDataRaw(t, s, map[string]interface{}{})
+
+	changes := []*plannedChange{
+		{ResourceID: "vpc-1", Action: plannedChangeActionCreate, Status: plannedChangeStatusApplied},
+		{ResourceID: "vpc-2", Action: plannedChangeActionCreate, Status: plannedChangeStatusFailed, Error: "boom"},
+	}
+
+	if diags := failedChangesDiagnostics(d); len(diags) != 0 {
+		t.Fatalf("got %d diagnostics before setting changes, expected none", len(diags))
+	}
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	diags := failedChangesDiagnostics(d)
+	if len(diags) != 1 {
+		t.Fatalf("got %d diagnostics, expected 1", len(diags))
+	}
+
+	if !diags.HasError() {
+		t.Errorf("got warning diagnostic, expected error")
+	}
+
+	if got, expected := diags[0].Detail, "vpc-2 (create): boom"; got != expected {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+}
+
+func TestPlannedChangesConfirmationToken(t *testing.T) {
+	token := func(changes ...*plannedChange) string {
+		got, err := plannedChangesConfirmationToken(changes)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return got
+	}
+
+	deleteFirst := &plannedChange{ResourceID: "sg-00000001", Action: plannedChangeActionDelete}
+	deleteSecond := &plannedChange{ResourceID: "sg-00000002", Action: plannedChangeActionDelete}
+	keepThird := &plannedChange{ResourceID: "sg-00000003", Action: plannedChangeActionNone}
+
+	base := token(deleteFirst, keepThird)
+
+	if got := token(&plannedChange{ResourceID: "sg-00000001", Action: plannedChangeActionDelete, Reason: "other reason", Status: plannedChangeStatusApplied}, keepThird); got != base {
+		t.Errorf("expected the token not to depend on the reasons and statuses of the changes")
+	}
+	if got := token(deleteFirst); got != base {
+		t.Errorf("expected the token not to depend on the changes with the none action")
+	}
+	if got := token(deleteFirst, deleteSecond); got == base {
+		t.Errorf("expected the token to change when another resource is selected")
+	}
+	if got := token(deleteSecond); got == base {
+		t.Errorf("expected the token to change when other resources are selected")
+	}
+	if got := token(&plannedChange{ResourceID: "sg-00000001", Action: plannedChangeActionUpdate}); got == base {
+		t.Errorf("expected the token to change when the action changes")
+	}
+}
+
+func TestCheckConfirmationToken(t *testing.T) {
+	s := map[string]*schema.Schema{
+		"require_confirmation_token":  requireConfirmationTokenSchema(),
+		"confirmation_token":          confirmationTokenSchema(),
+		"expected_confirmation_token": expectedConfirmationTokenSchema(),
+	}
+
+	changes := []*plannedChange{{ResourceID: "sg-00000001", Action: plannedChangeActionDelete}}
+	token, err := plannedChangesConfirmationToken(changes)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testCases := []struct {
+		Name              string
+		Raw               map[string]interface{}
+		Changes           []*plannedChange
+		ExpectedConfirmed bool
+		ExpectedWarning   bool
+	}{
+		{
+			Name:              "not required",
+			Changes:           changes,
+			ExpectedConfirmed: true,
+		},
+		{
+			Name:            "required without token",
+			Raw:             map[string]interface{}{"require_confirmation_token": true},
+			Changes:         changes,
+			ExpectedWarning: true,
+		},
+		{
+			Name:            "required with another token",
+			Raw:             map[string]interface{}{"require_confirmation_token": true, "confirmation_token": "0123456789abcdef"},
+			Changes:         changes,
+			ExpectedWarning: true,
+		},
+		{
+			Name:              "required with the token",
+			Raw:               map[string]interface{}{"require_confirmation_token": true, "confirmation_token": token},
+			Changes:           changes,
+			ExpectedConfirmed: true,
+		},
+		{
+			Name:              "required without changes",
+			Raw:               map[string]interface{}{"require_confirmation_token": true},
+			Changes:           []*plannedChange{{ResourceID: "sg-00000001", Action: plannedChangeActionNone}},
+			ExpectedConfirmed: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, s, testCase.Raw)
+
+			confirmed, warnings, err := checkConfirmationToken(d, testCase.Changes)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if confirmed != testCase.ExpectedConfirmed {
+				t.Errorf("got confirmed %t, expected %t", confirmed, testCase.ExpectedConfirmed)
+			}
+
+			if got := len(warnings) > 0; got != testCase.ExpectedWarning {
+				t.Errorf("got warnings %v, expected a warning: %t", warnings, testCase.ExpectedWarning)
+			}
+
+			if d.Get("expected_confirmation_token").(string) == "" {
+				t.Errorf("expected expected_confirmation_token to be set")
+			}
+		})
+	}
+}
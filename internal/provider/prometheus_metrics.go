@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// prometheusLabelValueReplacer escapes the label values of the Prometheus text format.
+var prometheusLabelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// prometheusHelpReplacer escapes the help text of the Prometheus text format, in which quotes are not escaped.
+var prometheusHelpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// prometheusMetric is a gauge rendered into the "prometheus_metrics" attribute of a data source.
+type prometheusMetric struct {
+	// Name is the name of the data source, such as awsutils_ec2_instances, for the counts of the objects it returns,
+	// followed by the name of the value for any other value, such as awsutils_ec2_vpc_quota_usage_limit.
+	Name    string
+	Help    string
+	Samples []prometheusSample
+}
+
+// prometheusSample is a value of a prometheusMetric, with the labels telling it apart from the other values of the
+// metric. The names of the labels are those of the attributes of the data source they come from.
+type prometheusSample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// prometheusMetricsSchema returns a *schema.Schema for the computed
+// "prometheus_metrics" attribute of the data sources reporting counts, set by
+// setPrometheusMetrics.
+func prometheusMetricsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+		Description: "The counts of the results in the Prometheus text format, for the textfile collector of the node exporter, such as " +
+			"`awsutils_ec2_instances{state=\"running\"} 42`. Every metric is a gauge named after the data source for the counts, and " +
+			"followed by the name of the value for any other value, such as `awsutils_ec2_vpc_quota_usage_limit`. The labels are named " +
+			"after the attributes of the data source and their values are escaped as required by the format.",
+	}
+}
+
+// setPrometheusMetrics sets the prometheus_metrics attribute of the given
+// *schema.ResourceData to the rendering of the given metrics.
+func setPrometheusMetrics(d *schema.ResourceData, metrics ...prometheusMetric) error {
+	if err := d.Set("prometheus_metrics", renderPrometheusMetrics(metrics...)); err != nil {
+		return fmt.Errorf("error setting prometheus_metrics: %w", err)
+	}
+
+	return nil
+}
+
+// renderPrometheusMetrics renders the given metrics in the Prometheus text
+// format, each preceded by its HELP and TYPE lines. The samples of a metric
+// are sorted by their labels, themselves sorted by name, so the rendering is
+// deterministic.
+func renderPrometheusMetrics(metrics ...prometheusMetric) string {
+	var b strings.Builder
+
+	for _, metric := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n", metric.Name, prometheusHelpReplacer.Replace(metric.Help))
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", metric.Name)
+
+		lines := make([]string, 0, len(metric.Samples))
+		for _, sample := range metric.Samples {
+			lines = append(lines, metric.Name+renderPrometheusLabels(sample.Labels)+" "+strconv.FormatFloat(sample.Value, 'f', -1, 64))
+		}
+		sort.Strings(lines)
+
+		for _, line := range lines {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// renderPrometheusLabels renders the given labels, sorted by name and with their values escaped, or the empty string
+// if there are none.
+func renderPrometheusLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, prometheusLabelValueReplacer.Replace(labels[name])))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// prometheusCountSamples returns a sample for each distinct value of the given values, labelled with the given
+// label name and counting the occurrences of the value.
+func prometheusCountSamples(label string, values []string) []prometheusSample {
+	counts := make(map[string]int)
+	for _, value := range values {
+		counts[value]++
+	}
+
+	samples := make([]prometheusSample, 0, len(counts))
+	for value, count := range counts {
+		samples = append(samples, prometheusSample{
+			Labels: map[string]string{label: value},
+			Value:  float64(count),
+		})
+	}
+
+	return samples
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestRenderPrometheusMetrics(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Metrics  []prometheusMetric
+		Expected string
+	}{
+		{
+			Name: "sorted samples and labels",
+			Metrics: []prometheusMetric{
+				{
+					Name: "awsutils_ec2_instances_grouped_by_tag",
+					Help: "The number of matching EC2 Instances by value of the tag.",
+					Samples: []prometheusSample{
+						{Labels: map[string]string{"tag_value": "web", "tag_key": "Role"}, Value: 3},
+						{Labels: map[string]string{"tag_value": "db", "tag_key": "Role"}, Value: 1},
+					},
+				},
+			},
+			Expected: `# HELP awsutils_ec2_instances_grouped_by_tag The number of matching EC2 Instances by value of the tag.
+# TYPE awsutils_ec2_instances_grouped_by_tag gauge
+awsutils_ec2_instances_grouped_by_tag{tag_key="Role",tag_value="db"} 1
+awsutils_ec2_instances_grouped_by_tag{tag_key="Role",tag_value="web"} 3
+`,
+		},
+		{
+			Name: "escaped label values and help",
+			Metrics: []prometheusMetric{
+				{
+					Name: "awsutils_ec2_instances_grouped_by_tag",
+					Help: "A \"help\" with a \\ and a\nnewline.",
+					Samples: []prometheusSample{
+						{Labels: map[string]string{"tag_value": "C:\\ \"quoted\"\nline"}, Value: 2},
+					},
+				},
+			},
+			Expected: `# HELP awsutils_ec2_instances_grouped_by_tag A "help" with a \\ and a\nnewline.
+# TYPE awsutils_ec2_instances_grouped_by_tag gauge
+awsutils_ec2_instances_grouped_by_tag{tag_value="C:\\ \"quoted\"\nline"} 2
+`,
+		},
+		{
+			Name: "no labels and fractional values",
+			Metrics: []prometheusMetric{
+				{
+					Name:    "awsutils_ec2_vpc_quota_usage_utilization",
+					Help:    "The ratio.",
+					Samples: []prometheusSample{{Value: 0.125}},
+				},
+				{
+					Name: "awsutils_ec2_instances",
+					Help: "No samples.",
+				},
+			},
+			Expected: `# HELP awsutils_ec2_vpc_quota_usage_utilization The ratio.
+# TYPE awsutils_ec2_vpc_quota_usage_utilization gauge
+awsutils_ec2_vpc_quota_usage_utilization 0.125
+# HELP awsutils_ec2_instances No samples.
+# TYPE awsutils_ec2_instances gauge
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := renderPrometheusMetrics(testCase.Metrics...); got != testCase.Expected {
+				t.Errorf("got:\n%s\nexpected:\n%s", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestEc2InstancesPrometheusMetric(t *testing.T) {
+	instance := func(state string) *ec2.Instance {
+		return &ec2.Instance{State: &ec2.InstanceState{Name: aws.String(state)}}
+	}
+
+	metric := ec2InstancesPrometheusMetric([]*ec2.Instance{
+		instance(ec2.InstanceStateNameRunning),
+		instance(ec2.InstanceStateNameStopped),
+		instance(ec2.InstanceStateNameRunning),
+	})
+
+	expected := `# HELP awsutils_ec2_instances The number of matching EC2 Instances by state.
+# TYPE awsutils_ec2_instances gauge
+awsutils_ec2_instances{state="running"} 2
+awsutils_ec2_instances{state="stopped"} 1
+`
+	if got := renderPrometheusMetrics(metric); got != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
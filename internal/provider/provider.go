@@ -181,16 +181,104 @@ func Provider() *schema.Provider {
 				Default:     false,
 				Description: descriptions["s3_force_path_style"],
 			},
+
+			"escape_filter_wildcards": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: descriptions["escape_filter_wildcards"],
+			},
+
+			"max_results_cap": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultMaxResultsCap,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  descriptions["max_results_cap"],
+			},
+
+			"describe_max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultDescribeMaxRetries,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  descriptions["describe_max_retries"],
+			},
+
+			"max_filter_values": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultEC2MaxFilterValues,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  descriptions["max_filter_values"],
+			},
+
+			"max_filters": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultEC2MaxFilters,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  descriptions["max_filters"],
+			},
+
+			"validate_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: descriptions["validate_only"],
+			},
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"awsutils_ec2_client_vpn_export_client_config": dataSourceAwsUtilsEc2ExportClientVpnClientConfiguration(),
+			"awsutils_ec2_client_vpn_export_client_config":     dataSourceAwsUtilsEc2ExportClientVpnClientConfiguration(),
+			"awsutils_ec2_amis_by_deprecation_status":          dataSourceAwsUtilsEc2AmisByDeprecationStatus(),
+			"awsutils_ec2_amis_by_tag_with_latest":             dataSourceAwsUtilsEc2AmisByTagWithLatest(),
+			"awsutils_ec2_amis_missing_required_tags":          dataSourceAwsUtilsEc2AmisMissingRequiredTags(),
+			"awsutils_ec2_amis_unused":                         dataSourceAwsUtilsEc2AmisUnused(),
+			"awsutils_ec2_filter_preview":                      dataSourceAwsUtilsEc2FilterPreview(),
+			"awsutils_ec2_instance_connectivity_matrix":        dataSourceAwsUtilsEc2InstanceConnectivityMatrix(),
+			"awsutils_ec2_instances":                           dataSourceAwsUtilsEc2Instances(),
+			"awsutils_ec2_instances_by_platform":               dataSourceAwsUtilsEc2InstancesByPlatform(),
+			"awsutils_ec2_instances_by_subnet_utilization":     dataSourceAwsUtilsEc2InstancesBySubnetUtilization(),
+			"awsutils_ec2_instances_cross_referenced_with_asg": dataSourceAwsUtilsEc2InstancesCrossReferencedWithAsg(),
+			"awsutils_ec2_instances_grouped_by_tag":            dataSourceAwsUtilsEc2InstancesGroupedByTag(),
+			"awsutils_ec2_instances_with_drifted_tags":         dataSourceAwsUtilsEc2InstancesWithDriftedTags(),
+			"awsutils_ec2_instances_with_public_ip":            dataSourceAwsUtilsEc2InstancesWithPublicIp(),
+			"awsutils_ec2_launch_template_versions":            dataSourceAwsUtilsEc2LaunchTemplateVersions(),
+			"awsutils_ec2_nat_gateway_consolidator_report":     dataSourceAwsUtilsEc2NatGatewayConsolidatorReport(),
+			"awsutils_ec2_orphaned_resources":                  dataSourceAwsUtilsEc2OrphanedResources(),
+			"awsutils_ec2_route_tables":                        dataSourceAwsUtilsEc2RouteTables(),
+			"awsutils_ec2_route_to_internet_checker":           dataSourceAwsUtilsEc2RouteToInternetChecker(),
+			"awsutils_ec2_sg_consolidation_candidates":         dataSourceAwsUtilsEc2SgConsolidationCandidates(),
+			"awsutils_ec2_sg_rules_diff_between_groups":        dataSourceAwsUtilsEc2SgRulesDiffBetweenGroups(),
+			"awsutils_ec2_sg_rules_overly_permissive":          dataSourceAwsUtilsEc2SgRulesOverlyPermissive(),
+			"awsutils_ec2_subnets":                             dataSourceAwsUtilsEc2Subnets(),
+			"awsutils_ec2_tagged_resources":                    dataSourceAwsUtilsEc2TaggedResources(),
+			"awsutils_ec2_unattached_volumes_cost_estimate":    dataSourceAwsUtilsEc2UnattachedVolumesCostEstimate(),
+			"awsutils_ec2_vpc_quota_usage":                     dataSourceAwsUtilsEc2VpcQuotaUsage(),
+			"awsutils_ec2_vpc_summary":                         dataSourceAwsUtilsEc2VpcSummary(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"awsutils_default_vpc_deletion":               resourceAwsUtilsDefaultVpcDeletion(),
-			"awsutils_guardduty_organization_settings":    resourceAwsUtilsGuardDutyOrganizationSettings(),
-			"awsutils_security_hub_control_disablement":   resourceAwsUtilsSecurityHubControlDisablement(),
-			"awsutils_security_hub_organization_settings": resourceAwsUtilsSecurityHubOrganizationSettings(),
+			"awsutils_default_vpc_deletion":                                  resourceAwsUtilsDefaultVpcDeletion(),
+			"awsutils_ec2_ami_block_public_access":                           resourceAwsUtilsEc2AmiBlockPublicAccess(),
+			"awsutils_ec2_default_network_acl_hardener":                      resourceAwsUtilsEc2DefaultNetworkAclHardener(),
+			"awsutils_ec2_default_vpc_recreate":                              resourceAwsUtilsEc2DefaultVpcRecreate(),
+			"awsutils_ec2_ebs_snapshot_tagger_from_volume":                   resourceAwsUtilsEc2EbsSnapshotTaggerFromVolume(),
+			"awsutils_ec2_elastic_ip_tagger":                                 resourceAwsUtilsEc2ElasticIpTagger(),
+			"awsutils_ec2_instance_detailed_monitoring_enforcer":             resourceAwsUtilsEc2InstanceDetailedMonitoringEnforcer(),
+			"awsutils_ec2_instance_reboot_scheduler":                         resourceAwsUtilsEc2InstanceRebootScheduler(),
+			"awsutils_ec2_instance_root_volume_deleteontermination_enforcer": resourceAwsUtilsEc2InstanceRootVolumeDeleteOnTerminationEnforcer(),
+			"awsutils_ec2_instance_stop_protection_scheduler":                resourceAwsUtilsEc2InstanceStopProtectionScheduler(),
+			"awsutils_ec2_route_table_association_fixer":                     resourceAwsUtilsEc2RouteTableAssociationFixer(),
+			"awsutils_ec2_sg_baseline_enforcer":                              resourceAwsUtilsEc2SgBaselineEnforcer(),
+			"awsutils_ec2_sg_rule_importer_from_json":                        resourceAwsUtilsEc2SgRuleImporterFromJson(),
+			"awsutils_ec2_sg_rule_tag_sync":                                  resourceAwsUtilsEc2SgRuleTagSync(),
+			"awsutils_ec2_sg_unused_deleter":                                 resourceAwsUtilsEc2SgUnusedDeleter(),
+			"awsutils_ec2_tag_bulk_replacer":                                 resourceAwsUtilsEc2TagBulkReplacer(),
+			"awsutils_ec2_vpc_flow_log_enforcer":                             resourceAwsUtilsEc2VpcFlowLogEnforcer(),
+			"awsutils_guardduty_organization_settings":                       resourceAwsUtilsGuardDutyOrganizationSettings(),
+			"awsutils_security_hub_control_disablement":                      resourceAwsUtilsSecurityHubControlDisablement(),
+			"awsutils_security_hub_organization_settings":                    resourceAwsUtilsSecurityHubOrganizationSettings(),
 		},
 	}
 
@@ -258,6 +346,28 @@ func init() {
 			"i.e., http://s3.amazonaws.com/BUCKET/KEY. By default, the S3 client will\n" +
 			"use virtual hosted bucket addressing when possible\n" +
 			"(http://BUCKET.s3.amazonaws.com/KEY). Specific to the Amazon S3 service.",
+
+		"escape_filter_wildcards": "Set this to true to match the `*` and `?` characters of EC2 filter values literally,\n" +
+			"rather than as wildcards. This changes the matching semantics of the `name`, `tags` and `filter`\n" +
+			"attributes of all data sources and resources, except for `filter` blocks setting `wildcard = true`.",
+
+		"max_results_cap": "The maximum number of objects a data source may read, above which it fails\n" +
+			"rather than storing them all in the state. Data sources may override it with their own `max_results_cap`.",
+
+		"describe_max_retries": "The maximum number of times the EC2 `Describe...` calls of the data sources are retried\n" +
+			"when throttled, such as with `RequestLimitExceeded`, or failing with a transient error, once the retries\n" +
+			"of `max_retries` are exhausted. Other errors, such as those of invalid filters, are not retried, nor is the\n" +
+			"stream of Instances of `awsutils_ec2_instances_with_public_ip`, which cannot be restarted once read from.",
+
+		"max_filter_values": "The maximum number of values of a single EC2 filter, above which data sources and resources\n" +
+			"fail before calling AWS rather than with the error of the API. Defaults to the limit documented by AWS.",
+
+		"max_filters": "The maximum number of EC2 filters of a request, above which data sources and resources\n" +
+			"fail before calling AWS rather than with the error of the API. Defaults to the limit documented by AWS.",
+
+		"validate_only": "Set this to true to build and validate the filters and other inputs of the data sources\n" +
+			"without calling AWS, such as to lint configurations in CI without credentials. The data sources\n" +
+			"then match nothing and return empty results, and calls changing anything fail.",
 	}
 
 	endpointServiceNames = []string{
@@ -441,6 +551,12 @@ func providerConfigure(d *schema.ResourceData, terraformVersion string) (interfa
 		SkipRequestingAccountId: d.Get("skip_requesting_account_id").(bool),
 		SkipMetadataApiCheck:    d.Get("skip_metadata_api_check").(bool),
 		S3ForcePathStyle:        d.Get("s3_force_path_style").(bool),
+		EscapeFilterWildcards:   d.Get("escape_filter_wildcards").(bool),
+		MaxResultsCap:           d.Get("max_results_cap").(int),
+		DescribeMaxRetries:      d.Get("describe_max_retries").(int),
+		MaxFilterValues:         d.Get("max_filter_values").(int),
+		MaxFilters:              d.Get("max_filters").(int),
+		ValidateOnly:            d.Get("validate_only").(bool),
 		terraformVersion:        terraformVersion,
 	}
 
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// testAWSSession returns a session with static credentials and without retries, for the mocked clients of the tests.
+func testAWSSession(t *testing.T) *session.Session {
+	t.Helper()
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		MaxRetries:  aws.Int(0),
+	})
+	if err != nil {
+		t.Fatalf("error creating session: %s", err)
+	}
+
+	return sess
+}
+
+// testMockHandlers replaces the handlers sending the requests of a client and unmarshaling their responses by the
+// given function, which answers each request by filling its r.Data output or setting r.Error.
+func testMockHandlers(handlers *request.Handlers, respond func(*request.Request)) {
+	handlers.Send.Clear()
+	handlers.Unmarshal.Clear()
+	handlers.UnmarshalMeta.Clear()
+	handlers.UnmarshalError.Clear()
+	handlers.ValidateResponse.Clear()
+	handlers.Send.PushBack(respond)
+}
+
+// testEc2Conn returns an EC2 client whose requests are answered by the given function instead of being sent to AWS,
+// as done by testMockHandlers.
+func testEc2Conn(t *testing.T, respond func(*request.Request)) *ec2.EC2 {
+	t.Helper()
+
+	conn := ec2.New(testAWSSession(t))
+	testMockHandlers(&conn.Handlers, respond)
+
+	return conn
+}
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2ImageBlockPublicAccessTimeout is the default time to wait for a change of the block public access for AMIs
+// setting to be reported, which AWS documents as taking up to 10 minutes.
+const ec2ImageBlockPublicAccessTimeout = 10 * time.Minute
+
+func resourceAwsUtilsEc2AmiBlockPublicAccess() *schema.Resource {
+	return &schema.Resource{
+		Description: `Manages the block public access for AMIs setting of the account in the configured region, which prevents
+the AMIs of the account from being made public.
+
+The setting is only changed, with ` + "`EnableImageBlockPublicAccess`" + ` or ` + "`DisableImageBlockPublicAccess`" + `, when its
+current state differs from ` + "`state`" + `, and the change is then waited for, as it can take several minutes to be
+reported. AMIs which are already public are not affected.
+
+Destroying this resource leaves the setting as it is unless ` + "`disable_on_destroy`" + ` is set, in which case public
+sharing of AMIs is unblocked again.`,
+		CreateContext: resourceAwsEc2AmiBlockPublicAccessCreate,
+		ReadContext:   resourceAwsEc2AmiBlockPublicAccessRead,
+		UpdateContext: resourceAwsEc2AmiBlockPublicAccessUpdate,
+		DeleteContext: resourceAwsEc2AmiBlockPublicAccessDelete,
+		SchemaVersion: 1,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(ec2ImageBlockPublicAccessTimeout),
+			Update: schema.DefaultTimeout(ec2ImageBlockPublicAccessTimeout),
+			Delete: schema.DefaultTimeout(ec2ImageBlockPublicAccessTimeout),
+		},
+		Schema: map[string]*schema.Schema{
+			"state": {
+				Description:  "The desired state of the setting: `block-new-sharing` to block public sharing of AMIs, or `unblocked`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      tfec2.ImageBlockPublicAccessStateBlockNewSharing,
+				ValidateFunc: validation.StringInSlice(tfec2.ImageBlockPublicAccessState_Values(), false),
+			},
+			"disable_on_destroy": {
+				Description: "Unblock public sharing of AMIs when this resource is destroyed, rather than leaving the setting as it is.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"current_state": {
+				Description: "The current state of the setting, as reported by `GetImageBlockPublicAccessState`.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceAwsEc2AmiBlockPublicAccessCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*AWSClient).ec2conn
+
+	if err := updateEc2ImageBlockPublicAccess(ctx, conn, d.Get("state").(string), d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(meta.(*AWSClient).region)
+
+	return resourceAwsEc2AmiBlockPublicAccessRead(ctx, d, meta)
+}
+
+func resourceAwsEc2AmiBlockPublicAccessRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*AWSClient).ec2conn
+
+	state, err := tfec2.GetImageBlockPublicAccessState(conn)
+	if err != nil {
+		return diag.Errorf("error reading EC2 Image Block Public Access state: %s", err)
+	}
+
+	d.Set("state", state)
+	d.Set("current_state", state)
+
+	return nil
+}
+
+func resourceAwsEc2AmiBlockPublicAccessUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*AWSClient).ec2conn
+
+	if d.HasChange("state") {
+		if err := updateEc2ImageBlockPublicAccess(ctx, conn, d.Get("state").(string), d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceAwsEc2AmiBlockPublicAccessRead(ctx, d, meta)
+}
+
+func resourceAwsEc2AmiBlockPublicAccessDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*AWSClient).ec2conn
+
+	if !d.Get("disable_on_destroy").(bool) {
+		log.Printf("[INFO] Leaving EC2 Image Block Public Access state as it is (%s)", d.Get("current_state").(string))
+		return nil
+	}
+
+	if err := updateEc2ImageBlockPublicAccess(ctx, conn, tfec2.ImageBlockPublicAccessStateUnblocked, d.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// updateEc2ImageBlockPublicAccess sets the block public access for AMIs setting to the given state, unless it
+// already is in that state, and waits up to the given timeout for the new state to be reported.
+func updateEc2ImageBlockPublicAccess(ctx context.Context, conn *ec2.EC2, state string, timeout time.Duration) error {
+	current, err := tfec2.GetImageBlockPublicAccessState(conn)
+	if err != nil {
+		return fmt.Errorf("error reading EC2 Image Block Public Access state: %w", err)
+	}
+
+	if current == state {
+		log.Printf("[DEBUG] EC2 Image Block Public Access state is already %s", state)
+		return nil
+	}
+
+	log.Printf("[INFO] Changing EC2 Image Block Public Access state from %s to %s", current, state)
+	if state == tfec2.ImageBlockPublicAccessStateUnblocked {
+		_, err = tfec2.DisableImageBlockPublicAccess(conn)
+	} else {
+		_, err = tfec2.EnableImageBlockPublicAccess(conn, state)
+	}
+	if err != nil {
+		return fmt.Errorf("error changing EC2 Image Block Public Access state to %s: %w", state, err)
+	}
+
+	err = resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		current, err := tfec2.GetImageBlockPublicAccessState(conn)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+
+		if current != state {
+			return resource.RetryableError(fmt.Errorf("state is still %s", current))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error waiting for EC2 Image Block Public Access state to be %s: %w", state, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+)
+
+// testEc2ImageBlockPublicAccessConn returns an EC2 client holding the given block public access for AMIs state,
+// changed by the enable and disable requests, and the actions of the requests it was sent.
+func testEc2ImageBlockPublicAccessConn(t *testing.T, state string) (*ec2.EC2, *[]string) {
+	// The custom operations are answered with HTTP responses rather than with testEc2Conn, so that their
+	// marshaling with the EC2 query protocol is exercised too.
+	conn := ec2.New(testAWSSession(t))
+	var actions []string
+
+	conn.Handlers.Send.Clear()
+	conn.Handlers.Send.PushBack(func(r *request.Request) {
+		body, err := ioutil.ReadAll(r.HTTPRequest.Body)
+		if err != nil {
+			r.Error = err
+			return
+		}
+
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			r.Error = err
+			return
+		}
+
+		action := values.Get("Action")
+		actions = append(actions, action)
+
+		switch action {
+		case "EnableImageBlockPublicAccess":
+			state = values.Get("ImageBlockPublicAccessState")
+		case "DisableImageBlockPublicAccess":
+			state = tfec2.ImageBlockPublicAccessStateUnblocked
+		}
+
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader("<Response><imageBlockPublicAccessState>" + state + "</imageBlockPublicAccessState></Response>")),
+		}
+	})
+
+	return conn, &actions
+}
+
+func TestUpdateEc2ImageBlockPublicAccess(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		Current         string
+		Desired         string
+		ExpectedActions []string
+	}{
+		{
+			Name:    "enable",
+			Current: tfec2.ImageBlockPublicAccessStateUnblocked,
+			Desired: tfec2.ImageBlockPublicAccessStateBlockNewSharing,
+			ExpectedActions: []string{
+				"GetImageBlockPublicAccessState",
+				"EnableImageBlockPublicAccess",
+				"GetImageBlockPublicAccessState",
+			},
+		},
+		{
+			Name:    "disable",
+			Current: tfec2.ImageBlockPublicAccessStateBlockNewSharing,
+			Desired: tfec2.ImageBlockPublicAccessStateUnblocked,
+			ExpectedActions: []string{
+				"GetImageBlockPublicAccessState",
+				"DisableImageBlockPublicAccess",
+				"GetImageBlockPublicAccessState",
+			},
+		},
+		{
+			Name:    "already enabled",
+			Current: tfec2.ImageBlockPublicAccessStateBlockNewSharing,
+			Desired: tfec2.ImageBlockPublicAccessStateBlockNewSharing,
+			ExpectedActions: []string{
+				"GetImageBlockPublicAccessState",
+			},
+		},
+		{
+			Name:    "already unblocked",
+			Current: tfec2.ImageBlockPublicAccessStateUnblocked,
+			Desired: tfec2.ImageBlockPublicAccessStateUnblocked,
+			ExpectedActions: []string{
+				"GetImageBlockPublicAccessState",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			conn, actions := testEc2ImageBlockPublicAccessConn(t, testCase.Current)
+
+			if err := updateEc2ImageBlockPublicAccess(context.Background(), conn, testCase.Desired, time.Minute); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(*actions, testCase.ExpectedActions) {
+				t.Errorf("got actions %v, expected %v", *actions, testCase.ExpectedActions)
+			}
+		})
+	}
+}
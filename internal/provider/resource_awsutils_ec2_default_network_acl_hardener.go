@@ -0,0 +1,883 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2NetworkAclDefaultRuleNumber is the number of the IPv4 deny-all entries every Network ACL ends with, followed by
+// the IPv6 ones, numbered 32768, in VPCs with an IPv6 CIDR block. Neither can be modified.
+const ec2NetworkAclDefaultRuleNumber = 32767
+
+// ec2NetworkAclProtocolNumbers maps the protocol names accepted in the "rule" blocks to the protocol numbers the
+// Network ACL Entries AWS returns have.
+var ec2NetworkAclProtocolNumbers = map[string]string{
+	"all":    "-1",
+	"icmp":   "1",
+	"tcp":    "6",
+	"udp":    "17",
+	"icmpv6": "58",
+}
+
+// ec2NetworkAclEntry is an entry of a Network ACL, normalized so that it can be compared with the existing entries.
+type ec2NetworkAclEntry struct {
+	RuleNumber    int64
+	Egress        bool
+	Protocol      string
+	RuleAction    string
+	CidrBlock     string
+	Ipv6CidrBlock string
+	FromPort      int64
+	ToPort        int64
+	IcmpType      int64
+	IcmpCode      int64
+}
+
+func resourceAwsUtilsEc2DefaultNetworkAclHardener() *schema.Resource {
+	return &schema.Resource{
+		Description: `Replaces the allow-all entries of the default Network ACL of the VPCs matching the given filters with a
+restrictive set of rules, as some compliance frameworks forbid a default Network ACL allowing all traffic.
+
+The entries of each default Network ACL are brought in line with the ` + "`rule`" + ` blocks: the missing entries are
+created, those with the same rule number and direction but different attributes are replaced and the remaining entries
+are deleted, so a default Network ACL without any ` + "`rule`" + ` only keeps the deny-all entries AWS adds to every
+Network ACL. Applying this resource repeatedly is a no-op once the entries are in place.
+
+Subnets which are not explicitly associated with a Network ACL use the default one, so hardening it restricts their
+traffic too. A warning lists the subnets associated with each default Network ACL which is changed, and the Network
+ACLs with associated subnets are left untouched when ` + "`skip_associated`" + ` is set.
+
+The entries of each default Network ACL are recorded in ` + "`original_network_acls`" + ` before it is first changed,
+and restored when ` + "`terraform destroy`" + ` is run. When ` + "`dry_run`" + ` is set, the changes are reported in
+` + "`planned_changes`" + ` but neither made nor recorded. When ` + "`require_confirmation_token`" + ` is set, they are
+only made once ` + "`confirmation_token`" + ` is set to the ` + "`expected_confirmation_token`" + ` of the changes, which
+changes whenever they do, forcing a new review. When ` + "`continue_on_error`" + ` is set, the entries which cannot be
+changed are reported in ` + "`failed`" + ` and in an error returned after the remaining changes are made.`,
+		CreateContext: resourceAwsEc2DefaultNetworkAclHardenerCreate,
+		ReadContext:   resourceAwsEc2DefaultNetworkAclHardenerRead,
+		UpdateContext: resourceAwsEc2DefaultNetworkAclHardenerUpdate,
+		DeleteContext: resourceAwsEc2DefaultNetworkAclHardenerDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 VPCs", countEc2SelectedVpcs),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeVpc),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"rule": {
+				Description: "An entry every selected default Network ACL must have, in place of the allow-all entries.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"rule_number": {
+							Description:  "The number of the entry, evaluated in increasing order.",
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(1, ec2NetworkAclDefaultRuleNumber-1),
+						},
+						"egress": {
+							Description: "Whether the entry applies to the egress traffic rather than the ingress traffic.",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+						},
+						"protocol": {
+							Description:  "The protocol of the entry, such as `tcp`, `udp` or `icmp` or a protocol number, or `-1` for all protocols.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+						},
+						"rule_action": {
+							Description:  "Whether to `allow` or `deny` the matching traffic.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(ec2.RuleAction_Values(), false),
+						},
+						"cidr_block": {
+							Description:  "The IPv4 CIDR block the entry matches.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.IsCIDR,
+						},
+						"ipv6_cidr_block": {
+							Description:  "The IPv6 CIDR block the entry matches.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.IsCIDR,
+						},
+						"from_port": {
+							Description:  "The start of the port range, for the `tcp` and `udp` protocols.",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validation.IntBetween(0, 65535),
+						},
+						"to_port": {
+							Description:  "The end of the port range, for the `tcp` and `udp` protocols.",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validation.IntBetween(0, 65535),
+						},
+						"icmp_type": {
+							Description:  "The ICMP type, for the `icmp` and `icmpv6` protocols, or `-1` for all types.",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      -1,
+							ValidateFunc: validation.IntBetween(-1, 255),
+						},
+						"icmp_code": {
+							Description:  "The ICMP code, for the `icmp` and `icmpv6` protocols, or `-1` for all codes.",
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      -1,
+							ValidateFunc: validation.IntBetween(-1, 255),
+						},
+					},
+				},
+			},
+			"skip_associated": {
+				Description: "Leave the default Network ACLs associated with subnets untouched.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"dry_run": {
+				Description: "Report the changes without making them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"associated_subnet_ids": {
+				Description: "The IDs of the subnets associated with the selected default Network ACLs, keyed by Network ACL ID and separated by commas.",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"original_network_acls": {
+				Description: "The entries of the default Network ACLs changed by this resource before they were first changed, which are restored on destroy.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"network_acl_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"entry": {
+							Description: "The entries of the Network ACL, without the deny-all entries every Network ACL ends with.",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"rule_number": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"egress": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+									"protocol": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"rule_action": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"cidr_block": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"ipv6_cidr_block": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"from_port": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"to_port": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"icmp_type": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"icmp_code": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"require_confirmation_token":  requireConfirmationTokenSchema(),
+			"confirmation_token":          confirmationTokenSchema(),
+			"expected_confirmation_token": expectedConfirmationTokenSchema(),
+			"warn_if_matches_over":        ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":        ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning":     ec2MatchThresholdWarningSchema(),
+			"continue_on_error":           continueOnErrorSchema(),
+			"planned_changes":             plannedChangesSchema(),
+			"failed":                      failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2DefaultNetworkAclHardenerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// The ID is set before any entry is changed so that, if changing one fails, the original entries of the Network
+	// ACLs changed before are still recorded in the state saved along with the tainted resource, to be restored.
+	d.SetId(uuid.New().String())
+
+	warnings, err := hardenEc2DefaultNetworkAcls(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2DefaultNetworkAclHardenerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2DefaultNetworkAclHardenerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*AWSClient).ec2conn
+	originals := d.Get("original_network_acls").([]interface{})
+
+	if len(originals) == 0 {
+		return nil
+	}
+
+	networkAcls, err := findEc2OriginalNetworkAcls(conn, originals)
+	if err != nil {
+		return diag.Errorf("error reading EC2 Network ACLs: %s", err)
+	}
+
+	existing := make(map[string]bool, len(networkAcls))
+	for _, networkAcl := range networkAcls {
+		existing[aws.StringValue(networkAcl.NetworkAclId)] = true
+	}
+
+	kept := make([]interface{}, 0, len(originals))
+	for _, v := range originals {
+		networkAclID := v.(map[string]interface{})["network_acl_id"].(string)
+		if !existing[networkAclID] {
+			log.Printf("[WARN] EC2 Network ACL (%s) no longer exists, removing from state", networkAclID)
+			continue
+		}
+		kept = append(kept, v)
+	}
+
+	if err := d.Set("original_network_acls", kept); err != nil {
+		return diag.Errorf("error setting original_network_acls: %s", err)
+	}
+
+	return nil
+}
+
+func resourceAwsEc2DefaultNetworkAclHardenerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := hardenEc2DefaultNetworkAcls(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2DefaultNetworkAclHardenerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2DefaultNetworkAclHardenerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*AWSClient).ec2conn
+	originals := d.Get("original_network_acls").([]interface{})
+
+	if len(originals) == 0 {
+		return nil
+	}
+
+	networkAcls, err := findEc2OriginalNetworkAcls(conn, originals)
+	if err != nil {
+		return diag.Errorf("error reading EC2 Network ACLs: %s", err)
+	}
+
+	entriesByID := make(map[string][]*ec2.NetworkAclEntry, len(networkAcls))
+	for _, networkAcl := range networkAcls {
+		entriesByID[aws.StringValue(networkAcl.NetworkAclId)] = networkAcl.Entries
+	}
+
+	var changes []*plannedChange
+	for _, v := range originals {
+		m := v.(map[string]interface{})
+		networkAclID := m["network_acl_id"].(string)
+
+		entries, ok := entriesByID[networkAclID]
+		if !ok {
+			log.Printf("[WARN] EC2 Network ACL (%s) no longer exists, skipping the restoration of its entries", networkAclID)
+			continue
+		}
+
+		changes = append(changes, ec2NetworkAclEntryChanges(networkAclID, entries, expandEc2NetworkAclOriginalEntries(m["entry"].([]interface{})))...)
+	}
+
+	if err := applyPlannedChanges(changes, false, false, func(change *plannedChange) error {
+		return applyEc2NetworkAclEntryChange(conn, change)
+	}); err != nil {
+		return diag.Errorf("error restoring EC2 Network ACL entries: %s", err)
+	}
+
+	return nil
+}
+
+// hardenEc2DefaultNetworkAcls brings the entries of the default Network ACLs of the selected VPCs in line with the
+// "rule" blocks, recording the outcome and the original entries in the given *schema.ResourceData. A warning is
+// returned for each changed default Network ACL associated with subnets, and for warn_if_matches_over and
+// require_confirmation_token.
+func hardenEc2DefaultNetworkAcls(d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+	dryRun := d.Get("dry_run").(bool)
+
+	desired, err := expandEc2NetworkAclEntries(d.Get("rule").([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+
+	vpcs, filters, warnings, err := findEc2SelectedVpcs(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(vpcs), "EC2 VPCs", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	var networkAcls []*ec2.NetworkAcl
+	if len(vpcs) > 0 {
+		vpcIDs := make([]string, 0, len(vpcs))
+		for _, vpc := range vpcs {
+			vpcIDs = append(vpcIDs, aws.StringValue(vpc.VpcId))
+		}
+
+		networkAcls, err = finder.NetworkAcls(conn, &ec2.DescribeNetworkAclsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("default"),
+					Values: aws.StringSlice([]string{"true"}),
+				},
+				{
+					Name:   aws.String("vpc-id"),
+					Values: aws.StringSlice(vpcIDs),
+				},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 Network ACLs: %w", err)
+		}
+	}
+
+	sort.Slice(networkAcls, func(i, j int) bool {
+		return aws.StringValue(networkAcls[i].NetworkAclId) < aws.StringValue(networkAcls[j].NetworkAclId)
+	})
+
+	originals := d.Get("original_network_acls").([]interface{})
+	recorded := make(map[string]bool, len(originals))
+	for _, networkAclID := range ec2OriginalNetworkAclIDs(originals) {
+		recorded[networkAclID] = true
+	}
+
+	var changes []*plannedChange
+	var changed []*ec2.NetworkAcl
+	associatedSubnetIDs := make(map[string]interface{})
+
+	for _, networkAcl := range networkAcls {
+		networkAclID := aws.StringValue(networkAcl.NetworkAclId)
+
+		var subnetIDs []string
+		for _, association := range networkAcl.Associations {
+			subnetIDs = appendUniqueString(subnetIDs, aws.StringValue(association.SubnetId))
+		}
+		sort.Strings(subnetIDs)
+		if len(subnetIDs) > 0 {
+			associatedSubnetIDs[networkAclID] = strings.Join(subnetIDs, ",")
+		}
+
+		if len(subnetIDs) > 0 && d.Get("skip_associated").(bool) {
+			changes = append(changes, &plannedChange{
+				ResourceID: networkAclID,
+				Action:     plannedChangeActionNone,
+				Reason:     "default Network ACL is associated with subnets",
+			})
+			continue
+		}
+
+		networkAclChanges := ec2NetworkAclEntryChanges(networkAclID, networkAcl.Entries, desired)
+		if networkAclChanges[0].Action == plannedChangeActionNone {
+			changes = append(changes, networkAclChanges...)
+			continue
+		}
+
+		if len(subnetIDs) > 0 {
+			warnings = append(warnings, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Default Network ACL %s is associated with subnets", networkAclID),
+				Detail:   fmt.Sprintf("The traffic of the subnets %s of VPC %s, which use the default Network ACL, is restricted to the given rules.", strings.Join(subnetIDs, ", "), aws.StringValue(networkAcl.VpcId)),
+			})
+		}
+
+		changed = append(changed, networkAcl)
+		changes = append(changes, networkAclChanges...)
+	}
+
+	confirmed, confirmationWarnings, err := checkConfirmationToken(d, changes)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, confirmationWarnings...)
+	dryRun = dryRun || !confirmed
+
+	for _, networkAcl := range changed {
+		if networkAclID := aws.StringValue(networkAcl.NetworkAclId); !dryRun && !recorded[networkAclID] {
+			originals = append(originals, map[string]interface{}{
+				"network_acl_id": networkAclID,
+				"entry":          flattenEc2NetworkAclEntries(networkAcl.Entries),
+			})
+			recorded[networkAclID] = true
+		}
+	}
+
+	err = applyPlannedChanges(changes, dryRun, d.Get("continue_on_error").(bool), func(change *plannedChange) error {
+		return applyEc2NetworkAclEntryChange(conn, change)
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	// The original entries are recorded even if a change failed, so that those which were changed are restored.
+	if err := d.Set("original_network_acls", originals); err != nil {
+		return nil, fmt.Errorf("error setting original_network_acls: %w", err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("associated_subnet_ids", associatedSubnetIDs); err != nil {
+		return nil, fmt.Errorf("error setting associated_subnet_ids: %w", err)
+	}
+
+	return warnings, nil
+}
+
+// expandEc2NetworkAclEntries expands the "rule" blocks. It is an error for a rule not to have exactly one CIDR
+// block, or for two rules to share a rule number and direction.
+func expandEc2NetworkAclEntries(l []interface{}) ([]ec2NetworkAclEntry, error) {
+	entries := make([]ec2NetworkAclEntry, 0, len(l))
+	seen := make(map[string]bool, len(l))
+
+	for i, v := range l {
+		m := v.(map[string]interface{})
+		entry := ec2NetworkAclEntry{
+			RuleNumber:    int64(m["rule_number"].(int)),
+			Egress:        m["egress"].(bool),
+			Protocol:      normalizeEc2NetworkAclProtocol(m["protocol"].(string)),
+			RuleAction:    m["rule_action"].(string),
+			CidrBlock:     m["cidr_block"].(string),
+			Ipv6CidrBlock: m["ipv6_cidr_block"].(string),
+			FromPort:      int64(m["from_port"].(int)),
+			ToPort:        int64(m["to_port"].(int)),
+			IcmpType:      int64(m["icmp_type"].(int)),
+			IcmpCode:      int64(m["icmp_code"].(int)),
+		}
+
+		if (entry.CidrBlock == "") == (entry.Ipv6CidrBlock == "") {
+			return nil, fmt.Errorf("rule.%d: exactly one of cidr_block and ipv6_cidr_block must be set", i)
+		}
+
+		if entry.FromPort > entry.ToPort {
+			return nil, fmt.Errorf("rule.%d: from_port (%d) must not be greater than to_port (%d)", i, entry.FromPort, entry.ToPort)
+		}
+
+		if seen[entry.key()] {
+			return nil, fmt.Errorf("rule.%d: duplicate rule number %d for the %s traffic", i, entry.RuleNumber, entry.direction())
+		}
+		seen[entry.key()] = true
+
+		entries = append(entries, entry.normalized())
+	}
+
+	return entries, nil
+}
+
+// expandEc2NetworkAclOriginalEntries expands the entries recorded in the "original_network_acls" attribute.
+func expandEc2NetworkAclOriginalEntries(l []interface{}) []ec2NetworkAclEntry {
+	entries := make([]ec2NetworkAclEntry, 0, len(l))
+
+	for _, v := range l {
+		m := v.(map[string]interface{})
+		entries = append(entries, ec2NetworkAclEntry{
+			RuleNumber:    int64(m["rule_number"].(int)),
+			Egress:        m["egress"].(bool),
+			Protocol:      m["protocol"].(string),
+			RuleAction:    m["rule_action"].(string),
+			CidrBlock:     m["cidr_block"].(string),
+			Ipv6CidrBlock: m["ipv6_cidr_block"].(string),
+			FromPort:      int64(m["from_port"].(int)),
+			ToPort:        int64(m["to_port"].(int)),
+			IcmpType:      int64(m["icmp_type"].(int)),
+			IcmpCode:      int64(m["icmp_code"].(int)),
+		}.normalized())
+	}
+
+	return entries
+}
+
+// flattenEc2NetworkAclEntries flattens the given entries of a Network ACL, without the deny-all entries every
+// Network ACL ends with, into the "entry" blocks of the "original_network_acls" attribute.
+func flattenEc2NetworkAclEntries(entries []*ec2.NetworkAclEntry) []interface{} {
+	result := make([]interface{}, 0, len(entries))
+
+	for _, entry := range ec2NetworkAclEntriesFromApi(entries) {
+		result = append(result, map[string]interface{}{
+			"rule_number":     int(entry.RuleNumber),
+			"egress":          entry.Egress,
+			"protocol":        entry.Protocol,
+			"rule_action":     entry.RuleAction,
+			"cidr_block":      entry.CidrBlock,
+			"ipv6_cidr_block": entry.Ipv6CidrBlock,
+			"from_port":       int(entry.FromPort),
+			"to_port":         int(entry.ToPort),
+			"icmp_type":       int(entry.IcmpType),
+			"icmp_code":       int(entry.IcmpCode),
+		})
+	}
+
+	return result
+}
+
+// findEc2OriginalNetworkAcls looks up the Network ACLs recorded in the "original_network_acls" attribute. The
+// Network ACLs are filtered on rather than looked up by ID, which would fail for those which no longer exist.
+func findEc2OriginalNetworkAcls(conn *ec2.EC2, originals []interface{}) ([]*ec2.NetworkAcl, error) {
+	return finder.NetworkAcls(conn, &ec2.DescribeNetworkAclsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("network-acl-id"),
+				Values: aws.StringSlice(ec2OriginalNetworkAclIDs(originals)),
+			},
+		},
+	})
+}
+
+// ec2OriginalNetworkAclIDs returns the IDs of the Network ACLs recorded in the "original_network_acls" attribute.
+func ec2OriginalNetworkAclIDs(originals []interface{}) []string {
+	ids := make([]string, 0, len(originals))
+	for _, v := range originals {
+		ids = append(ids, v.(map[string]interface{})["network_acl_id"].(string))
+	}
+
+	return ids
+}
+
+// ec2NetworkAclEntriesFromApi returns the normalized given Network ACL Entries, without the deny-all entries every
+// Network ACL ends with, ordered by direction and rule number.
+func ec2NetworkAclEntriesFromApi(apiEntries []*ec2.NetworkAclEntry) []ec2NetworkAclEntry {
+	var entries []ec2NetworkAclEntry
+
+	for _, apiEntry := range apiEntries {
+		if aws.Int64Value(apiEntry.RuleNumber) >= ec2NetworkAclDefaultRuleNumber {
+			continue
+		}
+
+		entry := ec2NetworkAclEntry{
+			RuleNumber:    aws.Int64Value(apiEntry.RuleNumber),
+			Egress:        aws.BoolValue(apiEntry.Egress),
+			Protocol:      normalizeEc2NetworkAclProtocol(aws.StringValue(apiEntry.Protocol)),
+			RuleAction:    aws.StringValue(apiEntry.RuleAction),
+			CidrBlock:     aws.StringValue(apiEntry.CidrBlock),
+			Ipv6CidrBlock: aws.StringValue(apiEntry.Ipv6CidrBlock),
+		}
+
+		if apiEntry.PortRange != nil {
+			entry.FromPort = aws.Int64Value(apiEntry.PortRange.From)
+			entry.ToPort = aws.Int64Value(apiEntry.PortRange.To)
+		}
+
+		if apiEntry.IcmpTypeCode != nil {
+			entry.IcmpType = aws.Int64Value(apiEntry.IcmpTypeCode.Type)
+			entry.IcmpCode = aws.Int64Value(apiEntry.IcmpTypeCode.Code)
+		}
+
+		entries = append(entries, entry.normalized())
+	}
+
+	sortEc2NetworkAclEntries(entries)
+
+	return entries
+}
+
+// sortEc2NetworkAclEntries sorts the given entries by direction, ingress first, and rule number.
+func sortEc2NetworkAclEntries(entries []ec2NetworkAclEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Egress != entries[j].Egress {
+			return !entries[i].Egress
+		}
+		return entries[i].RuleNumber < entries[j].RuleNumber
+	})
+}
+
+// ec2NetworkAclEntryChanges returns the changes bringing the given entries of a Network ACL in line with the desired
+// ones, in the order of the desired entries: the creation of each missing entry, the replacement of each entry with
+// the same rule number and direction but different attributes, and then the deletion of every other entry, except
+// for the deny-all entries every Network ACL ends with. A single change with the none action is returned for a
+// Network ACL already in line with the desired entries.
+func ec2NetworkAclEntryChanges(networkAclID string, apiEntries []*ec2.NetworkAclEntry, desired []ec2NetworkAclEntry) []*plannedChange {
+	var changes []*plannedChange
+
+	existing := make(map[string]ec2NetworkAclEntry, len(apiEntries))
+	for _, entry := range ec2NetworkAclEntriesFromApi(apiEntries) {
+		existing[entry.key()] = entry
+	}
+
+	sorted := append([]ec2NetworkAclEntry{}, desired...)
+	sortEc2NetworkAclEntries(sorted)
+
+	wanted := make(map[string]bool, len(sorted))
+	for _, entry := range sorted {
+		wanted[entry.key()] = true
+
+		current, ok := existing[entry.key()]
+		switch {
+		case !ok:
+			changes = append(changes, &plannedChange{
+				ResourceID: networkAclID,
+				Action:     plannedChangeActionCreate,
+				Reason:     fmt.Sprintf("%s entry %d is missing", entry.direction(), entry.RuleNumber),
+				After:      entry.flatten(),
+			})
+		case current != entry:
+			changes = append(changes, &plannedChange{
+				ResourceID: networkAclID,
+				Action:     plannedChangeActionUpdate,
+				Reason:     fmt.Sprintf("%s entry %d differs", entry.direction(), entry.RuleNumber),
+				Before:     current.flatten(),
+				After:      entry.flatten(),
+			})
+		}
+	}
+
+	for _, entry := range ec2NetworkAclEntriesFromApi(apiEntries) {
+		if wanted[entry.key()] {
+			continue
+		}
+
+		changes = append(changes, &plannedChange{
+			ResourceID: networkAclID,
+			Action:     plannedChangeActionDelete,
+			Reason:     fmt.Sprintf("%s entry %d is not a rule", entry.direction(), entry.RuleNumber),
+			Before:     entry.flatten(),
+		})
+	}
+
+	if len(changes) == 0 {
+		changes = append(changes, &plannedChange{
+			ResourceID: networkAclID,
+			Action:     plannedChangeActionNone,
+			Reason:     "entries are in place",
+		})
+	}
+
+	return changes
+}
+
+// applyEc2NetworkAclEntryChange makes the given change to an entry of a Network ACL, as returned by
+// ec2NetworkAclEntryChanges.
+func applyEc2NetworkAclEntryChange(conn *ec2.EC2, change *plannedChange) error {
+	networkAclID := change.ResourceID
+
+	switch change.Action {
+	case plannedChangeActionDelete:
+		entry := expandEc2NetworkAclEntryFlattened(change.Before)
+
+		log.Printf("[INFO] Deleting %s entry %d of EC2 Network ACL (%s)", entry.direction(), entry.RuleNumber, networkAclID)
+		_, err := conn.DeleteNetworkAclEntry(&ec2.DeleteNetworkAclEntryInput{
+			NetworkAclId: aws.String(networkAclID),
+			RuleNumber:   aws.Int64(entry.RuleNumber),
+			Egress:       aws.Bool(entry.Egress),
+		})
+		if err != nil {
+			return fmt.Errorf("error deleting %s entry %d of EC2 Network ACL (%s): %w", entry.direction(), entry.RuleNumber, networkAclID, err)
+		}
+	case plannedChangeActionUpdate:
+		entry := expandEc2NetworkAclEntryFlattened(change.After)
+		input := entry.replaceInput(networkAclID)
+
+		log.Printf("[INFO] Replacing %s entry %d of EC2 Network ACL (%s): %s", entry.direction(), entry.RuleNumber, networkAclID, input)
+		if _, err := conn.ReplaceNetworkAclEntry(input); err != nil {
+			return fmt.Errorf("error replacing %s entry %d of EC2 Network ACL (%s): %w", entry.direction(), entry.RuleNumber, networkAclID, err)
+		}
+	default:
+		entry := expandEc2NetworkAclEntryFlattened(change.After)
+		input := entry.createInput(networkAclID)
+
+		log.Printf("[INFO] Creating %s entry %d of EC2 Network ACL (%s): %s", entry.direction(), entry.RuleNumber, networkAclID, input)
+		if _, err := conn.CreateNetworkAclEntry(input); err != nil {
+			return fmt.Errorf("error creating %s entry %d of EC2 Network ACL (%s): %w", entry.direction(), entry.RuleNumber, networkAclID, err)
+		}
+	}
+
+	return nil
+}
+
+// normalizeEc2NetworkAclProtocol returns the protocol number AWS uses for the given Network ACL Entry protocol.
+func normalizeEc2NetworkAclProtocol(protocol string) string {
+	protocol = strings.ToLower(protocol)
+	if number, ok := ec2NetworkAclProtocolNumbers[protocol]; ok {
+		return number
+	}
+	return protocol
+}
+
+// key returns the rule number and direction of the entry, which identify it within a Network ACL.
+func (e ec2NetworkAclEntry) key() string {
+	return fmt.Sprintf("%s/%d", e.direction(), e.RuleNumber)
+}
+
+// direction returns the direction of the traffic the entry applies to, either ingress or egress.
+func (e ec2NetworkAclEntry) direction() string {
+	if e.Egress {
+		return "egress"
+	}
+	return "ingress"
+}
+
+// hasPorts returns whether the protocol of the entry has ports.
+func (e ec2NetworkAclEntry) hasPorts() bool {
+	return e.Protocol == "6" || e.Protocol == "17"
+}
+
+// hasIcmpTypeCode returns whether the protocol of the entry has ICMP types and codes.
+func (e ec2NetworkAclEntry) hasIcmpTypeCode() bool {
+	return e.Protocol == "1" || e.Protocol == "58"
+}
+
+// normalized returns the entry with the ports and ICMP type and code its protocol ignores set to zero.
+func (e ec2NetworkAclEntry) normalized() ec2NetworkAclEntry {
+	if !e.hasPorts() {
+		e.FromPort = 0
+		e.ToPort = 0
+	}
+	if !e.hasIcmpTypeCode() {
+		e.IcmpType = 0
+		e.IcmpCode = 0
+	}
+	return e
+}
+
+// flatten returns the relevant attributes of the entry, for planned_changes.
+func (e ec2NetworkAclEntry) flatten() map[string]string {
+	m := map[string]string{
+		"rule_number": strconv.FormatInt(e.RuleNumber, 10),
+		"egress":      strconv.FormatBool(e.Egress),
+		"protocol":    e.Protocol,
+		"rule_action": e.RuleAction,
+	}
+
+	if e.CidrBlock != "" {
+		m["cidr_block"] = e.CidrBlock
+	}
+	if e.Ipv6CidrBlock != "" {
+		m["ipv6_cidr_block"] = e.Ipv6CidrBlock
+	}
+	if e.hasPorts() {
+		m["from_port"] = strconv.FormatInt(e.FromPort, 10)
+		m["to_port"] = strconv.FormatInt(e.ToPort, 10)
+	}
+	if e.hasIcmpTypeCode() {
+		m["icmp_type"] = strconv.FormatInt(e.IcmpType, 10)
+		m["icmp_code"] = strconv.FormatInt(e.IcmpCode, 10)
+	}
+
+	return m
+}
+
+// expandEc2NetworkAclEntryFlattened returns the entry flattened by ec2NetworkAclEntry.flatten.
+func expandEc2NetworkAclEntryFlattened(m map[string]string) ec2NetworkAclEntry {
+	parse := func(k string) int64 {
+		i, _ := strconv.ParseInt(m[k], 10, 64)
+		return i
+	}
+
+	return ec2NetworkAclEntry{
+		RuleNumber:    parse("rule_number"),
+		Egress:        m["egress"] == "true",
+		Protocol:      m["protocol"],
+		RuleAction:    m["rule_action"],
+		CidrBlock:     m["cidr_block"],
+		Ipv6CidrBlock: m["ipv6_cidr_block"],
+		FromPort:      parse("from_port"),
+		ToPort:        parse("to_port"),
+		IcmpType:      parse("icmp_type"),
+		IcmpCode:      parse("icmp_code"),
+	}
+}
+
+// createInput returns the input creating the entry in the given Network ACL.
+func (e ec2NetworkAclEntry) createInput(networkAclID string) *ec2.CreateNetworkAclEntryInput {
+	input := &ec2.CreateNetworkAclEntryInput{
+		NetworkAclId: aws.String(networkAclID),
+		RuleNumber:   aws.Int64(e.RuleNumber),
+		Egress:       aws.Bool(e.Egress),
+		Protocol:     aws.String(e.Protocol),
+		RuleAction:   aws.String(e.RuleAction),
+	}
+
+	if e.CidrBlock != "" {
+		input.CidrBlock = aws.String(e.CidrBlock)
+	}
+	if e.Ipv6CidrBlock != "" {
+		input.Ipv6CidrBlock = aws.String(e.Ipv6CidrBlock)
+	}
+	if e.hasPorts() {
+		input.PortRange = &ec2.PortRange{From: aws.Int64(e.FromPort), To: aws.Int64(e.ToPort)}
+	}
+	if e.hasIcmpTypeCode() {
+		input.IcmpTypeCode = &ec2.IcmpTypeCode{Type: aws.Int64(e.IcmpType), Code: aws.Int64(e.IcmpCode)}
+	}
+
+	return input
+}
+
+// replaceInput returns the input replacing the entry with the same rule number and direction in the given Network
+// ACL with the entry.
+func (e ec2NetworkAclEntry) replaceInput(networkAclID string) *ec2.ReplaceNetworkAclEntryInput {
+	input := e.createInput(networkAclID)
+
+	return &ec2.ReplaceNetworkAclEntryInput{
+		NetworkAclId:  input.NetworkAclId,
+		RuleNumber:    input.RuleNumber,
+		Egress:        input.Egress,
+		Protocol:      input.Protocol,
+		RuleAction:    input.RuleAction,
+		CidrBlock:     input.CidrBlock,
+		Ipv6CidrBlock: input.Ipv6CidrBlock,
+		PortRange:     input.PortRange,
+		IcmpTypeCode:  input.IcmpTypeCode,
+	}
+}
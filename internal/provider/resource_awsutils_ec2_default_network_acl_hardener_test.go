@@ -0,0 +1,456 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testEc2DefaultNetworkAclEntries returns the entries of a new default Network ACL, allowing all IPv4 traffic.
+func testEc2DefaultNetworkAclEntries() []*ec2.NetworkAclEntry {
+	var entries []*ec2.NetworkAclEntry
+	for _, egress := range []bool{false, true} {
+		entries = append(entries,
+			&ec2.NetworkAclEntry{RuleNumber: aws.Int64(100), Egress: aws.Bool(egress), Protocol: aws.String("-1"), RuleAction: aws.String("allow"), CidrBlock: aws.String("0.0.0.0/0")},
+			&ec2.NetworkAclEntry{RuleNumber: aws.Int64(32767), Egress: aws.Bool(egress), Protocol: aws.String("-1"), RuleAction: aws.String("deny"), CidrBlock: aws.String("0.0.0.0/0")},
+		)
+	}
+	return entries
+}
+
+// testEc2DualStackDefaultNetworkAclEntries returns the entries of a new default Network ACL of a VPC with an IPv6
+// CIDR block, allowing all IPv4 and IPv6 traffic.
+func testEc2DualStackDefaultNetworkAclEntries() []*ec2.NetworkAclEntry {
+	entries := testEc2DefaultNetworkAclEntries()
+	for _, egress := range []bool{false, true} {
+		entries = append(entries,
+			&ec2.NetworkAclEntry{RuleNumber: aws.Int64(101), Egress: aws.Bool(egress), Protocol: aws.String("-1"), RuleAction: aws.String("allow"), Ipv6CidrBlock: aws.String("::/0")},
+			&ec2.NetworkAclEntry{RuleNumber: aws.Int64(32768), Egress: aws.Bool(egress), Protocol: aws.String("-1"), RuleAction: aws.String("deny"), Ipv6CidrBlock: aws.String("::/0")},
+		)
+	}
+	return entries
+}
+
+func TestEc2NetworkAclEntryChanges(t *testing.T) {
+	https := ec2NetworkAclEntry{RuleNumber: 100, Protocol: "6", RuleAction: "allow", CidrBlock: "10.0.0.0/8", FromPort: 443, ToPort: 443}
+	ephemeral := ec2NetworkAclEntry{RuleNumber: 110, Egress: true, Protocol: "6", RuleAction: "allow", CidrBlock: "10.0.0.0/8", FromPort: 1024, ToPort: 65535}
+
+	testCases := []struct {
+		Name     string
+		Entries  []*ec2.NetworkAclEntry
+		Desired  []ec2NetworkAclEntry
+		Expected []*plannedChange
+	}{
+		{
+			Name:    "allow-all entries",
+			Entries: testEc2DefaultNetworkAclEntries(),
+			Desired: []ec2NetworkAclEntry{ephemeral, https},
+			Expected: []*plannedChange{
+				{
+					ResourceID: "acl-00000001",
+					Action:     plannedChangeActionUpdate,
+					Reason:     "ingress entry 100 differs",
+					Before:     map[string]string{"rule_number": "100", "egress": "false", "protocol": "-1", "rule_action": "allow", "cidr_block": "0.0.0.0/0"},
+					After:      map[string]string{"rule_number": "100", "egress": "false", "protocol": "6", "rule_action": "allow", "cidr_block": "10.0.0.0/8", "from_port": "443", "to_port": "443"},
+				},
+				{
+					ResourceID: "acl-00000001",
+					Action:     plannedChangeActionCreate,
+					Reason:     "egress entry 110 is missing",
+					After:      map[string]string{"rule_number": "110", "egress": "true", "protocol": "6", "rule_action": "allow", "cidr_block": "10.0.0.0/8", "from_port": "1024", "to_port": "65535"},
+				},
+				{
+					ResourceID: "acl-00000001",
+					Action:     plannedChangeActionDelete,
+					Reason:     "egress entry 100 is not a rule",
+					Before:     map[string]string{"rule_number": "100", "egress": "true", "protocol": "-1", "rule_action": "allow", "cidr_block": "0.0.0.0/0"},
+				},
+			},
+		},
+		{
+			Name: "entries in place",
+			Entries: []*ec2.NetworkAclEntry{
+				{RuleNumber: aws.Int64(100), Egress: aws.Bool(false), Protocol: aws.String("6"), RuleAction: aws.String("allow"), CidrBlock: aws.String("10.0.0.0/8"), PortRange: &ec2.PortRange{From: aws.Int64(443), To: aws.Int64(443)}},
+				{RuleNumber: aws.Int64(32767), Egress: aws.Bool(false), Protocol: aws.String("-1"), RuleAction: aws.String("deny"), CidrBlock: aws.String("0.0.0.0/0")},
+			},
+			Desired: []ec2NetworkAclEntry{https},
+			Expected: []*plannedChange{
+				{
+					ResourceID: "acl-00000001",
+					Action:     plannedChangeActionNone,
+					Reason:     "entries are in place",
+				},
+			},
+		},
+		{
+			Name:    "no rules",
+			Entries: testEc2DefaultNetworkAclEntries()[:2],
+			Expected: []*plannedChange{
+				{
+					ResourceID: "acl-00000001",
+					Action:     plannedChangeActionDelete,
+					Reason:     "ingress entry 100 is not a rule",
+					Before:     map[string]string{"rule_number": "100", "egress": "false", "protocol": "-1", "rule_action": "allow", "cidr_block": "0.0.0.0/0"},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := ec2NetworkAclEntryChanges("acl-00000001", testCase.Entries, testCase.Desired)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestExpandEc2NetworkAclEntries(t *testing.T) {
+	rule := func(ruleNumber int, protocol, cidrBlock, ipv6CidrBlock string) map[string]interface{} {
+		return map[string]interface{}{
+			"rule_number":     ruleNumber,
+			"egress":          false,
+			"protocol":        protocol,
+			"rule_action":     "allow",
+			"cidr_block":      cidrBlock,
+			"ipv6_cidr_block": ipv6CidrBlock,
+			"from_port":       22,
+			"to_port":         22,
+			"icmp_type":       -1,
+			"icmp_code":       -1,
+		}
+	}
+
+	entries, err := expandEc2NetworkAclEntries([]interface{}{rule(100, "TCP", "10.0.0.0/8", ""), rule(110, "icmp", "10.0.0.0/8", "")})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []ec2NetworkAclEntry{
+		{RuleNumber: 100, Protocol: "6", RuleAction: "allow", CidrBlock: "10.0.0.0/8", FromPort: 22, ToPort: 22},
+		{RuleNumber: 110, Protocol: "1", RuleAction: "allow", CidrBlock: "10.0.0.0/8", IcmpType: -1, IcmpCode: -1},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("got %v, expected %v", entries, expected)
+	}
+
+	testCases := []struct {
+		Name  string
+		Rules []interface{}
+		Error string
+	}{
+		{
+			Name:  "no CIDR block",
+			Rules: []interface{}{rule(100, "tcp", "", "")},
+			Error: "exactly one of cidr_block and ipv6_cidr_block",
+		},
+		{
+			Name:  "both CIDR blocks",
+			Rules: []interface{}{rule(100, "tcp", "10.0.0.0/8", "::/0")},
+			Error: "exactly one of cidr_block and ipv6_cidr_block",
+		},
+		{
+			Name:  "duplicate rule number",
+			Rules: []interface{}{rule(100, "tcp", "10.0.0.0/8", ""), rule(100, "udp", "10.0.0.0/8", "")},
+			Error: "duplicate rule number 100 for the ingress traffic",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := expandEc2NetworkAclEntries(testCase.Rules)
+
+			if err == nil || !strings.Contains(err.Error(), testCase.Error) {
+				t.Errorf("got error %v, expected %q", err, testCase.Error)
+			}
+		})
+	}
+}
+
+// testEc2DefaultNetworkAclConn returns an EC2 client answering with a single VPC whose default Network ACL, with the
+// given entries, is associated with a subnet, and recording the entries created, replaced and deleted.
+func testEc2DefaultNetworkAclConn(t *testing.T, entries *[]*ec2.NetworkAclEntry, calls *[]string) *ec2.EC2 {
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeVpcsOutput:
+			output.Vpcs = []*ec2.Vpc{{VpcId: aws.String("vpc-00000001")}}
+		case *ec2.DescribeNetworkAclsOutput:
+			output.NetworkAcls = []*ec2.NetworkAcl{{
+				NetworkAclId: aws.String("acl-00000001"),
+				VpcId:        aws.String("vpc-00000001"),
+				IsDefault:    aws.Bool(true),
+				Associations: []*ec2.NetworkAclAssociation{{SubnetId: aws.String("subnet-00000001")}},
+				Entries:      *entries,
+			}}
+		case *ec2.CreateNetworkAclEntryOutput:
+			input := r.Params.(*ec2.CreateNetworkAclEntryInput)
+			*calls = append(*calls, "create "+ec2NetworkAclEntry{RuleNumber: aws.Int64Value(input.RuleNumber), Egress: aws.BoolValue(input.Egress)}.key())
+			*entries = append(*entries, &ec2.NetworkAclEntry{
+				RuleNumber:    input.RuleNumber,
+				Egress:        input.Egress,
+				Protocol:      input.Protocol,
+				RuleAction:    input.RuleAction,
+				CidrBlock:     input.CidrBlock,
+				Ipv6CidrBlock: input.Ipv6CidrBlock,
+				PortRange:     input.PortRange,
+			})
+		case *ec2.ReplaceNetworkAclEntryOutput:
+			input := r.Params.(*ec2.ReplaceNetworkAclEntryInput)
+			*calls = append(*calls, "replace "+ec2NetworkAclEntry{RuleNumber: aws.Int64Value(input.RuleNumber), Egress: aws.BoolValue(input.Egress)}.key())
+			for _, entry := range *entries {
+				if aws.Int64Value(entry.RuleNumber) == aws.Int64Value(input.RuleNumber) && aws.BoolValue(entry.Egress) == aws.BoolValue(input.Egress) {
+					entry.Protocol = input.Protocol
+					entry.RuleAction = input.RuleAction
+					entry.CidrBlock = input.CidrBlock
+					entry.Ipv6CidrBlock = input.Ipv6CidrBlock
+					entry.PortRange = input.PortRange
+				}
+			}
+		case *ec2.DeleteNetworkAclEntryOutput:
+			input := r.Params.(*ec2.DeleteNetworkAclEntryInput)
+			*calls = append(*calls, "delete "+ec2NetworkAclEntry{RuleNumber: aws.Int64Value(input.RuleNumber), Egress: aws.BoolValue(input.Egress)}.key())
+			kept := (*entries)[:0]
+			for _, entry := range *entries {
+				if aws.Int64Value(entry.RuleNumber) != aws.Int64Value(input.RuleNumber) || aws.BoolValue(entry.Egress) != aws.BoolValue(input.Egress) {
+					kept = append(kept, entry)
+				}
+			}
+			*entries = kept
+		}
+	})
+
+	return conn
+}
+
+func TestResourceAwsEc2DefaultNetworkAclHardenerCreateAndDelete(t *testing.T) {
+	entries := testEc2DefaultNetworkAclEntries()
+	var calls []string
+	client := &AWSClient{ec2conn: testEc2DefaultNetworkAclConn(t, &entries, &calls), region: "us-east-1"}
+
+	d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2DefaultNetworkAclHardener().Schema, map[string]interface{}{
+		"rule": []interface{}{
+			map[string]interface{}{"rule_number": 100, "protocol": "tcp", "rule_action": "allow", "cidr_block": "10.0.0.0/8", "from_port": 443, "to_port": 443},
+		},
+	})
+
+	diags := resourceAwsEc2DefaultNetworkAclHardenerCreate(context.Background(), d, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if len(diags) != 1 || !strings.Contains(diags[0].Detail, "subnet-00000001") {
+		t.Errorf("got diagnostics %v, expected a warning about subnet-00000001", diags)
+	}
+
+	expectedCalls := []string{"replace ingress/100", "delete egress/100"}
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Errorf("got calls %v, expected %v", calls, expectedCalls)
+	}
+
+	if got := d.Get("associated_subnet_ids").(map[string]interface{}); got["acl-00000001"] != "subnet-00000001" {
+		t.Errorf("got associated_subnet_ids %v, expected subnet-00000001", got)
+	}
+
+	originals := d.Get("original_network_acls").([]interface{})
+	if len(originals) != 1 || len(originals[0].(map[string]interface{})["entry"].([]interface{})) != 2 {
+		t.Fatalf("got original_network_acls %v, expected the two allow-all entries", originals)
+	}
+
+	// Applying again is a no-op, and keeps the original entries.
+	calls = nil
+	if diags := resourceAwsEc2DefaultNetworkAclHardenerUpdate(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if len(calls) != 0 {
+		t.Errorf("got calls %v, expected none", calls)
+	}
+
+	if got := d.Get("original_network_acls").([]interface{}); !reflect.DeepEqual(got, originals) {
+		t.Errorf("got original_network_acls %v, expected %v", got, originals)
+	}
+
+	if diags := resourceAwsEc2DefaultNetworkAclHardenerDelete(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	expectedCalls = []string{"replace ingress/100", "create egress/100"}
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Errorf("got calls %v, expected %v", calls, expectedCalls)
+	}
+
+	if got := ec2NetworkAclEntriesFromApi(entries); !reflect.DeepEqual(got, ec2NetworkAclEntriesFromApi(testEc2DefaultNetworkAclEntries())) {
+		t.Errorf("got entries %v after destroy, expected the allow-all entries", got)
+	}
+}
+
+func TestHardenEc2DefaultNetworkAclsConfirmationToken(t *testing.T) {
+	vpcIDs := []string{"vpc-00000001"}
+	var deleted []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeVpcsOutput:
+			for _, vpcID := range vpcIDs {
+				output.Vpcs = append(output.Vpcs, &ec2.Vpc{VpcId: aws.String(vpcID)})
+			}
+		case *ec2.DescribeNetworkAclsOutput:
+			for _, vpcID := range vpcIDs {
+				output.NetworkAcls = append(output.NetworkAcls, &ec2.NetworkAcl{
+					NetworkAclId: aws.String("acl-" + vpcID[len("vpc-"):]),
+					VpcId:        aws.String(vpcID),
+					IsDefault:    aws.Bool(true),
+					Entries:      testEc2DefaultNetworkAclEntries(),
+				})
+			}
+		case *ec2.DeleteNetworkAclEntryOutput:
+			input := r.Params.(*ec2.DeleteNetworkAclEntryInput)
+			deleted = append(deleted, aws.StringValue(input.NetworkAclId)+" "+ec2NetworkAclEntry{RuleNumber: aws.Int64Value(input.RuleNumber), Egress: aws.BoolValue(input.Egress)}.key())
+		}
+	})
+
+	harden := func(token string) *schema.ResourceData {
+		d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2DefaultNetworkAclHardener().Schema, map[string]interface{}{
+			"filter": []interface{}{
+				map[string]interface{}{"name": "tag:Environment", "values": []interface{}{"production"}},
+			},
+			"require_confirmation_token": true,
+			"confirmation_token":         token,
+		})
+
+		if _, err := hardenEc2DefaultNetworkAcls(d, &AWSClient{ec2conn: conn, region: "us-east-1"}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return d
+	}
+
+	d := harden("")
+	token := d.Get("expected_confirmation_token").(string)
+	if len(deleted) != 0 {
+		t.Fatalf("got deleted entries %v before confirmation, expected none", deleted)
+	}
+	if got := d.Get("original_network_acls").([]interface{}); len(got) != 0 {
+		t.Errorf("got original_network_acls %v before confirmation, expected none", got)
+	}
+
+	// Once the selection changes, the token no longer confirms the changes.
+	vpcIDs = append(vpcIDs, "vpc-00000002")
+	d = harden(token)
+	if len(deleted) != 0 {
+		t.Fatalf("got deleted entries %v with the token of another selection, expected none", deleted)
+	}
+	if d.Get("expected_confirmation_token").(string) == token {
+		t.Errorf("expected the token to change with the selection")
+	}
+
+	d = harden(d.Get("expected_confirmation_token").(string))
+	expected := []string{"acl-00000001 ingress/100", "acl-00000001 egress/100", "acl-00000002 ingress/100", "acl-00000002 egress/100"}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("got deleted entries %v, expected %v", deleted, expected)
+	}
+	if got := d.Get("original_network_acls").([]interface{}); len(got) != 2 {
+		t.Errorf("got original_network_acls %v, expected both Network ACLs", got)
+	}
+}
+
+func TestResourceAwsEc2DefaultNetworkAclHardenerDualStack(t *testing.T) {
+	entries := testEc2DualStackDefaultNetworkAclEntries()
+	var calls []string
+	client := &AWSClient{ec2conn: testEc2DefaultNetworkAclConn(t, &entries, &calls), region: "us-east-1"}
+
+	d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2DefaultNetworkAclHardener().Schema, map[string]interface{}{
+		"rule": []interface{}{
+			map[string]interface{}{"rule_number": 100, "protocol": "tcp", "rule_action": "allow", "cidr_block": "10.0.0.0/8", "from_port": 443, "to_port": 443},
+		},
+	})
+
+	if diags := resourceAwsEc2DefaultNetworkAclHardenerCreate(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	// The IPv6 deny-all entries, numbered 32768, are neither changed nor recorded.
+	expectedCalls := []string{"replace ingress/100", "delete ingress/101", "delete egress/100", "delete egress/101"}
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Errorf("got calls %v, expected %v", calls, expectedCalls)
+	}
+
+	originals := d.Get("original_network_acls").([]interface{})
+	if len(originals) != 1 || len(originals[0].(map[string]interface{})["entry"].([]interface{})) != 4 {
+		t.Fatalf("got original_network_acls %v, expected the four allow-all entries", originals)
+	}
+
+	// Applying again is a no-op.
+	calls = nil
+	if diags := resourceAwsEc2DefaultNetworkAclHardenerUpdate(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if len(calls) != 0 {
+		t.Errorf("got calls %v, expected none", calls)
+	}
+
+	if diags := resourceAwsEc2DefaultNetworkAclHardenerDelete(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if got := ec2NetworkAclEntriesFromApi(entries); !reflect.DeepEqual(got, ec2NetworkAclEntriesFromApi(testEc2DualStackDefaultNetworkAclEntries())) {
+		t.Errorf("got entries %v after destroy, expected the allow-all entries", got)
+	}
+}
+
+func TestResourceAwsEc2DefaultNetworkAclHardenerCreatePartialFailure(t *testing.T) {
+	var calls []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeVpcsOutput:
+			output.Vpcs = []*ec2.Vpc{{VpcId: aws.String("vpc-00000001")}}
+		case *ec2.DescribeNetworkAclsOutput:
+			output.NetworkAcls = []*ec2.NetworkAcl{{
+				NetworkAclId: aws.String("acl-00000001"),
+				VpcId:        aws.String("vpc-00000001"),
+				IsDefault:    aws.Bool(true),
+				Entries:      testEc2DefaultNetworkAclEntries(),
+			}}
+		case *ec2.ReplaceNetworkAclEntryOutput:
+			calls = append(calls, "replace")
+		case *ec2.DeleteNetworkAclEntryOutput:
+			r.Error = awserr.New("UnauthorizedOperation", "You are not authorized to perform this operation.", nil)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2DefaultNetworkAclHardener().Schema, map[string]interface{}{
+		"rule": []interface{}{
+			map[string]interface{}{"rule_number": 100, "protocol": "tcp", "rule_action": "allow", "cidr_block": "10.0.0.0/8", "from_port": 443, "to_port": 443},
+		},
+	})
+
+	diags := resourceAwsEc2DefaultNetworkAclHardenerCreate(context.Background(), d, &AWSClient{ec2conn: conn, region: "us-east-1"})
+	if !diags.HasError() {
+		t.Fatalf("got %v, expected an error deleting the egress entry", diags)
+	}
+
+	if expected := []string{"replace"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("got calls %v, expected %v", calls, expected)
+	}
+
+	// The original entries of the Network ACL changed before the error are recorded in the state saved with the
+	// tainted resource, so that destroying it restores them.
+	if d.Id() == "" {
+		t.Errorf("expected the ID to be set")
+	}
+	originals := d.Get("original_network_acls").([]interface{})
+	if len(originals) != 1 || len(originals[0].(map[string]interface{})["entry"].([]interface{})) != 2 {
+		t.Errorf("got original_network_acls %v, expected the two allow-all entries", originals)
+	}
+}
@@ -0,0 +1,254 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/hashicorp/aws-sdk-go-base/tfawserr"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// errCodeDefaultSubnetAlreadyExistsInAvailabilityZone is returned by CreateDefaultSubnet when the Availability Zone
+// already has a default Subnet.
+const errCodeDefaultSubnetAlreadyExistsInAvailabilityZone = "DefaultSubnetAlreadyExistsInAvailabilityZone"
+
+func resourceAwsUtilsEc2DefaultVpcRecreate() *schema.Resource {
+	return &schema.Resource{
+		Description: `Recreates the default VPC of the configured region, along with a default Subnet in each of its Availability
+Zones, for tools which require one to exist. This is the inverse of ` + "`awsutils_default_vpc_deletion`" + `, and the two
+should not be used for the same region.
+
+If a default VPC already exists, it is adopted instead of failing, and only the default Subnets missing from it are
+created. Nothing is deleted when ` + "`terraform destroy`" + ` is run unless ` + "`delete_on_destroy`" + ` is set, in which
+case the default VPC is deleted again along with its Subnets and Internet Gateway, but only if it was created by this
+resource.`,
+		Create:        resourceAwsEc2DefaultVpcRecreateCreate,
+		Read:          resourceAwsEc2DefaultVpcRecreateRead,
+		Update:        resourceAwsEc2DefaultVpcRecreateUpdate,
+		Delete:        resourceAwsEc2DefaultVpcRecreateDelete,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"create_default_subnets": {
+				Description: "Whether a default Subnet should be created in each Availability Zone missing one.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			"delete_on_destroy": {
+				Description: "Whether the default VPC should be deleted when this resource is destroyed, if it was created by this resource.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"vpc_id": {
+				Description: "The ID of the default VPC.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"created": {
+				Description: "Whether the default VPC was created by this resource, rather than already existing.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"default_subnet_ids": {
+				Description: "The IDs of the default Subnets of the default VPC, keyed by Availability Zone.",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"created_subnet_ids": {
+				Description: "The IDs of the default Subnets created by this resource.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceAwsEc2DefaultVpcRecreateCreate(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*AWSClient).ec2conn
+
+	vpc, err := finder.VpcDefault(conn)
+	if err != nil {
+		return fmt.Errorf("error reading EC2 default VPC: %w", err)
+	}
+
+	if vpc != nil {
+		log.Printf("[INFO] EC2 default VPC (%s) already exists, adopting it", aws.StringValue(vpc.VpcId))
+		if err := d.Set("created", false); err != nil {
+			return fmt.Errorf("error setting created: %w", err)
+		}
+	} else {
+		log.Printf("[DEBUG] Creating EC2 default VPC")
+		output, err := conn.CreateDefaultVpc(&ec2.CreateDefaultVpcInput{})
+		if err != nil {
+			return fmt.Errorf("error creating EC2 default VPC: %w", err)
+		}
+
+		vpc = output.Vpc
+		if err := d.Set("created", true); err != nil {
+			return fmt.Errorf("error setting created: %w", err)
+		}
+	}
+
+	d.SetId(aws.StringValue(vpc.VpcId))
+
+	if err := ensureEc2DefaultSubnets(d, meta); err != nil {
+		return err
+	}
+
+	return resourceAwsEc2DefaultVpcRecreateRead(d, meta)
+}
+
+func resourceAwsEc2DefaultVpcRecreateRead(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*AWSClient).ec2conn
+
+	vpc, err := finder.VpcDefault(conn)
+	if err != nil {
+		return fmt.Errorf("error reading EC2 default VPC: %w", err)
+	}
+
+	if vpc == nil || aws.StringValue(vpc.VpcId) != d.Id() {
+		if d.IsNewResource() {
+			return fmt.Errorf("error reading EC2 default VPC (%s): not found", d.Id())
+		}
+
+		log.Printf("[WARN] EC2 default VPC (%s) no longer exists, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	subnets, err := finder.Subnets(conn, &ec2.DescribeSubnetsInput{
+		Filters: buildEC2AttributeFilterList(map[string]string{
+			"vpc-id":         d.Id(),
+			"default-for-az": "true",
+		}),
+	}, 0)
+
+	if err != nil {
+		return fmt.Errorf("error reading EC2 Subnets for VPC (%s): %w", d.Id(), err)
+	}
+
+	subnetIDs := make(map[string]string, len(subnets))
+	for _, subnet := range subnets {
+		subnetIDs[aws.StringValue(subnet.AvailabilityZone)] = aws.StringValue(subnet.SubnetId)
+	}
+
+	if err := d.Set("vpc_id", d.Id()); err != nil {
+		return fmt.Errorf("error setting vpc_id: %w", err)
+	}
+
+	if err := d.Set("default_subnet_ids", subnetIDs); err != nil {
+		return fmt.Errorf("error setting default_subnet_ids: %w", err)
+	}
+
+	return nil
+}
+
+func resourceAwsEc2DefaultVpcRecreateUpdate(d *schema.ResourceData, meta interface{}) error {
+	if d.HasChange("create_default_subnets") {
+		if err := ensureEc2DefaultSubnets(d, meta); err != nil {
+			return err
+		}
+	}
+
+	return resourceAwsEc2DefaultVpcRecreateRead(d, meta)
+}
+
+func resourceAwsEc2DefaultVpcRecreateDelete(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*AWSClient).ec2conn
+
+	if !d.Get("delete_on_destroy").(bool) {
+		log.Printf("[INFO] Removing EC2 default VPC (%s) recreation state, keeping the VPC", d.Id())
+		return nil
+	}
+
+	if !d.Get("created").(bool) {
+		log.Printf("[INFO] EC2 default VPC (%s) was not created by this resource, keeping it", d.Id())
+		return nil
+	}
+
+	if err := deleteInternetGateway(conn, d.Id()); err != nil {
+		return err
+	}
+
+	if err := deleteSubnets(conn, d.Id()); err != nil {
+		return err
+	}
+
+	return deleteVpc(conn, d.Id())
+}
+
+// ensureEc2DefaultSubnets creates a default Subnet in each available Availability Zone of the region which is
+// missing one, if create_default_subnets is set, recording the created Subnet IDs in the given *schema.ResourceData
+// as they are created, so that those created before an error are recorded too.
+func ensureEc2DefaultSubnets(d *schema.ResourceData, meta interface{}) error {
+	conn := meta.(*AWSClient).ec2conn
+
+	if !d.Get("create_default_subnets").(bool) {
+		return nil
+	}
+
+	output, err := conn.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
+		Filters: buildEC2AttributeFilterList(map[string]string{
+			"state":     ec2.AvailabilityZoneStateAvailable,
+			"zone-type": "availability-zone",
+		}),
+	})
+	if err != nil {
+		return fmt.Errorf("error reading EC2 Availability Zones: %w", err)
+	}
+
+	subnets, err := finder.Subnets(conn, &ec2.DescribeSubnetsInput{
+		Filters: buildEC2AttributeFilterList(map[string]string{
+			"vpc-id":         d.Id(),
+			"default-for-az": "true",
+		}),
+	}, 0)
+
+	if err != nil {
+		return fmt.Errorf("error reading EC2 Subnets for VPC (%s): %w", d.Id(), err)
+	}
+
+	withDefaultSubnet := make(map[string]bool, len(subnets))
+	for _, subnet := range subnets {
+		withDefaultSubnet[aws.StringValue(subnet.AvailabilityZone)] = true
+	}
+
+	var zones []string
+	for _, zone := range output.AvailabilityZones {
+		if name := aws.StringValue(zone.ZoneName); !withDefaultSubnet[name] {
+			zones = append(zones, name)
+		}
+	}
+	sort.Strings(zones)
+
+	createdSubnetIDs := ExpandStringSliceofPointers(ExpandStringList(d.Get("created_subnet_ids").([]interface{})))
+
+	for _, zone := range zones {
+		log.Printf("[DEBUG] Creating EC2 default Subnet in Availability Zone (%s)", zone)
+		output, err := conn.CreateDefaultSubnet(&ec2.CreateDefaultSubnetInput{
+			AvailabilityZone: aws.String(zone),
+		})
+
+		if tfawserr.ErrCodeEquals(err, errCodeDefaultSubnetAlreadyExistsInAvailabilityZone) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("error creating EC2 default Subnet in Availability Zone (%s): %w", zone, err)
+		}
+
+		createdSubnetIDs = append(createdSubnetIDs, aws.StringValue(output.Subnet.SubnetId))
+		if err := d.Set("created_subnet_ids", createdSubnetIDs); err != nil {
+			return fmt.Errorf("error setting created_subnet_ids: %w", err)
+		}
+	}
+
+	return nil
+}
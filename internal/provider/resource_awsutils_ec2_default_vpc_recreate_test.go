@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testEc2DefaultVpcRecreateConn returns an EC2 client holding the default VPC vpc-00000001, if defaultVpc is set,
+// in a region with the us-east-1a, us-east-1b and us-east-1c Availability Zones, the given Availability Zones having
+// a default Subnet. Creating the default Subnet of an Availability Zone fails with the error of createSubnetErrors,
+// if any. The names of the operations it was sent are recorded.
+func testEc2DefaultVpcRecreateConn(t *testing.T, defaultVpc bool, withDefaultSubnets []string, createSubnetErrors map[string]error, operations *[]string) *ec2.EC2 {
+	subnets := make(map[string]string)
+	for _, zone := range withDefaultSubnets {
+		subnets[zone] = "subnet-" + zone
+	}
+
+	return testEc2Conn(t, func(r *request.Request) {
+		*operations = append(*operations, r.Operation.Name)
+
+		switch output := r.Data.(type) {
+		case *ec2.DescribeVpcsOutput:
+			if defaultVpc {
+				output.Vpcs = []*ec2.Vpc{{VpcId: aws.String("vpc-00000001"), IsDefault: aws.Bool(true)}}
+			}
+		case *ec2.CreateDefaultVpcOutput:
+			defaultVpc = true
+			output.Vpc = &ec2.Vpc{VpcId: aws.String("vpc-00000001"), IsDefault: aws.Bool(true)}
+		case *ec2.DescribeAvailabilityZonesOutput:
+			for _, zone := range []string{"us-east-1a", "us-east-1b", "us-east-1c"} {
+				output.AvailabilityZones = append(output.AvailabilityZones, &ec2.AvailabilityZone{ZoneName: aws.String(zone)})
+			}
+		case *ec2.DescribeSubnetsOutput:
+			for zone, subnetID := range subnets {
+				output.Subnets = append(output.Subnets, &ec2.Subnet{SubnetId: aws.String(subnetID), AvailabilityZone: aws.String(zone)})
+			}
+		case *ec2.CreateDefaultSubnetOutput:
+			zone := aws.StringValue(r.Params.(*ec2.CreateDefaultSubnetInput).AvailabilityZone)
+			if err := createSubnetErrors[zone]; err != nil {
+				r.Error = err
+				return
+			}
+			subnets[zone] = "subnet-" + zone
+			output.Subnet = &ec2.Subnet{SubnetId: aws.String(subnets[zone]), AvailabilityZone: aws.String(zone)}
+		}
+	})
+}
+
+func TestResourceAwsEc2DefaultVpcRecreateCreateAdopt(t *testing.T) {
+	var operations []string
+	conn := testEc2DefaultVpcRecreateConn(t, true, []string{"us-east-1a"}, map[string]error{
+		// The default Subnet of us-east-1c was created since the Subnets were read.
+		"us-east-1c": awserr.New(errCodeDefaultSubnetAlreadyExistsInAvailabilityZone, "default subnet already exists", nil),
+	}, &operations)
+
+	d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2DefaultVpcRecreate().Schema, map[string]interface{}{})
+	if err := resourceAwsEc2DefaultVpcRecreateCreate(d, &AWSClient{ec2conn: conn}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, operation := range operations {
+		if operation == "CreateDefaultVpc" {
+			t.Errorf("got a CreateDefaultVpc request, expected the existing default VPC to be adopted")
+		}
+	}
+
+	if d.Id() != "vpc-00000001" {
+		t.Errorf("got ID %s, expected vpc-00000001", d.Id())
+	}
+	if d.Get("created").(bool) {
+		t.Errorf("got created true, expected false")
+	}
+
+	if got, expected := d.Get("created_subnet_ids").([]interface{}), []interface{}{"subnet-us-east-1b"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got created_subnet_ids %v, expected %v", got, expected)
+	}
+	expectedSubnetIDs := map[string]interface{}{
+		"us-east-1a": "subnet-us-east-1a",
+		"us-east-1b": "subnet-us-east-1b",
+	}
+	if got := d.Get("default_subnet_ids").(map[string]interface{}); !reflect.DeepEqual(got, expectedSubnetIDs) {
+		t.Errorf("got default_subnet_ids %v, expected %v", got, expectedSubnetIDs)
+	}
+}
+
+func TestResourceAwsEc2DefaultVpcRecreateCreatePartialFailure(t *testing.T) {
+	var operations []string
+	conn := testEc2DefaultVpcRecreateConn(t, false, nil, map[string]error{
+		"us-east-1b": awserr.New("UnauthorizedOperation", "You are not authorized to perform this operation.", nil),
+	}, &operations)
+
+	d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2DefaultVpcRecreate().Schema, map[string]interface{}{})
+	err := resourceAwsEc2DefaultVpcRecreateCreate(d, &AWSClient{ec2conn: conn})
+	if err == nil || !strings.Contains(err.Error(), "error creating EC2 default Subnet in Availability Zone (us-east-1b)") {
+		t.Fatalf("got error %v, expected an error creating the default Subnet of us-east-1b", err)
+	}
+
+	// The default VPC and Subnet created before the error are surfaced in the state saved with the error.
+	if d.Id() != "vpc-00000001" {
+		t.Errorf("got ID %s, expected vpc-00000001", d.Id())
+	}
+	if !d.Get("created").(bool) {
+		t.Errorf("got created false, expected true")
+	}
+	if got, expected := d.Get("created_subnet_ids").([]interface{}), []interface{}{"subnet-us-east-1a"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got created_subnet_ids %v, expected %v", got, expected)
+	}
+}
+
+func TestResourceAwsEc2DefaultVpcRecreateDelete(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		DeleteOnDestroy    bool
+		Created            bool
+		ExpectedOperations []string
+	}{
+		{
+			Name:    "created without delete_on_destroy",
+			Created: true,
+		},
+		{
+			Name:            "adopted with delete_on_destroy",
+			DeleteOnDestroy: true,
+		},
+		{
+			Name:            "created with delete_on_destroy",
+			DeleteOnDestroy: true,
+			Created:         true,
+			ExpectedOperations: []string{
+				"DescribeInternetGateways",
+				"DescribeSubnets",
+				"DeleteSubnet",
+				"DeleteVpc",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var operations []string
+			conn := testEc2DefaultVpcRecreateConn(t, true, []string{"us-east-1a"}, nil, &operations)
+
+			d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2DefaultVpcRecreate().Schema, map[string]interface{}{
+				"delete_on_destroy": testCase.DeleteOnDestroy,
+			})
+			d.SetId("vpc-00000001")
+			if err := d.Set("created", testCase.Created); err != nil {
+				t.Fatalf("error setting created: %s", err)
+			}
+
+			if err := resourceAwsEc2DefaultVpcRecreateDelete(d, &AWSClient{ec2conn: conn}); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(operations, testCase.ExpectedOperations) {
+				t.Errorf("got operations %v, expected %v", operations, testCase.ExpectedOperations)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceAwsUtilsEc2EbsSnapshotTaggerFromVolume() *schema.Resource {
+	return &schema.Resource{
+		Description: `Copies tags from the source EBS Volume of each EBS Snapshot onto the Snapshot, so that Snapshots carry the
+cost allocation tags of the Volume they were taken from.
+
+For each Snapshot owned by the current account matching the given filters, the tags listed in ` + "`tag_keys`" + ` are read
+from the Volume identified by its ` + "`volume_id`" + ` and created on the Snapshot. Tags the Snapshot already has are left
+untouched unless ` + "`overwrite`" + ` is set, so applying this resource repeatedly is a no-op once the tags are copied.
+
+The source Volume of a Snapshot may have been deleted since, or never exist for Snapshots copied from another one. Such
+Snapshots are skipped, unless ` + "`fallback_tags`" + ` is set, in which case the listed keys are copied from it instead.
+When ` + "`dry_run`" + ` is set, the tags to copy are reported in ` + "`planned_changes`" + ` but not created. When
+` + "`continue_on_error`" + ` is set, Snapshots which cannot be tagged are reported in ` + "`failed`" + ` and in an error returned after
+the remaining Snapshots are tagged. Destroying this resource does not remove the copied tags.`,
+		CreateContext: resourceAwsEc2EbsSnapshotTaggerFromVolumeCreate,
+		ReadContext:   resourceAwsEc2EbsSnapshotTaggerFromVolumeRead,
+		UpdateContext: resourceAwsEc2EbsSnapshotTaggerFromVolumeUpdate,
+		DeleteContext: resourceAwsEc2EbsSnapshotTaggerFromVolumeDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EBS Snapshots", func(d resourceDataGetter, meta interface{}) (int, []*ec2.Filter, error) {
+			snapshots, filters, _, err := findEc2SelectedEbsSnapshots(d, meta)
+			return len(snapshots), filters, err
+		}),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeSnapshot),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"tag_keys": {
+				Description: "The keys of the tags to copy from the source Volume.",
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringIsNotEmpty,
+				},
+			},
+			"overwrite": {
+				Description: "Whether tags the Snapshot already has with a different value should be overwritten.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"fallback_tags": {
+				Description: "The tags to copy, restricted to `tag_keys`, onto the Snapshots whose source Volume no longer exists.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"dry_run": {
+				Description: "Report the tags to copy without creating them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"warn_if_matches_over":    ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":    ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning": ec2MatchThresholdWarningSchema(),
+			"continue_on_error":       continueOnErrorSchema(),
+			"planned_changes":         plannedChangesSchema(),
+			"failed":                  failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2EbsSnapshotTaggerFromVolumeCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := tagEc2EbsSnapshotsFromVolumes(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2EbsSnapshotTaggerFromVolumeRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2EbsSnapshotTaggerFromVolumeRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2EbsSnapshotTaggerFromVolumeUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := tagEc2EbsSnapshotsFromVolumes(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2EbsSnapshotTaggerFromVolumeRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2EbsSnapshotTaggerFromVolumeDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// tagEc2EbsSnapshotsFromVolumes copies the configured tags from the source Volume of each of the selected Snapshots
+// onto the Snapshot, recording the outcome in the given *schema.ResourceData.
+// It returns the warning of warn_if_matches_over, if any.
+func tagEc2EbsSnapshotsFromVolumes(d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+	tagKeys := ExpandStringSliceofPointers(ExpandStringSet(d.Get("tag_keys").(*schema.Set)))
+	overwrite := d.Get("overwrite").(bool)
+
+	fallbackTags := make(map[string]string)
+	for k, v := range d.Get("fallback_tags").(map[string]interface{}) {
+		fallbackTags[k] = v.(string)
+	}
+
+	snapshots, filters, warnings, err := findEc2SelectedEbsSnapshots(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(snapshots), "EBS Snapshots", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return aws.StringValue(snapshots[i].SnapshotId) < aws.StringValue(snapshots[j].SnapshotId)
+	})
+
+	var volumeIDs []string
+	for _, snapshot := range snapshots {
+		if volumeID := aws.StringValue(snapshot.VolumeId); volumeID != "" {
+			volumeIDs = appendUniqueString(volumeIDs, volumeID)
+		}
+	}
+
+	volumes, err := finder.VolumesByID(conn, volumeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error reading EBS Volumes: %w", err)
+	}
+
+	volumesByID := make(map[string]*ec2.Volume, len(volumes))
+	for _, volume := range volumes {
+		volumesByID[aws.StringValue(volume.VolumeId)] = volume
+	}
+
+	changes := make([]*plannedChange, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		changes = append(changes, ebsSnapshotTagsFromVolumeChange(snapshot, volumesByID[aws.StringValue(snapshot.VolumeId)], tagKeys, fallbackTags, overwrite))
+	}
+
+	err = applyPlannedChanges(changes, d.Get("dry_run").(bool), d.Get("continue_on_error").(bool), func(change *plannedChange) error {
+		input := &ec2.CreateTagsInput{
+			Resources: aws.StringSlice([]string{change.ResourceID}),
+			Tags:      keyvaluetags.New(change.After).Ec2Tags(),
+		}
+
+		log.Printf("[DEBUG] Creating tags on EBS Snapshot (%s): %s", change.ResourceID, input)
+		if _, err := conn.CreateTags(input); err != nil {
+			return fmt.Errorf("error creating tags on EBS Snapshot (%s): %w", change.ResourceID, err)
+		}
+
+		return nil
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	return warnings, err
+}
+
+// findEc2SelectedEbsSnapshots reads the EBS Snapshots of the account selected by the resource with the given
+// resourceDataGetter, as findEc2SelectedVpcs does the VPCs.
+func findEc2SelectedEbsSnapshots(d resourceDataGetter, meta interface{}) ([]*ec2.Snapshot, []*ec2.Filter, diag.Diagnostics, error) {
+	input := &ec2.DescribeSnapshotsInput{
+		OwnerIds: aws.StringSlice([]string{ec2OwnerSelf}),
+	}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeSnapshot)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	input.SnapshotIds = ids
+	input.Filters = filters
+
+	snapshots, err := finder.Snapshots(meta.(*AWSClient).ec2conn, input, ec2FailIfMatchesOver(d))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading EBS Snapshots: %w", ec2FailIfMatchesOverError(err, input.Filters))
+	}
+
+	return snapshots, input.Filters, warnings, nil
+}
+
+// ebsSnapshotTagsFromVolumeChange returns the change copying the given tag keys from the given source Volume, or from
+// the fallback tags if it is nil, onto the given Snapshot. Tags the Snapshot already has are only overwritten if
+// overwrite is set.
+func ebsSnapshotTagsFromVolumeChange(snapshot *ec2.Snapshot, volume *ec2.Volume, tagKeys []string, fallbackTags map[string]string, overwrite bool) *plannedChange {
+	snapshotID := aws.StringValue(snapshot.SnapshotId)
+	change := &plannedChange{
+		ResourceID: snapshotID,
+		Action:     plannedChangeActionNone,
+	}
+
+	var source map[string]string
+	switch {
+	case volume != nil:
+		source = keyvaluetags.Ec2KeyValueTags(volume.Tags).IgnoreAws().Map()
+	case len(fallbackTags) > 0:
+		source = fallbackTags
+	default:
+		change.Reason = fmt.Sprintf("source Volume (%s) not found", aws.StringValue(snapshot.VolumeId))
+		return change
+	}
+
+	current := keyvaluetags.Ec2KeyValueTags(snapshot.Tags).Map()
+	before := make(map[string]string)
+	after := make(map[string]string)
+
+	for _, key := range tagKeys {
+		value, ok := source[key]
+		if !ok {
+			continue
+		}
+
+		if existing, ok := current[key]; ok {
+			if existing == value || !overwrite {
+				continue
+			}
+			before[key] = existing
+		}
+
+		after[key] = value
+	}
+
+	if len(after) == 0 {
+		change.Reason = "tags are in sync"
+		return change
+	}
+
+	change.Action = plannedChangeActionUpdate
+	change.Before = before
+	change.After = after
+
+	if volume != nil {
+		change.Reason = fmt.Sprintf("tags copied from source Volume (%s)", aws.StringValue(volume.VolumeId))
+	} else {
+		change.Reason = fmt.Sprintf("source Volume (%s) not found, tags copied from fallback_tags", aws.StringValue(snapshot.VolumeId))
+	}
+
+	return change
+}
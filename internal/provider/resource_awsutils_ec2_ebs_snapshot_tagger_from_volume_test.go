@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEbsSnapshotTagsFromVolumeChange(t *testing.T) {
+	volume := &ec2.Volume{
+		VolumeId: aws.String("vol-01234567"),
+		Tags: []*ec2.Tag{
+			{Key: aws.String("CostCenter"), Value: aws.String("1234")},
+			{Key: aws.String("Team"), Value: aws.String("platform")},
+			{Key: aws.String("Name"), Value: aws.String("data")},
+		},
+	}
+	tagKeys := []string{"CostCenter", "Team", "Environment"}
+
+	testCases := []struct {
+		Name           string
+		Snapshot       *ec2.Snapshot
+		Volume         *ec2.Volume
+		FallbackTags   map[string]string
+		Overwrite      bool
+		ExpectedAction string
+		ExpectedBefore map[string]string
+		ExpectedAfter  map[string]string
+	}{
+		{
+			Name: "untagged snapshot",
+			Snapshot: &ec2.Snapshot{
+				SnapshotId: aws.String("snap-01234567"),
+				VolumeId:   aws.String("vol-01234567"),
+			},
+			Volume:         volume,
+			ExpectedAction: plannedChangeActionUpdate,
+			ExpectedBefore: map[string]string{},
+			ExpectedAfter:  map[string]string{"CostCenter": "1234", "Team": "platform"},
+		},
+		{
+			Name: "in sync",
+			Snapshot: &ec2.Snapshot{
+				SnapshotId: aws.String("snap-01234567"),
+				VolumeId:   aws.String("vol-01234567"),
+				Tags: []*ec2.Tag{
+					{Key: aws.String("CostCenter"), Value: aws.String("1234")},
+					{Key: aws.String("Team"), Value: aws.String("platform")},
+				},
+			},
+			Volume:         volume,
+			ExpectedAction: plannedChangeActionNone,
+		},
+		{
+			Name: "existing tag kept",
+			Snapshot: &ec2.Snapshot{
+				SnapshotId: aws.String("snap-01234567"),
+				VolumeId:   aws.String("vol-01234567"),
+				Tags: []*ec2.Tag{
+					{Key: aws.String("CostCenter"), Value: aws.String("5678")},
+					{Key: aws.String("Team"), Value: aws.String("platform")},
+				},
+			},
+			Volume:         volume,
+			ExpectedAction: plannedChangeActionNone,
+		},
+		{
+			Name: "existing tag overwritten",
+			Snapshot: &ec2.Snapshot{
+				SnapshotId: aws.String("snap-01234567"),
+				VolumeId:   aws.String("vol-01234567"),
+				Tags: []*ec2.Tag{
+					{Key: aws.String("CostCenter"), Value: aws.String("5678")},
+				},
+			},
+			Volume:         volume,
+			Overwrite:      true,
+			ExpectedAction: plannedChangeActionUpdate,
+			ExpectedBefore: map[string]string{"CostCenter": "5678"},
+			ExpectedAfter:  map[string]string{"CostCenter": "1234", "Team": "platform"},
+		},
+		{
+			Name: "volume deleted",
+			Snapshot: &ec2.Snapshot{
+				SnapshotId: aws.String("snap-01234567"),
+				VolumeId:   aws.String("vol-89abcdef"),
+			},
+			ExpectedAction: plannedChangeActionNone,
+		},
+		{
+			Name: "volume deleted with fallback tags",
+			Snapshot: &ec2.Snapshot{
+				SnapshotId: aws.String("snap-01234567"),
+				VolumeId:   aws.String("vol-89abcdef"),
+			},
+			FallbackTags:   map[string]string{"Environment": "prod", "Owner": "ops"},
+			ExpectedAction: plannedChangeActionUpdate,
+			ExpectedBefore: map[string]string{},
+			ExpectedAfter:  map[string]string{"Environment": "prod"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := ebsSnapshotTagsFromVolumeChange(testCase.Snapshot, testCase.Volume, tagKeys, testCase.FallbackTags, testCase.Overwrite)
+
+			if got.Action != testCase.ExpectedAction {
+				t.Errorf("got action %s, expected %s", got.Action, testCase.ExpectedAction)
+			}
+
+			if !reflect.DeepEqual(got.Before, testCase.ExpectedBefore) {
+				t.Errorf("got before %v, expected %v", got.Before, testCase.ExpectedBefore)
+			}
+
+			if !reflect.DeepEqual(got.After, testCase.ExpectedAfter) {
+				t.Errorf("got after %v, expected %v", got.After, testCase.ExpectedAfter)
+			}
+		})
+	}
+}
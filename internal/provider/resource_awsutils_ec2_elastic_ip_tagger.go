@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceAwsUtilsEc2ElasticIpTagger() *schema.Resource {
+	return &schema.Resource{
+		Description: `Applies a set of tags to the Elastic IP addresses matching the given filters, such as the cost allocation
+tags of addresses allocated by other tools.
+
+The tags of ` + "`desired_tags`" + `, merged onto the provider's ` + "`default_tags`" + `, are created on each address which
+is missing any of them or has a different value, so applying this resource repeatedly is a no-op once the tags are in
+place. Other tags of the addresses are left untouched, as are tags with the reserved ` + "`aws:`" + ` prefix.
+
+VPC addresses are tagged by allocation ID. EC2-Classic addresses, which have no allocation ID and cannot be tagged,
+are reported as skipped. When ` + "`dry_run`" + ` is set, the tags to create are reported in ` + "`planned_changes`" + ` but
+not created. When ` + "`continue_on_error`" + ` is set, the addresses which cannot be tagged are reported in ` + "`failed`" + `
+and in an error returned after the remaining addresses are tagged. Destroying this resource does not remove the tags.`,
+		CreateContext: resourceAwsEc2ElasticIpTaggerCreate,
+		ReadContext:   resourceAwsEc2ElasticIpTaggerRead,
+		UpdateContext: resourceAwsEc2ElasticIpTaggerUpdate,
+		DeleteContext: resourceAwsEc2ElasticIpTaggerDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 Elastic IPs", func(d resourceDataGetter, meta interface{}) (int, []*ec2.Filter, error) {
+			addresses, filters, _, err := findEc2SelectedElasticIps(d, meta)
+			return len(addresses), filters, err
+		}),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeElasticIp),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"desired_tags": {
+				Description: "The tags every selected address must have, merged onto the provider's `default_tags`.",
+				Type:        schema.TypeMap,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"dry_run": {
+				Description: "Report the tags to create without creating them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"warn_if_matches_over":    ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":    ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning": ec2MatchThresholdWarningSchema(),
+			"continue_on_error":       continueOnErrorSchema(),
+			"planned_changes":         plannedChangesSchema(),
+			"failed":                  failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2ElasticIpTaggerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := tagEc2ElasticIps(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2ElasticIpTaggerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2ElasticIpTaggerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2ElasticIpTaggerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := tagEc2ElasticIps(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2ElasticIpTaggerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2ElasticIpTaggerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// tagEc2ElasticIps creates the desired tags on each of the selected Elastic IP addresses missing any of them,
+// recording the outcome in the given *schema.ResourceData.
+// It returns the warning of warn_if_matches_over, if any.
+func tagEc2ElasticIps(d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+	desired := keyvaluetags.New(mergeTagsWithDefaults(d.Get("desired_tags").(map[string]interface{}), providerDefaultTags(meta))).IgnoreAws().Map()
+
+	addresses, filters, warnings, err := findEc2SelectedElasticIps(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(addresses), "EC2 Elastic IPs", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	changes := make([]*plannedChange, 0, len(addresses))
+	for _, address := range addresses {
+		changes = append(changes, ec2ElasticIpTagsChange(address, desired))
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].ResourceID < changes[j].ResourceID
+	})
+
+	err = applyPlannedChangesInBatches(changes, d.Get("dry_run").(bool), d.Get("continue_on_error").(bool), ec2TagOperationBatchSize, func(change *plannedChange) string {
+		return keyvaluetags.New(change.After).String()
+	}, func(batch []*plannedChange) error {
+		resources := make([]string, 0, len(batch))
+		for _, change := range batch {
+			resources = append(resources, change.ResourceID)
+		}
+
+		input := &ec2.CreateTagsInput{
+			Resources: aws.StringSlice(resources),
+			Tags:      keyvaluetags.New(batch[0].After).Ec2Tags(),
+		}
+
+		log.Printf("[DEBUG] Creating tags on EC2 Elastic IPs: %s", input)
+		if _, err := conn.CreateTags(input); err != nil {
+			return fmt.Errorf("error creating tags on EC2 Elastic IPs (%v): %w", resources, err)
+		}
+
+		return nil
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	return warnings, err
+}
+
+// findEc2SelectedElasticIps reads the Elastic IP addresses selected by the resource with the given resourceDataGetter,
+// and returns them along with the filters they were selected with and the warnings of buildEC2Selection. The
+// addresses are returned in a single page, so they can only be counted once read.
+func findEc2SelectedElasticIps(d resourceDataGetter, meta interface{}) ([]*ec2.Address, []*ec2.Filter, diag.Diagnostics, error) {
+	input := &ec2.DescribeAddressesInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeElasticIp)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	input.AllocationIds = ids
+	input.Filters = filters
+
+	addresses, err := finder.Addresses(meta.(*AWSClient).ec2conn, input)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading EC2 Elastic IPs: %w", err)
+	}
+
+	return addresses, input.Filters, warnings, nil
+}
+
+// ec2ElasticIpTagsChange returns the change creating the given desired tags the given Elastic IP address is missing
+// or has a different value for.
+func ec2ElasticIpTagsChange(address *ec2.Address, desired map[string]string) *plannedChange {
+	change := &plannedChange{
+		ResourceID: aws.StringValue(address.AllocationId),
+		Action:     plannedChangeActionNone,
+	}
+
+	// EC2-Classic addresses are identified by their public IP instead.
+	if aws.StringValue(address.Domain) == ec2.DomainTypeStandard || change.ResourceID == "" {
+		change.ResourceID = aws.StringValue(address.PublicIp)
+		change.Reason = "EC2-Classic Elastic IPs cannot be tagged"
+		return change
+	}
+
+	current := keyvaluetags.Ec2KeyValueTags(address.Tags).Map()
+	before := make(map[string]string)
+	after := make(map[string]string)
+
+	for key, value := range desired {
+		if existing, ok := current[key]; ok {
+			if existing == value {
+				continue
+			}
+			before[key] = existing
+		}
+
+		after[key] = value
+	}
+
+	if len(after) == 0 {
+		change.Reason = "tags are in sync"
+		return change
+	}
+
+	change.Action = plannedChangeActionUpdate
+	change.Reason = "tags are missing or out of date"
+	change.Before = before
+	change.After = after
+
+	return change
+}
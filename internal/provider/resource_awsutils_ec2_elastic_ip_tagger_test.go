@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEc2ElasticIpTagsChange(t *testing.T) {
+	desired := map[string]string{
+		"CostCenter": "1234",
+		"Team":       "platform",
+	}
+
+	testCases := []struct {
+		Name     string
+		Address  *ec2.Address
+		Expected *plannedChange
+	}{
+		{
+			Name: "untagged",
+			Address: &ec2.Address{
+				AllocationId: aws.String("eipalloc-01234567"),
+				Domain:       aws.String(ec2.DomainTypeVpc),
+			},
+			Expected: &plannedChange{
+				ResourceID: "eipalloc-01234567",
+				Action:     plannedChangeActionUpdate,
+				Reason:     "tags are missing or out of date",
+				Before:     map[string]string{},
+				After:      map[string]string{"CostCenter": "1234", "Team": "platform"},
+			},
+		},
+		{
+			Name: "out of date",
+			Address: &ec2.Address{
+				AllocationId: aws.String("eipalloc-01234567"),
+				Domain:       aws.String(ec2.DomainTypeVpc),
+				Tags: []*ec2.Tag{
+					{Key: aws.String("CostCenter"), Value: aws.String("1234")},
+					{Key: aws.String("Team"), Value: aws.String("data")},
+					{Key: aws.String("Name"), Value: aws.String("nat")},
+				},
+			},
+			Expected: &plannedChange{
+				ResourceID: "eipalloc-01234567",
+				Action:     plannedChangeActionUpdate,
+				Reason:     "tags are missing or out of date",
+				Before:     map[string]string{"Team": "data"},
+				After:      map[string]string{"Team": "platform"},
+			},
+		},
+		{
+			Name: "in sync",
+			Address: &ec2.Address{
+				AllocationId: aws.String("eipalloc-01234567"),
+				Domain:       aws.String(ec2.DomainTypeVpc),
+				Tags: []*ec2.Tag{
+					{Key: aws.String("CostCenter"), Value: aws.String("1234")},
+					{Key: aws.String("Team"), Value: aws.String("platform")},
+				},
+			},
+			Expected: &plannedChange{
+				ResourceID: "eipalloc-01234567",
+				Action:     plannedChangeActionNone,
+				Reason:     "tags are in sync",
+			},
+		},
+		{
+			Name: "EC2-Classic",
+			Address: &ec2.Address{
+				PublicIp: aws.String("203.0.113.10"),
+				Domain:   aws.String(ec2.DomainTypeStandard),
+			},
+			Expected: &plannedChange{
+				ResourceID: "203.0.113.10",
+				Action:     plannedChangeActionNone,
+				Reason:     "EC2-Classic Elastic IPs cannot be tagged",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := ec2ElasticIpTagsChange(testCase.Address, desired)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
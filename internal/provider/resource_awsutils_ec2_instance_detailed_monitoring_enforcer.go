@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2MonitoringBatchSize is the maximum number of instances passed in a single MonitorInstances or
+// UnmonitorInstances call.
+const ec2MonitoringBatchSize = 100
+
+func resourceAwsUtilsEc2InstanceDetailedMonitoringEnforcer() *schema.Resource {
+	return &schema.Resource{
+		Description: `Enables the detailed, 1-minute, CloudWatch monitoring of the EC2 Instances matching the given filters which
+only have basic monitoring, or, with ` + "`monitoring`" + ` set to ` + "`disabled`" + `, disables it on the instances having it,
+for accounts where its cost is not wanted.
+
+Both running and stopped instances are changed, the monitoring of a stopped instance applying once it is started.
+Instances which are pending or stopping are left as they are until the next apply, as are the instances whose
+monitoring is already being enabled or disabled. Applying this resource repeatedly is a no-op once every selected
+instance has the desired monitoring.
+
+When ` + "`dry_run`" + ` is set, the changes are reported in ` + "`planned_changes`" + ` but not made. When
+` + "`continue_on_error`" + ` is set, the instances which cannot be changed are reported in ` + "`failed`" + ` and in
+an error returned after the remaining instances are changed. Destroying this resource does not revert the changes.`,
+		CreateContext: resourceAwsEc2InstanceDetailedMonitoringEnforcerCreate,
+		ReadContext:   resourceAwsEc2InstanceDetailedMonitoringEnforcerRead,
+		UpdateContext: resourceAwsEc2InstanceDetailedMonitoringEnforcerUpdate,
+		DeleteContext: resourceAwsEc2InstanceDetailedMonitoringEnforcerDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 Instances", countEc2SelectedInstances(ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopping, ec2.InstanceStateNameStopped)),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeInstance),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"monitoring": {
+				Description:  "The monitoring enforced on the selected instances: `enabled` for detailed monitoring, or `disabled` for basic monitoring only.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      ec2.MonitoringStateEnabled,
+				ValidateFunc: validation.StringInSlice([]string{ec2.MonitoringStateEnabled, ec2.MonitoringStateDisabled}, false),
+			},
+			"dry_run": {
+				Description: "Report the changes without making them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"changed_instance_ids": {
+				Description: "The IDs of the instances whose monitoring was changed, or would be when `dry_run` is set, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"warn_if_matches_over":    ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":    ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning": ec2MatchThresholdWarningSchema(),
+			"continue_on_error":       continueOnErrorSchema(),
+			"planned_changes":         plannedChangesSchema(),
+			"failed":                  failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2InstanceDetailedMonitoringEnforcerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := enforceEc2InstanceDetailedMonitoring(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2InstanceDetailedMonitoringEnforcerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2InstanceDetailedMonitoringEnforcerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2InstanceDetailedMonitoringEnforcerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := enforceEc2InstanceDetailedMonitoring(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2InstanceDetailedMonitoringEnforcerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2InstanceDetailedMonitoringEnforcerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// enforceEc2InstanceDetailedMonitoring enables or disables the detailed monitoring of the selected instances,
+// recording the outcome in the given *schema.ResourceData.
+// It returns the warning of warn_if_matches_over, if any.
+func enforceEc2InstanceDetailedMonitoring(ctx context.Context, d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+	monitoring := d.Get("monitoring").(string)
+
+	instances, filters, warnings, err := findEc2SelectedInstances(d, meta, ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopping, ec2.InstanceStateNameStopped)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(instances), "EC2 Instances", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	sort.Slice(instances, func(i, j int) bool {
+		return aws.StringValue(instances[i].InstanceId) < aws.StringValue(instances[j].InstanceId)
+	})
+
+	changes := make([]*plannedChange, 0, len(instances))
+	changedIDs := make([]string, 0)
+	for _, instance := range instances {
+		change := ec2InstanceDetailedMonitoringChange(instance, monitoring)
+		if change.Action != plannedChangeActionNone {
+			changedIDs = append(changedIDs, change.ResourceID)
+		}
+		changes = append(changes, change)
+	}
+
+	err = applyPlannedChangesInBatches(changes, d.Get("dry_run").(bool), d.Get("continue_on_error").(bool), ec2MonitoringBatchSize, func(change *plannedChange) string {
+		return change.After["monitoring"]
+	}, func(batch []*plannedChange) error {
+		instanceIDs := make([]string, 0, len(batch))
+		for _, change := range batch {
+			instanceIDs = append(instanceIDs, change.ResourceID)
+		}
+
+		if batch[0].After["monitoring"] == ec2.MonitoringStateEnabled {
+			log.Printf("[INFO] Enabling the detailed monitoring of EC2 Instances: %v", instanceIDs)
+			if _, err := conn.MonitorInstancesWithContext(ctx, &ec2.MonitorInstancesInput{InstanceIds: aws.StringSlice(instanceIDs)}); err != nil {
+				return fmt.Errorf("error enabling the detailed monitoring of EC2 Instances (%v): %w", instanceIDs, err)
+			}
+
+			return nil
+		}
+
+		log.Printf("[INFO] Disabling the detailed monitoring of EC2 Instances: %v", instanceIDs)
+		if _, err := conn.UnmonitorInstancesWithContext(ctx, &ec2.UnmonitorInstancesInput{InstanceIds: aws.StringSlice(instanceIDs)}); err != nil {
+			return fmt.Errorf("error disabling the detailed monitoring of EC2 Instances (%v): %w", instanceIDs, err)
+		}
+
+		return nil
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("changed_instance_ids", changedIDs); err != nil {
+		return nil, fmt.Errorf("error setting changed_instance_ids: %w", err)
+	}
+
+	return warnings, err
+}
+
+// ec2InstanceDetailedMonitoringChange returns the change giving the given instance the given monitoring, either
+// enabled or disabled.
+//
+// Running and stopped instances are changed when their monitoring is the opposite of the given one. Instances in
+// another state, and those whose monitoring is pending or disabling, are left as they are until the next apply.
+func ec2InstanceDetailedMonitoringChange(instance *ec2.Instance, monitoring string) *plannedChange {
+	var state, current string
+	if instance.State != nil {
+		state = aws.StringValue(instance.State.Name)
+	}
+	if instance.Monitoring != nil {
+		current = aws.StringValue(instance.Monitoring.State)
+	}
+
+	change := &plannedChange{
+		ResourceID: aws.StringValue(instance.InstanceId),
+		Action:     plannedChangeActionNone,
+		Before:     map[string]string{"state": state, "monitoring": current},
+	}
+
+	opposite := ec2.MonitoringStateDisabled
+	if monitoring == ec2.MonitoringStateDisabled {
+		opposite = ec2.MonitoringStateEnabled
+	}
+
+	switch {
+	case current == monitoring:
+		change.Reason = fmt.Sprintf("monitoring is already %s", monitoring)
+	case current != opposite:
+		change.Reason = fmt.Sprintf("monitoring is %s, retrying on the next apply", current)
+	case state != ec2.InstanceStateNameRunning && state != ec2.InstanceStateNameStopped:
+		change.Reason = fmt.Sprintf("%s, retrying on the next apply", state)
+	default:
+		change.Action = plannedChangeActionUpdate
+		change.Reason = fmt.Sprintf("monitoring is %s on a %s instance", current, state)
+		change.After = map[string]string{"state": state, "monitoring": monitoring}
+	}
+
+	return change
+}
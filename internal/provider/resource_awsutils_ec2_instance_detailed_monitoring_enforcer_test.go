@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2InstanceDetailedMonitoringChange(t *testing.T) {
+	instance := func(state, monitoring string) *ec2.Instance {
+		return &ec2.Instance{
+			InstanceId: aws.String("i-00000001"),
+			State:      &ec2.InstanceState{Name: aws.String(state)},
+			Monitoring: &ec2.Monitoring{State: aws.String(monitoring)},
+		}
+	}
+
+	testCases := []struct {
+		Name       string
+		Instance   *ec2.Instance
+		Monitoring string
+		Expected   *plannedChange
+	}{
+		{
+			Name:       "running with basic monitoring",
+			Instance:   instance(ec2.InstanceStateNameRunning, ec2.MonitoringStateDisabled),
+			Monitoring: ec2.MonitoringStateEnabled,
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionUpdate,
+				Reason:     "monitoring is disabled on a running instance",
+				Before:     map[string]string{"state": "running", "monitoring": "disabled"},
+				After:      map[string]string{"state": "running", "monitoring": "enabled"},
+			},
+		},
+		{
+			Name:       "stopped with basic monitoring",
+			Instance:   instance(ec2.InstanceStateNameStopped, ec2.MonitoringStateDisabled),
+			Monitoring: ec2.MonitoringStateEnabled,
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionUpdate,
+				Reason:     "monitoring is disabled on a stopped instance",
+				Before:     map[string]string{"state": "stopped", "monitoring": "disabled"},
+				After:      map[string]string{"state": "stopped", "monitoring": "enabled"},
+			},
+		},
+		{
+			Name:       "already enabled",
+			Instance:   instance(ec2.InstanceStateNameRunning, ec2.MonitoringStateEnabled),
+			Monitoring: ec2.MonitoringStateEnabled,
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionNone,
+				Reason:     "monitoring is already enabled",
+				Before:     map[string]string{"state": "running", "monitoring": "enabled"},
+			},
+		},
+		{
+			Name:       "being enabled",
+			Instance:   instance(ec2.InstanceStateNameRunning, ec2.MonitoringStatePending),
+			Monitoring: ec2.MonitoringStateEnabled,
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionNone,
+				Reason:     "monitoring is pending, retrying on the next apply",
+				Before:     map[string]string{"state": "running", "monitoring": "pending"},
+			},
+		},
+		{
+			Name:       "stopping",
+			Instance:   instance(ec2.InstanceStateNameStopping, ec2.MonitoringStateDisabled),
+			Monitoring: ec2.MonitoringStateEnabled,
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionNone,
+				Reason:     "stopping, retrying on the next apply",
+				Before:     map[string]string{"state": "stopping", "monitoring": "disabled"},
+			},
+		},
+		{
+			Name:       "disabling detailed monitoring",
+			Instance:   instance(ec2.InstanceStateNameStopped, ec2.MonitoringStateEnabled),
+			Monitoring: ec2.MonitoringStateDisabled,
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionUpdate,
+				Reason:     "monitoring is enabled on a stopped instance",
+				Before:     map[string]string{"state": "stopped", "monitoring": "enabled"},
+				After:      map[string]string{"state": "stopped", "monitoring": "disabled"},
+			},
+		},
+		{
+			Name:       "already disabled",
+			Instance:   instance(ec2.InstanceStateNameRunning, ec2.MonitoringStateDisabled),
+			Monitoring: ec2.MonitoringStateDisabled,
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionNone,
+				Reason:     "monitoring is already disabled",
+				Before:     map[string]string{"state": "running", "monitoring": "disabled"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := ec2InstanceDetailedMonitoringChange(testCase.Instance, testCase.Monitoring)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %#v, expected %#v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestEnforceEc2InstanceDetailedMonitoring(t *testing.T) {
+	instances := []*ec2.Instance{
+		{
+			InstanceId: aws.String("i-00000002"),
+			State:      &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameStopped)},
+			Monitoring: &ec2.Monitoring{State: aws.String(ec2.MonitoringStateDisabled)},
+		},
+		{
+			InstanceId: aws.String("i-00000001"),
+			State:      &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+			Monitoring: &ec2.Monitoring{State: aws.String(ec2.MonitoringStateDisabled)},
+		},
+		{
+			InstanceId: aws.String("i-00000003"),
+			State:      &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+			Monitoring: &ec2.Monitoring{State: aws.String(ec2.MonitoringStateEnabled)},
+		},
+	}
+
+	for _, dryRun := range []bool{false, true} {
+		var monitored [][]string
+
+		conn := testEc2Conn(t, func(r *request.Request) {
+			switch output := r.Data.(type) {
+			case *ec2.DescribeInstancesOutput:
+				output.Reservations = []*ec2.Reservation{{Instances: instances}}
+			case *ec2.MonitorInstancesOutput:
+				monitored = append(monitored, aws.StringValueSlice(r.Params.(*ec2.MonitorInstancesInput).InstanceIds))
+			case *ec2.UnmonitorInstancesOutput:
+				t.Errorf("unexpected UnmonitorInstances request")
+			}
+		})
+
+		d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2InstanceDetailedMonitoringEnforcer().Schema, map[string]interface{}{
+			"tags":    map[string]interface{}{"Environment": "production"},
+			"dry_run": dryRun,
+		})
+
+		if _, err := enforceEc2InstanceDetailedMonitoring(context.Background(), d, &AWSClient{ec2conn: conn}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if expected := []interface{}{"i-00000001", "i-00000002"}; !reflect.DeepEqual(d.Get("changed_instance_ids"), expected) {
+			t.Errorf("got changed_instance_ids %v, expected %v", d.Get("changed_instance_ids"), expected)
+		}
+
+		var expected [][]string
+		if !dryRun {
+			expected = [][]string{{"i-00000001", "i-00000002"}}
+		}
+		if !reflect.DeepEqual(monitored, expected) {
+			t.Errorf("dry_run %t: got MonitorInstances requests %v, expected %v", dryRun, monitored, expected)
+		}
+	}
+}
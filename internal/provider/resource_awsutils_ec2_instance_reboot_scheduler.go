@@ -0,0 +1,301 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2InstanceIDChunkSize is the maximum number of instance IDs passed in a single "instance-id" filter.
+const ec2InstanceIDChunkSize = 200
+
+// ec2InstanceRebootEventCodes are the codes of the scheduled events a reboot of the instance stands for.
+var ec2InstanceRebootEventCodes = []string{
+	ec2.EventCodeInstanceReboot,
+	ec2.EventCodeSystemReboot,
+}
+
+// ec2InstanceEventDescriptionPrefixesDone are the prefixes AWS adds to the descriptions of the scheduled events
+// which are no longer pending.
+var ec2InstanceEventDescriptionPrefixesDone = []string{
+	"[Completed]",
+	"[Canceled]",
+}
+
+func resourceAwsUtilsEc2InstanceRebootScheduler() *schema.Resource {
+	return &schema.Resource{
+		Description: `Reboots the running EC2 Instances matching the given filters which have a pending scheduled reboot event,
+so that the reboot happens in a controlled window rather than at the time scheduled by AWS.
+
+Only the instances with a scheduled event of one of the ` + "`event_codes`" + ` which is neither completed nor canceled
+are rebooted, so applying this resource repeatedly is a no-op once the events are resolved. The instances are rebooted
+in batches of at most ` + "`max_concurrency`" + ` instances, waiting for the status checks of each batch to pass before
+rebooting the next one.
+
+When ` + "`dry_run`" + ` is set, the reboots are reported in ` + "`planned_changes`" + ` but not made. When
+` + "`require_confirmation_token`" + ` is set, they are only made once ` + "`confirmation_token`" + ` is set to the
+` + "`expected_confirmation_token`" + ` of the reboots, which changes whenever they do, forcing a new review. When
+` + "`continue_on_error`" + ` is set, the instances which cannot be rebooted are reported in ` + "`failed`" + ` and in an
+error returned after the remaining instances are rebooted.`,
+		CreateContext: resourceAwsEc2InstanceRebootSchedulerCreate,
+		ReadContext:   resourceAwsEc2InstanceRebootSchedulerRead,
+		UpdateContext: resourceAwsEc2InstanceRebootSchedulerUpdate,
+		DeleteContext: resourceAwsEc2InstanceRebootSchedulerDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 Instances with pending reboot events", func(d resourceDataGetter, meta interface{}) (int, []*ec2.Filter, error) {
+			instances, _, filters, _, err := findEc2InstancesWithPendingRebootEvents(d, meta)
+			return len(instances), filters, err
+		}, "event_codes"),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeInstance),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"event_codes": {
+				Description: "The codes of the scheduled events to reboot the instances for, among `instance-reboot` and `system-reboot`. Defaults to both.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(ec2InstanceRebootEventCodes, false),
+				},
+			},
+			"max_concurrency": {
+				Description:  "The maximum number of instances rebooted at the same time.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"dry_run": {
+				Description: "Report the reboots without making them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"require_confirmation_token":  requireConfirmationTokenSchema(),
+			"confirmation_token":          confirmationTokenSchema(),
+			"expected_confirmation_token": expectedConfirmationTokenSchema(),
+			"warn_if_matches_over":        ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":        ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning":     ec2MatchThresholdWarningSchema(),
+			"continue_on_error":           continueOnErrorSchema(),
+			"planned_changes":             plannedChangesSchema(),
+			"failed":                      failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2InstanceRebootSchedulerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := rebootEc2InstancesWithPendingEvents(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2InstanceRebootSchedulerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2InstanceRebootSchedulerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2InstanceRebootSchedulerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := rebootEc2InstancesWithPendingEvents(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2InstanceRebootSchedulerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2InstanceRebootSchedulerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// rebootEc2InstancesWithPendingEvents reboots the selected instances which have a pending scheduled reboot event,
+// recording the outcome in the given *schema.ResourceData.
+// It returns the warnings of warn_if_matches_over and of require_confirmation_token, if any.
+func rebootEc2InstancesWithPendingEvents(ctx context.Context, d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+
+	instances, events, filters, warnings, err := findEc2InstancesWithPendingRebootEvents(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(instances), "EC2 Instances with pending reboot events", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	sort.Slice(instances, func(i, j int) bool {
+		return aws.StringValue(instances[i].InstanceId) < aws.StringValue(instances[j].InstanceId)
+	})
+
+	changes := make([]*plannedChange, 0, len(instances))
+	for _, instance := range instances {
+		changes = append(changes, ec2InstanceRebootChange(aws.StringValue(instance.InstanceId), events[aws.StringValue(instance.InstanceId)]))
+	}
+
+	confirmed, confirmationWarnings, err := checkConfirmationToken(d, changes)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, confirmationWarnings...)
+
+	err = applyPlannedChangesInBatches(changes, d.Get("dry_run").(bool) || !confirmed, d.Get("continue_on_error").(bool), d.Get("max_concurrency").(int), func(change *plannedChange) string {
+		return ""
+	}, func(batch []*plannedChange) error {
+		instanceIDs := make([]string, 0, len(batch))
+		for _, change := range batch {
+			instanceIDs = append(instanceIDs, change.ResourceID)
+		}
+
+		log.Printf("[INFO] Rebooting EC2 Instances: %v", instanceIDs)
+		if _, err := conn.RebootInstancesWithContext(ctx, &ec2.RebootInstancesInput{InstanceIds: aws.StringSlice(instanceIDs)}); err != nil {
+			return fmt.Errorf("error rebooting EC2 Instances (%v): %w", instanceIDs, err)
+		}
+
+		log.Printf("[DEBUG] Waiting for the status checks of EC2 Instances (%v) to pass", instanceIDs)
+		if err := conn.WaitUntilInstanceStatusOkWithContext(ctx, &ec2.DescribeInstanceStatusInput{InstanceIds: aws.StringSlice(instanceIDs)}); err != nil {
+			return fmt.Errorf("error waiting for EC2 Instances (%v) to pass their status checks after reboot: %w", instanceIDs, err)
+		}
+
+		return nil
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	return warnings, err
+}
+
+// findEc2InstancesWithPendingRebootEvents reads the instances selected by the resource with the given
+// resourceDataGetter which have a pending scheduled reboot event, stopping past its fail_if_matches_over, and returns
+// them along with their events, keyed by instance ID, the filters they were selected with and the warnings of
+// buildEC2Selection. It is shared by apply and the count of customizeDiffEc2MatchThresholds.
+func findEc2InstancesWithPendingRebootEvents(d resourceDataGetter, meta interface{}) ([]*ec2.Instance, map[string]*ec2.InstanceStatusEvent, []*ec2.Filter, diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+
+	eventCodes := ec2InstanceRebootEventCodes
+	if v, ok := d.GetOk("event_codes"); ok && v.(*schema.Set).Len() > 0 {
+		eventCodes = ExpandStringSliceofPointers(ExpandStringSet(v.(*schema.Set)))
+	}
+
+	// Instance status filters do not include tags, so the instances with events are looked up first, and then those
+	// of them matching the selection.
+	statuses, err := finder.InstanceStatuses(conn, &ec2.DescribeInstanceStatusInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("event.code"),
+				Values: aws.StringSlice(eventCodes),
+			},
+		},
+	})
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("error reading EC2 Instance Statuses: %w", err)
+	}
+
+	events := make(map[string]*ec2.InstanceStatusEvent, len(statuses))
+	instanceIDs := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		if event := ec2InstancePendingRebootEvent(status, eventCodes); event != nil {
+			events[aws.StringValue(status.InstanceId)] = event
+			instanceIDs = append(instanceIDs, aws.StringValue(status.InstanceId))
+		}
+	}
+
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeInstance)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	var instances []*ec2.Instance
+	for i := 0; i < len(instanceIDs); i += ec2InstanceIDChunkSize {
+		j := i + ec2InstanceIDChunkSize
+		if j > len(instanceIDs) {
+			j = len(instanceIDs)
+		}
+
+		input := &ec2.DescribeInstancesInput{
+			InstanceIds: ids,
+			Filters: append(append([]*ec2.Filter{}, filters...), &ec2.Filter{
+				Name:   aws.String("instance-id"),
+				Values: aws.StringSlice(instanceIDs[i:j]),
+			}),
+		}
+
+		chunk, err := finder.Instances(conn, input, ec2FailIfMatchesOver(d))
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("error reading EC2 Instances: %w", ec2FailIfMatchesOverError(err, filters))
+		}
+		instances = append(instances, chunk...)
+
+		// The remaining chunks cannot bring the count back under the threshold.
+		if threshold := ec2FailIfMatchesOver(d); threshold > 0 && len(instances) > threshold {
+			break
+		}
+	}
+
+	return instances, events, filters, warnings, nil
+}
+
+// ec2InstancePendingRebootEvent returns the first scheduled event of the given instance status with one of the given
+// codes which is neither completed nor canceled, or nil if there is none.
+func ec2InstancePendingRebootEvent(status *ec2.InstanceStatus, eventCodes []string) *ec2.InstanceStatusEvent {
+	for _, event := range status.Events {
+		if event == nil || !contains(eventCodes, aws.StringValue(event.Code)) {
+			continue
+		}
+
+		pending := true
+		for _, prefix := range ec2InstanceEventDescriptionPrefixesDone {
+			if strings.HasPrefix(aws.StringValue(event.Description), prefix) {
+				pending = false
+			}
+		}
+
+		if pending {
+			return event
+		}
+	}
+
+	return nil
+}
+
+// ec2InstanceRebootChange returns the change rebooting the given instance for the given pending event.
+func ec2InstanceRebootChange(instanceID string, event *ec2.InstanceStatusEvent) *plannedChange {
+	before := map[string]string{
+		"event_id":   aws.StringValue(event.InstanceEventId),
+		"event_code": aws.StringValue(event.Code),
+	}
+	if event.NotBefore != nil {
+		before["not_before"] = aws.TimeValue(event.NotBefore).UTC().Format(time.RFC3339)
+	}
+
+	return &plannedChange{
+		ResourceID: instanceID,
+		Action:     plannedChangeActionUpdate,
+		Reason:     fmt.Sprintf("pending %s event (%s)", aws.StringValue(event.Code), aws.StringValue(event.InstanceEventId)),
+		Before:     before,
+	}
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2InstancePendingRebootEvent(t *testing.T) {
+	systemReboot := &ec2.InstanceStatusEvent{
+		Code:            aws.String(ec2.EventCodeSystemReboot),
+		Description:     aws.String("scheduled reboot"),
+		InstanceEventId: aws.String("instance-event-0123456789abcdef0"),
+	}
+
+	testCases := []struct {
+		Name       string
+		Events     []*ec2.InstanceStatusEvent
+		EventCodes []string
+		Expected   *ec2.InstanceStatusEvent
+	}{
+		{
+			Name:       "no events",
+			EventCodes: ec2InstanceRebootEventCodes,
+		},
+		{
+			Name:       "pending reboot",
+			Events:     []*ec2.InstanceStatusEvent{systemReboot},
+			EventCodes: ec2InstanceRebootEventCodes,
+			Expected:   systemReboot,
+		},
+		{
+			Name:       "other code",
+			Events:     []*ec2.InstanceStatusEvent{systemReboot},
+			EventCodes: []string{ec2.EventCodeInstanceReboot},
+		},
+		{
+			Name: "retirement",
+			Events: []*ec2.InstanceStatusEvent{
+				{Code: aws.String(ec2.EventCodeInstanceRetirement), Description: aws.String("scheduled retirement")},
+			},
+			EventCodes: ec2InstanceRebootEventCodes,
+		},
+		{
+			Name: "completed and canceled",
+			Events: []*ec2.InstanceStatusEvent{
+				{Code: aws.String(ec2.EventCodeSystemReboot), Description: aws.String("[Completed] scheduled reboot")},
+				{Code: aws.String(ec2.EventCodeInstanceReboot), Description: aws.String("[Canceled] scheduled reboot")},
+			},
+			EventCodes: ec2InstanceRebootEventCodes,
+		},
+		{
+			Name: "completed and pending",
+			Events: []*ec2.InstanceStatusEvent{
+				{Code: aws.String(ec2.EventCodeSystemReboot), Description: aws.String("[Completed] scheduled reboot")},
+				systemReboot,
+			},
+			EventCodes: ec2InstanceRebootEventCodes,
+			Expected:   systemReboot,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			status := &ec2.InstanceStatus{InstanceId: aws.String("i-01234567"), Events: testCase.Events}
+
+			if got := ec2InstancePendingRebootEvent(status, testCase.EventCodes); got != testCase.Expected {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestEc2InstanceRebootChange(t *testing.T) {
+	got := ec2InstanceRebootChange("i-01234567", &ec2.InstanceStatusEvent{
+		Code:            aws.String(ec2.EventCodeSystemReboot),
+		InstanceEventId: aws.String("instance-event-0123456789abcdef0"),
+		NotBefore:       aws.Time(time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)),
+	})
+
+	expected := &plannedChange{
+		ResourceID: "i-01234567",
+		Action:     plannedChangeActionUpdate,
+		Reason:     "pending system-reboot event (instance-event-0123456789abcdef0)",
+		Before: map[string]string{
+			"event_id":   "instance-event-0123456789abcdef0",
+			"event_code": "system-reboot",
+			"not_before": "2024-05-01T02:00:00Z",
+		},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %+v, expected %+v", got, expected)
+	}
+}
+
+func TestRebootEc2InstancesWithPendingEventsConfirmationToken(t *testing.T) {
+	instanceIDs := []string{"i-00000001"}
+	var rebooted []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeInstanceStatusOutput:
+			for _, instanceID := range instanceIDs {
+				output.InstanceStatuses = append(output.InstanceStatuses, &ec2.InstanceStatus{
+					InstanceId:     aws.String(instanceID),
+					InstanceStatus: &ec2.InstanceStatusSummary{Status: aws.String(ec2.SummaryStatusOk)},
+					Events: []*ec2.InstanceStatusEvent{
+						{InstanceEventId: aws.String("instance-event-" + instanceID), Code: aws.String(ec2.EventCodeSystemReboot)},
+					},
+				})
+			}
+		case *ec2.DescribeInstancesOutput:
+			var instances []*ec2.Instance
+			for _, instanceID := range instanceIDs {
+				instances = append(instances, &ec2.Instance{InstanceId: aws.String(instanceID)})
+			}
+			output.Reservations = []*ec2.Reservation{{Instances: instances}}
+		case *ec2.RebootInstancesOutput:
+			rebooted = append(rebooted, aws.StringValueSlice(r.Params.(*ec2.RebootInstancesInput).InstanceIds)...)
+		}
+	})
+
+	reboot := func(token string) *schema.ResourceData {
+		d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2InstanceRebootScheduler().Schema, map[string]interface{}{
+			"tags":                       map[string]interface{}{"Environment": "staging"},
+			"require_confirmation_token": true,
+			"confirmation_token":         token,
+		})
+
+		if _, err := rebootEc2InstancesWithPendingEvents(context.Background(), d, &AWSClient{ec2conn: conn}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return d
+	}
+
+	token := reboot("").Get("expected_confirmation_token").(string)
+	if len(rebooted) != 0 {
+		t.Fatalf("got rebooted instances %v before confirmation, expected none", rebooted)
+	}
+
+	// Once the selection changes, the token no longer confirms the reboots.
+	instanceIDs = append(instanceIDs, "i-00000002")
+	d := reboot(token)
+	if len(rebooted) != 0 {
+		t.Fatalf("got rebooted instances %v with the token of another selection, expected none", rebooted)
+	}
+	if d.Get("expected_confirmation_token").(string) == token {
+		t.Errorf("expected the token to change with the selection")
+	}
+
+	reboot(d.Get("expected_confirmation_token").(string))
+	if expected := []string{"i-00000001", "i-00000002"}; !reflect.DeepEqual(rebooted, expected) {
+		t.Errorf("got rebooted instances %v, expected %v", rebooted, expected)
+	}
+}
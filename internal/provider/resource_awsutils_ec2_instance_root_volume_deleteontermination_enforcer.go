@@ -0,0 +1,217 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceAwsUtilsEc2InstanceRootVolumeDeleteOnTerminationEnforcer() *schema.Resource {
+	return &schema.Resource{
+		Description: `Sets the ` + "`DeleteOnTermination`" + ` flag of the root EBS volume of the EC2 Instances matching the given
+filters where it is not set, so that the root volumes are not left behind, and billed, once the instances are terminated.
+
+The root volume of an instance is the block device mapping of its root device name, such as ` + "`/dev/xvda`" + `, which
+depends on its AMI. Only the flag of that mapping is changed, the flags of the data volumes being left as they are.
+Instances with an instance store root device, and those whose root device has no EBS block device mapping, are left as
+they are. Instances which are terminated or shutting down are not selected. Applying this resource repeatedly is a
+no-op once every selected instance has the flag set.
+
+When ` + "`dry_run`" + ` is set, the changes are reported in ` + "`planned_changes`" + ` but not made. When
+` + "`continue_on_error`" + ` is set, the instances which cannot be changed are reported in ` + "`failed`" + ` and in
+an error returned after the remaining instances are changed. Destroying this resource does not revert the changes.`,
+		CreateContext: resourceAwsEc2InstanceRootVolumeDeleteOnTerminationEnforcerCreate,
+		ReadContext:   resourceAwsEc2InstanceRootVolumeDeleteOnTerminationEnforcerRead,
+		UpdateContext: resourceAwsEc2InstanceRootVolumeDeleteOnTerminationEnforcerUpdate,
+		DeleteContext: resourceAwsEc2InstanceRootVolumeDeleteOnTerminationEnforcerDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 Instances", countEc2SelectedInstances(ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopping, ec2.InstanceStateNameStopped)),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeInstance),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"dry_run": {
+				Description: "Report the changes without making them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"changed_instance_ids": {
+				Description: "The IDs of the instances whose root volume flag was set, or would be when `dry_run` is set, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"warn_if_matches_over":    ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":    ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning": ec2MatchThresholdWarningSchema(),
+			"continue_on_error":       continueOnErrorSchema(),
+			"planned_changes":         plannedChangesSchema(),
+			"failed":                  failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2InstanceRootVolumeDeleteOnTerminationEnforcerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := enforceEc2InstanceRootVolumeDeleteOnTermination(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2InstanceRootVolumeDeleteOnTerminationEnforcerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2InstanceRootVolumeDeleteOnTerminationEnforcerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2InstanceRootVolumeDeleteOnTerminationEnforcerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := enforceEc2InstanceRootVolumeDeleteOnTermination(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2InstanceRootVolumeDeleteOnTerminationEnforcerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2InstanceRootVolumeDeleteOnTerminationEnforcerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// enforceEc2InstanceRootVolumeDeleteOnTermination sets the DeleteOnTermination flag of the root volume of the
+// selected instances, recording the outcome in the given *schema.ResourceData.
+// It returns the warning of warn_if_matches_over, if any.
+func enforceEc2InstanceRootVolumeDeleteOnTermination(ctx context.Context, d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+
+	instances, filters, warnings, err := findEc2SelectedInstances(d, meta, ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopping, ec2.InstanceStateNameStopped)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(instances), "EC2 Instances", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	sort.Slice(instances, func(i, j int) bool {
+		return aws.StringValue(instances[i].InstanceId) < aws.StringValue(instances[j].InstanceId)
+	})
+
+	changes := make([]*plannedChange, 0, len(instances))
+	changedIDs := make([]string, 0)
+	for _, instance := range instances {
+		change := ec2InstanceRootVolumeDeleteOnTerminationChange(instance)
+		if change.Action != plannedChangeActionNone {
+			changedIDs = append(changedIDs, change.ResourceID)
+		}
+		changes = append(changes, change)
+	}
+
+	err = applyPlannedChanges(changes, d.Get("dry_run").(bool), d.Get("continue_on_error").(bool), func(change *plannedChange) error {
+		deviceName := change.Before["root_device_name"]
+
+		log.Printf("[INFO] Setting DeleteOnTermination on the root volume (%s) of EC2 Instance (%s)", deviceName, change.ResourceID)
+		// Only the root device is given, so the flags of the other block device mappings are left as they are.
+		_, err := conn.ModifyInstanceAttributeWithContext(ctx, &ec2.ModifyInstanceAttributeInput{
+			InstanceId: aws.String(change.ResourceID),
+			BlockDeviceMappings: []*ec2.InstanceBlockDeviceMappingSpecification{
+				{
+					DeviceName: aws.String(deviceName),
+					Ebs: &ec2.EbsInstanceBlockDeviceSpecification{
+						DeleteOnTermination: aws.Bool(true),
+					},
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("error setting DeleteOnTermination on the root volume (%s) of EC2 Instance (%s): %w", deviceName, change.ResourceID, err)
+		}
+
+		return nil
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("changed_instance_ids", changedIDs); err != nil {
+		return nil, fmt.Errorf("error setting changed_instance_ids: %w", err)
+	}
+
+	return warnings, err
+}
+
+// ec2InstanceRootVolumeDeleteOnTerminationChange returns the change setting the DeleteOnTermination flag of the root
+// volume of the given instance.
+//
+// The root volume is the EBS block device mapping whose device name is the root device name of the instance, the
+// "/dev/" prefix being ignored, as some AMIs give the device names without it, such as "xvda". The change
+// is made on the device name of the mapping, which is the one ModifyInstanceAttribute accepts. Instances with an
+// instance store root device, or without a mapping for their root device, are left as they are.
+func ec2InstanceRootVolumeDeleteOnTerminationChange(instance *ec2.Instance) *plannedChange {
+	rootDeviceName := aws.StringValue(instance.RootDeviceName)
+
+	change := &plannedChange{
+		ResourceID: aws.StringValue(instance.InstanceId),
+		Action:     plannedChangeActionNone,
+		Before:     map[string]string{"root_device_name": rootDeviceName},
+	}
+
+	if aws.StringValue(instance.RootDeviceType) == ec2.DeviceTypeInstanceStore {
+		change.Reason = "the root device is an instance store volume"
+		return change
+	}
+
+	var mapping *ec2.InstanceBlockDeviceMapping
+	for _, m := range instance.BlockDeviceMappings {
+		if m != nil && m.Ebs != nil && rootDeviceName != "" && strings.TrimPrefix(aws.StringValue(m.DeviceName), "/dev/") == strings.TrimPrefix(rootDeviceName, "/dev/") {
+			mapping = m
+			break
+		}
+	}
+
+	if mapping == nil {
+		change.Reason = fmt.Sprintf("no EBS block device mapping for the root device %q", rootDeviceName)
+		return change
+	}
+
+	deleteOnTermination := aws.BoolValue(mapping.Ebs.DeleteOnTermination)
+	change.Before = map[string]string{
+		"root_device_name":      aws.StringValue(mapping.DeviceName),
+		"volume_id":             aws.StringValue(mapping.Ebs.VolumeId),
+		"delete_on_termination": strconv.FormatBool(deleteOnTermination),
+	}
+
+	if deleteOnTermination {
+		change.Reason = "DeleteOnTermination is already set on the root volume"
+		return change
+	}
+
+	change.Action = plannedChangeActionUpdate
+	change.Reason = "DeleteOnTermination is not set on the root volume"
+	change.After = map[string]string{
+		"root_device_name":      aws.StringValue(mapping.DeviceName),
+		"volume_id":             aws.StringValue(mapping.Ebs.VolumeId),
+		"delete_on_termination": "true",
+	}
+
+	return change
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testEc2BlockDeviceMapping(deviceName, volumeID string, deleteOnTermination bool) *ec2.InstanceBlockDeviceMapping {
+	return &ec2.InstanceBlockDeviceMapping{
+		DeviceName: aws.String(deviceName),
+		Ebs: &ec2.EbsInstanceBlockDevice{
+			VolumeId:            aws.String(volumeID),
+			DeleteOnTermination: aws.Bool(deleteOnTermination),
+		},
+	}
+}
+
+func TestEc2InstanceRootVolumeDeleteOnTerminationChange(t *testing.T) {
+	instance := func(rootDeviceName string, mappings ...*ec2.InstanceBlockDeviceMapping) *ec2.Instance {
+		return &ec2.Instance{
+			InstanceId:          aws.String("i-00000001"),
+			RootDeviceName:      aws.String(rootDeviceName),
+			RootDeviceType:      aws.String(ec2.DeviceTypeEbs),
+			BlockDeviceMappings: mappings,
+		}
+	}
+
+	testCases := []struct {
+		Name     string
+		Instance *ec2.Instance
+		Expected *plannedChange
+	}{
+		{
+			Name: "root volume not deleted on termination",
+			Instance: instance("/dev/xvda",
+				testEc2BlockDeviceMapping("/dev/xvdf", "vol-00000002", true),
+				testEc2BlockDeviceMapping("/dev/xvda", "vol-00000001", false),
+			),
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionUpdate,
+				Reason:     "DeleteOnTermination is not set on the root volume",
+				Before:     map[string]string{"root_device_name": "/dev/xvda", "volume_id": "vol-00000001", "delete_on_termination": "false"},
+				After:      map[string]string{"root_device_name": "/dev/xvda", "volume_id": "vol-00000001", "delete_on_termination": "true"},
+			},
+		},
+		{
+			Name: "only a data volume not deleted on termination",
+			Instance: instance("/dev/sda1",
+				testEc2BlockDeviceMapping("/dev/sda1", "vol-00000001", true),
+				testEc2BlockDeviceMapping("/dev/sdf", "vol-00000002", false),
+			),
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionNone,
+				Reason:     "DeleteOnTermination is already set on the root volume",
+				Before:     map[string]string{"root_device_name": "/dev/sda1", "volume_id": "vol-00000001", "delete_on_termination": "true"},
+			},
+		},
+		{
+			Name: "device names without prefix",
+			Instance: instance("/dev/xvda",
+				testEc2BlockDeviceMapping("xvda", "vol-00000001", false),
+			),
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionUpdate,
+				Reason:     "DeleteOnTermination is not set on the root volume",
+				Before:     map[string]string{"root_device_name": "xvda", "volume_id": "vol-00000001", "delete_on_termination": "false"},
+				After:      map[string]string{"root_device_name": "xvda", "volume_id": "vol-00000001", "delete_on_termination": "true"},
+			},
+		},
+		{
+			Name: "no mapping for the root device",
+			Instance: instance("/dev/xvda",
+				testEc2BlockDeviceMapping("/dev/xvdf", "vol-00000002", false),
+			),
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionNone,
+				Reason:     `no EBS block device mapping for the root device "/dev/xvda"`,
+				Before:     map[string]string{"root_device_name": "/dev/xvda"},
+			},
+		},
+		{
+			Name: "instance store root device",
+			Instance: &ec2.Instance{
+				InstanceId:     aws.String("i-00000001"),
+				RootDeviceName: aws.String("/dev/sda1"),
+				RootDeviceType: aws.String(ec2.DeviceTypeInstanceStore),
+			},
+			Expected: &plannedChange{
+				ResourceID: "i-00000001",
+				Action:     plannedChangeActionNone,
+				Reason:     "the root device is an instance store volume",
+				Before:     map[string]string{"root_device_name": "/dev/sda1"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := ec2InstanceRootVolumeDeleteOnTerminationChange(testCase.Instance)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestEnforceEc2InstanceRootVolumeDeleteOnTermination(t *testing.T) {
+	instances := []*ec2.Instance{
+		{
+			InstanceId:     aws.String("i-00000002"),
+			RootDeviceName: aws.String("/dev/xvda"),
+			RootDeviceType: aws.String(ec2.DeviceTypeEbs),
+			BlockDeviceMappings: []*ec2.InstanceBlockDeviceMapping{
+				testEc2BlockDeviceMapping("/dev/xvda", "vol-00000003", true),
+				testEc2BlockDeviceMapping("/dev/xvdf", "vol-00000004", false),
+			},
+		},
+		{
+			InstanceId:     aws.String("i-00000001"),
+			RootDeviceName: aws.String("/dev/sda1"),
+			RootDeviceType: aws.String(ec2.DeviceTypeEbs),
+			BlockDeviceMappings: []*ec2.InstanceBlockDeviceMapping{
+				testEc2BlockDeviceMapping("/dev/sdf", "vol-00000002", false),
+				testEc2BlockDeviceMapping("/dev/sda1", "vol-00000001", false),
+			},
+		},
+	}
+
+	for _, dryRun := range []bool{false, true} {
+		var modified []*ec2.ModifyInstanceAttributeInput
+
+		conn := testEc2Conn(t, func(r *request.Request) {
+			switch output := r.Data.(type) {
+			case *ec2.DescribeInstancesOutput:
+				output.Reservations = []*ec2.Reservation{{Instances: instances}}
+			case *ec2.ModifyInstanceAttributeOutput:
+				modified = append(modified, r.Params.(*ec2.ModifyInstanceAttributeInput))
+			}
+		})
+
+		d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2InstanceRootVolumeDeleteOnTerminationEnforcer().Schema, map[string]interface{}{
+			"tags":    map[string]interface{}{"Environment": "production"},
+			"dry_run": dryRun,
+		})
+
+		if _, err := enforceEc2InstanceRootVolumeDeleteOnTermination(context.Background(), d, &AWSClient{ec2conn: conn}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if expected := []interface{}{"i-00000001"}; !reflect.DeepEqual(d.Get("changed_instance_ids"), expected) {
+			t.Errorf("got changed_instance_ids %v, expected %v", d.Get("changed_instance_ids"), expected)
+		}
+
+		var expected []*ec2.ModifyInstanceAttributeInput
+		if !dryRun {
+			expected = []*ec2.ModifyInstanceAttributeInput{
+				{
+					InstanceId: aws.String("i-00000001"),
+					BlockDeviceMappings: []*ec2.InstanceBlockDeviceMappingSpecification{
+						{
+							DeviceName: aws.String("/dev/sda1"),
+							Ebs:        &ec2.EbsInstanceBlockDeviceSpecification{DeleteOnTermination: aws.Bool(true)},
+						},
+					},
+				},
+			}
+		}
+		if !reflect.DeepEqual(modified, expected) {
+			t.Errorf("dry_run %t: got ModifyInstanceAttribute requests %v, expected %v", dryRun, modified, expected)
+		}
+	}
+}
@@ -0,0 +1,531 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2StopProtectionSchedulerOwnershipTagKey is the default key of the tag marking the instances stopped by
+// awsutils_ec2_instance_stop_protection_scheduler.
+const ec2StopProtectionSchedulerOwnershipTagKey = "awsutils:stop-protection-scheduler"
+
+// ec2BusinessHoursTimeFormat is the layout of the start and end times of the business hours.
+const ec2BusinessHoursTimeFormat = "15:04"
+
+// ec2BusinessHoursDays maps the days of the business hours to their time.Weekday.
+var ec2BusinessHoursDays = map[string]time.Weekday{
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+	"sun": time.Sunday,
+}
+
+// ec2BusinessHours are the hours during which the instances are kept running.
+type ec2BusinessHours struct {
+	Days     map[time.Weekday]bool
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+}
+
+// contains returns whether the given time is within the business hours, the start time included and the end time
+// excluded.
+func (h *ec2BusinessHours) contains(t time.Time) bool {
+	t = t.In(h.Location)
+	if !h.Days[t.Weekday()] {
+		return false
+	}
+
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	return sinceMidnight >= h.Start && sinceMidnight < h.End
+}
+
+// ec2StopProtection is the stop and termination protection of an instance.
+type ec2StopProtection struct {
+	DisableApiStop        bool
+	DisableApiTermination bool
+}
+
+// String returns the value of the ownership tag recording the given protection, such as
+// "disable-api-stop=false disable-api-termination=false".
+func (p ec2StopProtection) String() string {
+	return fmt.Sprintf("disable-api-stop=%t disable-api-termination=%t", p.DisableApiStop, p.DisableApiTermination)
+}
+
+// parseEc2StopProtection parses the protection recorded in the value of an ownership tag by ec2StopProtection.String.
+// Missing or invalid values are read as false, which is the default of new instances.
+func parseEc2StopProtection(value string) ec2StopProtection {
+	var protection ec2StopProtection
+
+	for _, field := range strings.Fields(value) {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		enabled, _ := strconv.ParseBool(parts[1])
+		switch parts[0] {
+		case "disable-api-stop":
+			protection.DisableApiStop = enabled
+		case "disable-api-termination":
+			protection.DisableApiTermination = enabled
+		}
+	}
+
+	return protection
+}
+
+func resourceAwsUtilsEc2InstanceStopProtectionScheduler() *schema.Resource {
+	return &schema.Resource{
+		Description: `Stops the running EC2 Instances matching the given filters outside of the given business hours, protecting
+them from termination while they are stopped, and starts them again during the business hours.
+
+The business hours are evaluated when the resource is applied, so it is meant to be applied on a schedule, e.g. from a
+CI pipeline, at least once after each boundary of the business hours. When stopping an instance, its stop and
+termination protection are recorded in the ` + "`ownership_tag_key`" + ` tag, its stop protection is disabled for it
+to be stopped, and its termination protection is enabled. When starting it, it is only started if it carries the tag,
+and its protection is restored from the tag, which is then removed. Instances stopped by anything else are therefore
+never started, and instances started by anything else outside of the business hours are stopped again only if they
+still carry the tag. Applying this resource repeatedly on the same side of a boundary is a no-op.
+
+When ` + "`dry_run`" + ` is set, the changes are reported in ` + "`planned_changes`" + ` but not made. When
+` + "`require_confirmation_token`" + ` is set, they are only made once ` + "`confirmation_token`" + ` is set to the
+` + "`expected_confirmation_token`" + ` of the changes, which changes whenever they do, forcing a new review. When
+` + "`continue_on_error`" + ` is set, the instances which cannot be changed are reported in ` + "`failed`" + ` and in
+an error returned after the remaining instances are changed.`,
+		CreateContext: resourceAwsEc2InstanceStopProtectionSchedulerCreate,
+		ReadContext:   resourceAwsEc2InstanceStopProtectionSchedulerRead,
+		UpdateContext: resourceAwsEc2InstanceStopProtectionSchedulerUpdate,
+		DeleteContext: resourceAwsEc2InstanceStopProtectionSchedulerDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 Instances", countEc2SelectedInstances(ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopping, ec2.InstanceStateNameStopped)),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeInstance),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"business_hours": {
+				Description: "The hours during which the instances are kept running.",
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"days": {
+							Description: "The days of the business hours, among `mon`, `tue`, `wed`, `thu`, `fri`, `sat` and `sun`.",
+							Type:        schema.TypeSet,
+							Required:    true,
+							MinItems:    1,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringInSlice([]string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}, false),
+							},
+						},
+						"start_time": {
+							Description:  "The start time of the business hours, in the `HH:MM` format.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateEc2BusinessHoursTime,
+						},
+						"end_time": {
+							Description:  "The end time of the business hours, in the `HH:MM` format, which must be after `start_time`.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateEc2BusinessHoursTime,
+						},
+						"time_zone": {
+							Description:  "The IANA time zone of the business hours, e.g. `Europe/Paris`.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "UTC",
+							ValidateFunc: validateEc2BusinessHoursTimeZone,
+						},
+					},
+				},
+			},
+			"ownership_tag_key": {
+				Description:  "The key of the tag marking the instances stopped by this resource, which are the only ones it starts.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      ec2StopProtectionSchedulerOwnershipTagKey,
+				ValidateFunc: validation.StringDoesNotMatch(regexp.MustCompile(`^aws:`), "cannot begin with the reserved aws: prefix"),
+			},
+			"dry_run": {
+				Description: "Report the changes without making them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"in_business_hours": {
+				Description: "Whether the resource was last applied during the business hours, starting the instances, rather than stopping them.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"require_confirmation_token":  requireConfirmationTokenSchema(),
+			"confirmation_token":          confirmationTokenSchema(),
+			"expected_confirmation_token": expectedConfirmationTokenSchema(),
+			"warn_if_matches_over":        ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":        ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning":     ec2MatchThresholdWarningSchema(),
+			"continue_on_error":           continueOnErrorSchema(),
+			"planned_changes":             plannedChangesSchema(),
+			"failed":                      failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2InstanceStopProtectionSchedulerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := scheduleEc2InstanceStopProtection(ctx, d, meta, time.Now())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2InstanceStopProtectionSchedulerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2InstanceStopProtectionSchedulerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2InstanceStopProtectionSchedulerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := scheduleEc2InstanceStopProtection(ctx, d, meta, time.Now())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2InstanceStopProtectionSchedulerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2InstanceStopProtectionSchedulerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// validateEc2BusinessHoursTime validates a start or end time of the business hours.
+func validateEc2BusinessHoursTime(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := time.Parse(ec2BusinessHoursTimeFormat, v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a time in the HH:MM format, got %q", k, v.(string)))
+	}
+
+	return
+}
+
+// validateEc2BusinessHoursTimeZone validates the time zone of the business hours.
+func validateEc2BusinessHoursTimeZone(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := time.LoadLocation(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be an IANA time zone: %w", k, err))
+	}
+
+	return
+}
+
+// expandEc2BusinessHours expands the given business_hours block.
+func expandEc2BusinessHours(tfMap map[string]interface{}) (*ec2BusinessHours, error) {
+	location, err := time.LoadLocation(tfMap["time_zone"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("error loading time zone: %w", err)
+	}
+
+	hours := &ec2BusinessHours{
+		Days:     make(map[time.Weekday]bool),
+		Location: location,
+	}
+
+	for _, day := range ExpandStringSliceofPointers(ExpandStringSet(tfMap["days"].(*schema.Set))) {
+		hours.Days[ec2BusinessHoursDays[day]] = true
+	}
+
+	for _, boundary := range []struct {
+		key   string
+		value *time.Duration
+	}{
+		{"start_time", &hours.Start},
+		{"end_time", &hours.End},
+	} {
+		t, err := time.Parse(ec2BusinessHoursTimeFormat, tfMap[boundary.key].(string))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", boundary.key, err)
+		}
+		*boundary.value = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+
+	if hours.End <= hours.Start {
+		return nil, fmt.Errorf("end_time (%s) must be after start_time (%s)", tfMap["end_time"], tfMap["start_time"])
+	}
+
+	return hours, nil
+}
+
+// scheduleEc2InstanceStopProtection stops or starts the selected instances depending on whether the given time is
+// within the business hours, recording the outcome in the given *schema.ResourceData.
+// It returns the warnings of warn_if_matches_over and of require_confirmation_token, if any.
+func scheduleEc2InstanceStopProtection(ctx context.Context, d *schema.ResourceData, meta interface{}, now time.Time) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+	ownershipTagKey := d.Get("ownership_tag_key").(string)
+
+	hours, err := expandEc2BusinessHours(d.Get("business_hours").([]interface{})[0].(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	inBusinessHours := hours.contains(now)
+
+	instances, filters, warnings, err := findEc2SelectedInstances(d, meta, ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopping, ec2.InstanceStateNameStopped)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(instances), "EC2 Instances", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	sort.Slice(instances, func(i, j int) bool {
+		return aws.StringValue(instances[i].InstanceId) < aws.StringValue(instances[j].InstanceId)
+	})
+
+	changes := make([]*plannedChange, 0, len(instances))
+	for _, instance := range instances {
+		instanceID := aws.StringValue(instance.InstanceId)
+
+		protection, err := readEc2StopProtection(ctx, conn, instanceID)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, ec2InstanceStopProtectionChange(instance, protection, ownershipTagKey, inBusinessHours))
+	}
+
+	confirmed, confirmationWarnings, err := checkConfirmationToken(d, changes)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, confirmationWarnings...)
+
+	err = applyPlannedChanges(changes, d.Get("dry_run").(bool) || !confirmed, d.Get("continue_on_error").(bool), func(change *plannedChange) error {
+		return applyEc2InstanceStopProtectionChange(ctx, conn, change, ownershipTagKey)
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("in_business_hours", inBusinessHours); err != nil {
+		return nil, fmt.Errorf("error setting in_business_hours: %w", err)
+	}
+
+	return warnings, err
+}
+
+// readEc2StopProtection reads the stop and termination protection of the given instance, which are not returned
+// by DescribeInstances.
+func readEc2StopProtection(ctx context.Context, conn *ec2.EC2, instanceID string) (ec2StopProtection, error) {
+	var protection ec2StopProtection
+
+	for _, attribute := range []struct {
+		name  string
+		value *bool
+	}{
+		{ec2.InstanceAttributeNameDisableApiStop, &protection.DisableApiStop},
+		{ec2.InstanceAttributeNameDisableApiTermination, &protection.DisableApiTermination},
+	} {
+		output, err := conn.DescribeInstanceAttributeWithContext(ctx, &ec2.DescribeInstanceAttributeInput{
+			Attribute:  aws.String(attribute.name),
+			InstanceId: aws.String(instanceID),
+		})
+		if err != nil {
+			return protection, fmt.Errorf("error reading EC2 Instance (%s) attribute %s: %w", instanceID, attribute.name, err)
+		}
+
+		switch attribute.name {
+		case ec2.InstanceAttributeNameDisableApiStop:
+			if output.DisableApiStop != nil {
+				*attribute.value = aws.BoolValue(output.DisableApiStop.Value)
+			}
+		case ec2.InstanceAttributeNameDisableApiTermination:
+			if output.DisableApiTermination != nil {
+				*attribute.value = aws.BoolValue(output.DisableApiTermination.Value)
+			}
+		}
+	}
+
+	return protection, nil
+}
+
+// ec2InstanceStopProtectionState returns the state and protection of an instance as recorded in a change.
+func ec2InstanceStopProtectionState(state string, protection ec2StopProtection) map[string]string {
+	return map[string]string{
+		"state":                   state,
+		"disable_api_stop":        strconv.FormatBool(protection.DisableApiStop),
+		"disable_api_termination": strconv.FormatBool(protection.DisableApiTermination),
+	}
+}
+
+// ec2InstanceStopProtectionChange returns the change stopping the given instance outside of the business hours, or
+// starting it during them, given its current protection.
+//
+// Outside of the business hours, the running instances are stopped and protected from termination. During the
+// business hours, the instances carrying the ownership tag are started if they are stopped, and their protection is
+// restored from the tag. Instances in a transitional state are left as they are until the next apply.
+func ec2InstanceStopProtectionChange(instance *ec2.Instance, protection ec2StopProtection, ownershipTagKey string, inBusinessHours bool) *plannedChange {
+	var state string
+	if instance.State != nil {
+		state = aws.StringValue(instance.State.Name)
+	}
+
+	ownershipTag := keyvaluetags.Ec2KeyValueTags(instance.Tags).KeyValue(ownershipTagKey)
+
+	change := &plannedChange{
+		ResourceID: aws.StringValue(instance.InstanceId),
+		Action:     plannedChangeActionNone,
+		Before:     ec2InstanceStopProtectionState(state, protection),
+	}
+
+	if !inBusinessHours {
+		switch {
+		case state == ec2.InstanceStateNameRunning:
+			// The protection recorded when the instance was first stopped is kept if it was started by something
+			// else since, as it may since have been changed by this resource.
+			original := protection
+			if ownershipTag != nil {
+				original = parseEc2StopProtection(aws.StringValue(ownershipTag))
+			}
+
+			change.Action = plannedChangeActionUpdate
+			change.Reason = "running outside of the business hours"
+			change.After = ec2InstanceStopProtectionState(ec2.InstanceStateNameStopped, ec2StopProtection{DisableApiTermination: true})
+			change.After["ownership_tag"] = original.String()
+		case ownershipTag != nil && state == ec2.InstanceStateNameStopped && (protection.DisableApiStop || !protection.DisableApiTermination):
+			change.Action = plannedChangeActionUpdate
+			change.Reason = "stopped without termination protection outside of the business hours"
+			change.After = ec2InstanceStopProtectionState(ec2.InstanceStateNameStopped, ec2StopProtection{DisableApiTermination: true})
+		case ownershipTag != nil:
+			change.Reason = fmt.Sprintf("%s by the scheduler", state)
+		default:
+			change.Reason = fmt.Sprintf("%s and not owned by the scheduler", state)
+		}
+
+		return change
+	}
+
+	if ownershipTag == nil {
+		change.Reason = "not stopped by the scheduler"
+		return change
+	}
+
+	original := parseEc2StopProtection(aws.StringValue(ownershipTag))
+
+	switch state {
+	case ec2.InstanceStateNameStopped:
+		change.Action = plannedChangeActionUpdate
+		change.Reason = "stopped by the scheduler during the business hours"
+		change.After = ec2InstanceStopProtectionState(ec2.InstanceStateNameRunning, original)
+	case ec2.InstanceStateNameRunning:
+		change.Action = plannedChangeActionUpdate
+		change.Reason = "started since stopped by the scheduler, restoring its protection"
+		change.After = ec2InstanceStopProtectionState(ec2.InstanceStateNameRunning, original)
+	default:
+		change.Reason = fmt.Sprintf("%s, retrying on the next apply", state)
+	}
+
+	return change
+}
+
+// applyEc2InstanceStopProtectionChange makes the given change returned by ec2InstanceStopProtectionChange.
+func applyEc2InstanceStopProtectionChange(ctx context.Context, conn *ec2.EC2, change *plannedChange, ownershipTagKey string) error {
+	instanceID := change.ResourceID
+	before := change.Before
+	after := change.After
+
+	modifyAttribute := func(key string, input *ec2.ModifyInstanceAttributeInput) error {
+		if before[key] == after[key] {
+			return nil
+		}
+
+		input.InstanceId = aws.String(instanceID)
+		log.Printf("[DEBUG] Setting %s of EC2 Instance (%s) to %s", key, instanceID, after[key])
+		if _, err := conn.ModifyInstanceAttributeWithContext(ctx, input); err != nil {
+			return fmt.Errorf("error setting %s of EC2 Instance (%s): %w", key, instanceID, err)
+		}
+
+		return nil
+	}
+
+	disableApiStop := &ec2.ModifyInstanceAttributeInput{DisableApiStop: &ec2.AttributeBooleanValue{Value: aws.Bool(after["disable_api_stop"] == "true")}}
+	disableApiTermination := &ec2.ModifyInstanceAttributeInput{DisableApiTermination: &ec2.AttributeBooleanValue{Value: aws.Bool(after["disable_api_termination"] == "true")}}
+
+	if after["state"] == ec2.InstanceStateNameStopped {
+		// The ownership tag is set first, so that the original protection is not lost if the instance is stopped
+		// but the protection cannot be changed.
+		if value, ok := after["ownership_tag"]; ok {
+			if _, err := conn.CreateTagsWithContext(ctx, &ec2.CreateTagsInput{
+				Resources: aws.StringSlice([]string{instanceID}),
+				Tags:      []*ec2.Tag{{Key: aws.String(ownershipTagKey), Value: aws.String(value)}},
+			}); err != nil {
+				return fmt.Errorf("error tagging EC2 Instance (%s): %w", instanceID, err)
+			}
+		}
+
+		if err := modifyAttribute("disable_api_stop", disableApiStop); err != nil {
+			return err
+		}
+
+		if before["state"] == ec2.InstanceStateNameRunning {
+			log.Printf("[INFO] Stopping EC2 Instance (%s)", instanceID)
+			if _, err := conn.StopInstancesWithContext(ctx, &ec2.StopInstancesInput{InstanceIds: aws.StringSlice([]string{instanceID})}); err != nil {
+				return fmt.Errorf("error stopping EC2 Instance (%s): %w", instanceID, err)
+			}
+		}
+
+		return modifyAttribute("disable_api_termination", disableApiTermination)
+	}
+
+	if before["state"] == ec2.InstanceStateNameStopped {
+		log.Printf("[INFO] Starting EC2 Instance (%s)", instanceID)
+		if _, err := conn.StartInstancesWithContext(ctx, &ec2.StartInstancesInput{InstanceIds: aws.StringSlice([]string{instanceID})}); err != nil {
+			return fmt.Errorf("error starting EC2 Instance (%s): %w", instanceID, err)
+		}
+	}
+
+	if err := modifyAttribute("disable_api_stop", disableApiStop); err != nil {
+		return err
+	}
+
+	if err := modifyAttribute("disable_api_termination", disableApiTermination); err != nil {
+		return err
+	}
+
+	// The ownership tag is removed last, so that the protection is restored again on the next apply if it could not
+	// be restored now.
+	if _, err := conn.DeleteTagsWithContext(ctx, &ec2.DeleteTagsInput{
+		Resources: aws.StringSlice([]string{instanceID}),
+		Tags:      []*ec2.Tag{{Key: aws.String(ownershipTagKey)}},
+	}); err != nil {
+		return fmt.Errorf("error untagging EC2 Instance (%s): %w", instanceID, err)
+	}
+
+	return nil
+}
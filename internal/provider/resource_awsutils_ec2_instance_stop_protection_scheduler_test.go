@@ -0,0 +1,307 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2BusinessHoursContains(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatalf("error loading time zone: %s", err)
+	}
+
+	hours := &ec2BusinessHours{
+		Days:     map[time.Weekday]bool{time.Monday: true, time.Tuesday: true},
+		Start:    8 * time.Hour,
+		End:      19 * time.Hour,
+		Location: paris,
+	}
+
+	testCases := []struct {
+		Name     string
+		Time     time.Time
+		Expected bool
+	}{
+		{Name: "during", Time: time.Date(2021, 6, 7, 12, 0, 0, 0, paris), Expected: true},
+		{Name: "at start", Time: time.Date(2021, 6, 7, 8, 0, 0, 0, paris), Expected: true},
+		{Name: "before start", Time: time.Date(2021, 6, 7, 7, 59, 59, 0, paris), Expected: false},
+		{Name: "at end", Time: time.Date(2021, 6, 7, 19, 0, 0, 0, paris), Expected: false},
+		{Name: "other day", Time: time.Date(2021, 6, 9, 12, 0, 0, 0, paris), Expected: false},
+		// 06:30 UTC is 08:30 in Paris in the summer.
+		{Name: "other time zone", Time: time.Date(2021, 6, 7, 6, 30, 0, 0, time.UTC), Expected: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := hours.contains(testCase.Time); got != testCase.Expected {
+				t.Errorf("got %t, expected %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestParseEc2StopProtection(t *testing.T) {
+	for _, protection := range []ec2StopProtection{
+		{},
+		{DisableApiStop: true},
+		{DisableApiTermination: true},
+		{DisableApiStop: true, DisableApiTermination: true},
+	} {
+		if got := parseEc2StopProtection(protection.String()); got != protection {
+			t.Errorf("got %+v, expected %+v", got, protection)
+		}
+	}
+
+	if got := parseEc2StopProtection("invalid"); got != (ec2StopProtection{}) {
+		t.Errorf("got %+v, expected no protection", got)
+	}
+}
+
+func TestEc2InstanceStopProtectionChange(t *testing.T) {
+	owned := []*ec2.Tag{{
+		Key:   aws.String(ec2StopProtectionSchedulerOwnershipTagKey),
+		Value: aws.String(ec2StopProtection{DisableApiStop: true}.String()),
+	}}
+
+	testCases := []struct {
+		Name            string
+		State           string
+		Tags            []*ec2.Tag
+		Protection      ec2StopProtection
+		InBusinessHours bool
+		ExpectedAction  string
+		ExpectedAfter   map[string]string
+	}{
+		{
+			Name:           "running outside of the business hours",
+			State:          ec2.InstanceStateNameRunning,
+			Protection:     ec2StopProtection{DisableApiStop: true},
+			ExpectedAction: plannedChangeActionUpdate,
+			ExpectedAfter: map[string]string{
+				"state":                   ec2.InstanceStateNameStopped,
+				"disable_api_stop":        "false",
+				"disable_api_termination": "true",
+				"ownership_tag":           "disable-api-stop=true disable-api-termination=false",
+			},
+		},
+		{
+			Name:           "owned and started again outside of the business hours",
+			State:          ec2.InstanceStateNameRunning,
+			Tags:           owned,
+			Protection:     ec2StopProtection{DisableApiTermination: true},
+			ExpectedAction: plannedChangeActionUpdate,
+			ExpectedAfter: map[string]string{
+				"state":                   ec2.InstanceStateNameStopped,
+				"disable_api_stop":        "false",
+				"disable_api_termination": "true",
+				"ownership_tag":           "disable-api-stop=true disable-api-termination=false",
+			},
+		},
+		{
+			Name:           "stopped by the scheduler",
+			State:          ec2.InstanceStateNameStopped,
+			Tags:           owned,
+			Protection:     ec2StopProtection{DisableApiTermination: true},
+			ExpectedAction: plannedChangeActionNone,
+		},
+		{
+			Name:           "stopped by the scheduler without termination protection",
+			State:          ec2.InstanceStateNameStopped,
+			Tags:           owned,
+			ExpectedAction: plannedChangeActionUpdate,
+			ExpectedAfter: map[string]string{
+				"state":                   ec2.InstanceStateNameStopped,
+				"disable_api_stop":        "false",
+				"disable_api_termination": "true",
+			},
+		},
+		{
+			Name:           "stopped by something else",
+			State:          ec2.InstanceStateNameStopped,
+			ExpectedAction: plannedChangeActionNone,
+		},
+		{
+			Name:            "stopped by the scheduler during the business hours",
+			State:           ec2.InstanceStateNameStopped,
+			Tags:            owned,
+			Protection:      ec2StopProtection{DisableApiTermination: true},
+			InBusinessHours: true,
+			ExpectedAction:  plannedChangeActionUpdate,
+			ExpectedAfter: map[string]string{
+				"state":                   ec2.InstanceStateNameRunning,
+				"disable_api_stop":        "true",
+				"disable_api_termination": "false",
+			},
+		},
+		{
+			Name:            "stopped by something else during the business hours",
+			State:           ec2.InstanceStateNameStopped,
+			InBusinessHours: true,
+			ExpectedAction:  plannedChangeActionNone,
+		},
+		{
+			Name:            "running during the business hours",
+			State:           ec2.InstanceStateNameRunning,
+			InBusinessHours: true,
+			ExpectedAction:  plannedChangeActionNone,
+		},
+		{
+			Name:            "stopping during the business hours",
+			State:           ec2.InstanceStateNameStopping,
+			Tags:            owned,
+			InBusinessHours: true,
+			ExpectedAction:  plannedChangeActionNone,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			instance := &ec2.Instance{
+				InstanceId: aws.String("i-0123456789abcdef0"),
+				State:      &ec2.InstanceState{Name: aws.String(testCase.State)},
+				Tags:       testCase.Tags,
+			}
+
+			change := ec2InstanceStopProtectionChange(instance, testCase.Protection, ec2StopProtectionSchedulerOwnershipTagKey, testCase.InBusinessHours)
+
+			if change.Action != testCase.ExpectedAction {
+				t.Errorf("got action %q, expected %q", change.Action, testCase.ExpectedAction)
+			}
+
+			if !reflect.DeepEqual(change.After, testCase.ExpectedAfter) {
+				t.Errorf("got after %v, expected %v", change.After, testCase.ExpectedAfter)
+			}
+		})
+	}
+}
+
+func TestApplyEc2InstanceStopProtectionChange(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		State           string
+		Protection      ec2StopProtection
+		Tags            []*ec2.Tag
+		InBusinessHours bool
+		ExpectedActions []string
+	}{
+		{
+			Name:       "stop",
+			State:      ec2.InstanceStateNameRunning,
+			Protection: ec2StopProtection{DisableApiStop: true},
+			ExpectedActions: []string{
+				"CreateTags",
+				"ModifyInstanceAttribute",
+				"StopInstances",
+				"ModifyInstanceAttribute",
+			},
+		},
+		{
+			Name:       "start",
+			State:      ec2.InstanceStateNameStopped,
+			Protection: ec2StopProtection{DisableApiTermination: true},
+			Tags: []*ec2.Tag{{
+				Key:   aws.String(ec2StopProtectionSchedulerOwnershipTagKey),
+				Value: aws.String(ec2StopProtection{}.String()),
+			}},
+			InBusinessHours: true,
+			ExpectedActions: []string{
+				"StartInstances",
+				"ModifyInstanceAttribute",
+				"DeleteTags",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var actions []string
+			conn := testEc2Conn(t, func(r *request.Request) {
+				actions = append(actions, r.Operation.Name)
+			})
+
+			instance := &ec2.Instance{
+				InstanceId: aws.String("i-0123456789abcdef0"),
+				State:      &ec2.InstanceState{Name: aws.String(testCase.State)},
+				Tags:       testCase.Tags,
+			}
+			change := ec2InstanceStopProtectionChange(instance, testCase.Protection, ec2StopProtectionSchedulerOwnershipTagKey, testCase.InBusinessHours)
+
+			if err := applyEc2InstanceStopProtectionChange(context.Background(), conn, change, ec2StopProtectionSchedulerOwnershipTagKey); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(actions, testCase.ExpectedActions) {
+				t.Errorf("got actions %v, expected %v", actions, testCase.ExpectedActions)
+			}
+		})
+	}
+}
+
+func TestScheduleEc2InstanceStopProtectionConfirmationToken(t *testing.T) {
+	instanceIDs := []string{"i-00000001"}
+	var stopped []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeInstancesOutput:
+			var instances []*ec2.Instance
+			for _, instanceID := range instanceIDs {
+				instances = append(instances, &ec2.Instance{
+					InstanceId: aws.String(instanceID),
+					State:      &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+				})
+			}
+			output.Reservations = []*ec2.Reservation{{Instances: instances}}
+		case *ec2.StopInstancesOutput:
+			stopped = append(stopped, aws.StringValueSlice(r.Params.(*ec2.StopInstancesInput).InstanceIds)...)
+		}
+	})
+
+	// A Sunday, outside of the business hours.
+	now := time.Date(2021, 6, 6, 12, 0, 0, 0, time.UTC)
+
+	schedule := func(token string) *schema.ResourceData {
+		d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2InstanceStopProtectionScheduler().Schema, map[string]interface{}{
+			"tags": map[string]interface{}{"Environment": "development"},
+			"business_hours": []interface{}{
+				map[string]interface{}{"days": []interface{}{"mon", "tue", "wed", "thu", "fri"}, "start_time": "08:00", "end_time": "19:00"},
+			},
+			"require_confirmation_token": true,
+			"confirmation_token":         token,
+		})
+
+		if _, err := scheduleEc2InstanceStopProtection(context.Background(), d, &AWSClient{ec2conn: conn}, now); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return d
+	}
+
+	token := schedule("").Get("expected_confirmation_token").(string)
+	if len(stopped) != 0 {
+		t.Fatalf("got stopped instances %v before confirmation, expected none", stopped)
+	}
+
+	// Once the selection changes, the token no longer confirms the changes.
+	instanceIDs = append(instanceIDs, "i-00000002")
+	d := schedule(token)
+	if len(stopped) != 0 {
+		t.Fatalf("got stopped instances %v with the token of another selection, expected none", stopped)
+	}
+	if d.Get("expected_confirmation_token").(string) == token {
+		t.Errorf("expected the token to change with the selection")
+	}
+
+	schedule(d.Get("expected_confirmation_token").(string))
+	if expected := []string{"i-00000001", "i-00000002"}; !reflect.DeepEqual(stopped, expected) {
+		t.Errorf("got stopped instances %v, expected %v", stopped, expected)
+	}
+}
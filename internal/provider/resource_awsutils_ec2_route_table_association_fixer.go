@@ -0,0 +1,399 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceAwsUtilsEc2RouteTableAssociationFixer() *schema.Resource {
+	return &schema.Resource{
+		Description: `Ensures that Subnets are associated with their intended Route Table, fixing the Subnets which are associated
+with another Route Table or fell back to the main Route Table of their VPC.
+
+The intended Route Table of each Subnet is given by ` + "`subnet_route_table_ids`" + `, or, for the Subnets matching the
+given filters, by ` + "`route_table_id`" + `, in which case the filters only select Subnets in the VPC of that Route
+Table. A Subnet explicitly associated with another Route Table has its association replaced, while a Subnet without
+an explicit association, which implicitly uses the main Route Table, is explicitly associated with its Route Table.
+A Subnet implicitly using the main Route Table is left untouched when the main Route Table is its intended one.
+
+Applying this resource repeatedly is a no-op once every Subnet is associated with its Route Table. When ` + "`dry_run`" + `
+is set, the changes are reported in ` + "`planned_changes`" + ` but not made. When ` + "`continue_on_error`" + ` is set,
+the associations which cannot be made are reported in ` + "`failed`" + ` and in an error returned after the remaining changes
+are made. Destroying this resource does not revert the changes.`,
+		CreateContext: resourceAwsEc2RouteTableAssociationFixerCreate,
+		ReadContext:   resourceAwsEc2RouteTableAssociationFixerRead,
+		UpdateContext: resourceAwsEc2RouteTableAssociationFixerUpdate,
+		DeleteContext: resourceAwsEc2RouteTableAssociationFixerDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 Subnets", func(d resourceDataGetter, meta interface{}) (int, []*ec2.Filter, error) {
+			// Only the Subnets selected by filters are counted, as on apply.
+			if d.Get("route_table_id").(string) == "" {
+				return 0, nil, nil
+			}
+			subnets, filters, _, err := findEc2SelectedSubnetsOfRouteTable(d, meta)
+			return len(subnets), filters, err
+		}, "route_table_id"),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"subnet_route_table_ids": {
+				Description: "The ID of the Route Table each Subnet must be associated with, keyed by Subnet ID. Takes precedence over `route_table_id` for the Subnets matching the filters.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+					for subnetID, routeTableID := range v.(map[string]interface{}) {
+						if err := tfec2.ValidateResourceID(ec2.ResourceTypeSubnet, subnetID); err != nil {
+							errors = append(errors, fmt.Errorf("%s: %w", k, err))
+						}
+						if err := tfec2.ValidateResourceID(ec2.ResourceTypeRouteTable, routeTableID.(string)); err != nil {
+							errors = append(errors, fmt.Errorf("%s[%s]: %w", k, subnetID, err))
+						}
+					}
+					return
+				},
+			},
+			"route_table_id": {
+				Description: "The ID of the Route Table the Subnets matching the filters must be associated with. The filters are ignored unless this is set.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+					if err := tfec2.ValidateResourceID(ec2.ResourceTypeRouteTable, v.(string)); err != nil {
+						errors = append(errors, fmt.Errorf("%s: %w", k, err))
+					}
+					return
+				},
+			},
+			"ids":               ec2IDsSchema(ec2.ResourceTypeSubnet),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"dry_run": {
+				Description: "Report the changes without making them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"noncompliant_subnet_ids": {
+				Description: "The IDs of the Subnets which were not associated with their Route Table, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"association_ids": {
+				Description: "The IDs of the explicit associations of the Subnets with their Route Table, keyed by Subnet ID. Subnets implicitly using the main Route Table have none.",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"warn_if_matches_over":    ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":    ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning": ec2MatchThresholdWarningSchema(),
+			"continue_on_error":       continueOnErrorSchema(),
+			"planned_changes":         plannedChangesSchema(),
+			"failed":                  failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2RouteTableAssociationFixerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := fixEc2RouteTableAssociations(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2RouteTableAssociationFixerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2RouteTableAssociationFixerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2RouteTableAssociationFixerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := fixEc2RouteTableAssociations(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2RouteTableAssociationFixerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2RouteTableAssociationFixerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// fixEc2RouteTableAssociations associates the selected Subnets with their intended Route Table, recording the
+// outcome in the given *schema.ResourceData.
+// It returns the warning of warn_if_matches_over, if any.
+func fixEc2RouteTableAssociations(d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+	routeTableID := d.Get("route_table_id").(string)
+
+	desired := make(map[string]string)
+	for subnetID, v := range d.Get("subnet_route_table_ids").(map[string]interface{}) {
+		desired[subnetID] = v.(string)
+	}
+
+	if len(desired) == 0 && routeTableID == "" {
+		return nil, fmt.Errorf("one of subnet_route_table_ids and route_table_id must be set")
+	}
+
+	var subnets []*ec2.Subnet
+	var warnings diag.Diagnostics
+
+	if len(desired) > 0 {
+		subnetIDs := make([]string, 0, len(desired))
+		for subnetID := range desired {
+			subnetIDs = append(subnetIDs, subnetID)
+		}
+		sort.Strings(subnetIDs)
+
+		mapped, err := finder.Subnets(conn, &ec2.DescribeSubnetsInput{SubnetIds: aws.StringSlice(subnetIDs)}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 Subnets: %w", err)
+		}
+		subnets = append(subnets, mapped...)
+	}
+
+	if routeTableID != "" {
+		selected, filters, selectionWarnings, err := findEc2SelectedSubnetsOfRouteTable(d, meta)
+		if err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, selectionWarnings...)
+
+		// Only the Subnets selected by filters are counted, as those of subnet_route_table_ids are given one by one.
+		thresholdWarnings, err := checkEc2MatchThresholds(d, len(selected), "EC2 Subnets", filters)
+		if err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, thresholdWarnings...)
+
+		for _, subnet := range selected {
+			subnetID := aws.StringValue(subnet.SubnetId)
+			if _, ok := desired[subnetID]; ok {
+				continue
+			}
+			desired[subnetID] = routeTableID
+			subnets = append(subnets, subnet)
+		}
+	}
+
+	var vpcIDs []string
+	for _, subnet := range subnets {
+		vpcIDs = appendUniqueString(vpcIDs, aws.StringValue(subnet.VpcId))
+	}
+	sort.Strings(vpcIDs)
+
+	var routeTables []*ec2.RouteTable
+	if len(vpcIDs) > 0 {
+		// All the Route Tables of the VPCs are read, so that the main Route Tables of Subnets without an explicit
+		// association are found as well.
+		var err error
+		routeTables, err = finder.RouteTables(conn, &ec2.DescribeRouteTablesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: aws.StringSlice(vpcIDs),
+				},
+			},
+		}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 Route Tables: %w", err)
+		}
+	}
+
+	changes, err := ec2RouteTableAssociationChanges(subnets, desired, routeTables)
+	if err != nil {
+		return nil, err
+	}
+
+	var noncompliant []string
+	associationIDs := make(map[string]string)
+	for _, change := range changes {
+		if change.Action == plannedChangeActionNone {
+			if associationID := change.Before["association_id"]; associationID != "" {
+				associationIDs[change.ResourceID] = associationID
+			}
+			continue
+		}
+		noncompliant = append(noncompliant, change.ResourceID)
+	}
+
+	err = applyPlannedChanges(changes, d.Get("dry_run").(bool), d.Get("continue_on_error").(bool), func(change *plannedChange) error {
+		subnetID := change.ResourceID
+		routeTableID := change.After["route_table_id"]
+
+		if change.Action == plannedChangeActionUpdate {
+			associationID := change.Before["association_id"]
+
+			log.Printf("[INFO] Replacing EC2 Route Table association (%s) of EC2 Subnet (%s) with EC2 Route Table (%s)", associationID, subnetID, routeTableID)
+			output, err := conn.ReplaceRouteTableAssociation(&ec2.ReplaceRouteTableAssociationInput{
+				AssociationId: aws.String(associationID),
+				RouteTableId:  aws.String(routeTableID),
+			})
+			if err != nil {
+				return fmt.Errorf("error replacing EC2 Route Table association (%s) of EC2 Subnet (%s): %w", associationID, subnetID, err)
+			}
+
+			associationIDs[subnetID] = aws.StringValue(output.NewAssociationId)
+			change.After["association_id"] = aws.StringValue(output.NewAssociationId)
+
+			return nil
+		}
+
+		log.Printf("[INFO] Associating EC2 Subnet (%s) with EC2 Route Table (%s)", subnetID, routeTableID)
+		output, err := conn.AssociateRouteTable(&ec2.AssociateRouteTableInput{
+			RouteTableId: aws.String(routeTableID),
+			SubnetId:     aws.String(subnetID),
+		})
+		if err != nil {
+			return fmt.Errorf("error associating EC2 Subnet (%s) with EC2 Route Table (%s): %w", subnetID, routeTableID, err)
+		}
+
+		associationIDs[subnetID] = aws.StringValue(output.AssociationId)
+		change.After["association_id"] = aws.StringValue(output.AssociationId)
+
+		return nil
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("association_ids", associationIDs); err != nil {
+		return nil, fmt.Errorf("error setting association_ids: %w", err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("noncompliant_subnet_ids", noncompliant); err != nil {
+		return nil, fmt.Errorf("error setting noncompliant_subnet_ids: %w", err)
+	}
+
+	return warnings, nil
+}
+
+// findEc2SelectedSubnetsOfRouteTable reads the Subnets selected by the resource with the given resourceDataGetter in
+// the VPC of its route_table_id, as findEc2SelectedVpcs does the VPCs.
+func findEc2SelectedSubnetsOfRouteTable(d resourceDataGetter, meta interface{}) ([]*ec2.Subnet, []*ec2.Filter, diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+	routeTableID := d.Get("route_table_id").(string)
+
+	routeTables, err := finder.RouteTables(conn, &ec2.DescribeRouteTablesInput{RouteTableIds: aws.StringSlice([]string{routeTableID})}, 0)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading EC2 Route Table (%s): %w", routeTableID, err)
+	}
+	if len(routeTables) == 0 {
+		return nil, nil, nil, fmt.Errorf("EC2 Route Table (%s) not found", routeTableID)
+	}
+
+	input := &ec2.DescribeSubnetsInput{}
+	ids, filters, warnings, err := buildEC2Selection(d, meta, ec2.ResourceTypeSubnet)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	input.SubnetIds = ids
+	input.Filters = append(filters, &ec2.Filter{
+		Name:   aws.String("vpc-id"),
+		Values: aws.StringSlice([]string{aws.StringValue(routeTables[0].VpcId)}),
+	})
+
+	subnets, err := finder.Subnets(conn, input, ec2FailIfMatchesOver(d))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading EC2 Subnets: %w", ec2FailIfMatchesOverError(err, input.Filters))
+	}
+
+	return subnets, input.Filters, warnings, nil
+}
+
+// ec2RouteTableAssociationChanges returns the changes, ordered by Subnet ID, associating each of the given Subnets
+// with its desired Route Table among the given Route Tables of their VPCs. A Subnet explicitly associated with
+// another Route Table has its association replaced, with the update action, while a Subnet implicitly using the main
+// Route Table is explicitly associated, with the create action, unless the main Route Table is the desired one. It
+// is an error for the desired Route Table of a Subnet not to be among the Route Tables of its VPC.
+func ec2RouteTableAssociationChanges(subnets []*ec2.Subnet, desired map[string]string, routeTables []*ec2.RouteTable) ([]*plannedChange, error) {
+	index := newEc2RouteTableIndex(routeTables)
+
+	vpcIDs := make(map[string]string, len(routeTables))
+	for _, routeTable := range routeTables {
+		vpcIDs[aws.StringValue(routeTable.RouteTableId)] = aws.StringValue(routeTable.VpcId)
+	}
+
+	sorted := append([]*ec2.Subnet{}, subnets...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return aws.StringValue(sorted[i].SubnetId) < aws.StringValue(sorted[j].SubnetId)
+	})
+
+	changes := make([]*plannedChange, 0, len(sorted))
+	for _, subnet := range sorted {
+		subnetID := aws.StringValue(subnet.SubnetId)
+		vpcID := aws.StringValue(subnet.VpcId)
+		routeTableID := desired[subnetID]
+
+		if vpcIDs[routeTableID] != vpcID {
+			return nil, fmt.Errorf("EC2 Route Table (%s) for EC2 Subnet (%s) not found in VPC (%s)", routeTableID, subnetID, vpcID)
+		}
+
+		current, explicit := index.forSubnet(subnet)
+		currentID := ""
+		if current != nil {
+			currentID = aws.StringValue(current.RouteTableId)
+		}
+
+		change := &plannedChange{
+			ResourceID: subnetID,
+			Before:     map[string]string{"route_table_id": currentID},
+			After:      map[string]string{"route_table_id": routeTableID},
+		}
+
+		switch {
+		case explicit && currentID == routeTableID:
+			change.Action = plannedChangeActionNone
+			change.Reason = "Subnet is associated with its Route Table"
+			change.Before["association_id"] = ec2SubnetRouteTableAssociationID(current, subnetID)
+		case explicit:
+			change.Action = plannedChangeActionUpdate
+			change.Reason = "Subnet is associated with another Route Table"
+			change.Before["association_id"] = ec2SubnetRouteTableAssociationID(current, subnetID)
+		case currentID == routeTableID:
+			change.Action = plannedChangeActionNone
+			change.Reason = "Subnet implicitly uses its Route Table, the main Route Table"
+		default:
+			change.Action = plannedChangeActionCreate
+			change.Reason = "Subnet implicitly uses the main Route Table"
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// ec2SubnetRouteTableAssociationID returns the ID of the association of the given Route Table with the Subnet with
+// the given ID, or an empty string if there is none.
+func ec2SubnetRouteTableAssociationID(routeTable *ec2.RouteTable, subnetID string) string {
+	for _, association := range routeTable.Associations {
+		if association != nil && aws.StringValue(association.SubnetId) == subnetID {
+			return aws.StringValue(association.RouteTableAssociationId)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestEc2RouteTableAssociationChanges(t *testing.T) {
+	routeTables := []*ec2.RouteTable{
+		{
+			RouteTableId: aws.String("rtb-00000001"),
+			VpcId:        aws.String("vpc-01234567"),
+			Associations: []*ec2.RouteTableAssociation{
+				{RouteTableAssociationId: aws.String("rtbassoc-00000001"), Main: aws.Bool(true)},
+			},
+		},
+		{
+			RouteTableId: aws.String("rtb-00000002"),
+			VpcId:        aws.String("vpc-01234567"),
+			Associations: []*ec2.RouteTableAssociation{
+				{RouteTableAssociationId: aws.String("rtbassoc-00000002"), SubnetId: aws.String("subnet-00000001")},
+			},
+		},
+		{
+			RouteTableId: aws.String("rtb-00000003"),
+			VpcId:        aws.String("vpc-01234567"),
+		},
+		{
+			RouteTableId: aws.String("rtb-00000004"),
+			VpcId:        aws.String("vpc-89abcdef"),
+		},
+	}
+
+	subnet := func(id string) *ec2.Subnet {
+		return &ec2.Subnet{SubnetId: aws.String(id), VpcId: aws.String("vpc-01234567")}
+	}
+
+	testCases := []struct {
+		Name     string
+		Subnets  []*ec2.Subnet
+		Desired  map[string]string
+		Expected []*plannedChange
+		Error    bool
+	}{
+		{
+			Name:    "explicitly associated with its Route Table",
+			Subnets: []*ec2.Subnet{subnet("subnet-00000001")},
+			Desired: map[string]string{"subnet-00000001": "rtb-00000002"},
+			Expected: []*plannedChange{
+				{
+					ResourceID: "subnet-00000001",
+					Action:     plannedChangeActionNone,
+					Reason:     "Subnet is associated with its Route Table",
+					Before:     map[string]string{"route_table_id": "rtb-00000002", "association_id": "rtbassoc-00000002"},
+					After:      map[string]string{"route_table_id": "rtb-00000002"},
+				},
+			},
+		},
+		{
+			Name:    "explicitly associated with another Route Table",
+			Subnets: []*ec2.Subnet{subnet("subnet-00000001")},
+			Desired: map[string]string{"subnet-00000001": "rtb-00000003"},
+			Expected: []*plannedChange{
+				{
+					ResourceID: "subnet-00000001",
+					Action:     plannedChangeActionUpdate,
+					Reason:     "Subnet is associated with another Route Table",
+					Before:     map[string]string{"route_table_id": "rtb-00000002", "association_id": "rtbassoc-00000002"},
+					After:      map[string]string{"route_table_id": "rtb-00000003"},
+				},
+			},
+		},
+		{
+			Name:    "implicitly using the main Route Table",
+			Subnets: []*ec2.Subnet{subnet("subnet-00000002")},
+			Desired: map[string]string{"subnet-00000002": "rtb-00000003"},
+			Expected: []*plannedChange{
+				{
+					ResourceID: "subnet-00000002",
+					Action:     plannedChangeActionCreate,
+					Reason:     "Subnet implicitly uses the main Route Table",
+					Before:     map[string]string{"route_table_id": "rtb-00000001"},
+					After:      map[string]string{"route_table_id": "rtb-00000003"},
+				},
+			},
+		},
+		{
+			Name:    "main Route Table intended",
+			Subnets: []*ec2.Subnet{subnet("subnet-00000002")},
+			Desired: map[string]string{"subnet-00000002": "rtb-00000001"},
+			Expected: []*plannedChange{
+				{
+					ResourceID: "subnet-00000002",
+					Action:     plannedChangeActionNone,
+					Reason:     "Subnet implicitly uses its Route Table, the main Route Table",
+					Before:     map[string]string{"route_table_id": "rtb-00000001"},
+					After:      map[string]string{"route_table_id": "rtb-00000001"},
+				},
+			},
+		},
+		{
+			Name:    "ordered by Subnet ID",
+			Subnets: []*ec2.Subnet{subnet("subnet-00000002"), subnet("subnet-00000001")},
+			Desired: map[string]string{"subnet-00000001": "rtb-00000002", "subnet-00000002": "rtb-00000001"},
+			Expected: []*plannedChange{
+				{
+					ResourceID: "subnet-00000001",
+					Action:     plannedChangeActionNone,
+					Reason:     "Subnet is associated with its Route Table",
+					Before:     map[string]string{"route_table_id": "rtb-00000002", "association_id": "rtbassoc-00000002"},
+					After:      map[string]string{"route_table_id": "rtb-00000002"},
+				},
+				{
+					ResourceID: "subnet-00000002",
+					Action:     plannedChangeActionNone,
+					Reason:     "Subnet implicitly uses its Route Table, the main Route Table",
+					Before:     map[string]string{"route_table_id": "rtb-00000001"},
+					After:      map[string]string{"route_table_id": "rtb-00000001"},
+				},
+			},
+		},
+		{
+			Name:    "Route Table in another VPC",
+			Subnets: []*ec2.Subnet{subnet("subnet-00000001")},
+			Desired: map[string]string{"subnet-00000001": "rtb-00000004"},
+			Error:   true,
+		},
+		{
+			Name:    "unknown Route Table",
+			Subnets: []*ec2.Subnet{subnet("subnet-00000001")},
+			Desired: map[string]string{"subnet-00000001": "rtb-0000000f"},
+			Error:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, err := ec2RouteTableAssociationChanges(testCase.Subnets, testCase.Desired, routeTables)
+
+			if testCase.Error {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
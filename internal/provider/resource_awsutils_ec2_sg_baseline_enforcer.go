@@ -0,0 +1,491 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// ec2SgDefaultEgressCidrIpv4 is the destination of the allow-all egress rule AWS adds to every new Security Group.
+const ec2SgDefaultEgressCidrIpv4 = "0.0.0.0/0"
+
+// ec2SgProtocolNames maps the protocol numbers of the Security Group Rules AWS returns by name to these names.
+var ec2SgProtocolNames = map[string]string{
+	"1":   "icmp",
+	"6":   "tcp",
+	"17":  "udp",
+	"58":  "icmpv6",
+	"all": "-1",
+}
+
+// ec2SgBaselineRule is an egress rule every selected Security Group must have, normalized so that it can be
+// compared with the existing rules.
+type ec2SgBaselineRule struct {
+	Protocol     string
+	FromPort     int64
+	ToPort       int64
+	CidrIpv4     string
+	CidrIpv6     string
+	PrefixListID string
+	// ReferencedGroupID is set for rules to other Security Groups, which no baseline rule matches.
+	ReferencedGroupID string
+	Description       string
+}
+
+func resourceAwsUtilsEc2SgBaselineEnforcer() *schema.Resource {
+	return &schema.Resource{
+		Description: `Ensures that the Security Groups matching the given filters have a baseline set of egress rules, such as
+allowing DNS, and not the allow-all egress rule AWS adds to every new Security Group.
+
+Each ` + "`baseline_egress_rule`" + ` missing from a Security Group is added, a rule being present when an egress rule
+with the same protocol, ports and destination exists, whatever its description. Unless ` + "`revoke_default_egress`" + `
+is unset, the default egress rule is then revoked. Only a rule identical to the one AWS creates, allowing all
+protocols to ` + "`0.0.0.0/0`" + ` without a description, is considered the default, so intentional egress rules,
+including described allow-all rules, are left untouched. Rules are never revoked from a Security Group whose baseline
+itself allows all egress to ` + "`0.0.0.0/0`" + `.
+
+Applying this resource repeatedly is a no-op once the baseline is in place. When ` + "`dry_run`" + ` is set, the
+changes are reported in ` + "`planned_changes`" + ` but not made. When ` + "`require_confirmation_token`" + ` is set,
+they are only made once ` + "`confirmation_token`" + ` is set to the ` + "`expected_confirmation_token`" + ` of the
+changes, which changes whenever they do, forcing a new review. When ` + "`continue_on_error`" + ` is set, the rules
+which cannot be added or revoked are reported in ` + "`failed`" + ` and in an error returned after the remaining changes are
+made. Destroying this resource does not revert the changes.`,
+		CreateContext: resourceAwsEc2SgBaselineEnforcerCreate,
+		ReadContext:   resourceAwsEc2SgBaselineEnforcerRead,
+		UpdateContext: resourceAwsEc2SgBaselineEnforcerUpdate,
+		DeleteContext: resourceAwsEc2SgBaselineEnforcerDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 Security Groups", countEc2SelectedSecurityGroups),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeSecurityGroup),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"baseline_egress_rule": {
+				Description: "An egress rule every selected Security Group must have.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"protocol": {
+							Description:  "The protocol of the rule, such as `tcp`, `udp` or `icmp`, or `-1` for all protocols.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+						},
+						"from_port": {
+							Description:  "The start of the port range, or the ICMP type.",
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(-1, 65535),
+						},
+						"to_port": {
+							Description:  "The end of the port range, or the ICMP code.",
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(-1, 65535),
+						},
+						"cidr_ipv4": {
+							Description:  "The IPv4 CIDR block the rule allows traffic to.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.IsCIDR,
+						},
+						"cidr_ipv6": {
+							Description:  "The IPv6 CIDR block the rule allows traffic to.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.IsCIDR,
+						},
+						"prefix_list_id": {
+							Description: "The ID of the managed prefix list the rule allows traffic to.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+								if err := tfec2.ValidateResourceID(ec2.ResourceTypePrefixList, v.(string)); err != nil {
+									errors = append(errors, fmt.Errorf("%s: %w", k, err))
+								}
+								return
+							},
+						},
+						"description": {
+							Description:  "The description of the rule when it is added.",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringLenBetween(0, sgRuleDescriptionMaxLength),
+						},
+					},
+				},
+			},
+			"revoke_default_egress": {
+				Description: "Whether to revoke the default allow-all egress rule.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			"dry_run": {
+				Description: "Report the changes without making them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"require_confirmation_token":  requireConfirmationTokenSchema(),
+			"confirmation_token":          confirmationTokenSchema(),
+			"expected_confirmation_token": expectedConfirmationTokenSchema(),
+			"warn_if_matches_over":        ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":        ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning":     ec2MatchThresholdWarningSchema(),
+			"continue_on_error":           continueOnErrorSchema(),
+			"planned_changes":             plannedChangesSchema(),
+			"failed":                      failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2SgBaselineEnforcerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := enforceEc2SgBaseline(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2SgBaselineEnforcerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2SgBaselineEnforcerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2SgBaselineEnforcerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := enforceEc2SgBaseline(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2SgBaselineEnforcerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2SgBaselineEnforcerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// enforceEc2SgBaseline adds the missing baseline egress rules to the selected Security Groups and revokes their
+// default egress rule, recording the outcome in the given *schema.ResourceData.
+// It returns the warnings of warn_if_matches_over and of require_confirmation_token, if any.
+func enforceEc2SgBaseline(d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+
+	baseline, err := expandEc2SgBaselineRules(d.Get("baseline_egress_rule").([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+
+	groups, filters, warnings, err := findEc2SelectedSecurityGroups(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(groups), "EC2 Security Groups", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	groupIDs := make([]string, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, aws.StringValue(group.GroupId))
+	}
+	sort.Strings(groupIDs)
+
+	rules, err := finder.SecurityGroupRulesForGroups(conn, groupIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error reading EC2 Security Group Rules: %w", err)
+	}
+
+	rulesByGroupID := make(map[string][]*ec2.SecurityGroupRule, len(groupIDs))
+	for _, rule := range rules {
+		rulesByGroupID[aws.StringValue(rule.GroupId)] = append(rulesByGroupID[aws.StringValue(rule.GroupId)], rule)
+	}
+
+	var changes []*plannedChange
+	additions := make(map[*plannedChange]ec2SgBaselineRule)
+
+	for _, groupID := range groupIDs {
+		groupChanges, groupAdditions := ec2SgBaselineChanges(groupID, rulesByGroupID[groupID], baseline, d.Get("revoke_default_egress").(bool))
+		changes = append(changes, groupChanges...)
+		for change, rule := range groupAdditions {
+			additions[change] = rule
+		}
+	}
+
+	confirmed, confirmationWarnings, err := checkConfirmationToken(d, changes)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, confirmationWarnings...)
+
+	err = applyPlannedChanges(changes, d.Get("dry_run").(bool) || !confirmed, d.Get("continue_on_error").(bool), func(change *plannedChange) error {
+		if change.Action == plannedChangeActionDelete {
+			groupID := change.Before["group_id"]
+
+			log.Printf("[INFO] Revoking default egress rule (%s) of EC2 Security Group (%s)", change.ResourceID, groupID)
+			_, err := conn.RevokeSecurityGroupEgress(&ec2.RevokeSecurityGroupEgressInput{
+				GroupId:              aws.String(groupID),
+				SecurityGroupRuleIds: aws.StringSlice([]string{change.ResourceID}),
+			})
+			if err != nil {
+				return fmt.Errorf("error revoking EC2 Security Group Rule (%s) of EC2 Security Group (%s): %w", change.ResourceID, groupID, err)
+			}
+
+			return nil
+		}
+
+		rule := additions[change]
+
+		log.Printf("[INFO] Adding baseline egress rule to EC2 Security Group (%s): %v", change.ResourceID, change.After)
+		_, err := conn.AuthorizeSecurityGroupEgress(&ec2.AuthorizeSecurityGroupEgressInput{
+			GroupId:       aws.String(change.ResourceID),
+			IpPermissions: []*ec2.IpPermission{rule.ipPermission()},
+		})
+		if err != nil {
+			return fmt.Errorf("error adding egress rule to EC2 Security Group (%s): %w", change.ResourceID, err)
+		}
+
+		return nil
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	return warnings, err
+}
+
+// expandEc2SgBaselineRules expands the "baseline_egress_rule" blocks. It is an error for a rule not to have
+// exactly one destination.
+func expandEc2SgBaselineRules(l []interface{}) ([]ec2SgBaselineRule, error) {
+	rules := make([]ec2SgBaselineRule, 0, len(l))
+
+	for i, v := range l {
+		m := v.(map[string]interface{})
+		rule := ec2SgBaselineRule{
+			Protocol:     normalizeEc2SgProtocol(m["protocol"].(string)),
+			FromPort:     int64(m["from_port"].(int)),
+			ToPort:       int64(m["to_port"].(int)),
+			CidrIpv4:     m["cidr_ipv4"].(string),
+			CidrIpv6:     m["cidr_ipv6"].(string),
+			PrefixListID: m["prefix_list_id"].(string),
+			Description:  m["description"].(string),
+		}
+
+		var destinations int
+		for _, destination := range []string{rule.CidrIpv4, rule.CidrIpv6, rule.PrefixListID} {
+			if destination != "" {
+				destinations++
+			}
+		}
+		if destinations != 1 {
+			return nil, fmt.Errorf("baseline_egress_rule.%d: exactly one of cidr_ipv4, cidr_ipv6 and prefix_list_id must be set", i)
+		}
+
+		rules = append(rules, rule.normalized())
+	}
+
+	return rules, nil
+}
+
+// ec2SgBaselineChanges returns the changes bringing the given rules of a Security Group in line
+// with the given baseline: the addition of each missing baseline rule, mapped to the rule, followed by the revocation
+// of the default egress rule if revokeDefault is set. A single change with the none action is returned for a Security
+// Group already in line with the baseline.
+func ec2SgBaselineChanges(groupID string, rules []*ec2.SecurityGroupRule, baseline []ec2SgBaselineRule, revokeDefault bool) ([]*plannedChange, map[*plannedChange]ec2SgBaselineRule) {
+	var changes []*plannedChange
+	additions := make(map[*plannedChange]ec2SgBaselineRule)
+
+	existing := make(map[ec2SgBaselineRule]bool, len(rules))
+	for _, rule := range rules {
+		if aws.BoolValue(rule.IsEgress) {
+			existing[ec2SgBaselineRuleFromSecurityGroupRule(rule).withoutDescription()] = true
+		}
+	}
+
+	baselineAllowsAll := false
+	for _, rule := range baseline {
+		if rule.withoutDescription() == ec2SgDefaultEgressRule() {
+			baselineAllowsAll = true
+		}
+
+		if existing[rule.withoutDescription()] {
+			continue
+		}
+		existing[rule.withoutDescription()] = true
+
+		change := &plannedChange{
+			ResourceID: groupID,
+			Action:     plannedChangeActionCreate,
+			Reason:     "baseline egress rule is missing",
+			After:      rule.flatten(),
+		}
+		changes = append(changes, change)
+		additions[change] = rule
+	}
+
+	if revokeDefault && !baselineAllowsAll {
+		sorted := append([]*ec2.SecurityGroupRule{}, rules...)
+		sort.Slice(sorted, func(i, j int) bool {
+			return aws.StringValue(sorted[i].SecurityGroupRuleId) < aws.StringValue(sorted[j].SecurityGroupRuleId)
+		})
+
+		for _, rule := range sorted {
+			if !isEc2SgDefaultEgressRule(rule) {
+				continue
+			}
+
+			before := ec2SgBaselineRuleFromSecurityGroupRule(rule).flatten()
+			before["group_id"] = groupID
+
+			changes = append(changes, &plannedChange{
+				ResourceID: aws.StringValue(rule.SecurityGroupRuleId),
+				Action:     plannedChangeActionDelete,
+				Reason:     "default allow-all egress rule",
+				Before:     before,
+			})
+		}
+	}
+
+	if len(changes) == 0 {
+		changes = append(changes, &plannedChange{
+			ResourceID: groupID,
+			Action:     plannedChangeActionNone,
+			Reason:     "baseline is in place",
+		})
+	}
+
+	return changes, additions
+}
+
+// ec2SgDefaultEgressRule returns the allow-all egress rule AWS adds to every new Security Group.
+func ec2SgDefaultEgressRule() ec2SgBaselineRule {
+	return ec2SgBaselineRule{
+		Protocol: "-1",
+		FromPort: -1,
+		ToPort:   -1,
+		CidrIpv4: ec2SgDefaultEgressCidrIpv4,
+	}
+}
+
+// isEc2SgDefaultEgressRule returns whether the given rule is identical to the allow-all egress rule AWS adds to
+// every new Security Group, which has no description.
+func isEc2SgDefaultEgressRule(rule *ec2.SecurityGroupRule) bool {
+	return aws.BoolValue(rule.IsEgress) && ec2SgBaselineRuleFromSecurityGroupRule(rule) == ec2SgDefaultEgressRule()
+}
+
+// ec2SgBaselineRuleFromSecurityGroupRule returns the normalized given Security Group Rule.
+func ec2SgBaselineRuleFromSecurityGroupRule(rule *ec2.SecurityGroupRule) ec2SgBaselineRule {
+	r := ec2SgBaselineRule{
+		Protocol:     normalizeEc2SgProtocol(aws.StringValue(rule.IpProtocol)),
+		FromPort:     aws.Int64Value(rule.FromPort),
+		ToPort:       aws.Int64Value(rule.ToPort),
+		CidrIpv4:     aws.StringValue(rule.CidrIpv4),
+		CidrIpv6:     aws.StringValue(rule.CidrIpv6),
+		PrefixListID: aws.StringValue(rule.PrefixListId),
+		Description:  aws.StringValue(rule.Description),
+	}
+
+	if rule.ReferencedGroupInfo != nil {
+		r.ReferencedGroupID = aws.StringValue(rule.ReferencedGroupInfo.GroupId)
+	}
+
+	return r.normalized()
+}
+
+// normalizeEc2SgProtocol returns the name AWS uses for the given Security Group Rule protocol.
+func normalizeEc2SgProtocol(protocol string) string {
+	protocol = strings.ToLower(protocol)
+	if name, ok := ec2SgProtocolNames[protocol]; ok {
+		return name
+	}
+	return protocol
+}
+
+// normalized returns the rule with the ports of an all-protocols rule, which AWS ignores, set to -1.
+func (r ec2SgBaselineRule) normalized() ec2SgBaselineRule {
+	if r.Protocol == "-1" {
+		r.FromPort = -1
+		r.ToPort = -1
+	}
+	return r
+}
+
+// withoutDescription returns the rule without its description, for comparison with other rules.
+func (r ec2SgBaselineRule) withoutDescription() ec2SgBaselineRule {
+	r.Description = ""
+	return r
+}
+
+// flatten returns the non-empty attributes of the rule, for planned_changes.
+func (r ec2SgBaselineRule) flatten() map[string]string {
+	m := map[string]string{
+		"protocol":  r.Protocol,
+		"from_port": strconv.FormatInt(r.FromPort, 10),
+		"to_port":   strconv.FormatInt(r.ToPort, 10),
+	}
+
+	for k, v := range map[string]string{
+		"cidr_ipv4":           r.CidrIpv4,
+		"cidr_ipv6":           r.CidrIpv6,
+		"prefix_list_id":      r.PrefixListID,
+		"referenced_group_id": r.ReferencedGroupID,
+		"description":         r.Description,
+	} {
+		if v != "" {
+			m[k] = v
+		}
+	}
+
+	return m
+}
+
+// ipPermission returns the permission authorizing the rule.
+func (r ec2SgBaselineRule) ipPermission() *ec2.IpPermission {
+	permission := &ec2.IpPermission{
+		IpProtocol: aws.String(r.Protocol),
+		FromPort:   aws.Int64(r.FromPort),
+		ToPort:     aws.Int64(r.ToPort),
+	}
+
+	var description *string
+	if r.Description != "" {
+		description = aws.String(r.Description)
+	}
+
+	switch {
+	case r.CidrIpv4 != "":
+		permission.IpRanges = []*ec2.IpRange{{CidrIp: aws.String(r.CidrIpv4), Description: description}}
+	case r.CidrIpv6 != "":
+		permission.Ipv6Ranges = []*ec2.Ipv6Range{{CidrIpv6: aws.String(r.CidrIpv6), Description: description}}
+	case r.PrefixListID != "":
+		permission.PrefixListIds = []*ec2.PrefixListId{{PrefixListId: aws.String(r.PrefixListID), Description: description}}
+	case r.ReferencedGroupID != "":
+		permission.UserIdGroupPairs = []*ec2.UserIdGroupPair{{GroupId: aws.String(r.ReferencedGroupID), Description: description}}
+	}
+
+	return permission
+}
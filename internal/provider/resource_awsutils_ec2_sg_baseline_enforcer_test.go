@@ -0,0 +1,253 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2SgBaselineChanges(t *testing.T) {
+	dns := ec2SgBaselineRule{Protocol: "udp", FromPort: 53, ToPort: 53, CidrIpv4: "10.0.0.2/32", Description: "DNS"}
+
+	defaultEgress := &ec2.SecurityGroupRule{
+		SecurityGroupRuleId: aws.String("sgr-00000001"),
+		IsEgress:            aws.Bool(true),
+		IpProtocol:          aws.String("-1"),
+		FromPort:            aws.Int64(-1),
+		ToPort:              aws.Int64(-1),
+		CidrIpv4:            aws.String("0.0.0.0/0"),
+	}
+	describedAllowAll := &ec2.SecurityGroupRule{
+		SecurityGroupRuleId: aws.String("sgr-00000002"),
+		IsEgress:            aws.Bool(true),
+		IpProtocol:          aws.String("-1"),
+		FromPort:            aws.Int64(-1),
+		ToPort:              aws.Int64(-1),
+		CidrIpv4:            aws.String("0.0.0.0/0"),
+		Description:         aws.String("intentional"),
+	}
+	ipv6AllowAll := &ec2.SecurityGroupRule{
+		SecurityGroupRuleId: aws.String("sgr-00000003"),
+		IsEgress:            aws.Bool(true),
+		IpProtocol:          aws.String("-1"),
+		FromPort:            aws.Int64(-1),
+		ToPort:              aws.Int64(-1),
+		CidrIpv6:            aws.String("::/0"),
+	}
+	ingressAllowAll := &ec2.SecurityGroupRule{
+		SecurityGroupRuleId: aws.String("sgr-00000004"),
+		IsEgress:            aws.Bool(false),
+		IpProtocol:          aws.String("-1"),
+		FromPort:            aws.Int64(-1),
+		ToPort:              aws.Int64(-1),
+		CidrIpv4:            aws.String("0.0.0.0/0"),
+	}
+	existingDNS := &ec2.SecurityGroupRule{
+		SecurityGroupRuleId: aws.String("sgr-00000005"),
+		IsEgress:            aws.Bool(true),
+		IpProtocol:          aws.String("17"),
+		FromPort:            aws.Int64(53),
+		ToPort:              aws.Int64(53),
+		CidrIpv4:            aws.String("10.0.0.2/32"),
+		Description:         aws.String("resolver"),
+	}
+
+	testCases := []struct {
+		Name          string
+		Rules         []*ec2.SecurityGroupRule
+		Baseline      []ec2SgBaselineRule
+		RevokeDefault bool
+		Expected      []*plannedChange
+	}{
+		{
+			Name:          "new Security Group",
+			Rules:         []*ec2.SecurityGroupRule{defaultEgress},
+			Baseline:      []ec2SgBaselineRule{dns},
+			RevokeDefault: true,
+			Expected: []*plannedChange{
+				{
+					ResourceID: "sg-01234567",
+					Action:     plannedChangeActionCreate,
+					Reason:     "baseline egress rule is missing",
+					After:      map[string]string{"protocol": "udp", "from_port": "53", "to_port": "53", "cidr_ipv4": "10.0.0.2/32", "description": "DNS"},
+				},
+				{
+					ResourceID: "sgr-00000001",
+					Action:     plannedChangeActionDelete,
+					Reason:     "default allow-all egress rule",
+					Before:     map[string]string{"protocol": "-1", "from_port": "-1", "to_port": "-1", "cidr_ipv4": "0.0.0.0/0", "group_id": "sg-01234567"},
+				},
+			},
+		},
+		{
+			Name:     "default egress kept",
+			Rules:    []*ec2.SecurityGroupRule{defaultEgress},
+			Baseline: []ec2SgBaselineRule{dns},
+			Expected: []*plannedChange{
+				{
+					ResourceID: "sg-01234567",
+					Action:     plannedChangeActionCreate,
+					Reason:     "baseline egress rule is missing",
+					After:      map[string]string{"protocol": "udp", "from_port": "53", "to_port": "53", "cidr_ipv4": "10.0.0.2/32", "description": "DNS"},
+				},
+			},
+		},
+		{
+			Name:          "intentional rules untouched",
+			Rules:         []*ec2.SecurityGroupRule{describedAllowAll, ipv6AllowAll, ingressAllowAll, existingDNS},
+			Baseline:      []ec2SgBaselineRule{dns},
+			RevokeDefault: true,
+			Expected: []*plannedChange{
+				{
+					ResourceID: "sg-01234567",
+					Action:     plannedChangeActionNone,
+					Reason:     "baseline is in place",
+				},
+			},
+		},
+		{
+			Name:          "baseline allowing all egress",
+			Rules:         []*ec2.SecurityGroupRule{defaultEgress},
+			Baseline:      []ec2SgBaselineRule{ec2SgDefaultEgressRule()},
+			RevokeDefault: true,
+			Expected: []*plannedChange{
+				{
+					ResourceID: "sg-01234567",
+					Action:     plannedChangeActionNone,
+					Reason:     "baseline is in place",
+				},
+			},
+		},
+		{
+			Name:          "duplicate baseline rules",
+			Baseline:      []ec2SgBaselineRule{dns, dns.withoutDescription()},
+			RevokeDefault: true,
+			Expected: []*plannedChange{
+				{
+					ResourceID: "sg-01234567",
+					Action:     plannedChangeActionCreate,
+					Reason:     "baseline egress rule is missing",
+					After:      map[string]string{"protocol": "udp", "from_port": "53", "to_port": "53", "cidr_ipv4": "10.0.0.2/32", "description": "DNS"},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, additions := ec2SgBaselineChanges("sg-01234567", testCase.Rules, testCase.Baseline, testCase.RevokeDefault)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+
+			for _, change := range got {
+				if _, ok := additions[change]; ok != (change.Action == plannedChangeActionCreate) {
+					t.Errorf("got addition %t for %s change", ok, change.Action)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandEc2SgBaselineRules(t *testing.T) {
+	rule := func(protocol string, cidrIpv4, prefixListID string) map[string]interface{} {
+		return map[string]interface{}{
+			"protocol":       protocol,
+			"from_port":      443,
+			"to_port":        443,
+			"cidr_ipv4":      cidrIpv4,
+			"cidr_ipv6":      "",
+			"prefix_list_id": prefixListID,
+			"description":    "",
+		}
+	}
+
+	got, err := expandEc2SgBaselineRules([]interface{}{rule("TCP", "10.0.0.0/8", ""), rule("all", "10.0.0.0/8", "")})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []ec2SgBaselineRule{
+		{Protocol: "tcp", FromPort: 443, ToPort: 443, CidrIpv4: "10.0.0.0/8"},
+		{Protocol: "-1", FromPort: -1, ToPort: -1, CidrIpv4: "10.0.0.0/8"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+
+	for _, invalid := range []map[string]interface{}{rule("tcp", "", ""), rule("tcp", "10.0.0.0/8", "pl-01234567")} {
+		if _, err := expandEc2SgBaselineRules([]interface{}{invalid}); err == nil {
+			t.Errorf("expected an error for %v", invalid)
+		}
+	}
+}
+
+func TestEnforceEc2SgBaselineConfirmationToken(t *testing.T) {
+	groupIDs := []string{"sg-00000001"}
+	var revoked []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeSecurityGroupsOutput:
+			for _, groupID := range groupIDs {
+				output.SecurityGroups = append(output.SecurityGroups, &ec2.SecurityGroup{GroupId: aws.String(groupID)})
+			}
+		case *ec2.DescribeSecurityGroupRulesOutput:
+			// Every Security Group only has the default egress rule.
+			for _, groupID := range groupIDs {
+				output.SecurityGroupRules = append(output.SecurityGroupRules, &ec2.SecurityGroupRule{
+					SecurityGroupRuleId: aws.String("sgr-" + groupID[len("sg-"):]),
+					GroupId:             aws.String(groupID),
+					IsEgress:            aws.Bool(true),
+					IpProtocol:          aws.String("-1"),
+					FromPort:            aws.Int64(-1),
+					ToPort:              aws.Int64(-1),
+					CidrIpv4:            aws.String("0.0.0.0/0"),
+				})
+			}
+		case *ec2.RevokeSecurityGroupEgressOutput:
+			revoked = append(revoked, aws.StringValueSlice(r.Params.(*ec2.RevokeSecurityGroupEgressInput).SecurityGroupRuleIds)...)
+		}
+	})
+
+	enforce := func(token string) *schema.ResourceData {
+		d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2SgBaselineEnforcer().Schema, map[string]interface{}{
+			"filter": []interface{}{
+				map[string]interface{}{"name": "vpc-id", "values": []interface{}{"vpc-01234567"}},
+			},
+			"require_confirmation_token": true,
+			"confirmation_token":         token,
+		})
+
+		if _, err := enforceEc2SgBaseline(d, &AWSClient{ec2conn: conn}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return d
+	}
+
+	token := enforce("").Get("expected_confirmation_token").(string)
+	if len(revoked) != 0 {
+		t.Fatalf("got revoked rules %v before confirmation, expected none", revoked)
+	}
+
+	// Once the selection changes, the token no longer confirms the changes.
+	groupIDs = append(groupIDs, "sg-00000002")
+	d := enforce(token)
+	if len(revoked) != 0 {
+		t.Fatalf("got revoked rules %v with the token of another selection, expected none", revoked)
+	}
+	if d.Get("expected_confirmation_token").(string) == token {
+		t.Errorf("expected the token to change with the selection")
+	}
+
+	enforce(d.Get("expected_confirmation_token").(string))
+	if expected := []string{"sgr-00000001", "sgr-00000002"}; !reflect.DeepEqual(revoked, expected) {
+		t.Errorf("got revoked rules %v, expected %v", revoked, expected)
+	}
+}
@@ -0,0 +1,424 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ec2SgRuleSetJSON is the rule set described by the rules_json attribute of awsutils_ec2_sg_rule_importer_from_json.
+type ec2SgRuleSetJSON struct {
+	Ingress []ec2SgRuleJSON `json:"ingress"`
+	Egress  []ec2SgRuleJSON `json:"egress"`
+}
+
+// ec2SgRuleJSON is a rule of an ec2SgRuleSetJSON, with exactly one source or destination.
+type ec2SgRuleJSON struct {
+	Protocol          string `json:"protocol"`
+	FromPort          *int64 `json:"from_port"`
+	ToPort            *int64 `json:"to_port"`
+	CidrIpv4          string `json:"cidr_ipv4"`
+	CidrIpv6          string `json:"cidr_ipv6"`
+	PrefixListID      string `json:"prefix_list_id"`
+	ReferencedGroupID string `json:"referenced_group_id"`
+	Description       string `json:"description"`
+}
+
+// ec2SgImportedRule is a rule of a Security Group, ingress or egress, normalized so that it can be compared with
+// the existing rules.
+type ec2SgImportedRule struct {
+	Egress bool
+	Rule   ec2SgBaselineRule
+}
+
+func resourceAwsUtilsEc2SgRuleImporterFromJson() *schema.Resource {
+	return &schema.Resource{
+		Description: `Reconciles the rules of an existing Security Group with a rule set described in JSON, such as one exported
+from another tool.
+
+` + "`rules_json`" + ` is an object with ` + "`ingress`" + ` and ` + "`egress`" + ` lists of rules, each with a
+` + "`protocol`" + `, a ` + "`from_port`" + ` and a ` + "`to_port`" + `, exactly one of ` + "`cidr_ipv4`" + `,
+` + "`cidr_ipv6`" + `, ` + "`prefix_list_id`" + ` and ` + "`referenced_group_id`" + `, and an optional
+` + "`description`" + `. Its shape is validated at plan time. The rules of the rule set missing from the Security Group
+are authorized, and then the rules of the Security Group missing from the rule set are revoked, including the default
+allow-all egress rule unless the rule set has it. A rule is present when a rule with the same direction, protocol,
+ports and source or destination exists, whatever its description. The description of a present rule is only updated
+when the rule set gives one, so the existing descriptions are otherwise preserved.
+
+Applying this resource repeatedly is a no-op once the rules match. When ` + "`dry_run`" + ` is set, the changes are
+reported in ` + "`planned_changes`" + ` but not made. When ` + "`require_confirmation_token`" + ` is set, they are
+only made once ` + "`confirmation_token`" + ` is set to the ` + "`expected_confirmation_token`" + ` of the changes, which
+changes whenever they do, forcing a new review. When ` + "`continue_on_error`" + ` is set, the rules which cannot be
+changed are reported in ` + "`failed`" + ` and in an error returned after the remaining changes are made. Destroying
+this resource does not revert the changes.`,
+		CreateContext: resourceAwsEc2SgRuleImporterFromJsonCreate,
+		ReadContext:   resourceAwsEc2SgRuleImporterFromJsonRead,
+		UpdateContext: resourceAwsEc2SgRuleImporterFromJsonUpdate,
+		DeleteContext: resourceAwsEc2SgRuleImporterFromJsonDelete,
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"security_group_id": {
+				Description: "The ID of the Security Group whose rules are reconciled.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+					if err := tfec2.ValidateResourceID(ec2.ResourceTypeSecurityGroup, v.(string)); err != nil {
+						errors = append(errors, fmt.Errorf("%s: %w", k, err))
+					}
+					return
+				},
+			},
+			"rules_json": {
+				Description: "The JSON rule set the rules of the Security Group must match.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+					if _, err := expandEc2SgRuleSetJSON(v.(string)); err != nil {
+						errors = append(errors, fmt.Errorf("%s: %w", k, err))
+					}
+					return
+				},
+			},
+			"dry_run": {
+				Description: "Report the changes without making them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"require_confirmation_token":  requireConfirmationTokenSchema(),
+			"confirmation_token":          confirmationTokenSchema(),
+			"expected_confirmation_token": expectedConfirmationTokenSchema(),
+			"continue_on_error":           continueOnErrorSchema(),
+			"planned_changes":             plannedChangesSchema(),
+			"failed":                      failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2SgRuleImporterFromJsonCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := importEc2SgRulesFromJSON(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2SgRuleImporterFromJsonRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2SgRuleImporterFromJsonRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2SgRuleImporterFromJsonUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := importEc2SgRulesFromJSON(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2SgRuleImporterFromJsonRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2SgRuleImporterFromJsonDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// importEc2SgRulesFromJSON reconciles the rules of the Security Group with the rule set, recording the outcome in
+// the given *schema.ResourceData. It returns the warning of require_confirmation_token, if any.
+func importEc2SgRulesFromJSON(ctx context.Context, d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+	groupID := d.Get("security_group_id").(string)
+
+	desired, err := expandEc2SgRuleSetJSON(d.Get("rules_json").(string))
+	if err != nil {
+		return nil, fmt.Errorf("rules_json: %w", err)
+	}
+
+	rules, err := finder.SecurityGroupRulesForGroups(conn, []string{groupID})
+	if err != nil {
+		return nil, fmt.Errorf("error reading EC2 Security Group Rules of EC2 Security Group (%s): %w", groupID, err)
+	}
+
+	changes, additions := ec2SgRuleImportChanges(groupID, rules, desired)
+
+	rulesByID := make(map[string]*ec2.SecurityGroupRule, len(rules))
+	for _, rule := range rules {
+		rulesByID[aws.StringValue(rule.SecurityGroupRuleId)] = rule
+	}
+
+	confirmed, warnings, err := checkConfirmationToken(d, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = applyPlannedChanges(changes, d.Get("dry_run").(bool) || !confirmed, d.Get("continue_on_error").(bool), func(change *plannedChange) error {
+		var err error
+
+		switch change.Action {
+		case plannedChangeActionCreate:
+			rule := additions[change]
+			permissions := []*ec2.IpPermission{rule.Rule.ipPermission()}
+
+			log.Printf("[INFO] Authorizing rule of EC2 Security Group (%s): %v", groupID, change.After)
+			if rule.Egress {
+				_, err = conn.AuthorizeSecurityGroupEgressWithContext(ctx, &ec2.AuthorizeSecurityGroupEgressInput{GroupId: aws.String(groupID), IpPermissions: permissions})
+			} else {
+				_, err = conn.AuthorizeSecurityGroupIngressWithContext(ctx, &ec2.AuthorizeSecurityGroupIngressInput{GroupId: aws.String(groupID), IpPermissions: permissions})
+			}
+			if err != nil {
+				return fmt.Errorf("error authorizing rule of EC2 Security Group (%s): %w", groupID, err)
+			}
+		case plannedChangeActionUpdate:
+			rule := rulesByID[change.ResourceID]
+
+			log.Printf("[INFO] Updating description of EC2 Security Group Rule (%s)", change.ResourceID)
+			_, err = conn.ModifySecurityGroupRulesWithContext(ctx, &ec2.ModifySecurityGroupRulesInput{
+				GroupId: aws.String(groupID),
+				SecurityGroupRules: []*ec2.SecurityGroupRuleUpdate{
+					{
+						SecurityGroupRuleId: rule.SecurityGroupRuleId,
+						SecurityGroupRule:   sgRuleRequest(rule, change.After["description"]),
+					},
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("error modifying EC2 Security Group Rule (%s): %w", change.ResourceID, err)
+			}
+		case plannedChangeActionDelete:
+			ruleIDs := aws.StringSlice([]string{change.ResourceID})
+
+			log.Printf("[INFO] Revoking EC2 Security Group Rule (%s) of EC2 Security Group (%s)", change.ResourceID, groupID)
+			if aws.BoolValue(rulesByID[change.ResourceID].IsEgress) {
+				_, err = conn.RevokeSecurityGroupEgressWithContext(ctx, &ec2.RevokeSecurityGroupEgressInput{GroupId: aws.String(groupID), SecurityGroupRuleIds: ruleIDs})
+			} else {
+				_, err = conn.RevokeSecurityGroupIngressWithContext(ctx, &ec2.RevokeSecurityGroupIngressInput{GroupId: aws.String(groupID), SecurityGroupRuleIds: ruleIDs})
+			}
+			if err != nil {
+				return fmt.Errorf("error revoking EC2 Security Group Rule (%s) of EC2 Security Group (%s): %w", change.ResourceID, groupID, err)
+			}
+		}
+
+		return nil
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	return warnings, err
+}
+
+// expandEc2SgRuleSetJSON parses and validates the given rules_json, returning its rules normalized and
+// deduplicated. It is an error for the JSON to have unknown attributes, or for a rule to be invalid.
+func expandEc2SgRuleSetJSON(s string) ([]ec2SgImportedRule, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(s)))
+	decoder.DisallowUnknownFields()
+
+	var ruleSet ec2SgRuleSetJSON
+	if err := decoder.Decode(&ruleSet); err != nil {
+		return nil, fmt.Errorf("invalid rule set: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("invalid rule set: unexpected data after the rule set object")
+	}
+
+	var rules []ec2SgImportedRule
+	seen := make(map[ec2SgImportedRule]bool)
+
+	for _, direction := range []struct {
+		name   string
+		egress bool
+		rules  []ec2SgRuleJSON
+	}{
+		{"ingress", false, ruleSet.Ingress},
+		{"egress", true, ruleSet.Egress},
+	} {
+		for i, r := range direction.rules {
+			rule, err := r.expand()
+			if err != nil {
+				return nil, fmt.Errorf("%s.%d: %w", direction.name, i, err)
+			}
+
+			imported := ec2SgImportedRule{Egress: direction.egress, Rule: rule}
+			if key := imported.withoutDescription(); !seen[key] {
+				seen[key] = true
+				rules = append(rules, imported)
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// expand validates the rule and returns it normalized.
+func (r ec2SgRuleJSON) expand() (ec2SgBaselineRule, error) {
+	if r.Protocol == "" {
+		return ec2SgBaselineRule{}, fmt.Errorf("protocol is required")
+	}
+
+	rule := ec2SgBaselineRule{
+		Protocol:          normalizeEc2SgProtocol(r.Protocol),
+		FromPort:          -1,
+		ToPort:            -1,
+		CidrIpv4:          r.CidrIpv4,
+		CidrIpv6:          r.CidrIpv6,
+		PrefixListID:      r.PrefixListID,
+		ReferencedGroupID: r.ReferencedGroupID,
+		Description:       r.Description,
+	}
+
+	if rule.Protocol != "-1" {
+		if r.FromPort == nil || r.ToPort == nil {
+			return ec2SgBaselineRule{}, fmt.Errorf("from_port and to_port are required for protocol %s", r.Protocol)
+		}
+		rule.FromPort = *r.FromPort
+		rule.ToPort = *r.ToPort
+	}
+
+	for _, port := range []int64{rule.FromPort, rule.ToPort} {
+		if port < -1 || port > 65535 {
+			return ec2SgBaselineRule{}, fmt.Errorf("port %d must be between -1 and 65535", port)
+		}
+	}
+
+	var targets int
+	for _, target := range []string{rule.CidrIpv4, rule.CidrIpv6, rule.PrefixListID, rule.ReferencedGroupID} {
+		if target != "" {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return ec2SgBaselineRule{}, fmt.Errorf("exactly one of cidr_ipv4, cidr_ipv6, prefix_list_id and referenced_group_id must be set")
+	}
+
+	for _, cidr := range []string{rule.CidrIpv4, rule.CidrIpv6} {
+		if cidr == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return ec2SgBaselineRule{}, fmt.Errorf("invalid CIDR block %q", cidr)
+		}
+	}
+
+	if rule.PrefixListID != "" {
+		if err := tfec2.ValidateResourceID(ec2.ResourceTypePrefixList, rule.PrefixListID); err != nil {
+			return ec2SgBaselineRule{}, fmt.Errorf("prefix_list_id: %w", err)
+		}
+	}
+
+	if rule.ReferencedGroupID != "" {
+		if err := tfec2.ValidateResourceID(ec2.ResourceTypeSecurityGroup, rule.ReferencedGroupID); err != nil {
+			return ec2SgBaselineRule{}, fmt.Errorf("referenced_group_id: %w", err)
+		}
+	}
+
+	if len(rule.Description) > sgRuleDescriptionMaxLength {
+		return ec2SgBaselineRule{}, fmt.Errorf("description exceeds %d characters", sgRuleDescriptionMaxLength)
+	}
+
+	return rule.normalized(), nil
+}
+
+// withoutDescription returns the rule without its description, for comparison with other rules.
+func (r ec2SgImportedRule) withoutDescription() ec2SgImportedRule {
+	r.Rule = r.Rule.withoutDescription()
+	return r
+}
+
+// flatten returns the attributes of the rule, for planned_changes.
+func (r ec2SgImportedRule) flatten() map[string]string {
+	m := r.Rule.flatten()
+	m["direction"] = "ingress"
+	if r.Egress {
+		m["direction"] = "egress"
+	}
+	return m
+}
+
+// ec2SgRuleImportChanges returns the changes reconciling the given rules of a Security Group with the given desired
+// rules: the authorization of each missing rule, mapped to the rule, followed by the description updates, and then
+// the revocation of each extra rule. A single change with the none action is returned for a Security Group whose
+// rules already match.
+func ec2SgRuleImportChanges(groupID string, rules []*ec2.SecurityGroupRule, desired []ec2SgImportedRule) ([]*plannedChange, map[*plannedChange]ec2SgImportedRule) {
+	var additions, updates, revocations []*plannedChange
+	additionRules := make(map[*plannedChange]ec2SgImportedRule)
+
+	sorted := append([]*ec2.SecurityGroupRule{}, rules...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return aws.StringValue(sorted[i].SecurityGroupRuleId) < aws.StringValue(sorted[j].SecurityGroupRuleId)
+	})
+
+	wanted := make(map[ec2SgImportedRule]ec2SgImportedRule, len(desired))
+	for _, rule := range desired {
+		wanted[rule.withoutDescription()] = rule
+	}
+
+	existing := make(map[ec2SgImportedRule]bool, len(sorted))
+	for _, rule := range sorted {
+		current := ec2SgImportedRule{Egress: aws.BoolValue(rule.IsEgress), Rule: ec2SgBaselineRuleFromSecurityGroupRule(rule)}
+		key := current.withoutDescription()
+		ruleID := aws.StringValue(rule.SecurityGroupRuleId)
+
+		desiredRule, ok := wanted[key]
+		switch {
+		case !ok || existing[key]:
+			// Duplicates of a desired rule cannot be created by the API, but are revoked as extra if they exist.
+			revocations = append(revocations, &plannedChange{
+				ResourceID: ruleID,
+				Action:     plannedChangeActionDelete,
+				Reason:     "rule is not in the rule set",
+				Before:     current.flatten(),
+			})
+		case desiredRule.Rule.Description != "" && desiredRule.Rule.Description != current.Rule.Description:
+			updates = append(updates, &plannedChange{
+				ResourceID: ruleID,
+				Action:     plannedChangeActionUpdate,
+				Reason:     "description differs from the rule set",
+				Before:     map[string]string{"description": current.Rule.Description},
+				After:      map[string]string{"description": desiredRule.Rule.Description},
+			})
+		}
+		existing[key] = true
+	}
+
+	for _, rule := range desired {
+		if existing[rule.withoutDescription()] {
+			continue
+		}
+
+		change := &plannedChange{
+			ResourceID: groupID,
+			Action:     plannedChangeActionCreate,
+			Reason:     "rule of the rule set is missing",
+			After:      rule.flatten(),
+		}
+		additions = append(additions, change)
+		additionRules[change] = rule
+	}
+
+	// The missing rules are authorized before the extra rules are revoked, so that traffic allowed by both the old and
+	// the new rules is never interrupted.
+	changes := append(append(additions, updates...), revocations...)
+
+	if len(changes) == 0 {
+		changes = append(changes, &plannedChange{
+			ResourceID: groupID,
+			Action:     plannedChangeActionNone,
+			Reason:     "rules match the rule set",
+		})
+	}
+
+	return changes, additionRules
+}
@@ -0,0 +1,306 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandEc2SgRuleSetJSON(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		JSON          string
+		Expected      []ec2SgImportedRule
+		ExpectedError string
+	}{
+		{
+			Name: "ingress and egress",
+			JSON: `{
+				"ingress": [
+					{"protocol": "TCP", "from_port": 443, "to_port": 443, "cidr_ipv4": "0.0.0.0/0", "description": "HTTPS"},
+					{"protocol": "tcp", "from_port": 443, "to_port": 443, "cidr_ipv4": "0.0.0.0/0", "description": "duplicate"}
+				],
+				"egress": [
+					{"protocol": "-1", "from_port": 0, "to_port": 0, "referenced_group_id": "sg-01234567"}
+				]
+			}`,
+			Expected: []ec2SgImportedRule{
+				{Rule: ec2SgBaselineRule{Protocol: "tcp", FromPort: 443, ToPort: 443, CidrIpv4: "0.0.0.0/0", Description: "HTTPS"}},
+				{Egress: true, Rule: ec2SgBaselineRule{Protocol: "-1", FromPort: -1, ToPort: -1, ReferencedGroupID: "sg-01234567"}},
+			},
+		},
+		{
+			Name: "all protocols without ports",
+			JSON: `{"egress": [{"protocol": "all", "cidr_ipv6": "::/0"}]}`,
+			Expected: []ec2SgImportedRule{
+				{Egress: true, Rule: ec2SgBaselineRule{Protocol: "-1", FromPort: -1, ToPort: -1, CidrIpv6: "::/0"}},
+			},
+		},
+		{
+			Name: "empty",
+			JSON: `{}`,
+		},
+		{
+			Name:          "not JSON",
+			JSON:          `ingress`,
+			ExpectedError: "invalid rule set",
+		},
+		{
+			Name:          "unknown attribute",
+			JSON:          `{"ingress": [{"protocol": "tcp", "from_port": 22, "to_port": 22, "cidr": "10.0.0.0/8"}]}`,
+			ExpectedError: `unknown field "cidr"`,
+		},
+		{
+			Name:          "trailing data",
+			JSON:          `{} {}`,
+			ExpectedError: "unexpected data",
+		},
+		{
+			Name:          "missing protocol",
+			JSON:          `{"ingress": [{"from_port": 22, "to_port": 22, "cidr_ipv4": "10.0.0.0/8"}]}`,
+			ExpectedError: "ingress.0: protocol is required",
+		},
+		{
+			Name:          "missing ports",
+			JSON:          `{"ingress": [{"protocol": "tcp", "cidr_ipv4": "10.0.0.0/8"}]}`,
+			ExpectedError: "ingress.0: from_port and to_port are required",
+		},
+		{
+			Name:          "port out of range",
+			JSON:          `{"ingress": [{"protocol": "tcp", "from_port": 22, "to_port": 65536, "cidr_ipv4": "10.0.0.0/8"}]}`,
+			ExpectedError: "port 65536",
+		},
+		{
+			Name:          "no source",
+			JSON:          `{"egress": [{"protocol": "tcp", "from_port": 22, "to_port": 22}]}`,
+			ExpectedError: "egress.0: exactly one of",
+		},
+		{
+			Name:          "several sources",
+			JSON:          `{"ingress": [{"protocol": "tcp", "from_port": 22, "to_port": 22, "cidr_ipv4": "10.0.0.0/8", "prefix_list_id": "pl-01234567"}]}`,
+			ExpectedError: "ingress.0: exactly one of",
+		},
+		{
+			Name:          "invalid CIDR block",
+			JSON:          `{"ingress": [{"protocol": "tcp", "from_port": 22, "to_port": 22, "cidr_ipv4": "10.0.0.0"}]}`,
+			ExpectedError: "invalid CIDR block",
+		},
+		{
+			Name:          "invalid referenced group",
+			JSON:          `{"ingress": [{"protocol": "tcp", "from_port": 22, "to_port": 22, "referenced_group_id": "vpc-01234567"}]}`,
+			ExpectedError: "referenced_group_id",
+		},
+		{
+			Name:          "description too long",
+			JSON:          `{"ingress": [{"protocol": "tcp", "from_port": 22, "to_port": 22, "cidr_ipv4": "10.0.0.0/8", "description": "` + strings.Repeat("a", 256) + `"}]}`,
+			ExpectedError: "description exceeds",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, err := expandEc2SgRuleSetJSON(testCase.JSON)
+
+			if testCase.ExpectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.ExpectedError) {
+					t.Fatalf("got error %v, expected %q", err, testCase.ExpectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestEc2SgRuleImportChanges(t *testing.T) {
+	const groupID = "sg-01234567"
+
+	https := &ec2.SecurityGroupRule{
+		SecurityGroupRuleId: aws.String("sgr-00000001"),
+		GroupId:             aws.String(groupID),
+		IsEgress:            aws.Bool(false),
+		IpProtocol:          aws.String("tcp"),
+		FromPort:            aws.Int64(443),
+		ToPort:              aws.Int64(443),
+		CidrIpv4:            aws.String("0.0.0.0/0"),
+		Description:         aws.String("managed elsewhere"),
+	}
+	ssh := &ec2.SecurityGroupRule{
+		SecurityGroupRuleId: aws.String("sgr-00000002"),
+		GroupId:             aws.String(groupID),
+		IsEgress:            aws.Bool(false),
+		IpProtocol:          aws.String("tcp"),
+		FromPort:            aws.Int64(22),
+		ToPort:              aws.Int64(22),
+		CidrIpv4:            aws.String("0.0.0.0/0"),
+	}
+	defaultEgress := &ec2.SecurityGroupRule{
+		SecurityGroupRuleId: aws.String("sgr-00000003"),
+		GroupId:             aws.String(groupID),
+		IsEgress:            aws.Bool(true),
+		IpProtocol:          aws.String("-1"),
+		FromPort:            aws.Int64(-1),
+		ToPort:              aws.Int64(-1),
+		CidrIpv4:            aws.String("0.0.0.0/0"),
+	}
+
+	httpsRule := ec2SgImportedRule{Rule: ec2SgBaselineRule{Protocol: "tcp", FromPort: 443, ToPort: 443, CidrIpv4: "0.0.0.0/0"}}
+	defaultEgressRule := ec2SgImportedRule{Egress: true, Rule: ec2SgDefaultEgressRule()}
+	dnsRule := ec2SgImportedRule{Egress: true, Rule: ec2SgBaselineRule{Protocol: "udp", FromPort: 53, ToPort: 53, CidrIpv4: "10.0.0.2/32", Description: "DNS"}}
+
+	type expectedChange struct {
+		ResourceID string
+		Action     string
+	}
+
+	testCases := []struct {
+		Name     string
+		Rules    []*ec2.SecurityGroupRule
+		Desired  []ec2SgImportedRule
+		Expected []expectedChange
+	}{
+		{
+			Name:    "matching, preserving descriptions",
+			Rules:   []*ec2.SecurityGroupRule{https, defaultEgress},
+			Desired: []ec2SgImportedRule{httpsRule, defaultEgressRule},
+			Expected: []expectedChange{
+				{ResourceID: groupID, Action: plannedChangeActionNone},
+			},
+		},
+		{
+			Name:    "authorize missing before revoking extra",
+			Rules:   []*ec2.SecurityGroupRule{defaultEgress, ssh, https},
+			Desired: []ec2SgImportedRule{httpsRule, dnsRule},
+			Expected: []expectedChange{
+				{ResourceID: groupID, Action: plannedChangeActionCreate},
+				{ResourceID: "sgr-00000002", Action: plannedChangeActionDelete},
+				{ResourceID: "sgr-00000003", Action: plannedChangeActionDelete},
+			},
+		},
+		{
+			Name:  "description given",
+			Rules: []*ec2.SecurityGroupRule{https},
+			Desired: []ec2SgImportedRule{
+				{Rule: ec2SgBaselineRule{Protocol: "tcp", FromPort: 443, ToPort: 443, CidrIpv4: "0.0.0.0/0", Description: "HTTPS"}},
+			},
+			Expected: []expectedChange{
+				{ResourceID: "sgr-00000001", Action: plannedChangeActionUpdate},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			changes, additions := ec2SgRuleImportChanges(groupID, testCase.Rules, testCase.Desired)
+
+			got := make([]expectedChange, 0, len(changes))
+			for _, change := range changes {
+				got = append(got, expectedChange{ResourceID: change.ResourceID, Action: change.Action})
+
+				if change.Action == plannedChangeActionCreate {
+					if _, ok := additions[change]; !ok {
+						t.Errorf("no rule mapped to the addition %v", change.After)
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestEc2SgBaselineRuleIpPermissionReferencedGroup(t *testing.T) {
+	rule := ec2SgBaselineRule{Protocol: "tcp", FromPort: 5432, ToPort: 5432, ReferencedGroupID: "sg-01234567", Description: "app"}
+
+	expected := &ec2.IpPermission{
+		IpProtocol:       aws.String("tcp"),
+		FromPort:         aws.Int64(5432),
+		ToPort:           aws.Int64(5432),
+		UserIdGroupPairs: []*ec2.UserIdGroupPair{{GroupId: aws.String("sg-01234567"), Description: aws.String("app")}},
+	}
+
+	if got := rule.ipPermission(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestImportEc2SgRulesFromJSONConfirmationToken(t *testing.T) {
+	rules := []*ec2.SecurityGroupRule{
+		{
+			SecurityGroupRuleId: aws.String("sgr-00000001"),
+			GroupId:             aws.String("sg-01234567"),
+			IsEgress:            aws.Bool(false),
+			IpProtocol:          aws.String("tcp"),
+			FromPort:            aws.Int64(22),
+			ToPort:              aws.Int64(22),
+			CidrIpv4:            aws.String("0.0.0.0/0"),
+		},
+	}
+	var revoked []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeSecurityGroupRulesOutput:
+			output.SecurityGroupRules = rules
+		case *ec2.RevokeSecurityGroupIngressOutput:
+			revoked = append(revoked, aws.StringValueSlice(r.Params.(*ec2.RevokeSecurityGroupIngressInput).SecurityGroupRuleIds)...)
+		}
+	})
+
+	importRules := func(token string) *schema.ResourceData {
+		d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2SgRuleImporterFromJson().Schema, map[string]interface{}{
+			"security_group_id":          "sg-01234567",
+			"rules_json":                 `{"ingress": [], "egress": []}`,
+			"require_confirmation_token": true,
+			"confirmation_token":         token,
+		})
+
+		if _, err := importEc2SgRulesFromJSON(context.Background(), d, &AWSClient{ec2conn: conn}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return d
+	}
+
+	token := importRules("").Get("expected_confirmation_token").(string)
+	if len(revoked) != 0 {
+		t.Fatalf("got revoked rules %v before confirmation, expected none", revoked)
+	}
+
+	// Once the rules of the Security Group change, the token no longer confirms the changes.
+	rules = append(rules, &ec2.SecurityGroupRule{
+		SecurityGroupRuleId: aws.String("sgr-00000002"),
+		GroupId:             aws.String("sg-01234567"),
+		IsEgress:            aws.Bool(false),
+		IpProtocol:          aws.String("tcp"),
+		FromPort:            aws.Int64(3389),
+		ToPort:              aws.Int64(3389),
+		CidrIpv4:            aws.String("0.0.0.0/0"),
+	})
+	d := importRules(token)
+	if len(revoked) != 0 {
+		t.Fatalf("got revoked rules %v with the token of other changes, expected none", revoked)
+	}
+	if d.Get("expected_confirmation_token").(string) == token {
+		t.Errorf("expected the token to change with the rules")
+	}
+
+	importRules(d.Get("expected_confirmation_token").(string))
+	if expected := []string{"sgr-00000001", "sgr-00000002"}; !reflect.DeepEqual(revoked, expected) {
+		t.Errorf("got revoked rules %v, expected %v", revoked, expected)
+	}
+}
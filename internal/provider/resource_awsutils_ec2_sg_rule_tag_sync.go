@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	// sgRuleDescriptionValuePlaceholder is replaced by the tag value in description templates.
+	sgRuleDescriptionValuePlaceholder = "{value}"
+	// sgRuleDescriptionMaxLength is the maximum length of a Security Group Rule description.
+	sgRuleDescriptionMaxLength = 255
+)
+
+func resourceAwsUtilsEc2SgRuleTagSync() *schema.Resource {
+	return &schema.Resource{
+		Description: `Keeps the descriptions of Security Group Rules in sync with the tags of their Security Group.
+
+Security Group Rules cannot be tagged, so metadata is commonly encoded in their descriptions instead. For each
+Security Group matching the given filters, ` + "`description_mapping`" + ` maps tag keys to description templates in which
+` + "`{value}`" + ` is replaced by the tag value. The rendered templates of the tags present on the Security Group, ordered
+by tag key, are joined with ` + "`separator`" + ` to form the description of each of its rules.
+
+Rules of Security Groups carrying none of the mapped tags are left untouched, as are rules whose description is already
+in sync, so applying this resource repeatedly is a no-op. Only ingress rules are updated unless ` + "`include_egress`" + `
+is set. When ` + "`dry_run`" + ` is set, the out of sync rules are reported in ` + "`planned_changes`" + ` but not modified.
+When ` + "`continue_on_error`" + ` is set, rules which cannot be modified are reported in ` + "`failed`" + ` and in an error
+returned after the remaining rules are updated. Destroying this resource does not restore the previous descriptions.`,
+		CreateContext: resourceAwsEc2SgRuleTagSyncCreate,
+		ReadContext:   resourceAwsEc2SgRuleTagSyncRead,
+		UpdateContext: resourceAwsEc2SgRuleTagSyncUpdate,
+		DeleteContext: resourceAwsEc2SgRuleTagSyncDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 Security Groups", countEc2SelectedSecurityGroups),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeSecurityGroup),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"description_mapping": {
+				Description: "Description templates keyed by Security Group tag key. `{value}` is replaced by the tag value.",
+				Type:        schema.TypeMap,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"separator": {
+				Description:  "The separator used to join the rendered templates of several mapped tags.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "; ",
+				ValidateFunc: validation.StringLenBetween(1, 10),
+			},
+			"include_egress": {
+				Description: "Whether egress rules should be updated in addition to ingress rules.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"dry_run": {
+				Description: "Report the out of sync rules without modifying them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"warn_if_matches_over":    ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":    ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning": ec2MatchThresholdWarningSchema(),
+			"continue_on_error":       continueOnErrorSchema(),
+			"planned_changes":         plannedChangesSchema(),
+			"failed":                  failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2SgRuleTagSyncCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := syncEc2SgRuleDescriptions(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2SgRuleTagSyncRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2SgRuleTagSyncRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2SgRuleTagSyncUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := syncEc2SgRuleDescriptions(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2SgRuleTagSyncRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2SgRuleTagSyncDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// syncEc2SgRuleDescriptions updates the description of each of the selected Security Group Rules which is out of
+// sync with the tags of its Security Group, recording the outcome in the given *schema.ResourceData.
+// It returns the warning of warn_if_matches_over, if any.
+func syncEc2SgRuleDescriptions(d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+	includeEgress := d.Get("include_egress").(bool)
+	separator := d.Get("separator").(string)
+
+	mapping := make(map[string]string)
+	for k, v := range d.Get("description_mapping").(map[string]interface{}) {
+		mapping[k] = v.(string)
+	}
+
+	groups, filters, warnings, err := findEc2SelectedSecurityGroups(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(groups), "EC2 Security Groups", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	descriptions := make(map[string]string, len(groups))
+	groupIDs := make([]string, 0, len(groups))
+	for _, group := range groups {
+		groupID := aws.StringValue(group.GroupId)
+		groupIDs = append(groupIDs, groupID)
+		descriptions[groupID] = sgRuleDescriptionFromTags(keyvaluetags.Ec2KeyValueTags(group.Tags).IgnoreAws().Map(), mapping, separator)
+	}
+
+	rules, err := finder.SecurityGroupRulesForGroups(conn, groupIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error reading EC2 Security Group Rules: %w", err)
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return aws.StringValue(rules[i].SecurityGroupRuleId) < aws.StringValue(rules[j].SecurityGroupRuleId)
+	})
+
+	rulesByID := make(map[string]*ec2.SecurityGroupRule, len(rules))
+	changes := make([]*plannedChange, 0, len(rules))
+
+	for _, rule := range rules {
+		if aws.BoolValue(rule.IsEgress) && !includeEgress {
+			continue
+		}
+
+		ruleID := aws.StringValue(rule.SecurityGroupRuleId)
+		rulesByID[ruleID] = rule
+
+		current := aws.StringValue(rule.Description)
+		desired := descriptions[aws.StringValue(rule.GroupId)]
+		change := &plannedChange{
+			ResourceID: ruleID,
+			Action:     plannedChangeActionNone,
+			Before:     map[string]string{"description": current},
+		}
+
+		switch {
+		case desired == "":
+			change.Reason = "Security Group has none of the mapped tags"
+		case len(desired) > sgRuleDescriptionMaxLength:
+			change.Reason = fmt.Sprintf("rendered description exceeds %d characters", sgRuleDescriptionMaxLength)
+		case desired == current:
+			change.Reason = "description is in sync"
+		default:
+			change.Action = plannedChangeActionUpdate
+			change.Reason = "description is out of sync with the Security Group tags"
+			change.After = map[string]string{"description": desired}
+		}
+
+		changes = append(changes, change)
+	}
+
+	err = applyPlannedChanges(changes, d.Get("dry_run").(bool), d.Get("continue_on_error").(bool), func(change *plannedChange) error {
+		rule := rulesByID[change.ResourceID]
+		input := &ec2.ModifySecurityGroupRulesInput{
+			GroupId: rule.GroupId,
+			SecurityGroupRules: []*ec2.SecurityGroupRuleUpdate{
+				{
+					SecurityGroupRuleId: rule.SecurityGroupRuleId,
+					SecurityGroupRule:   sgRuleRequest(rule, change.After["description"]),
+				},
+			},
+		}
+
+		log.Printf("[DEBUG] Modifying EC2 Security Group Rule (%s): %s", change.ResourceID, input)
+		if _, err := conn.ModifySecurityGroupRules(input); err != nil {
+			return fmt.Errorf("error modifying EC2 Security Group Rule (%s): %w", change.ResourceID, err)
+		}
+
+		return nil
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	return warnings, err
+}
+
+// sgRuleDescriptionFromTags renders the description of the rules of a Security Group with the given tags. It
+// returns an empty string if none of the mapped tags are present.
+func sgRuleDescriptionFromTags(tags map[string]string, mapping map[string]string, separator string) string {
+	keys := make([]string, 0, len(mapping))
+	for k := range mapping {
+		if _, ok := tags[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, strings.ReplaceAll(mapping[k], sgRuleDescriptionValuePlaceholder, tags[k]))
+	}
+
+	return strings.Join(parts, separator)
+}
+
+// sgRuleRequest returns the request replacing the given Security Group Rule with an identical rule with the given
+// description.
+func sgRuleRequest(rule *ec2.SecurityGroupRule, description string) *ec2.SecurityGroupRuleRequest {
+	request := &ec2.SecurityGroupRuleRequest{
+		CidrIpv4:     rule.CidrIpv4,
+		CidrIpv6:     rule.CidrIpv6,
+		Description:  aws.String(description),
+		FromPort:     rule.FromPort,
+		IpProtocol:   rule.IpProtocol,
+		PrefixListId: rule.PrefixListId,
+		ToPort:       rule.ToPort,
+	}
+
+	if rule.ReferencedGroupInfo != nil {
+		request.ReferencedGroupId = rule.ReferencedGroupInfo.GroupId
+	}
+
+	return request
+}
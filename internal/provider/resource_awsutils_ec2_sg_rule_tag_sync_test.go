@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestSgRuleDescriptionFromTags(t *testing.T) {
+	mapping := map[string]string{
+		"Team":   "team={value}",
+		"Ticket": "ticket={value}",
+		"Static": "managed",
+	}
+
+	testCases := []struct {
+		Name     string
+		Tags     map[string]string
+		Expected string
+	}{
+		{
+			Name: "no mapped tags",
+			Tags: map[string]string{
+				"Name": "web",
+			},
+		},
+		{
+			Name: "one mapped tag",
+			Tags: map[string]string{
+				"Name": "web",
+				"Team": "platform",
+			},
+			Expected: "team=platform",
+		},
+		{
+			Name: "mapped tags ordered by key",
+			Tags: map[string]string{
+				"Ticket": "OPS-1",
+				"Team":   "platform",
+				"Static": "",
+			},
+			Expected: "managed; team=platform; ticket=OPS-1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := sgRuleDescriptionFromTags(testCase.Tags, mapping, "; ")
+
+			if got != testCase.Expected {
+				t.Errorf("got %s, expected %s", got, testCase.Expected)
+			}
+		})
+	}
+}
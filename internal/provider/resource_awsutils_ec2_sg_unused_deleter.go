@@ -0,0 +1,416 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/google/uuid"
+	"github.com/hashicorp/aws-sdk-go-base/tfawserr"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	// ec2SgGroupIDChunkSize is the maximum number of Security Group IDs passed in a single "group-id" filter.
+	ec2SgGroupIDChunkSize = 200
+	// ec2SgDefaultGroupName is the name of the default Security Group of every VPC, which AWS does not allow
+	// deleting.
+	ec2SgDefaultGroupName = "default"
+)
+
+func resourceAwsUtilsEc2SgUnusedDeleter() *schema.Resource {
+	return &schema.Resource{
+		Description: `Deletes the Security Groups matching the given filters which are not used by any Network Interface,
+and so by no instance, load balancer, endpoint or other resource living in a VPC.
+
+The default Security Group of each VPC, which AWS does not allow deleting, and the Security Groups with the
+` + "`protect_tag`" + ` tag key are never deleted. When ` + "`check_rule_references`" + ` is set, the Security Groups
+referenced by the rules of other Security Groups of the account are kept too. A Security Group which AWS still refuses
+to delete as it is in use, such as by a resource of another account or created since it was read, is not an error: it
+is reported in ` + "`planned_changes`" + ` as skipped and as a warning. The IDs of the Security Groups deleted are
+recorded in ` + "`deleted_group_ids`" + `, across applies.
+
+When ` + "`dry_run`" + ` is set, the deletions are reported in ` + "`planned_changes`" + ` but not made. When
+` + "`require_confirmation_token`" + ` is set, they are only made once ` + "`confirmation_token`" + ` is set to the
+` + "`expected_confirmation_token`" + ` of the deletions, which changes whenever they do, forcing a new review. When
+` + "`continue_on_error`" + ` is set, the Security Groups which cannot be deleted are reported in ` + "`failed`" + ` and
+in an error returned after the remaining ones are deleted. Destroying this resource does not restore the Security Groups.`,
+		CreateContext: resourceAwsEc2SgUnusedDeleterCreate,
+		ReadContext:   resourceAwsEc2SgUnusedDeleterRead,
+		UpdateContext: resourceAwsEc2SgUnusedDeleterUpdate,
+		DeleteContext: resourceAwsEc2SgUnusedDeleterDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 Security Groups", countEc2SelectedSecurityGroups),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeSecurityGroup),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"protect_tag": {
+				Description:  "A tag key, such as `DoNotDelete`, protecting the Security Groups which have it, whatever its value, from being deleted.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"check_rule_references": {
+				Description: "Whether to also keep the Security Groups referenced by the rules of other Security Groups, which AWS otherwise refuses to delete.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"dry_run": {
+				Description: "Report the deletions without making them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"require_confirmation_token":  requireConfirmationTokenSchema(),
+			"confirmation_token":          confirmationTokenSchema(),
+			"expected_confirmation_token": expectedConfirmationTokenSchema(),
+			"warn_if_matches_over":        ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":        ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning":     ec2MatchThresholdWarningSchema(),
+			"continue_on_error":           continueOnErrorSchema(),
+			"planned_changes":             plannedChangesSchema(),
+			"failed":                      failedChangesSchema(),
+			"deleted_group_ids": {
+				Description: "The IDs of the Security Groups deleted by this resource, ordered by ID.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceAwsEc2SgUnusedDeleterCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// The ID is set before any Security Group is deleted so that, if deleting one fails, those deleted before are
+	// still recorded in the state saved along with the tainted resource.
+	d.SetId(uuid.New().String())
+
+	warnings, err := deleteEc2UnusedSgs(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2SgUnusedDeleterRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2SgUnusedDeleterRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2SgUnusedDeleterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := deleteEc2UnusedSgs(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2SgUnusedDeleterRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2SgUnusedDeleterDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// deleteEc2UnusedSgs deletes the selected Security Groups which are not in use, recording the outcome in the given
+// *schema.ResourceData. It returns the warnings of warn_if_matches_over and of the Security Groups AWS refused to
+// delete as still in use, if any.
+func deleteEc2UnusedSgs(d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+
+	groups, filters, warnings, err := findEc2SelectedSecurityGroups(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(groups), "EC2 Security Groups", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	groupIDs := make([]string, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, aws.StringValue(group.GroupId))
+	}
+
+	networkInterfaces, err := ec2SgNetworkInterfaceCounts(conn, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var referencedBy map[string][]string
+	if d.Get("check_rule_references").(bool) {
+		referencedBy, err = ec2SgReferencingGroupIDs(conn, groupIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	changes := ec2SgUnusedDeleterChanges(groups, networkInterfaces, referencedBy, d.Get("protect_tag").(string))
+
+	confirmed, confirmationWarnings, err := checkConfirmationToken(d, changes)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, confirmationWarnings...)
+
+	stillInUse := make(map[*plannedChange]string)
+
+	err = applyPlannedChanges(changes, d.Get("dry_run").(bool) || !confirmed, d.Get("continue_on_error").(bool), func(change *plannedChange) error {
+		log.Printf("[INFO] Deleting unused EC2 Security Group (%s)", change.ResourceID)
+		_, err := conn.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{
+			GroupId: aws.String(change.ResourceID),
+		})
+
+		if tfawserr.ErrCodeEquals(err, tfec2.ErrCodeDependencyViolation) {
+			log.Printf("[WARN] EC2 Security Group (%s) is still in use, not deleting it: %s", change.ResourceID, err)
+			stillInUse[change] = err.Error()
+			return nil
+		}
+
+		// A Security Group deleted since it was read is already gone.
+		if tfawserr.ErrCodeEquals(err, tfec2.InvalidSecurityGroupIDNotFound) || tfawserr.ErrCodeEquals(err, tfec2.InvalidGroupNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("error deleting EC2 Security Group (%s): %w", change.ResourceID, err)
+		}
+
+		return nil
+	})
+
+	var skipped []string
+	for _, change := range changes {
+		if message, ok := stillInUse[change]; ok {
+			change.Status = plannedChangeStatusSkipped
+			change.Reason = "still in use"
+			skipped = append(skipped, fmt.Sprintf("%s: %s", change.ResourceID, message))
+		}
+	}
+	if len(skipped) > 0 {
+		warnings = append(warnings, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%d EC2 Security Group(s) not deleted as still in use", len(skipped)),
+			Detail:   strings.Join(skipped, "\n"),
+		})
+	}
+
+	// The Security Groups deleted are recorded first, even if deleting another one failed.
+	deletedGroupIDs := ec2SgDeletedGroupIDs(d.Get("deleted_group_ids").([]interface{}), changes)
+	if err := d.Set("deleted_group_ids", deletedGroupIDs); err != nil {
+		return nil, fmt.Errorf("error setting deleted_group_ids: %w", err)
+	}
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return warnings, nil
+}
+
+// ec2SgNetworkInterfaceCounts returns the number of Network Interfaces each of the given Security Groups is attached
+// to, keyed by group ID. Security Groups without any Network Interface are missing from the result.
+func ec2SgNetworkInterfaceCounts(conn *ec2.EC2, groupIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+
+	for i := 0; i < len(groupIDs); i += ec2SgGroupIDChunkSize {
+		j := i + ec2SgGroupIDChunkSize
+		if j > len(groupIDs) {
+			j = len(groupIDs)
+		}
+
+		// Only the groups of the chunk are counted, a Network Interface attached to groups of several chunks being
+		// returned for each of them.
+		chunk := make(map[string]bool, j-i)
+		for _, groupID := range groupIDs[i:j] {
+			chunk[groupID] = true
+		}
+
+		networkInterfaces, err := finder.NetworkInterfaces(conn, &ec2.DescribeNetworkInterfacesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("group-id"),
+					Values: aws.StringSlice(groupIDs[i:j]),
+				},
+			},
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 Network Interfaces: %w", err)
+		}
+
+		for _, networkInterface := range networkInterfaces {
+			for _, group := range networkInterface.Groups {
+				if groupID := aws.StringValue(group.GroupId); chunk[groupID] {
+					counts[groupID]++
+				}
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// ec2SgReferencingGroupIDs returns the IDs of the other Security Groups with an ingress or egress rule referencing
+// each of the given Security Groups, keyed by group ID and ordered by ID. Security Groups referenced by no other one,
+// including those only referencing themselves, are missing from the result.
+func ec2SgReferencingGroupIDs(conn *ec2.EC2, groupIDs []string) (map[string][]string, error) {
+	selected := make(map[string]bool, len(groupIDs))
+	for _, groupID := range groupIDs {
+		selected[groupID] = true
+	}
+
+	references := make(map[string]map[string]bool)
+
+	for i := 0; i < len(groupIDs); i += ec2SgGroupIDChunkSize {
+		j := i + ec2SgGroupIDChunkSize
+		if j > len(groupIDs) {
+			j = len(groupIDs)
+		}
+
+		// The filters of a request are combined, so ingress and egress references are looked up separately.
+		for _, filterName := range []string{"ip-permission.group-id", "egress.ip-permission.group-id"} {
+			referencing, err := finder.SecurityGroups(conn, &ec2.DescribeSecurityGroupsInput{
+				Filters: []*ec2.Filter{
+					{
+						Name:   aws.String(filterName),
+						Values: aws.StringSlice(groupIDs[i:j]),
+					},
+				},
+			}, 0)
+
+			if err != nil {
+				return nil, fmt.Errorf("error reading EC2 Security Groups referencing EC2 Security Groups: %w", err)
+			}
+
+			for _, group := range referencing {
+				referencingID := aws.StringValue(group.GroupId)
+
+				for _, permission := range append(append([]*ec2.IpPermission{}, group.IpPermissions...), group.IpPermissionsEgress...) {
+					for _, pair := range permission.UserIdGroupPairs {
+						referencedID := aws.StringValue(pair.GroupId)
+						if !selected[referencedID] || referencedID == referencingID {
+							continue
+						}
+
+						if references[referencedID] == nil {
+							references[referencedID] = make(map[string]bool)
+						}
+						references[referencedID][referencingID] = true
+					}
+				}
+			}
+		}
+	}
+
+	result := make(map[string][]string, len(references))
+	for referencedID, referencingIDs := range references {
+		for referencingID := range referencingIDs {
+			result[referencedID] = append(result[referencedID], referencingID)
+		}
+		sort.Strings(result[referencedID])
+	}
+
+	return result, nil
+}
+
+// ec2SgUnusedDeleterChanges returns the changes deleting the given Security Groups which are neither the default
+// Security Group of their VPC, nor tagged with the given protect tag key, if any, nor attached to any Network
+// Interface according to the given counts, nor referenced by other Security Groups according to the given
+// references, ordered by group ID. The Security Groups which are kept have a change with the none action.
+//
+// The protect tag is looked up in all the tags of the Security Groups, including the aws: ones and those ignored by
+// the provider, so that no protected Security Group is deleted.
+func ec2SgUnusedDeleterChanges(groups []*ec2.SecurityGroup, networkInterfaces map[string]int, referencedBy map[string][]string, protectTag string) []*plannedChange {
+	sorted := append([]*ec2.SecurityGroup{}, groups...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return aws.StringValue(sorted[i].GroupId) < aws.StringValue(sorted[j].GroupId)
+	})
+
+	changes := make([]*plannedChange, 0, len(sorted))
+
+	for _, group := range sorted {
+		groupID := aws.StringValue(group.GroupId)
+		before := map[string]string{
+			"group_name": aws.StringValue(group.GroupName),
+			"vpc_id":     aws.StringValue(group.VpcId),
+		}
+
+		change := &plannedChange{
+			ResourceID: groupID,
+			Action:     plannedChangeActionNone,
+			Before:     before,
+		}
+
+		switch {
+		case aws.StringValue(group.GroupName) == ec2SgDefaultGroupName:
+			change.Reason = "default Security Group of the VPC, which AWS does not allow deleting"
+		case protectTag != "" && keyvaluetags.Ec2KeyValueTags(group.Tags).KeyExists(protectTag):
+			change.Reason = fmt.Sprintf("protected by the %s tag", protectTag)
+		case networkInterfaces[groupID] > 0:
+			change.Reason = fmt.Sprintf("in use by %d Network Interface(s)", networkInterfaces[groupID])
+			before["network_interfaces"] = strconv.Itoa(networkInterfaces[groupID])
+		case len(referencedBy[groupID]) > 0:
+			change.Reason = "referenced by the rules of " + strings.Join(referencedBy[groupID], ", ")
+		default:
+			change.Action = plannedChangeActionDelete
+			change.Reason = "not used by any Network Interface"
+			if referencedBy != nil {
+				change.Reason += " nor referenced by another Security Group"
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// ec2SgDeletedGroupIDs returns the given previously deleted group IDs, as recorded in the deleted_group_ids
+// attribute, together with the IDs of the given deletions which were applied, ordered by ID.
+func ec2SgDeletedGroupIDs(previous []interface{}, changes []*plannedChange) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(previous))
+
+	add := func(groupID string) {
+		if !seen[groupID] {
+			seen[groupID] = true
+			result = append(result, groupID)
+		}
+	}
+
+	for _, v := range previous {
+		add(v.(string))
+	}
+
+	for _, change := range changes {
+		if change.Action == plannedChangeActionDelete && change.Status == plannedChangeStatusApplied {
+			add(change.ResourceID)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2SgUnusedDeleterChanges(t *testing.T) {
+	group := func(id, name string, tags ...string) *ec2.SecurityGroup {
+		group := &ec2.SecurityGroup{GroupId: aws.String(id), GroupName: aws.String(name), VpcId: aws.String("vpc-01234567")}
+		for _, key := range tags {
+			group.Tags = append(group.Tags, &ec2.Tag{Key: aws.String(key), Value: aws.String("")})
+		}
+		return group
+	}
+	before := func(name string) map[string]string {
+		return map[string]string{"group_name": name, "vpc_id": "vpc-01234567"}
+	}
+
+	groups := []*ec2.SecurityGroup{
+		group("sg-00000005", "referenced"),
+		group("sg-00000001", "default"),
+		group("sg-00000002", "protected", "DoNotDelete"),
+		group("sg-00000003", "attached"),
+		group("sg-00000004", "unused"),
+	}
+	networkInterfaces := map[string]int{"sg-00000001": 1, "sg-00000003": 2}
+
+	testCases := []struct {
+		Name         string
+		ReferencedBy map[string][]string
+		ProtectTag   string
+		Expected     []*plannedChange
+	}{
+		{
+			Name:       "without rule references",
+			ProtectTag: "DoNotDelete",
+			Expected: []*plannedChange{
+				{ResourceID: "sg-00000001", Action: plannedChangeActionNone, Reason: "default Security Group of the VPC, which AWS does not allow deleting", Before: before("default")},
+				{ResourceID: "sg-00000002", Action: plannedChangeActionNone, Reason: "protected by the DoNotDelete tag", Before: before("protected")},
+				{ResourceID: "sg-00000003", Action: plannedChangeActionNone, Reason: "in use by 2 Network Interface(s)", Before: map[string]string{"group_name": "attached", "vpc_id": "vpc-01234567", "network_interfaces": "2"}},
+				{ResourceID: "sg-00000004", Action: plannedChangeActionDelete, Reason: "not used by any Network Interface", Before: before("unused")},
+				{ResourceID: "sg-00000005", Action: plannedChangeActionDelete, Reason: "not used by any Network Interface", Before: before("referenced")},
+			},
+		},
+		{
+			Name:         "with rule references",
+			ReferencedBy: map[string][]string{"sg-00000005": {"sg-00000009", "sg-0000000a"}},
+			Expected: []*plannedChange{
+				{ResourceID: "sg-00000001", Action: plannedChangeActionNone, Reason: "default Security Group of the VPC, which AWS does not allow deleting", Before: before("default")},
+				{ResourceID: "sg-00000002", Action: plannedChangeActionDelete, Reason: "not used by any Network Interface nor referenced by another Security Group", Before: before("protected")},
+				{ResourceID: "sg-00000003", Action: plannedChangeActionNone, Reason: "in use by 2 Network Interface(s)", Before: map[string]string{"group_name": "attached", "vpc_id": "vpc-01234567", "network_interfaces": "2"}},
+				{ResourceID: "sg-00000004", Action: plannedChangeActionDelete, Reason: "not used by any Network Interface nor referenced by another Security Group", Before: before("unused")},
+				{ResourceID: "sg-00000005", Action: plannedChangeActionNone, Reason: "referenced by the rules of sg-00000009, sg-0000000a", Before: before("referenced")},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := ec2SgUnusedDeleterChanges(groups, networkInterfaces, testCase.ReferencedBy, testCase.ProtectTag)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestDeleteEc2UnusedSgsDependencyViolation(t *testing.T) {
+	var deleted []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeSecurityGroupsOutput:
+			output.SecurityGroups = []*ec2.SecurityGroup{
+				{GroupId: aws.String("sg-00000001"), GroupName: aws.String("default")},
+				{GroupId: aws.String("sg-00000002"), GroupName: aws.String("in-use-elsewhere")},
+				{GroupId: aws.String("sg-00000003"), GroupName: aws.String("unused")},
+			}
+		case *ec2.DescribeNetworkInterfacesOutput:
+		case *ec2.DeleteSecurityGroupOutput:
+			groupID := aws.StringValue(r.Params.(*ec2.DeleteSecurityGroupInput).GroupId)
+			if groupID == "sg-00000002" {
+				r.Error = awserr.New("DependencyViolation", "resource sg-00000002 has a dependent object", nil)
+				return
+			}
+			deleted = append(deleted, groupID)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2SgUnusedDeleter().Schema, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{"name": "vpc-id", "values": []interface{}{"vpc-01234567"}},
+		},
+	})
+	if err := d.Set("deleted_group_ids", []string{"sg-00000000"}); err != nil {
+		t.Fatalf("error setting deleted_group_ids: %s", err)
+	}
+
+	warnings, err := deleteEc2UnusedSgs(d, &AWSClient{ec2conn: conn})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(deleted, []string{"sg-00000003"}) {
+		t.Errorf("got deleted Security Groups %v, expected [sg-00000003]", deleted)
+	}
+
+	if len(warnings) != 1 || warnings[0].Severity != diag.Warning || !strings.Contains(warnings[0].Detail, "sg-00000002: DependencyViolation") {
+		t.Errorf("got %v, expected a warning that sg-00000002 is still in use", warnings)
+	}
+
+	if got, expected := d.Get("deleted_group_ids").([]interface{}), []interface{}{"sg-00000000", "sg-00000003"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got deleted_group_ids %v, expected %v", got, expected)
+	}
+
+	statuses := make(map[string]string)
+	for _, v := range d.Get("planned_changes").([]interface{}) {
+		m := v.(map[string]interface{})
+		statuses[m["resource_id"].(string)] = m["status"].(string) + " " + m["reason"].(string)
+	}
+	expectedStatuses := map[string]string{
+		"sg-00000001": "skipped default Security Group of the VPC, which AWS does not allow deleting",
+		"sg-00000002": "skipped still in use",
+		"sg-00000003": "applied not used by any Network Interface",
+	}
+	if !reflect.DeepEqual(statuses, expectedStatuses) {
+		t.Errorf("got planned_changes statuses %v, expected %v", statuses, expectedStatuses)
+	}
+}
+
+func TestDeleteEc2UnusedSgsConfirmationToken(t *testing.T) {
+	groups := []*ec2.SecurityGroup{
+		{GroupId: aws.String("sg-00000001"), GroupName: aws.String("unused")},
+	}
+	var deleted []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeSecurityGroupsOutput:
+			output.SecurityGroups = groups
+		case *ec2.DescribeNetworkInterfacesOutput:
+		case *ec2.DeleteSecurityGroupOutput:
+			deleted = append(deleted, aws.StringValue(r.Params.(*ec2.DeleteSecurityGroupInput).GroupId))
+		}
+	})
+
+	read := func(token string) (*schema.ResourceData, diag.Diagnostics) {
+		d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2SgUnusedDeleter().Schema, map[string]interface{}{
+			"filter": []interface{}{
+				map[string]interface{}{"name": "vpc-id", "values": []interface{}{"vpc-01234567"}},
+			},
+			"require_confirmation_token": true,
+			"confirmation_token":         token,
+		})
+
+		warnings, err := deleteEc2UnusedSgs(d, &AWSClient{ec2conn: conn})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return d, warnings
+	}
+
+	// The first apply only exposes the token.
+	d, warnings := read("")
+	if len(deleted) != 0 {
+		t.Fatalf("got deleted Security Groups %v before confirmation, expected none", deleted)
+	}
+	token := d.Get("expected_confirmation_token").(string)
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Detail, token) {
+		t.Errorf("got %v, expected a warning with the token %s", warnings, token)
+	}
+	if got := d.Get("planned_changes").([]interface{})[0].(map[string]interface{})["status"]; got != plannedChangeStatusPlanned {
+		t.Errorf("got status %s, expected %s", got, plannedChangeStatusPlanned)
+	}
+
+	// Once the selection changes, the token no longer confirms the deletions.
+	groups = append(groups, &ec2.SecurityGroup{GroupId: aws.String("sg-00000002"), GroupName: aws.String("unused-too")})
+	d, _ = read(token)
+	if len(deleted) != 0 {
+		t.Fatalf("got deleted Security Groups %v with the token of another selection, expected none", deleted)
+	}
+	if d.Get("expected_confirmation_token").(string) == token {
+		t.Errorf("expected the token to change with the selection")
+	}
+
+	// The token of the current selection confirms the deletions.
+	read(d.Get("expected_confirmation_token").(string))
+	if expected := []string{"sg-00000001", "sg-00000002"}; !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("got deleted Security Groups %v, expected %v", deleted, expected)
+	}
+}
+
+func TestResourceAwsEc2SgUnusedDeleterCreatePartialFailure(t *testing.T) {
+	var deleted []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeSecurityGroupsOutput:
+			output.SecurityGroups = []*ec2.SecurityGroup{
+				{GroupId: aws.String("sg-00000001"), GroupName: aws.String("unused")},
+				{GroupId: aws.String("sg-00000002"), GroupName: aws.String("unauthorized")},
+				{GroupId: aws.String("sg-00000003"), GroupName: aws.String("unused-too")},
+			}
+		case *ec2.DescribeNetworkInterfacesOutput:
+		case *ec2.DeleteSecurityGroupOutput:
+			groupID := aws.StringValue(r.Params.(*ec2.DeleteSecurityGroupInput).GroupId)
+			if groupID == "sg-00000002" {
+				r.Error = awserr.New("UnauthorizedOperation", "You are not authorized to perform this operation.", nil)
+				return
+			}
+			deleted = append(deleted, groupID)
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2SgUnusedDeleter().Schema, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{"name": "vpc-id", "values": []interface{}{"vpc-01234567"}},
+		},
+	})
+
+	diags := resourceAwsEc2SgUnusedDeleterCreate(context.Background(), d, &AWSClient{ec2conn: conn})
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "error deleting EC2 Security Group (sg-00000002)") {
+		t.Fatalf("got %v, expected an error deleting sg-00000002", diags)
+	}
+
+	if expected := []string{"sg-00000001"}; !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("got deleted Security Groups %v, expected %v", deleted, expected)
+	}
+
+	// The Security Group deleted before the error is recorded in the state saved with the tainted resource.
+	if d.Id() == "" {
+		t.Errorf("expected the ID to be set")
+	}
+	if got, expected := d.Get("deleted_group_ids").([]interface{}), []interface{}{"sg-00000001"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got deleted_group_ids %v, expected %v", got, expected)
+	}
+	if got := d.Get("planned_changes").([]interface{}); len(got) != 3 {
+		t.Errorf("got %d planned_changes, expected 3", len(got))
+	}
+}
+
+func TestDeleteEc2UnusedSgsProtectTagIgnored(t *testing.T) {
+	var deleted []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeSecurityGroupsOutput:
+			output.SecurityGroups = []*ec2.SecurityGroup{
+				{GroupId: aws.String("sg-00000001"), GroupName: aws.String("protected"), Tags: []*ec2.Tag{{Key: aws.String("DoNotDelete"), Value: aws.String("true")}}},
+				{GroupId: aws.String("sg-00000002"), GroupName: aws.String("unused")},
+			}
+		case *ec2.DescribeNetworkInterfacesOutput:
+		case *ec2.DeleteSecurityGroupOutput:
+			deleted = append(deleted, aws.StringValue(r.Params.(*ec2.DeleteSecurityGroupInput).GroupId))
+		}
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2SgUnusedDeleter().Schema, map[string]interface{}{
+		"filter": []interface{}{
+			map[string]interface{}{"name": "vpc-id", "values": []interface{}{"vpc-01234567"}},
+		},
+		"protect_tag": "DoNotDelete",
+	})
+
+	// The protect tag is still honored when the provider ignores it.
+	client := &AWSClient{
+		ec2conn: conn,
+		IgnoreTagsConfig: &keyvaluetags.IgnoreConfig{
+			Keys:        keyvaluetags.New([]interface{}{"DoNotDelete"}),
+			KeyPrefixes: keyvaluetags.New([]interface{}{"Do"}),
+		},
+	}
+
+	if _, err := deleteEc2UnusedSgs(d, client); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := []string{"sg-00000002"}; !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("got deleted Security Groups %v, expected %v", deleted, expected)
+	}
+}
+
+func TestEc2SgUnusedDeleterChangesAwsProtectTag(t *testing.T) {
+	groups := []*ec2.SecurityGroup{
+		{GroupId: aws.String("sg-00000001"), GroupName: aws.String("stack"), Tags: []*ec2.Tag{{Key: aws.String("aws:cloudformation:stack-name"), Value: aws.String("network")}}},
+	}
+
+	got := ec2SgUnusedDeleterChanges(groups, nil, nil, "aws:cloudformation:stack-name")
+	if len(got) != 1 || got[0].Action != plannedChangeActionNone {
+		t.Errorf("got %v, expected the Security Group to be protected", got)
+	}
+}
@@ -0,0 +1,319 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	// ec2TagOperationBatchSize is the maximum number of resources passed in a single CreateTags or DeleteTags call.
+	ec2TagOperationBatchSize = 200
+
+	// ec2TagResourceIDChunkSize is the maximum number of resource IDs passed in a single "resource-id" filter when
+	// looking up the tags of resources.
+	ec2TagResourceIDChunkSize = 200
+)
+
+// ec2TagBulkReplacerKeyValidation validates the tag keys of the tag bulk replacer, which may not be the reserved
+// aws: keys managed by AWS.
+var ec2TagBulkReplacerKeyValidation = validation.All(
+	validation.StringIsNotEmpty,
+	validation.StringDoesNotMatch(regexp.MustCompile(`^aws:`), "must not be an aws: tag key"),
+)
+
+func resourceAwsUtilsEc2TagBulkReplacer() *schema.Resource {
+	return &schema.Resource{
+		Description: `Renames a tag key on the EC2 resources of the given types, copying the value of the tag with the key
+` + "`old_key`" + ` to a tag with the key ` + "`new_key`" + ` and deleting the former.
+
+The resources are those of the types listed in ` + "`resource_types`" + ` which have a tag with the key ` + "`old_key`" + `
+and match the given filters, which are those of the ` + "`DescribeTags`" + ` API. Resources already migrated no longer
+have the old tag key and are not selected, so applying this resource repeatedly is a no-op once the tag key is renamed.
+Resources with both tag keys set to the same value only have the old one deleted, while those with different values are
+left untouched and reported in ` + "`planned_changes`" + `. The tags are created and deleted in batches of resources with
+the same value.
+
+When ` + "`dry_run`" + ` is set, the renames are reported in ` + "`planned_changes`" + ` but not made. When
+` + "`require_confirmation_token`" + ` is set, they are only made once ` + "`confirmation_token`" + ` is set to the
+` + "`expected_confirmation_token`" + ` of the renames, which changes whenever they do, forcing a new review. When
+` + "`continue_on_error`" + ` is set, the resources which cannot be retagged are reported in ` + "`failed`" + ` and in an
+error returned after the remaining resources are retagged. Destroying this resource does not restore the old tag key.`,
+		CreateContext: resourceAwsEc2TagBulkReplacerCreate,
+		ReadContext:   resourceAwsEc2TagBulkReplacerRead,
+		UpdateContext: resourceAwsEc2TagBulkReplacerUpdate,
+		DeleteContext: resourceAwsEc2TagBulkReplacerDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 resources", func(d resourceDataGetter, meta interface{}) (int, []*ec2.Filter, error) {
+			oldTags, filters, _, err := findEc2TagsWithOldKey(d, meta)
+			return len(oldTags), filters, err
+		}, "old_key", "resource_types"),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"resource_types": {
+				Description: "The types of the resources to retag, e.g. `instance` or `security-group`.",
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(tfec2.ResourceTypes(), false),
+				},
+			},
+			"old_key": {
+				Description:  "The tag key to rename.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: ec2TagBulkReplacerKeyValidation,
+			},
+			"new_key": {
+				Description:  "The tag key to rename `old_key` to.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: ec2TagBulkReplacerKeyValidation,
+			},
+			"dry_run": {
+				Description: "Report the renames without making them.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"require_confirmation_token":  requireConfirmationTokenSchema(),
+			"confirmation_token":          confirmationTokenSchema(),
+			"expected_confirmation_token": expectedConfirmationTokenSchema(),
+			"warn_if_matches_over":        ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":        ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning":     ec2MatchThresholdWarningSchema(),
+			"continue_on_error":           continueOnErrorSchema(),
+			"planned_changes":             plannedChangesSchema(),
+			"failed":                      failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2TagBulkReplacerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := replaceEc2TagKeys(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(uuid.New().String())
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2TagBulkReplacerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2TagBulkReplacerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceAwsEc2TagBulkReplacerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := replaceEc2TagKeys(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2TagBulkReplacerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2TagBulkReplacerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// replaceEc2TagKeys renames the configured tag key on each of the selected resources, recording the outcome in the
+// given *schema.ResourceData.
+// It returns the warnings of warn_if_matches_over and of require_confirmation_token, if any.
+func replaceEc2TagKeys(d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+	oldKey := d.Get("old_key").(string)
+	newKey := d.Get("new_key").(string)
+
+	if oldKey == newKey {
+		return nil, fmt.Errorf("old_key and new_key must differ, got %s for both", oldKey)
+	}
+
+	// A resource has at most one tag with the old key, so the tags are counted as the resources they are on.
+	oldTags, filters, warnings, err := findEc2TagsWithOldKey(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(oldTags), "EC2 resources", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	sort.Slice(oldTags, func(i, j int) bool {
+		return aws.StringValue(oldTags[i].ResourceId) < aws.StringValue(oldTags[j].ResourceId)
+	})
+
+	resourceIDs := make([]string, 0, len(oldTags))
+	for _, tag := range oldTags {
+		resourceIDs = append(resourceIDs, aws.StringValue(tag.ResourceId))
+	}
+
+	newValues, err := ec2TagValuesByResourceID(conn, newKey, resourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*plannedChange, 0, len(oldTags))
+	for _, tag := range oldTags {
+		resourceID := aws.StringValue(tag.ResourceId)
+		changes = append(changes, ec2TagKeyReplacementChange(resourceID, oldKey, aws.StringValue(tag.Value), newKey, newValues[resourceID]))
+	}
+
+	confirmed, confirmationWarnings, err := checkConfirmationToken(d, changes)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, confirmationWarnings...)
+
+	err = applyPlannedChangesInBatches(changes, d.Get("dry_run").(bool) || !confirmed, d.Get("continue_on_error").(bool), ec2TagOperationBatchSize, func(change *plannedChange) string {
+		return change.After[newKey]
+	}, func(batch []*plannedChange) error {
+		resources := make([]string, 0, len(batch))
+		for _, change := range batch {
+			resources = append(resources, change.ResourceID)
+		}
+		value := batch[0].After[newKey]
+
+		createInput := &ec2.CreateTagsInput{
+			Resources: aws.StringSlice(resources),
+			Tags:      []*ec2.Tag{{Key: aws.String(newKey), Value: aws.String(value)}},
+		}
+
+		log.Printf("[DEBUG] Creating tags on EC2 resources: %s", createInput)
+		if _, err := conn.CreateTags(createInput); err != nil {
+			return fmt.Errorf("error creating tag %s on EC2 resources (%v): %w", newKey, resources, err)
+		}
+
+		// The value is given so that the tag is only deleted if it was not changed in the meantime.
+		deleteInput := &ec2.DeleteTagsInput{
+			Resources: aws.StringSlice(resources),
+			Tags:      []*ec2.Tag{{Key: aws.String(oldKey), Value: aws.String(value)}},
+		}
+
+		log.Printf("[DEBUG] Deleting tags on EC2 resources: %s", deleteInput)
+		if _, err := conn.DeleteTags(deleteInput); err != nil {
+			return fmt.Errorf("error deleting tag %s on EC2 resources (%v): %w", oldKey, resources, err)
+		}
+
+		return nil
+	})
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	return warnings, err
+}
+
+// findEc2TagsWithOldKey reads the tags with the old_key of the resources of the resource_types selected by the
+// resource with the given resourceDataGetter, as findEc2SelectedVpcs does the VPCs.
+func findEc2TagsWithOldKey(d resourceDataGetter, meta interface{}) ([]*ec2.TagDescription, []*ec2.Filter, diag.Diagnostics, error) {
+	// No ids attribute is declared, so the resource type the selection IDs would be of does not matter.
+	_, filters, warnings, err := buildEC2Selection(d, meta, "")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	input := &ec2.DescribeTagsInput{
+		Filters: append([]*ec2.Filter{
+			{
+				Name:   aws.String("key"),
+				Values: aws.StringSlice([]string{d.Get("old_key").(string)}),
+			},
+			{
+				Name:   aws.String("resource-type"),
+				Values: ExpandStringSet(d.Get("resource_types").(*schema.Set)),
+			},
+		}, filters...),
+	}
+
+	tags, err := finder.Tags(meta.(*AWSClient).ec2conn, input, ec2FailIfMatchesOver(d))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading EC2 Tags: %w", ec2FailIfMatchesOverError(err, input.Filters))
+	}
+
+	return tags, input.Filters, warnings, nil
+}
+
+// ec2TagValuesByResourceID returns the values of the tag with the given key of the given resources, keyed by resource
+// ID. Resources without the tag are missing from the result.
+func ec2TagValuesByResourceID(conn *ec2.EC2, key string, resourceIDs []string) (map[string]*string, error) {
+	values := make(map[string]*string, len(resourceIDs))
+
+	for i := 0; i < len(resourceIDs); i += ec2TagResourceIDChunkSize {
+		j := i + ec2TagResourceIDChunkSize
+		if j > len(resourceIDs) {
+			j = len(resourceIDs)
+		}
+
+		tags, err := finder.Tags(conn, &ec2.DescribeTagsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("key"),
+					Values: aws.StringSlice([]string{key}),
+				},
+				{
+					Name:   aws.String("resource-id"),
+					Values: aws.StringSlice(resourceIDs[i:j]),
+				},
+			},
+		}, 0)
+
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 Tags: %w", err)
+		}
+
+		for _, tag := range tags {
+			values[aws.StringValue(tag.ResourceId)] = aws.String(aws.StringValue(tag.Value))
+		}
+	}
+
+	return values, nil
+}
+
+// ec2TagKeyReplacementChange returns the change renaming the tag with the key oldKey and the value oldValue of the
+// given resource to newKey, given the current value of the tag with the key newKey, or nil if it has none.
+func ec2TagKeyReplacementChange(resourceID, oldKey, oldValue, newKey string, newValue *string) *plannedChange {
+	change := &plannedChange{
+		ResourceID: resourceID,
+		Action:     plannedChangeActionNone,
+	}
+
+	switch {
+	case newValue == nil:
+		change.Reason = fmt.Sprintf("tag key %s renamed to %s", oldKey, newKey)
+		change.Before = map[string]string{oldKey: oldValue}
+	case *newValue == oldValue:
+		change.Reason = fmt.Sprintf("tag key %s already set to the same value, %s deleted", newKey, oldKey)
+		change.Before = map[string]string{oldKey: oldValue, newKey: oldValue}
+	default:
+		change.Reason = fmt.Sprintf("tag key %s already set to a different value (%s)", newKey, *newValue)
+		return change
+	}
+
+	change.Action = plannedChangeActionUpdate
+	change.After = map[string]string{newKey: oldValue}
+
+	return change
+}
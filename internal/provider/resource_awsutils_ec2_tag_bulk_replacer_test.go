@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestEc2TagKeyReplacementChange(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		NewValue *string
+		Expected *plannedChange
+	}{
+		{
+			Name:     "not migrated",
+			NewValue: nil,
+			Expected: &plannedChange{
+				ResourceID: "i-01234567",
+				Action:     plannedChangeActionUpdate,
+				Reason:     "tag key Team renamed to team",
+				Before:     map[string]string{"Team": "platform"},
+				After:      map[string]string{"team": "platform"},
+			},
+		},
+		{
+			Name:     "partially migrated",
+			NewValue: aws.String("platform"),
+			Expected: &plannedChange{
+				ResourceID: "i-01234567",
+				Action:     plannedChangeActionUpdate,
+				Reason:     "tag key team already set to the same value, Team deleted",
+				Before:     map[string]string{"Team": "platform", "team": "platform"},
+				After:      map[string]string{"team": "platform"},
+			},
+		},
+		{
+			Name:     "conflicting values",
+			NewValue: aws.String("data"),
+			Expected: &plannedChange{
+				ResourceID: "i-01234567",
+				Action:     plannedChangeActionNone,
+				Reason:     "tag key team already set to a different value (data)",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := ec2TagKeyReplacementChange("i-01234567", "Team", "platform", "team", testCase.NewValue)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %+v, expected %+v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestEc2TagBulkReplacerKeyValidation(t *testing.T) {
+	for _, testCase := range []struct {
+		Key   string
+		Valid bool
+	}{
+		{"Team", true},
+		{"team:aws", true},
+		{"", false},
+		{"aws:cloudformation:stack-name", false},
+	} {
+		_, errs := ec2TagBulkReplacerKeyValidation(testCase.Key, "old_key")
+		if got := len(errs) == 0; got != testCase.Valid {
+			t.Errorf("got valid %t for %q, expected %t: %v", got, testCase.Key, testCase.Valid, errs)
+		}
+	}
+}
+
+func TestReplaceEc2TagKeysConfirmationToken(t *testing.T) {
+	oldTags := []*ec2.TagDescription{
+		{ResourceId: aws.String("i-00000001"), Key: aws.String("Team"), Value: aws.String("platform")},
+	}
+	var deleted []string
+
+	conn := testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeTagsOutput:
+			// The tags with the new key are looked up by resource ID, the resources have none.
+			if aws.StringValue(r.Params.(*ec2.DescribeTagsInput).Filters[0].Values[0]) == "Team" {
+				output.Tags = oldTags
+			}
+		case *ec2.CreateTagsOutput:
+		case *ec2.DeleteTagsOutput:
+			deleted = append(deleted, aws.StringValueSlice(r.Params.(*ec2.DeleteTagsInput).Resources)...)
+		}
+	})
+
+	replace := func(token string) *schema.ResourceData {
+		d := schema.TestResourceDataRaw(t, resourceAwsUtilsEc2TagBulkReplacer().Schema, map[string]interface{}{
+			"resource_types":             []interface{}{"instance"},
+			"old_key":                    "Team",
+			"new_key":                    "team",
+			"require_confirmation_token": true,
+			"confirmation_token":         token,
+		})
+
+		if _, err := replaceEc2TagKeys(d, &AWSClient{ec2conn: conn}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return d
+	}
+
+	token := replace("").Get("expected_confirmation_token").(string)
+	if len(deleted) != 0 {
+		t.Fatalf("got retagged resources %v before confirmation, expected none", deleted)
+	}
+
+	// Once the selection changes, the token no longer confirms the renames.
+	oldTags = append(oldTags, &ec2.TagDescription{ResourceId: aws.String("i-00000002"), Key: aws.String("Team"), Value: aws.String("platform")})
+	d := replace(token)
+	if len(deleted) != 0 {
+		t.Fatalf("got retagged resources %v with the token of another selection, expected none", deleted)
+	}
+	if d.Get("expected_confirmation_token").(string) == token {
+		t.Errorf("expected the token to change with the selection")
+	}
+
+	replace(d.Get("expected_confirmation_token").(string))
+	if expected := []string{"i-00000001", "i-00000002"}; !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("got retagged resources %v, expected %v", deleted, expected)
+	}
+}
@@ -0,0 +1,322 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/keyvaluetags"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceAwsUtilsEc2VpcFlowLogEnforcer() *schema.Resource {
+	return &schema.Resource{
+		Description: `Ensures every VPC matching the given filters has a Flow Log, creating one for any VPC that is missing one.
+
+VPCs which already have a Flow Log, whether or not it was created by this resource, are left untouched, so applying
+this resource repeatedly never creates duplicate Flow Logs. Flow Logs created by this resource are recorded in state
+and deleted when ` + "`terraform destroy`" + ` is run. When ` + "`dry_run`" + ` is set, the VPCs missing a Flow Log are
+reported but no Flow Logs are created. The outcome for each selected VPC is reported in ` + "`planned_changes`" + `. When
+` + "`continue_on_error`" + ` is set, VPCs for which a Flow Log cannot be created are reported in ` + "`failed`" + ` and in an
+error returned after Flow Logs are created for the remaining VPCs.`,
+		CreateContext: resourceAwsEc2VpcFlowLogEnforcerCreate,
+		ReadContext:   resourceAwsEc2VpcFlowLogEnforcerRead,
+		UpdateContext: resourceAwsEc2VpcFlowLogEnforcerUpdate,
+		DeleteContext: resourceAwsEc2VpcFlowLogEnforcerDelete,
+		CustomizeDiff: customizeDiffEc2MatchThresholds("EC2 VPCs", countEc2SelectedVpcs),
+		SchemaVersion: 1,
+		Schema: map[string]*schema.Schema{
+			"ids":               ec2IDsSchema(ec2.ResourceTypeVpc),
+			"name":              ec2NameSchema(),
+			"filter":            ec2CustomFiltersSchema(),
+			"tags":              tagsSchema(),
+			"any_tag_keys":      ec2AnyTagKeysSchema(),
+			"required_tag_keys": ec2RequiredTagKeysSchema(),
+			"filters_csv":       ec2FiltersCSVSchema(),
+			"filters_json":      ec2FiltersJSONSchema(),
+			"log_destination_type": {
+				Description:  "The type of destination the Flow Log data is published to, either `cloud-watch-logs` or `s3`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      ec2.LogDestinationTypeCloudWatchLogs,
+				ValidateFunc: validation.StringInSlice([]string{ec2.LogDestinationTypeCloudWatchLogs, ec2.LogDestinationTypeS3}, false),
+			},
+			"log_destination": {
+				Description:  "The ARN of the CloudWatch log group or S3 bucket the Flow Log data is published to.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateArn,
+			},
+			"iam_role_arn": {
+				Description:  "The ARN of the IAM role that allows the Flow Log to publish to a CloudWatch log group. Required when `log_destination_type` is `cloud-watch-logs`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateArn,
+			},
+			"traffic_type": {
+				Description:  "The type of traffic to capture, one of `ACCEPT`, `REJECT` or `ALL`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      ec2.TrafficTypeAll,
+				ValidateFunc: validation.StringInSlice(ec2.TrafficType_Values(), false),
+			},
+			"flow_log_tags": {
+				Description: "Tags to apply to the created Flow Logs.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"dry_run": {
+				Description: "Report the VPCs missing a Flow Log without creating any Flow Logs.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"compliant_vpc_ids": {
+				Description: "The IDs of the matching VPCs which already had a Flow Log.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"noncompliant_vpc_ids": {
+				Description: "The IDs of the matching VPCs which were missing a Flow Log.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"flow_log_ids": {
+				Description: "The IDs of the Flow Logs created by this resource, keyed by VPC ID.",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"warn_if_matches_over":    ec2WarnIfMatchesOverSchema(),
+			"fail_if_matches_over":    ec2FailIfMatchesOverSchema(),
+			"match_threshold_warning": ec2MatchThresholdWarningSchema(),
+			"continue_on_error":       continueOnErrorSchema(),
+			"planned_changes":         plannedChangesSchema(),
+			"failed":                  failedChangesSchema(),
+		},
+	}
+}
+
+func resourceAwsEc2VpcFlowLogEnforcerCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// The ID is set before any Flow Log is created so that, if creating one fails, those created before are still
+	// saved in state, along with the tainted resource, and deleted when it is destroyed.
+	d.SetId(uuid.New().String())
+
+	warnings, err := enforceEc2VpcFlowLogs(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2VpcFlowLogEnforcerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2VpcFlowLogEnforcerRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*AWSClient).ec2conn
+	flowLogIDs := d.Get("flow_log_ids").(map[string]interface{})
+
+	if len(flowLogIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(flowLogIDs))
+	for _, v := range flowLogIDs {
+		ids = append(ids, v.(string))
+	}
+
+	flowLogs, err := finder.FlowLogs(conn, &ec2.DescribeFlowLogsInput{FlowLogIds: aws.StringSlice(ids)})
+	if err != nil {
+		return diag.Errorf("error reading EC2 Flow Logs: %s", err)
+	}
+
+	existing := make(map[string]bool, len(flowLogs))
+	for _, flowLog := range flowLogs {
+		existing[aws.StringValue(flowLog.FlowLogId)] = true
+	}
+
+	for vpcID, v := range flowLogIDs {
+		if !existing[v.(string)] {
+			log.Printf("[WARN] EC2 Flow Log (%s) for VPC (%s) no longer exists, removing from state", v.(string), vpcID)
+			delete(flowLogIDs, vpcID)
+		}
+	}
+
+	if err := d.Set("flow_log_ids", flowLogIDs); err != nil {
+		return diag.Errorf("error setting flow_log_ids: %s", err)
+	}
+
+	return nil
+}
+
+func resourceAwsEc2VpcFlowLogEnforcerUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	warnings, err := enforceEc2VpcFlowLogs(d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return append(append(warnings, failedChangesDiagnostics(d)...), resourceAwsEc2VpcFlowLogEnforcerRead(ctx, d, meta)...)
+}
+
+func resourceAwsEc2VpcFlowLogEnforcerDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	conn := meta.(*AWSClient).ec2conn
+	flowLogIDs := d.Get("flow_log_ids").(map[string]interface{})
+
+	if len(flowLogIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]*string, 0, len(flowLogIDs))
+	for _, v := range flowLogIDs {
+		ids = append(ids, aws.String(v.(string)))
+	}
+
+	output, err := conn.DeleteFlowLogs(&ec2.DeleteFlowLogsInput{FlowLogIds: ids})
+	if err != nil {
+		return diag.Errorf("error deleting EC2 Flow Logs: %s", err)
+	}
+
+	if err := tfec2.UnsuccessfulItemsError(output.Unsuccessful); err != nil {
+		return diag.Errorf("error deleting EC2 Flow Logs: %s", err)
+	}
+
+	return nil
+}
+
+// enforceEc2VpcFlowLogs creates a Flow Log for each of the selected VPCs missing one, recording the outcome in
+// the given *schema.ResourceData.
+// It returns the warning of warn_if_matches_over, if any.
+func enforceEc2VpcFlowLogs(d *schema.ResourceData, meta interface{}) (diag.Diagnostics, error) {
+	conn := meta.(*AWSClient).ec2conn
+	destinationType := d.Get("log_destination_type").(string)
+	iamRoleArn := d.Get("iam_role_arn").(string)
+
+	if destinationType == ec2.LogDestinationTypeCloudWatchLogs && iamRoleArn == "" {
+		return nil, fmt.Errorf("iam_role_arn is required when log_destination_type is %q", ec2.LogDestinationTypeCloudWatchLogs)
+	}
+
+	vpcs, filters, warnings, err := findEc2SelectedVpcs(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdWarnings, err := checkEc2MatchThresholds(d, len(vpcs), "EC2 VPCs", filters)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, thresholdWarnings...)
+
+	vpcIDs := make([]string, 0, len(vpcs))
+	for _, vpc := range vpcs {
+		vpcIDs = append(vpcIDs, aws.StringValue(vpc.VpcId))
+	}
+	sort.Strings(vpcIDs)
+
+	withFlowLogs := make(map[string]bool)
+	if len(vpcIDs) > 0 {
+		flowLogs, err := finder.FlowLogs(conn, &ec2.DescribeFlowLogsInput{
+			Filter: []*ec2.Filter{
+				{
+					Name:   aws.String("resource-id"),
+					Values: aws.StringSlice(vpcIDs),
+				},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reading EC2 Flow Logs: %w", err)
+		}
+
+		for _, flowLog := range flowLogs {
+			withFlowLogs[aws.StringValue(flowLog.ResourceId)] = true
+		}
+	}
+
+	var compliant, noncompliant []string
+	changes := make([]*plannedChange, 0, len(vpcIDs))
+
+	for _, vpcID := range vpcIDs {
+		if withFlowLogs[vpcID] {
+			compliant = append(compliant, vpcID)
+			changes = append(changes, &plannedChange{
+				ResourceID: vpcID,
+				Action:     plannedChangeActionNone,
+				Reason:     "VPC already has a Flow Log",
+			})
+		} else {
+			noncompliant = append(noncompliant, vpcID)
+			changes = append(changes, &plannedChange{
+				ResourceID: vpcID,
+				Action:     plannedChangeActionCreate,
+				Reason:     "VPC has no Flow Log",
+			})
+		}
+	}
+
+	flowLogIDs := d.Get("flow_log_ids").(map[string]interface{})
+	tags := keyvaluetags.New(mergeTagsWithDefaults(d.Get("flow_log_tags").(map[string]interface{}), providerDefaultTags(meta)))
+
+	err = applyPlannedChanges(changes, d.Get("dry_run").(bool), d.Get("continue_on_error").(bool), func(change *plannedChange) error {
+		vpcID := change.ResourceID
+		input := &ec2.CreateFlowLogsInput{
+			LogDestination:     aws.String(d.Get("log_destination").(string)),
+			LogDestinationType: aws.String(destinationType),
+			ResourceIds:        aws.StringSlice([]string{vpcID}),
+			ResourceType:       aws.String(ec2.FlowLogsResourceTypeVpc),
+			TagSpecifications:  ec2TagSpecificationsFromKeyValueTags(tags, ec2.ResourceTypeVpcFlowLog),
+			TrafficType:        aws.String(d.Get("traffic_type").(string)),
+		}
+
+		if iamRoleArn != "" {
+			input.DeliverLogsPermissionArn = aws.String(iamRoleArn)
+		}
+
+		log.Printf("[DEBUG] Creating EC2 Flow Log for VPC (%s): %s", vpcID, input)
+		output, err := conn.CreateFlowLogs(input)
+		if err != nil {
+			return fmt.Errorf("error creating EC2 Flow Log for VPC (%s): %w", vpcID, err)
+		}
+
+		if err := tfec2.UnsuccessfulItemsError(output.Unsuccessful); err != nil {
+			return fmt.Errorf("error creating EC2 Flow Log for VPC (%s): %w", vpcID, err)
+		}
+
+		if len(output.FlowLogIds) > 0 {
+			flowLogIDs[vpcID] = aws.StringValue(output.FlowLogIds[0])
+			change.After = map[string]string{"flow_log_id": aws.StringValue(output.FlowLogIds[0])}
+		}
+
+		return nil
+	})
+
+	// The Flow Logs created are recorded first, even if creating another one failed, so that they are not leaked.
+	if err := d.Set("flow_log_ids", flowLogIDs); err != nil {
+		return nil, fmt.Errorf("error setting flow_log_ids: %w", err)
+	}
+
+	if err := setPlannedChanges(d, changes); err != nil {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("compliant_vpc_ids", compliant); err != nil {
+		return nil, fmt.Errorf("error setting compliant_vpc_ids: %w", err)
+	}
+
+	if err := d.Set("noncompliant_vpc_ids", noncompliant); err != nil {
+		return nil, fmt.Errorf("error setting noncompliant_vpc_ids: %w", err)
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// testEc2VpcFlowLogEnforcerConn returns an EC2 client holding the given VPCs, those of withFlowLogs having a Flow
+// Log, failing to create the Flow Log of failVpcID, if any, and recording the VPCs Flow Logs are created for.
+func testEc2VpcFlowLogEnforcerConn(t *testing.T, vpcIDs []string, withFlowLogs []string, failVpcID string, created *[]string) *ec2.EC2 {
+	return testEc2Conn(t, func(r *request.Request) {
+		switch output := r.Data.(type) {
+		case *ec2.DescribeVpcsOutput:
+			for _, vpcID := range vpcIDs {
+				output.Vpcs = append(output.Vpcs, &ec2.Vpc{VpcId: aws.String(vpcID)})
+			}
+		case *ec2.DescribeFlowLogsOutput:
+			for _, vpcID := range withFlowLogs {
+				output.FlowLogs = append(output.FlowLogs, &ec2.FlowLog{FlowLogId: aws.String("fl-" + vpcID), ResourceId: aws.String(vpcID)})
+			}
+			for _, vpcID := range *created {
+				output.FlowLogs = append(output.FlowLogs, &ec2.FlowLog{FlowLogId: aws.String("fl-" + vpcID), ResourceId: aws.String(vpcID)})
+			}
+		case *ec2.CreateFlowLogsOutput:
+			vpcID := aws.StringValue(r.Params.(*ec2.CreateFlowLogsInput).ResourceIds[0])
+			if vpcID == failVpcID {
+				r.Error = awserr.New("FlowLogsLimitExceeded", "flow log limit exceeded", nil)
+				return
+			}
+			*created = append(*created, vpcID)
+			output.FlowLogIds = aws.StringSlice([]string{"fl-" + vpcID})
+		}
+	})
+}
+
+func testEc2VpcFlowLogEnforcerResourceData(t *testing.T, raw map[string]interface{}) *schema.ResourceData {
+	config := map[string]interface{}{
+		"tags":                 map[string]interface{}{"Environment": "production"},
+		"log_destination_type": ec2.LogDestinationTypeS3,
+		"log_destination":      "arn:aws:s3:::flow-logs",
+	}
+	for k, v := range raw {
+		config[k] = v
+	}
+
+	return schema.TestResourceDataRaw(t, resourceAwsUtilsEc2VpcFlowLogEnforcer().Schema, config)
+}
+
+func TestEnforceEc2VpcFlowLogs(t *testing.T) {
+	var created []string
+	conn := testEc2VpcFlowLogEnforcerConn(t, []string{"vpc-00000002", "vpc-00000001"}, []string{"vpc-00000001"}, "", &created)
+
+	d := testEc2VpcFlowLogEnforcerResourceData(t, nil)
+	if _, err := enforceEc2VpcFlowLogs(d, &AWSClient{ec2conn: conn}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := []string{"vpc-00000002"}; !reflect.DeepEqual(created, expected) {
+		t.Errorf("got Flow Logs created for %v, expected %v", created, expected)
+	}
+
+	if got, expected := d.Get("compliant_vpc_ids").([]interface{}), []interface{}{"vpc-00000001"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got compliant_vpc_ids %v, expected %v", got, expected)
+	}
+	if got, expected := d.Get("noncompliant_vpc_ids").([]interface{}), []interface{}{"vpc-00000002"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got noncompliant_vpc_ids %v, expected %v", got, expected)
+	}
+
+	// The Flow Log of vpc-00000001 was not created by the resource, so it is not deleted with it.
+	if got, expected := d.Get("flow_log_ids").(map[string]interface{}), map[string]interface{}{"vpc-00000002": "fl-vpc-00000002"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got flow_log_ids %v, expected %v", got, expected)
+	}
+}
+
+func TestEnforceEc2VpcFlowLogsDryRun(t *testing.T) {
+	var created []string
+	conn := testEc2VpcFlowLogEnforcerConn(t, []string{"vpc-00000001", "vpc-00000002"}, []string{"vpc-00000001"}, "", &created)
+
+	d := testEc2VpcFlowLogEnforcerResourceData(t, map[string]interface{}{"dry_run": true})
+	if _, err := enforceEc2VpcFlowLogs(d, &AWSClient{ec2conn: conn}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(created) != 0 {
+		t.Errorf("got Flow Logs created for %v, expected none", created)
+	}
+
+	if got, expected := d.Get("noncompliant_vpc_ids").([]interface{}), []interface{}{"vpc-00000002"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got noncompliant_vpc_ids %v, expected %v", got, expected)
+	}
+	if got := d.Get("flow_log_ids").(map[string]interface{}); len(got) != 0 {
+		t.Errorf("got flow_log_ids %v, expected none", got)
+	}
+
+	statuses := make(map[string]string)
+	for _, v := range d.Get("planned_changes").([]interface{}) {
+		m := v.(map[string]interface{})
+		statuses[m["resource_id"].(string)] = m["status"].(string)
+	}
+	expectedStatuses := map[string]string{
+		"vpc-00000001": plannedChangeStatusSkipped,
+		"vpc-00000002": plannedChangeStatusPlanned,
+	}
+	if !reflect.DeepEqual(statuses, expectedStatuses) {
+		t.Errorf("got planned_changes statuses %v, expected %v", statuses, expectedStatuses)
+	}
+}
+
+func TestResourceAwsEc2VpcFlowLogEnforcerCreatePartialFailure(t *testing.T) {
+	var created []string
+	conn := testEc2VpcFlowLogEnforcerConn(t, []string{"vpc-00000001", "vpc-00000002", "vpc-00000003"}, nil, "vpc-00000002", &created)
+
+	d := testEc2VpcFlowLogEnforcerResourceData(t, nil)
+	diags := resourceAwsEc2VpcFlowLogEnforcerCreate(context.Background(), d, &AWSClient{ec2conn: conn})
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "error creating EC2 Flow Log for VPC (vpc-00000002)") {
+		t.Fatalf("got %v, expected an error creating the Flow Log of vpc-00000002", diags)
+	}
+
+	if expected := []string{"vpc-00000001"}; !reflect.DeepEqual(created, expected) {
+		t.Errorf("got Flow Logs created for %v, expected %v", created, expected)
+	}
+
+	// The Flow Log created before the error is saved in state with the tainted resource, to be deleted with it.
+	if d.Id() == "" {
+		t.Errorf("expected the ID to be set")
+	}
+	if got, expected := d.Get("flow_log_ids").(map[string]interface{}), map[string]interface{}{"vpc-00000001": "fl-vpc-00000001"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got flow_log_ids %v, expected %v", got, expected)
+	}
+}
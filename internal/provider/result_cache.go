@@ -0,0 +1,236 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resultCacheDirName is the name of the directory of the result cache in the user cache directory, when no
+// cache_path is given.
+const resultCacheDirName = "terraform-provider-awsutils"
+
+// resultCacheEntry is the content of a file of the result cache.
+type resultCacheEntry struct {
+	CreatedAt time.Time       `json:"created_at"`
+	Result    json.RawMessage `json:"result"`
+}
+
+// resultCacheSchemas returns the attributes of the data sources caching the
+// objects they read on disk, to be merged into their schema with
+// mergeSchemas. The cache is used by withResultCache.
+//
+// Only the data sources making a single Describe call per read support it:
+// awsutils_ec2_instances_by_platform, awsutils_ec2_instances_by_subnet_utilization,
+// awsutils_ec2_instances_cross_referenced_with_asg, awsutils_ec2_instances_grouped_by_tag
+// and awsutils_ec2_instances_with_drifted_tags.
+//
+// In Terraform configuration this looks like this, to read the objects at
+// most once an hour:
+//
+// cache_ttl = "1h"
+func resultCacheSchemas() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"cache_ttl": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "How long the objects read are cached on disk and reused by the reads with the same selection, region and account, as a duration such as `30m` or `1h`. Nothing is cached when unset, or when the provider does not know its account, as with `skip_requesting_account_id`.",
+			ValidateFunc: func(v interface{}, k string) (ws []string, errors []error) {
+				if ttl, err := time.ParseDuration(v.(string)); err != nil || ttl <= 0 {
+					errors = append(errors, fmt.Errorf("%q must be a positive duration such as 30m or 1h, got %q", k, v.(string)))
+				}
+				return
+			},
+		},
+		"cache_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "The directory the cached objects are stored in, one file per selection. Defaults to `" + resultCacheDirName + "` in the user cache directory.",
+		},
+	}
+}
+
+// withResultCache sets result, a pointer, to the cached result of the given
+// data source for the given API input, and otherwise calls fetch to set it
+// and caches it, if the cache_ttl attribute returned by resultCacheSchemas is
+// set. Otherwise, it only calls fetch.
+//
+// The cache key is the hash of the data source, the region read, that of the
+// "region" attribute or else of the provider, the account of the provider, the
+// input and the resultCacheKeyAttributes, so that results are never shared
+// across contexts. Nothing is cached when the account of the provider is
+// unknown, as the credentials read could then belong to any account.
+// A cache which cannot be read or written is logged and bypassed, as it only
+// makes reads faster.
+func withResultCache(d *schema.ResourceData, meta interface{}, dataSource string, input interface{}, result interface{}, fetch func() error) error {
+	v, ok := d.GetOk("cache_ttl")
+	if !ok {
+		return fetch()
+	}
+
+	accountID := meta.(*AWSClient).accountid
+	if accountID == "" {
+		log.Printf("[WARN] Not caching %s result: the AWS account ID of the provider is unknown", dataSource)
+		return fetch()
+	}
+
+	ttl, err := time.ParseDuration(v.(string))
+	if err != nil {
+		return fmt.Errorf("error parsing cache_ttl: %w", err)
+	}
+
+	dir := d.Get("cache_path").(string)
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return fmt.Errorf("error finding the user cache directory, set cache_path: %w", err)
+		}
+		dir = filepath.Join(userCacheDir, resultCacheDirName)
+	}
+
+	region := meta.(*AWSClient).region
+	if v, ok := d.GetOk("region"); ok {
+		region = v.(string)
+	}
+
+	attributes := map[string]interface{}{
+		"max_results_cap": maxResultsCap(d, meta),
+	}
+	for _, k := range resultCacheKeyAttributes {
+		if v, ok := d.GetOk(k); ok {
+			if set, ok := v.(*schema.Set); ok {
+				v = set.List()
+			}
+			attributes[k] = v
+		}
+	}
+
+	key, err := resultCacheKey(dataSource, region, accountID, input, attributes)
+	if err != nil {
+		return err
+	}
+
+	hit, err := readResultCache(dir, key, ttl, time.Now(), result)
+	if err != nil {
+		log.Printf("[WARN] Error reading %s result cache (%s): %s", dataSource, dir, err)
+	}
+	if hit {
+		log.Printf("[DEBUG] Using cached %s result (%s)", dataSource, key)
+		return nil
+	}
+
+	if err := fetch(); err != nil {
+		return err
+	}
+
+	if err := writeResultCache(dir, key, time.Now(), result); err != nil {
+		log.Printf("[WARN] Error writing %s result cache (%s): %s", dataSource, dir, err)
+	}
+
+	return nil
+}
+
+// resultCacheKeyAttributes are the attributes shaping the result of a read
+// besides its API input, such as the client-side filters, which are part of
+// the cache key along with the max_results_cap in effect.
+var resultCacheKeyAttributes = []string{
+	"ids_strict",
+	"regex_filter",
+	"tags",
+	"case_insensitive",
+	"exclude_tags",
+	"any_tag_keys",
+	"has_tags",
+	"required_tag_keys",
+}
+
+// resultCacheKey returns the key of the cached result of the given data source for the given API input and attributes.
+func resultCacheKey(dataSource, region, accountID string, input interface{}, attributes map[string]interface{}) (string, error) {
+	b, err := json.Marshal(map[string]interface{}{
+		"data_source": dataSource,
+		"region":      region,
+		"account_id":  accountID,
+		"input":       input,
+		"attributes":  attributes,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error encoding result cache key: %w", err)
+	}
+
+	hash := sha256.Sum256(b)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// readResultCache decodes the result cached under the given key in the given directory into result, and returns
+// whether there was one created less than ttl before now. A missing entry is not an error.
+func readResultCache(dir, key string, ttl time.Duration, now time.Time, result interface{}) (bool, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, key+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var entry resultCacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return false, fmt.Errorf("error decoding cache entry (%s): %w", key, err)
+	}
+
+	if !now.Before(entry.CreatedAt.Add(ttl)) {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(entry.Result, result); err != nil {
+		return false, fmt.Errorf("error decoding cached result (%s): %w", key, err)
+	}
+
+	return true, nil
+}
+
+// writeResultCache caches the given result under the given key in the given directory, created if needed. The entry
+// is written to a temporary file renamed over the previous one, so that concurrent reads never see a partial entry
+// and concurrent writes of the same key leave one of them.
+func writeResultCache(dir, key string, now time.Time, result interface{}) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("error encoding result: %w", err)
+	}
+
+	b, err = json.Marshal(resultCacheEntry{CreatedAt: now, Result: b})
+	if err != nil {
+		return fmt.Errorf("error encoding cache entry: %w", err)
+	}
+
+	// The results may describe the infrastructure of the account, so they are only readable by the user.
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), filepath.Join(dir, key+".json"))
+}
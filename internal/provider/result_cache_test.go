@@ -0,0 +1,282 @@
+package provider
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResultCacheKey(t *testing.T) {
+	input := &ec2.DescribeInstancesInput{Filters: buildEC2AttributeFilterList(map[string]string{"tag:Team": "platform"})}
+
+	key, err := resultCacheKey("awsutils_ec2_instances_by_platform", "us-east-1", "123456789012", input, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if again, _ := resultCacheKey("awsutils_ec2_instances_by_platform", "us-east-1", "123456789012", input, nil); again != key {
+		t.Errorf("got key %s, expected the same key %s", again, key)
+	}
+
+	for name, args := range map[string][]string{
+		"data source": {"awsutils_ec2_instances_grouped_by_tag", "us-east-1", "123456789012"},
+		"region":      {"awsutils_ec2_instances_by_platform", "eu-west-1", "123456789012"},
+		"account":     {"awsutils_ec2_instances_by_platform", "us-east-1", "210987654321"},
+	} {
+		if other, _ := resultCacheKey(args[0], args[1], args[2], input, nil); other == key {
+			t.Errorf("another %s has the same key", name)
+		}
+	}
+
+	other := &ec2.DescribeInstancesInput{Filters: buildEC2AttributeFilterList(map[string]string{"tag:Team": "data"})}
+	if otherKey, _ := resultCacheKey("awsutils_ec2_instances_by_platform", "us-east-1", "123456789012", other, nil); otherKey == key {
+		t.Errorf("other filters have the same key")
+	}
+
+	attributes := map[string]interface{}{"max_results_cap": 10}
+	if otherKey, _ := resultCacheKey("awsutils_ec2_instances_by_platform", "us-east-1", "123456789012", input, attributes); otherKey == key {
+		t.Errorf("other attributes have the same key")
+	}
+}
+
+func TestResultCacheReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "result_cache")
+	if err != nil {
+		t.Fatalf("error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2021, 6, 7, 12, 0, 0, 0, time.UTC)
+	instances := []*ec2.Instance{{InstanceId: aws.String("i-0123456789abcdef0"), LaunchTime: aws.Time(now.Add(-time.Hour))}}
+
+	var got []*ec2.Instance
+	if hit, err := readResultCache(dir, "key", time.Hour, now, &got); err != nil || hit {
+		t.Fatalf("got hit %t and error %v for a missing entry, expected a miss", hit, err)
+	}
+
+	if err := writeResultCache(dir, "key", now, instances); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if hit, err := readResultCache(dir, "key", time.Hour, now.Add(59*time.Minute), &got); err != nil || !hit {
+		t.Fatalf("got hit %t and error %v within the TTL, expected a hit", hit, err)
+	}
+	if !reflect.DeepEqual(got, instances) {
+		t.Errorf("got %v, expected %v", got, instances)
+	}
+
+	if hit, err := readResultCache(dir, "key", time.Hour, now.Add(time.Hour), &got); err != nil || hit {
+		t.Errorf("got hit %t and error %v after the TTL, expected a miss", hit, err)
+	}
+
+	// Only the entry is left once written.
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("error reading directory: %s", err)
+	}
+	if len(files) != 1 || files[0].Name() != "key.json" || files[0].Mode().Perm() != 0600 {
+		t.Errorf("got files %v, expected key.json readable by the user only", files)
+	}
+}
+
+func TestResultCacheConcurrentWrites(t *testing.T) {
+	dir, err := ioutil.TempDir("", "result_cache")
+	if err != nil {
+		t.Fatalf("error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			result := make([]int, 1000)
+			for j := range result {
+				result[j] = i
+			}
+
+			if err := writeResultCache(dir, "key", now, result); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+
+			var got []int
+			if _, err := readResultCache(dir, "key", time.Hour, now, &got); err != nil {
+				t.Errorf("unexpected error reading a concurrently written entry: %s", err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	var got []int
+	if hit, err := readResultCache(dir, "key", time.Hour, now, &got); err != nil || !hit || len(got) != 1000 {
+		t.Errorf("got hit %t, error %v and %d values, expected one complete entry", hit, err, len(got))
+	}
+}
+
+func TestWithResultCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "result_cache")
+	if err != nil {
+		t.Fatalf("error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	meta := &AWSClient{region: "us-east-1", accountid: "123456789012"}
+	input := &ec2.DescribeInstancesInput{}
+
+	testCases := []struct {
+		Name           string
+		Raw            map[string]interface{}
+		ExpectedFetchs int
+	}{
+		{
+			Name:           "disabled",
+			Raw:            map[string]interface{}{"cache_path": dir},
+			ExpectedFetchs: 2,
+		},
+		{
+			Name:           "enabled",
+			Raw:            map[string]interface{}{"cache_path": dir, "cache_ttl": "1h"},
+			ExpectedFetchs: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resultCacheSchemas(), testCase.Raw)
+			fetchs := 0
+
+			for i := 0; i < 2; i++ {
+				var got []string
+				err := withResultCache(d, meta, "awsutils_test_"+testCase.Name, input, &got, func() error {
+					fetchs++
+					got = []string{"i-0123456789abcdef0"}
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if !reflect.DeepEqual(got, []string{"i-0123456789abcdef0"}) {
+					t.Errorf("got %v, expected the fetched result", got)
+				}
+			}
+
+			if fetchs != testCase.ExpectedFetchs {
+				t.Errorf("fetched %d times, expected %d", fetchs, testCase.ExpectedFetchs)
+			}
+		})
+	}
+}
+
+func TestWithResultCacheKeyAttributes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "result_cache")
+	if err != nil {
+		t.Fatalf("error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := mergeSchemas(map[string]*schema.Schema{
+		"max_results_cap": maxResultsCapSchema(),
+		"exclude_tags":    ec2ExcludeTagsSchema(),
+		"regex_filter":    ec2RegexFiltersSchema(),
+	}, resultCacheSchemas())
+	input := &ec2.DescribeInstancesInput{}
+
+	testCases := []struct {
+		Name           string
+		Meta           *AWSClient
+		Raw            []map[string]interface{}
+		ExpectedFetchs int
+	}{
+		{
+			Name: "same attributes",
+			Meta: &AWSClient{region: "us-east-1", accountid: "123456789012", maxResultsCap: 1000},
+			Raw: []map[string]interface{}{
+				{"cache_path": dir, "cache_ttl": "1h", "max_results_cap": 10},
+				{"cache_path": dir, "cache_ttl": "1h", "max_results_cap": 10},
+			},
+			ExpectedFetchs: 1,
+		},
+		{
+			Name: "max_results_cap",
+			Meta: &AWSClient{region: "us-east-1", accountid: "123456789012", maxResultsCap: 1000},
+			Raw: []map[string]interface{}{
+				{"cache_path": dir, "cache_ttl": "1h", "max_results_cap": 10},
+				{"cache_path": dir, "cache_ttl": "1h", "max_results_cap": 20},
+			},
+			ExpectedFetchs: 2,
+		},
+		{
+			Name: "provider max_results_cap",
+			Meta: &AWSClient{region: "us-east-1", accountid: "123456789012", maxResultsCap: 1000},
+			Raw: []map[string]interface{}{
+				{"cache_path": dir, "cache_ttl": "1h"},
+				{"cache_path": dir, "cache_ttl": "1h", "max_results_cap": 1000},
+			},
+			ExpectedFetchs: 1,
+		},
+		{
+			Name: "exclude_tags",
+			Meta: &AWSClient{region: "us-east-1", accountid: "123456789012", maxResultsCap: 1000},
+			Raw: []map[string]interface{}{
+				{"cache_path": dir, "cache_ttl": "1h"},
+				{"cache_path": dir, "cache_ttl": "1h", "exclude_tags": map[string]interface{}{"Env": "dev"}},
+			},
+			ExpectedFetchs: 2,
+		},
+		{
+			Name: "regex_filter",
+			Meta: &AWSClient{region: "us-east-1", accountid: "123456789012", maxResultsCap: 1000},
+			Raw: []map[string]interface{}{
+				{"cache_path": dir, "cache_ttl": "1h"},
+				{"cache_path": dir, "cache_ttl": "1h", "regex_filter": []interface{}{map[string]interface{}{"attribute": "private_dns_name", "pattern": "^ip-10-"}}},
+			},
+			ExpectedFetchs: 2,
+		},
+		{
+			Name: "unknown account",
+			Meta: &AWSClient{region: "us-east-1", maxResultsCap: 1000},
+			Raw: []map[string]interface{}{
+				{"cache_path": dir, "cache_ttl": "1h"},
+				{"cache_path": dir, "cache_ttl": "1h"},
+			},
+			ExpectedFetchs: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			fetchs := 0
+
+			for _, raw := range testCase.Raw {
+				d := schema.TestResourceDataRaw(t, s, raw)
+
+				var got []string
+				err := withResultCache(d, testCase.Meta, "awsutils_test_"+testCase.Name, input, &got, func() error {
+					fetchs++
+					got = []string{"i-0123456789abcdef0"}
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			}
+
+			if fetchs != testCase.ExpectedFetchs {
+				t.Errorf("fetched %d times, expected %d", fetchs, testCase.ExpectedFetchs)
+			}
+		})
+	}
+}
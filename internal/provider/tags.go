@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -47,6 +48,24 @@ func tagsSchemaConflictsWith(conflictsWith []string) *schema.Schema {
 	}
 }
 
+// tagsFromMap returns the EC2 tags for the given tag key/value map, typically
+// the value of an attribute conforming to tagsSchema(). AWS-managed tags are
+// ignored, except those of ec2FilterableAwsTagKeys, and the result is sorted
+// by key so that anything built from it, such as a filter list, is
+// deterministic.
+func tagsFromMap(m map[string]interface{}) []*ec2.Tag {
+	if len(m) == 0 {
+		return nil
+	}
+
+	tags := keyvaluetags.New(m).IgnoreAwsExcept(ec2FilterableAwsTagKeys...).Ec2Tags()
+	sort.Slice(tags, func(i, j int) bool {
+		return aws.StringValue(tags[i].Key) < aws.StringValue(tags[j].Key)
+	})
+
+	return tags
+}
+
 // ec2TagsFromTagDescriptions returns the tags from the given tag descriptions.
 // No attempt is made to remove duplicates.
 func ec2TagsFromTagDescriptions(tds []*ec2.TagDescription) []*ec2.Tag {
@@ -133,3 +152,34 @@ func SetTagsDiff(_ context.Context, diff *schema.ResourceDiff, meta interface{})
 
 	return nil
 }
+
+// mergeTagsWithDefaults returns the tags to create on a sub-resource of a
+// resource: the given default tags, typically those of the provider's
+// default_tags, overridden by the given resource tags. Default tags with the
+// reserved "aws:" prefix are skipped, while those of the resource tags are
+// preserved, leaving it to the caller to ignore them.
+func mergeTagsWithDefaults(resourceTags, defaultTags map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(resourceTags)+len(defaultTags))
+
+	for k, v := range keyvaluetags.New(defaultTags).IgnoreAws().Map() {
+		merged[k] = v
+	}
+
+	for k, v := range resourceTags {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+// providerDefaultTags returns the tags of the provider's default_tags, for use
+// with mergeTagsWithDefaults.
+func providerDefaultTags(meta interface{}) map[string]interface{} {
+	tags := make(map[string]interface{})
+
+	for k, v := range meta.(*AWSClient).DefaultTagsConfig.GetTags().Map() {
+		tags[k] = v
+	}
+
+	return tags
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeTagsWithDefaults(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		ResourceTags map[string]interface{}
+		DefaultTags  map[string]interface{}
+		Expected     map[string]interface{}
+	}{
+		{
+			Name:     "no tags",
+			Expected: map[string]interface{}{},
+		},
+		{
+			Name: "addition",
+			ResourceTags: map[string]interface{}{
+				"Name": "flow-log",
+			},
+			DefaultTags: map[string]interface{}{
+				"Environment": "prod",
+			},
+			Expected: map[string]interface{}{
+				"Name":        "flow-log",
+				"Environment": "prod",
+			},
+		},
+		{
+			Name: "override",
+			ResourceTags: map[string]interface{}{
+				"Environment": "staging",
+			},
+			DefaultTags: map[string]interface{}{
+				"Environment": "prod",
+				"Team":        "platform",
+			},
+			Expected: map[string]interface{}{
+				"Environment": "staging",
+				"Team":        "platform",
+			},
+		},
+		{
+			Name: "aws: tags",
+			ResourceTags: map[string]interface{}{
+				"aws:cloudformation:stack-name": "my-stack",
+			},
+			DefaultTags: map[string]interface{}{
+				"aws:created-by": "someone",
+				"Team":           "platform",
+			},
+			Expected: map[string]interface{}{
+				"aws:cloudformation:stack-name": "my-stack",
+				"Team":                          "platform",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got := mergeTagsWithDefaults(testCase.ResourceTags, testCase.DefaultTags)
+
+			if !reflect.DeepEqual(got, testCase.Expected) {
+				t.Errorf("got %v, expected %v", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestProviderDefaultTags(t *testing.T) {
+	if got := providerDefaultTags(&AWSClient{}); len(got) != 0 {
+		t.Errorf("got %v, expected no tags", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"regexp"
 	"sort"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/cloudposse/terraform-provider-awsutils/internal/tfresource"
@@ -85,4 +86,41 @@ func StringSlicesEqual(s1, s2 []*string) bool {
 	v2 := aws.StringValueSlice(s2)
 
 	return reflect.DeepEqual(v1, v2)
-}
\ No newline at end of file
+}
+
+// runConcurrently calls each of the given functions in its own goroutine, with at most limit of them running at
+// any time, and waits for all of them to return. It returns the error of the first function, in the given order,
+// which failed.
+func runConcurrently(limit int, funcs ...func() error) error {
+	if limit < 1 {
+		limit = 1
+	}
+
+	errs := make([]error, len(funcs))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, f := range funcs {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, f func() error) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			errs[i] = f()
+		}(i, f)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
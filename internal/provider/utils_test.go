@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunConcurrently(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Limit    int
+		Errors   []error
+		Expected error
+	}{
+		{
+			Name:   "no errors",
+			Limit:  2,
+			Errors: []error{nil, nil, nil, nil, nil},
+		},
+		{
+			Name:     "first error in order",
+			Limit:    3,
+			Errors:   []error{nil, errors.New("second"), errors.New("third")},
+			Expected: errors.New("second"),
+		},
+		{
+			Name:   "zero limit",
+			Limit:  0,
+			Errors: []error{nil, nil},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var running, maxRunning int32
+
+			funcs := make([]func() error, 0, len(testCase.Errors))
+			for _, err := range testCase.Errors {
+				err := err
+				funcs = append(funcs, func() error {
+					n := atomic.AddInt32(&running, 1)
+					for {
+						m := atomic.LoadInt32(&maxRunning)
+						if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+							break
+						}
+					}
+					time.Sleep(10 * time.Millisecond)
+					atomic.AddInt32(&running, -1)
+					return err
+				})
+			}
+
+			err := runConcurrently(testCase.Limit, funcs...)
+
+			if (err == nil) != (testCase.Expected == nil) || (err != nil && err.Error() != testCase.Expected.Error()) {
+				t.Errorf("got error %v, expected %v", err, testCase.Expected)
+			}
+
+			limit := int32(testCase.Limit)
+			if limit < 1 {
+				limit = 1
+			}
+
+			if maxRunning > limit {
+				t.Errorf("got %d functions running concurrently, expected at most %d", maxRunning, limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// ErrCodeValidateOnly is the error code of the API calls refused in the
+// validate_only mode, see validateOnlySession.
+const ErrCodeValidateOnly = "ValidateOnly"
+
+// validateOnlyOperationPrefixes are the prefixes of the names of the
+// read-only operations answered with an empty response in the validate_only
+// mode.
+var validateOnlyOperationPrefixes = []string{"Describe", "Get", "List"}
+
+// validateOnlySession returns the session of the provider's validate_only
+// mode, in the given region, which sends no requests, so that neither
+// credentials nor network access are needed. The read-only operations, such
+// as the "Describe..." calls, return an empty response, as if nothing
+// matched, after the filters and the other inputs are built and validated
+// as usual. The other operations fail with ErrCodeValidateOnly rather than
+// pretending to change anything.
+//
+// The account ID is unknown in this mode, and the partition is the one of the
+// region.
+func validateOnlySession(region string) (*session.Session, string, string, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Credentials: credentials.AnonymousCredentials,
+		MaxRetries:  aws.Int(0),
+		Region:      aws.String(region),
+	})
+	if err != nil {
+		return nil, "", "", fmt.Errorf("error configuring validate_only session: %w", err)
+	}
+
+	// The handlers unmarshalling the responses are added by each service
+	// client, so the requests are answered with an empty body, which all the
+	// protocols decode as an empty output.
+	sess.Handlers.Send.Clear()
+	sess.Handlers.Send.PushBack(func(r *request.Request) {
+		for _, prefix := range validateOnlyOperationPrefixes {
+			if strings.HasPrefix(r.Operation.Name, prefix) {
+				r.HTTPResponse = &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+				}
+				return
+			}
+		}
+		r.Error = awserr.New(ErrCodeValidateOnly, fmt.Sprintf("%s is not called when validate_only is set", r.Operation.Name), nil)
+	})
+
+	partition := endpoints.AwsPartitionID
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		partition = p.ID()
+	}
+
+	return sess, "", partition, nil
+}
@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/aws-sdk-go-base/tfawserr"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testValidateOnlyClient(t *testing.T) *AWSClient {
+	t.Helper()
+
+	config := Config{
+		Region:        "us-east-1",
+		Endpoints:     map[string]string{},
+		MaxResultsCap: defaultMaxResultsCap,
+		ValidateOnly:  true,
+	}
+
+	client, err := config.Client()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return client.(*AWSClient)
+}
+
+func TestValidateOnlySession(t *testing.T) {
+	client := testValidateOnlyClient(t)
+
+	if client.accountid != "" {
+		t.Errorf("got account ID %q, expected none", client.accountid)
+	}
+	if client.partition != "aws" {
+		t.Errorf("got partition %q, expected aws", client.partition)
+	}
+
+	output, err := client.ec2conn.DescribeVpcs(&ec2.DescribeVpcsInput{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(output.Vpcs) != 0 || output.NextToken != nil {
+		t.Errorf("got %v, expected an empty response", output)
+	}
+
+	_, err = client.ec2conn.CreateTags(&ec2.CreateTagsInput{
+		Resources: aws.StringSlice([]string{"vpc-01234567"}),
+		Tags:      []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String("my-vpc")}},
+	})
+	if !tfawserr.ErrCodeEquals(err, ErrCodeValidateOnly) {
+		t.Errorf("got error %v, expected %s", err, ErrCodeValidateOnly)
+	}
+}
+
+func TestValidateOnlyDataSources(t *testing.T) {
+	client := testValidateOnlyClient(t)
+
+	testCases := []struct {
+		Name          string
+		Resource      *schema.Resource
+		Raw           map[string]interface{}
+		ExpectedEmpty string
+		ExpectedError string
+	}{
+		{
+			Name:     "AMIs failing on empty",
+			Resource: dataSourceAwsUtilsEc2AmisByTagWithLatest(),
+			Raw: map[string]interface{}{
+				"tags": map[string]interface{}{"Team": "platform"},
+			},
+			ExpectedEmpty: "matched_image_ids",
+		},
+		{
+			Name:     "VPC summary",
+			Resource: dataSourceAwsUtilsEc2VpcSummary(),
+			Raw: map[string]interface{}{
+				"name": "my-vpc",
+			},
+		},
+		{
+			Name:     "instances",
+			Resource: dataSourceAwsUtilsEc2InstancesGroupedByTag(),
+			Raw: map[string]interface{}{
+				"group_by_tag_key": "Team",
+				"filter": []interface{}{
+					map[string]interface{}{"name": "vpc-id", "values": []interface{}{"vpc-01234567"}},
+				},
+			},
+		},
+		{
+			Name:     "Subnets containing an IP",
+			Resource: dataSourceAwsUtilsEc2Subnets(),
+			Raw: map[string]interface{}{
+				"vpc_id":      "vpc-01234567",
+				"contains_ip": "10.0.1.1",
+			},
+			ExpectedEmpty: "subnet_ids",
+		},
+		{
+			Name:     "invalid filter value",
+			Resource: dataSourceAwsUtilsEc2InstancesGroupedByTag(),
+			Raw: map[string]interface{}{
+				"group_by_tag_key": "Team",
+				"filter": []interface{}{
+					map[string]interface{}{"name": "vpc-id", "values": []interface{}{"vpc-0123"}},
+				},
+			},
+			ExpectedError: `"vpc-0123" is not a valid vpc ID`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, testCase.Resource.Schema, testCase.Raw)
+
+			diags := testCase.Resource.ReadContext(context.Background(), d, client)
+
+			if testCase.ExpectedError != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary, testCase.ExpectedError) {
+					t.Fatalf("got %v, expected %q", diags, testCase.ExpectedError)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if d.Id() != "us-east-1" {
+				t.Errorf("got ID %q, expected the region", d.Id())
+			}
+
+			if testCase.ExpectedEmpty != "" {
+				if got := d.Get(testCase.ExpectedEmpty).([]interface{}); len(got) != 0 {
+					t.Errorf("got %s %v, expected none", testCase.ExpectedEmpty, got)
+				}
+			}
+		})
+	}
+}
@@ -1,5 +1,9 @@
 package ec2
 
+import (
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
 const (
 	// https://docs.aws.amazon.com/vpc/latest/privatelink/vpce-interface.html#vpce-interface-lifecycle
 	VpcEndpointStateAvailable         = "available"
@@ -10,3 +14,23 @@ const (
 	VpcEndpointStatePendingAcceptance = "pendingAcceptance"
 	VpcEndpointStateRejected          = "rejected"
 )
+
+// NetworkInterfaceTypeFilterNatGateway is the value of the "interface-type" filter of DescribeNetworkInterfaces
+// matching NAT gateway interfaces. It differs from ec2.NetworkInterfaceTypeNatGateway ("natGateway"), which is the
+// value reported in the InterfaceType attribute of those interfaces.
+const NetworkInterfaceTypeFilterNatGateway = "nat_gateway"
+
+// NetworkInterfaceTypeFilter_Values returns the values accepted by the "interface-type" filter of
+// DescribeNetworkInterfaces.
+func NetworkInterfaceTypeFilter_Values() []string {
+	var values []string
+
+	for _, v := range ec2.NetworkInterfaceType_Values() {
+		if v == ec2.NetworkInterfaceTypeNatGateway {
+			v = NetworkInterfaceTypeFilterNatGateway
+		}
+		values = append(values, v)
+	}
+
+	return values
+}
@@ -9,6 +9,7 @@ import (
 )
 
 const (
+	ErrCodeDependencyViolation          = "DependencyViolation"
 	ErrCodeGatewayNotAttached           = "Gateway.NotAttached"
 	ErrCodeInvalidAssociationIDNotFound = "InvalidAssociationID.NotFound"
 	ErrCodeInvalidParameter             = "InvalidParameter"
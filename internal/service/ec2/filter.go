@@ -29,6 +29,18 @@ import (
 // the EC2 API, to aid in the implementation of Terraform data sources that
 // retrieve data about EC2 objects.
 func BuildAttributeFilterList(attrs map[string]string) []*ec2.Filter {
+	return BuildAttributeFilterListWithOpts(attrs, false)
+}
+
+// BuildAttributeFilterListWithOpts is BuildAttributeFilterList, except that
+// the attributes given with empty string values produce a filter with a
+// single empty value if keepEmpty is set, rather than being ignored.
+//
+// Setting keepEmpty changes the meaning of an empty value from "any value" to
+// "the empty value": for example, a "tag:Name" attribute with an empty value
+// then only matches the objects with an empty Name tag, rather than every
+// object.
+func BuildAttributeFilterListWithOpts(attrs map[string]string, keepEmpty bool) []*ec2.Filter {
 	var filters []*ec2.Filter
 
 	// sort the filters by name to make the output deterministic
@@ -41,7 +53,7 @@ func BuildAttributeFilterList(attrs map[string]string) []*ec2.Filter {
 
 	for _, filterName := range names {
 		value := attrs[filterName]
-		if value == "" {
+		if value == "" && !keepEmpty {
 			continue
 		}
 
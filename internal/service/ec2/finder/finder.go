@@ -1,10 +1,22 @@
 package finder
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 )
 
+// MaxResultsExceededError is returned by the finders accepting a maxResults argument when more results than it match.
+type MaxResultsExceededError struct {
+	MaxResults int
+}
+
+func (e *MaxResultsExceededError) Error() string {
+	return fmt.Sprintf("more than %d results matched", e.MaxResults)
+}
+
 // InternetGatewayForVPC looks up the Internet Gateway for the given VPC. When not found, returns nil and potentially an API error.
 func InternetGatewayForVPC(conn *ec2.EC2, vpcID string) (*ec2.InternetGateway, error) {
 	filters := []*ec2.Filter{
@@ -98,3 +110,887 @@ func VpcDefault(conn *ec2.EC2) (*ec2.Vpc, error) {
 
 	return nil, nil
 }
+
+// SecurityGroups looks up the Security Groups matching the given input, following all result pages until more than
+// maxResults, if positive, are found, in which case a *MaxResultsExceededError is returned.
+func SecurityGroups(conn *ec2.EC2, input *ec2.DescribeSecurityGroupsInput, maxResults int) ([]*ec2.SecurityGroup, error) {
+	var output []*ec2.SecurityGroup
+	var exceeded bool
+
+	// A lookup by ID only is made in a single request, whose results are bounded by the number of IDs rather than
+	// maxResults.
+	if len(input.GroupIds) > 0 && len(input.Filters) == 0 && input.NextToken == nil {
+		page, err := conn.DescribeSecurityGroups(input)
+		if err != nil {
+			return nil, err
+		}
+
+		if page.NextToken == nil {
+			for _, sg := range page.SecurityGroups {
+				if sg != nil {
+					output = append(output, sg)
+				}
+			}
+
+			return output, nil
+		}
+	}
+
+	err := conn.DescribeSecurityGroupsPages(input, func(page *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, sg := range page.SecurityGroups {
+			if sg == nil {
+				continue
+			}
+
+			output = append(output, sg)
+		}
+
+		if maxResults > 0 && len(output) > maxResults {
+			exceeded = true
+			return false
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exceeded {
+		return nil, &MaxResultsExceededError{MaxResults: maxResults}
+	}
+
+	return output, nil
+}
+
+// securityGroupRulesGroupIDChunkSize is the maximum number of group IDs passed in a single
+// "group-id" filter when looking up Security Group Rules.
+const securityGroupRulesGroupIDChunkSize = 200
+
+// SecurityGroupRulesForGroups looks up all the Security Group Rules belonging to the given Security Groups,
+// following all result pages.
+func SecurityGroupRulesForGroups(conn *ec2.EC2, groupIDs []string) ([]*ec2.SecurityGroupRule, error) {
+	var output []*ec2.SecurityGroupRule
+
+	for i := 0; i < len(groupIDs); i += securityGroupRulesGroupIDChunkSize {
+		j := i + securityGroupRulesGroupIDChunkSize
+		if j > len(groupIDs) {
+			j = len(groupIDs)
+		}
+
+		input := &ec2.DescribeSecurityGroupRulesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("group-id"),
+					Values: aws.StringSlice(groupIDs[i:j]),
+				},
+			},
+		}
+
+		err := conn.DescribeSecurityGroupRulesPages(input, func(page *ec2.DescribeSecurityGroupRulesOutput, lastPage bool) bool {
+			if page == nil {
+				return !lastPage
+			}
+
+			for _, rule := range page.SecurityGroupRules {
+				if rule == nil {
+					continue
+				}
+
+				output = append(output, rule)
+			}
+
+			return !lastPage
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return output, nil
+}
+
+// ManagedPrefixListEntries looks up the entries of the given Managed Prefix List, following all result pages.
+func ManagedPrefixListEntries(conn *ec2.EC2, prefixListID string) ([]*ec2.PrefixListEntry, error) {
+	var output []*ec2.PrefixListEntry
+
+	input := &ec2.GetManagedPrefixListEntriesInput{
+		PrefixListId: aws.String(prefixListID),
+	}
+
+	err := conn.GetManagedPrefixListEntriesPages(input, func(page *ec2.GetManagedPrefixListEntriesOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, entry := range page.Entries {
+			if entry == nil {
+				continue
+			}
+
+			output = append(output, entry)
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// Vpcs looks up the VPCs matching the given input, following all result pages until more than
+// maxResults, if positive, are found, in which case a *MaxResultsExceededError is returned.
+func Vpcs(conn *ec2.EC2, input *ec2.DescribeVpcsInput, maxResults int) ([]*ec2.Vpc, error) {
+	var output []*ec2.Vpc
+	var exceeded bool
+
+	err := conn.DescribeVpcsPages(input, func(page *ec2.DescribeVpcsOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, vpc := range page.Vpcs {
+			if vpc == nil {
+				continue
+			}
+
+			output = append(output, vpc)
+		}
+
+		if maxResults > 0 && len(output) > maxResults {
+			exceeded = true
+			return false
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exceeded {
+		return nil, &MaxResultsExceededError{MaxResults: maxResults}
+	}
+
+	return output, nil
+}
+
+// FlowLogs looks up the Flow Logs matching the given input, following all result pages.
+func FlowLogs(conn *ec2.EC2, input *ec2.DescribeFlowLogsInput) ([]*ec2.FlowLog, error) {
+	var output []*ec2.FlowLog
+
+	err := conn.DescribeFlowLogsPages(input, func(page *ec2.DescribeFlowLogsOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, flowLog := range page.FlowLogs {
+			if flowLog == nil {
+				continue
+			}
+
+			output = append(output, flowLog)
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// Volumes looks up the EBS Volumes matching the given input, following all result pages until more than maxResults,
+// if positive, are found, in which case a *MaxResultsExceededError is returned.
+func Volumes(conn *ec2.EC2, input *ec2.DescribeVolumesInput, maxResults int) ([]*ec2.Volume, error) {
+	var output []*ec2.Volume
+	var exceeded bool
+
+	// A lookup by ID only is made in a single request, whose results are bounded by the number of IDs rather than
+	// maxResults.
+	if len(input.VolumeIds) > 0 && len(input.Filters) == 0 && input.NextToken == nil {
+		page, err := conn.DescribeVolumes(input)
+		if err != nil {
+			return nil, err
+		}
+
+		if page.NextToken == nil {
+			for _, volume := range page.Volumes {
+				if volume != nil {
+					output = append(output, volume)
+				}
+			}
+
+			return output, nil
+		}
+	}
+
+	err := conn.DescribeVolumesPages(input, func(page *ec2.DescribeVolumesOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, volume := range page.Volumes {
+			if volume == nil {
+				continue
+			}
+
+			output = append(output, volume)
+		}
+
+		if maxResults > 0 && len(output) > maxResults {
+			exceeded = true
+			return false
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exceeded {
+		return nil, &MaxResultsExceededError{MaxResults: maxResults}
+	}
+
+	return output, nil
+}
+
+// Instances looks up the EC2 Instances of all the Reservations matching the given input, following all result pages
+// until more than maxResults instances, if positive, are found, in which case a *MaxResultsExceededError is returned.
+func Instances(conn *ec2.EC2, input *ec2.DescribeInstancesInput, maxResults int) ([]*ec2.Instance, error) {
+	var output []*ec2.Instance
+
+	// A lookup by ID only is made in a single request, whose results are bounded by the number of IDs rather than
+	// maxResults.
+	if len(input.InstanceIds) > 0 && len(input.Filters) == 0 && input.NextToken == nil {
+		page, err := conn.DescribeInstances(input)
+		if err != nil {
+			return nil, err
+		}
+
+		if page.NextToken == nil {
+			for _, reservation := range page.Reservations {
+				if reservation == nil {
+					continue
+				}
+
+				for _, instance := range reservation.Instances {
+					if instance != nil {
+						output = append(output, instance)
+					}
+				}
+			}
+
+			return output, nil
+		}
+	}
+
+	stream := StreamInstances(context.Background(), conn, input, maxResults)
+	defer stream.Close()
+
+	for instance := stream.Next(); instance != nil; instance = stream.Next() {
+		output = append(output, instance)
+	}
+
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// Subnets looks up the Subnets matching the given input, following all result pages until more than
+// maxResults, if positive, are found, in which case a *MaxResultsExceededError is returned.
+func Subnets(conn *ec2.EC2, input *ec2.DescribeSubnetsInput, maxResults int) ([]*ec2.Subnet, error) {
+	var output []*ec2.Subnet
+	var exceeded bool
+
+	err := conn.DescribeSubnetsPages(input, func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, subnet := range page.Subnets {
+			if subnet == nil {
+				continue
+			}
+
+			output = append(output, subnet)
+		}
+
+		if maxResults > 0 && len(output) > maxResults {
+			exceeded = true
+			return false
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exceeded {
+		return nil, &MaxResultsExceededError{MaxResults: maxResults}
+	}
+
+	return output, nil
+}
+
+// RouteTables looks up the Route Tables matching the given input, following all result pages until more than
+// maxResults, if positive, are found, in which case a *MaxResultsExceededError is returned.
+func RouteTables(conn *ec2.EC2, input *ec2.DescribeRouteTablesInput, maxResults int) ([]*ec2.RouteTable, error) {
+	var output []*ec2.RouteTable
+	var exceeded bool
+
+	// A lookup by ID only is made in a single request, whose results are bounded by the number of IDs rather than
+	// maxResults.
+	if len(input.RouteTableIds) > 0 && len(input.Filters) == 0 && input.NextToken == nil {
+		page, err := conn.DescribeRouteTables(input)
+		if err != nil {
+			return nil, err
+		}
+
+		if page.NextToken == nil {
+			for _, routeTable := range page.RouteTables {
+				if routeTable != nil {
+					output = append(output, routeTable)
+				}
+			}
+
+			return output, nil
+		}
+	}
+
+	err := conn.DescribeRouteTablesPages(input, func(page *ec2.DescribeRouteTablesOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, routeTable := range page.RouteTables {
+			if routeTable == nil {
+				continue
+			}
+
+			output = append(output, routeTable)
+		}
+
+		if maxResults > 0 && len(output) > maxResults {
+			exceeded = true
+			return false
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exceeded {
+		return nil, &MaxResultsExceededError{MaxResults: maxResults}
+	}
+
+	return output, nil
+}
+
+// InternetGateways looks up the Internet Gateways matching the given input, following all result pages.
+func InternetGateways(conn *ec2.EC2, input *ec2.DescribeInternetGatewaysInput) ([]*ec2.InternetGateway, error) {
+	var output []*ec2.InternetGateway
+
+	err := conn.DescribeInternetGatewaysPages(input, func(page *ec2.DescribeInternetGatewaysOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, internetGateway := range page.InternetGateways {
+			if internetGateway == nil {
+				continue
+			}
+
+			output = append(output, internetGateway)
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// NatGateways looks up the NAT Gateways matching the given input, following all result pages.
+func NatGateways(conn *ec2.EC2, input *ec2.DescribeNatGatewaysInput) ([]*ec2.NatGateway, error) {
+	var output []*ec2.NatGateway
+
+	err := conn.DescribeNatGatewaysPages(input, func(page *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, natGateway := range page.NatGateways {
+			if natGateway == nil {
+				continue
+			}
+
+			output = append(output, natGateway)
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// Images looks up the AMIs matching the given input, following all result pages until more than maxResults, if
+// positive, are found, in which case a *MaxResultsExceededError is returned.
+func Images(conn *ec2.EC2, input *ec2.DescribeImagesInput, maxResults int) ([]*ec2.Image, error) {
+	var output []*ec2.Image
+	var exceeded bool
+
+	// A lookup by ID only is made in a single request, whose results are bounded by the number of IDs rather than
+	// maxResults.
+	if len(input.ImageIds) > 0 && len(input.Filters) == 0 && input.NextToken == nil {
+		page, err := conn.DescribeImages(input)
+		if err != nil {
+			return nil, err
+		}
+
+		if page.NextToken == nil {
+			for _, image := range page.Images {
+				if image != nil {
+					output = append(output, image)
+				}
+			}
+
+			return output, nil
+		}
+	}
+
+	err := conn.DescribeImagesPages(input, func(page *ec2.DescribeImagesOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, image := range page.Images {
+			if image == nil {
+				continue
+			}
+
+			output = append(output, image)
+		}
+
+		if maxResults > 0 && len(output) > maxResults {
+			exceeded = true
+			return false
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exceeded {
+		return nil, &MaxResultsExceededError{MaxResults: maxResults}
+	}
+
+	return output, nil
+}
+
+// Snapshots looks up the EBS Snapshots matching the given input, following all result pages until more than
+// maxResults, if positive, are found, in which case a *MaxResultsExceededError is returned.
+func Snapshots(conn *ec2.EC2, input *ec2.DescribeSnapshotsInput, maxResults int) ([]*ec2.Snapshot, error) {
+	var output []*ec2.Snapshot
+	var exceeded bool
+
+	err := conn.DescribeSnapshotsPages(input, func(page *ec2.DescribeSnapshotsOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, snapshot := range page.Snapshots {
+			if snapshot == nil {
+				continue
+			}
+
+			output = append(output, snapshot)
+		}
+
+		if maxResults > 0 && len(output) > maxResults {
+			exceeded = true
+			return false
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exceeded {
+		return nil, &MaxResultsExceededError{MaxResults: maxResults}
+	}
+
+	return output, nil
+}
+
+// snapshotIDChunkSize is the maximum number of snapshot IDs passed in a single "snapshot-id" filter
+// when looking up EBS Snapshots by ID.
+const snapshotIDChunkSize = 200
+
+// SnapshotsByID looks up the EBS Snapshots with the given IDs, following all result pages. Unlike the
+// SnapshotIds input parameter, the "snapshot-id" filter used does not fail when some of the Snapshots do
+// not exist, which are instead missing from the result.
+func SnapshotsByID(conn *ec2.EC2, snapshotIDs []string) ([]*ec2.Snapshot, error) {
+	var output []*ec2.Snapshot
+
+	for i := 0; i < len(snapshotIDs); i += snapshotIDChunkSize {
+		j := i + snapshotIDChunkSize
+		if j > len(snapshotIDs) {
+			j = len(snapshotIDs)
+		}
+
+		snapshots, err := Snapshots(conn, &ec2.DescribeSnapshotsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("snapshot-id"),
+					Values: aws.StringSlice(snapshotIDs[i:j]),
+				},
+			},
+		}, 0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, snapshots...)
+	}
+
+	return output, nil
+}
+
+// volumeIDChunkSize is the maximum number of volume IDs passed in a single "volume-id" filter
+// when looking up EBS Volumes by ID.
+const volumeIDChunkSize = 200
+
+// VolumesByID looks up the EBS Volumes with the given IDs, following all result pages. Unlike the
+// VolumeIds input parameter, the "volume-id" filter used does not fail when some of the Volumes do
+// not exist, which are instead missing from the result.
+func VolumesByID(conn *ec2.EC2, volumeIDs []string) ([]*ec2.Volume, error) {
+	var output []*ec2.Volume
+
+	for i := 0; i < len(volumeIDs); i += volumeIDChunkSize {
+		j := i + volumeIDChunkSize
+		if j > len(volumeIDs) {
+			j = len(volumeIDs)
+		}
+
+		volumes, err := Volumes(conn, &ec2.DescribeVolumesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("volume-id"),
+					Values: aws.StringSlice(volumeIDs[i:j]),
+				},
+			},
+		}, 0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, volumes...)
+	}
+
+	return output, nil
+}
+
+// subnetIDChunkSize is the maximum number of subnet IDs passed in a single "subnet-id" filter
+// when looking up Subnets by ID.
+const subnetIDChunkSize = 200
+
+// SubnetsByID looks up the Subnets with the given IDs, following all result pages. Unlike the
+// SubnetIds input parameter, the "subnet-id" filter used does not fail when some of the Subnets do
+// not exist, which are instead missing from the result.
+func SubnetsByID(conn *ec2.EC2, subnetIDs []string) ([]*ec2.Subnet, error) {
+	var output []*ec2.Subnet
+
+	for i := 0; i < len(subnetIDs); i += subnetIDChunkSize {
+		j := i + subnetIDChunkSize
+		if j > len(subnetIDs) {
+			j = len(subnetIDs)
+		}
+
+		subnets, err := Subnets(conn, &ec2.DescribeSubnetsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("subnet-id"),
+					Values: aws.StringSlice(subnetIDs[i:j]),
+				},
+			},
+		}, 0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, subnets...)
+	}
+
+	return output, nil
+}
+
+// imageIDChunkSize is the maximum number of AMI IDs passed in a single "image-id" filter when looking up the
+// objects referencing AMIs.
+const imageIDChunkSize = 200
+
+// InstancesByImageID looks up the EC2 Instances launched from any of the given AMIs, in any of the given states if
+// any, following all result pages. The AMI IDs are passed in chunks, so that any number of them is looked up in as
+// few requests as possible.
+func InstancesByImageID(conn *ec2.EC2, imageIDs, states []string) ([]*ec2.Instance, error) {
+	var output []*ec2.Instance
+
+	for i := 0; i < len(imageIDs); i += imageIDChunkSize {
+		j := i + imageIDChunkSize
+		if j > len(imageIDs) {
+			j = len(imageIDs)
+		}
+
+		input := &ec2.DescribeInstancesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("image-id"),
+					Values: aws.StringSlice(imageIDs[i:j]),
+				},
+			},
+		}
+		if len(states) > 0 {
+			input.Filters = append(input.Filters, &ec2.Filter{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice(states),
+			})
+		}
+
+		instances, err := Instances(conn, input, 0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, instances...)
+	}
+
+	return output, nil
+}
+
+// LaunchTemplates looks up the launch templates matching the given input, following all result pages.
+func LaunchTemplates(conn *ec2.EC2, input *ec2.DescribeLaunchTemplatesInput) ([]*ec2.LaunchTemplate, error) {
+	var output []*ec2.LaunchTemplate
+
+	err := conn.DescribeLaunchTemplatesPages(input, func(page *ec2.DescribeLaunchTemplatesOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, launchTemplate := range page.LaunchTemplates {
+			if launchTemplate == nil {
+				continue
+			}
+
+			output = append(output, launchTemplate)
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// NetworkInterfaces looks up the network interfaces matching the given input, following all result pages.
+func NetworkInterfaces(conn *ec2.EC2, input *ec2.DescribeNetworkInterfacesInput) ([]*ec2.NetworkInterface, error) {
+	var output []*ec2.NetworkInterface
+
+	err := conn.DescribeNetworkInterfacesPages(input, func(page *ec2.DescribeNetworkInterfacesOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, networkInterface := range page.NetworkInterfaces {
+			if networkInterface == nil {
+				continue
+			}
+
+			output = append(output, networkInterface)
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// Tags looks up the tags matching the given input, following all result pages until more than
+// maxResults, if positive, are found, in which case a *MaxResultsExceededError is returned.
+func Tags(conn *ec2.EC2, input *ec2.DescribeTagsInput, maxResults int) ([]*ec2.TagDescription, error) {
+	var output []*ec2.TagDescription
+	var exceeded bool
+
+	err := conn.DescribeTagsPages(input, func(page *ec2.DescribeTagsOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, tag := range page.Tags {
+			if tag == nil {
+				continue
+			}
+
+			output = append(output, tag)
+		}
+
+		if maxResults > 0 && len(output) > maxResults {
+			exceeded = true
+			return false
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exceeded {
+		return nil, &MaxResultsExceededError{MaxResults: maxResults}
+	}
+
+	return output, nil
+}
+
+// LaunchTemplateVersions looks up the launch template versions matching the given input, following all result pages.
+func LaunchTemplateVersions(conn *ec2.EC2, input *ec2.DescribeLaunchTemplateVersionsInput) ([]*ec2.LaunchTemplateVersion, error) {
+	var output []*ec2.LaunchTemplateVersion
+
+	err := conn.DescribeLaunchTemplateVersionsPages(input, func(page *ec2.DescribeLaunchTemplateVersionsOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, version := range page.LaunchTemplateVersions {
+			if version == nil {
+				continue
+			}
+
+			output = append(output, version)
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// InstanceStatuses looks up the statuses of the EC2 Instances matching the given input, following all result pages.
+func InstanceStatuses(conn *ec2.EC2, input *ec2.DescribeInstanceStatusInput) ([]*ec2.InstanceStatus, error) {
+	var output []*ec2.InstanceStatus
+
+	err := conn.DescribeInstanceStatusPages(input, func(page *ec2.DescribeInstanceStatusOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, status := range page.InstanceStatuses {
+			if status == nil {
+				continue
+			}
+
+			output = append(output, status)
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// Addresses looks up the Elastic IP addresses matching the given input, which are returned in a single page.
+func Addresses(conn *ec2.EC2, input *ec2.DescribeAddressesInput) ([]*ec2.Address, error) {
+	output, err := conn.DescribeAddresses(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []*ec2.Address
+	for _, address := range output.Addresses {
+		if address != nil {
+			addresses = append(addresses, address)
+		}
+	}
+
+	return addresses, nil
+}
+
+// NetworkAcls looks up the Network ACLs matching the given input, following all result pages.
+func NetworkAcls(conn *ec2.EC2, input *ec2.DescribeNetworkAclsInput) ([]*ec2.NetworkAcl, error) {
+	var output []*ec2.NetworkAcl
+
+	err := conn.DescribeNetworkAclsPages(input, func(page *ec2.DescribeNetworkAclsOutput, lastPage bool) bool {
+		if page == nil {
+			return !lastPage
+		}
+
+		for _, networkAcl := range page.NetworkAcls {
+			if networkAcl == nil {
+				continue
+			}
+
+			output = append(output, networkAcl)
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
@@ -0,0 +1,248 @@
+package finder_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2/finder"
+)
+
+// testEc2Conn returns an EC2 client answering each DescribeInstances request with the next of the given pages
+// instead of sending it, failing for a nil page, and a pointer to the inputs of the requests made.
+func testEc2Conn(t *testing.T, pages ...*ec2.DescribeInstancesOutput) (*ec2.EC2, *[]*ec2.DescribeInstancesInput) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+	})
+	if err != nil {
+		t.Fatalf("error creating session: %s", err)
+	}
+
+	conn := ec2.New(sess)
+	var inputs []*ec2.DescribeInstancesInput
+
+	conn.Handlers.Send.Clear()
+	conn.Handlers.Unmarshal.Clear()
+	conn.Handlers.UnmarshalMeta.Clear()
+	conn.Handlers.ValidateResponse.Clear()
+	conn.Handlers.Send.PushBack(func(r *request.Request) {
+		input := *r.Params.(*ec2.DescribeInstancesInput)
+		inputs = append(inputs, &input)
+
+		if len(inputs) > len(pages) {
+			r.Error = errors.New("unexpected request")
+			return
+		}
+		if pages[len(inputs)-1] == nil {
+			r.Error = errors.New("request failed")
+			return
+		}
+		*r.Data.(*ec2.DescribeInstancesOutput) = *pages[len(inputs)-1]
+	})
+
+	return conn, &inputs
+}
+
+func testEc2InstancesPage(nextToken *string, instanceIDs ...string) *ec2.DescribeInstancesOutput {
+	reservation := &ec2.Reservation{}
+	for _, instanceID := range instanceIDs {
+		reservation.Instances = append(reservation.Instances, &ec2.Instance{InstanceId: aws.String(instanceID)})
+	}
+
+	return &ec2.DescribeInstancesOutput{
+		NextToken:    nextToken,
+		Reservations: []*ec2.Reservation{reservation},
+	}
+}
+
+func testEc2InstanceIDs(instances []*ec2.Instance) []string {
+	ids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, aws.StringValue(instance.InstanceId))
+	}
+	return ids
+}
+
+func TestInstancesByIDOnly(t *testing.T) {
+	conn, inputs := testEc2Conn(t, testEc2InstancesPage(nil, "i-00000001", "i-00000002"))
+
+	instances, err := finder.Instances(conn, &ec2.DescribeInstancesInput{
+		InstanceIds: aws.StringSlice([]string{"i-00000001", "i-00000002"}),
+	}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := []string{"i-00000001", "i-00000002"}; !reflect.DeepEqual(testEc2InstanceIDs(instances), expected) {
+		t.Errorf("got %s, expected %s", testEc2InstanceIDs(instances), expected)
+	}
+
+	if len(*inputs) != 1 {
+		t.Errorf("got %d requests, expected 1", len(*inputs))
+	}
+}
+
+func TestInstancesByIDOnlyPaginated(t *testing.T) {
+	conn, inputs := testEc2Conn(t,
+		testEc2InstancesPage(aws.String("token"), "i-00000001"),
+		testEc2InstancesPage(aws.String("token"), "i-00000001"),
+		testEc2InstancesPage(nil, "i-00000002"),
+	)
+
+	instances, err := finder.Instances(conn, &ec2.DescribeInstancesInput{
+		InstanceIds: aws.StringSlice([]string{"i-00000001", "i-00000002"}),
+	}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// An unexpectedly paginated result is read again following all pages.
+	if expected := []string{"i-00000001", "i-00000002"}; !reflect.DeepEqual(testEc2InstanceIDs(instances), expected) {
+		t.Errorf("got %s, expected %s", testEc2InstanceIDs(instances), expected)
+	}
+
+	if len(*inputs) != 3 {
+		t.Errorf("got %d requests, expected 3", len(*inputs))
+	}
+}
+
+func TestInstancesByIDWithFilters(t *testing.T) {
+	input := &ec2.DescribeInstancesInput{
+		InstanceIds: aws.StringSlice([]string{"i-00000001", "i-00000002", "i-00000003"}),
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{"running"}),
+			},
+		},
+	}
+
+	conn, inputs := testEc2Conn(t,
+		testEc2InstancesPage(aws.String("token"), "i-00000001"),
+		testEc2InstancesPage(nil, "i-00000003"),
+	)
+
+	instances, err := finder.Instances(conn, input, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := []string{"i-00000001", "i-00000003"}; !reflect.DeepEqual(testEc2InstanceIDs(instances), expected) {
+		t.Errorf("got %s, expected %s", testEc2InstanceIDs(instances), expected)
+	}
+
+	if len(*inputs) != 2 || aws.StringValue((*inputs)[1].NextToken) != "token" {
+		t.Errorf("expected both pages to be requested, got %v", *inputs)
+	}
+
+	conn, _ = testEc2Conn(t,
+		testEc2InstancesPage(aws.String("token"), "i-00000001"),
+		testEc2InstancesPage(nil, "i-00000003"),
+	)
+
+	var exceeded *finder.MaxResultsExceededError
+	if _, err := finder.Instances(conn, input, 1); !errors.As(err, &exceeded) {
+		t.Errorf("got error %v, expected a *finder.MaxResultsExceededError", err)
+	}
+}
+
+func TestStreamInstances(t *testing.T) {
+	conn, _ := testEc2Conn(t,
+		testEc2InstancesPage(aws.String("token"), "i-00000001", "i-00000002"),
+		testEc2InstancesPage(nil, "i-00000003"),
+	)
+
+	stream := finder.StreamInstances(context.Background(), conn, &ec2.DescribeInstancesInput{}, 0)
+	defer stream.Close()
+
+	var got []string
+	for instance := stream.Next(); instance != nil; instance = stream.Next() {
+		got = append(got, aws.StringValue(instance.InstanceId))
+	}
+
+	if err := stream.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := []string{"i-00000001", "i-00000002", "i-00000003"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+}
+
+func TestStreamInstancesError(t *testing.T) {
+	conn, _ := testEc2Conn(t,
+		testEc2InstancesPage(aws.String("token"), "i-00000001"),
+		nil,
+	)
+
+	stream := finder.StreamInstances(context.Background(), conn, &ec2.DescribeInstancesInput{}, 0)
+	defer stream.Close()
+
+	var got []string
+	for instance := stream.Next(); instance != nil; instance = stream.Next() {
+		got = append(got, aws.StringValue(instance.InstanceId))
+	}
+
+	// The instances read before the failure are delivered, and the failure is then reported.
+	if expected := []string{"i-00000001"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+
+	if err := stream.Err(); err == nil {
+		t.Error("expected an error")
+	}
+
+	conn, _ = testEc2Conn(t,
+		testEc2InstancesPage(aws.String("token"), "i-00000001"),
+		testEc2InstancesPage(nil, "i-00000002"),
+	)
+
+	stream = finder.StreamInstances(context.Background(), conn, &ec2.DescribeInstancesInput{}, 1)
+	defer stream.Close()
+
+	for stream.Next() != nil {
+	}
+
+	var exceeded *finder.MaxResultsExceededError
+	if err := stream.Err(); !errors.As(err, &exceeded) {
+		t.Errorf("got error %v, expected a *finder.MaxResultsExceededError", err)
+	}
+}
+
+func TestStreamInstancesClose(t *testing.T) {
+	var pages []*ec2.DescribeInstancesOutput
+	for i := 0; i < 10; i++ {
+		var instanceIDs []string
+		for j := 0; j < 100; j++ {
+			instanceIDs = append(instanceIDs, fmt.Sprintf("i-%08x", i*100+j))
+		}
+		pages = append(pages, testEc2InstancesPage(aws.String("token"), instanceIDs...))
+	}
+
+	conn, inputs := testEc2Conn(t, pages...)
+
+	stream := finder.StreamInstances(context.Background(), conn, &ec2.DescribeInstancesInput{}, 0)
+
+	if instance := stream.Next(); aws.StringValue(instance.InstanceId) != "i-00000000" {
+		t.Errorf("got %v, expected i-00000000", instance)
+	}
+
+	// Closing the stream before consuming it stops the producer, which would otherwise block, without an error.
+	stream.Close()
+
+	if err := stream.Err(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if len(*inputs) == len(pages) {
+		t.Errorf("expected the producer to stop before reading all %d pages", len(pages))
+	}
+}
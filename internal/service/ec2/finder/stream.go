@@ -0,0 +1,104 @@
+package finder
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// streamBufferSize is the number of objects a producer goroutine reads ahead of the consumer of a stream.
+const streamBufferSize = 100
+
+// InstanceStream delivers the EC2 Instances matching a DescribeInstances input one at a time as their result pages
+// are read by a producer goroutine, so that they can be processed, and discarded, without holding all the matching
+// instances in memory at once.
+//
+// The instances are consumed with Next until it returns nil, and Err then returns the error reading them, if any.
+// Close must be called when done with the stream, typically deferred, so that the producer goroutine exits even if
+// the instances have not all been consumed.
+type InstanceStream struct {
+	instances chan *ec2.Instance
+	done      chan struct{}
+	cancel    context.CancelFunc
+	err       error
+}
+
+// StreamInstances starts reading the EC2 Instances of all the Reservations matching the given input, following all
+// result pages. As with Instances, reading fails with a *MaxResultsExceededError once more than maxResults instances,
+// if positive, are found, unless the instances are looked up by ID only.
+func StreamInstances(ctx context.Context, conn *ec2.EC2, input *ec2.DescribeInstancesInput, maxResults int) *InstanceStream {
+	ctx, cancel := context.WithCancel(ctx)
+
+	s := &InstanceStream{
+		instances: make(chan *ec2.Instance, streamBufferSize),
+		done:      make(chan struct{}),
+		cancel:    cancel,
+	}
+
+	if len(input.InstanceIds) > 0 && len(input.Filters) == 0 {
+		maxResults = 0
+	}
+
+	go func() {
+		defer close(s.done)
+		defer close(s.instances)
+
+		var count int
+
+		err := conn.DescribeInstancesPagesWithContext(ctx, input, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			if page == nil {
+				return !lastPage
+			}
+
+			for _, reservation := range page.Reservations {
+				if reservation == nil {
+					continue
+				}
+
+				for _, instance := range reservation.Instances {
+					if instance == nil {
+						continue
+					}
+
+					if count++; maxResults > 0 && count > maxResults {
+						s.err = &MaxResultsExceededError{MaxResults: maxResults}
+						return false
+					}
+
+					select {
+					case s.instances <- instance:
+					case <-ctx.Done():
+						return false
+					}
+				}
+			}
+
+			return !lastPage
+		})
+
+		// The error of a request canceled by Close is of no interest to the consumer, which has stopped reading.
+		if err != nil && s.err == nil && ctx.Err() == nil {
+			s.err = err
+		}
+	}()
+
+	return s
+}
+
+// Next returns the next instance, blocking until it is read, or nil once all the instances have been delivered or
+// reading them failed.
+func (s *InstanceStream) Next() *ec2.Instance {
+	return <-s.instances
+}
+
+// Err returns the error which ended the stream, if any, once Next has returned nil.
+func (s *InstanceStream) Err() error {
+	<-s.done
+	return s.err
+}
+
+// Close stops the producer goroutine, if it is still running, and waits for it to exit.
+func (s *InstanceStream) Close() {
+	s.cancel()
+	<-s.done
+}
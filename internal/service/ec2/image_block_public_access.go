@@ -0,0 +1,113 @@
+package ec2
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// The block public access for AMIs operations were added to the EC2 API after the version of the AWS SDK the
+// provider is built with, so they are sent as custom operations of the same service client, which marshals and
+// unmarshals them with the EC2 query protocol like the generated ones.
+
+const (
+	// ImageBlockPublicAccessStateBlockNewSharing is the state of the block public access for AMIs setting in which
+	// AMIs cannot be made public.
+	ImageBlockPublicAccessStateBlockNewSharing = "block-new-sharing"
+	// ImageBlockPublicAccessStateUnblocked is the state of the block public access for AMIs setting in which AMIs
+	// can be made public.
+	ImageBlockPublicAccessStateUnblocked = "unblocked"
+)
+
+// ImageBlockPublicAccessState_Values returns the states of the block public access for AMIs setting.
+func ImageBlockPublicAccessState_Values() []string {
+	return []string{
+		ImageBlockPublicAccessStateBlockNewSharing,
+		ImageBlockPublicAccessStateUnblocked,
+	}
+}
+
+type getImageBlockPublicAccessStateInput struct {
+	_ struct{} `type:"structure"`
+}
+
+type getImageBlockPublicAccessStateOutput struct {
+	_ struct{} `type:"structure"`
+
+	ImageBlockPublicAccessState *string `locationName:"imageBlockPublicAccessState" type:"string"`
+}
+
+type enableImageBlockPublicAccessInput struct {
+	_ struct{} `type:"structure"`
+
+	ImageBlockPublicAccessState *string `type:"string" required:"true"`
+}
+
+type enableImageBlockPublicAccessOutput struct {
+	_ struct{} `type:"structure"`
+
+	ImageBlockPublicAccessState *string `locationName:"imageBlockPublicAccessState" type:"string"`
+}
+
+type disableImageBlockPublicAccessInput struct {
+	_ struct{} `type:"structure"`
+}
+
+type disableImageBlockPublicAccessOutput struct {
+	_ struct{} `type:"structure"`
+
+	ImageBlockPublicAccessState *string `locationName:"imageBlockPublicAccessState" type:"string"`
+}
+
+// GetImageBlockPublicAccessState returns the current state of the block public access for AMIs setting of the
+// region of the given client, one of ImageBlockPublicAccessState_Values.
+func GetImageBlockPublicAccessState(conn *ec2.EC2) (string, error) {
+	output := &getImageBlockPublicAccessStateOutput{}
+	req := conn.NewRequest(&request.Operation{
+		Name:       "GetImageBlockPublicAccessState",
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}, &getImageBlockPublicAccessStateInput{}, output)
+
+	if err := req.Send(); err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(output.ImageBlockPublicAccessState), nil
+}
+
+// EnableImageBlockPublicAccess sets the block public access for AMIs setting of the region of the given client to
+// the given state, which can only be ImageBlockPublicAccessStateBlockNewSharing, and returns the state reported in
+// the response. The change may take several minutes to be reported by GetImageBlockPublicAccessState.
+func EnableImageBlockPublicAccess(conn *ec2.EC2, state string) (string, error) {
+	output := &enableImageBlockPublicAccessOutput{}
+	req := conn.NewRequest(&request.Operation{
+		Name:       "EnableImageBlockPublicAccess",
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}, &enableImageBlockPublicAccessInput{ImageBlockPublicAccessState: aws.String(state)}, output)
+
+	if err := req.Send(); err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(output.ImageBlockPublicAccessState), nil
+}
+
+// DisableImageBlockPublicAccess sets the block public access for AMIs setting of the region of the given client to
+// ImageBlockPublicAccessStateUnblocked, and returns the state reported in the response. The change may take several
+// minutes to be reported by GetImageBlockPublicAccessState.
+func DisableImageBlockPublicAccess(conn *ec2.EC2) (string, error) {
+	output := &disableImageBlockPublicAccessOutput{}
+	req := conn.NewRequest(&request.Operation{
+		Name:       "DisableImageBlockPublicAccess",
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}, &disableImageBlockPublicAccessInput{}, output)
+
+	if err := req.Send(); err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(output.ImageBlockPublicAccessState), nil
+}
@@ -0,0 +1,121 @@
+package ec2
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// testImageBlockPublicAccessConn returns an EC2 client answering every request with the given state, and the
+// parameters of the requests it was sent.
+func testImageBlockPublicAccessConn(t *testing.T, state string) (*ec2.EC2, *[]url.Values) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+	})
+	if err != nil {
+		t.Fatalf("error creating session: %s", err)
+	}
+
+	conn := ec2.New(sess)
+	var params []url.Values
+
+	conn.Handlers.Send.Clear()
+	conn.Handlers.Send.PushBack(func(r *request.Request) {
+		body, err := ioutil.ReadAll(r.HTTPRequest.Body)
+		if err != nil {
+			r.Error = err
+			return
+		}
+
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			r.Error = err
+			return
+		}
+		params = append(params, values)
+
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body: ioutil.NopCloser(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<Response xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+  <imageBlockPublicAccessState>` + state + `</imageBlockPublicAccessState>
+</Response>`)),
+		}
+	})
+
+	return conn, &params
+}
+
+func TestImageBlockPublicAccess(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		State          string
+		Call           func(conn *ec2.EC2) (string, error)
+		ExpectedParams url.Values
+	}{
+		{
+			Name:  "get",
+			State: ImageBlockPublicAccessStateUnblocked,
+			Call:  GetImageBlockPublicAccessState,
+			ExpectedParams: url.Values{
+				"Action":  {"GetImageBlockPublicAccessState"},
+				"Version": {"2016-11-15"},
+			},
+		},
+		{
+			Name:  "enable",
+			State: ImageBlockPublicAccessStateBlockNewSharing,
+			Call: func(conn *ec2.EC2) (string, error) {
+				return EnableImageBlockPublicAccess(conn, ImageBlockPublicAccessStateBlockNewSharing)
+			},
+			ExpectedParams: url.Values{
+				"Action":                      {"EnableImageBlockPublicAccess"},
+				"Version":                     {"2016-11-15"},
+				"ImageBlockPublicAccessState": {"block-new-sharing"},
+			},
+		},
+		{
+			Name:  "disable",
+			State: ImageBlockPublicAccessStateUnblocked,
+			Call:  DisableImageBlockPublicAccess,
+			ExpectedParams: url.Values{
+				"Action":  {"DisableImageBlockPublicAccess"},
+				"Version": {"2016-11-15"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			conn, params := testImageBlockPublicAccessConn(t, testCase.State)
+
+			state, err := testCase.Call(conn)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if state != testCase.State {
+				t.Errorf("got state %q, expected %q", state, testCase.State)
+			}
+
+			if len(*params) != 1 {
+				t.Fatalf("got %d requests, expected 1", len(*params))
+			}
+
+			if got := (*params)[0].Encode(); got != testCase.ExpectedParams.Encode() {
+				t.Errorf("got params %s, expected %s", got, testCase.ExpectedParams.Encode())
+			}
+		})
+	}
+}
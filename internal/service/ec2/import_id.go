@@ -0,0 +1,81 @@
+package ec2
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The functions in this file format the IDs expected by "terraform import" for
+// the corresponding resources of the upstream AWS provider, so that objects
+// discovered by this provider's data sources can be brought under management there.
+
+const securityGroupRuleImportIDSeparator = "_"
+
+// SecurityGroupRuleImportID returns the aws_security_group_rule import ID for a rule of the given
+// Security Group, e.g. sg-6e616f6d69_ingress_tcp_8000_8000_10.0.3.0/24.
+//
+// Sources are CIDR blocks, prefix list IDs, Security Group IDs or "self". A protocol of "-1"
+// is rendered as "all", in which case the ports are always rendered as 0.
+func SecurityGroupRuleImportID(groupID string, isEgress bool, protocol string, fromPort, toPort int64, sources ...string) string {
+	ruleType := "ingress"
+	if isEgress {
+		ruleType = "egress"
+	}
+
+	if protocol == "-1" || protocol == "all" {
+		protocol = "all"
+		fromPort, toPort = 0, 0
+	}
+
+	parts := []string{groupID, ruleType, protocol, fmt.Sprintf("%d", fromPort), fmt.Sprintf("%d", toPort)}
+	parts = append(parts, sources...)
+
+	return strings.Join(parts, securityGroupRuleImportIDSeparator)
+}
+
+// SecurityGroupRuleSource returns the source of a rule as used in its aws_security_group_rule import ID.
+// The first non-empty value of the given CIDR blocks, prefix list ID and referenced Security Group ID
+// is returned, with a reference to the rule's own Security Group rendered as "self".
+func SecurityGroupRuleSource(groupID, cidrIpv4, cidrIpv6, prefixListID, referencedGroupID string) string {
+	switch {
+	case cidrIpv4 != "":
+		return cidrIpv4
+	case cidrIpv6 != "":
+		return cidrIpv6
+	case prefixListID != "":
+		return prefixListID
+	case referencedGroupID != "" && referencedGroupID == groupID:
+		return "self"
+	default:
+		return referencedGroupID
+	}
+}
+
+// RouteImportID returns the aws_route import ID for a route, e.g. rtb-656C65616E6F72_10.42.0.0/16.
+func RouteImportID(routeTableID, destination string) string {
+	return fmt.Sprintf("%s_%s", routeTableID, destination)
+}
+
+// RouteTableAssociationImportID returns the aws_route_table_association import ID for the association
+// of a subnet or gateway with a route table, e.g. subnet-6777656e646f6c796e/rtb-656c65616e6f72.
+func RouteTableAssociationImportID(subnetOrGatewayID, routeTableID string) string {
+	return fmt.Sprintf("%s/%s", subnetOrGatewayID, routeTableID)
+}
+
+// NetworkAclRuleImportID returns the aws_network_acl_rule import ID for a Network ACL entry,
+// e.g. acl-7aaabd18:100:tcp:false.
+func NetworkAclRuleImportID(networkAclID string, ruleNumber int64, protocol string, egress bool) string {
+	return fmt.Sprintf("%s:%d:%s:%t", networkAclID, ruleNumber, protocol, egress)
+}
+
+// VolumeAttachmentImportID returns the aws_volume_attachment import ID for the attachment of a volume
+// to an instance, e.g. /dev/sdh:vol-049df61146c4d7901:i-12345678.
+func VolumeAttachmentImportID(deviceName, volumeID, instanceID string) string {
+	return fmt.Sprintf("%s:%s:%s", deviceName, volumeID, instanceID)
+}
+
+// TagImportID returns the aws_ec2_tag import ID for a tag on an EC2 resource,
+// e.g. tgw-attach-1234567890abcdef,Name.
+func TagImportID(resourceID, key string) string {
+	return fmt.Sprintf("%s,%s", resourceID, key)
+}
@@ -0,0 +1,72 @@
+package ec2_test
+
+import (
+	"testing"
+
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+)
+
+func TestSecurityGroupRuleImportID(t *testing.T) {
+	for _, ts := range []struct {
+		groupID  string
+		isEgress bool
+		protocol string
+		fromPort int64
+		toPort   int64
+		sources  []string
+		expected string
+	}{
+		{"sg-6e616f6d69", false, "tcp", 8000, 8000, []string{"10.0.3.0/24"}, "sg-6e616f6d69_ingress_tcp_8000_8000_10.0.3.0/24"},
+		{"sg-62726f6479", false, "tcp", 8000, 8000, []string{"10.0.3.0/24", "10.0.4.0/24"}, "sg-62726f6479_ingress_tcp_8000_8000_10.0.3.0/24_10.0.4.0/24"},
+		{"sg-656c65616e6f72", false, "tcp", 80, 80, []string{"self", "2001:db8::/48"}, "sg-656c65616e6f72_ingress_tcp_80_80_self_2001:db8::/48"},
+		{"sg-4973616163", true, "tcp", 8000, 8000, []string{"pl-6173696d6f76"}, "sg-4973616163_egress_tcp_8000_8000_pl-6173696d6f76"},
+		{"sg-62726f6479", false, "-1", -1, -1, []string{"sg-6176657279"}, "sg-62726f6479_ingress_all_0_0_sg-6176657279"},
+		{"sg-62726f6479", false, "icmp", 8, -1, []string{"0.0.0.0/0"}, "sg-62726f6479_ingress_icmp_8_-1_0.0.0.0/0"},
+	} {
+		got := tfec2.SecurityGroupRuleImportID(ts.groupID, ts.isEgress, ts.protocol, ts.fromPort, ts.toPort, ts.sources...)
+		if got != ts.expected {
+			t.Fatalf("SecurityGroupRuleImportID should be: %q, got: %q", ts.expected, got)
+		}
+	}
+}
+
+func TestSecurityGroupRuleSource(t *testing.T) {
+	for _, ts := range []struct {
+		groupID           string
+		cidrIpv4          string
+		cidrIpv6          string
+		prefixListID      string
+		referencedGroupID string
+		expected          string
+	}{
+		{"sg-1", "10.0.3.0/24", "", "", "", "10.0.3.0/24"},
+		{"sg-1", "", "2001:db8::/48", "", "", "2001:db8::/48"},
+		{"sg-1", "", "", "pl-6173696d6f76", "", "pl-6173696d6f76"},
+		{"sg-1", "", "", "", "sg-1", "self"},
+		{"sg-1", "", "", "", "sg-2", "sg-2"},
+	} {
+		got := tfec2.SecurityGroupRuleSource(ts.groupID, ts.cidrIpv4, ts.cidrIpv6, ts.prefixListID, ts.referencedGroupID)
+		if got != ts.expected {
+			t.Fatalf("SecurityGroupRuleSource should be: %q, got: %q", ts.expected, got)
+		}
+	}
+}
+
+func TestImportIDs(t *testing.T) {
+	for _, ts := range []struct {
+		got      string
+		expected string
+	}{
+		{tfec2.RouteImportID("rtb-656C65616E6F72", "10.42.0.0/16"), "rtb-656C65616E6F72_10.42.0.0/16"},
+		{tfec2.RouteImportID("rtb-656C65616E6F72", "2620:0:2d0:200::8/125"), "rtb-656C65616E6F72_2620:0:2d0:200::8/125"},
+		{tfec2.RouteTableAssociationImportID("subnet-6777656e646f6c796e", "rtb-656c65616e6f72"), "subnet-6777656e646f6c796e/rtb-656c65616e6f72"},
+		{tfec2.RouteTableAssociationImportID("igw-01b3a60780f8d034a", "rtb-656c65616e6f72"), "igw-01b3a60780f8d034a/rtb-656c65616e6f72"},
+		{tfec2.NetworkAclRuleImportID("acl-7aaabd18", 100, "tcp", false), "acl-7aaabd18:100:tcp:false"},
+		{tfec2.VolumeAttachmentImportID("/dev/sdh", "vol-049df61146c4d7901", "i-12345678"), "/dev/sdh:vol-049df61146c4d7901:i-12345678"},
+		{tfec2.TagImportID("tgw-attach-1234567890abcdef", "Name"), "tgw-attach-1234567890abcdef,Name"},
+	} {
+		if ts.got != ts.expected {
+			t.Fatalf("import ID should be: %q, got: %q", ts.expected, ts.got)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package ec2
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// ResourceTypeMetadata describes how the objects of an EC2 resource type are identified.
+type ResourceTypeMetadata struct {
+	// IDPrefix is the prefix of the object IDs, e.g. "vpc" for "vpc-0123456789abcdef0".
+	IDPrefix string
+	// IDFilterName is the name of the "Describe..." filter matching objects by ID.
+	IDFilterName string
+	// IDParameter is the name of the dedicated ID list parameter of the "Describe..." input, if any.
+	IDParameter string
+	// OwnersParameter is the name of the dedicated owner list parameter of the "Describe..." input, if any. Unlike
+	// the "owner-id" filter, it accepts "self" for the account of the caller.
+	OwnersParameter string
+}
+
+// resourceTypes holds the metadata of the supported resource types, keyed by their ec2.ResourceType value.
+var resourceTypes = map[string]ResourceTypeMetadata{
+	ec2.ResourceTypeElasticIp:            {IDPrefix: "eipalloc", IDFilterName: "allocation-id", IDParameter: "AllocationIds"},
+	ec2.ResourceTypeImage:                {IDPrefix: "ami", IDFilterName: "image-id", IDParameter: "ImageIds", OwnersParameter: "Owners"},
+	ec2.ResourceTypeInstance:             {IDPrefix: "i", IDFilterName: "instance-id", IDParameter: "InstanceIds"},
+	ec2.ResourceTypeInternetGateway:      {IDPrefix: "igw", IDFilterName: "internet-gateway-id", IDParameter: "InternetGatewayIds"},
+	ec2.ResourceTypeLaunchTemplate:       {IDPrefix: "lt", IDFilterName: "launch-template-id", IDParameter: "LaunchTemplateIds"},
+	ec2.ResourceTypeNatgateway:           {IDPrefix: "nat", IDFilterName: "nat-gateway-id", IDParameter: "NatGatewayIds"},
+	ec2.ResourceTypeNetworkAcl:           {IDPrefix: "acl", IDFilterName: "network-acl-id", IDParameter: "NetworkAclIds"},
+	ec2.ResourceTypeNetworkInterface:     {IDPrefix: "eni", IDFilterName: "network-interface-id", IDParameter: "NetworkInterfaceIds"},
+	ec2.ResourceTypePrefixList:           {IDPrefix: "pl", IDFilterName: "prefix-list-id", IDParameter: "PrefixListIds"},
+	ec2.ResourceTypeRouteTable:           {IDPrefix: "rtb", IDFilterName: "route-table-id", IDParameter: "RouteTableIds"},
+	ec2.ResourceTypeSecurityGroup:        {IDPrefix: "sg", IDFilterName: "group-id", IDParameter: "GroupIds"},
+	ec2.ResourceTypeSecurityGroupRule:    {IDPrefix: "sgr", IDFilterName: "security-group-rule-id", IDParameter: "SecurityGroupRuleIds"},
+	ec2.ResourceTypeSnapshot:             {IDPrefix: "snap", IDFilterName: "snapshot-id", IDParameter: "SnapshotIds", OwnersParameter: "OwnerIds"},
+	ec2.ResourceTypeSubnet:               {IDPrefix: "subnet", IDFilterName: "subnet-id", IDParameter: "SubnetIds"},
+	ec2.ResourceTypeTransitGateway:       {IDPrefix: "tgw", IDFilterName: "transit-gateway-id", IDParameter: "TransitGatewayIds"},
+	ec2.ResourceTypeVolume:               {IDPrefix: "vol", IDFilterName: "volume-id", IDParameter: "VolumeIds"},
+	ec2.ResourceTypeVpc:                  {IDPrefix: "vpc", IDFilterName: "vpc-id", IDParameter: "VpcIds"},
+	ec2.ResourceTypeVpcEndpoint:          {IDPrefix: "vpce", IDFilterName: "vpc-endpoint-id", IDParameter: "VpcEndpointIds"},
+	ec2.ResourceTypeVpcFlowLog:           {IDPrefix: "fl", IDFilterName: "flow-log-id", IDParameter: "FlowLogIds"},
+	ec2.ResourceTypeVpcPeeringConnection: {IDPrefix: "pcx", IDFilterName: "vpc-peering-connection-id", IDParameter: "VpcPeeringConnectionIds"},
+}
+
+// resourceIDSuffixRegexp matches the hexadecimal suffix of EC2 object IDs, in either the short (8 character)
+// or the long (17 character) format.
+var resourceIDSuffixRegexp = regexp.MustCompile(`^([0-9a-f]{8}|[0-9a-f]{17})$`)
+
+// ResourceTypeMetadataFor returns the metadata of the given resource type, and whether it is supported.
+func ResourceTypeMetadataFor(resourceType string) (ResourceTypeMetadata, bool) {
+	metadata, ok := resourceTypes[resourceType]
+	return metadata, ok
+}
+
+// ResourceTypes returns the supported resource types, sorted.
+func ResourceTypes() []string {
+	result := make([]string, 0, len(resourceTypes))
+	for resourceType := range resourceTypes {
+		result = append(result, resourceType)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// ValidateResourceID returns an error if the given ID is not a well-formed ID of the given resource type.
+func ValidateResourceID(resourceType, id string) error {
+	metadata, ok := resourceTypes[resourceType]
+	if !ok {
+		return fmt.Errorf("unsupported EC2 resource type: %s", resourceType)
+	}
+
+	prefix := metadata.IDPrefix + "-"
+	if !strings.HasPrefix(id, prefix) || !resourceIDSuffixRegexp.MatchString(strings.TrimPrefix(id, prefix)) {
+		return fmt.Errorf("%q is not a valid %s ID, expected %s followed by 8 or 17 hexadecimal characters", id, resourceType, prefix)
+	}
+
+	return nil
+}
+
+// ParsedResourceARN is an EC2 object ARN parsed by ParseResourceARN.
+type ParsedResourceARN struct {
+	// ID is the ID of the object, e.g. "vpc-0123456789abcdef0".
+	ID string
+	// Region is the region of the object.
+	Region string
+}
+
+// ParseResourceARN parses the given ARN of an object of the given resource type, e.g.
+// "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0123456789abcdef0", and returns an error if it is malformed, is not
+// the ARN of an object of that type or does not include a well-formed ID. The resource type of EC2 ARNs is the
+// ec2.ResourceType value itself.
+func ParseResourceARN(resourceType, s string) (ParsedResourceARN, error) {
+	if _, ok := resourceTypes[resourceType]; !ok {
+		return ParsedResourceARN{}, fmt.Errorf("unsupported EC2 resource type: %s", resourceType)
+	}
+
+	parsed, err := arn.Parse(s)
+	if err != nil {
+		return ParsedResourceARN{}, fmt.Errorf("%q is not a valid ARN: %w", s, err)
+	}
+
+	if parsed.Service != ec2.ServiceName {
+		return ParsedResourceARN{}, fmt.Errorf("%q is not an EC2 ARN, got service %q", s, parsed.Service)
+	}
+
+	parts := strings.SplitN(parsed.Resource, "/", 2)
+	if len(parts) != 2 {
+		return ParsedResourceARN{}, fmt.Errorf("%q is not a valid EC2 ARN, expected a resource of the form %s/ID", s, resourceType)
+	}
+
+	if parts[0] != resourceType {
+		return ParsedResourceARN{}, fmt.Errorf("%q is not the ARN of a %s, got resource type %q", s, resourceType, parts[0])
+	}
+
+	if err := ValidateResourceID(resourceType, parts[1]); err != nil {
+		return ParsedResourceARN{}, fmt.Errorf("%q is not a valid %s ARN: %w", s, resourceType, err)
+	}
+
+	return ParsedResourceARN{
+		ID:     parts[1],
+		Region: parsed.Region,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package ec2_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	tfec2 "github.com/cloudposse/terraform-provider-awsutils/internal/service/ec2"
+)
+
+func TestValidateResourceID(t *testing.T) {
+	for _, ts := range []struct {
+		resourceType string
+		id           string
+		valid        bool
+	}{
+		{ec2.ResourceTypeVpc, "vpc-0123456789abcdef0", true},
+		{ec2.ResourceTypeVpc, "vpc-01234567", true},
+		{ec2.ResourceTypeVpc, "vpc-0123456789", false},
+		{ec2.ResourceTypeVpc, "subnet-01234567", false},
+		{ec2.ResourceTypeVpc, "vpc-0123456G", false},
+		{ec2.ResourceTypeInstance, "i-0123456789abcdef0", true},
+		{ec2.ResourceTypeSecurityGroup, "sg-0123456789abcdef0", true},
+		{ec2.ResourceTypeSecurityGroup, "sgr-0123456789abcdef0", false},
+		{ec2.ResourceTypeSecurityGroupRule, "sgr-0123456789abcdef0", true},
+		{"unsupported", "x-01234567", false},
+	} {
+		err := tfec2.ValidateResourceID(ts.resourceType, ts.id)
+		if ts.valid && err != nil {
+			t.Errorf("ValidateResourceID(%q, %q) returned unexpected error: %s", ts.resourceType, ts.id, err)
+		}
+		if !ts.valid && err == nil {
+			t.Errorf("ValidateResourceID(%q, %q) returned no error", ts.resourceType, ts.id)
+		}
+	}
+}
+
+func TestParseResourceARN(t *testing.T) {
+	for _, ts := range []struct {
+		resourceType string
+		arn          string
+		expected     tfec2.ParsedResourceARN
+		valid        bool
+	}{
+		{ec2.ResourceTypeVpc, "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0123456789abcdef0", tfec2.ParsedResourceARN{ID: "vpc-0123456789abcdef0", Region: "us-east-1"}, true},
+		{ec2.ResourceTypeInstance, "arn:aws-us-gov:ec2:us-gov-west-1:123456789012:instance/i-01234567", tfec2.ParsedResourceARN{ID: "i-01234567", Region: "us-gov-west-1"}, true},
+		{ec2.ResourceTypeImage, "arn:aws:ec2:eu-west-1::image/ami-0123456789abcdef0", tfec2.ParsedResourceARN{ID: "ami-0123456789abcdef0", Region: "eu-west-1"}, true},
+		{ec2.ResourceTypeSecurityGroupRule, "arn:aws:ec2:us-east-1:123456789012:security-group-rule/sgr-0123456789abcdef0", tfec2.ParsedResourceARN{ID: "sgr-0123456789abcdef0", Region: "us-east-1"}, true},
+		{ec2.ResourceTypeVpc, "vpc-0123456789abcdef0", tfec2.ParsedResourceARN{}, false},
+		{ec2.ResourceTypeVpc, "arn:aws:ec2:us-east-1", tfec2.ParsedResourceARN{}, false},
+		{ec2.ResourceTypeVpc, "arn:aws:s3:::vpc/vpc-0123456789abcdef0", tfec2.ParsedResourceARN{}, false},
+		{ec2.ResourceTypeVpc, "arn:aws:ec2:us-east-1:123456789012:vpc-0123456789abcdef0", tfec2.ParsedResourceARN{}, false},
+		{ec2.ResourceTypeVpc, "arn:aws:ec2:us-east-1:123456789012:subnet/subnet-0123456789abcdef0", tfec2.ParsedResourceARN{}, false},
+		{ec2.ResourceTypeSecurityGroup, "arn:aws:ec2:us-east-1:123456789012:security-group/sgr-0123456789abcdef0", tfec2.ParsedResourceARN{}, false},
+		{ec2.ResourceTypeVpc, "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0123", tfec2.ParsedResourceARN{}, false},
+		{"unsupported", "arn:aws:ec2:us-east-1:123456789012:unsupported/x-01234567", tfec2.ParsedResourceARN{}, false},
+	} {
+		parsed, err := tfec2.ParseResourceARN(ts.resourceType, ts.arn)
+		if ts.valid && err != nil {
+			t.Errorf("ParseResourceARN(%q, %q) returned unexpected error: %s", ts.resourceType, ts.arn, err)
+		}
+		if !ts.valid && err == nil {
+			t.Errorf("ParseResourceARN(%q, %q) returned no error", ts.resourceType, ts.arn)
+		}
+		if parsed != ts.expected {
+			t.Errorf("ParseResourceARN(%q, %q) returned %+v, expected %+v", ts.resourceType, ts.arn, parsed, ts.expected)
+		}
+	}
+}